@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 	"github.com/smart-payment-infrastructure/internal/services"
 	"github.com/smart-payment-infrastructure/pkg/auth"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
 )
 
 func main() {
@@ -53,38 +56,61 @@ func main() {
 	walletRepo := repository.NewWalletRepository(db)
 	auditRepo := repository.NewAuditRepository(db)
 
+	// Sandbox mode runs against an in-process ledger simulator instead of a network
+	if xrpl.Mode(cfg.XRPL.Mode) == xrpl.ModeSandbox {
+		sandbox, err := simulator.StartSandbox()
+		if err != nil {
+			log.Fatalf("Failed to start XRPL sandbox: %v", err)
+		}
+		defer sandbox.Close()
+		cfg.XRPL.JSONRPCURL = sandbox.URL
+		cfg.XRPL.NetworkURL = sandbox.WebSocketURL
+	}
+
 	// Initialize XRPL service
 	xrplService := services.NewXRPLService(services.XRPLConfig{
-		NetworkURL: cfg.XRPL.NetworkURL,
+		NetworkURL: cfg.XRPL.JSONRPCURL,
 		TestNet:    cfg.XRPL.TestNet,
+		Mode:       cfg.XRPL.Mode,
 	})
 
 	if err := xrplService.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize XRPL service: %v", err)
 	}
 
+	// Issued-currency escrows and trust lines use the issuers configured per supported asset, and
+	// amounts its precision
+	assetRepo := repository.NewPostgresAssetRepository(db)
+	if assets, err := assetRepo.GetAssets(context.Background(), true); err != nil {
+		log.Printf("Failed to load supported assets: %v", err)
+	} else if err := xrplService.ConfigureIssuedAssets(assets); err != nil {
+		log.Fatalf("Invalid XRPL asset configuration: %v", err)
+	} else if err := models.ConfigureCurrencyScales(assets...); err != nil {
+		log.Fatalf("Invalid asset precision: %v", err)
+	}
+
 	// Initialize wallet service
 	walletService, err := services.NewWalletService(
 		walletRepo,
 		enterpriseRepo,
 		xrplService,
 		services.WalletServiceConfig{
-			EncryptionKey: cfg.JWT.SecretKey, // Using JWT secret as encryption key for now
+			EncryptionKey:   cfg.JWT.SecretKey, // Using JWT secret as encryption key for now
+			TreasuryAddress: cfg.XRPL.TreasuryAddress,
 		},
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize wallet service: %v", err)
 	}
 
-	// Initialize wallet monitoring service
-	walletMonitoringService := services.NewWalletMonitoringService(walletRepo, xrplService)
+	// Transactions are signed in-process with the keys held by the wallet service
+	xrplService.SetKeyProvider(walletService)
 
 	authService := services.NewAuthService(userRepo, jwtService)
 	enterpriseService := services.NewEnterpriseService(enterpriseRepo, walletService)
 	auditService := services.NewAuditService(auditRepo)
 	authHandler := handlers.NewAuthHandler(authService)
 	enterpriseHandler := handlers.NewEnterpriseHandler(enterpriseService)
-	walletHandler := handlers.NewWalletHandler(walletService, walletMonitoringService)
 	auditHandler := handlers.NewAuditHandler(auditService)
 
 	// Initialize messaging service
@@ -98,6 +124,51 @@ func main() {
 	}
 	defer messagingService.Close()
 
+	// Initialize wallet monitoring service; low reserve alerts are published on the event bus
+	walletMonitoringService := services.NewWalletMonitoringServiceWithAlerts(walletRepo, xrplService, messagingService.EventBus(), services.DefaultWalletMonitoringConfig())
+	walletHandler := handlers.NewWalletHandler(walletService, walletMonitoringService)
+
+	// Deposits into shared receiving wallets are credited by destination tag
+	balanceService := services.NewBalanceService(
+		repository.NewPostgresBalanceRepository(db),
+		assetRepo,
+		messagingService,
+	)
+	destinationTagService := services.NewDestinationTagService(
+		repository.NewDestinationTagRepository(db),
+		walletRepo,
+		xrplService,
+		balanceService,
+	)
+	destinationTagHandler := handlers.NewDestinationTagHandler(destinationTagService)
+
+	// Track wallet activity from the rippled WebSocket stream when connected to a ledger
+	if mode := xrpl.Mode(cfg.XRPL.Mode); mode == xrpl.ModeJSONRPC || mode == xrpl.ModeSandbox {
+		ledgerStream := services.NewLedgerStreamService(
+			xrpl.NewSubscriptionClient(xrpl.StreamConfig{URL: cfg.XRPL.NetworkURL}),
+			walletRepo,
+			balanceService,
+		)
+		ledgerStream.SetDepositCreditor(destinationTagService)
+
+		// Index the on-ledger history of every wallet, backfilling once and then per closed ledger
+		ledgerIndexer := services.NewLedgerIndexerService(
+			repository.NewLedgerTransactionRepository(db),
+			walletRepo,
+			xrplService,
+		)
+		ledgerStream.AddLedgerHandler(ledgerIndexer)
+		go func() {
+			if _, err := ledgerIndexer.IndexAll(context.Background()); err != nil {
+				log.Printf("Failed to backfill XRPL account history: %v", err)
+			}
+		}()
+		if err := ledgerStream.Start(context.Background()); err != nil {
+			log.Printf("Failed to start XRPL ledger stream: %v", err)
+		}
+		defer ledgerStream.Stop()
+	}
+
 	// Subscribe to relevant events
 	err = messagingService.SubscribeToEvent(messaging.EventTypeEnterpriseRegistered, handleEnterpriseRegistered)
 	if err != nil {
@@ -221,6 +292,10 @@ func main() {
 			middleware.RequirePermission(models.PermissionApproveKYB),
 			walletHandler.SuspendWallet)
 
+		protected.PUT("/wallets/:id/retire",
+			middleware.RequirePermission(models.PermissionApproveKYB),
+			walletHandler.RetireWallet)
+
 		protected.GET("/wallets/whitelisted",
 			middleware.RequirePermission(models.PermissionViewAuditLogs),
 			walletHandler.GetWhitelistedWallets)
@@ -242,9 +317,30 @@ func main() {
 			middleware.RequirePermission(models.PermissionViewAuditLogs),
 			walletHandler.GetInactiveWallets)
 
+		protected.GET("/wallets/reserve-alerts",
+			middleware.RequirePermission(models.PermissionViewAuditLogs),
+			walletHandler.GetReserveAlerts)
+
 		protected.GET("/wallets/metrics",
 			middleware.RequirePermission(models.PermissionViewAuditLogs),
 			walletHandler.GetWalletMetrics)
+
+		// Shared receiving wallets and destination tag routing
+		protected.PUT("/wallets/:id/omnibus",
+			middleware.RequirePermission(models.PermissionApproveKYB),
+			destinationTagHandler.ConfigureOmnibusWallet)
+
+		protected.POST("/wallets/address/:address/destination-tags",
+			middleware.RequirePermission(models.PermissionApproveKYB),
+			destinationTagHandler.AllocateDestinationTag)
+
+		protected.GET("/deposits/quarantined",
+			middleware.RequirePermission(models.PermissionViewAuditLogs),
+			destinationTagHandler.GetQuarantinedDeposits)
+
+		protected.POST("/deposits/quarantined/:id/release",
+			middleware.RequirePermission(models.PermissionApprovePayment),
+			destinationTagHandler.ReleaseQuarantinedDeposit)
 	}
 
 	log.Println("Identity Service starting on :8001")
@@ -1,20 +1,117 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
 
 	"github.com/smart-payment-infrastructure/internal/config"
+	"github.com/smart-payment-infrastructure/internal/handlers"
 	"github.com/smart-payment-infrastructure/internal/middleware"
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/internal/services"
+	"github.com/smart-payment-infrastructure/pkg/auth"
+	"github.com/smart-payment-infrastructure/pkg/crypto"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Initialize database connection
+	db, err := sql.Open("postgres", cfg.Database.PostgresURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Test database connection
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	// Parse JWT token durations
+	accessTokenDuration, err := time.ParseDuration(cfg.JWT.AccessTokenDuration)
+	if err != nil {
+		log.Fatalf("Invalid access token duration: %v", err)
+	}
+
+	refreshTokenDuration, err := time.ParseDuration(cfg.JWT.RefreshTokenDuration)
+	if err != nil {
+		log.Fatalf("Invalid refresh token duration: %v", err)
+	}
+
+	// Initialize repositories
+	jwtService := auth.NewJWTService(cfg.JWT.SecretKey, accessTokenDuration, refreshTokenDuration)
+	userRepo := repository.NewUserRepository(db)
+	enterpriseRepo := repository.NewEnterpriseRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	smartChequeRepo := repository.NewSmartChequeRepository(db)
+
+	// Sandbox mode runs against an in-process ledger simulator instead of a network
+	if xrpl.Mode(cfg.XRPL.Mode) == xrpl.ModeSandbox {
+		sandbox, err := simulator.StartSandbox()
+		if err != nil {
+			log.Fatalf("Failed to start XRPL sandbox: %v", err)
+		}
+		defer sandbox.Close()
+		cfg.XRPL.JSONRPCURL = sandbox.URL
+		cfg.XRPL.NetworkURL = sandbox.WebSocketURL
+	}
+
+	// Initialize XRPL service
+	xrplService := services.NewXRPLService(services.XRPLConfig{
+		NetworkURL: cfg.XRPL.JSONRPCURL,
+		TestNet:    cfg.XRPL.TestNet,
+		Mode:       cfg.XRPL.Mode,
+	})
+
+	if err := xrplService.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize XRPL service: %v", err)
+	}
+
+	// Issued-currency escrows use the issuers configured per supported asset, and amounts its precision
+	assetRepo := repository.NewPostgresAssetRepository(db)
+	if assets, err := assetRepo.GetAssets(context.Background(), true); err != nil {
+		log.Printf("Failed to load supported assets: %v", err)
+	} else if err := xrplService.ConfigureIssuedAssets(assets); err != nil {
+		log.Fatalf("Invalid XRPL asset configuration: %v", err)
+	} else if err := models.ConfigureCurrencyScales(assets...); err != nil {
+		log.Fatalf("Invalid asset precision: %v", err)
+	}
+
+	// The wallet service holds the keys smart check payers sign with
+	walletService, err := services.NewWalletService(
+		walletRepo,
+		enterpriseRepo,
+		xrplService,
+		services.WalletServiceConfig{
+			EncryptionKey:   cfg.JWT.SecretKey, // Using JWT secret as encryption key for now
+			TreasuryAddress: cfg.XRPL.TreasuryAddress,
+		},
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize wallet service: %v", err)
+	}
+	xrplService.SetKeyProvider(walletService)
+
+	networkType := "testnet"
+	if !cfg.XRPL.TestNet {
+		networkType = "mainnet"
+	}
+
 	// Initialize messaging service
 	messagingService, err := messaging.NewService(
 		cfg.Redis.URL,
@@ -26,17 +123,95 @@ func main() {
 	}
 	defer messagingService.Close()
 
+	// Escrow fulfillments are sealed in the vault and only payment execution releases them
+	fulfillmentEncryptor, err := crypto.NewEncryptor(cfg.JWT.SecretKey) // Using JWT secret as encryption key for now
+	if err != nil {
+		log.Fatalf("Failed to initialize fulfillment encryption: %v", err)
+	}
+	fulfillmentVault := services.NewFulfillmentVault(repository.NewFulfillmentVaultRepository(db), fulfillmentEncryptor)
+
+	milestoneRepo := repository.NewPostgresMilestoneRepository(db)
+	contractRepo := repository.NewPostgresContractRepository(db)
+	transactionRepo := repository.NewSmartChequeTransactionRepository(db)
+
+	// Payment execution finishes milestone escrows, pays endorsed proceeds on to their holder and
+	// settles them in the smart check's settlement currency
+	paymentExecutionService := services.NewPaymentExecutionServiceWithSettlement(
+		services.NewPaymentAuthorizationService(
+			smartChequeRepo,
+			milestoneRepo,
+			contractRepo,
+			nil,
+			messagingService.EventBus(),
+			&services.PaymentAuthorizationConfig{},
+		),
+		smartChequeRepo,
+		transactionRepo,
+		xrplService,
+		fulfillmentVault,
+		messagingService.EventBus(),
+		&services.PaymentExecutionConfig{},
+		services.NewCrossCurrencySettlementService(xrplService, transactionRepo, services.CrossCurrencySettlementConfig{}),
+	)
+
+	authService := services.NewAuthService(userRepo, jwtService)
+	auditService := services.NewAuditService(auditRepo)
+	smartChequeXRPLService := services.NewSmartChequeXRPLService(
+		smartChequeRepo,
+		transactionRepo,
+		xrplService,
+		milestoneRepo,
+		fulfillmentVault,
+		paymentExecutionService,
+	)
+	// Status updates go through the state machine that carries out escrow effects
+	smartChequeService := services.NewSmartChequeServiceWithStateMachine(smartChequeRepo, auditRepo, smartChequeXRPLService.StateMachine())
+	smartChequeHandler := handlers.NewSmartChequeHandlerWithSettlement(smartChequeService, smartChequeXRPLService, walletService, networkType)
+
 	// Subscribe to relevant events
 	err = messagingService.SubscribeToEvent(messaging.EventTypeEnterpriseRegistered, handleEnterpriseRegistered)
 	if err != nil {
 		log.Printf("Failed to subscribe to enterprise registered events: %v", err)
 	}
 
-	err = messagingService.SubscribeToEvent(messaging.EventTypeMilestoneCompleted, handleMilestoneCompleted)
+	err = messagingService.SubscribeToEvent(messaging.EventTypeMilestoneCompleted, milestoneCompletedHandler(smartChequeXRPLService))
 	if err != nil {
 		log.Printf("Failed to subscribe to milestone completed events: %v", err)
 	}
 
+	// Record every status transition and announce the ones the parties should hear about
+	transitionRepo := repository.NewSmartChequeTransitionRepository(db)
+	for _, stateMachine := range []*services.SmartChequeStateMachine{smartChequeXRPLService.StateMachine(), paymentExecutionService.StateMachine()} {
+		stateMachine.SetHistory(transitionRepo)
+		stateMachine.SetEventBus(messagingService.EventBus())
+	}
+
+	// Payees can endorse their proceeds to other enterprises, with the payer's acknowledgement
+	// where the contract requires it
+	smartChequeHandler.SetEndorsementService(services.NewSmartChequeEndorsementService(
+		smartChequeRepo,
+		repository.NewSmartChequeEndorsementRepository(db),
+		contractRepo,
+		messagingService.EventBus(),
+	))
+
+	// Return the funds of milestone escrows whose CancelAfter has passed
+	expirySweeper := services.NewSmartChequeExpirySweeper(smartChequeRepo, smartChequeXRPLService, 10*time.Minute)
+	if err := expirySweeper.Start(context.Background()); err != nil {
+		log.Printf("Failed to start smart check expiry sweeper: %v", err)
+	}
+	defer expirySweeper.Stop()
+
+	// Confirm submitted escrow, refund and payout transactions once a validated ledger applies them
+	submissionConfirmer := services.NewLedgerSubmissionConfirmer(transactionRepo, xrplService, time.Minute)
+	// Milestone escrows are resolved once their finishes and cancellations validate
+	submissionConfirmer.AddObserver(paymentExecutionService)
+	submissionConfirmer.AddObserver(smartChequeXRPLService)
+	if err := submissionConfirmer.Start(context.Background()); err != nil {
+		log.Printf("Failed to start ledger submission confirmer: %v", err)
+	}
+	defer submissionConfirmer.Stop()
+
 	r := gin.New()
 
 	// Add global error handling middleware first
@@ -48,6 +223,9 @@ func main() {
 	// Add messaging middleware
 	r.Use(middleware.MessagingMiddleware(messagingService))
 
+	// Add audit middleware for authenticated routes
+	r.Use(middleware.AuditMiddleware(auditService))
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -59,107 +237,105 @@ func main() {
 	// Messaging health check
 	r.GET("/health/messaging", middleware.MessagingHealthCheck)
 
-	// Smart Check creation endpoint (example)
-	r.POST("/smart-checks", createSmartCheque)
+	// Protected endpoints
+	protected := r.Group("/api")
+	protected.Use(handlers.AuthMiddleware(authService))
+	{
+		// Smart check lifecycle with RBAC; the handlers also check the caller's enterprise is a
+		// party to the smart check it acts on
+		protected.POST("/smart-checks",
+			middleware.RequirePermission(models.PermissionCreateSmartCheque),
+			smartChequeHandler.CreateSmartCheque)
 
-	// Milestone completion endpoint (example)
-	r.POST("/milestones/:id/complete", completeMilestone)
+		protected.GET("/smart-checks/payer",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.ListSmartChequesByPayer)
 
-	log.Println("Orchestration Service starting on :8002")
-	log.Fatal(http.ListenAndServe(":8002", r))
-}
+		protected.GET("/smart-checks/payee",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.ListSmartChequesByPayee)
 
-func handleEnterpriseRegistered(event *messaging.Event) error {
-	log.Printf("Orchestration service handling enterprise registered event: %+v", event)
-	// TODO: Initialize enterprise-specific orchestration workflows
-	return nil
-}
+		protected.GET("/smart-checks/status",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.ListSmartChequesByStatus)
 
-func handleMilestoneCompleted(event *messaging.Event) error {
-	log.Printf("Orchestration service handling milestone completed event: %+v", event)
-	// TODO: Trigger payment release workflow
-	return nil
-}
+		protected.GET("/smart-checks/statistics",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.GetSmartChequeStatistics)
 
-func createSmartCheque(c *gin.Context) {
-	messagingService, exists := middleware.GetService(c)
-	if !exists {
-		_ = c.Error(middleware.NewAppError(http.StatusInternalServerError, "Messaging service not available", nil))
-		return
-	}
+		protected.GET("/smart-checks/:id",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.GetSmartCheque)
 
-	var request struct {
-		PayerID  string  `json:"payer_id" binding:"required"`
-		PayeeID  string  `json:"payee_id" binding:"required"`
-		Amount   float64 `json:"amount" binding:"required"`
-		Currency string  `json:"currency" binding:"required"`
-	}
+		protected.PUT("/smart-checks/:id",
+			middleware.RequirePermission(models.PermissionCreateSmartCheque),
+			smartChequeHandler.UpdateSmartCheque)
 
-	if err := c.ShouldBindJSON(&request); err != nil {
-		_ = c.Error(middleware.NewAppError(http.StatusBadRequest, "Invalid request payload", err))
-		return
-	}
+		protected.DELETE("/smart-checks/:id",
+			middleware.RequirePermission(models.PermissionCreateSmartCheque),
+			smartChequeHandler.DeleteSmartCheque)
 
-	// TODO: Implement actual Smart Check creation logic
-	chequeID := "sc_" + request.PayerID + "_" + request.PayeeID // Simplified for demo
+		protected.GET("/smart-checks/:id/audit-trail",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.GetSmartChequeAuditTrail)
 
-	// Publish Smart Check created event
-	event := messaging.NewSmartChequeCreatedEvent(
-		chequeID,
-		request.PayerID,
-		request.PayeeID,
-		request.Amount,
-		request.Currency,
-	)
-	if err := messagingService.PublishEvent(event); err != nil {
-		log.Printf("Failed to publish Smart Check created event: %v", err)
-	}
+		protected.GET("/smart-checks/:id/endorsements",
+			middleware.RequirePermission(models.PermissionViewSmartCheque),
+			smartChequeHandler.GetSmartChequeEndorsements)
 
-	c.JSON(http.StatusCreated, gin.H{
-		"check_id": chequeID,
-		"payer_id": request.PayerID,
-		"payee_id": request.PayeeID,
-		"amount":   request.Amount,
-		"currency": request.Currency,
-		"status":   "created",
-	})
-}
+		// Endorsement assigns who the milestone payouts go to
+		protected.POST("/smart-checks/:id/endorsements",
+			middleware.RequirePermission(models.PermissionCreateSmartCheque),
+			smartChequeHandler.EndorseSmartCheque)
 
-func completeMilestone(c *gin.Context) {
-	messagingService, exists := middleware.GetService(c)
-	if !exists {
-		_ = c.Error(middleware.NewAppError(http.StatusInternalServerError, "Messaging service not available", nil))
-		return
-	}
+		protected.POST("/smart-checks/:id/endorsements/:endorsementId/acknowledge",
+			middleware.RequirePermission(models.PermissionApprovePayment),
+			smartChequeHandler.AcknowledgeSmartChequeEndorsement)
 
-	milestoneID := c.Param("id")
+		// Settlement moves funds on the XRPL
+		protected.POST("/smart-checks/:id/lock",
+			middleware.RequirePermission(models.PermissionProcessPayment),
+			smartChequeHandler.LockSmartChequeFunds)
 
-	var request struct {
-		SmartChequeID string  `json:"smart_check_id" binding:"required"`
-		Amount        float64 `json:"amount" binding:"required"`
-	}
+		protected.POST("/smart-checks/:id/milestones/:milestoneId/complete",
+			middleware.RequirePermission(models.PermissionApprovePayment),
+			smartChequeHandler.CompleteSmartChequeMilestone)
 
-	if err := c.ShouldBindJSON(&request); err != nil {
-		_ = c.Error(middleware.NewAppError(http.StatusBadRequest, "Invalid request payload", err))
-		return
+		protected.POST("/smart-checks/:id/cancel",
+			middleware.RequirePermission(models.PermissionApprovePayment),
+			smartChequeHandler.CancelSmartCheque)
 	}
 
-	// TODO: Implement actual milestone completion logic
+	log.Println("Orchestration Service starting on :8002")
+	log.Fatal(http.ListenAndServe(":8002", r))
+}
 
-	// Publish milestone completed event
-	event := messaging.NewMilestoneCompletedEvent(
-		milestoneID,
-		request.SmartChequeID,
-		request.Amount,
-	)
-	if err := messagingService.PublishEvent(event); err != nil {
-		log.Printf("Failed to publish milestone completed event: %v", err)
-	}
+func handleEnterpriseRegistered(event *messaging.Event) error {
+	log.Printf("Orchestration service handling enterprise registered event: %+v", event)
+	// TODO: Initialize enterprise-specific orchestration workflows
+	return nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"milestone_id":   milestoneID,
-		"smart_check_id": request.SmartChequeID,
-		"amount":         request.Amount,
-		"status":         "completed",
-	})
+// milestoneCompletedHandler releases the payment of a milestone another service reported completed.
+// Milestones already paid, such as those completed through the API, are acknowledged without paying again.
+func milestoneCompletedHandler(smartChequeXRPLService services.SmartChequeXRPLServiceInterface) func(*messaging.Event) error {
+	return func(event *messaging.Event) error {
+		milestoneID, _ := event.Data["milestone_id"].(string)
+		smartChequeID, _ := event.Data["smart_check_id"].(string)
+		if milestoneID == "" || smartChequeID == "" {
+			log.Printf("Ignoring milestone completed event without a milestone and smart check: %+v", event)
+			return nil
+		}
+
+		err := smartChequeXRPLService.CompleteMilestonePayment(context.Background(), smartChequeID, milestoneID)
+		if errors.Is(err, services.ErrMilestoneAlreadyReleased) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to release payment for milestone %s of smart check %s: %w", milestoneID, smartChequeID, err)
+		}
+
+		log.Printf("Released payment for milestone %s of smart check %s", milestoneID, smartChequeID)
+		return nil
+	}
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/smart-payment-infrastructure/internal/config"
 	"github.com/smart-payment-infrastructure/internal/services"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
 )
 
 func main() {
@@ -19,10 +21,22 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Sandbox mode runs against an in-process ledger simulator instead of a network
+	if xrpl.Mode(cfg.XRPL.Mode) == xrpl.ModeSandbox {
+		sandbox, err := simulator.StartSandbox()
+		if err != nil {
+			log.Fatalf("Failed to start XRPL sandbox: %v", err)
+		}
+		defer sandbox.Close()
+		cfg.XRPL.JSONRPCURL = sandbox.URL
+		cfg.XRPL.NetworkURL = sandbox.WebSocketURL
+	}
+
 	// Initialize XRPL service
 	xrplService := services.NewXRPLService(services.XRPLConfig{
-		NetworkURL: cfg.XRPL.NetworkURL,
+		NetworkURL: cfg.XRPL.JSONRPCURL,
 		TestNet:    cfg.XRPL.TestNet,
+		Mode:       cfg.XRPL.Mode,
 	})
 
 	// Initialize the service
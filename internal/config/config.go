@@ -35,8 +35,12 @@ type JWTConfig struct {
 
 // XRPLConfig represents XRPL configuration
 type XRPLConfig struct {
-	NetworkURL string
+	NetworkURL string // rippled WebSocket endpoint used for ledger subscriptions
+	JSONRPCURL string
 	TestNet    bool
+	Mode       string
+	// TreasuryAddress receives the XRP swept from retired wallets
+	TreasuryAddress string
 }
 
 // Load loads configuration from environment variables with defaults
@@ -58,7 +62,11 @@ func Load() *Config {
 		},
 		XRPL: XRPLConfig{
 			NetworkURL: getEnv("XRPL_NETWORK_URL", "wss://s.altnet.rippletest.net:51233"),
+			JSONRPCURL: getEnv("XRPL_JSONRPC_URL", "https://s.altnet.rippletest.net:51234"),
 			TestNet:    getEnvAsBool("XRPL_TESTNET", true),
+			Mode:       getEnv("XRPL_CLIENT_MODE", "simulator"),
+			// Wallets cannot be retired until a treasury is configured
+			TreasuryAddress: getEnv("XRPL_TREASURY_ADDRESS", ""),
 		},
 	}
 }
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/services"
+)
+
+// DestinationTagHandler handles HTTP requests for shared receiving wallets and their destination tags
+type DestinationTagHandler struct {
+	destinationTagService *services.DestinationTagService
+}
+
+// NewDestinationTagHandler creates a new destination tag handler
+func NewDestinationTagHandler(destinationTagService *services.DestinationTagService) *DestinationTagHandler {
+	return &DestinationTagHandler{
+		destinationTagService: destinationTagService,
+	}
+}
+
+// AllocateDestinationTagRequest asks for an enterprise's tag, or a smart cheque's when SmartChequeID is set
+type AllocateDestinationTagRequest struct {
+	EnterpriseID  uuid.UUID `json:"enterprise_id" binding:"required"`
+	SmartChequeID string    `json:"smart_cheque_id,omitempty"`
+}
+
+// ReleaseQuarantinedDepositRequest names the enterprise a quarantined deposit belongs to
+type ReleaseQuarantinedDepositRequest struct {
+	EnterpriseID uuid.UUID `json:"enterprise_id" binding:"required"`
+}
+
+// ConfigureOmnibusWallet handles making a wallet a shared receiving wallet that requires destination tags
+func (h *DestinationTagHandler) ConfigureOmnibusWallet(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid wallet ID format",
+		})
+		return
+	}
+
+	result, err := h.destinationTagService.ConfigureOmnibusWallet(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to configure shared receiving wallet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Wallet now requires destination tags",
+		"transaction_id": result.TransactionID,
+	})
+}
+
+// AllocateDestinationTag handles allocating an enterprise or smart cheque tag on a shared receiving wallet
+func (h *DestinationTagHandler) AllocateDestinationTag(c *gin.Context) {
+	var req AllocateDestinationTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	address := c.Param("address")
+	var tag *models.DestinationTag
+	var err error
+	if req.SmartChequeID != "" {
+		tag, err = h.destinationTagService.AllocateSmartChequeTag(c.Request.Context(), address, req.EnterpriseID, req.SmartChequeID)
+	} else {
+		tag, err = h.destinationTagService.AllocateEnterpriseTag(c.Request.Context(), address, req.EnterpriseID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to allocate destination tag",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"destination_tag": tag,
+	})
+}
+
+// GetQuarantinedDeposits handles listing deposits awaiting review because no tag routed them
+func (h *DestinationTagHandler) GetQuarantinedDeposits(c *gin.Context) {
+	pagination := ParsePaginationParams(c)
+
+	deposits, err := h.destinationTagService.GetQuarantinedDeposits(c.Request.Context(), pagination.Limit, pagination.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get quarantined deposits",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deposits": deposits,
+		"count":    len(deposits),
+	})
+}
+
+// ReleaseQuarantinedDeposit handles crediting a quarantined deposit to the enterprise it belongs to
+func (h *DestinationTagHandler) ReleaseQuarantinedDeposit(c *gin.Context) {
+	depositID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deposit ID format",
+		})
+		return
+	}
+
+	var req ReleaseQuarantinedDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transaction, err := h.destinationTagService.ReleaseQuarantinedDeposit(c.Request.Context(), depositID, req.EnterpriseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to release quarantined deposit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Deposit credited",
+		"transaction": transaction,
+	})
+}
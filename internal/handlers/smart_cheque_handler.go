@@ -1,27 +1,102 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/smart-payment-infrastructure/internal/middleware"
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/services"
+	"github.com/smart-payment-infrastructure/pkg/messaging"
 )
 
 // SmartChequeHandler handles HTTP requests for smart checks
 type SmartChequeHandler struct {
 	smartChequeService services.SmartChequeServiceInterface
+	xrplService        services.SmartChequeXRPLServiceInterface
+	wallets            SmartChequeWalletResolver
+	networkType        string
+	endorsements       services.SmartChequeEndorsementServiceInterface
+}
+
+// SmartChequeWalletResolver finds the wallet an enterprise pays or is paid from; *services.WalletService satisfies it
+type SmartChequeWalletResolver interface {
+	GetActiveWalletForEnterprise(enterpriseID uuid.UUID, networkType string) (*models.WalletResponse, error)
 }
 
 // NewSmartChequeHandler creates a new smart check handler
 func NewSmartChequeHandler(smartChequeService services.SmartChequeServiceInterface) *SmartChequeHandler {
+	return NewSmartChequeHandlerWithSettlement(smartChequeService, nil, nil, "")
+}
+
+// NewSmartChequeHandlerWithSettlement creates a smart check handler that also locks, releases and
+// cancels funds on the XRPL between the payer's and payee's active wallets on networkType
+func NewSmartChequeHandlerWithSettlement(
+	smartChequeService services.SmartChequeServiceInterface,
+	xrplService services.SmartChequeXRPLServiceInterface,
+	wallets SmartChequeWalletResolver,
+	networkType string,
+) *SmartChequeHandler {
 	return &SmartChequeHandler{
 		smartChequeService: smartChequeService,
+		xrplService:        xrplService,
+		wallets:            wallets,
+		networkType:        networkType,
 	}
 }
 
+// SetEndorsementService lets payees endorse the proceeds of their smart checks through the handler
+func (h *SmartChequeHandler) SetEndorsementService(endorsements services.SmartChequeEndorsementServiceInterface) {
+	h.endorsements = endorsements
+}
+
+// LockSmartChequeFundsRequest configures how a smart check's funds are locked
+type LockSmartChequeFundsRequest struct {
+	// ValidForHours is how long an XRPL Check stays cashable; 30 days when zero
+	ValidForHours int `json:"valid_for_hours,omitempty"`
+}
+
+// CancelSmartChequeRequest gives the reason a smart check's locked funds are returned
+type CancelSmartChequeRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// EndorseSmartChequeRequest assigns the proceeds of a smart check's milestones to another enterprise.
+// The endorser is the caller's enterprise.
+type EndorseSmartChequeRequest struct {
+	EndorseeID string `json:"endorsee_id" binding:"required"`
+	// MilestoneIDs are the milestones assigned; every unpaid milestone the endorser holds when empty
+	MilestoneIDs []string `json:"milestone_ids,omitempty"`
+	Notes        string   `json:"notes,omitempty"`
+}
+
+// AcknowledgeEndorsementRequest is the payer, the caller's enterprise, accepting or declining an endorsement
+type AcknowledgeEndorsementRequest struct {
+	Accept bool   `json:"accept"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// smartChequeParty is a role an enterprise plays in a smart check
+type smartChequeParty int
+
+const (
+	partyPayer smartChequeParty = iota
+	partyPayee
+	// partyHolder is an enterprise holding the proceeds of a milestone endorsed to it
+	partyHolder
+)
+
+// defaultCheckValidity is how long an XRPL Check stays cashable when the request does not say
+const defaultCheckValidity = 30 * 24 * time.Hour
+
 // CreateSmartCheque creates a new smart check
 // @Summary Create a new smart check
 // @Description Create a new smart check with the provided details
@@ -31,6 +106,7 @@ func NewSmartChequeHandler(smartChequeService services.SmartChequeServiceInterfa
 // @Param smartCheque body services.CreateSmartChequeRequest true "Smart Check details"
 // @Success 201 {object} models.SmartCheque
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /smart-cheques [post]
 func (h *SmartChequeHandler) CreateSmartCheque(c *gin.Context) {
@@ -39,6 +115,14 @@ func (h *SmartChequeHandler) CreateSmartCheque(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	enterpriseID, ok := actingEnterprise(c)
+	if !ok {
+		return
+	}
+	if request.PayerID != enterpriseID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - smart checks can only be issued by their payer"})
+		return
+	}
 
 	smartCheque, err := h.smartChequeService.CreateSmartCheque(c.Request.Context(), &request)
 	if err != nil {
@@ -46,6 +130,14 @@ func (h *SmartChequeHandler) CreateSmartCheque(c *gin.Context) {
 		return
 	}
 
+	publishEvent(c, messaging.NewSmartChequeCreatedEvent(
+		smartCheque.ID,
+		smartCheque.PayerID,
+		smartCheque.PayeeID,
+		smartCheque.Amount.String(),
+		string(smartCheque.Currency),
+	))
+
 	c.JSON(http.StatusCreated, smartCheque)
 }
 
@@ -57,6 +149,7 @@ func (h *SmartChequeHandler) CreateSmartCheque(c *gin.Context) {
 // @Param id path string true "Smart Check ID"
 // @Success 200 {object} models.SmartCheque
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /smart-cheques/{id} [get]
@@ -67,9 +160,8 @@ func (h *SmartChequeHandler) GetSmartCheque(c *gin.Context) {
 		return
 	}
 
-	smartCheque, err := h.smartChequeService.GetSmartCheque(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer, partyPayee, partyHolder) {
 		return
 	}
 
@@ -86,7 +178,9 @@ func (h *SmartChequeHandler) GetSmartCheque(c *gin.Context) {
 // @Param smartCheque body services.UpdateSmartChequeRequest true "Smart Check update details"
 // @Success 200 {object} models.SmartCheque
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /smart-cheques/{id} [put]
 func (h *SmartChequeHandler) UpdateSmartCheque(c *gin.Context) {
@@ -102,7 +196,20 @@ func (h *SmartChequeHandler) UpdateSmartCheque(c *gin.Context) {
 		return
 	}
 
+	current, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, current, partyPayer) {
+		return
+	}
+	if request.PayerID != nil && *request.PayerID != current.PayerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - a smart check cannot be handed to another payer"})
+		return
+	}
+
 	smartCheque, err := h.smartChequeService.UpdateSmartCheque(c.Request.Context(), id, &request)
+	if errors.Is(err, services.ErrSmartChequeLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -119,7 +226,9 @@ func (h *SmartChequeHandler) UpdateSmartCheque(c *gin.Context) {
 // @Param id path string true "Smart Check ID"
 // @Success 204
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /smart-cheques/{id} [delete]
 func (h *SmartChequeHandler) DeleteSmartCheque(c *gin.Context) {
@@ -129,7 +238,17 @@ func (h *SmartChequeHandler) DeleteSmartCheque(c *gin.Context) {
 		return
 	}
 
-	if err := h.smartChequeService.DeleteSmartCheque(c.Request.Context(), id); err != nil {
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer) {
+		return
+	}
+
+	err := h.smartChequeService.DeleteSmartCheque(c.Request.Context(), id)
+	if errors.Is(err, services.ErrSmartChequeLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
@@ -240,6 +359,7 @@ func (h *SmartChequeHandler) ListSmartChequesByStatus(c *gin.Context) {
 // @Param status query string true "New Status"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /smart-cheques/{id}/status [put]
@@ -256,6 +376,11 @@ func (h *SmartChequeHandler) UpdateSmartChequeStatus(c *gin.Context) {
 		return
 	}
 
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer) {
+		return
+	}
+
 	status := models.SmartChequeStatus(statusStr)
 	if err := h.smartChequeService.UpdateSmartChequeStatus(c.Request.Context(), id, status); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -282,3 +407,460 @@ func (h *SmartChequeHandler) GetSmartChequeStatistics(c *gin.Context) {
 
 	c.JSON(http.StatusOK, statistics)
 }
+
+// LockSmartChequeFunds locks a smart check's funds on the XRPL
+// @Summary Lock smart check funds
+// @Description Lock the amount of a created smart check from the payer's wallet into its escrow or XRPL Check, or pay a direct smart check outright
+// @Tags SmartCheques
+// @Accept json
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param lock body LockSmartChequeFundsRequest false "Lock options"
+// @Success 200 {object} models.SmartCheque
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/lock [post]
+func (h *SmartChequeHandler) LockSmartChequeFunds(c *gin.Context) {
+	if !h.settlementAvailable(c) {
+		return
+	}
+
+	var request LockSmartChequeFundsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer) {
+		return
+	}
+	if smartCheque.Status != models.SmartChequeStatusCreated {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("smart check is %s; only created smart checks can be locked", smartCheque.Status)})
+		return
+	}
+
+	payerWallet, err := h.activeWallet(smartCheque.PayerID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Payer has no active wallet", "details": err.Error()})
+		return
+	}
+	payeeWallet, err := h.activeWallet(smartCheque.PayeeID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Payee has no active wallet", "details": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	switch smartCheque.Settlement() {
+	case models.SettlementModeXRPLCheck:
+		validFor := defaultCheckValidity
+		if request.ValidForHours > 0 {
+			validFor = time.Duration(request.ValidForHours) * time.Hour
+		}
+		err = h.xrplService.IssueCheckForSmartCheque(ctx, smartCheque.ID, payerWallet, payeeWallet, validFor)
+	case models.SettlementModeDirect:
+		err = h.xrplService.PaySmartChequeDirect(ctx, smartCheque.ID, payerWallet, payeeWallet)
+	default:
+		err = h.xrplService.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payerWallet, payeeWallet)
+	}
+	if err != nil {
+		c.JSON(settlementErrorStatus(err), gin.H{"error": "Failed to lock smart check funds", "details": err.Error()})
+		return
+	}
+
+	h.respondWithSmartCheque(c, smartCheque.ID, http.StatusOK)
+}
+
+// CompleteSmartChequeMilestone submits the release of a milestone's payment to the payee
+// @Summary Complete a smart check milestone
+// @Description Submit the release of a completed milestone's escrowed payment to the payee. The smart check changes status once the ledger validates the release.
+// @Tags SmartCheques
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param milestoneId path string true "Milestone ID"
+// @Success 202 {object} models.SmartCheque
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/milestones/{milestoneId}/complete [post]
+func (h *SmartChequeHandler) CompleteSmartChequeMilestone(c *gin.Context) {
+	if !h.settlementAvailable(c) {
+		return
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer) {
+		return
+	}
+
+	milestoneID := c.Param("milestoneId")
+	var milestone *models.Milestone
+	for i := range smartCheque.Milestones {
+		if smartCheque.Milestones[i].ID == milestoneID {
+			milestone = &smartCheque.Milestones[i]
+			break
+		}
+	}
+	if milestone == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("milestone %s not found in smart check", milestoneID)})
+		return
+	}
+
+	if err := h.xrplService.CompleteMilestonePayment(c.Request.Context(), smartCheque.ID, milestoneID); err != nil {
+		c.JSON(settlementErrorStatus(err), gin.H{"error": "Failed to release milestone payment", "details": err.Error()})
+		return
+	}
+
+	// Subscribers learn of the release; the orchestration service's own handler sees it already paid
+	publishEvent(c, messaging.NewMilestoneCompletedEvent(milestoneID, smartCheque.ID, milestone.Amount.String()))
+
+	// The release is submitted; the ledger confirmer resolves the escrow once it validates
+	h.respondWithSmartCheque(c, smartCheque.ID, http.StatusAccepted)
+}
+
+// CancelSmartCheque returns a smart check's locked funds to the payer
+// @Summary Cancel a smart check
+// @Description Cancel the escrow or XRPL Check holding a smart check's funds
+// @Tags SmartCheques
+// @Accept json
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param cancel body CancelSmartChequeRequest true "Cancellation reason"
+// @Success 200 {object} models.SmartCheque
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/cancel [post]
+func (h *SmartChequeHandler) CancelSmartCheque(c *gin.Context) {
+	if !h.settlementAvailable(c) {
+		return
+	}
+
+	var request CancelSmartChequeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer, partyPayee) {
+		return
+	}
+	if smartCheque.Status == models.SmartChequeStatusCreated {
+		c.JSON(http.StatusConflict, gin.H{"error": "smart check has no locked funds to cancel; delete it instead"})
+		return
+	}
+
+	var err error
+	if smartCheque.Settlement() == models.SettlementModeXRPLCheck {
+		err = h.xrplService.CancelSmartChequeCheck(c.Request.Context(), smartCheque.ID, request.Reason, request.Notes)
+	} else {
+		err = h.xrplService.CancelSmartChequeEscrowWithReason(c.Request.Context(), smartCheque.ID, request.Reason, request.Notes)
+	}
+	if err != nil {
+		c.JSON(settlementErrorStatus(err), gin.H{"error": "Failed to cancel smart check", "details": err.Error()})
+		return
+	}
+
+	h.respondWithSmartCheque(c, smartCheque.ID, http.StatusOK)
+}
+
+// GetSmartChequeAuditTrail retrieves the audit trail of a smart check
+// @Summary Get smart check audit trail
+// @Description List the audit log entries recorded for a smart check
+// @Tags SmartCheques
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param limit query int false "Limit (default: 50)"
+// @Param offset query int false "Offset (default: 0)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/audit-trail [get]
+func (h *SmartChequeHandler) GetSmartChequeAuditTrail(c *gin.Context) {
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer, partyPayee, partyHolder) {
+		return
+	}
+	pagination := ParsePaginationParams(c)
+
+	entries, err := h.smartChequeService.GetAuditTrail(c.Request.Context(), smartCheque.ID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit_trail": entries,
+		"count":       len(entries),
+	})
+}
+
+// EndorseSmartCheque assigns the proceeds of a smart check's unpaid milestones to another enterprise
+// @Summary Endorse a smart check
+// @Description Assign all or some of a smart check's unpaid milestone proceeds from their holder to another enterprise, paid into its active wallet. The endorsement waits for the payer's acknowledgement when the contract requires it.
+// @Tags SmartCheques
+// @Accept json
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param endorsement body EndorseSmartChequeRequest true "Endorsement"
+// @Success 201 {object} models.SmartChequeEndorsement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/endorsements [post]
+func (h *SmartChequeHandler) EndorseSmartCheque(c *gin.Context) {
+	if !h.endorsementAvailable(c) {
+		return
+	}
+
+	var request EndorseSmartChequeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayee, partyHolder) {
+		return
+	}
+	endorserID, _ := actingEnterprise(c)
+
+	endorseeWallet, err := h.activeWallet(request.EndorseeID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Endorsee has no active wallet", "details": err.Error()})
+		return
+	}
+
+	endorsement, err := h.endorsements.Endorse(c.Request.Context(), &services.EndorseSmartChequeRequest{
+		SmartChequeID:   smartCheque.ID,
+		EndorserID:      endorserID,
+		EndorseeID:      request.EndorseeID,
+		EndorseeAddress: endorseeWallet,
+		MilestoneIDs:    request.MilestoneIDs,
+		Notes:           request.Notes,
+	})
+	if err != nil {
+		c.JSON(endorsementErrorStatus(err), gin.H{"error": "Failed to endorse smart check", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, endorsement)
+}
+
+// AcknowledgeSmartChequeEndorsement records the payer accepting or declining an endorsement
+// @Summary Acknowledge a smart check endorsement
+// @Description Accept or decline an endorsement waiting for the payer's acknowledgement; an accepted endorsement takes effect
+// @Tags SmartCheques
+// @Accept json
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Param endorsementId path string true "Endorsement ID"
+// @Param acknowledgement body AcknowledgeEndorsementRequest true "Acknowledgement"
+// @Success 200 {object} models.SmartChequeEndorsement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/endorsements/{endorsementId}/acknowledge [post]
+func (h *SmartChequeHandler) AcknowledgeSmartChequeEndorsement(c *gin.Context) {
+	if !h.endorsementAvailable(c) {
+		return
+	}
+
+	endorsementID, err := uuid.Parse(c.Param("endorsementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endorsement ID"})
+		return
+	}
+	var request AcknowledgeEndorsementRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer) {
+		return
+	}
+
+	endorsement, err := h.endorsements.AcknowledgeEndorsement(c.Request.Context(), smartCheque.ID, endorsementID, smartCheque.PayerID, request.Accept, request.Notes)
+	if err != nil {
+		c.JSON(endorsementErrorStatus(err), gin.H{"error": "Failed to acknowledge endorsement", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, endorsement)
+}
+
+// GetSmartChequeEndorsements lists the chain of endorsements of a smart check
+// @Summary Get smart check endorsements
+// @Description List a smart check's endorsements in the order they were made
+// @Tags SmartCheques
+// @Produce json
+// @Param id path string true "Smart Check ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /smart-cheques/{id}/endorsements [get]
+func (h *SmartChequeHandler) GetSmartChequeEndorsements(c *gin.Context) {
+	if !h.endorsementAvailable(c) {
+		return
+	}
+
+	smartCheque, ok := h.getSmartCheque(c)
+	if !ok || !authorizeParty(c, smartCheque, partyPayer, partyPayee, partyHolder) {
+		return
+	}
+
+	chain, err := h.endorsements.GetEndorsementChain(c.Request.Context(), smartCheque.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"endorsements": chain,
+		"count":        len(chain),
+	})
+}
+
+// settlementAvailable reports whether the handler can move funds, answering the request when it cannot
+func (h *SmartChequeHandler) settlementAvailable(c *gin.Context) bool {
+	if h.xrplService == nil || h.wallets == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "smart check settlement is not configured"})
+		return false
+	}
+	return true
+}
+
+// endorsementAvailable reports whether the handler can record endorsements, answering the request when it cannot
+func (h *SmartChequeHandler) endorsementAvailable(c *gin.Context) bool {
+	if h.endorsements == nil || h.wallets == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "smart check endorsement is not configured"})
+		return false
+	}
+	return true
+}
+
+// getSmartCheque loads the smart check named by the id path parameter, answering 404 when it does not exist
+func (h *SmartChequeHandler) getSmartCheque(c *gin.Context) (*models.SmartCheque, bool) {
+	smartCheque, err := h.smartChequeService.GetSmartCheque(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return smartCheque, true
+}
+
+// actingEnterprise returns the enterprise the caller's token acts for, answering 403 when it acts for none
+func actingEnterprise(c *gin.Context) (string, bool) {
+	if value, exists := c.Get("enterprise_id"); exists {
+		if enterpriseID, ok := value.(*uuid.UUID); ok && enterpriseID != nil {
+			return enterpriseID.String(), true
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - the caller does not act for an enterprise"})
+	return "", false
+}
+
+// authorizeParty reports whether the caller's enterprise plays one of the given parts in a smart
+// check, answering 403 when it does not
+func authorizeParty(c *gin.Context, smartCheque *models.SmartCheque, parties ...smartChequeParty) bool {
+	enterpriseID, ok := actingEnterprise(c)
+	if !ok {
+		return false
+	}
+	for _, party := range parties {
+		if playsParty(smartCheque, enterpriseID, party) {
+			return true
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - the caller's enterprise is not a party to this smart check"})
+	return false
+}
+
+// playsParty reports whether an enterprise plays a part in a smart check
+func playsParty(smartCheque *models.SmartCheque, enterpriseID string, party smartChequeParty) bool {
+	switch party {
+	case partyPayer:
+		return smartCheque.PayerID == enterpriseID
+	case partyPayee:
+		return smartCheque.PayeeID == enterpriseID
+	case partyHolder:
+		for i := range smartCheque.Milestones {
+			if smartCheque.Milestones[i].Holder != nil && smartCheque.Milestones[i].Holder.EnterpriseID == enterpriseID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// respondWithSmartCheque answers with status and the smart check as it was stored after a settlement step
+func (h *SmartChequeHandler) respondWithSmartCheque(c *gin.Context, id string, status int) {
+	smartCheque, err := h.smartChequeService.GetSmartCheque(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, smartCheque)
+}
+
+// activeWallet returns the address of an enterprise's active wallet on the handler's network
+func (h *SmartChequeHandler) activeWallet(enterpriseID string) (string, error) {
+	id, err := uuid.Parse(enterpriseID)
+	if err != nil {
+		return "", fmt.Errorf("invalid enterprise ID %q: %w", enterpriseID, err)
+	}
+	wallet, err := h.wallets.GetActiveWalletForEnterprise(id, h.networkType)
+	if err != nil {
+		return "", err
+	}
+	return wallet.Address, nil
+}
+
+// settlementErrorStatus maps a settlement failure to the HTTP status it is answered with
+func settlementErrorStatus(err error) int {
+	if errors.Is(err, services.ErrSettlementMode) || errors.Is(err, services.ErrMilestoneAlreadyReleased) ||
+		errors.Is(err, services.ErrInvalidStatusTransition) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// endorsementErrorStatus maps an endorsement failure to the HTTP status it is answered with
+func endorsementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrEndorsementNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrEndorsementNotAllowed):
+		return http.StatusConflict
+	}
+	return settlementErrorStatus(err)
+}
+
+// publishEvent publishes an event when the request carries a messaging service; failures are only logged
+func publishEvent(c *gin.Context, event *messaging.Event) {
+	messagingService, exists := middleware.GetService(c)
+	if !exists {
+		return
+	}
+	if err := messagingService.PublishEvent(event); err != nil {
+		log.Printf("Failed to publish %s event: %v", event.Type, err)
+	}
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/services"
+)
+
+// memorySmartChequeService serves smart checks from memory; unused methods panic through the nil interface
+type memorySmartChequeService struct {
+	services.SmartChequeServiceInterface
+	cheques map[string]*models.SmartCheque
+}
+
+func (s *memorySmartChequeService) GetSmartCheque(_ context.Context, id string) (*models.SmartCheque, error) {
+	smartCheque, ok := s.cheques[id]
+	if !ok {
+		return nil, errors.New("smart check not found")
+	}
+	copied := *smartCheque
+	copied.Milestones = append([]models.Milestone(nil), smartCheque.Milestones...)
+	return &copied, nil
+}
+
+func (s *memorySmartChequeService) UpdateSmartCheque(_ context.Context, id string, request *services.UpdateSmartChequeRequest) (*models.SmartCheque, error) {
+	smartCheque := s.cheques[id]
+	if request.PayeeID != nil {
+		smartCheque.PayeeID = *request.PayeeID
+	}
+	return smartCheque, nil
+}
+
+func (s *memorySmartChequeService) DeleteSmartCheque(_ context.Context, id string) error {
+	if smartCheque := s.cheques[id]; smartCheque != nil && smartCheque.Status != models.SmartChequeStatusCreated {
+		return services.ErrSmartChequeLocked
+	}
+	delete(s.cheques, id)
+	return nil
+}
+
+func (s *memorySmartChequeService) GetAuditTrail(_ context.Context, id string, _, _ int) ([]services.AuditLogEntry, error) {
+	return []services.AuditLogEntry{{ID: "entry-1", SmartChequeID: id, Action: "smart_check_created"}}, nil
+}
+
+// recordingSettlement settles escrowed smart checks held by a memorySmartChequeService
+type recordingSettlement struct {
+	services.SmartChequeXRPLServiceInterface
+	cheques *memorySmartChequeService
+	escrows map[string][2]string
+}
+
+func (s *recordingSettlement) CreateEscrowForSmartCheque(_ context.Context, id, payer, payee string) error {
+	s.escrows[id] = [2]string{payer, payee}
+	s.cheques.cheques[id].Status = models.SmartChequeStatusLocked
+	return nil
+}
+
+func (s *recordingSettlement) CompleteMilestonePayment(_ context.Context, id, milestoneID string) error {
+	for i, milestone := range s.cheques.cheques[id].Milestones {
+		if milestone.ID != milestoneID {
+			continue
+		}
+		if milestone.Status == models.MilestoneStatusVerified {
+			return services.ErrMilestoneAlreadyReleased
+		}
+		s.cheques.cheques[id].Milestones[i].Status = models.MilestoneStatusVerified
+	}
+	return nil
+}
+
+func (s *recordingSettlement) CancelSmartChequeEscrowWithReason(_ context.Context, id, reason, _ string) error {
+	s.cheques.cheques[id].Status = models.SmartChequeStatusCancelled
+	return nil
+}
+
+type walletsByEnterprise map[uuid.UUID]string
+
+func (w walletsByEnterprise) GetActiveWalletForEnterprise(enterpriseID uuid.UUID, _ string) (*models.WalletResponse, error) {
+	address, ok := w[enterpriseID]
+	if !ok {
+		return nil, errors.New("no active wallet")
+	}
+	return &models.WalletResponse{EnterpriseID: enterpriseID, Address: address}, nil
+}
+
+func TestSmartChequeHandler_SettlementLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payer, payee, unfundedPayer := uuid.New(), uuid.New(), uuid.New()
+	cheques := &memorySmartChequeService{cheques: map[string]*models.SmartCheque{
+		"cheque-1": {
+			ID:       "cheque-1",
+			PayerID:  payer.String(),
+			PayeeID:  payee.String(),
+			Amount:   models.MustParseMoney("100", models.CurrencyUSDT),
+			Currency: models.CurrencyUSDT,
+			Status:   models.SmartChequeStatusCreated,
+			Milestones: []models.Milestone{
+				{ID: "m1", Amount: models.MustParseMoney("100", models.CurrencyUSDT), Status: models.MilestoneStatusPending},
+			},
+		},
+		"unfunded": {
+			ID:      "unfunded",
+			PayerID: unfundedPayer.String(),
+			PayeeID: payee.String(),
+			Status:  models.SmartChequeStatusCreated,
+		},
+	}}
+	settlement := &recordingSettlement{cheques: cheques, escrows: make(map[string][2]string)}
+	handler := NewSmartChequeHandlerWithSettlement(cheques, settlement, walletsByEnterprise{payer: "rPayer", payee: "rPayee"}, "testnet")
+
+	r := gin.New()
+	r.Use(actAs)
+	r.POST("/smart-checks/:id/lock", handler.LockSmartChequeFunds)
+	r.POST("/smart-checks/:id/milestones/:milestoneId/complete", handler.CompleteSmartChequeMilestone)
+	r.POST("/smart-checks/:id/cancel", handler.CancelSmartCheque)
+	r.GET("/smart-checks/:id/audit-trail", handler.GetSmartChequeAuditTrail)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Enterprise-ID", payer.String())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	doAs := func(as uuid.UUID, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Enterprise-ID", as.String())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Only the payer moves its funds
+	assert.Equal(t, http.StatusForbidden, doAs(payee, "POST", "/smart-checks/cheque-1/lock", "").Code)
+	assert.Equal(t, http.StatusForbidden, doAs(uuid.New(), "POST", "/smart-checks/cheque-1/lock", "").Code)
+	assert.Empty(t, settlement.escrows)
+
+	// Funds are locked between the parties' active wallets
+	w := do("POST", "/smart-checks/cheque-1/lock", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, [2]string{"rPayer", "rPayee"}, settlement.escrows["cheque-1"])
+	var locked models.SmartCheque
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &locked))
+	assert.Equal(t, models.SmartChequeStatusLocked, locked.Status)
+
+	assert.Equal(t, http.StatusConflict, do("POST", "/smart-checks/cheque-1/lock", "").Code, "funds are locked once")
+	assert.Equal(t, http.StatusUnprocessableEntity, doAs(unfundedPayer, "POST", "/smart-checks/unfunded/lock", "").Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/smart-checks/missing/lock", "").Code)
+
+	// A milestone's payment is submitted for release once
+	assert.Equal(t, http.StatusNotFound, do("POST", "/smart-checks/cheque-1/milestones/m9/complete", "").Code)
+	assert.Equal(t, http.StatusForbidden, doAs(payee, "POST", "/smart-checks/cheque-1/milestones/m1/complete", "").Code)
+	w = do("POST", "/smart-checks/cheque-1/milestones/m1/complete", "")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusConflict, do("POST", "/smart-checks/cheque-1/milestones/m1/complete", "").Code)
+
+	// Cancelling needs a reason and locked funds
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/smart-checks/cheque-1/cancel", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, doAs(unfundedPayer, "POST", "/smart-checks/unfunded/cancel", `{"reason":"payer_request"}`).Code)
+	assert.Equal(t, http.StatusForbidden, doAs(uuid.New(), "POST", "/smart-checks/cheque-1/cancel", `{"reason":"payer_request"}`).Code)
+	assert.Equal(t, http.StatusOK, doAs(payee, "POST", "/smart-checks/cheque-1/cancel", `{"reason":"payee_declined"}`).Code)
+
+	assert.Equal(t, http.StatusForbidden, doAs(uuid.New(), "GET", "/smart-checks/cheque-1/audit-trail", "").Code)
+	w = do("GET", "/smart-checks/cheque-1/audit-trail", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var trail struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trail))
+	assert.Equal(t, 1, trail.Count)
+
+	// Without settlement services the handler still serves records but refuses to move funds
+	readOnly := NewSmartChequeHandler(cheques)
+	r2 := gin.New()
+	r2.POST("/smart-checks/:id/lock", readOnly.LockSmartChequeFunds)
+	req, _ := http.NewRequest("POST", "/smart-checks/cheque-1/lock", nil)
+	req.Header.Set("X-Enterprise-ID", payer.String())
+	w = httptest.NewRecorder()
+	r2.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// recordingEndorsements records endorsements of smart checks in memory
+type recordingEndorsements struct {
+	chain []*models.SmartChequeEndorsement
+}
+
+func (e *recordingEndorsements) Endorse(_ context.Context, request *services.EndorseSmartChequeRequest) (*models.SmartChequeEndorsement, error) {
+	if request.EndorserID == request.EndorseeID {
+		return nil, services.ErrEndorsementNotAllowed
+	}
+	endorsement := &models.SmartChequeEndorsement{
+		ID:              uuid.New(),
+		SmartChequeID:   request.SmartChequeID,
+		Sequence:        len(e.chain) + 1,
+		EndorserID:      request.EndorserID,
+		EndorseeID:      request.EndorseeID,
+		EndorseeAddress: request.EndorseeAddress,
+		MilestoneIDs:    request.MilestoneIDs,
+		Status:          models.EndorsementStatusPendingAcknowledgement,
+	}
+	e.chain = append(e.chain, endorsement)
+	return endorsement, nil
+}
+
+func (e *recordingEndorsements) AcknowledgeEndorsement(_ context.Context, smartChequeID string, endorsementID uuid.UUID, _ string, accept bool, _ string) (*models.SmartChequeEndorsement, error) {
+	for _, endorsement := range e.chain {
+		if endorsement.ID == endorsementID && endorsement.SmartChequeID == smartChequeID {
+			endorsement.Status = models.EndorsementStatusDeclined
+			if accept {
+				endorsement.Status = models.EndorsementStatusEffective
+			}
+			return endorsement, nil
+		}
+	}
+	return nil, services.ErrEndorsementNotFound
+}
+
+func (e *recordingEndorsements) GetEndorsementChain(_ context.Context, _ string) ([]*models.SmartChequeEndorsement, error) {
+	return e.chain, nil
+}
+
+// actAs stands in for the auth middleware, acting for the enterprise named by the X-Enterprise-ID header
+func actAs(c *gin.Context) {
+	if enterpriseID, err := uuid.Parse(c.GetHeader("X-Enterprise-ID")); err == nil {
+		c.Set("enterprise_id", &enterpriseID)
+	}
+}
+
+func TestSmartChequeHandler_Endorsement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payer, payee, financier := uuid.New(), uuid.New(), uuid.New()
+	cheques := &memorySmartChequeService{cheques: map[string]*models.SmartCheque{
+		"cheque-1": {ID: "cheque-1", PayerID: payer.String(), PayeeID: payee.String(), Status: models.SmartChequeStatusLocked},
+	}}
+	handler := NewSmartChequeHandlerWithSettlement(cheques, &recordingSettlement{cheques: cheques}, walletsByEnterprise{payee: "rPayee", financier: "rFinancier"}, "testnet")
+
+	r := gin.New()
+	r.Use(actAs)
+	r.POST("/smart-checks/:id/endorsements", handler.EndorseSmartCheque)
+	r.GET("/smart-checks/:id/endorsements", handler.GetSmartChequeEndorsements)
+	r.POST("/smart-checks/:id/endorsements/:endorsementId/acknowledge", handler.AcknowledgeSmartChequeEndorsement)
+	do := func(as uuid.UUID, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if as != uuid.Nil {
+			req.Header.Set("X-Enterprise-ID", as.String())
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Endorsement is refused until the handler has an endorsement service
+	body := `{"endorsee_id":"` + financier.String() + `"}`
+	assert.Equal(t, http.StatusNotImplemented, do(payee, "POST", "/smart-checks/cheque-1/endorsements", body).Code)
+	endorsements := &recordingEndorsements{}
+	handler.SetEndorsementService(endorsements)
+
+	// Only the payee or a holder of the proceeds endorses them, whatever the body claims
+	assert.Equal(t, http.StatusForbidden, do(uuid.Nil, "POST", "/smart-checks/cheque-1/endorsements", body).Code)
+	assert.Equal(t, http.StatusForbidden, do(payer, "POST", "/smart-checks/cheque-1/endorsements", body).Code)
+	assert.Equal(t, http.StatusForbidden, do(financier, "POST", "/smart-checks/cheque-1/endorsements",
+		`{"endorser_id":"`+payee.String()+`","endorsee_id":"`+financier.String()+`"}`).Code)
+	assert.Empty(t, endorsements.chain)
+
+	// The endorsee is paid into its active wallet
+	w := do(payee, "POST", "/smart-checks/cheque-1/endorsements", body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var endorsement models.SmartChequeEndorsement
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &endorsement))
+	assert.Equal(t, "rFinancier", endorsement.EndorseeAddress)
+	assert.Equal(t, payee.String(), endorsement.EndorserID)
+
+	assert.Equal(t, http.StatusBadRequest, do(payee, "POST", "/smart-checks/cheque-1/endorsements", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(payee, "POST", "/smart-checks/missing/endorsements", body).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(payee, "POST", "/smart-checks/cheque-1/endorsements",
+		`{"endorsee_id":"`+uuid.New().String()+`"}`).Code)
+	assert.Equal(t, http.StatusConflict, do(payee, "POST", "/smart-checks/cheque-1/endorsements",
+		`{"endorsee_id":"`+payee.String()+`"}`).Code)
+
+	// The payer acknowledges it; nobody else can
+	acknowledge := "/smart-checks/cheque-1/endorsements/" + endorsement.ID.String() + "/acknowledge"
+	assert.Equal(t, http.StatusBadRequest, do(payer, "POST", "/smart-checks/cheque-1/endorsements/not-an-id/acknowledge", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(payer, "POST", "/smart-checks/cheque-1/endorsements/"+uuid.New().String()+"/acknowledge", `{}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(payee, "POST", acknowledge, `{"accept":true}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(financier, "POST", acknowledge, `{"payer_id":"`+payer.String()+`","accept":true}`).Code)
+	assert.Equal(t, models.EndorsementStatusPendingAcknowledgement, endorsements.chain[0].Status)
+	w = do(payer, "POST", acknowledge, `{"accept":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &endorsement))
+	assert.Equal(t, models.EndorsementStatusEffective, endorsement.Status)
+
+	w = do(payer, "GET", "/smart-checks/cheque-1/endorsements", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var chain struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, 1, chain.Count)
+}
+
+func TestSmartChequeHandler_PartyOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payer, payee, holder, outsider := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	cheques := &memorySmartChequeService{cheques: map[string]*models.SmartCheque{
+		"cheque-1": {
+			ID: "cheque-1", PayerID: payer.String(), PayeeID: payee.String(), Status: models.SmartChequeStatusCreated,
+			Milestones: []models.Milestone{{ID: "m1", Holder: &models.MilestoneHolder{EnterpriseID: holder.String(), Address: "rHolder"}}},
+		},
+	}}
+	handler := NewSmartChequeHandler(cheques)
+
+	r := gin.New()
+	r.Use(actAs)
+	r.POST("/smart-checks", handler.CreateSmartCheque)
+	r.GET("/smart-checks/:id", handler.GetSmartCheque)
+	r.PUT("/smart-checks/:id", handler.UpdateSmartCheque)
+	r.DELETE("/smart-checks/:id", handler.DeleteSmartCheque)
+	do := func(as uuid.UUID, method, path, body string) int {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if as != uuid.Nil {
+			req.Header.Set("X-Enterprise-ID", as.String())
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Every party reads the smart check; nobody else does
+	for _, party := range []uuid.UUID{payer, payee, holder} {
+		assert.Equal(t, http.StatusOK, do(party, "GET", "/smart-checks/cheque-1", ""))
+	}
+	assert.Equal(t, http.StatusForbidden, do(outsider, "GET", "/smart-checks/cheque-1", ""))
+	assert.Equal(t, http.StatusForbidden, do(uuid.Nil, "GET", "/smart-checks/cheque-1", ""))
+
+	// Only the payer edits or deletes it, and it cannot be handed to another payer
+	redirect := `{"payee_id":"` + outsider.String() + `"}`
+	assert.Equal(t, http.StatusForbidden, do(payee, "PUT", "/smart-checks/cheque-1", redirect))
+	assert.Equal(t, http.StatusForbidden, do(outsider, "PUT", "/smart-checks/cheque-1", redirect))
+	assert.Equal(t, http.StatusForbidden, do(payer, "PUT", "/smart-checks/cheque-1", `{"payer_id":"`+outsider.String()+`"}`))
+	assert.Equal(t, payee.String(), cheques.cheques["cheque-1"].PayeeID)
+	assert.Equal(t, http.StatusForbidden, do(holder, "DELETE", "/smart-checks/cheque-1", ""))
+
+	// A funded smart check is kept, so its escrow can still be settled
+	cheques.cheques["cheque-1"].Status = models.SmartChequeStatusLocked
+	assert.Equal(t, http.StatusConflict, do(payer, "DELETE", "/smart-checks/cheque-1", ""))
+	assert.Contains(t, cheques.cheques, "cheque-1")
+	cheques.cheques["cheque-1"].Status = models.SmartChequeStatusCreated
+	assert.Equal(t, http.StatusNoContent, do(payer, "DELETE", "/smart-checks/cheque-1", ""))
+
+	// A smart check is issued by its payer
+	assert.Equal(t, http.StatusForbidden, do(outsider, "POST", "/smart-checks",
+		`{"payer_id":"`+payer.String()+`","payee_id":"`+outsider.String()+`","amount":"10","currency":"USDT"}`))
+}
@@ -209,6 +209,35 @@ func (h *WalletHandler) SuspendWallet(c *gin.Context) {
 	})
 }
 
+// RetireWallet handles deleting a deactivated wallet's XRPL account and sweeping its XRP to treasury
+func (h *WalletHandler) RetireWallet(c *gin.Context) {
+	walletIDStr := c.Param("id")
+	walletID, err := uuid.Parse(walletIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid wallet ID format",
+		})
+		return
+	}
+
+	result, err := h.walletService.RetireWallet(walletID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retire wallet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "Wallet retired successfully",
+	}
+	if result != nil {
+		response["transaction_id"] = result.TransactionID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // GetWhitelistedWallets handles retrieving all whitelisted wallets
 func (h *WalletHandler) GetWhitelistedWallets(c *gin.Context) {
 	wallets, err := h.walletService.GetWhitelistedWallets()
@@ -342,6 +371,23 @@ func (h *WalletHandler) GetInactiveWallets(c *gin.Context) {
 	})
 }
 
+// GetReserveAlerts handles reporting wallets whose spendable XRP is nearing their reserve
+func (h *WalletHandler) GetReserveAlerts(c *gin.Context) {
+	alerts, err := h.monitoringService.CheckReserveHeadroom()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check wallet reserves",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reserve_alerts": alerts,
+		"count":          len(alerts),
+	})
+}
+
 // GetWalletMetrics handles wallet metrics endpoint
 func (h *WalletHandler) GetWalletMetrics(c *gin.Context) {
 	metrics, err := h.monitoringService.GetWalletMetrics()
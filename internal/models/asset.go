@@ -154,6 +154,37 @@ func (sa *SupportedAsset) RequiresTrustLine() bool {
 	return !sa.IsNative() && sa.IssuerAddress != nil
 }
 
+// ParseAmount parses an amount of the asset held at its precision, rejecting more decimal places
+// than DecimalPlaces
+func (sa *SupportedAsset) ParseAmount(value string) (Money, error) {
+	amount, err := ParseMoney(value, Currency(sa.CurrencyCode))
+	if err != nil {
+		return Money{}, err
+	}
+	return amount.Rescale(sa.DecimalPlaces)
+}
+
+// FromBaseUnits reads an amount stored as a count of the asset's smallest unit, as balances are
+func (sa *SupportedAsset) FromBaseUnits(units string) (Money, error) {
+	count, ok := new(big.Int).SetString(units, 10)
+	if !ok {
+		return Money{}, fmt.Errorf("invalid %s base units %q", sa.CurrencyCode, units)
+	}
+	return NewMoneyFromUnits(count, sa.DecimalPlaces, Currency(sa.CurrencyCode))
+}
+
+// BaseUnits returns an amount of the asset as a count of its smallest unit, as balances are stored
+func (sa *SupportedAsset) BaseUnits(amount Money) (string, error) {
+	if amount.Currency() != "" && amount.Currency() != Currency(sa.CurrencyCode) {
+		return "", fmt.Errorf("%w: %s is not %s", ErrCurrencyMismatch, amount.Currency(), sa.CurrencyCode)
+	}
+	precise, err := amount.Rescale(sa.DecimalPlaces)
+	if err != nil {
+		return "", err
+	}
+	return precise.Units().String(), nil
+}
+
 // GetMinimumAmountBigInt returns the minimum amount as *big.Int
 func (sa *SupportedAsset) GetMinimumAmountBigInt() (*big.Int, error) {
 	amount := new(big.Int)
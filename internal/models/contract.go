@@ -21,8 +21,11 @@ type Contract struct {
 	Categories        []string           `json:"categories" db:"-"`
 	ExpirationDate    *time.Time         `json:"expiration_date" db:"expiration_date"`
 	RenewalTerms      string             `json:"renewal_terms" db:"renewal_terms"`
-	CreatedAt         time.Time          `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time          `json:"updated_at" db:"updated_at"`
+	// AssignmentRequiresConsent is set when the payee may only endorse its proceeds to another
+	// enterprise once the payer acknowledges the assignment
+	AssignmentRequiresConsent bool      `json:"assignment_requires_consent" db:"assignment_requires_consent"`
+	CreatedAt                 time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type Obligation struct {
@@ -35,7 +38,7 @@ type Obligation struct {
 
 type PaymentTerm struct {
 	ID         string    `json:"id"`
-	Amount     float64   `json:"amount"`
+	Amount     Money     `json:"amount"`
 	Currency   Currency  `json:"currency"`
 	DueDate    time.Time `json:"due_date"`
 	Conditions []string  `json:"conditions"`
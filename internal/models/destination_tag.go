@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletMetadataOmnibus marks a shared receiving wallet whose deposits are routed by destination tag
+const WalletMetadataOmnibus = "omnibus"
+
+// DestinationTag routes deposits into a shared receiving wallet to the enterprise it was allocated
+// to. A tag allocated for a smart cheque also identifies the cheque.
+type DestinationTag struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	WalletAddress string    `json:"wallet_address" db:"wallet_address"`
+	Tag           uint32    `json:"tag" db:"tag"`
+	EnterpriseID  uuid.UUID `json:"enterprise_id" db:"enterprise_id"`
+	SmartChequeID *string   `json:"smart_cheque_id,omitempty" db:"smart_cheque_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+
+	// XAddress encodes the wallet address and tag together for senders; it is not stored
+	XAddress string `json:"x_address,omitempty" db:"-"`
+}
+
+// QuarantinedDepositStatus is the review state of a quarantined deposit
+type QuarantinedDepositStatus string
+
+const (
+	QuarantinedDepositStatusPending  QuarantinedDepositStatus = "pending"
+	QuarantinedDepositStatusReleased QuarantinedDepositStatus = "released"
+)
+
+// QuarantinedDeposit is a payment into a shared receiving wallet that no destination tag routes to
+// an enterprise. It is held uncredited until an operator releases it to the right enterprise.
+type QuarantinedDeposit struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	WalletAddress   string    `json:"wallet_address" db:"wallet_address"`
+	TransactionHash string    `json:"transaction_hash" db:"transaction_hash"`
+	Sender          string    `json:"sender" db:"sender"`
+	DestinationTag  *uint32   `json:"destination_tag,omitempty" db:"destination_tag"`
+	// DeliveredValue is in drops for XRP and in the currency's units otherwise
+	DeliveredValue     string                   `json:"delivered_value" db:"delivered_value"`
+	DeliveredCurrency  string                   `json:"delivered_currency" db:"delivered_currency"`
+	Reason             string                   `json:"reason" db:"reason"`
+	Status             QuarantinedDepositStatus `json:"status" db:"status"`
+	EnterpriseID       *uuid.UUID               `json:"enterprise_id,omitempty" db:"enterprise_id"`
+	AssetTransactionID *uuid.UUID               `json:"asset_transaction_id,omitempty" db:"asset_transaction_id"`
+	CreatedAt          time.Time                `json:"created_at" db:"created_at"`
+	ReleasedAt         *time.Time               `json:"released_at,omitempty" db:"released_at"`
+}
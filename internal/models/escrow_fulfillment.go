@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EscrowFulfillment is a sealed escrow fulfillment held in the fulfillment vault.
+// The preimage is only ever stored encrypted and is bound to its smart cheque and milestone.
+type EscrowFulfillment struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	SmartChequeID        string     `json:"smart_cheque_id" db:"smart_cheque_id"`
+	MilestoneID          string     `json:"milestone_id" db:"milestone_id"`
+	Condition            string     `json:"condition" db:"condition"`
+	EncryptedFulfillment string     `json:"-" db:"encrypted_fulfillment"`
+	EscrowOwner          string     `json:"escrow_owner" db:"escrow_owner"`
+	EscrowDestination    string     `json:"escrow_destination" db:"escrow_destination"`
+	OfferSequence        uint32     `json:"offer_sequence" db:"offer_sequence"`
+	ReleaseCount         int        `json:"release_count" db:"release_count"`
+	LastReleasedAt       *time.Time `json:"last_released_at,omitempty" db:"last_released_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// FulfillmentAccessAction identifies an operation performed against the fulfillment vault
+type FulfillmentAccessAction string
+
+const (
+	FulfillmentAccessSeal    FulfillmentAccessAction = "seal"
+	FulfillmentAccessLookup  FulfillmentAccessAction = "lookup"
+	FulfillmentAccessRelease FulfillmentAccessAction = "release"
+)
+
+// FulfillmentAccessLog is the audit record written for every fulfillment vault access
+type FulfillmentAccessLog struct {
+	ID            uuid.UUID               `json:"id" db:"id"`
+	SmartChequeID string                  `json:"smart_cheque_id" db:"smart_cheque_id"`
+	MilestoneID   string                  `json:"milestone_id" db:"milestone_id"`
+	Action        FulfillmentAccessAction `json:"action" db:"action"`
+	Actor         string                  `json:"actor" db:"actor"`
+	Success       bool                    `json:"success" db:"success"`
+	Reason        string                  `json:"reason,omitempty" db:"reason"`
+	CreatedAt     time.Time               `json:"created_at" db:"created_at"`
+}
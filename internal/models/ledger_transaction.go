@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerTransaction is a validated XRPL transaction that affected a managed account, as recorded by
+// the ledger indexer from the account's on-ledger history. It includes transactions made outside
+// the platform.
+type LedgerTransaction struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	Account         string    `json:"account" db:"account"`
+	TransactionHash string    `json:"transaction_hash" db:"transaction_hash"`
+	LedgerIndex     uint32    `json:"ledger_index" db:"ledger_index"`
+	TransactionType string    `json:"transaction_type" db:"transaction_type"`
+	Sender          string    `json:"sender" db:"sender"`
+	Destination     string    `json:"destination,omitempty" db:"destination"`
+	Sequence        uint32    `json:"sequence" db:"sequence"`
+
+	// Amount is the transaction's Amount field; Delivered is what a payment actually delivered.
+	// XRP values are in drops and carry no currency.
+	AmountValue       string `json:"amount_value,omitempty" db:"amount_value"`
+	AmountCurrency    string `json:"amount_currency,omitempty" db:"amount_currency"`
+	AmountIssuer      string `json:"amount_issuer,omitempty" db:"amount_issuer"`
+	DeliveredValue    string `json:"delivered_value,omitempty" db:"delivered_value"`
+	DeliveredCurrency string `json:"delivered_currency,omitempty" db:"delivered_currency"`
+	DeliveredIssuer   string `json:"delivered_issuer,omitempty" db:"delivered_issuer"`
+	PartialPayment    bool   `json:"partial_payment" db:"partial_payment"`
+
+	FeeDrops   int64     `json:"fee_drops" db:"fee_drops"`
+	ResultCode string    `json:"result_code" db:"result_code"`
+	LedgerTime time.Time `json:"ledger_time" db:"ledger_time"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Succeeded reports whether the transaction applied with tesSUCCESS
+func (t *LedgerTransaction) Succeeded() bool {
+	return t.ResultCode == "tesSUCCESS"
+}
+
+// Inbound reports whether the transaction was sent to the indexed account by another account
+func (t *LedgerTransaction) Inbound() bool {
+	return t.Destination == t.Account && t.Sender != t.Account
+}
+
+// LedgerCheckpoint is the last validated ledger whose transactions the indexer has recorded for an account
+type LedgerCheckpoint struct {
+	Account     string    `json:"account" db:"account"`
+	LedgerIndex uint32    `json:"ledger_index" db:"ledger_index"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
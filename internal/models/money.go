@@ -0,0 +1,546 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxMoneyScale is the most decimal places an amount can carry: the finest exponent of an XRPL
+// issued-currency amount, whose values run from 10^-96 to just under 10^96
+const MaxMoneyScale = 96
+
+var (
+	// ErrMoneyPrecision is returned when an amount has more decimal places than its currency allows
+	ErrMoneyPrecision = errors.New("amount is more precise than the currency allows")
+	// ErrCurrencyMismatch is returned when amounts in different currencies are combined
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	// ErrMoneyOverflow is returned when an amount is larger than any ledger amount can be
+	ErrMoneyOverflow = errors.New("amount is out of range")
+)
+
+// defaultCurrencyScale is the scale of a currency no SupportedAsset has configured
+const defaultCurrencyScale = 6
+
+var (
+	currencyScalesMu sync.RWMutex
+	// currencyScales hold SupportedAsset.DecimalPlaces by currency. They start at the precision the
+	// built-in assets are seeded with until ConfigureCurrencyScales loads the configured assets.
+	currencyScales = map[Currency]int{
+		CurrencyXRP:    6,
+		CurrencyUSDT:   6,
+		CurrencyUSDC:   6,
+		CurrencyERupee: 2,
+	}
+)
+
+// ConfigureCurrencyScales accounts each asset's currency in its DecimalPlaces. Assets registered or
+// updated later are configured the same way.
+func ConfigureCurrencyScales(assets ...*SupportedAsset) error {
+	for _, asset := range assets {
+		if asset.DecimalPlaces < 0 || asset.DecimalPlaces > MaxMoneyScale {
+			return fmt.Errorf("invalid decimal places %d for %s", asset.DecimalPlaces, asset.CurrencyCode)
+		}
+	}
+
+	currencyScalesMu.Lock()
+	defer currencyScalesMu.Unlock()
+	for _, asset := range assets {
+		currencyScales[Currency(asset.CurrencyCode)] = asset.DecimalPlaces
+	}
+	return nil
+}
+
+// Scale returns the decimal places the currency is accounted in, its SupportedAsset.DecimalPlaces.
+// Currencies without a configured asset default to 6.
+func (c Currency) Scale() int {
+	currencyScalesMu.RLock()
+	defer currencyScalesMu.RUnlock()
+	if scale, ok := currencyScales[c]; ok {
+		return scale
+	}
+	return defaultCurrencyScale
+}
+
+// moneyLimit bounds the magnitude of an amount: no XRPL amount reaches 10^96
+var moneyLimit = bigPow10(96)
+
+// Money is an exact fixed-point amount of a currency, held as a whole number of units of 10^-scale.
+// Sums and splits of Money never drift the way float64 amounts do, and amounts past what a ledger
+// can hold fail with ErrMoneyOverflow. The zero value is zero with no currency; amounts decoded from
+// JSON or SQL take their currency from the record they belong to. Money is immutable: its units are
+// never modified once set.
+type Money struct {
+	units    *big.Int
+	scale    int
+	currency Currency
+}
+
+// NewMoney returns units of 10^-scale of a currency, e.g. NewMoney(1050, 2, CurrencyUSDT) is 10.50 USDT
+func NewMoney(units int64, scale int, currency Currency) Money {
+	return Money{units: big.NewInt(units), scale: scale, currency: currency}
+}
+
+// NewMoneyFromUnits is NewMoney for unit counts that may not fit an int64, failing with
+// ErrMoneyOverflow past the largest ledger amount
+func NewMoneyFromUnits(units *big.Int, scale int, currency Currency) (Money, error) {
+	if scale < 0 || scale > MaxMoneyScale {
+		return Money{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	return Money{units: new(big.Int).Set(units), scale: scale, currency: currency}.checked()
+}
+
+// ZeroMoney returns zero of a currency at its accounting scale
+func ZeroMoney(currency Currency) Money {
+	return NewMoney(0, currency.Scale(), currency)
+}
+
+// ParseMoney parses a decimal amount such as "1250.75", "-3", "0.000001" or "1.5e3" exactly,
+// keeping the decimal places it was written with
+func ParseMoney(value string, currency Currency) (Money, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	exponent := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > MaxMoneyScale || exp < -MaxMoneyScale {
+			return Money{}, fmt.Errorf("invalid amount %q", value)
+		}
+		exponent = exp
+		s = s[:i]
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	digits := whole + fraction
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	units, _ := new(big.Int).SetString(digits, 10)
+	scale := len(fraction) - exponent
+	if scale < 0 {
+		units.Mul(units, bigPow10(-scale))
+		scale = 0
+	}
+	if scale > MaxMoneyScale {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrMoneyPrecision, value, MaxMoneyScale)
+	}
+	if negative {
+		units.Neg(units)
+	}
+	m, err := Money{units: units, scale: scale, currency: currency}.checked()
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, value)
+	}
+	return m, nil
+}
+
+// MustParseMoney is ParseMoney for amounts known to be valid, such as constants; it panics otherwise
+func MustParseMoney(value string, currency Currency) Money {
+	m, err := ParseMoney(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MoneyFromFloat converts a float64 amount using its shortest decimal representation, so 0.29
+// becomes exactly 0.29 rather than the 0.28999... the float holds
+func MoneyFromFloat(amount float64, currency Currency) (Money, error) {
+	return ParseMoney(strconv.FormatFloat(amount, 'g', -1, 64), currency)
+}
+
+// MoneyFromRat converts an exact quantity, such as an amount multiplied by a rate, into Money at
+// scale decimal places, rounding half away from zero
+func MoneyFromRat(value *big.Rat, scale int, currency Currency) (Money, error) {
+	if scale < 0 || scale > MaxMoneyScale {
+		return Money{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(bigPow10(scale)))
+	units, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	remainder.Abs(remainder)
+	if remainder.Cmp(new(big.Int).Sub(scaled.Denom(), remainder)) >= 0 {
+		units.Add(units, big.NewInt(int64(scaled.Sign())))
+	}
+	return Money{units: units, scale: scale, currency: currency}.checked()
+}
+
+// Currency returns the currency the amount is in; empty when it was decoded without one
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// WithCurrency returns the amount in the given currency
+func (m Money) WithCurrency(currency Currency) Money {
+	m.currency = currency
+	return m
+}
+
+// Denominate returns the amount in currency at the currency's accounting scale. An amount already
+// in another currency fails with ErrCurrencyMismatch, one finer than the currency with ErrMoneyPrecision.
+func (m Money) Denominate(currency Currency) (Money, error) {
+	if _, err := m.commonCurrency(Money{currency: currency}); err != nil {
+		return Money{}, err
+	}
+	m.currency = currency
+	return m.Rescale(currency.Scale())
+}
+
+// Scale returns the number of decimal places the amount is held at
+func (m Money) Scale() int {
+	return m.scale
+}
+
+// Units returns the amount as a whole number of 10^-Scale units
+func (m Money) Units() *big.Int {
+	return new(big.Int).Set(m.bigUnits())
+}
+
+// Rat returns the exact value of the amount as a fraction
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(m.bigUnits(), bigPow10(m.scale))
+}
+
+// Sign returns -1, 0 or +1 for negative, zero and positive amounts
+func (m Money) Sign() int {
+	return m.bigUnits().Sign()
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Sign() == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Sign() > 0
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	m.units = new(big.Int).Neg(m.bigUnits())
+	return m
+}
+
+// String formats the amount as a decimal with Scale places, e.g. "10.50"
+func (m Money) String() string {
+	units := m.bigUnits()
+	digits := new(big.Int).Abs(units).String()
+	if m.scale > 0 {
+		if len(digits) <= m.scale {
+			digits = strings.Repeat("0", m.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-m.scale] + "." + digits[len(digits)-m.scale:]
+	}
+	if units.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Float64 returns the nearest float64, for APIs and metrics that have not moved to Money
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.String(), 64)
+	return f
+}
+
+// Rescale returns the amount held at scale decimal places. It fails with ErrMoneyPrecision rather
+// than drop non-zero digits.
+func (m Money) Rescale(scale int) (Money, error) {
+	if scale < 0 || scale > MaxMoneyScale {
+		return Money{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	if scale >= m.scale {
+		return Money{units: scaleUp(m.bigUnits(), scale-m.scale), scale: scale, currency: m.currency}, nil
+	}
+
+	units, remainder := new(big.Int).QuoRem(m.bigUnits(), bigPow10(m.scale-scale), new(big.Int))
+	if remainder.Sign() != 0 {
+		return Money{}, fmt.Errorf("%w: %s %s has more than %d decimal places", ErrMoneyPrecision, m, m.currency, scale)
+	}
+	return Money{units: units, scale: scale, currency: m.currency}, nil
+}
+
+// Round returns the amount rounded half away from zero to scale decimal places
+func (m Money) Round(scale int) (Money, error) {
+	if scale >= m.scale {
+		return m.Rescale(scale)
+	}
+	if scale < 0 {
+		return Money{}, fmt.Errorf("invalid scale %d", scale)
+	}
+
+	divisor := bigPow10(m.scale - scale)
+	units, remainder := new(big.Int).QuoRem(m.bigUnits(), divisor, new(big.Int))
+	remainder.Abs(remainder)
+	if remainder.Cmp(new(big.Int).Sub(divisor, remainder)) >= 0 {
+		units.Add(units, big.NewInt(int64(m.Sign())))
+	}
+	return Money{units: units, scale: scale, currency: m.currency}, nil
+}
+
+// Truncate returns the amount with the digits past scale decimal places dropped, rounding toward zero
+func (m Money) Truncate(scale int) (Money, error) {
+	if scale >= m.scale {
+		return m.Rescale(scale)
+	}
+	if scale < 0 {
+		return Money{}, fmt.Errorf("invalid scale %d", scale)
+	}
+	units := new(big.Int).Quo(m.bigUnits(), bigPow10(m.scale-scale))
+	return Money{units: units, scale: scale, currency: m.currency}, nil
+}
+
+// Add returns m+o at the finer of the two scales
+func (m Money) Add(o Money) (Money, error) {
+	currency, err := m.commonCurrency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	a, b, scale := align(m, o)
+	sum, err := Money{units: a.Add(a, b), scale: scale, currency: currency}.checked()
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrMoneyOverflow, m, o)
+	}
+	return sum, nil
+}
+
+// Sub returns m-o at the finer of the two scales
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Neg())
+}
+
+// Cmp compares the values of two amounts regardless of scale, returning -1, 0 or +1. Amounts in
+// different currencies cannot be compared and fail with ErrCurrencyMismatch; an amount without a
+// currency compares with any.
+func (m Money) Cmp(o Money) (int, error) {
+	if _, err := m.commonCurrency(o); err != nil {
+		return 0, err
+	}
+	a, b, _ := align(m, o)
+	return a.Cmp(b), nil
+}
+
+// Equal reports whether two amounts have the same value and currency. An amount without a
+// currency matches any currency.
+func (m Money) Equal(o Money) bool {
+	cmp, err := m.Cmp(o)
+	return err == nil && cmp == 0
+}
+
+// SumMoney adds amounts of one currency; the sum of no amounts is zero
+func SumMoney(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Allocate splits the amount in proportion to weights without losing a unit: each share is rounded
+// down to the amount's scale and the units left over go one at a time to the shares that lost the
+// most, earlier shares first on ties. The shares always sum to the amount.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("allocation needs at least one weight")
+	}
+	total := new(big.Int)
+	for _, weight := range weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("allocation weights cannot be negative: %d", weight)
+		}
+		total.Add(total, big.NewInt(weight))
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("allocation weights cannot all be zero")
+	}
+
+	negative := m.Sign() < 0
+	amount := new(big.Int).Abs(m.bigUnits())
+
+	shares := make([]*big.Int, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	allocated := new(big.Int)
+	for i, weight := range weights {
+		share, remainder := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(weight)), total, new(big.Int))
+		shares[i], remainders[i] = share, remainder
+		allocated.Add(allocated, share)
+	}
+
+	// Fewer leftover units remain than there are shares, so each share gains at most one
+	for left := new(big.Int).Sub(amount, allocated).Int64(); left > 0; left-- {
+		largest := -1
+		for i, remainder := range remainders {
+			if weights[i] > 0 && (largest < 0 || remainder.Cmp(remainders[largest]) > 0) {
+				largest = i
+			}
+		}
+		shares[largest].Add(shares[largest], big.NewInt(1))
+		remainders[largest] = big.NewInt(-1)
+	}
+
+	result := make([]Money, len(shares))
+	for i, share := range shares {
+		if negative {
+			share.Neg(share)
+		}
+		result[i] = Money{units: share, scale: m.scale, currency: m.currency}
+	}
+	return result, nil
+}
+
+// Split divides the amount into n shares differing by at most one unit, larger shares first
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("cannot split an amount into %d shares", n)
+	}
+	weights := make([]int64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return m.Allocate(weights...)
+}
+
+// MarshalJSON encodes the amount as an exact JSON number such as 10.50
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or a decimal string without rounding it through float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		*m = Money{currency: m.currency}
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoney(text, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as its exact decimal text, which NUMERIC columns accept
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a NUMERIC, integer or text column. The zeros a NUMERIC column pads fractions with are
+// dropped, so 10.50000000 reads back as 10.5.
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		*m = Money{currency: m.currency}
+		return nil
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	case int64:
+		text = strconv.FormatInt(value, 10)
+	case float64:
+		text = strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	parsed, err := ParseMoney(text, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed.trimmed()
+	return nil
+}
+
+// bigUnits returns the units for reading; the zero value has none
+func (m Money) bigUnits() *big.Int {
+	if m.units == nil {
+		return new(big.Int)
+	}
+	return m.units
+}
+
+// checked fails with ErrMoneyOverflow when the amount reaches moneyLimit
+func (m Money) checked() (Money, error) {
+	limit := new(big.Int).Mul(moneyLimit, bigPow10(m.scale))
+	if new(big.Int).Abs(m.bigUnits()).Cmp(limit) >= 0 {
+		return Money{}, fmt.Errorf("%w: %s", ErrMoneyOverflow, m)
+	}
+	return m, nil
+}
+
+// trimmed drops trailing fractional zeros
+func (m Money) trimmed() Money {
+	units := new(big.Int).Set(m.bigUnits())
+	ten, digit := big.NewInt(10), new(big.Int)
+	for m.scale > 0 && units.Sign() != 0 {
+		quotient, _ := new(big.Int).QuoRem(units, ten, digit)
+		if digit.Sign() != 0 {
+			break
+		}
+		units = quotient
+		m.scale--
+	}
+	if units.Sign() == 0 {
+		m.scale = 0
+	}
+	m.units = units
+	return m
+}
+
+func (m Money) commonCurrency(o Money) (Currency, error) {
+	switch {
+	case m.currency == "":
+		return o.currency, nil
+	case o.currency == "" || o.currency == m.currency:
+		return m.currency, nil
+	default:
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+}
+
+// align returns fresh copies of the units of two amounts at the finer of their scales
+func align(m, o Money) (*big.Int, *big.Int, int) {
+	scale := m.scale
+	if o.scale > scale {
+		scale = o.scale
+	}
+	return scaleUp(m.bigUnits(), scale-m.scale), scaleUp(o.bigUnits(), scale-o.scale), scale
+}
+
+// scaleUp returns units multiplied by 10^places
+func scaleUp(units *big.Int, places int) *big.Int {
+	return new(big.Int).Mul(units, bigPow10(places))
+}
+
+func bigPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,271 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value  string
+		units  string
+		scale  int
+		string string
+	}{
+		{"1250.75", "125075", 2, "1250.75"},
+		{"-3", "-3", 0, "-3"},
+		{"0.000001", "1", 6, "0.000001"},
+		{".5", "5", 1, "0.5"},
+		{"1.5e3", "1500", 0, "1500"},
+		{"2.5E-2", "25", 3, "0.025"},
+		{"+10.50", "1050", 2, "10.50"},
+		{"99999999999999999999", "99999999999999999999", 0, "99999999999999999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			m, err := ParseMoney(tt.value, CurrencyUSDT)
+			require.NoError(t, err)
+			assert.Equal(t, tt.units, m.Units().String())
+			assert.Equal(t, tt.scale, m.Scale())
+			assert.Equal(t, tt.string, m.String())
+			assert.Equal(t, CurrencyUSDT, m.Currency())
+		})
+	}
+
+	for _, invalid := range []string{"", "abc", "1.2.3", "1e", "--1", "0x10", "1e400"} {
+		_, err := ParseMoney(invalid, CurrencyUSDT)
+		assert.Error(t, err, invalid)
+	}
+	_, err := ParseMoney("0.1e-96", CurrencyUSDT)
+	assert.ErrorIs(t, err, ErrMoneyPrecision)
+	_, err = ParseMoney("1e96", CurrencyUSDT)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestParseMoney_IssuedCurrencyRange(t *testing.T) {
+	// The smallest and largest values an XRPL issued-currency amount can hold
+	smallest, err := ParseMoney("1000000000000000e-96", CurrencyUSDC)
+	require.NoError(t, err)
+	assert.Equal(t, 96, smallest.Scale())
+	assert.Equal(t, "0."+strings.Repeat("0", 80)+"1"+strings.Repeat("0", 15), smallest.String())
+
+	largest, err := ParseMoney("9999999999999999e80", CurrencyUSDC)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("9", 16)+strings.Repeat("0", 80), largest.String())
+
+	_, err = largest.Add(largest)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+	cmp, err := smallest.Cmp(largest)
+	require.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+}
+
+func TestMoneyFromFloat_UsesShortestDecimal(t *testing.T) {
+	// 0.29 * 1e6 truncates to 289999 drops as a float64
+	m, err := MoneyFromFloat(0.29, "XRP")
+	require.NoError(t, err)
+	drops, err := m.Rescale(6)
+	require.NoError(t, err)
+	assert.Equal(t, "290000", drops.Units().String())
+
+	_, err = MoneyFromFloat(0.1+0.2, CurrencyUSDT)
+	require.NoError(t, err)
+}
+
+func TestMoney_SumsWithoutDrift(t *testing.T) {
+	var floatTotal float64
+	for i := 0; i < 10; i++ {
+		floatTotal += 0.1
+	}
+	assert.NotEqual(t, 1.0, floatTotal)
+
+	total := ZeroMoney(CurrencyUSDC)
+	for i := 0; i < 10; i++ {
+		var err error
+		total, err = total.Add(MustParseMoney("0.1", CurrencyUSDC))
+		require.NoError(t, err)
+	}
+	cmp, err := total.Cmp(MustParseMoney("1", CurrencyUSDC))
+	require.NoError(t, err)
+	assert.Equal(t, 0, cmp)
+	assert.Equal(t, "1.000000", total.String())
+
+	_, err = total.Add(MustParseMoney("1", CurrencyERupee))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = total.Cmp(MustParseMoney("1", CurrencyERupee))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	// Amounts decoded without a currency combine with any
+	sum, err := total.Add(MustParseMoney("2", ""))
+	require.NoError(t, err)
+	assert.True(t, sum.Equal(MustParseMoney("3", CurrencyUSDC)))
+	assert.False(t, sum.Equal(MustParseMoney("3", CurrencyUSDT)))
+
+	difference, err := sum.Sub(MustParseMoney("3.5", CurrencyUSDC))
+	require.NoError(t, err)
+	assert.Equal(t, "-0.500000", difference.String())
+	assert.Equal(t, -1, difference.Sign())
+}
+
+func TestMoney_RescaleAndRound(t *testing.T) {
+	m := MustParseMoney("10.125", CurrencyERupee)
+
+	_, err := m.Rescale(CurrencyERupee.Scale())
+	assert.ErrorIs(t, err, ErrMoneyPrecision)
+
+	rounded, err := m.Round(2)
+	require.NoError(t, err)
+	assert.Equal(t, "10.13", rounded.String())
+	rounded, err = m.Neg().Round(2)
+	require.NoError(t, err)
+	assert.Equal(t, "-10.13", rounded.String())
+	rounded, err = MustParseMoney("10.124", CurrencyERupee).Round(2)
+	require.NoError(t, err)
+	assert.Equal(t, "10.12", rounded.String())
+
+	truncated, err := m.Neg().Truncate(2)
+	require.NoError(t, err)
+	assert.Equal(t, "-10.12", truncated.String())
+
+	widened, err := MustParseMoney("10.5", CurrencyERupee).Rescale(2)
+	require.NoError(t, err)
+	assert.Equal(t, "10.50", widened.String())
+
+	// Amounts past an int64 of units stay exact
+	widened, err = NewMoney(1<<62, 0, CurrencyUSDT).Rescale(18)
+	require.NoError(t, err)
+	assert.Equal(t, "4611686018427387904.000000000000000000", widened.String())
+}
+
+func TestMoney_RatRoundTrip(t *testing.T) {
+	m := MustParseMoney("30.3", CurrencyUSDC)
+	assert.Equal(t, big.NewRat(303, 10), m.Rat())
+
+	// Products of an amount and a rate are rounded half away from zero at the requested scale
+	third, err := MoneyFromRat(new(big.Rat).Quo(m.Rat(), big.NewRat(3, 1)), 6, CurrencyUSDC)
+	require.NoError(t, err)
+	assert.Equal(t, "10.100000", third.String())
+	assert.Equal(t, CurrencyUSDC, third.Currency())
+	rounded, err := MoneyFromRat(big.NewRat(-2, 3), 2, CurrencyUSDT)
+	require.NoError(t, err)
+	assert.Equal(t, "-0.67", rounded.String())
+	rounded, err = MoneyFromRat(big.NewRat(1, 8), 2, CurrencyUSDT)
+	require.NoError(t, err)
+	assert.Equal(t, "0.13", rounded.String())
+}
+
+func TestMoney_AllocateNeverLosesAUnit(t *testing.T) {
+	total := MustParseMoney("100.00", CurrencyUSDT)
+
+	thirds, err := total.Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"33.34", "33.33", "33.33"}, moneyStrings(thirds))
+	sum, err := SumMoney(thirds...)
+	require.NoError(t, err)
+	assert.True(t, sum.Equal(total))
+
+	// The leftover unit goes to the share that lost the most to rounding
+	shares, err := MustParseMoney("0.10", CurrencyUSDT).Allocate(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.03", "0.07"}, moneyStrings(shares))
+
+	shares, err = MustParseMoney("-10", CurrencyUSDT).Allocate(1, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-3", "0", "-7"}, moneyStrings(shares))
+
+	_, err = total.Allocate()
+	assert.Error(t, err)
+	_, err = total.Allocate(0, 0)
+	assert.Error(t, err)
+	_, err = total.Allocate(1, -1)
+	assert.Error(t, err)
+}
+
+func TestMoney_JSONAndSQL(t *testing.T) {
+	var decoded struct {
+		Number Money `json:"number"`
+		Text   Money `json:"text"`
+		Null   Money `json:"null"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"number": 1234567.89, "text": "0.1", "null": null}`), &decoded))
+	assert.Equal(t, "1234567.89", decoded.Number.String())
+	assert.Equal(t, "0.1", decoded.Text.String())
+	assert.True(t, decoded.Null.IsZero())
+
+	encoded, err := json.Marshal(map[string]Money{"amount": MustParseMoney("10.50", CurrencyUSDT)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 10.50}`, string(encoded))
+
+	var scanned Money
+	require.NoError(t, scanned.Scan([]byte("125.50000000")))
+	assert.Equal(t, "125.5", scanned.String())
+	require.NoError(t, scanned.Scan(int64(7)))
+	assert.Equal(t, "7", scanned.String())
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+	assert.Error(t, scanned.Scan(true))
+
+	value, err := MustParseMoney("10.50", CurrencyUSDT).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "10.50", value)
+}
+
+func TestSupportedAsset_MoneyPrecision(t *testing.T) {
+	rupee := &SupportedAsset{CurrencyCode: string(CurrencyERupee), DecimalPlaces: 2}
+
+	amount, err := rupee.ParseAmount("99.5")
+	require.NoError(t, err)
+	assert.Equal(t, "99.50", amount.String())
+	_, err = rupee.ParseAmount("99.505")
+	assert.ErrorIs(t, err, ErrMoneyPrecision)
+
+	units, err := rupee.BaseUnits(amount)
+	require.NoError(t, err)
+	assert.Equal(t, "9950", units)
+	_, err = rupee.BaseUnits(MustParseMoney("1", CurrencyUSDT))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	balance, err := rupee.FromBaseUnits("9950")
+	require.NoError(t, err)
+	assert.True(t, balance.Equal(amount))
+	_, err = rupee.FromBaseUnits("99.50")
+	assert.Error(t, err)
+
+	// Balances of 18-decimal tokens run past an int64 of base units
+	token := &SupportedAsset{CurrencyCode: "WETH", DecimalPlaces: 18}
+	balance, err = token.FromBaseUnits("12345000000000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, "12345.000000000000000000", balance.String())
+	units, err = token.BaseUnits(balance)
+	require.NoError(t, err)
+	assert.Equal(t, "12345000000000000000000", units)
+}
+
+func TestCurrency_ScaleFollowsSupportedAsset(t *testing.T) {
+	currency := Currency("SCALETEST")
+	assert.Equal(t, 6, currency.Scale())
+
+	require.NoError(t, ConfigureCurrencyScales(&SupportedAsset{CurrencyCode: string(currency), DecimalPlaces: 4}))
+	assert.Equal(t, 4, currency.Scale())
+	assert.Equal(t, "0.0000", ZeroMoney(currency).String())
+
+	_, err := MustParseMoney("1.00001", currency).Denominate(currency)
+	assert.ErrorIs(t, err, ErrMoneyPrecision)
+
+	assert.Error(t, ConfigureCurrencyScales(&SupportedAsset{CurrencyCode: string(currency), DecimalPlaces: -1}))
+	assert.Equal(t, 4, currency.Scale())
+}
+
+func moneyStrings(amounts []Money) []string {
+	strs := make([]string, len(amounts))
+	for i, amount := range amounts {
+		strs[i] = amount.String()
+	}
+	return strs
+}
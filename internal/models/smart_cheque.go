@@ -5,22 +5,49 @@ import (
 )
 
 type SmartCheque struct {
-	ID            string            `json:"id" db:"id"`
-	PayerID       string            `json:"payer_id" db:"payer_id"`
-	PayeeID       string            `json:"payee_id" db:"payee_id"`
-	Amount        float64           `json:"amount" db:"amount"`
-	Currency      Currency          `json:"currency" db:"currency"`
-	Milestones    []Milestone       `json:"milestones"`
-	EscrowAddress string            `json:"escrow_address" db:"escrow_address"`
-	Status        SmartChequeStatus `json:"status" db:"status"`
-	ContractHash  string            `json:"contract_hash" db:"contract_hash"`
-	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at" db:"updated_at"`
+	ID            string      `json:"id" db:"id"`
+	PayerID       string      `json:"payer_id" db:"payer_id"`
+	PayeeID       string      `json:"payee_id" db:"payee_id"`
+	Amount        Money       `json:"amount" db:"amount"`
+	Currency      Currency    `json:"currency" db:"currency"`
+	Milestones    []Milestone `json:"milestones"`
+	EscrowAddress string      `json:"escrow_address" db:"escrow_address"`
+	// SettlementCurrency is the currency the payee is paid in when it differs from Currency;
+	// milestone releases are converted on the XRPL decentralized exchange
+	SettlementCurrency Currency `json:"settlement_currency,omitempty" db:"settlement_currency"`
+	// SettlementMode is the instrument that pays the cheque out; escrow when empty
+	SettlementMode SettlementMode `json:"settlement_mode,omitempty" db:"settlement_mode"`
+	// CheckID is the ledger entry ID of the XRPL Check an xrpl_check cheque was issued as
+	CheckID      string            `json:"check_id,omitempty" db:"check_id"`
+	Status       SmartChequeStatus `json:"status" db:"status"`
+	ContractHash string            `json:"contract_hash" db:"contract_hash"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
 }
 
+// Settlement returns the cheque's settlement mode, defaulting to escrow
+func (s *SmartCheque) Settlement() SettlementMode {
+	if s.SettlementMode == "" {
+		return SettlementModeEscrow
+	}
+	return s.SettlementMode
+}
+
+type SettlementMode string
+
+const (
+	// SettlementModeEscrow locks the cheque's funds in an XRPL escrow released per milestone
+	SettlementModeEscrow SettlementMode = "escrow"
+	// SettlementModeXRPLCheck issues a native XRPL Check the payee cashes; funds stay with the payer until then
+	SettlementModeXRPLCheck SettlementMode = "xrpl_check"
+	// SettlementModeDirect pays the cheque with a plain payment and no deferred instrument
+	SettlementModeDirect SettlementMode = "direct"
+)
+
 type Currency string
 
 const (
+	CurrencyXRP    Currency = "XRP"
 	CurrencyUSDT   Currency = "USDT"
 	CurrencyUSDC   Currency = "USDC"
 	CurrencyERupee Currency = "e₹"
@@ -34,16 +61,63 @@ const (
 	SmartChequeStatusInProgress SmartChequeStatus = "in_progress"
 	SmartChequeStatusCompleted  SmartChequeStatus = "completed"
 	SmartChequeStatusDisputed   SmartChequeStatus = "disputed"
+	// SmartChequeStatusPartiallyPaid is a cheque that paid out some milestones and returned the
+	// rest to the payer
+	SmartChequeStatusPartiallyPaid SmartChequeStatus = "partially_paid"
+	// SmartChequeStatusCancelled is a cheque called off whose escrows wait for their CancelAfter
+	// before the funds can be returned to the payer
+	SmartChequeStatusCancelled SmartChequeStatus = "cancelled"
+	// SmartChequeStatusExpired is a cheque whose escrows passed their CancelAfter before their
+	// milestones were completed
+	SmartChequeStatusExpired SmartChequeStatus = "expired"
+	// SmartChequeStatusRefunded is a cheque whose funds all went back to the payer
+	SmartChequeStatusRefunded SmartChequeStatus = "refunded"
+	// SmartChequeStatusFrozen is a cheque held by a dispute or fraud review; nothing is released
+	// or cancelled until it is unfrozen
+	SmartChequeStatusFrozen SmartChequeStatus = "frozen"
 )
 
+// SmartChequeStatuses lists every status a smart cheque can be in
+var SmartChequeStatuses = []SmartChequeStatus{
+	SmartChequeStatusCreated,
+	SmartChequeStatusLocked,
+	SmartChequeStatusInProgress,
+	SmartChequeStatusCompleted,
+	SmartChequeStatusDisputed,
+	SmartChequeStatusPartiallyPaid,
+	SmartChequeStatusCancelled,
+	SmartChequeStatusExpired,
+	SmartChequeStatusRefunded,
+	SmartChequeStatusFrozen,
+}
+
+// IsValid reports whether the status is one a smart cheque can be in
+func (s SmartChequeStatus) IsValid() bool {
+	for _, status := range SmartChequeStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Milestone struct {
 	ID                 string             `json:"id"`
 	Description        string             `json:"description"`
-	Amount             float64            `json:"amount"`
+	Amount             Money              `json:"amount"`
 	VerificationMethod VerificationMethod `json:"verification_method"`
 	OracleConfig       *OracleConfig      `json:"oracle_config,omitempty"`
 	Status             MilestoneStatus    `json:"status"`
 	CompletedAt        *time.Time         `json:"completed_at,omitempty"`
+	// PaymentMode is how the milestone pays out; escrow when empty
+	PaymentMode MilestonePaymentMode `json:"payment_mode,omitempty"`
+	// Channel is the payment channel streaming a payment_channel milestone
+	Channel *MilestoneChannel `json:"channel,omitempty"`
+	// Escrow is the XRPL escrow funding an escrow milestone of its own
+	Escrow *MilestoneEscrow `json:"escrow,omitempty"`
+	// Holder is the enterprise the milestone's proceeds were endorsed to; the payee holds them
+	// when it is nil
+	Holder *MilestoneHolder `json:"holder,omitempty"`
 
 	// Enhanced fields from ContractMilestone
 	ContractID           string         `json:"contract_id,omitempty"`
@@ -77,6 +151,218 @@ const (
 	VerificationMethodHybrid VerificationMethod = "hybrid"
 )
 
+type MilestonePaymentMode string
+
+const (
+	MilestonePaymentModeEscrow MilestonePaymentMode = "escrow"
+	// MilestonePaymentModeChannel streams time-and-materials payouts through an XRP payment channel,
+	// releasing a claim for the share of the channel matching the milestone's progress
+	MilestonePaymentModeChannel MilestonePaymentMode = "payment_channel"
+)
+
+// MilestoneChannel tracks the payment channel of a payment_channel milestone; amounts are in drops
+type MilestoneChannel struct {
+	ChannelID    string `json:"channel_id"`
+	PayerAccount string `json:"payer_account"`
+	PayeeAccount string `json:"payee_account"`
+	PublicKey    string `json:"public_key"`
+	Amount       int64  `json:"amount"`
+	// AuthorizedAmount is the cumulative claim the payer has signed, and ClaimSignature its signature
+	AuthorizedAmount int64  `json:"authorized_amount"`
+	ClaimSignature   string `json:"claim_signature,omitempty"`
+	// RedeemedAmount is how much of the authorized amount the payee has redeemed on the ledger
+	RedeemedAmount int64      `json:"redeemed_amount"`
+	RedeemedAt     *time.Time `json:"redeemed_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+}
+
+// MilestoneEscrow maps a milestone to the escrow that funds it. Each milestone is escrowed on its
+// own, with its own condition and time bounds, so it can be released or cancelled independently.
+type MilestoneEscrow struct {
+	// TransactionID is the hash of the EscrowCreate that funded the milestone
+	TransactionID string `json:"transaction_id"`
+	Owner         string `json:"owner"`
+	Destination   string `json:"destination"`
+	OfferSequence uint32 `json:"offer_sequence"`
+	LedgerIndex   uint32 `json:"ledger_index,omitempty"`
+	Condition     string `json:"condition"`
+	// FinishAfter and CancelAfter bound the escrow in seconds since the Ripple epoch
+	FinishAfter uint32                `json:"finish_after,omitempty"`
+	CancelAfter uint32                `json:"cancel_after,omitempty"`
+	Status      MilestoneEscrowStatus `json:"status"`
+	// ResolvedBy is the EscrowFinish or EscrowCancel that removed the escrow from the ledger
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	// PendingTransaction is the EscrowFinish or EscrowCancel submitted to resolve the escrow while no
+	// validated ledger has settled it; LastError is why the last one left the escrow active
+	PendingTransaction string `json:"pending_transaction,omitempty"`
+	LastError          string `json:"last_error,omitempty"`
+}
+
+// Resolving reports whether an EscrowFinish or EscrowCancel of the escrow is waiting for a validated ledger
+func (e *MilestoneEscrow) Resolving() bool {
+	return e.PendingTransaction != ""
+}
+
+type MilestoneEscrowStatus string
+
+const (
+	MilestoneEscrowStatusActive    MilestoneEscrowStatus = "active"
+	MilestoneEscrowStatusFinished  MilestoneEscrowStatus = "finished"
+	MilestoneEscrowStatusCancelled MilestoneEscrowStatus = "cancelled"
+)
+
+// MilestoneEscrowFunding is the outcome of funding one milestone's escrow. The fulfillment is
+// handed back once so it can be sealed; it is never stored on the milestone.
+type MilestoneEscrowFunding struct {
+	MilestoneID string
+	Escrow      MilestoneEscrow
+	Fulfillment string
+	// Validated reports whether the EscrowCreate was already in a validated ledger; otherwise the
+	// ledger can still drop it until LastLedgerSequence has passed
+	Validated          bool
+	LastLedgerSequence uint32
+}
+
+// HasMilestoneEscrows reports whether the cheque's milestones are funded by escrows of their own
+// rather than a single escrow for the whole cheque
+func (s *SmartCheque) HasMilestoneEscrows() bool {
+	for _, milestone := range s.Milestones {
+		if milestone.Escrow != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FindMilestone returns the cheque's milestone with the given ID, or nil when it has none
+func (s *SmartCheque) FindMilestone(milestoneID string) *Milestone {
+	for i := range s.Milestones {
+		if s.Milestones[i].ID == milestoneID {
+			return &s.Milestones[i]
+		}
+	}
+	return nil
+}
+
+// ResolveMilestoneEscrow records that a milestone's escrow was finished or cancelled by
+// transactionID, returning false when the milestone has no escrow or it was already resolved
+func (s *SmartCheque) ResolveMilestoneEscrow(milestoneID string, status MilestoneEscrowStatus, transactionID string, at time.Time) bool {
+	for i := range s.Milestones {
+		escrow := s.Milestones[i].Escrow
+		if s.Milestones[i].ID != milestoneID || escrow == nil {
+			continue
+		}
+		if escrow.Status != MilestoneEscrowStatusActive {
+			return false
+		}
+		escrow.Status = status
+		escrow.ResolvedBy = transactionID
+		escrow.ResolvedAt = &at
+		escrow.PendingTransaction = ""
+		escrow.LastError = ""
+		return true
+	}
+	return false
+}
+
+// SubmitMilestoneEscrowResolution records that transactionID was submitted to finish or cancel a
+// milestone's escrow, which stays active until a validated ledger applies it. It returns false when
+// the milestone has no active escrow.
+func (s *SmartCheque) SubmitMilestoneEscrowResolution(milestoneID, transactionID string) bool {
+	milestone := s.FindMilestone(milestoneID)
+	if milestone == nil || milestone.Escrow == nil || milestone.Escrow.Status != MilestoneEscrowStatusActive {
+		return false
+	}
+	milestone.Escrow.PendingTransaction = transactionID
+	return true
+}
+
+// FailMilestoneEscrowResolution records that the pending transactionID did not resolve a milestone's
+// escrow, which stays active. It returns false when transactionID is not the pending resolution.
+func (s *SmartCheque) FailMilestoneEscrowResolution(milestoneID, transactionID, reason string) bool {
+	milestone := s.FindMilestone(milestoneID)
+	if milestone == nil || milestone.Escrow == nil || milestone.Escrow.PendingTransaction != transactionID {
+		return false
+	}
+	milestone.Escrow.PendingTransaction = ""
+	milestone.Escrow.LastError = reason
+	return true
+}
+
+// StatusFromMilestoneEscrows derives the cheque status from the states of its milestone
+// escrows: locked while every escrow holds its funds and in progress once some are released.
+// When no escrow holds funds any more the cheque is completed if all were released, partially
+// paid if some were and refunded if none were. It returns false for a cheque without milestone
+// escrows.
+func (s *SmartCheque) StatusFromMilestoneEscrows() (SmartChequeStatus, bool) {
+	escrows, finished, cancelled := 0, 0, 0
+	for _, milestone := range s.Milestones {
+		if milestone.Escrow == nil {
+			continue
+		}
+		escrows++
+		switch milestone.Escrow.Status {
+		case MilestoneEscrowStatusFinished:
+			finished++
+		case MilestoneEscrowStatusCancelled:
+			cancelled++
+		}
+	}
+
+	switch {
+	case escrows == 0:
+		return s.Status, false
+	case finished == escrows:
+		return SmartChequeStatusCompleted, true
+	case finished+cancelled == escrows && finished > 0:
+		return SmartChequeStatusPartiallyPaid, true
+	case cancelled == escrows:
+		return SmartChequeStatusRefunded, true
+	case finished > 0:
+		return SmartChequeStatusInProgress, true
+	default:
+		return SmartChequeStatusLocked, true
+	}
+}
+
+// ActiveMilestoneEscrows returns the milestone escrows still holding funds
+func (s *SmartCheque) ActiveMilestoneEscrows() []*MilestoneEscrow {
+	var active []*MilestoneEscrow
+	for i := range s.Milestones {
+		if escrow := s.Milestones[i].Escrow; escrow != nil && escrow.Status == MilestoneEscrowStatusActive {
+			active = append(active, escrow)
+		}
+	}
+	return active
+}
+
+// ResolvingMilestoneEscrows returns the active milestone escrows waiting for a submitted finish or
+// cancellation to validate
+func (s *SmartCheque) ResolvingMilestoneEscrows() []*MilestoneEscrow {
+	var resolving []*MilestoneEscrow
+	for _, escrow := range s.ActiveMilestoneEscrows() {
+		if escrow.Resolving() {
+			resolving = append(resolving, escrow)
+		}
+	}
+	return resolving
+}
+
+// HasPaidMilestones reports whether any milestone was paid out, verified or released from its escrow
+func (s *SmartCheque) HasPaidMilestones() bool {
+	for _, milestone := range s.Milestones {
+		if milestone.Status == MilestoneStatusVerified {
+			return true
+		}
+		if milestone.Escrow != nil && milestone.Escrow.Status == MilestoneEscrowStatusFinished {
+			return true
+		}
+	}
+	return false
+}
+
 type MilestoneStatus string
 
 const (
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmartChequeEndorsement assigns the proceeds of some of a smart cheque's unpaid milestones from
+// their current holder to another enterprise, such as a financier buying the payee's receivable.
+// Each endorser must hold the milestones it assigns, so a cheque's endorsements in sequence order
+// form the chain the proceeds passed along.
+type SmartChequeEndorsement struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	SmartChequeID string    `json:"smart_cheque_id" db:"smart_cheque_id"`
+	// Sequence orders the cheque's endorsements, starting at 1
+	Sequence        int    `json:"sequence" db:"sequence"`
+	EndorserID      string `json:"endorser_id" db:"endorser_id"`
+	EndorseeID      string `json:"endorsee_id" db:"endorsee_id"`
+	EndorseeAddress string `json:"endorsee_address" db:"endorsee_address"`
+	// MilestoneIDs are the milestones whose proceeds are assigned; Amount is what they pay out
+	MilestoneIDs []string          `json:"milestone_ids" db:"milestone_ids"`
+	Amount       Money             `json:"amount" db:"amount"`
+	Currency     Currency          `json:"currency" db:"currency"`
+	Status       EndorsementStatus `json:"status" db:"status"`
+	// RequiresAcknowledgement is set when the contract behind the cheque makes the assignment
+	// wait for the payer to acknowledge it
+	RequiresAcknowledgement bool       `json:"requires_acknowledgement" db:"requires_acknowledgement"`
+	AcknowledgedBy          string     `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	AcknowledgedAt          *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	Notes                   string     `json:"notes,omitempty" db:"notes"`
+	CreatedAt               time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type EndorsementStatus string
+
+const (
+	// EndorsementStatusPendingAcknowledgement is an endorsement waiting for the payer to acknowledge it
+	EndorsementStatusPendingAcknowledgement EndorsementStatus = "pending_acknowledgement"
+	// EndorsementStatusEffective is an endorsement whose endorsee now holds the milestones' proceeds
+	EndorsementStatusEffective EndorsementStatus = "effective"
+	// EndorsementStatusDeclined is an endorsement the payer refused; the proceeds stayed with the endorser
+	EndorsementStatusDeclined EndorsementStatus = "declined"
+)
+
+// MilestoneHolder is the enterprise a milestone's proceeds were endorsed to and the account they
+// are paid into
+type MilestoneHolder struct {
+	EnterpriseID string `json:"enterprise_id"`
+	Address      string `json:"address"`
+	// EndorsementID is the endorsement that made the enterprise the holder
+	EndorsementID string `json:"endorsement_id"`
+}
+
+// MilestoneHolderID returns the enterprise holding a milestone's proceeds: the last endorsee, or
+// the payee when the milestone was never endorsed
+func (s *SmartCheque) MilestoneHolderID(milestone *Milestone) string {
+	if milestone.Holder != nil {
+		return milestone.Holder.EnterpriseID
+	}
+	return s.PayeeID
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmartChequeStatusTransition records a smart cheque moving from one status to another, kept as
+// the cheque's status history
+type SmartChequeStatusTransition struct {
+	ID            uuid.UUID              `json:"id" db:"id"`
+	SmartChequeID string                 `json:"smart_cheque_id" db:"smart_cheque_id"`
+	FromStatus    SmartChequeStatus      `json:"from_status" db:"from_status"`
+	ToStatus      SmartChequeStatus      `json:"to_status" db:"to_status"`
+	Reason        string                 `json:"reason,omitempty" db:"reason"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+}
+
+// NewSmartChequeStatusTransition creates the record of a smart cheque leaving its current status for to
+func NewSmartChequeStatusTransition(smartCheque *SmartCheque, to SmartChequeStatus, reason string) *SmartChequeStatusTransition {
+	return &SmartChequeStatusTransition{
+		ID:            uuid.New(),
+		SmartChequeID: smartCheque.ID,
+		FromStatus:    smartCheque.Status,
+		ToStatus:      to,
+		Reason:        reason,
+		Metadata:      make(map[string]interface{}),
+		CreatedAt:     time.Now(),
+	}
+}
@@ -33,6 +33,9 @@ const (
 	TransactionTypeEscrowCreate TransactionType = "escrow_create"
 	TransactionTypeEscrowFinish TransactionType = "escrow_finish"
 	TransactionTypeEscrowCancel TransactionType = "escrow_cancel"
+	TransactionTypeCheckCreate  TransactionType = "check_create"
+	TransactionTypeCheckCash    TransactionType = "check_cash"
+	TransactionTypeCheckCancel  TransactionType = "check_cancel"
 	TransactionTypePayment      TransactionType = "payment"
 	TransactionTypeWalletSetup  TransactionType = "wallet_setup"
 )
@@ -61,11 +64,21 @@ type Transaction struct {
 	Amount      string `json:"amount" gorm:"type:varchar(255);not null"`
 	Currency    string `json:"currency" gorm:"type:varchar(10);not null;default:'XRP'"`
 	Fee         string `json:"fee" gorm:"type:varchar(255)"`
+	// NetworkFee is the open ledger cost in drops when the fee was last set
+	NetworkFee string `json:"network_fee,omitempty" gorm:"type:varchar(255)"`
+	// DeliveredAmount and DeliveredCurrency are what a converting payment delivered, and
+	// ExchangeRate the delivered units it obtained per unit of Currency spent
+	DeliveredAmount   string `json:"delivered_amount,omitempty" gorm:"type:varchar(255)"`
+	DeliveredCurrency string `json:"delivered_currency,omitempty" gorm:"type:varchar(10)"`
+	ExchangeRate      string `json:"exchange_rate,omitempty" gorm:"type:varchar(255)"`
 
 	// XRPL Specific Fields
-	Sequence        *uint32 `json:"sequence,omitempty" gorm:"type:int"`
-	LedgerIndex     *uint32 `json:"ledger_index,omitempty" gorm:"type:int"`
-	TransactionHash string  `json:"transaction_hash,omitempty" gorm:"type:varchar(255);index"`
+	Sequence           *uint32 `json:"sequence,omitempty" gorm:"type:int"`
+	TicketSequence     *uint32 `json:"ticket_sequence,omitempty" gorm:"type:int"`
+	LedgerIndex        *uint32 `json:"ledger_index,omitempty" gorm:"type:int"`
+	LastLedgerSequence *uint32 `json:"last_ledger_sequence,omitempty" gorm:"type:int"`
+	TransactionHash    string  `json:"transaction_hash,omitempty" gorm:"type:varchar(255);index"`
+	ResultCode         string  `json:"result_code,omitempty" gorm:"type:varchar(32)"`
 
 	// Escrow Specific Fields
 	Condition     string  `json:"condition,omitempty" gorm:"type:text"`
@@ -389,6 +402,12 @@ func (t *Transaction) SetError(err error) {
 	t.UpdatedAt = time.Now()
 }
 
+// SetTerminalError records an error that retrying cannot fix and stops further retries
+func (t *Transaction) SetTerminalError(err error) {
+	t.SetError(err)
+	t.MaxRetries = t.RetryCount
+}
+
 // IsExpired checks if the transaction has expired
 func (t *Transaction) IsExpired() bool {
 	if t.ExpiresAt == nil {
@@ -118,6 +118,20 @@ func TestTransaction_SetError(t *testing.T) {
 	assert.True(t, tx.UpdatedAt.After(oldUpdateTime))
 }
 
+func TestTransaction_SetTerminalError(t *testing.T) {
+	tx := &Transaction{
+		Status:     TransactionStatusProcessing,
+		RetryCount: 1,
+		MaxRetries: 3,
+	}
+
+	tx.SetTerminalError(assert.AnError)
+
+	assert.Equal(t, TransactionStatusFailed, tx.Status)
+	assert.Equal(t, 1, tx.RetryCount)
+	assert.False(t, tx.CanRetry())
+}
+
 func TestTransaction_IsExpired(t *testing.T) {
 	tx := &Transaction{}
 
@@ -16,6 +16,8 @@ const (
 	WalletStatusActive      WalletStatus = "active"
 	WalletStatusSuspended   WalletStatus = "suspended"
 	WalletStatusDeactivated WalletStatus = "deactivated"
+	// WalletStatusRetired marks a wallet whose XRPL account was deleted and its XRP swept to treasury
+	WalletStatusRetired WalletStatus = "retired"
 )
 
 // WalletMetadata is a custom type for handling JSONB in PostgreSQL
@@ -25,10 +25,10 @@ func (r *PostgresContractRepository) CreateContract(ctx context.Context, c *mode
 	query := `
 		INSERT INTO contracts (
 			id, parties, status, contract_type, version, parent_contract_id,
-			expiration_date, renewal_terms, created_at, updated_at
+			expiration_date, renewal_terms, assignment_requires_consent, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6,
-			$7, $8, $9, $10
+			$7, $8, $9, $10, $11
 		)`
 
 	var parent interface{}
@@ -48,6 +48,7 @@ func (r *PostgresContractRepository) CreateContract(ctx context.Context, c *mode
 		parent,
 		c.ExpirationDate,
 		c.RenewalTerms,
+		c.AssignmentRequiresConsent,
 		c.CreatedAt,
 		c.UpdatedAt,
 	)
@@ -61,7 +62,7 @@ func (r *PostgresContractRepository) CreateContract(ctx context.Context, c *mode
 func (r *PostgresContractRepository) GetContractByID(ctx context.Context, id string) (*models.Contract, error) {
 	query := `
 		SELECT id, parties, status, contract_type, version, parent_contract_id,
-		       expiration_date, renewal_terms, created_at, updated_at
+		       expiration_date, renewal_terms, assignment_requires_consent, created_at, updated_at
 		FROM contracts
 		WHERE id = $1`
 
@@ -80,6 +81,7 @@ func (r *PostgresContractRepository) GetContractByID(ctx context.Context, id str
 		&parent,
 		&expiration,
 		&c.RenewalTerms,
+		&c.AssignmentRequiresConsent,
 		&c.CreatedAt,
 		&c.UpdatedAt,
 	)
@@ -106,7 +108,8 @@ func (r *PostgresContractRepository) UpdateContract(ctx context.Context, c *mode
 	query := `
 		UPDATE contracts SET
 			parties = $2, status = $3, contract_type = $4, version = $5,
-			parent_contract_id = $6, expiration_date = $7, renewal_terms = $8, updated_at = $9
+			parent_contract_id = $6, expiration_date = $7, renewal_terms = $8,
+			assignment_requires_consent = $9, updated_at = $10
 		WHERE id = $1`
 
 	var parent interface{}
@@ -126,6 +129,7 @@ func (r *PostgresContractRepository) UpdateContract(ctx context.Context, c *mode
 		parent,
 		c.ExpirationDate,
 		c.RenewalTerms,
+		c.AssignmentRequiresConsent,
 		c.UpdatedAt,
 	)
 	if err != nil {
@@ -176,6 +180,7 @@ func (r *PostgresContractRepository) scanContracts(rows *sql.Rows) ([]*models.Co
 			&parent,
 			&expiration,
 			&c.RenewalTerms,
+			&c.AssignmentRequiresConsent,
 			&c.CreatedAt,
 			&c.UpdatedAt,
 		); err != nil {
@@ -200,7 +205,7 @@ func (r *PostgresContractRepository) scanContracts(rows *sql.Rows) ([]*models.Co
 func (r *PostgresContractRepository) GetContractsByStatus(ctx context.Context, status string, limit, offset int) ([]*models.Contract, error) {
 	query := `
 		SELECT id, parties, status, contract_type, version, parent_contract_id,
-		       expiration_date, renewal_terms, created_at, updated_at
+		       expiration_date, renewal_terms, assignment_requires_consent, created_at, updated_at
 		FROM contracts
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -219,7 +224,7 @@ func (r *PostgresContractRepository) GetContractsByStatus(ctx context.Context, s
 func (r *PostgresContractRepository) GetContractsByType(ctx context.Context, contractType string, limit, offset int) ([]*models.Contract, error) {
 	query := `
 		SELECT id, parties, status, contract_type, version, parent_contract_id,
-		       expiration_date, renewal_terms, created_at, updated_at
+		       expiration_date, renewal_terms, assignment_requires_consent, created_at, updated_at
 		FROM contracts
 		WHERE contract_type = $1
 		ORDER BY created_at DESC
@@ -238,7 +243,7 @@ func (r *PostgresContractRepository) GetContractsByType(ctx context.Context, con
 func (r *PostgresContractRepository) GetContractsByParty(ctx context.Context, party string, limit, offset int) ([]*models.Contract, error) {
 	query := `
 		SELECT id, parties, status, contract_type, version, parent_contract_id,
-		       expiration_date, renewal_terms, created_at, updated_at
+		       expiration_date, renewal_terms, assignment_requires_consent, created_at, updated_at
 		FROM contracts
 		WHERE $1 = ANY(parties)
 		ORDER BY created_at DESC
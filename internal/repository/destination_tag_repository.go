@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// destinationTagRepository implements DestinationTagRepositoryInterface
+type destinationTagRepository struct {
+	db *sql.DB
+}
+
+// NewDestinationTagRepository creates a new destination tag repository
+func NewDestinationTagRepository(db *sql.DB) DestinationTagRepositoryInterface {
+	return &destinationTagRepository{db: db}
+}
+
+// CreateDestinationTag allocates the wallet's next unused tag. Tags start at 1 and are never reused;
+// two concurrent allocations on one wallet conflict on the unique tag rather than sharing it.
+func (r *destinationTagRepository) CreateDestinationTag(ctx context.Context, tag *models.DestinationTag) error {
+	query := `
+		INSERT INTO destination_tags (id, wallet_address, tag, enterprise_id, smart_cheque_id, created_at)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(tag), 0) + 1 FROM destination_tags WHERE wallet_address = $2), $3, $4, $5)
+		RETURNING tag
+	`
+
+	if tag.ID == uuid.Nil {
+		tag.ID = uuid.New()
+	}
+	tag.CreatedAt = time.Now()
+
+	var allocated int64
+	err := r.db.QueryRowContext(ctx, query, tag.ID, tag.WalletAddress, tag.EnterpriseID, tag.SmartChequeID, tag.CreatedAt).Scan(&allocated)
+	if err != nil {
+		return fmt.Errorf("failed to allocate destination tag on %s: %w", tag.WalletAddress, err)
+	}
+	tag.Tag = uint32(allocated)
+	return nil
+}
+
+// GetDestinationTag returns the allocation of a tag on a wallet
+func (r *destinationTagRepository) GetDestinationTag(ctx context.Context, walletAddress string, tag uint32) (*models.DestinationTag, error) {
+	query := `
+		SELECT id, wallet_address, tag, enterprise_id, smart_cheque_id, created_at
+		FROM destination_tags
+		WHERE wallet_address = $1 AND tag = $2
+	`
+	return r.getDestinationTag(ctx, query, walletAddress, int64(tag))
+}
+
+// GetEnterpriseDestinationTag returns the enterprise-wide tag allocated to an enterprise on a wallet
+func (r *destinationTagRepository) GetEnterpriseDestinationTag(ctx context.Context, walletAddress string, enterpriseID uuid.UUID) (*models.DestinationTag, error) {
+	query := `
+		SELECT id, wallet_address, tag, enterprise_id, smart_cheque_id, created_at
+		FROM destination_tags
+		WHERE wallet_address = $1 AND enterprise_id = $2 AND smart_cheque_id IS NULL
+	`
+	return r.getDestinationTag(ctx, query, walletAddress, enterpriseID)
+}
+
+// GetSmartChequeDestinationTag returns the tag allocated to a smart cheque
+func (r *destinationTagRepository) GetSmartChequeDestinationTag(ctx context.Context, smartChequeID string) (*models.DestinationTag, error) {
+	query := `
+		SELECT id, wallet_address, tag, enterprise_id, smart_cheque_id, created_at
+		FROM destination_tags
+		WHERE smart_cheque_id = $1
+	`
+	return r.getDestinationTag(ctx, query, smartChequeID)
+}
+
+func (r *destinationTagRepository) getDestinationTag(ctx context.Context, query string, args ...interface{}) (*models.DestinationTag, error) {
+	var tag models.DestinationTag
+	var value int64
+	var smartChequeID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&tag.ID,
+		&tag.WalletAddress,
+		&value,
+		&tag.EnterpriseID,
+		&smartChequeID,
+		&tag.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get destination tag: %w", err)
+	}
+
+	tag.Tag = uint32(value)
+	if smartChequeID.Valid {
+		tag.SmartChequeID = &smartChequeID.String
+	}
+	return &tag, nil
+}
+
+// SaveQuarantinedDeposit records a quarantined deposit; a deposit already recorded is skipped
+func (r *destinationTagRepository) SaveQuarantinedDeposit(ctx context.Context, deposit *models.QuarantinedDeposit) error {
+	query := `
+		INSERT INTO quarantined_deposits (
+			id, wallet_address, transaction_hash, sender, destination_tag, delivered_value,
+			delivered_currency, reason, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (transaction_hash) DO NOTHING
+	`
+
+	if deposit.ID == uuid.Nil {
+		deposit.ID = uuid.New()
+	}
+	if deposit.Status == "" {
+		deposit.Status = models.QuarantinedDepositStatusPending
+	}
+	deposit.CreatedAt = time.Now()
+
+	var tag sql.NullInt64
+	if deposit.DestinationTag != nil {
+		tag = sql.NullInt64{Int64: int64(*deposit.DestinationTag), Valid: true}
+	}
+	_, err := r.db.ExecContext(
+		ctx, query,
+		deposit.ID,
+		deposit.WalletAddress,
+		deposit.TransactionHash,
+		deposit.Sender,
+		tag,
+		deposit.DeliveredValue,
+		deposit.DeliveredCurrency,
+		deposit.Reason,
+		deposit.Status,
+		deposit.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine deposit %s: %w", deposit.TransactionHash, err)
+	}
+	return nil
+}
+
+const quarantinedDepositColumns = `
+	id, wallet_address, transaction_hash, sender, destination_tag, delivered_value, delivered_currency,
+	reason, status, enterprise_id, asset_transaction_id, created_at, released_at
+`
+
+// GetQuarantinedDeposit returns a quarantined deposit by ID
+func (r *destinationTagRepository) GetQuarantinedDeposit(ctx context.Context, id uuid.UUID) (*models.QuarantinedDeposit, error) {
+	query := `SELECT ` + quarantinedDepositColumns + ` FROM quarantined_deposits WHERE id = $1`
+
+	deposit, err := scanQuarantinedDeposit(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get quarantined deposit: %w", err)
+	}
+	return deposit, nil
+}
+
+// GetQuarantinedDeposits lists quarantined deposits in a status, oldest first
+func (r *destinationTagRepository) GetQuarantinedDeposits(ctx context.Context, status models.QuarantinedDepositStatus, limit, offset int) ([]*models.QuarantinedDeposit, error) {
+	query := `SELECT ` + quarantinedDepositColumns + `
+		FROM quarantined_deposits
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quarantined deposits: %w", err)
+	}
+	defer rows.Close()
+
+	deposits := []*models.QuarantinedDeposit{}
+	for rows.Next() {
+		deposit, err := scanQuarantinedDeposit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined deposit: %w", err)
+		}
+		deposits = append(deposits, deposit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quarantined deposits: %w", err)
+	}
+
+	return deposits, nil
+}
+
+// UpdateQuarantinedDeposit records the review outcome of a quarantined deposit
+func (r *destinationTagRepository) UpdateQuarantinedDeposit(ctx context.Context, deposit *models.QuarantinedDeposit) error {
+	query := `
+		UPDATE quarantined_deposits
+		SET status = $2, enterprise_id = $3, asset_transaction_id = $4, released_at = $5
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, deposit.ID, deposit.Status, deposit.EnterpriseID, deposit.AssetTransactionID, deposit.ReleasedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update quarantined deposit: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("quarantined deposit not found: %s", deposit.ID)
+	}
+	return nil
+}
+
+// quarantinedDepositScanner is satisfied by *sql.Row and *sql.Rows
+type quarantinedDepositScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQuarantinedDeposit(row quarantinedDepositScanner) (*models.QuarantinedDeposit, error) {
+	var deposit models.QuarantinedDeposit
+	var tag sql.NullInt64
+	var enterpriseID, assetTransactionID uuid.NullUUID
+	var releasedAt sql.NullTime
+	if err := row.Scan(
+		&deposit.ID,
+		&deposit.WalletAddress,
+		&deposit.TransactionHash,
+		&deposit.Sender,
+		&tag,
+		&deposit.DeliveredValue,
+		&deposit.DeliveredCurrency,
+		&deposit.Reason,
+		&deposit.Status,
+		&enterpriseID,
+		&assetTransactionID,
+		&deposit.CreatedAt,
+		&releasedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if tag.Valid {
+		value := uint32(tag.Int64)
+		deposit.DestinationTag = &value
+	}
+	if enterpriseID.Valid {
+		deposit.EnterpriseID = &enterpriseID.UUID
+	}
+	if assetTransactionID.Valid {
+		deposit.AssetTransactionID = &assetTransactionID.UUID
+	}
+	if releasedAt.Valid {
+		deposit.ReleasedAt = &releasedAt.Time
+	}
+	return &deposit, nil
+}
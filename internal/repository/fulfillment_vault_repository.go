@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// fulfillmentVaultRepository implements FulfillmentVaultRepositoryInterface
+type fulfillmentVaultRepository struct {
+	db *sql.DB
+}
+
+// NewFulfillmentVaultRepository creates a new fulfillment vault repository
+func NewFulfillmentVaultRepository(db *sql.DB) FulfillmentVaultRepositoryInterface {
+	return &fulfillmentVaultRepository{db: db}
+}
+
+// CreateEscrowFulfillment stores a sealed fulfillment
+func (r *fulfillmentVaultRepository) CreateEscrowFulfillment(ctx context.Context, fulfillment *models.EscrowFulfillment) error {
+	query := `
+		INSERT INTO escrow_fulfillments (
+			id, smart_cheque_id, milestone_id, condition, encrypted_fulfillment,
+			escrow_owner, escrow_destination, offer_sequence, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	if fulfillment.ID == uuid.Nil {
+		fulfillment.ID = uuid.New()
+	}
+	now := time.Now()
+	fulfillment.CreatedAt = now
+	fulfillment.UpdatedAt = now
+
+	_, err := r.db.ExecContext(
+		ctx, query,
+		fulfillment.ID,
+		fulfillment.SmartChequeID,
+		fulfillment.MilestoneID,
+		fulfillment.Condition,
+		fulfillment.EncryptedFulfillment,
+		fulfillment.EscrowOwner,
+		fulfillment.EscrowDestination,
+		int64(fulfillment.OfferSequence),
+		fulfillment.CreatedAt,
+		fulfillment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create escrow fulfillment: %w", err)
+	}
+
+	return nil
+}
+
+// GetEscrowFulfillment retrieves the sealed fulfillment for a smart cheque milestone
+func (r *fulfillmentVaultRepository) GetEscrowFulfillment(ctx context.Context, smartChequeID, milestoneID string) (*models.EscrowFulfillment, error) {
+	query := `
+		SELECT id, smart_cheque_id, milestone_id, condition, encrypted_fulfillment,
+		       escrow_owner, escrow_destination, offer_sequence, release_count,
+		       last_released_at, created_at, updated_at
+		FROM escrow_fulfillments
+		WHERE smart_cheque_id = $1 AND milestone_id = $2
+	`
+
+	var fulfillment models.EscrowFulfillment
+	var offerSequence int64
+	err := r.db.QueryRowContext(ctx, query, smartChequeID, milestoneID).Scan(
+		&fulfillment.ID,
+		&fulfillment.SmartChequeID,
+		&fulfillment.MilestoneID,
+		&fulfillment.Condition,
+		&fulfillment.EncryptedFulfillment,
+		&fulfillment.EscrowOwner,
+		&fulfillment.EscrowDestination,
+		&offerSequence,
+		&fulfillment.ReleaseCount,
+		&fulfillment.LastReleasedAt,
+		&fulfillment.CreatedAt,
+		&fulfillment.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get escrow fulfillment: %w", err)
+	}
+
+	fulfillment.OfferSequence = uint32(offerSequence)
+	return &fulfillment, nil
+}
+
+// RecordFulfillmentRelease increments the release counter of a sealed fulfillment
+func (r *fulfillmentVaultRepository) RecordFulfillmentRelease(ctx context.Context, id uuid.UUID, releasedAt time.Time) error {
+	query := `
+		UPDATE escrow_fulfillments
+		SET release_count = release_count + 1, last_released_at = $1, updated_at = $1
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, releasedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to record fulfillment release: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("escrow fulfillment not found: %s", id)
+	}
+
+	return nil
+}
+
+// CreateFulfillmentAccessLog records an access to the fulfillment vault
+func (r *fulfillmentVaultRepository) CreateFulfillmentAccessLog(ctx context.Context, entry *models.FulfillmentAccessLog) error {
+	query := `
+		INSERT INTO escrow_fulfillment_access_logs (
+			id, smart_cheque_id, milestone_id, action, actor, success, reason, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(
+		ctx, query,
+		entry.ID,
+		entry.SmartChequeID,
+		entry.MilestoneID,
+		string(entry.Action),
+		entry.Actor,
+		entry.Success,
+		entry.Reason,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create fulfillment access log: %w", err)
+	}
+
+	return nil
+}
+
+// GetFulfillmentAccessLogs retrieves the access history of a smart cheque milestone, newest first
+func (r *fulfillmentVaultRepository) GetFulfillmentAccessLogs(ctx context.Context, smartChequeID, milestoneID string, limit, offset int) ([]*models.FulfillmentAccessLog, error) {
+	query := `
+		SELECT id, smart_cheque_id, milestone_id, action, actor, success, reason, created_at
+		FROM escrow_fulfillment_access_logs
+		WHERE smart_cheque_id = $1 AND milestone_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, smartChequeID, milestoneID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fulfillment access logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.FulfillmentAccessLog
+	for rows.Next() {
+		var entry models.FulfillmentAccessLog
+		var action string
+		var reason sql.NullString
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.SmartChequeID,
+			&entry.MilestoneID,
+			&action,
+			&entry.Actor,
+			&entry.Success,
+			&reason,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan fulfillment access log: %w", err)
+		}
+		entry.Action = models.FulfillmentAccessAction(action)
+		entry.Reason = reason.String
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate fulfillment access logs: %w", err)
+	}
+
+	return entries, nil
+}
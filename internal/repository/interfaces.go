@@ -56,11 +56,23 @@ type XRPLServiceInterface interface {
 	ValidateAddress(address string) bool
 	GetAccountInfo(address string) (interface{}, error)
 	HealthCheck() error
-	CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount float64, currency string, milestoneSecret string) (*xrpl.TransactionResult, string, error)
-	CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount float64, currency string, milestones []models.Milestone) (*xrpl.TransactionResult, string, error)
+	GetAccountReserve(address string) (*xrpl.AccountReserve, error)
+	DeleteAccount(address, destination string) (*xrpl.TransactionResult, error)
+	WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error)
+	CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string) (*xrpl.TransactionResult, string, error)
+	CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount models.Money, milestones []models.Milestone) ([]models.MilestoneEscrowFunding, error)
 	CompleteSmartChequeMilestone(payeeAddress, ownerAddress string, sequence uint32, condition, fulfillment string) (*xrpl.TransactionResult, error)
 	CancelSmartCheque(accountAddress, ownerAddress string, sequence uint32) (*xrpl.TransactionResult, error)
 	GetEscrowStatus(ownerAddress string, sequence string) (*xrpl.EscrowInfo, error)
+	ListEscrows(ownerAddress string) ([]xrpl.EscrowInfo, error)
+	FindEscrowResolution(ownerAddress string, sequence, sinceLedger uint32) (*xrpl.EscrowResolution, error)
+	GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error)
+	CreateSmartChequeCheck(payerAddress, payeeAddress string, amount models.Money, validFor time.Duration, invoiceID string) (*xrpl.TransactionResult, error)
+	CashSmartChequeCheck(payeeAddress, checkID string, amount models.Money, minimum bool) (*xrpl.TransactionResult, error)
+	CancelSmartChequeCheck(accountAddress, checkID string) (*xrpl.TransactionResult, error)
+	GetCheck(checkID string) (*xrpl.CheckInfo, error)
+	FindCheckResolution(sourceAddress, checkID string, sinceLedger uint32) (*xrpl.CheckResolution, error)
+	SendPayment(fromAddress, toAddress string, amount models.Money) (*xrpl.TransactionResult, error)
 	GenerateCondition(secret string) (condition string, fulfillment string, err error)
 }
 
@@ -72,6 +84,41 @@ type AuditRepositoryInterface interface {
 	GetAuditLogsByEnterprise(enterpriseID uuid.UUID, limit, offset int) ([]models.AuditLog, error)
 }
 
+// FulfillmentVaultRepositoryInterface defines the interface for sealed escrow fulfillment storage
+type FulfillmentVaultRepositoryInterface interface {
+	CreateEscrowFulfillment(ctx context.Context, fulfillment *models.EscrowFulfillment) error
+	GetEscrowFulfillment(ctx context.Context, smartChequeID, milestoneID string) (*models.EscrowFulfillment, error)
+	RecordFulfillmentRelease(ctx context.Context, id uuid.UUID, releasedAt time.Time) error
+	CreateFulfillmentAccessLog(ctx context.Context, entry *models.FulfillmentAccessLog) error
+	GetFulfillmentAccessLogs(ctx context.Context, smartChequeID, milestoneID string, limit, offset int) ([]*models.FulfillmentAccessLog, error)
+}
+
+// LedgerTransactionRepositoryInterface defines the interface for the indexed on-ledger history of
+// managed accounts and the per-account indexing checkpoints
+type LedgerTransactionRepositoryInterface interface {
+	SaveLedgerTransactions(ctx context.Context, transactions []*models.LedgerTransaction) error
+	GetLedgerTransactionsByAccount(ctx context.Context, account string, sinceLedger uint32, limit, offset int) ([]*models.LedgerTransaction, error)
+	GetLedgerCheckpoint(ctx context.Context, account string) (*models.LedgerCheckpoint, error)
+	SaveLedgerCheckpoint(ctx context.Context, checkpoint *models.LedgerCheckpoint) error
+}
+
+// DestinationTagRepositoryInterface defines the interface for destination tags allocated on shared
+// receiving wallets and for the deposits quarantined because no tag routes them. Lookups return nil
+// when nothing matches.
+type DestinationTagRepositoryInterface interface {
+	// CreateDestinationTag allocates the wallet's next unused tag and sets it on tag
+	CreateDestinationTag(ctx context.Context, tag *models.DestinationTag) error
+	GetDestinationTag(ctx context.Context, walletAddress string, tag uint32) (*models.DestinationTag, error)
+	GetEnterpriseDestinationTag(ctx context.Context, walletAddress string, enterpriseID uuid.UUID) (*models.DestinationTag, error)
+	GetSmartChequeDestinationTag(ctx context.Context, smartChequeID string) (*models.DestinationTag, error)
+
+	// SaveQuarantinedDeposit records a deposit once; a deposit already quarantined is left as it is
+	SaveQuarantinedDeposit(ctx context.Context, deposit *models.QuarantinedDeposit) error
+	GetQuarantinedDeposit(ctx context.Context, id uuid.UUID) (*models.QuarantinedDeposit, error)
+	GetQuarantinedDeposits(ctx context.Context, status models.QuarantinedDepositStatus, limit, offset int) ([]*models.QuarantinedDeposit, error)
+	UpdateQuarantinedDeposit(ctx context.Context, deposit *models.QuarantinedDeposit) error
+}
+
 // TransactionRepositoryInterface defines the interface for transaction repository operations
 type TransactionRepositoryInterface interface {
 	// Transaction CRUD operations
@@ -105,6 +152,15 @@ type TransactionRepositoryInterface interface {
 	GetTransactionCountByStatus() (map[models.TransactionStatus]int64, error)
 }
 
+// SmartChequeTransactionRepositoryInterface records the XRPL transactions that settle smart checks;
+// every TransactionRepositoryInterface satisfies it
+type SmartChequeTransactionRepositoryInterface interface {
+	CreateTransaction(transaction *models.Transaction) error
+	UpdateTransaction(transaction *models.Transaction) error
+	GetTransactionsBySmartChequeID(smartChequeID string, limit, offset int) ([]*models.Transaction, error)
+	GetTransactionsByStatus(status models.TransactionStatus, limit, offset int) ([]*models.Transaction, error)
+}
+
 // AssetRepositoryInterface defines the interface for asset repository operations
 type AssetRepositoryInterface interface {
 	// Asset CRUD operations
@@ -158,6 +214,7 @@ type BalanceRepositoryInterface interface {
 	GetEnterpriseBalances(ctx context.Context, enterpriseID uuid.UUID) ([]*models.EnterpriseBalance, error)
 	UpdateBalance(ctx context.Context, balance *models.EnterpriseBalance) error
 	UpdateEnterpriseBalance(ctx context.Context, balance *models.EnterpriseBalance) error
+	GetAssetTransactionsByType(ctx context.Context, txType models.AssetTransactionType, limit, offset int) ([]*models.AssetTransaction, error)
 
 	// Balance queries
 	GetEnterpriseBalanceSummary(ctx context.Context, enterpriseID uuid.UUID) ([]*models.EnterpriseBalanceSummary, error)
@@ -379,6 +436,23 @@ type SmartChequeRepositoryInterface interface {
 	GetSmartChequePerformanceMetrics(ctx context.Context, filters *SmartChequeFilter) (*SmartChequePerformanceMetrics, error)
 }
 
+// SmartChequeTransitionRepositoryInterface defines the interface for the status history of smart checks
+type SmartChequeTransitionRepositoryInterface interface {
+	CreateStatusTransition(ctx context.Context, transition *models.SmartChequeStatusTransition) error
+	// GetStatusTransitions returns a smart check's transitions, oldest first
+	GetStatusTransitions(ctx context.Context, smartChequeID string) ([]*models.SmartChequeStatusTransition, error)
+}
+
+// SmartChequeEndorsementRepositoryInterface defines the interface for the endorsement chains of smart checks
+type SmartChequeEndorsementRepositoryInterface interface {
+	CreateEndorsement(ctx context.Context, endorsement *models.SmartChequeEndorsement) error
+	UpdateEndorsement(ctx context.Context, endorsement *models.SmartChequeEndorsement) error
+	// GetEndorsementByID returns nil when there is no such endorsement
+	GetEndorsementByID(ctx context.Context, id uuid.UUID) (*models.SmartChequeEndorsement, error)
+	// GetEndorsementsBySmartChequeID returns a smart check's endorsements in sequence order
+	GetEndorsementsBySmartChequeID(ctx context.Context, smartChequeID string) ([]*models.SmartChequeEndorsement, error)
+}
+
 // SmartChequeComplianceReport represents a compliance report for a smart check
 type SmartChequeComplianceReport struct {
 	SmartChequeID       string                    `json:"smart_check_id"`
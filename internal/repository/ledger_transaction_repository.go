@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// ledgerTransactionRepository implements LedgerTransactionRepositoryInterface
+type ledgerTransactionRepository struct {
+	db *sql.DB
+}
+
+// NewLedgerTransactionRepository creates a new ledger transaction repository
+func NewLedgerTransactionRepository(db *sql.DB) LedgerTransactionRepositoryInterface {
+	return &ledgerTransactionRepository{db: db}
+}
+
+// SaveLedgerTransactions records indexed transactions in one database transaction; transactions
+// already recorded for the same account are skipped, so a page can be indexed again safely
+func (r *ledgerTransactionRepository) SaveLedgerTransactions(ctx context.Context, transactions []*models.LedgerTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO ledger_transactions (
+			id, account, transaction_hash, ledger_index, transaction_type, sender, destination, sequence,
+			amount_value, amount_currency, amount_issuer, delivered_value, delivered_currency, delivered_issuer,
+			partial_payment, fee_drops, result_code, ledger_time, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (account, transaction_hash) DO NOTHING
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	for _, ledgerTx := range transactions {
+		if ledgerTx.ID == uuid.Nil {
+			ledgerTx.ID = uuid.New()
+		}
+		ledgerTx.CreatedAt = now
+
+		var ledgerTime *time.Time
+		if !ledgerTx.LedgerTime.IsZero() {
+			ledgerTime = &ledgerTx.LedgerTime
+		}
+		_, err := tx.ExecContext(
+			ctx, query,
+			ledgerTx.ID,
+			ledgerTx.Account,
+			ledgerTx.TransactionHash,
+			int64(ledgerTx.LedgerIndex),
+			ledgerTx.TransactionType,
+			ledgerTx.Sender,
+			ledgerTx.Destination,
+			int64(ledgerTx.Sequence),
+			ledgerTx.AmountValue,
+			ledgerTx.AmountCurrency,
+			ledgerTx.AmountIssuer,
+			ledgerTx.DeliveredValue,
+			ledgerTx.DeliveredCurrency,
+			ledgerTx.DeliveredIssuer,
+			ledgerTx.PartialPayment,
+			ledgerTx.FeeDrops,
+			ledgerTx.ResultCode,
+			ledgerTime,
+			ledgerTx.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save ledger transaction %s: %w", ledgerTx.TransactionHash, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ledger transactions: %w", err)
+	}
+	return nil
+}
+
+// GetLedgerTransactionsByAccount lists an account's indexed transactions from sinceLedger onwards, oldest first
+func (r *ledgerTransactionRepository) GetLedgerTransactionsByAccount(ctx context.Context, account string, sinceLedger uint32, limit, offset int) ([]*models.LedgerTransaction, error) {
+	query := `
+		SELECT id, account, transaction_hash, ledger_index, transaction_type, sender, destination, sequence,
+		       amount_value, amount_currency, amount_issuer, delivered_value, delivered_currency, delivered_issuer,
+		       partial_payment, fee_drops, result_code, ledger_time, created_at
+		FROM ledger_transactions
+		WHERE account = $1 AND ledger_index >= $2
+		ORDER BY ledger_index ASC, created_at ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, account, int64(sinceLedger), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*models.LedgerTransaction{}
+	for rows.Next() {
+		var ledgerTx models.LedgerTransaction
+		var ledgerIndex, sequence int64
+		var ledgerTime sql.NullTime
+		if err := rows.Scan(
+			&ledgerTx.ID,
+			&ledgerTx.Account,
+			&ledgerTx.TransactionHash,
+			&ledgerIndex,
+			&ledgerTx.TransactionType,
+			&ledgerTx.Sender,
+			&ledgerTx.Destination,
+			&sequence,
+			&ledgerTx.AmountValue,
+			&ledgerTx.AmountCurrency,
+			&ledgerTx.AmountIssuer,
+			&ledgerTx.DeliveredValue,
+			&ledgerTx.DeliveredCurrency,
+			&ledgerTx.DeliveredIssuer,
+			&ledgerTx.PartialPayment,
+			&ledgerTx.FeeDrops,
+			&ledgerTx.ResultCode,
+			&ledgerTime,
+			&ledgerTx.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger transaction: %w", err)
+		}
+		ledgerTx.LedgerIndex = uint32(ledgerIndex)
+		ledgerTx.Sequence = uint32(sequence)
+		if ledgerTime.Valid {
+			ledgerTx.LedgerTime = ledgerTime.Time
+		}
+		transactions = append(transactions, &ledgerTx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ledger transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetLedgerCheckpoint returns an account's indexing checkpoint, or nil when it was never indexed
+func (r *ledgerTransactionRepository) GetLedgerCheckpoint(ctx context.Context, account string) (*models.LedgerCheckpoint, error) {
+	query := `SELECT account, ledger_index, updated_at FROM ledger_checkpoints WHERE account = $1`
+
+	var checkpoint models.LedgerCheckpoint
+	var ledgerIndex int64
+	err := r.db.QueryRowContext(ctx, query, account).Scan(&checkpoint.Account, &ledgerIndex, &checkpoint.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ledger checkpoint: %w", err)
+	}
+
+	checkpoint.LedgerIndex = uint32(ledgerIndex)
+	return &checkpoint, nil
+}
+
+// SaveLedgerCheckpoint records an account's indexing checkpoint; it never moves a checkpoint backwards
+func (r *ledgerTransactionRepository) SaveLedgerCheckpoint(ctx context.Context, checkpoint *models.LedgerCheckpoint) error {
+	query := `
+		INSERT INTO ledger_checkpoints (account, ledger_index, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account) DO UPDATE
+		SET ledger_index = GREATEST(ledger_checkpoints.ledger_index, EXCLUDED.ledger_index),
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	checkpoint.UpdatedAt = time.Now()
+	if _, err := r.db.ExecContext(ctx, query, checkpoint.Account, int64(checkpoint.LedgerIndex), checkpoint.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save ledger checkpoint: %w", err)
+	}
+	return nil
+}
@@ -126,6 +126,10 @@ func (m *BalanceRepositoryInterface) CreateEnterpriseBalance(ctx context.Context
 	args := m.Called(ctx, balance)
 	return args.Error(0)
 }
+func (m *BalanceRepositoryInterface) GetAssetTransactionsByType(ctx context.Context, txType models.AssetTransactionType, limit, offset int) ([]*models.AssetTransaction, error) {
+	args := m.Called(ctx, txType, limit, offset)
+	return args.Get(0).([]*models.AssetTransaction), args.Error(1)
+}
 
 // XRPLServiceInterface mock
 type XRPLServiceInterface struct {
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// smartChequeEndorsementRepository implements SmartChequeEndorsementRepositoryInterface
+type smartChequeEndorsementRepository struct {
+	db *sql.DB
+}
+
+// NewSmartChequeEndorsementRepository creates a new smart check endorsement repository
+func NewSmartChequeEndorsementRepository(db *sql.DB) SmartChequeEndorsementRepositoryInterface {
+	return &smartChequeEndorsementRepository{db: db}
+}
+
+const endorsementColumns = `id, smart_cheque_id, sequence, endorser_id, endorsee_id, endorsee_address, milestone_ids,
+		       amount, currency, status, requires_acknowledgement, acknowledged_by, acknowledged_at, notes,
+		       created_at, updated_at`
+
+// CreateEndorsement appends an endorsement to a smart check's endorsement chain
+func (r *smartChequeEndorsementRepository) CreateEndorsement(ctx context.Context, endorsement *models.SmartChequeEndorsement) error {
+	query := `
+		INSERT INTO smart_cheque_endorsements (` + endorsementColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	if endorsement.ID == uuid.Nil {
+		endorsement.ID = uuid.New()
+	}
+	_, err := r.db.ExecContext(
+		ctx, query,
+		endorsement.ID,
+		endorsement.SmartChequeID,
+		endorsement.Sequence,
+		endorsement.EndorserID,
+		endorsement.EndorseeID,
+		endorsement.EndorseeAddress,
+		pq.Array(endorsement.MilestoneIDs),
+		endorsement.Amount,
+		endorsement.Currency,
+		endorsement.Status,
+		endorsement.RequiresAcknowledgement,
+		acknowledgedBy(endorsement),
+		endorsement.AcknowledgedAt,
+		endorsement.Notes,
+		endorsement.CreatedAt,
+		endorsement.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record endorsement %d of smart check %s: %w", endorsement.Sequence, endorsement.SmartChequeID, err)
+	}
+	return nil
+}
+
+// UpdateEndorsement records an endorsement being acknowledged or declined
+func (r *smartChequeEndorsementRepository) UpdateEndorsement(ctx context.Context, endorsement *models.SmartChequeEndorsement) error {
+	query := `
+		UPDATE smart_cheque_endorsements SET
+			status = $2, acknowledged_by = $3, acknowledged_at = $4, notes = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	res, err := r.db.ExecContext(
+		ctx, query,
+		endorsement.ID,
+		endorsement.Status,
+		acknowledgedBy(endorsement),
+		endorsement.AcknowledgedAt,
+		endorsement.Notes,
+		endorsement.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update endorsement %s: %w", endorsement.ID, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("endorsement %s not found", endorsement.ID)
+	}
+	return nil
+}
+
+// GetEndorsementByID retrieves an endorsement by ID
+func (r *smartChequeEndorsementRepository) GetEndorsementByID(ctx context.Context, id uuid.UUID) (*models.SmartChequeEndorsement, error) {
+	query := `
+		SELECT ` + endorsementColumns + `
+		FROM smart_cheque_endorsements
+		WHERE id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	endorsements, err := scanEndorsements(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(endorsements) == 0 {
+		return nil, nil
+	}
+	return endorsements[0], nil
+}
+
+// GetEndorsementsBySmartChequeID returns a smart check's endorsement chain in sequence order
+func (r *smartChequeEndorsementRepository) GetEndorsementsBySmartChequeID(ctx context.Context, smartChequeID string) ([]*models.SmartChequeEndorsement, error) {
+	query := `
+		SELECT ` + endorsementColumns + `
+		FROM smart_cheque_endorsements
+		WHERE smart_cheque_id = $1
+		ORDER BY sequence
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, smartChequeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsements of smart check %s: %w", smartChequeID, err)
+	}
+	defer rows.Close()
+
+	return scanEndorsements(rows)
+}
+
+// acknowledgedBy is the enterprise that acknowledged or declined an endorsement, or NULL before then
+func acknowledgedBy(endorsement *models.SmartChequeEndorsement) interface{} {
+	if endorsement.AcknowledgedBy == "" {
+		return nil
+	}
+	return endorsement.AcknowledgedBy
+}
+
+// scanEndorsements reads endorsement rows selected with endorsementColumns
+func scanEndorsements(rows *sql.Rows) ([]*models.SmartChequeEndorsement, error) {
+	var endorsements []*models.SmartChequeEndorsement
+	for rows.Next() {
+		var endorsement models.SmartChequeEndorsement
+		var currency string
+		var acknowledger, notes sql.NullString
+		var acknowledgedAt sql.NullTime
+		if err := rows.Scan(
+			&endorsement.ID,
+			&endorsement.SmartChequeID,
+			&endorsement.Sequence,
+			&endorsement.EndorserID,
+			&endorsement.EndorseeID,
+			&endorsement.EndorseeAddress,
+			pq.Array(&endorsement.MilestoneIDs),
+			&endorsement.Amount,
+			&currency,
+			&endorsement.Status,
+			&endorsement.RequiresAcknowledgement,
+			&acknowledger,
+			&acknowledgedAt,
+			&notes,
+			&endorsement.CreatedAt,
+			&endorsement.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan endorsement: %w", err)
+		}
+		endorsement.Currency = models.Currency(currency)
+		endorsement.Amount = endorsement.Amount.WithCurrency(endorsement.Currency)
+		endorsement.AcknowledgedBy = acknowledger.String
+		endorsement.Notes = notes.String
+		if acknowledgedAt.Valid {
+			endorsement.AcknowledgedAt = &acknowledgedAt.Time
+		}
+		endorsements = append(endorsements, &endorsement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating endorsements: %w", err)
+	}
+	return endorsements, nil
+}
@@ -28,9 +28,9 @@ func (r *smartChequeRepository) CreateSmartCheque(ctx context.Context, smartCheq
 	query := `
 		INSERT INTO smart_checks (
 			id, payer_id, payee_id, amount, currency, 
-			milestones, escrow_address, status, contract_hash, 
+			milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	// Convert milestones to JSON
@@ -48,6 +48,9 @@ func (r *smartChequeRepository) CreateSmartCheque(ctx context.Context, smartCheq
 		string(smartCheque.Currency),
 		milestonesJSON,
 		smartCheque.EscrowAddress,
+		string(smartCheque.SettlementCurrency),
+		string(smartCheque.SettlementMode),
+		smartCheque.CheckID,
 		string(smartCheque.Status),
 		smartCheque.ContractHash,
 		smartCheque.CreatedAt,
@@ -81,9 +84,9 @@ func (r *smartChequeRepository) BatchCreateSmartCheques(ctx context.Context, sma
 	query := `
 		INSERT INTO smart_checks (
 			id, payer_id, payee_id, amount, currency, 
-			milestones, escrow_address, status, contract_hash, 
+			milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -109,6 +112,9 @@ func (r *smartChequeRepository) BatchCreateSmartCheques(ctx context.Context, sma
 			string(smartCheque.Currency),
 			milestonesJSON,
 			smartCheque.EscrowAddress,
+			string(smartCheque.SettlementCurrency),
+			string(smartCheque.SettlementMode),
+			smartCheque.CheckID,
 			string(smartCheque.Status),
 			smartCheque.ContractHash,
 			smartCheque.CreatedAt,
@@ -132,7 +138,7 @@ func (r *smartChequeRepository) BatchCreateSmartCheques(ctx context.Context, sma
 func (r *smartChequeRepository) GetSmartChequeByID(ctx context.Context, id string) (*models.SmartCheque, error) {
 	query := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE id = $1
@@ -151,6 +157,9 @@ func (r *smartChequeRepository) GetSmartChequeByID(ctx context.Context, id strin
 		&currencyStr,
 		&milestonesJSON,
 		&smartCheque.EscrowAddress,
+		&smartCheque.SettlementCurrency,
+		&smartCheque.SettlementMode,
+		&smartCheque.CheckID,
 		&statusStr,
 		&smartCheque.ContractHash,
 		&smartCheque.CreatedAt,
@@ -182,9 +191,9 @@ func (r *smartChequeRepository) UpdateSmartCheque(ctx context.Context, smartCheq
 	query := `
 		UPDATE smart_checks 
 		SET payer_id = $1, payee_id = $2, amount = $3, currency = $4, 
-		    milestones = $5, escrow_address = $6, status = $7, contract_hash = $8, 
-		    updated_at = $9
-		WHERE id = $10
+		    milestones = $5, escrow_address = $6, settlement_currency = $7, settlement_mode = $8, 
+		    check_id = $9, status = $10, contract_hash = $11, updated_at = $12
+		WHERE id = $13
 	`
 
 	// Convert milestones to JSON
@@ -201,6 +210,9 @@ func (r *smartChequeRepository) UpdateSmartCheque(ctx context.Context, smartCheq
 		string(smartCheque.Currency),
 		milestonesJSON,
 		smartCheque.EscrowAddress,
+		string(smartCheque.SettlementCurrency),
+		string(smartCheque.SettlementMode),
+		smartCheque.CheckID,
 		string(smartCheque.Status),
 		smartCheque.ContractHash,
 		smartCheque.UpdatedAt,
@@ -252,7 +264,7 @@ func (r *smartChequeRepository) GetSmartChequesByPayer(ctx context.Context, paye
 func (r *smartChequeRepository) getSmartChequesByEntity(ctx context.Context, entityID string, entityColumn string, limit, offset int) ([]*models.SmartCheque, error) {
 	query := fmt.Sprintf(`
 		SELECT id, payer_id, payee_id, amount, currency,
-		       milestones, escrow_address, status, contract_hash,
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash,
 		       created_at, updated_at
 		FROM smart_checks
 		WHERE %s = $1
@@ -281,6 +293,9 @@ func (r *smartChequeRepository) getSmartChequesByEntity(ctx context.Context, ent
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&statusStr,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
@@ -315,7 +330,7 @@ func (r *smartChequeRepository) getSmartChequesByEntity(ctx context.Context, ent
 func (r *smartChequeRepository) GetSmartChequesByPayee(ctx context.Context, payeeID string, limit, offset int) ([]*models.SmartCheque, error) {
 	query := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE payee_id = $1
@@ -344,6 +359,9 @@ func (r *smartChequeRepository) GetSmartChequesByPayee(ctx context.Context, paye
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&statusStr,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
@@ -378,7 +396,7 @@ func (r *smartChequeRepository) GetSmartChequesByPayee(ctx context.Context, paye
 func (r *smartChequeRepository) GetSmartChequesByStatus(ctx context.Context, status models.SmartChequeStatus, limit, offset int) ([]*models.SmartCheque, error) {
 	query := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE status = $1
@@ -406,6 +424,9 @@ func (r *smartChequeRepository) GetSmartChequesByStatus(ctx context.Context, sta
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
 			&smartCheque.UpdatedAt,
@@ -444,7 +465,7 @@ func (r *smartChequeRepository) GetSmartChequesByContract(ctx context.Context, c
 func (r *smartChequeRepository) GetSmartChequesByMilestone(ctx context.Context, milestoneID string) (*models.SmartCheque, error) {
 	query := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE milestones @> $1
@@ -466,6 +487,9 @@ func (r *smartChequeRepository) GetSmartChequesByMilestone(ctx context.Context,
 		&currencyStr,
 		&milestonesJSON,
 		&smartCheque.EscrowAddress,
+		&smartCheque.SettlementCurrency,
+		&smartCheque.SettlementMode,
+		&smartCheque.CheckID,
 		&statusStr,
 		&smartCheque.ContractHash,
 		&smartCheque.CreatedAt,
@@ -577,7 +601,7 @@ func (r *smartChequeRepository) GetRecentSmartCheques(ctx context.Context, limit
 
 	query := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		ORDER BY created_at DESC
@@ -605,6 +629,9 @@ func (r *smartChequeRepository) GetRecentSmartCheques(ctx context.Context, limit
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&statusStr,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
@@ -689,9 +716,9 @@ func (r *smartChequeRepository) BatchUpdateSmartCheques(ctx context.Context, sma
 	query := `
 		UPDATE smart_checks 
 		SET payer_id = $1, payee_id = $2, amount = $3, currency = $4, 
-		    milestones = $5, escrow_address = $6, status = $7, contract_hash = $8, 
-		    updated_at = $9
-		WHERE id = $10
+		    milestones = $5, escrow_address = $6, settlement_currency = $7, settlement_mode = $8, 
+		    check_id = $9, status = $10, contract_hash = $11, updated_at = $12
+		WHERE id = $13
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -716,6 +743,9 @@ func (r *smartChequeRepository) BatchUpdateSmartCheques(ctx context.Context, sma
 			string(smartCheque.Currency),
 			milestonesJSON,
 			smartCheque.EscrowAddress,
+			string(smartCheque.SettlementCurrency),
+			string(smartCheque.SettlementMode),
+			smartCheque.CheckID,
 			string(smartCheque.Status),
 			smartCheque.ContractHash,
 			smartCheque.UpdatedAt,
@@ -795,7 +825,7 @@ func (r *smartChequeRepository) BatchGetSmartCheques(ctx context.Context, ids []
 
 	query := fmt.Sprintf(`
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE id IN (%s)
@@ -823,6 +853,9 @@ func (r *smartChequeRepository) BatchGetSmartCheques(ctx context.Context, ids []
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&statusStr,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
@@ -1426,7 +1459,7 @@ func (r *smartChequeRepository) SearchSmartCheques(ctx context.Context, query st
 	// Build search query - search in payer_id, payee_id, contract_hash, and id fields
 	searchQuery := `
 		SELECT id, payer_id, payee_id, amount, currency, 
-		       milestones, escrow_address, status, contract_hash, 
+		       milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, 
 		       created_at, updated_at
 		FROM smart_checks 
 		WHERE id ILIKE $1 OR payer_id ILIKE $1 OR payee_id ILIKE $1 OR contract_hash ILIKE $1
@@ -1458,6 +1491,9 @@ func (r *smartChequeRepository) SearchSmartCheques(ctx context.Context, query st
 			&currencyStr,
 			&milestonesJSON,
 			&smartCheque.EscrowAddress,
+			&smartCheque.SettlementCurrency,
+			&smartCheque.SettlementMode,
+			&smartCheque.CheckID,
 			&statusStr,
 			&smartCheque.ContractHash,
 			&smartCheque.CreatedAt,
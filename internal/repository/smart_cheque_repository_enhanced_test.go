@@ -137,11 +137,11 @@ func TestSmartChequeRepository_GetSmartChequeAnalyticsByPayer(t *testing.T) {
 			AddRow(10000.0, 1000.0, 5000.0, 100.0))
 
 	// Mock recent activity query (GetSmartChequesByPayer)
-	mock.ExpectQuery("SELECT id, payer_id, payee_id, amount, currency, milestones, escrow_address, status, contract_hash, created_at, updated_at FROM smart_checks WHERE payer_id = \\$1 ORDER BY created_at DESC LIMIT \\$2 OFFSET \\$3").
+	mock.ExpectQuery("SELECT id, payer_id, payee_id, amount, currency, milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, created_at, updated_at FROM smart_checks WHERE payer_id = \\$1 ORDER BY created_at DESC LIMIT \\$2 OFFSET \\$3").
 		WithArgs(payerID, 10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "status", "contract_hash", "created_at", "updated_at"}).
-			AddRow("1", payerID, "payee1", 1000.0, "USDT", []byte("[]"), "", "created", "", time.Now(), time.Now()).
-			AddRow("2", payerID, "payee2", 2000.0, "USDC", []byte("[]"), "", "in_progress", "", time.Now(), time.Now()))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "settlement_currency", "settlement_mode", "check_id", "status", "contract_hash", "created_at", "updated_at"}).
+			AddRow("1", payerID, "payee1", 1000.0, "USDT", []byte("[]"), "", "", "", "", "created", "", time.Now(), time.Now()).
+			AddRow("2", payerID, "payee2", 2000.0, "USDC", []byte("[]"), "", "", "", "", "in_progress", "", time.Now(), time.Now()))
 
 	// Mock trends query
 	mock.ExpectQuery("SELECT DATE\\(created_at\\) as creation_date, COUNT\\(\\*\\) as count FROM smart_checks WHERE payer_id = \\$1 AND created_at >= CURRENT_DATE - INTERVAL '30 days' GROUP BY DATE\\(created_at\\) ORDER BY creation_date").
@@ -38,11 +38,11 @@ func TestSmartChequeRepository_GetSmartChequeAnalyticsByPayee(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"total_amount", "average_amount", "largest_amount", "smallest_amount"}).
 			AddRow(10000.0, 1000.0, 5000.0, 100.0))
 
-	mock.ExpectQuery("SELECT id, payer_id, payee_id, amount, currency, milestones, escrow_address, status, contract_hash, created_at, updated_at FROM smart_checks WHERE payee_id = \\$1 ORDER BY created_at DESC LIMIT \\$2 OFFSET \\$3").
+	mock.ExpectQuery("SELECT id, payer_id, payee_id, amount, currency, milestones, escrow_address, settlement_currency, settlement_mode, check_id, status, contract_hash, created_at, updated_at FROM smart_checks WHERE payee_id = \\$1 ORDER BY created_at DESC LIMIT \\$2 OFFSET \\$3").
 		WithArgs("payee1", 10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "status", "contract_hash", "created_at", "updated_at"}).
-			AddRow("1", "payer1", "payee1", 1000.0, "USDT", []byte("[]"), "", "created", "", time.Now(), time.Now()).
-			AddRow("2", "payer1", "payee1", 2000.0, "USDC", []byte("[]"), "", "in_progress", "", time.Now(), time.Now()))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "settlement_currency", "settlement_mode", "check_id", "status", "contract_hash", "created_at", "updated_at"}).
+			AddRow("1", "payer1", "payee1", 1000.0, "USDT", []byte("[]"), "", "", "", "", "created", "", time.Now(), time.Now()).
+			AddRow("2", "payer1", "payee1", 2000.0, "USDC", []byte("[]"), "", "", "", "", "in_progress", "", time.Now(), time.Now()))
 
 	mock.ExpectQuery("SELECT DATE\\(created_at\\) as creation_date, COUNT\\(\\*\\) as count FROM smart_checks WHERE payee_id = \\$1 AND created_at >= CURRENT_DATE - INTERVAL '30 days' GROUP BY DATE\\(created_at\\) ORDER BY creation_date").
 		WithArgs("payee1").
@@ -83,9 +83,9 @@ func TestSmartChequeRepository_BatchGetSmartCheques(t *testing.T) {
 	now := time.Now()
 	mock.ExpectQuery("SELECT id, payer_id, payee_id, amount, currency.*").
 		WithArgs("id1", "id2").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "status", "contract_hash", "created_at", "updated_at"}).
-			AddRow("id1", "payer1", "payee1", 1000.0, "USDT", []byte("[]"), "", "created", "", now, now).
-			AddRow("id2", "payer2", "payee2", 2000.0, "USDC", []byte("[]"), "", "in_progress", "", now, now))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payer_id", "payee_id", "amount", "currency", "milestones", "escrow_address", "settlement_currency", "settlement_mode", "check_id", "status", "contract_hash", "created_at", "updated_at"}).
+			AddRow("id1", "payer1", "payee1", 1000.0, "USDT", []byte("[]"), "", "", "", "", "created", "", now, now).
+			AddRow("id2", "payer2", "payee2", 2000.0, "USDC", []byte("[]"), "", "", "", "", "in_progress", "", now, now))
 
 	checks, err = repo.BatchGetSmartCheques(context.Background(), []string{"id1", "id2"})
 	require.NoError(t, err)
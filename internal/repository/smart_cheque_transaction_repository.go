@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// smartChequeTransactionRepository implements SmartChequeTransactionRepositoryInterface on database/sql
+type smartChequeTransactionRepository struct {
+	db *sql.DB
+}
+
+// NewSmartChequeTransactionRepository creates a repository recording smart check transactions in
+// the transactions table
+func NewSmartChequeTransactionRepository(db *sql.DB) SmartChequeTransactionRepositoryInterface {
+	return &smartChequeTransactionRepository{db: db}
+}
+
+const smartChequeTransactionColumns = `
+	id, type, status, priority, batch_id, from_address, to_address, amount, currency,
+	COALESCE(fee, ''), COALESCE(network_fee, ''), COALESCE(delivered_amount, ''), COALESCE(delivered_currency, ''),
+	COALESCE(exchange_rate, ''), sequence, ticket_sequence, ledger_index, last_ledger_sequence,
+	COALESCE(transaction_hash, ''), COALESCE(result_code, ''), COALESCE(condition, ''), COALESCE(fulfillment, ''),
+	cancel_after, finish_after, offer_sequence, smart_cheque_id, milestone_id, enterprise_id, user_id,
+	retry_count, max_retries, COALESCE(last_error, ''), metadata,
+	created_at, updated_at, scheduled_at, processed_at, confirmed_at, expires_at
+`
+
+// CreateTransaction records a transaction
+func (r *smartChequeTransactionRepository) CreateTransaction(transaction *models.Transaction) error {
+	query := `
+		INSERT INTO transactions (
+			id, type, status, priority, batch_id, from_address, to_address, amount, currency,
+			fee, network_fee, delivered_amount, delivered_currency, exchange_rate,
+			sequence, ticket_sequence, ledger_index, last_ledger_sequence, transaction_hash, result_code,
+			condition, fulfillment, cancel_after, finish_after, offer_sequence,
+			smart_cheque_id, milestone_id, enterprise_id, user_id,
+			retry_count, max_retries, last_error, metadata,
+			created_at, updated_at, scheduled_at, processed_at, confirmed_at, expires_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39
+		)
+	`
+
+	metadataJSON, err := json.Marshal(transaction.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction metadata: %w", err)
+	}
+
+	now := time.Now()
+	if transaction.CreatedAt.IsZero() {
+		transaction.CreatedAt = now
+	}
+	transaction.UpdatedAt = now
+
+	_, err = r.db.Exec(
+		query,
+		transaction.ID,
+		string(transaction.Type),
+		string(transaction.Status),
+		int(transaction.Priority),
+		transaction.BatchID,
+		transaction.FromAddress,
+		transaction.ToAddress,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Fee,
+		transaction.NetworkFee,
+		transaction.DeliveredAmount,
+		transaction.DeliveredCurrency,
+		transaction.ExchangeRate,
+		transaction.Sequence,
+		transaction.TicketSequence,
+		transaction.LedgerIndex,
+		transaction.LastLedgerSequence,
+		transaction.TransactionHash,
+		transaction.ResultCode,
+		transaction.Condition,
+		transaction.Fulfillment,
+		transaction.CancelAfter,
+		transaction.FinishAfter,
+		transaction.OfferSequence,
+		transaction.SmartChequeID,
+		transaction.MilestoneID,
+		transaction.EnterpriseID,
+		transaction.UserID,
+		transaction.RetryCount,
+		transaction.MaxRetries,
+		transaction.LastError,
+		metadataJSON,
+		transaction.CreatedAt,
+		transaction.UpdatedAt,
+		transaction.ScheduledAt,
+		transaction.ProcessedAt,
+		transaction.ConfirmedAt,
+		transaction.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+	return nil
+}
+
+// UpdateTransaction records what the ledger settled a transaction as
+func (r *smartChequeTransactionRepository) UpdateTransaction(transaction *models.Transaction) error {
+	query := `
+		UPDATE transactions SET
+			status = $2, ledger_index = $3, transaction_hash = $4, result_code = $5,
+			delivered_amount = $6, delivered_currency = $7, exchange_rate = $8,
+			retry_count = $9, last_error = $10, metadata = $11,
+			updated_at = $12, processed_at = $13, confirmed_at = $14
+		WHERE id = $1
+	`
+
+	metadataJSON, err := json.Marshal(transaction.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction metadata: %w", err)
+	}
+
+	transaction.UpdatedAt = time.Now()
+	result, err := r.db.Exec(
+		query,
+		transaction.ID,
+		string(transaction.Status),
+		transaction.LedgerIndex,
+		transaction.TransactionHash,
+		transaction.ResultCode,
+		transaction.DeliveredAmount,
+		transaction.DeliveredCurrency,
+		transaction.ExchangeRate,
+		transaction.RetryCount,
+		transaction.LastError,
+		metadataJSON,
+		transaction.UpdatedAt,
+		transaction.ProcessedAt,
+		transaction.ConfirmedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("transaction not found: %s", transaction.ID)
+	}
+	return nil
+}
+
+// GetTransactionsBySmartChequeID lists a smart check's transactions, newest first
+func (r *smartChequeTransactionRepository) GetTransactionsBySmartChequeID(smartChequeID string, limit, offset int) ([]*models.Transaction, error) {
+	query := `SELECT ` + smartChequeTransactionColumns + `
+		FROM transactions
+		WHERE smart_cheque_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, smartChequeID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart check transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSmartChequeTransactions(rows)
+}
+
+// GetTransactionsByStatus lists the smart check transactions in a status, oldest first
+func (r *smartChequeTransactionRepository) GetTransactionsByStatus(status models.TransactionStatus, limit, offset int) ([]*models.Transaction, error) {
+	query := `SELECT ` + smartChequeTransactionColumns + `
+		FROM transactions
+		WHERE status = $1 AND smart_cheque_id IS NOT NULL
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s smart check transactions: %w", status, err)
+	}
+	defer rows.Close()
+
+	return scanSmartChequeTransactions(rows)
+}
+
+// scanSmartChequeTransactions reads transactions selected with smartChequeTransactionColumns
+func scanSmartChequeTransactions(rows *sql.Rows) ([]*models.Transaction, error) {
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		var transaction models.Transaction
+		var metadataJSON []byte
+		if err := rows.Scan(
+			&transaction.ID,
+			&transaction.Type,
+			&transaction.Status,
+			&transaction.Priority,
+			&transaction.BatchID,
+			&transaction.FromAddress,
+			&transaction.ToAddress,
+			&transaction.Amount,
+			&transaction.Currency,
+			&transaction.Fee,
+			&transaction.NetworkFee,
+			&transaction.DeliveredAmount,
+			&transaction.DeliveredCurrency,
+			&transaction.ExchangeRate,
+			&transaction.Sequence,
+			&transaction.TicketSequence,
+			&transaction.LedgerIndex,
+			&transaction.LastLedgerSequence,
+			&transaction.TransactionHash,
+			&transaction.ResultCode,
+			&transaction.Condition,
+			&transaction.Fulfillment,
+			&transaction.CancelAfter,
+			&transaction.FinishAfter,
+			&transaction.OfferSequence,
+			&transaction.SmartChequeID,
+			&transaction.MilestoneID,
+			&transaction.EnterpriseID,
+			&transaction.UserID,
+			&transaction.RetryCount,
+			&transaction.MaxRetries,
+			&transaction.LastError,
+			&metadataJSON,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+			&transaction.ScheduledAt,
+			&transaction.ProcessedAt,
+			&transaction.ConfirmedAt,
+			&transaction.ExpiresAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &transaction.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal transaction metadata: %w", err)
+			}
+		}
+		transactions = append(transactions, &transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read smart check transactions: %w", err)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+)
+
+// smartChequeTransitionRepository implements SmartChequeTransitionRepositoryInterface
+type smartChequeTransitionRepository struct {
+	db *sql.DB
+}
+
+// NewSmartChequeTransitionRepository creates a new smart check status history repository
+func NewSmartChequeTransitionRepository(db *sql.DB) SmartChequeTransitionRepositoryInterface {
+	return &smartChequeTransitionRepository{db: db}
+}
+
+// CreateStatusTransition appends a transition to a smart check's status history
+func (r *smartChequeTransitionRepository) CreateStatusTransition(ctx context.Context, transition *models.SmartChequeStatusTransition) error {
+	query := `
+		INSERT INTO smart_cheque_status_transitions (id, smart_cheque_id, from_status, to_status, reason, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if transition.ID == uuid.Nil {
+		transition.ID = uuid.New()
+	}
+	metadataJSON, err := json.Marshal(transition.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transition metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx, query,
+		transition.ID,
+		transition.SmartChequeID,
+		transition.FromStatus,
+		transition.ToStatus,
+		transition.Reason,
+		metadataJSON,
+		transition.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record transition of smart check %s from %s to %s: %w",
+			transition.SmartChequeID, transition.FromStatus, transition.ToStatus, err)
+	}
+	return nil
+}
+
+// GetStatusTransitions returns a smart check's status history, oldest first
+func (r *smartChequeTransitionRepository) GetStatusTransitions(ctx context.Context, smartChequeID string) ([]*models.SmartChequeStatusTransition, error) {
+	query := `
+		SELECT id, smart_cheque_id, from_status, to_status, reason, metadata, created_at
+		FROM smart_cheque_status_transitions
+		WHERE smart_cheque_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, smartChequeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history of smart check %s: %w", smartChequeID, err)
+	}
+	defer rows.Close()
+
+	var transitions []*models.SmartChequeStatusTransition
+	for rows.Next() {
+		var transition models.SmartChequeStatusTransition
+		var reason sql.NullString
+		var metadataJSON []byte
+		if err := rows.Scan(
+			&transition.ID,
+			&transition.SmartChequeID,
+			&transition.FromStatus,
+			&transition.ToStatus,
+			&reason,
+			&metadataJSON,
+			&transition.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan status transition: %w", err)
+		}
+		transition.Reason = reason.String
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &transition.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal transition metadata: %w", err)
+			}
+		}
+		transitions = append(transitions, &transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read status history of smart check %s: %w", smartChequeID, err)
+	}
+
+	return transitions, nil
+}
@@ -113,6 +113,9 @@ func (s *AssetService) RegisterAsset(ctx context.Context, req *AssetRegistryRequ
 	if err := s.assetRepo.CreateAsset(ctx, asset); err != nil {
 		return nil, fmt.Errorf("failed to create asset: %w", err)
 	}
+	if err := models.ConfigureCurrencyScales(asset); err != nil {
+		return nil, err
+	}
 
 	// Publish asset registration event
 	if s.messagingClient != nil {
@@ -178,6 +181,9 @@ func (s *AssetService) UpdateAsset(ctx context.Context, currencyCode string, req
 	if err := s.assetRepo.UpdateAsset(ctx, asset); err != nil {
 		return nil, fmt.Errorf("failed to update asset: %w", err)
 	}
+	if err := models.ConfigureCurrencyScales(asset); err != nil {
+		return nil, err
+	}
 
 	// Publish update event
 	if s.messagingClient != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 // BalanceService handles balance tracking and validation operations
@@ -394,6 +395,94 @@ func (s *BalanceService) SyncXRPLBalance(ctx context.Context, enterpriseID uuid.
 	return nil
 }
 
+// CreditXRPLDeposit credits an enterprise with what an inbound XRPL payment actually delivered.
+// Partial payments, and any payment that delivered less than its Amount, are credited at the
+// delivered amount and flagged for review.
+func (s *BalanceService) CreditXRPLDeposit(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, delivery *xrpl.PaymentDelivery) (*models.AssetTransaction, error) {
+	asset, err := s.assetRepo.GetAssetByCurrency(ctx, currencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported currency %s: %w", currencyCode, err)
+	}
+	amount, err := baseUnits(asset, delivery.Delivered)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delivered amount for %s: %w", delivery.TransactionID, err)
+	}
+
+	reviewRequired := delivery.PartialPayment || delivery.Short()
+	description := fmt.Sprintf("XRPL deposit %s from %s", delivery.TransactionID, delivery.Account)
+	transaction, err := s.ProcessBalanceOperation(ctx, &BalanceOperationRequest{
+		EnterpriseID:  enterpriseID,
+		CurrencyCode:  currencyCode,
+		Amount:        amount,
+		OperationType: models.AssetTransactionTypeDeposit,
+		ReferenceID:   &delivery.TransactionID,
+		Description:   &description,
+		Metadata: map[string]interface{}{
+			"external_tx_hash": delivery.TransactionID,
+			"declared_amount":  delivery.Amount.Value,
+			"delivered_amount": delivery.Delivered.Value,
+			"partial_payment":  delivery.PartialPayment,
+			"review_required":  reviewRequired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	transaction.ExternalTxHash = &delivery.TransactionID
+
+	if reviewRequired {
+		log.Printf("XRPL deposit %s to enterprise %s declared %s but delivered %s; flagged for review",
+			delivery.TransactionID, enterpriseID, delivery.Amount.Value, delivery.Delivered.Value)
+		if s.messagingClient != nil {
+			event := &messaging.Event{
+				Type:   "balance.deposit.review_required",
+				Source: "balance-service",
+				Data: map[string]interface{}{
+					"enterprise_id":    enterpriseID,
+					"currency_code":    currencyCode,
+					"transaction_id":   transaction.ID,
+					"external_tx_hash": delivery.TransactionID,
+					"declared_amount":  delivery.Amount.Value,
+					"delivered_amount": delivery.Delivered.Value,
+					"partial_payment":  delivery.PartialPayment,
+				},
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			if err := s.messagingClient.PublishEvent(event); err != nil {
+				log.Printf("Failed to publish deposit review event: %v", err)
+			}
+		}
+	}
+
+	return transaction, nil
+}
+
+// baseUnits converts an XRPL amount of an asset into its smallest units, truncating anything
+// finer. Issued amounts must come from the asset's configured issuer.
+func baseUnits(asset *models.SupportedAsset, amount xrpl.Amount) (string, error) {
+	if amount.IsNative() != asset.IsNative() {
+		return "", fmt.Errorf("amount %s is not denominated in %s", amount, asset.CurrencyCode)
+	}
+	if !amount.IsNative() {
+		issued, err := issuedCurrencyForAsset(asset)
+		if err != nil {
+			return "", err
+		}
+		if amount.Issuer != issued.Issuer || amount.Currency != issued.Currency {
+			return "", fmt.Errorf("amount %s is not issued by the %s issuer %s", amount, asset.CurrencyCode, issued.Issuer)
+		}
+	}
+
+	value, err := platformAmount(amount, models.Currency(asset.CurrencyCode))
+	if err != nil {
+		return "", err
+	}
+	if value, err = value.Truncate(asset.DecimalPlaces); err != nil {
+		return "", err
+	}
+	return asset.BaseUnits(value)
+}
+
 // ValidateBalanceConsistency checks if internal balance matches XRPL balance
 func (s *BalanceService) ValidateBalanceConsistency(ctx context.Context, enterpriseID uuid.UUID, currencyCode string) (*BalanceConsistencyReport, error) {
 	balance, err := s.balanceRepo.GetEnterpriseBalance(ctx, enterpriseID, currencyCode)
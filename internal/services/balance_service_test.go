@@ -9,8 +9,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 func createTestBalance(enterpriseID uuid.UUID, currencyCode string, available, reserved string) *models.EnterpriseBalance {
@@ -294,6 +296,50 @@ func TestBalanceService_ValidateBalanceConsistency(t *testing.T) {
 	}
 }
 
+func TestBalanceService_CreditXRPLDeposit(t *testing.T) {
+	enterpriseID := uuid.New()
+	issuer := newTestKeyPair(t).Address()
+	usdt, err := xrpl.NewIssuedCurrency("USDT", issuer)
+	require.NoError(t, err)
+	asset := createTestAsset("USDT", models.AssetTypeStablecoin, true)
+	asset.IssuerAddress = &issuer
+
+	declared, err := usdt.Amount("1000000")
+	require.NoError(t, err)
+	delivered, err := usdt.Amount("0.0123456789")
+	require.NoError(t, err)
+
+	assetRepo := &MockAssetRepository{}
+	balanceRepo := &MockBalanceRepository{}
+	service := NewBalanceService(balanceRepo, assetRepo, nil)
+	assetRepo.On("GetAssetByCurrency", mock.Anything, "USDT").Return(asset, nil)
+	balanceRepo.On("GetEnterpriseBalance", mock.Anything, enterpriseID, "USDT").Return(createTestBalance(enterpriseID, "USDT", "0", "0"), nil)
+	// The credit is the delivered 0.012345 USDT in base units, not the declared million
+	balanceRepo.On("UpdateBalance", mock.Anything, enterpriseID, "USDT", "12345", models.AssetTransactionTypeDeposit, mock.Anything).Return(nil).Once()
+
+	transaction, err := service.CreditXRPLDeposit(context.Background(), enterpriseID, "USDT", &xrpl.PaymentDelivery{
+		TransactionID:  "DEPOSITHASH",
+		Amount:         declared,
+		Delivered:      delivered,
+		PartialPayment: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "12345", transaction.Amount)
+	require.NotNil(t, transaction.ExternalTxHash)
+	assert.Equal(t, "DEPOSITHASH", *transaction.ExternalTxHash)
+	assert.Equal(t, true, transaction.Metadata["review_required"])
+	assert.Equal(t, "1000000", transaction.Metadata["declared_amount"])
+
+	// Tokens with the right code from any other issuer are not the platform's asset
+	counterfeit, err := xrpl.NewIssuedCurrency("USDT", newTestKeyPair(t).Address())
+	require.NoError(t, err)
+	fake, err := counterfeit.Amount("500")
+	require.NoError(t, err)
+	_, err = service.CreditXRPLDeposit(context.Background(), enterpriseID, "USDT", &xrpl.PaymentDelivery{TransactionID: "FAKEHASH", Amount: fake, Delivered: fake})
+	assert.ErrorContains(t, err, "not issued by the USDT issuer")
+	balanceRepo.AssertExpectations(t)
+}
+
 // Benchmark tests
 func BenchmarkBalanceService_CheckBalanceSufficiency(b *testing.B) {
 	enterpriseID := uuid.New()
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Cross-currency settlement errors
+var (
+	ErrQuoteNotFound = errors.New("settlement quote not found")
+	ErrQuoteExpired  = errors.New("settlement quote expired")
+)
+
+// CrossCurrencySettlementConfig bounds how far an executed conversion may drift from its quote
+type CrossCurrencySettlementConfig struct {
+	// MaxSlippage is the fraction by which a settlement may spend more, or deliver less, than
+	// quoted; defaults to 0.005
+	MaxSlippage float64
+	// QuoteTTL is how long a quote stays locked for execution; defaults to 30 seconds
+	QuoteTTL time.Duration
+}
+
+// settlementRatePlaces is how many decimal places a rate that does not terminate is rendered with
+const settlementRatePlaces = 18
+
+// SettlementRate is an exact exchange rate: the units of one currency obtained per unit of another.
+// The zero value is a zero rate.
+type SettlementRate struct {
+	value *big.Rat
+}
+
+// newSettlementRate returns the rate at which spent bought delivered; zero when nothing was spent
+func newSettlementRate(delivered, spent models.Money) SettlementRate {
+	if spent.IsZero() {
+		return SettlementRate{}
+	}
+	return SettlementRate{value: new(big.Rat).Quo(delivered.Rat(), spent.Rat())}
+}
+
+// Rat returns the exact rate as a fraction
+func (r SettlementRate) Rat() *big.Rat {
+	if r.value == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(r.value)
+}
+
+// String renders the rate as a decimal without trailing zeros, exact when it terminates within
+// settlementRatePlaces
+func (r SettlementRate) String() string {
+	value := r.Rat().FloatString(settlementRatePlaces)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
+
+// MarshalJSON encodes the rate as a JSON number such as 2 or 0.5
+func (r SettlementRate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// SettlementQuote is a locked price for delivering one currency by spending another
+type SettlementQuote struct {
+	ID                  string         `json:"id"`
+	SourceAccount       string         `json:"source_account"`
+	DestinationAccount  string         `json:"destination_account"`
+	SourceCurrency      string         `json:"source_currency"`
+	DestinationCurrency string         `json:"destination_currency"`
+	SourceAmount        models.Money   `json:"source_amount"`
+	DestinationAmount   models.Money   `json:"destination_amount"`
+	Rate                SettlementRate `json:"rate"`
+	QuotedAt            time.Time      `json:"quoted_at"`
+	ExpiresAt           time.Time      `json:"expires_at"`
+
+	// payment is the transaction that executes the quote within its slippage limits
+	payment *xrpl.Payment
+}
+
+// SettlementResult records what a settlement actually spent and delivered
+type SettlementResult struct {
+	QuoteID             string         `json:"quote_id"`
+	TransactionID       string         `json:"transaction_id"`
+	LedgerIndex         uint32         `json:"ledger_index"`
+	SourceCurrency      string         `json:"source_currency"`
+	DestinationCurrency string         `json:"destination_currency"`
+	SourceAmount        models.Money   `json:"source_amount"`
+	DeliveredAmount     models.Money   `json:"delivered_amount"`
+	QuotedRate          SettlementRate `json:"quoted_rate"`
+	ExecutedRate        SettlementRate `json:"executed_rate"`
+}
+
+// CrossCurrencySettlementService converts payouts between currencies through the XRPL order
+// books. A quote prices the conversion with path finding and stays locked for QuoteTTL; executing
+// it submits a payment whose SendMax or DeliverMin enforces the slippage limit on the ledger.
+type CrossCurrencySettlementService struct {
+	xrplService     *XRPLService
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface
+	config          CrossCurrencySettlementConfig
+	// maxSlippage is config.MaxSlippage as the exact decimal it was written as
+	maxSlippage *big.Rat
+
+	mu     sync.Mutex
+	quotes map[string]*SettlementQuote
+}
+
+// NewCrossCurrencySettlementService creates a settlement service; transactionRepo may be nil when
+// payouts are not recorded
+func NewCrossCurrencySettlementService(xrplService *XRPLService, transactionRepo repository.SmartChequeTransactionRepositoryInterface, config CrossCurrencySettlementConfig) *CrossCurrencySettlementService {
+	if config.MaxSlippage <= 0 {
+		config.MaxSlippage = 0.005
+	}
+	if config.QuoteTTL <= 0 {
+		config.QuoteTTL = 30 * time.Second
+	}
+	maxSlippage, _ := new(big.Rat).SetString(strconv.FormatFloat(config.MaxSlippage, 'g', -1, 64))
+	return &CrossCurrencySettlementService{
+		xrplService:     xrplService,
+		transactionRepo: transactionRepo,
+		config:          config,
+		maxSlippage:     maxSlippage,
+		quotes:          make(map[string]*SettlementQuote),
+	}
+}
+
+// QuoteDelivery prices delivering exactly amount, in its currency, to destination by spending
+// sourceCurrency from source. The payment spends at most the quoted source amount plus slippage.
+func (s *CrossCurrencySettlementService) QuoteDelivery(source, destination, sourceCurrency string, amount models.Money) (*SettlementQuote, error) {
+	destinationCurrency := string(amount.Currency())
+	if sourceCurrency == destinationCurrency {
+		return nil, fmt.Errorf("settlement requires different currencies, got %s for both", sourceCurrency)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	deliverAmount, err := amount.Round(amount.Currency().Scale())
+	if err != nil {
+		return nil, err
+	}
+	deliver, err := s.xrplService.paymentAmount(deliverAmount)
+	if err != nil {
+		return nil, err
+	}
+	spend, err := s.xrplService.ledgerCurrency(sourceCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	pathQuote, err := s.xrplService.FindPaths(&xrpl.PathFindRequest{
+		SourceAccount:      source,
+		DestinationAccount: destination,
+		DestinationAmount:  deliver,
+		SourceCurrencies:   []xrpl.IssuedCurrency{spend},
+	})
+	if err != nil {
+		return nil, err
+	}
+	best, err := pathQuote.Best()
+	if err != nil {
+		return nil, err
+	}
+	quotedSource, err := platformAmount(best.SourceAmount, models.Currency(sourceCurrency))
+	if err != nil {
+		return nil, err
+	}
+	sourceAmount, err := quotedSource.Round(quotedSource.Currency().Scale())
+	if err != nil {
+		return nil, err
+	}
+
+	sendMaxAmount, err := s.withSlippage(sourceAmount, 1)
+	if err != nil {
+		return nil, err
+	}
+	sendMax, err := s.xrplService.paymentAmount(sendMaxAmount)
+	if err != nil {
+		return nil, err
+	}
+	payment := &xrpl.Payment{
+		Account:     source,
+		Destination: destination,
+		Amount:      deliver,
+		SendMax:     &sendMax,
+		Paths:       best.Paths,
+	}
+	return s.lock(payment, sourceCurrency, destinationCurrency, sourceAmount, deliverAmount), nil
+}
+
+// QuoteConversion prices converting exactly amount, held by account in its currency, into
+// destinationCurrency on the same account. The payment delivers at least the quoted amount less
+// slippage.
+func (s *CrossCurrencySettlementService) QuoteConversion(account string, amount models.Money, destinationCurrency string) (*SettlementQuote, error) {
+	sourceCurrency := string(amount.Currency())
+	if sourceCurrency == destinationCurrency {
+		return nil, fmt.Errorf("settlement requires different currencies, got %s for both", sourceCurrency)
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	spendAmount, err := amount.Round(amount.Currency().Scale())
+	if err != nil {
+		return nil, err
+	}
+	sendMax, err := s.xrplService.paymentAmount(spendAmount)
+	if err != nil {
+		return nil, err
+	}
+	receive, err := s.xrplService.ledgerCurrency(destinationCurrency)
+	if err != nil {
+		return nil, err
+	}
+	deliverAll := xrpl.Amount{Value: "-1"}
+	if destinationCurrency != "XRP" {
+		deliverAll = xrpl.Amount{Currency: receive.Currency, Issuer: receive.Issuer, Value: "-1"}
+	}
+
+	pathQuote, err := s.xrplService.FindPaths(&xrpl.PathFindRequest{
+		SourceAccount:      account,
+		DestinationAccount: account,
+		DestinationAmount:  deliverAll,
+		SendMax:            &sendMax,
+	})
+	if err != nil {
+		return nil, err
+	}
+	best, err := pathQuote.Best()
+	if err != nil {
+		return nil, err
+	}
+	if best.DestinationAmount == nil {
+		return nil, fmt.Errorf("path quote for %s to %s reported no destination amount", sourceCurrency, destinationCurrency)
+	}
+	quotedDestination, err := platformAmount(*best.DestinationAmount, models.Currency(destinationCurrency))
+	if err != nil {
+		return nil, err
+	}
+	destinationAmount, err := quotedDestination.Round(quotedDestination.Currency().Scale())
+	if err != nil {
+		return nil, err
+	}
+
+	deliverMinAmount, err := s.withSlippage(destinationAmount, -1)
+	if err != nil {
+		return nil, err
+	}
+	deliverMin, err := s.xrplService.paymentAmount(deliverMinAmount)
+	if err != nil {
+		return nil, err
+	}
+	payment := &xrpl.Payment{
+		Account:     account,
+		Destination: account,
+		Amount:      *best.DestinationAmount,
+		SendMax:     &sendMax,
+		DeliverMin:  &deliverMin,
+		Paths:       best.Paths,
+		Flags:       xrpl.PaymentFlagPartialPayment,
+	}
+	return s.lock(payment, sourceCurrency, destinationCurrency, spendAmount, destinationAmount), nil
+}
+
+// withSlippage returns amount moved by the maximum slippage, up for a spending limit (direction 1)
+// or down for a delivery floor (direction -1), rounded to the currency's precision
+func (s *CrossCurrencySettlementService) withSlippage(amount models.Money, direction int64) (models.Money, error) {
+	factor := new(big.Rat).Mul(s.maxSlippage, big.NewRat(direction, 1))
+	factor.Add(factor, big.NewRat(1, 1))
+	return models.MoneyFromRat(factor.Mul(factor, amount.Rat()), amount.Currency().Scale(), amount.Currency())
+}
+
+// SettleRelease waits for an escrow release to validate, then converts the released amount on the
+// payee's account into its settlement currency
+func (s *CrossCurrencySettlementService) SettleRelease(ctx context.Context, release *xrpl.TransactionResult, payee string, amount models.Money, settlementCurrency string, payout *models.Transaction) (*SettlementResult, error) {
+	if _, err := s.xrplService.WaitForValidation(ctx, release.TransactionID, release.LastLedgerSequence); err != nil {
+		return nil, fmt.Errorf("release %s did not validate: %w", release.TransactionID, err)
+	}
+
+	quote, err := s.QuoteConversion(payee, amount, settlementCurrency)
+	if err != nil {
+		return nil, err
+	}
+	return s.Execute(ctx, quote.ID, payout)
+}
+
+// lock stores a quote so it can be executed until it expires
+func (s *CrossCurrencySettlementService) lock(payment *xrpl.Payment, sourceCurrency, destinationCurrency string, sourceAmount, destinationAmount models.Money) *SettlementQuote {
+	now := time.Now()
+	quote := &SettlementQuote{
+		ID:                  uuid.New().String(),
+		SourceAccount:       payment.Account,
+		DestinationAccount:  payment.Destination,
+		SourceCurrency:      sourceCurrency,
+		DestinationCurrency: destinationCurrency,
+		SourceAmount:        sourceAmount,
+		DestinationAmount:   destinationAmount,
+		Rate:                newSettlementRate(destinationAmount, sourceAmount),
+		QuotedAt:            now,
+		ExpiresAt:           now.Add(s.config.QuoteTTL),
+		payment:             payment,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, locked := range s.quotes {
+		if now.After(locked.ExpiresAt) {
+			delete(s.quotes, id)
+		}
+	}
+	s.quotes[quote.ID] = quote
+	return quote
+}
+
+// take removes a quote for execution; a quote can be executed only once
+func (s *CrossCurrencySettlementService) take(quoteID string) (*SettlementQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quote, ok := s.quotes[quoteID]
+	if !ok {
+		return nil, fmt.Errorf("quote %s: %w", quoteID, ErrQuoteNotFound)
+	}
+	delete(s.quotes, quoteID)
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, fmt.Errorf("quote %s expired at %s: %w", quoteID, quote.ExpiresAt.Format(time.RFC3339), ErrQuoteExpired)
+	}
+	return quote, nil
+}
+
+// Execute submits a locked quote and waits for the ledger to validate it. When payout is given,
+// the executed rate, spent and delivered amounts are recorded on it, creating the record when it
+// has no ID yet.
+func (s *CrossCurrencySettlementService) Execute(ctx context.Context, quoteID string, payout *models.Transaction) (*SettlementResult, error) {
+	quote, err := s.take(quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted, err := s.xrplService.SubmitPayment(quote.payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle quote %s: %w", quote.ID, err)
+	}
+	validated, err := s.xrplService.WaitForValidation(ctx, submitted.TransactionID, submitted.LastLedgerSequence)
+	if err != nil {
+		return nil, fmt.Errorf("settlement %s did not validate: %w", submitted.TransactionID, err)
+	}
+
+	delivered := quote.payment.Amount
+	if validated.DeliveredAmount != nil {
+		delivered = *validated.DeliveredAmount
+	}
+	deliveredAmount, err := platformAmount(delivered, models.Currency(quote.DestinationCurrency))
+	if err != nil {
+		return nil, err
+	}
+	spentAmount, err := s.spent(quote, validated)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SettlementResult{
+		QuoteID:             quote.ID,
+		TransactionID:       submitted.TransactionID,
+		LedgerIndex:         validated.LedgerIndex,
+		SourceCurrency:      quote.SourceCurrency,
+		DestinationCurrency: quote.DestinationCurrency,
+		SourceAmount:        spentAmount,
+		DeliveredAmount:     deliveredAmount,
+		QuotedRate:          quote.Rate,
+		ExecutedRate:        newSettlementRate(deliveredAmount, spentAmount),
+	}
+	log.Printf("Settled quote %s: spent %s %s, delivered %s %s at %s, TxID: %s", quote.ID,
+		spentAmount, quote.SourceCurrency, deliveredAmount, quote.DestinationCurrency, result.ExecutedRate, result.TransactionID)
+
+	if payout != nil {
+		if err := s.recordPayout(payout, quote, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// spent reads what the source account paid from the validated balance changes, excluding the
+// transaction cost, and falls back to the quoted amount when no metadata was returned
+func (s *CrossCurrencySettlementService) spent(quote *SettlementQuote, validated *xrpl.TransactionResult) (models.Money, error) {
+	if validated.Meta == nil {
+		return quote.SourceAmount, nil
+	}
+
+	sendMax := quote.payment.SendMax
+	change := validated.Meta.BalanceChange(quote.SourceAccount, sendMax.Currency, sendMax.Issuer)
+	spent := new(big.Rat).Neg(change)
+	if sendMax.IsNative() {
+		if fee, ok := new(big.Rat).SetString(validated.Fee); ok {
+			spent.Sub(spent, fee)
+		}
+		spent.Quo(spent, big.NewRat(1000000, 1))
+	}
+	currency := models.Currency(quote.SourceCurrency)
+	return models.MoneyFromRat(spent, currency.Scale(), currency)
+}
+
+// recordPayout stores the executed settlement on the payout transaction
+func (s *CrossCurrencySettlementService) recordPayout(payout *models.Transaction, quote *SettlementQuote, result *SettlementResult) error {
+	payout.TransactionHash = result.TransactionID
+	payout.Amount = result.SourceAmount.String()
+	payout.Currency = result.SourceCurrency
+	payout.DeliveredAmount = result.DeliveredAmount.String()
+	payout.DeliveredCurrency = result.DestinationCurrency
+	payout.ExchangeRate = result.ExecutedRate.String()
+	payout.LedgerIndex = &result.LedgerIndex
+	payout.Status = models.TransactionStatusConfirmed
+	now := time.Now()
+	payout.ConfirmedAt = &now
+	payout.UpdatedAt = now
+	if payout.Metadata == nil {
+		payout.Metadata = make(models.TransactionMetadata)
+	}
+	payout.Metadata["settlement_quote_id"] = quote.ID
+	payout.Metadata["quoted_rate"] = quote.Rate.String()
+
+	if s.transactionRepo == nil {
+		return nil
+	}
+	if payout.ID == "" {
+		payout.ID = uuid.New().String()
+		if err := s.transactionRepo.CreateTransaction(payout); err != nil {
+			return fmt.Errorf("failed to record settlement %s: %w", result.TransactionID, err)
+		}
+		return nil
+	}
+	if err := s.transactionRepo.UpdateTransaction(payout); err != nil {
+		return fmt.Errorf("failed to record settlement %s: %w", result.TransactionID, err)
+	}
+	return nil
+}
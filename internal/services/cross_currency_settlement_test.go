@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
+)
+
+// newSettlementMarket funds a payee holding 200 USDC and a market maker selling XRP at 2 XRP per
+// USDC on a network with TokenEscrow. Any further holders are funded with 200 USDC as well.
+func newSettlementMarket(t *testing.T, holders ...*xrpl.KeyPair) (*XRPLService, *simulator.Ledger, string) {
+	t.Helper()
+	issuer, maker, payee := newTestKeyPair(t), newTestKeyPair(t), newTestKeyPair(t)
+	keys := approverKeys{issuer.Address(): issuer, maker.Address(): maker, payee.Address(): payee}
+	usdcHolders := []string{payee.Address()}
+	for _, holder := range holders {
+		keys[holder.Address()] = holder
+		usdcHolders = append(usdcHolders, holder.Address())
+	}
+	service, ledger := newSimulatedXRPLServiceWithConfig(t, keys, simulator.Config{Amendments: []string{xrpl.AmendmentTokenEscrow}})
+	stop := ledger.AutoClose(20 * time.Millisecond)
+	t.Cleanup(stop)
+	for account := range keys {
+		require.NoError(t, ledger.Fund(account, 10000000000))
+	}
+	require.NoError(t, service.ConfigureIssuedAssets(testIssuedAssets(issuer.Address())))
+
+	usdc, ok := service.IssuedCurrency("USDC")
+	require.True(t, ok)
+	limit, err := usdc.Amount("100000")
+	require.NoError(t, err)
+	for _, account := range append([]string{maker.Address()}, usdcHolders...) {
+		_, err := service.SetTrustLine(account, limit, 0)
+		require.NoError(t, err)
+	}
+	for _, account := range usdcHolders {
+		_, err = service.SendPayment(issuer.Address(), account, models.MustParseMoney("200", models.CurrencyUSDC))
+		require.NoError(t, err)
+	}
+
+	price, err := usdc.Amount("500")
+	require.NoError(t, err)
+	_, err = service.client.CreateOffer(&xrpl.OfferCreate{Account: maker.Address(), TakerGets: xrpl.XRPAmount(1000000000), TakerPays: price})
+	require.NoError(t, err)
+	return service, ledger, payee.Address()
+}
+
+func TestCrossCurrencySettlement_ConvertsAndRecordsPayout(t *testing.T) {
+	service, _, payee := newSettlementMarket(t)
+	transactionRepo := &mockTransactionRepoXRPL{}
+	settlement := NewCrossCurrencySettlementService(service, transactionRepo, CrossCurrencySettlementConfig{})
+
+	quote, err := settlement.QuoteConversion(payee, models.MustParseMoney("100", models.CurrencyUSDC), "XRP")
+	require.NoError(t, err)
+	assert.Equal(t, "200.000000", quote.DestinationAmount.String())
+	assert.Equal(t, "2", quote.Rate.String())
+
+	payout := models.NewTransaction(models.TransactionTypePayment, payee, payee, "100", "USDC", "enterprise-1", "user-1")
+	transactionRepo.On("UpdateTransaction", payout).Return(nil).Once()
+
+	result, err := settlement.Execute(context.Background(), quote.ID, payout)
+	require.NoError(t, err)
+	assert.Equal(t, "100.000000", result.SourceAmount.String())
+	assert.Equal(t, "200.000000", result.DeliveredAmount.String())
+	assert.Equal(t, "2", result.ExecutedRate.String())
+
+	assert.Equal(t, result.TransactionID, payout.TransactionHash)
+	assert.Equal(t, "100.000000", payout.Amount)
+	assert.Equal(t, "USDC", payout.Currency)
+	assert.Equal(t, "200.000000", payout.DeliveredAmount)
+	assert.Equal(t, "XRP", payout.DeliveredCurrency)
+	assert.Equal(t, "2", payout.ExchangeRate)
+	assert.Equal(t, models.TransactionStatusConfirmed, payout.Status)
+	assert.Equal(t, quote.ID, payout.Metadata["settlement_quote_id"])
+	transactionRepo.AssertExpectations(t)
+
+	// A quote executes once
+	_, err = settlement.Execute(context.Background(), quote.ID, nil)
+	assert.ErrorIs(t, err, ErrQuoteNotFound)
+}
+
+func TestCrossCurrencySettlement_QuoteLockExpires(t *testing.T) {
+	service, _, payee := newSettlementMarket(t)
+	settlement := NewCrossCurrencySettlementService(service, nil, CrossCurrencySettlementConfig{QuoteTTL: time.Millisecond})
+
+	quote, err := settlement.QuoteConversion(payee, models.MustParseMoney("50", models.CurrencyUSDC), "XRP")
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = settlement.Execute(context.Background(), quote.ID, nil)
+	assert.ErrorIs(t, err, ErrQuoteExpired)
+}
+
+func TestCrossCurrencySettlement_QuoteDeliveryCapsSpend(t *testing.T) {
+	service, _, payee := newSettlementMarket(t)
+	settlement := NewCrossCurrencySettlementService(service, nil, CrossCurrencySettlementConfig{MaxSlippage: 0.01})
+
+	quote, err := settlement.QuoteDelivery(payee, payee, "USDC", models.MustParseMoney("60", models.CurrencyXRP))
+	require.NoError(t, err)
+	assert.Equal(t, "30.000000", quote.SourceAmount.String())
+	require.NotNil(t, quote.payment.SendMax)
+	assert.Equal(t, "30.300000", quote.payment.SendMax.Value)
+
+	result, err := settlement.Execute(context.Background(), quote.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "60.000000", result.DeliveredAmount.String())
+	assert.Equal(t, "30.000000", result.SourceAmount.String())
+
+	_, err = settlement.QuoteConversion(payee, models.MustParseMoney("10", models.CurrencyUSDT), "XRP")
+	assert.ErrorIs(t, err, xrpl.ErrNoPath)
+	_, err = settlement.QuoteConversion(payee, models.MustParseMoney("10", models.CurrencyXRP), "XRP")
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// ErrDepositQuarantined is returned for a deposit into a shared receiving wallet that no destination
+// tag routes to an enterprise; the deposit is held for review instead of being credited
+var ErrDepositQuarantined = errors.New("deposit quarantined")
+
+// DestinationTagLedger is the XRPL access destination tag routing needs; *XRPLService satisfies it
+type DestinationTagLedger interface {
+	SetAccountFlag(address string, flag uint32) (*xrpl.TransactionResult, error)
+	GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error)
+}
+
+var (
+	_ DestinationTagLedger = (*XRPLService)(nil)
+	_ XRPLDepositCreditor  = (*DestinationTagService)(nil)
+)
+
+// DestinationTagService allocates destination tags on shared receiving (omnibus) wallets and routes
+// the deposits those wallets receive to the enterprise each tag belongs to. Deposits into other
+// wallets are credited to the wallet's own enterprise as before.
+type DestinationTagService struct {
+	tagRepo    repository.DestinationTagRepositoryInterface
+	walletRepo repository.WalletRepositoryInterface
+	ledger     DestinationTagLedger
+	creditor   XRPLDepositCreditor
+
+	// mu serializes quarantine releases so a deposit is never credited twice
+	mu sync.Mutex
+}
+
+// NewDestinationTagService creates a destination tag service crediting routed deposits through creditor
+func NewDestinationTagService(
+	tagRepo repository.DestinationTagRepositoryInterface,
+	walletRepo repository.WalletRepositoryInterface,
+	ledger DestinationTagLedger,
+	creditor XRPLDepositCreditor,
+) *DestinationTagService {
+	return &DestinationTagService{
+		tagRepo:    tagRepo,
+		walletRepo: walletRepo,
+		ledger:     ledger,
+		creditor:   creditor,
+	}
+}
+
+// ConfigureOmnibusWallet makes an active wallet a shared receiving wallet. Its account is set to
+// require destination tags, so the ledger itself rejects untagged payments, and deposits into it are
+// routed by tag from then on.
+func (s *DestinationTagService) ConfigureOmnibusWallet(ctx context.Context, walletID uuid.UUID) (*xrpl.TransactionResult, error) {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if wallet.Status != models.WalletStatusActive {
+		return nil, fmt.Errorf("only active wallets can receive deposits (status: %s)", wallet.Status)
+	}
+
+	result, err := s.ledger.SetAccountFlag(wallet.Address, xrpl.AccountSetFlagRequireDest)
+	if err != nil {
+		return result, err
+	}
+
+	if wallet.Metadata == nil {
+		wallet.Metadata = make(models.WalletMetadata)
+	}
+	wallet.Metadata[models.WalletMetadataOmnibus] = "true"
+	wallet.Metadata["require_dest_tx"] = result.TransactionID
+	if err := s.walletRepo.Update(wallet); err != nil {
+		return result, fmt.Errorf("failed to mark wallet as omnibus: %w", err)
+	}
+
+	log.Printf("Wallet %s now requires destination tags and routes deposits by tag", wallet.Address)
+	return result, nil
+}
+
+// AllocateEnterpriseTag returns an enterprise's tag on a shared receiving wallet, allocating it the
+// first time it is asked for
+func (s *DestinationTagService) AllocateEnterpriseTag(ctx context.Context, walletAddress string, enterpriseID uuid.UUID) (*models.DestinationTag, error) {
+	wallet, err := s.omnibusWallet(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.GetEnterpriseDestinationTag(ctx, walletAddress, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		tag = &models.DestinationTag{WalletAddress: walletAddress, EnterpriseID: enterpriseID}
+		if err := s.tagRepo.CreateDestinationTag(ctx, tag); err != nil {
+			return nil, err
+		}
+		log.Printf("Allocated destination tag %d on %s to enterprise %s", tag.Tag, walletAddress, enterpriseID)
+	}
+	if err := withXAddress(wallet, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// AllocateSmartChequeTag returns the tag identifying deposits toward one smart cheque on a shared
+// receiving wallet, allocating it the first time it is asked for. Deposits carrying it are credited
+// to the given enterprise.
+func (s *DestinationTagService) AllocateSmartChequeTag(ctx context.Context, walletAddress string, enterpriseID uuid.UUID, smartChequeID string) (*models.DestinationTag, error) {
+	wallet, err := s.omnibusWallet(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.GetSmartChequeDestinationTag(ctx, smartChequeID)
+	if err != nil {
+		return nil, err
+	}
+	if tag != nil && (tag.WalletAddress != walletAddress || tag.EnterpriseID != enterpriseID) {
+		return nil, fmt.Errorf("smart check %s already has destination tag %d on %s", smartChequeID, tag.Tag, tag.WalletAddress)
+	}
+	if tag == nil {
+		tag = &models.DestinationTag{WalletAddress: walletAddress, EnterpriseID: enterpriseID, SmartChequeID: &smartChequeID}
+		if err := s.tagRepo.CreateDestinationTag(ctx, tag); err != nil {
+			return nil, err
+		}
+		log.Printf("Allocated destination tag %d on %s to smart check %s", tag.Tag, walletAddress, smartChequeID)
+	}
+	if err := withXAddress(wallet, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// CreditXRPLDeposit credits a deposit into a shared receiving wallet to the enterprise its
+// destination tag was allocated to. A deposit without a tag, or with a tag that was never
+// allocated, is quarantined and ErrDepositQuarantined returned. Deposits into any other wallet are
+// credited to enterpriseID, the wallet's own enterprise.
+func (s *DestinationTagService) CreditXRPLDeposit(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, delivery *xrpl.PaymentDelivery) (*models.AssetTransaction, error) {
+	wallet, err := s.walletRepo.GetByAddress(delivery.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet %s: %w", delivery.Destination, err)
+	}
+	if !isOmnibusWallet(wallet) {
+		return s.creditor.CreditXRPLDeposit(ctx, enterpriseID, currencyCode, delivery)
+	}
+
+	if delivery.DestinationTag == nil {
+		return nil, s.quarantine(ctx, currencyCode, delivery, "payment carried no destination tag")
+	}
+	tag, err := s.tagRepo.GetDestinationTag(ctx, delivery.Destination, *delivery.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, s.quarantine(ctx, currencyCode, delivery, fmt.Sprintf("destination tag %d is not allocated", *delivery.DestinationTag))
+	}
+
+	if tag.SmartChequeID != nil {
+		log.Printf("Routing deposit %s for smart check %s to enterprise %s", delivery.TransactionID, *tag.SmartChequeID, tag.EnterpriseID)
+	}
+	return s.creditor.CreditXRPLDeposit(ctx, tag.EnterpriseID, currencyCode, delivery)
+}
+
+// quarantine holds a deposit no tag routes and returns ErrDepositQuarantined with the reason
+func (s *DestinationTagService) quarantine(ctx context.Context, currencyCode string, delivery *xrpl.PaymentDelivery, reason string) error {
+	deposit := &models.QuarantinedDeposit{
+		WalletAddress:     delivery.Destination,
+		TransactionHash:   delivery.TransactionID,
+		Sender:            delivery.Account,
+		DestinationTag:    delivery.DestinationTag,
+		DeliveredValue:    delivery.Delivered.Value,
+		DeliveredCurrency: currencyCode,
+		Reason:            reason,
+	}
+	if err := s.tagRepo.SaveQuarantinedDeposit(ctx, deposit); err != nil {
+		return err
+	}
+
+	log.Printf("Quarantined deposit %s of %s %s into %s: %s",
+		delivery.TransactionID, delivery.Delivered.Value, currencyCode, delivery.Destination, reason)
+	return fmt.Errorf("%w: %s", ErrDepositQuarantined, reason)
+}
+
+// GetQuarantinedDeposits lists the quarantined deposits awaiting review, oldest first
+func (s *DestinationTagService) GetQuarantinedDeposits(ctx context.Context, limit, offset int) ([]*models.QuarantinedDeposit, error) {
+	return s.tagRepo.GetQuarantinedDeposits(ctx, models.QuarantinedDepositStatusPending, limit, offset)
+}
+
+// ReleaseQuarantinedDeposit credits a quarantined deposit to the enterprise a reviewer identified.
+// The credit is what the validated payment delivered, read again from the ledger.
+func (s *DestinationTagService) ReleaseQuarantinedDeposit(ctx context.Context, depositID, enterpriseID uuid.UUID) (*models.AssetTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deposit, err := s.tagRepo.GetQuarantinedDeposit(ctx, depositID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit == nil {
+		return nil, fmt.Errorf("quarantined deposit not found: %s", depositID)
+	}
+	if deposit.Status != models.QuarantinedDepositStatusPending {
+		return nil, fmt.Errorf("quarantined deposit %s was already %s", depositID, deposit.Status)
+	}
+
+	delivery, err := s.ledger.GetPaymentDelivery(deposit.TransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := s.creditor.CreditXRPLDeposit(ctx, enterpriseID, deposit.DeliveredCurrency, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deposit.Status = models.QuarantinedDepositStatusReleased
+	deposit.EnterpriseID = &enterpriseID
+	deposit.AssetTransactionID = &transaction.ID
+	deposit.ReleasedAt = &now
+	if err := s.tagRepo.UpdateQuarantinedDeposit(ctx, deposit); err != nil {
+		return transaction, fmt.Errorf("deposit %s credited but not marked released: %w", deposit.TransactionHash, err)
+	}
+
+	log.Printf("Released quarantined deposit %s to enterprise %s", deposit.TransactionHash, enterpriseID)
+	return transaction, nil
+}
+
+// omnibusWallet returns a wallet configured to route deposits by destination tag
+func (s *DestinationTagService) omnibusWallet(address string) (*models.Wallet, error) {
+	wallet, err := s.walletRepo.GetByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if !isOmnibusWallet(wallet) {
+		return nil, fmt.Errorf("wallet %s is not a shared receiving wallet", address)
+	}
+	return wallet, nil
+}
+
+func isOmnibusWallet(wallet *models.Wallet) bool {
+	return wallet.Metadata[models.WalletMetadataOmnibus] == "true"
+}
+
+// withXAddress sets the X-address senders can pay to reach the tag's holder on the wallet's network
+func withXAddress(wallet *models.Wallet, tag *models.DestinationTag) error {
+	xAddress, err := xrpl.EncodeXAddress(&xrpl.XAddress{
+		ClassicAddress: wallet.Address,
+		Tag:            tag.Tag,
+		HasTag:         true,
+		TestNet:        wallet.NetworkType == "testnet",
+	})
+	if err != nil {
+		return err
+	}
+	tag.XAddress = xAddress
+	return nil
+}
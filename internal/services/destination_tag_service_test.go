@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// memoryTagRepo is an in-memory DestinationTagRepositoryInterface
+type memoryTagRepo struct {
+	tags     []*models.DestinationTag
+	deposits []*models.QuarantinedDeposit
+}
+
+func (r *memoryTagRepo) CreateDestinationTag(_ context.Context, tag *models.DestinationTag) error {
+	tag.ID = uuid.New()
+	tag.Tag = 1
+	for _, existing := range r.tags {
+		if existing.WalletAddress == tag.WalletAddress && existing.Tag >= tag.Tag {
+			tag.Tag = existing.Tag + 1
+		}
+	}
+	r.tags = append(r.tags, tag)
+	return nil
+}
+
+func (r *memoryTagRepo) find(match func(*models.DestinationTag) bool) *models.DestinationTag {
+	for _, tag := range r.tags {
+		if match(tag) {
+			copied := *tag
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *memoryTagRepo) GetDestinationTag(_ context.Context, walletAddress string, value uint32) (*models.DestinationTag, error) {
+	return r.find(func(tag *models.DestinationTag) bool { return tag.WalletAddress == walletAddress && tag.Tag == value }), nil
+}
+
+func (r *memoryTagRepo) GetEnterpriseDestinationTag(_ context.Context, walletAddress string, enterpriseID uuid.UUID) (*models.DestinationTag, error) {
+	return r.find(func(tag *models.DestinationTag) bool {
+		return tag.WalletAddress == walletAddress && tag.EnterpriseID == enterpriseID && tag.SmartChequeID == nil
+	}), nil
+}
+
+func (r *memoryTagRepo) GetSmartChequeDestinationTag(_ context.Context, smartChequeID string) (*models.DestinationTag, error) {
+	return r.find(func(tag *models.DestinationTag) bool {
+		return tag.SmartChequeID != nil && *tag.SmartChequeID == smartChequeID
+	}), nil
+}
+
+func (r *memoryTagRepo) SaveQuarantinedDeposit(_ context.Context, deposit *models.QuarantinedDeposit) error {
+	for _, existing := range r.deposits {
+		if existing.TransactionHash == deposit.TransactionHash {
+			return nil
+		}
+	}
+	deposit.ID = uuid.New()
+	deposit.Status = models.QuarantinedDepositStatusPending
+	r.deposits = append(r.deposits, deposit)
+	return nil
+}
+
+func (r *memoryTagRepo) GetQuarantinedDeposit(_ context.Context, id uuid.UUID) (*models.QuarantinedDeposit, error) {
+	for _, deposit := range r.deposits {
+		if deposit.ID == id {
+			copied := *deposit
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryTagRepo) GetQuarantinedDeposits(_ context.Context, status models.QuarantinedDepositStatus, limit, offset int) ([]*models.QuarantinedDeposit, error) {
+	deposits := []*models.QuarantinedDeposit{}
+	for _, deposit := range r.deposits {
+		if deposit.Status == status {
+			deposits = append(deposits, deposit)
+		}
+	}
+	return deposits, nil
+}
+
+func (r *memoryTagRepo) UpdateQuarantinedDeposit(_ context.Context, deposit *models.QuarantinedDeposit) error {
+	for i, existing := range r.deposits {
+		if existing.ID == deposit.ID {
+			r.deposits[i] = deposit
+		}
+	}
+	return nil
+}
+
+// enterpriseCreditRecorder records which enterprise each deposit was credited to
+type enterpriseCreditRecorder struct {
+	credited map[uuid.UUID][]string
+}
+
+func (r *enterpriseCreditRecorder) CreditXRPLDeposit(_ context.Context, enterpriseID uuid.UUID, currencyCode string, delivery *xrpl.PaymentDelivery) (*models.AssetTransaction, error) {
+	r.credited[enterpriseID] = append(r.credited[enterpriseID], currencyCode+":"+delivery.Delivered.Value)
+	return &models.AssetTransaction{ID: uuid.New(), EnterpriseID: enterpriseID, CurrencyCode: currencyCode}, nil
+}
+
+func TestDestinationTagService_RoutesSharedWalletDepositsByTag(t *testing.T) {
+	ctx := context.Background()
+	omnibusKeys, customerKeys := newTestKeyPair(t), newTestKeyPair(t)
+	omnibus, customer := omnibusKeys.Address(), customerKeys.Address()
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{omnibus: omnibusKeys, customer: customerKeys})
+	require.NoError(t, ledger.Fund(omnibus, 100000000))
+	require.NoError(t, ledger.Fund(customer, 100000000))
+
+	operator, enterpriseA, enterpriseB := uuid.New(), uuid.New(), uuid.New()
+	omnibusWallet := &models.Wallet{ID: uuid.New(), EnterpriseID: operator, Address: omnibus, Status: models.WalletStatusActive, NetworkType: "testnet"}
+	walletRepo := &MockWalletRepositoryInterface{}
+	walletRepo.On("GetByID", omnibusWallet.ID).Return(omnibusWallet, nil)
+	walletRepo.On("GetByAddress", omnibus).Return(omnibusWallet, nil)
+	walletRepo.On("Update", mock.Anything).Return(nil)
+
+	tagRepo := &memoryTagRepo{}
+	creditor := &enterpriseCreditRecorder{credited: make(map[uuid.UUID][]string)}
+	service := NewDestinationTagService(tagRepo, walletRepo, xrplService, creditor)
+
+	// Tags are only handed out on shared receiving wallets
+	_, err := service.AllocateEnterpriseTag(ctx, omnibus, enterpriseA)
+	assert.Error(t, err)
+
+	_, err = service.ConfigureOmnibusWallet(ctx, omnibusWallet.ID)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	enterpriseTag, err := service.AllocateEnterpriseTag(ctx, omnibus, enterpriseA)
+	require.NoError(t, err)
+	again, err := service.AllocateEnterpriseTag(ctx, omnibus, enterpriseA)
+	require.NoError(t, err)
+	assert.Equal(t, enterpriseTag.Tag, again.Tag)
+	chequeTag, err := service.AllocateSmartChequeTag(ctx, omnibus, enterpriseB, "cheque-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, enterpriseTag.Tag, chequeTag.Tag)
+
+	decoded, err := xrpl.DecodeXAddress(chequeTag.XAddress)
+	require.NoError(t, err)
+	assert.Equal(t, omnibus, decoded.ClassicAddress)
+	assert.Equal(t, chequeTag.Tag, decoded.Tag)
+	assert.True(t, decoded.TestNet)
+
+	// The ledger itself refuses untagged payments once the wallet requires a tag
+	_, err = xrplService.SendPayment(customer, omnibus, models.MustParseMoney("1", models.CurrencyXRP))
+	var txErr *xrpl.TransactionError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, "tecDST_TAG_NEEDED", txErr.Code)
+
+	toEnterprise, err := xrplService.SendPayment(customer, enterpriseTag.XAddress, models.MustParseMoney("5", models.CurrencyXRP))
+	require.NoError(t, err)
+	toCheque, err := xrplService.SendPayment(customer, chequeTag.XAddress, models.MustParseMoney("3", models.CurrencyXRP))
+	require.NoError(t, err)
+	unknownTag, err := xrplService.SubmitPayment(&xrpl.Payment{Account: customer, Destination: omnibus, DestinationTag: 99, Amount: xrpl.XRPAmount(2000000)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	credit := func(hash string) error {
+		delivery, err := xrplService.GetPaymentDelivery(hash)
+		require.NoError(t, err)
+		_, err = service.CreditXRPLDeposit(ctx, operator, "XRP", delivery)
+		return err
+	}
+	require.NoError(t, credit(toEnterprise.TransactionID))
+	require.NoError(t, credit(toCheque.TransactionID))
+	assert.ErrorIs(t, credit(unknownTag.TransactionID), ErrDepositQuarantined)
+
+	assert.Equal(t, []string{"XRP:5000000"}, creditor.credited[enterpriseA])
+	assert.Equal(t, []string{"XRP:3000000"}, creditor.credited[enterpriseB])
+	assert.Empty(t, creditor.credited[operator], "the wallet's owner is never credited for routed deposits")
+
+	// A reviewer releases the quarantined deposit to the enterprise it was meant for
+	quarantined, err := service.GetQuarantinedDeposits(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	assert.Equal(t, unknownTag.TransactionID, quarantined[0].TransactionHash)
+	require.NotNil(t, quarantined[0].DestinationTag)
+	assert.Equal(t, uint32(99), *quarantined[0].DestinationTag)
+
+	transaction, err := service.ReleaseQuarantinedDeposit(ctx, quarantined[0].ID, enterpriseA)
+	require.NoError(t, err)
+	assert.Equal(t, enterpriseA, transaction.EnterpriseID)
+	assert.Equal(t, []string{"XRP:5000000", "XRP:2000000"}, creditor.credited[enterpriseA])
+	_, err = service.ReleaseQuarantinedDeposit(ctx, quarantined[0].ID, enterpriseA)
+	assert.Error(t, err, "a released deposit cannot be credited again")
+
+	// Deposits into an enterprise's own wallet still go to that enterprise
+	walletRepo.On("GetByAddress", customer).Return(&models.Wallet{Address: customer, EnterpriseID: enterpriseB}, nil)
+	_, err = service.CreditXRPLDeposit(ctx, enterpriseB, "XRP", &xrpl.PaymentDelivery{
+		TransactionID: "DIRECT",
+		Destination:   customer,
+		Delivered:     xrpl.XRPAmount(1000000),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"XRP:3000000", "XRP:1000000"}, creditor.credited[enterpriseB])
+}
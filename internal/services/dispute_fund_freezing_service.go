@@ -52,7 +52,7 @@ type FundFreezingStatus struct {
 	DisputeID      string                 `json:"dispute_id"`
 	SmartChequeID  string                 `json:"smart_check_id"`
 	Status         FundFreezingStatusType `json:"status"`
-	FrozenAmount   float64                `json:"frozen_amount"`
+	FrozenAmount   models.Money           `json:"frozen_amount"`
 	Currency       models.Currency        `json:"currency"`
 	FrozenAt       *time.Time             `json:"frozen_at,omitempty"`
 	UnfrozenAt     *time.Time             `json:"unfrozen_at,omitempty"`
@@ -70,7 +70,7 @@ type FrozenFund struct {
 	DisputeID      string                 `json:"dispute_id"`
 	SmartChequeID  string                 `json:"smart_check_id"`
 	EnterpriseID   string                 `json:"enterprise_id"`
-	Amount         float64                `json:"amount"`
+	Amount         models.Money           `json:"amount"`
 	Currency       models.Currency        `json:"currency"`
 	Status         FundFreezingStatusType `json:"status"`
 	FrozenAt       time.Time              `json:"frozen_at"`
@@ -90,7 +90,7 @@ type FundFreezingEvent struct {
 	DisputeID string                 `json:"dispute_id"`
 	EventType string                 `json:"event_type"` // freeze, unfreeze, status_update
 	Status    FundFreezingStatusType `json:"status"`
-	Amount    float64                `json:"amount"`
+	Amount    models.Money           `json:"amount"`
 	Currency  models.Currency        `json:"currency"`
 	Reason    string                 `json:"reason"`
 	UserID    string                 `json:"user_id"`
@@ -182,7 +182,7 @@ func (s *DisputeFundFreezingService) FreezeFundsForDispute(ctx context.Context,
 
 	// Store frozen fund (this would be implemented in a repository)
 	// For now, we'll simulate the storage
-	log.Printf("Freezing funds for dispute %s: amount=%f %s", disputeID, frozenFund.Amount, frozenFund.Currency)
+	log.Printf("Freezing funds for dispute %s: amount=%s %s", disputeID, frozenFund.Amount, frozenFund.Currency)
 
 	// Update enterprise fraud status to indicate frozen funds
 	err = s.updateEnterpriseFraudStatus(ctx, smartCheque.PayerID, "frozen", freezeReason, userID)
@@ -253,7 +253,7 @@ func (s *DisputeFundFreezingService) UnfreezeFundsForDispute(ctx context.Context
 
 	// Update frozen fund status
 	// In a real implementation, this would update the database
-	log.Printf("Unfreezing funds for dispute %s: amount=%f %s", disputeID, freezingStatus.FrozenAmount, freezingStatus.Currency)
+	log.Printf("Unfreezing funds for dispute %s: amount=%s %s", disputeID, freezingStatus.FrozenAmount, freezingStatus.Currency)
 
 	// Update enterprise fraud status to remove frozen status
 	err = s.updateEnterpriseFraudStatus(ctx, dispute.InitiatorID, "normal", unfreezeReason, userID)
@@ -304,7 +304,7 @@ func (s *DisputeFundFreezingService) GetFundFreezingStatus(ctx context.Context,
 		DisputeID:     disputeID,
 		SmartChequeID: "mock-smart-check-id",
 		Status:        FundFreezingStatusNotFrozen,
-		FrozenAmount:  0,
+		FrozenAmount:  models.ZeroMoney(models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		LastUpdatedAt: time.Now(),
 	}, nil
@@ -526,7 +526,7 @@ func TestDisputeHandlingService_HoldMilestoneFunds(t *testing.T) {
 		ID:            "sc-test-milestone-1",
 		PayerID:       "payer-1",
 		PayeeID:       "payee-1",
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		EscrowAddress: "escrow-address-123",
 		Status:        models.SmartChequeStatusLocked,
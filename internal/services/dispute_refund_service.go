@@ -41,7 +41,7 @@ type DisputeRefundServiceInterface interface {
 type RefundRequest struct {
 	DisputeID     string                 `json:"dispute_id" validate:"required"`
 	SmartChequeID string                 `json:"smart_check_id" validate:"required"`
-	RefundAmount  float64                `json:"refund_amount" validate:"required,gt=0"`
+	RefundAmount  models.Money           `json:"refund_amount" validate:"required"`
 	Currency      models.Currency        `json:"currency" validate:"required"`
 	RefundReason  string                 `json:"refund_reason" validate:"required"`
 	RefundType    RefundType             `json:"refund_type" validate:"required"`
@@ -54,8 +54,8 @@ type RefundRequest struct {
 type PartialPaymentRequest struct {
 	DisputeID          string                 `json:"dispute_id" validate:"required"`
 	SmartChequeID      string                 `json:"smart_check_id" validate:"required"`
-	PartialAmount      float64                `json:"partial_amount" validate:"required,gt=0"`
-	OriginalAmount     float64                `json:"original_amount" validate:"required,gt=0"`
+	PartialAmount      models.Money           `json:"partial_amount" validate:"required"`
+	OriginalAmount     models.Money           `json:"original_amount" validate:"required"`
 	Currency           models.Currency        `json:"currency" validate:"required"`
 	PaymentReason      string                 `json:"payment_reason" validate:"required"`
 	MilestoneCompleted string                 `json:"milestone_completed,omitempty"`
@@ -88,7 +88,7 @@ type RefundResult struct {
 	RefundID      string                 `json:"refund_id"`
 	DisputeID     string                 `json:"dispute_id"`
 	SmartChequeID string                 `json:"smart_check_id"`
-	Amount        float64                `json:"amount"`
+	Amount        models.Money           `json:"amount"`
 	Currency      models.Currency        `json:"currency"`
 	Status        RefundStatus           `json:"status"`
 	RefundType    RefundType             `json:"refund_type"`
@@ -103,7 +103,7 @@ type PartialPaymentResult struct {
 	PaymentID          string                 `json:"payment_id"`
 	DisputeID          string                 `json:"dispute_id"`
 	SmartChequeID      string                 `json:"smart_check_id"`
-	Amount             float64                `json:"amount"`
+	Amount             models.Money           `json:"amount"`
 	Currency           models.Currency        `json:"currency"`
 	Status             PaymentStatus          `json:"status"`
 	MilestoneCompleted string                 `json:"milestone_completed,omitempty"`
@@ -142,7 +142,7 @@ type RefundRecord struct {
 	ID            string                 `json:"id"`
 	DisputeID     string                 `json:"dispute_id"`
 	SmartChequeID string                 `json:"smart_check_id"`
-	Amount        float64                `json:"amount"`
+	Amount        models.Money           `json:"amount"`
 	Currency      models.Currency        `json:"currency"`
 	Status        RefundStatus           `json:"status"`
 	RefundType    RefundType             `json:"refund_type"`
@@ -214,8 +214,10 @@ func (s *DisputeRefundService) ProcessRefund(ctx context.Context, disputeID stri
 	}
 
 	// Validate refund amount
-	if refundRequest.RefundAmount > smartCheque.Amount {
-		return nil, fmt.Errorf("refund amount %f exceeds smart check amount %f", refundRequest.RefundAmount, smartCheque.Amount)
+	if cmp, err := refundRequest.RefundAmount.Cmp(smartCheque.Amount); err != nil {
+		return nil, fmt.Errorf("invalid refund amount: %w", err)
+	} else if cmp > 0 {
+		return nil, fmt.Errorf("refund amount %s exceeds smart check amount %s", refundRequest.RefundAmount, smartCheque.Amount)
 	}
 
 	// Create refund record
@@ -237,7 +239,7 @@ func (s *DisputeRefundService) ProcessRefund(ctx context.Context, disputeID stri
 
 	// Store refund record (this would be implemented in a repository)
 	// For now, we'll simulate the storage
-	log.Printf("Processing refund for dispute %s: amount=%f %s", disputeID, refundRequest.RefundAmount, refundRequest.Currency)
+	log.Printf("Processing refund for dispute %s: amount=%s %s", disputeID, refundRequest.RefundAmount, refundRequest.Currency)
 
 	// Create audit log entry
 	err = s.createAuditLog(ctx, disputeID, "refund_requested", refundRequest.RequestedBy, map[string]interface{}{
@@ -295,8 +297,10 @@ func (s *DisputeRefundService) ProcessPartialPayment(ctx context.Context, disput
 	}
 
 	// Validate partial payment amount
-	if partialPaymentRequest.PartialAmount >= partialPaymentRequest.OriginalAmount {
-		return nil, fmt.Errorf("partial amount %f must be less than original amount %f", partialPaymentRequest.PartialAmount, partialPaymentRequest.OriginalAmount)
+	if cmp, err := partialPaymentRequest.PartialAmount.Cmp(partialPaymentRequest.OriginalAmount); err != nil {
+		return nil, fmt.Errorf("invalid partial amount: %w", err)
+	} else if cmp >= 0 {
+		return nil, fmt.Errorf("partial amount %s must be less than original amount %s", partialPaymentRequest.PartialAmount, partialPaymentRequest.OriginalAmount)
 	}
 
 	// Create partial payment record
@@ -317,7 +321,7 @@ func (s *DisputeRefundService) ProcessPartialPayment(ctx context.Context, disput
 
 	// Store partial payment record (this would be implemented in a repository)
 	// For now, we'll simulate the storage
-	log.Printf("Processing partial payment for dispute %s: amount=%f %s", disputeID, partialPaymentRequest.PartialAmount, partialPaymentRequest.Currency)
+	log.Printf("Processing partial payment for dispute %s: amount=%s %s", disputeID, partialPaymentRequest.PartialAmount, partialPaymentRequest.Currency)
 
 	// Create audit log entry
 	err = s.createAuditLog(ctx, disputeID, "partial_payment_requested", partialPaymentRequest.RequestedBy, map[string]interface{}{
@@ -413,7 +417,7 @@ func (s *DisputeRefundService) ExecuteRefund(ctx context.Context, refundID strin
 
 // validateRefundRequest validates a refund request
 func (s *DisputeRefundService) validateRefundRequest(request *RefundRequest) error {
-	if request.RefundAmount <= 0 {
+	if !request.RefundAmount.IsPositive() {
 		return fmt.Errorf("refund amount must be greater than 0")
 	}
 	if request.RefundReason == "" {
@@ -427,13 +431,15 @@ func (s *DisputeRefundService) validateRefundRequest(request *RefundRequest) err
 
 // validatePartialPaymentRequest validates a partial payment request
 func (s *DisputeRefundService) validatePartialPaymentRequest(request *PartialPaymentRequest) error {
-	if request.PartialAmount <= 0 {
+	if !request.PartialAmount.IsPositive() {
 		return fmt.Errorf("partial amount must be greater than 0")
 	}
-	if request.OriginalAmount <= 0 {
+	if !request.OriginalAmount.IsPositive() {
 		return fmt.Errorf("original amount must be greater than 0")
 	}
-	if request.PartialAmount >= request.OriginalAmount {
+	if cmp, err := request.PartialAmount.Cmp(request.OriginalAmount); err != nil {
+		return fmt.Errorf("invalid partial amount: %w", err)
+	} else if cmp >= 0 {
 		return fmt.Errorf("partial amount must be less than original amount")
 	}
 	if request.PaymentReason == "" {
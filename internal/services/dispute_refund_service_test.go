@@ -51,7 +51,7 @@ func TestDisputeRefundService_ValidateRefundRequest(t *testing.T) {
 	validRequest := &RefundRequest{
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		RefundAmount:  100.0,
+		RefundAmount:  models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		RefundReason:  "Service not delivered",
 		RefundType:    RefundTypeFull,
@@ -66,7 +66,7 @@ func TestDisputeRefundService_ValidateRefundRequest(t *testing.T) {
 	invalidAmount := &RefundRequest{
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		RefundAmount:  0.0,
+		RefundAmount:  models.MustParseMoney("0", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		RefundReason:  "Service not delivered",
 		RefundType:    RefundTypeFull,
@@ -82,7 +82,7 @@ func TestDisputeRefundService_ValidateRefundRequest(t *testing.T) {
 	negativeAmount := &RefundRequest{
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		RefundAmount:  -50.0,
+		RefundAmount:  models.MustParseMoney("-50", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		RefundReason:  "Service not delivered",
 		RefundType:    RefundTypeFull,
@@ -98,7 +98,7 @@ func TestDisputeRefundService_ValidateRefundRequest(t *testing.T) {
 	emptyReason := &RefundRequest{
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		RefundAmount:  100.0,
+		RefundAmount:  models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		RefundReason:  "",
 		RefundType:    RefundTypeFull,
@@ -114,7 +114,7 @@ func TestDisputeRefundService_ValidateRefundRequest(t *testing.T) {
 	emptyRequester := &RefundRequest{
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		RefundAmount:  100.0,
+		RefundAmount:  models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		RefundReason:  "Service not delivered",
 		RefundType:    RefundTypeFull,
@@ -134,8 +134,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	validRequest := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      50.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("50", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -150,8 +150,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	equalAmount := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      100.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("100", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -167,8 +167,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	greaterAmount := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      150.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("150", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -184,8 +184,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	zeroPartialAmount := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      0.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("0", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -201,8 +201,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	zeroOriginalAmount := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      50.0,
-		OriginalAmount:     0.0,
+		PartialAmount:      models.MustParseMoney("50", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("0", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -218,8 +218,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	emptyReason := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      50.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("50", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "",
 		MilestoneCompleted: "milestone-1",
@@ -235,8 +235,8 @@ func TestDisputeRefundService_ValidatePartialPaymentRequest(t *testing.T) {
 	emptyRequester := &PartialPaymentRequest{
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		PartialAmount:      50.0,
-		OriginalAmount:     100.0,
+		PartialAmount:      models.MustParseMoney("50", models.CurrencyUSDT),
+		OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		PaymentReason:      "Partial milestone completion",
 		MilestoneCompleted: "milestone-1",
@@ -294,7 +294,7 @@ func TestDisputeRefundService_RefundRecord(t *testing.T) {
 		ID:            "refund-123",
 		DisputeID:     "dispute-123",
 		SmartChequeID: "check-123",
-		Amount:        100.0,
+		Amount:        models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		Status:        RefundStatusPending,
 		RefundType:    RefundTypeFull,
@@ -307,7 +307,7 @@ func TestDisputeRefundService_RefundRecord(t *testing.T) {
 	assert.Equal(t, "refund-123", refundRecord.ID)
 	assert.Equal(t, "dispute-123", refundRecord.DisputeID)
 	assert.Equal(t, "check-123", refundRecord.SmartChequeID)
-	assert.Equal(t, 100.0, refundRecord.Amount)
+	assert.Equal(t, "100", refundRecord.Amount.String())
 	assert.Equal(t, models.CurrencyUSDT, refundRecord.Currency)
 	assert.Equal(t, RefundStatusPending, refundRecord.Status)
 	assert.Equal(t, RefundTypeFull, refundRecord.RefundType)
@@ -324,7 +324,7 @@ func TestDisputeRefundService_PartialPaymentResult(t *testing.T) {
 		PaymentID:          "payment-123",
 		DisputeID:          "dispute-123",
 		SmartChequeID:      "check-123",
-		Amount:             50.0,
+		Amount:             models.MustParseMoney("50", models.CurrencyUSDT),
 		Currency:           models.CurrencyUSDT,
 		Status:             PaymentStatusPending,
 		MilestoneCompleted: "milestone-1",
@@ -336,7 +336,7 @@ func TestDisputeRefundService_PartialPaymentResult(t *testing.T) {
 	assert.Equal(t, "payment-123", partialPaymentResult.PaymentID)
 	assert.Equal(t, "dispute-123", partialPaymentResult.DisputeID)
 	assert.Equal(t, "check-123", partialPaymentResult.SmartChequeID)
-	assert.Equal(t, 50.0, partialPaymentResult.Amount)
+	assert.Equal(t, "50", partialPaymentResult.Amount.String())
 	assert.Equal(t, models.CurrencyUSDT, partialPaymentResult.Currency)
 	assert.Equal(t, PaymentStatusPending, partialPaymentResult.Status)
 	assert.Equal(t, "milestone-1", partialPaymentResult.MilestoneCompleted)
@@ -72,7 +72,7 @@ func TestDisputeResolutionBasic_Structs(t *testing.T) {
 		refundRequest := &RefundRequest{
 			DisputeID:     "dispute-123",
 			SmartChequeID: "check-123",
-			RefundAmount:  100.0,
+			RefundAmount:  models.MustParseMoney("100", models.CurrencyUSDT),
 			Currency:      models.CurrencyUSDT,
 			RefundReason:  "Service quality below standard",
 			RefundType:    RefundTypeFull,
@@ -82,7 +82,7 @@ func TestDisputeResolutionBasic_Structs(t *testing.T) {
 
 		assert.Equal(t, "dispute-123", refundRequest.DisputeID)
 		assert.Equal(t, "check-123", refundRequest.SmartChequeID)
-		assert.Equal(t, 100.0, refundRequest.RefundAmount)
+		assert.Equal(t, "100", refundRequest.RefundAmount.String())
 		assert.Equal(t, models.CurrencyUSDT, refundRequest.Currency)
 		assert.Equal(t, "Service quality below standard", refundRequest.RefundReason)
 		assert.Equal(t, RefundTypeFull, refundRequest.RefundType)
@@ -94,8 +94,8 @@ func TestDisputeResolutionBasic_Structs(t *testing.T) {
 		partialPaymentRequest := &PartialPaymentRequest{
 			DisputeID:          "dispute-123",
 			SmartChequeID:      "check-123",
-			PartialAmount:      75.0,
-			OriginalAmount:     100.0,
+			PartialAmount:      models.MustParseMoney("75", models.CurrencyUSDT),
+			OriginalAmount:     models.MustParseMoney("100", models.CurrencyUSDT),
 			Currency:           models.CurrencyUSDT,
 			PaymentReason:      "Partial milestone completion",
 			MilestoneCompleted: "milestone-1",
@@ -105,8 +105,8 @@ func TestDisputeResolutionBasic_Structs(t *testing.T) {
 
 		assert.Equal(t, "dispute-123", partialPaymentRequest.DisputeID)
 		assert.Equal(t, "check-123", partialPaymentRequest.SmartChequeID)
-		assert.Equal(t, 75.0, partialPaymentRequest.PartialAmount)
-		assert.Equal(t, 100.0, partialPaymentRequest.OriginalAmount)
+		assert.Equal(t, "75", partialPaymentRequest.PartialAmount.String())
+		assert.Equal(t, "100", partialPaymentRequest.OriginalAmount.String())
 		assert.Equal(t, models.CurrencyUSDT, partialPaymentRequest.Currency)
 		assert.Equal(t, "Partial milestone completion", partialPaymentRequest.PaymentReason)
 		assert.Equal(t, "milestone-1", partialPaymentRequest.MilestoneCompleted)
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/crypto"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Actors recorded in the fulfillment vault access log
+const (
+	FulfillmentActorEscrowCreation   = "smartcheque_xrpl_service"
+	FulfillmentActorPaymentExecution = "payment_execution_service"
+)
+
+var (
+	ErrFulfillmentNotFound      = errors.New("no sealed fulfillment for smart cheque milestone")
+	ErrFulfillmentReleaseDenied = errors.New("fulfillment release denied")
+)
+
+// FulfillmentCipher encrypts sealed fulfillments at rest. *crypto.Encryptor satisfies it;
+// a KMS-backed implementation can be plugged in instead.
+type FulfillmentCipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+var _ FulfillmentCipher = (*crypto.Encryptor)(nil)
+
+// EscrowReference identifies the on-ledger escrow that a fulfillment finishes
+type EscrowReference struct {
+	Owner         string `json:"owner"`
+	Destination   string `json:"destination"`
+	OfferSequence uint32 `json:"offer_sequence"`
+}
+
+// sealedFulfillment is the plaintext that gets encrypted; carrying the IDs inside the
+// ciphertext binds it to one smart cheque milestone so rows cannot be swapped
+type sealedFulfillment struct {
+	SmartChequeID string `json:"smart_cheque_id"`
+	MilestoneID   string `json:"milestone_id"`
+	Fulfillment   string `json:"fulfillment"`
+}
+
+// FulfillmentVault keeps escrow fulfillments encrypted and audit-logs every access
+type FulfillmentVault struct {
+	repo   repository.FulfillmentVaultRepositoryInterface
+	cipher FulfillmentCipher
+}
+
+// NewFulfillmentVault creates a new fulfillment vault
+func NewFulfillmentVault(repo repository.FulfillmentVaultRepositoryInterface, cipher FulfillmentCipher) *FulfillmentVault {
+	return &FulfillmentVault{
+		repo:   repo,
+		cipher: cipher,
+	}
+}
+
+// Seal encrypts a fulfillment and stores it for a smart cheque milestone
+func (v *FulfillmentVault) Seal(ctx context.Context, smartChequeID, milestoneID string, escrow EscrowReference, condition, fulfillment, actor string) error {
+	err := v.seal(ctx, smartChequeID, milestoneID, escrow, condition, fulfillment)
+	if auditErr := v.recordAccess(ctx, smartChequeID, milestoneID, models.FulfillmentAccessSeal, actor, err); auditErr != nil && err == nil {
+		return auditErr
+	}
+	return err
+}
+
+func (v *FulfillmentVault) seal(ctx context.Context, smartChequeID, milestoneID string, escrow EscrowReference, condition, fulfillment string) error {
+	if smartChequeID == "" || milestoneID == "" {
+		return fmt.Errorf("smart cheque ID and milestone ID are required")
+	}
+
+	if err := xrpl.ValidateFulfillment(condition, fulfillment); err != nil {
+		return fmt.Errorf("refusing to seal fulfillment: %w", err)
+	}
+
+	existing, err := v.repo.GetEscrowFulfillment(ctx, smartChequeID, milestoneID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("fulfillment already sealed for smart cheque %s milestone %s", smartChequeID, milestoneID)
+	}
+
+	plaintext, err := json.Marshal(sealedFulfillment{
+		SmartChequeID: smartChequeID,
+		MilestoneID:   milestoneID,
+		Fulfillment:   fulfillment,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal fulfillment: %w", err)
+	}
+
+	encrypted, err := v.cipher.Encrypt(string(plaintext))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt fulfillment: %w", err)
+	}
+
+	return v.repo.CreateEscrowFulfillment(ctx, &models.EscrowFulfillment{
+		SmartChequeID:        smartChequeID,
+		MilestoneID:          milestoneID,
+		Condition:            condition,
+		EncryptedFulfillment: encrypted,
+		EscrowOwner:          escrow.Owner,
+		EscrowDestination:    escrow.Destination,
+		OfferSequence:        escrow.OfferSequence,
+	})
+}
+
+// Lookup returns the condition and escrow details of a sealed fulfillment without the fulfillment itself
+func (v *FulfillmentVault) Lookup(ctx context.Context, smartChequeID, milestoneID, actor string) (*models.EscrowFulfillment, error) {
+	record, err := v.repo.GetEscrowFulfillment(ctx, smartChequeID, milestoneID)
+	if err == nil && record == nil {
+		err = ErrFulfillmentNotFound
+	}
+	if auditErr := v.recordAccess(ctx, smartChequeID, milestoneID, models.FulfillmentAccessLookup, actor, err); auditErr != nil && err == nil {
+		return nil, auditErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.EncryptedFulfillment = ""
+	return record, nil
+}
+
+// GetAccessLog returns the audit trail of a smart cheque milestone's sealed fulfillment
+func (v *FulfillmentVault) GetAccessLog(ctx context.Context, smartChequeID, milestoneID string, limit, offset int) ([]*models.FulfillmentAccessLog, error) {
+	return v.repo.GetFulfillmentAccessLogs(ctx, smartChequeID, milestoneID, limit, offset)
+}
+
+// releaseForEscrowFinish decrypts a fulfillment for an approved payment authorization.
+// It is unexported so that only PaymentExecutionService can obtain a fulfillment, and it
+// refuses to release when the release cannot be audit-logged.
+func (v *FulfillmentVault) releaseForEscrowFinish(ctx context.Context, auth *PaymentAuthorization) (*models.EscrowFulfillment, string, error) {
+	if auth == nil {
+		return nil, "", fmt.Errorf("%w: payment authorization is required", ErrFulfillmentReleaseDenied)
+	}
+
+	record, fulfillment, err := v.release(ctx, auth)
+	if auditErr := v.recordAccess(ctx, auth.SmartChequeID, auth.MilestoneID, models.FulfillmentAccessRelease, FulfillmentActorPaymentExecution, err); auditErr != nil && err == nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrFulfillmentReleaseDenied, auditErr)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := v.repo.RecordFulfillmentRelease(ctx, record.ID, time.Now()); err != nil {
+		log.Printf("Warning: Failed to record fulfillment release for smart cheque %s milestone %s: %v", auth.SmartChequeID, auth.MilestoneID, err)
+	}
+
+	return record, fulfillment, nil
+}
+
+func (v *FulfillmentVault) release(ctx context.Context, auth *PaymentAuthorization) (*models.EscrowFulfillment, string, error) {
+	if auth.Status != PaymentAuthStatusApproved {
+		return nil, "", fmt.Errorf("%w: payment authorization is %s", ErrFulfillmentReleaseDenied, auth.Status)
+	}
+
+	record, err := v.repo.GetEscrowFulfillment(ctx, auth.SmartChequeID, auth.MilestoneID)
+	if err != nil {
+		return nil, "", err
+	}
+	if record == nil {
+		return nil, "", ErrFulfillmentNotFound
+	}
+
+	plaintext, err := v.cipher.Decrypt(record.EncryptedFulfillment)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt fulfillment: %w", err)
+	}
+
+	var sealed sealedFulfillment
+	if err := json.Unmarshal([]byte(plaintext), &sealed); err != nil {
+		return nil, "", fmt.Errorf("failed to decode sealed fulfillment: %w", err)
+	}
+	if sealed.SmartChequeID != auth.SmartChequeID || sealed.MilestoneID != auth.MilestoneID {
+		return nil, "", fmt.Errorf("%w: sealed fulfillment is bound to a different milestone", ErrFulfillmentReleaseDenied)
+	}
+
+	if err := xrpl.ValidateFulfillment(record.Condition, sealed.Fulfillment); err != nil {
+		return nil, "", fmt.Errorf("sealed fulfillment failed verification: %w", err)
+	}
+
+	return record, sealed.Fulfillment, nil
+}
+
+// recordAccess writes an access log entry for a vault operation and its outcome
+func (v *FulfillmentVault) recordAccess(ctx context.Context, smartChequeID, milestoneID string, action models.FulfillmentAccessAction, actor string, accessErr error) error {
+	entry := &models.FulfillmentAccessLog{
+		SmartChequeID: smartChequeID,
+		MilestoneID:   milestoneID,
+		Action:        action,
+		Actor:         actor,
+		Success:       accessErr == nil,
+		CreatedAt:     time.Now(),
+	}
+	if accessErr != nil {
+		entry.Reason = accessErr.Error()
+	}
+
+	log.Printf("Fulfillment vault %s by %s for smart cheque %s milestone %s (success: %v)",
+		action, actor, smartChequeID, milestoneID, entry.Success)
+
+	if err := v.repo.CreateFulfillmentAccessLog(ctx, entry); err != nil {
+		return fmt.Errorf("failed to audit fulfillment vault access: %w", err)
+	}
+	return nil
+}
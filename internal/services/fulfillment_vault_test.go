@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/crypto"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// inMemoryFulfillmentVaultRepository is a FulfillmentVaultRepositoryInterface backed by maps
+type inMemoryFulfillmentVaultRepository struct {
+	records  map[string]*models.EscrowFulfillment
+	logs     []*models.FulfillmentAccessLog
+	auditErr error
+}
+
+func newInMemoryFulfillmentVaultRepository() *inMemoryFulfillmentVaultRepository {
+	return &inMemoryFulfillmentVaultRepository{records: make(map[string]*models.EscrowFulfillment)}
+}
+
+func (r *inMemoryFulfillmentVaultRepository) CreateEscrowFulfillment(ctx context.Context, fulfillment *models.EscrowFulfillment) error {
+	fulfillment.ID = uuid.New()
+	stored := *fulfillment
+	r.records[fulfillment.SmartChequeID+"/"+fulfillment.MilestoneID] = &stored
+	return nil
+}
+
+func (r *inMemoryFulfillmentVaultRepository) GetEscrowFulfillment(ctx context.Context, smartChequeID, milestoneID string) (*models.EscrowFulfillment, error) {
+	record, ok := r.records[smartChequeID+"/"+milestoneID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *inMemoryFulfillmentVaultRepository) RecordFulfillmentRelease(ctx context.Context, id uuid.UUID, releasedAt time.Time) error {
+	for _, record := range r.records {
+		if record.ID == id {
+			record.ReleaseCount++
+			record.LastReleasedAt = &releasedAt
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryFulfillmentVaultRepository) CreateFulfillmentAccessLog(ctx context.Context, entry *models.FulfillmentAccessLog) error {
+	if r.auditErr != nil {
+		return r.auditErr
+	}
+	r.logs = append(r.logs, entry)
+	return nil
+}
+
+func (r *inMemoryFulfillmentVaultRepository) GetFulfillmentAccessLogs(ctx context.Context, smartChequeID, milestoneID string, limit, offset int) ([]*models.FulfillmentAccessLog, error) {
+	var logs []*models.FulfillmentAccessLog
+	for _, entry := range r.logs {
+		if entry.SmartChequeID == smartChequeID && entry.MilestoneID == milestoneID {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+func newTestFulfillmentVault(t *testing.T) (*FulfillmentVault, *inMemoryFulfillmentVaultRepository) {
+	encryptor, err := crypto.NewEncryptor("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+
+	repo := newInMemoryFulfillmentVaultRepository()
+	return NewFulfillmentVault(repo, encryptor), repo
+}
+
+func newTestCondition(t *testing.T) (string, string) {
+	preimage, err := xrpl.GeneratePreimage()
+	require.NoError(t, err)
+	condition, fulfillment, err := xrpl.NewPreimageCondition(preimage)
+	require.NoError(t, err)
+	return condition, fulfillment
+}
+
+func TestFulfillmentVault_SealAndRelease(t *testing.T) {
+	vault, repo := newTestFulfillmentVault(t)
+	ctx := context.Background()
+	condition, fulfillment := newTestCondition(t)
+	escrow := EscrowReference{Owner: "rOwner", Destination: "rDestination", OfferSequence: 42}
+
+	require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-1", escrow, condition, fulfillment, FulfillmentActorEscrowCreation))
+
+	stored := repo.records["cheque-1/milestone-1"]
+	require.NotNil(t, stored)
+	assert.NotContains(t, stored.EncryptedFulfillment, fulfillment)
+
+	record, err := vault.Lookup(ctx, "cheque-1", "milestone-1", FulfillmentActorPaymentExecution)
+	require.NoError(t, err)
+	assert.Equal(t, condition, record.Condition)
+	assert.Equal(t, uint32(42), record.OfferSequence)
+	assert.Empty(t, record.EncryptedFulfillment)
+
+	auth := &PaymentAuthorization{SmartChequeID: "cheque-1", MilestoneID: "milestone-1", Status: PaymentAuthStatusApproved}
+	record, released, err := vault.releaseForEscrowFinish(ctx, auth)
+	require.NoError(t, err)
+	assert.Equal(t, fulfillment, released)
+	assert.Equal(t, "rOwner", record.EscrowOwner)
+	assert.Equal(t, "rDestination", record.EscrowDestination)
+	assert.Equal(t, 1, repo.records["cheque-1/milestone-1"].ReleaseCount)
+
+	logs, err := vault.GetAccessLog(ctx, "cheque-1", "milestone-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+	assert.Equal(t, models.FulfillmentAccessSeal, logs[0].Action)
+	assert.Equal(t, models.FulfillmentAccessLookup, logs[1].Action)
+	assert.Equal(t, models.FulfillmentAccessRelease, logs[2].Action)
+	for _, entry := range logs {
+		assert.True(t, entry.Success)
+	}
+}
+
+func TestFulfillmentVault_SealRejectsInvalidFulfillment(t *testing.T) {
+	vault, repo := newTestFulfillmentVault(t)
+	ctx := context.Background()
+	condition, _ := newTestCondition(t)
+	_, otherFulfillment := newTestCondition(t)
+
+	err := vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, otherFulfillment, FulfillmentActorEscrowCreation)
+	assert.ErrorIs(t, err, xrpl.ErrConditionMismatch)
+	assert.Empty(t, repo.records)
+
+	require.Len(t, repo.logs, 1)
+	assert.False(t, repo.logs[0].Success)
+	assert.NotEmpty(t, repo.logs[0].Reason)
+}
+
+func TestFulfillmentVault_SealRejectsDuplicate(t *testing.T) {
+	vault, _ := newTestFulfillmentVault(t)
+	ctx := context.Background()
+	condition, fulfillment := newTestCondition(t)
+
+	require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+	assert.Error(t, vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+}
+
+func TestFulfillmentVault_ReleaseDenied(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unapproved authorization", func(t *testing.T) {
+		vault, repo := newTestFulfillmentVault(t)
+		condition, fulfillment := newTestCondition(t)
+		require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+
+		auth := &PaymentAuthorization{SmartChequeID: "cheque-1", MilestoneID: "milestone-1", Status: PaymentAuthStatusPending}
+		_, released, err := vault.releaseForEscrowFinish(ctx, auth)
+		assert.ErrorIs(t, err, ErrFulfillmentReleaseDenied)
+		assert.Empty(t, released)
+		assert.Equal(t, 0, repo.records["cheque-1/milestone-1"].ReleaseCount)
+
+		last := repo.logs[len(repo.logs)-1]
+		assert.Equal(t, models.FulfillmentAccessRelease, last.Action)
+		assert.False(t, last.Success)
+	})
+
+	t.Run("missing fulfillment", func(t *testing.T) {
+		vault, _ := newTestFulfillmentVault(t)
+		auth := &PaymentAuthorization{SmartChequeID: "cheque-1", MilestoneID: "milestone-1", Status: PaymentAuthStatusApproved}
+		_, _, err := vault.releaseForEscrowFinish(ctx, auth)
+		assert.ErrorIs(t, err, ErrFulfillmentNotFound)
+	})
+
+	t.Run("ciphertext bound to another milestone", func(t *testing.T) {
+		vault, repo := newTestFulfillmentVault(t)
+		condition, fulfillment := newTestCondition(t)
+		require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+		require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-2", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+
+		// Swap the ciphertexts between the two rows
+		first, second := repo.records["cheque-1/milestone-1"], repo.records["cheque-1/milestone-2"]
+		first.EncryptedFulfillment, second.EncryptedFulfillment = second.EncryptedFulfillment, first.EncryptedFulfillment
+
+		auth := &PaymentAuthorization{SmartChequeID: "cheque-1", MilestoneID: "milestone-1", Status: PaymentAuthStatusApproved}
+		_, _, err := vault.releaseForEscrowFinish(ctx, auth)
+		assert.ErrorIs(t, err, ErrFulfillmentReleaseDenied)
+	})
+
+	t.Run("audit log unavailable", func(t *testing.T) {
+		vault, repo := newTestFulfillmentVault(t)
+		condition, fulfillment := newTestCondition(t)
+		require.NoError(t, vault.Seal(ctx, "cheque-1", "milestone-1", EscrowReference{}, condition, fulfillment, FulfillmentActorEscrowCreation))
+
+		repo.auditErr = errors.New("database unavailable")
+		auth := &PaymentAuthorization{SmartChequeID: "cheque-1", MilestoneID: "milestone-1", Status: PaymentAuthStatusApproved}
+		_, released, err := vault.releaseForEscrowFinish(ctx, auth)
+		assert.ErrorIs(t, err, ErrFulfillmentReleaseDenied)
+		assert.Empty(t, released)
+		assert.Equal(t, 0, repo.records["cheque-1/milestone-1"].ReleaseCount)
+	})
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// AccountHistoryReader pages through an account's validated history; *XRPLService satisfies it
+type AccountHistoryReader interface {
+	GetAccountTransactions(request *xrpl.AccountTransactionsRequest) (*xrpl.AccountTransactionsPage, error)
+}
+
+var (
+	_ AccountHistoryReader = (*XRPLService)(nil)
+	_ LedgerEventHandler   = (*LedgerIndexerService)(nil)
+)
+
+// defaultIndexerPageSize is how many transactions the indexer requests per account_tx page
+const defaultIndexerPageSize = 200
+
+// LedgerIndexerService backfills the validated history of every managed wallet from account_tx and
+// keeps it current as ledgers close, so reconciliation and audit can compare the platform's
+// records against everything that happened on-ledger, including transactions made elsewhere
+type LedgerIndexerService struct {
+	ledgerRepo repository.LedgerTransactionRepositoryInterface
+	walletRepo repository.WalletRepositoryInterface
+	history    AccountHistoryReader
+	pageSize   uint32
+
+	// mu serializes indexing runs so two runs never page the same account at once
+	mu sync.Mutex
+}
+
+// NewLedgerIndexerService creates a new ledger indexer
+func NewLedgerIndexerService(
+	ledgerRepo repository.LedgerTransactionRepositoryInterface,
+	walletRepo repository.WalletRepositoryInterface,
+	history AccountHistoryReader,
+) *LedgerIndexerService {
+	return &LedgerIndexerService{
+		ledgerRepo: ledgerRepo,
+		walletRepo: walletRepo,
+		history:    history,
+		pageSize:   defaultIndexerPageSize,
+	}
+}
+
+// IndexAll indexes every managed wallet up to the latest validated ledger and returns how many
+// transactions it read. A wallet that fails is logged and retried on the next run.
+func (s *LedgerIndexerService) IndexAll(ctx context.Context) (int, error) {
+	return s.indexWallets(ctx, 0)
+}
+
+// HandleLedgerClosed advances every managed wallet's checkpoint to the closed ledger
+func (s *LedgerIndexerService) HandleLedgerClosed(ctx context.Context, event *xrpl.LedgerEvent) {
+	if _, err := s.indexWallets(ctx, event.LedgerIndex); err != nil {
+		log.Printf("Failed to index ledger %d: %v", event.LedgerIndex, err)
+	}
+}
+
+func (s *LedgerIndexerService) indexWallets(ctx context.Context, throughLedger uint32) (int, error) {
+	wallets, err := s.walletRepo.GetAllWallets()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get wallets: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	indexed := 0
+	for _, wallet := range wallets {
+		// A retired wallet's account no longer exists, and its history was indexed while it did
+		if wallet.Status == models.WalletStatusRetired {
+			continue
+		}
+		count, err := s.indexAccount(ctx, wallet.Address, throughLedger)
+		if err != nil {
+			log.Printf("Failed to index XRPL history of wallet %s: %v", wallet.Address, err)
+			continue
+		}
+		indexed += count
+	}
+	return indexed, nil
+}
+
+// IndexAccount records an account's validated transactions since its checkpoint, following
+// account_tx markers to the last page, then advances the checkpoint. It returns how many
+// transactions it read.
+func (s *LedgerIndexerService) IndexAccount(ctx context.Context, address string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.indexAccount(ctx, address, 0)
+}
+
+// indexAccount indexes an account up to throughLedger, or the latest validated ledger when zero
+func (s *LedgerIndexerService) indexAccount(ctx context.Context, address string, throughLedger uint32) (int, error) {
+	checkpoint, err := s.ledgerRepo.GetLedgerCheckpoint(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	var fromLedger uint32
+	if checkpoint != nil {
+		if throughLedger != 0 && checkpoint.LedgerIndex >= throughLedger {
+			return 0, nil
+		}
+		fromLedger = checkpoint.LedgerIndex + 1
+	}
+
+	request := &xrpl.AccountTransactionsRequest{
+		Account:   address,
+		MinLedger: fromLedger,
+		MaxLedger: throughLedger,
+		Limit:     s.pageSize,
+	}
+	indexed := 0
+	var indexedThrough uint32
+	for {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+
+		page, err := s.history.GetAccountTransactions(request)
+		switch {
+		// An account that was never funded has no history yet
+		case errors.Is(err, xrpl.ErrAccountNotFound):
+			return indexed, nil
+		// The checkpoint is already at the latest validated ledger
+		case errors.Is(err, xrpl.ErrLedgerNotFound) && fromLedger > 0:
+			return indexed, nil
+		case err != nil:
+			return indexed, fmt.Errorf("failed to read account history: %w", err)
+		}
+
+		transactions := make([]*models.LedgerTransaction, 0, len(page.Transactions))
+		for i := range page.Transactions {
+			transactions = append(transactions, ledgerTransactionModel(address, &page.Transactions[i]))
+		}
+		if err := s.ledgerRepo.SaveLedgerTransactions(ctx, transactions); err != nil {
+			return indexed, err
+		}
+		indexed += len(transactions)
+		// The server fixes the ledger range on the first page; later pages continue within it
+		if indexedThrough == 0 {
+			indexedThrough = page.LedgerIndexMax
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		request.Marker = page.Marker
+	}
+
+	if indexedThrough > 0 {
+		if err := s.ledgerRepo.SaveLedgerCheckpoint(ctx, &models.LedgerCheckpoint{Account: address, LedgerIndex: indexedThrough}); err != nil {
+			return indexed, err
+		}
+	}
+	if indexed > 0 {
+		log.Printf("Indexed %d XRPL transactions of %s through ledger %d", indexed, address, indexedThrough)
+	}
+	return indexed, nil
+}
+
+// GetAccountHistory lists an account's indexed transactions from sinceLedger onwards, oldest first
+func (s *LedgerIndexerService) GetAccountHistory(ctx context.Context, address string, sinceLedger uint32, limit, offset int) ([]*models.LedgerTransaction, error) {
+	return s.ledgerRepo.GetLedgerTransactionsByAccount(ctx, address, sinceLedger, limit, offset)
+}
+
+// ledgerTransactionModel converts a transaction read from an account's history into its stored form
+func ledgerTransactionModel(account string, tx *xrpl.LedgerTransaction) *models.LedgerTransaction {
+	record := &models.LedgerTransaction{
+		Account:         account,
+		TransactionHash: tx.Hash,
+		LedgerIndex:     tx.LedgerIndex,
+		TransactionType: tx.TransactionType,
+		Sender:          tx.Account,
+		Destination:     tx.Destination,
+		Sequence:        tx.Sequence,
+		PartialPayment:  tx.PartialPayment,
+		FeeDrops:        tx.FeeDrops,
+		ResultCode:      tx.ResultCode,
+		LedgerTime:      tx.CloseTime,
+	}
+	if tx.Amount != nil {
+		record.AmountValue = tx.Amount.Value
+		record.AmountCurrency = ledgerCurrencyName(*tx.Amount)
+		record.AmountIssuer = tx.Amount.Issuer
+	}
+	if tx.Delivered != nil {
+		record.DeliveredValue = tx.Delivered.Value
+		record.DeliveredCurrency = ledgerCurrencyName(*tx.Delivered)
+		record.DeliveredIssuer = tx.Delivered.Issuer
+	}
+	return record
+}
+
+// ledgerCurrencyName returns the readable currency of an amount, empty for XRP
+func ledgerCurrencyName(amount xrpl.Amount) string {
+	if amount.IsNative() {
+		return ""
+	}
+	return xrpl.CurrencyName(amount.Currency)
+}
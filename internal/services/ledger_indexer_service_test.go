@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// memoryLedgerRepo is an in-memory LedgerTransactionRepositoryInterface
+type memoryLedgerRepo struct {
+	transactions map[string][]*models.LedgerTransaction
+	checkpoints  map[string]uint32
+}
+
+func newMemoryLedgerRepo() *memoryLedgerRepo {
+	return &memoryLedgerRepo{
+		transactions: make(map[string][]*models.LedgerTransaction),
+		checkpoints:  make(map[string]uint32),
+	}
+}
+
+func (r *memoryLedgerRepo) SaveLedgerTransactions(_ context.Context, transactions []*models.LedgerTransaction) error {
+	for _, tx := range transactions {
+		duplicate := false
+		for _, existing := range r.transactions[tx.Account] {
+			duplicate = duplicate || existing.TransactionHash == tx.TransactionHash
+		}
+		if !duplicate {
+			r.transactions[tx.Account] = append(r.transactions[tx.Account], tx)
+		}
+	}
+	return nil
+}
+
+func (r *memoryLedgerRepo) GetLedgerTransactionsByAccount(_ context.Context, account string, sinceLedger uint32, limit, offset int) ([]*models.LedgerTransaction, error) {
+	var matching []*models.LedgerTransaction
+	for _, tx := range r.transactions[account] {
+		if tx.LedgerIndex >= sinceLedger {
+			matching = append(matching, tx)
+		}
+	}
+	if offset >= len(matching) {
+		return []*models.LedgerTransaction{}, nil
+	}
+	matching = matching[offset:]
+	if len(matching) > limit {
+		matching = matching[:limit]
+	}
+	return matching, nil
+}
+
+func (r *memoryLedgerRepo) GetLedgerCheckpoint(_ context.Context, account string) (*models.LedgerCheckpoint, error) {
+	index, ok := r.checkpoints[account]
+	if !ok {
+		return nil, nil
+	}
+	return &models.LedgerCheckpoint{Account: account, LedgerIndex: index}, nil
+}
+
+func (r *memoryLedgerRepo) SaveLedgerCheckpoint(_ context.Context, checkpoint *models.LedgerCheckpoint) error {
+	if checkpoint.LedgerIndex > r.checkpoints[checkpoint.Account] {
+		r.checkpoints[checkpoint.Account] = checkpoint.LedgerIndex
+	}
+	return nil
+}
+
+func TestLedgerIndexerService_BackfillsAndFollowsClosedLedgers(t *testing.T) {
+	managed, outsider, unfunded := newTestKeyPair(t), newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{managed.Address(): managed, outsider.Address(): outsider})
+	require.NoError(t, ledger.Fund(managed.Address(), 100000000))
+	require.NoError(t, ledger.Fund(outsider.Address(), 100000000))
+
+	walletRepo := &MockWalletRepositoryInterface{}
+	walletRepo.On("GetAllWallets").Return([]*models.Wallet{
+		{ID: uuid.New(), Address: managed.Address(), Status: models.WalletStatusActive},
+		{ID: uuid.New(), Address: unfunded.Address(), Status: models.WalletStatusPending},
+	}, nil)
+	ledgerRepo := newMemoryLedgerRepo()
+	indexer := NewLedgerIndexerService(ledgerRepo, walletRepo, xrplService)
+	indexer.pageSize = 2
+
+	// History made outside the platform before the indexer ever ran is backfilled page by page
+	var deposits []string
+	for _, amount := range []int64{5, 7, 9} {
+		result, err := xrplService.SendPayment(outsider.Address(), managed.Address(), models.NewMoney(amount, 0, models.CurrencyXRP))
+		require.NoError(t, err)
+		deposits = append(deposits, result.TransactionID)
+		ledger.CloseLedger()
+	}
+	sent, err := xrplService.SendPayment(managed.Address(), outsider.Address(), models.MustParseMoney("1", models.CurrencyXRP))
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	indexed, err := indexer.IndexAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, indexed)
+	assert.Equal(t, ledger.LedgerIndex(), ledgerRepo.checkpoints[managed.Address()])
+
+	history, err := indexer.GetAccountHistory(context.Background(), managed.Address(), 0, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, deposits[0], history[0].TransactionHash)
+	assert.True(t, history[0].Inbound())
+	assert.Equal(t, "5000000", history[0].DeliveredValue)
+	assert.Equal(t, int64(10), history[0].FeeDrops)
+	assert.False(t, history[0].LedgerTime.IsZero())
+	assert.Equal(t, sent.TransactionID, history[3].TransactionHash)
+	assert.False(t, history[3].Inbound())
+
+	// Running again at the checkpoint reads nothing new
+	indexed, err = indexer.IndexAll(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, indexed)
+
+	// A closed ledger advances the checkpoint to it
+	late, err := xrplService.SendPayment(outsider.Address(), managed.Address(), models.MustParseMoney("2", models.CurrencyXRP))
+	require.NoError(t, err)
+	closed := ledger.CloseLedger()
+	indexer.HandleLedgerClosed(context.Background(), &xrpl.LedgerEvent{LedgerIndex: closed})
+	assert.Equal(t, closed, ledgerRepo.checkpoints[managed.Address()])
+	history, err = indexer.GetAccountHistory(context.Background(), managed.Address(), closed, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, late.TransactionID, history[0].TransactionHash)
+
+	// Reconciliation reports the outside payments no deposit credited
+	deposits = append(deposits, late.TransactionID)
+	balanceRepo := &TestMockBalanceRepository{}
+	balanceRepo.On("GetAssetTransactionsByType", mock.Anything, models.AssetTransactionTypeDeposit, 100, 0).Return([]*models.AssetTransaction{
+		{ID: uuid.New(), CurrencyCode: "XRP", Amount: "5000000", Metadata: map[string]interface{}{"external_tx_hash": deposits[0]}},
+	}, nil)
+	walletRepo.On("GetByAddress", outsider.Address()).Return(nil, assert.AnError)
+	reconciliation := NewReconciliationServiceWithLedgerHistory(balanceRepo, &TestMockAssetRepository{}, &MockXRPLService{}, nil, &ReconciliationConfig{}, ledgerRepo, walletRepo)
+
+	uncredited, err := reconciliation.DetectUncreditedLedgerDeposits(context.Background(), managed.Address(), 0)
+	require.NoError(t, err)
+	require.Len(t, uncredited, 3)
+	for i, finding := range uncredited {
+		assert.Equal(t, deposits[i+1], finding.TransactionHash)
+		assert.Equal(t, outsider.Address(), finding.Sender)
+		assert.Equal(t, "XRP", finding.DeliveredCurrency)
+	}
+}
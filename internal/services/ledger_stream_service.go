@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// LedgerEventHandler reacts to ledgers closed on the XRPL ledger stream
+type LedgerEventHandler interface {
+	HandleLedgerClosed(ctx context.Context, event *xrpl.LedgerEvent)
+}
+
+// TransactionEventHandler reacts to transactions delivered by the XRPL ledger stream
+type TransactionEventHandler interface {
+	HandleTransaction(ctx context.Context, event *xrpl.TransactionEvent)
+}
+
+// XRPLBalanceSyncer records the on-ledger balance of an enterprise; *BalanceService satisfies it
+type XRPLBalanceSyncer interface {
+	SyncXRPLBalance(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, xrplBalance string) error
+}
+
+// XRPLDepositCreditor credits enterprises for payments their wallets receive; *BalanceService satisfies it
+type XRPLDepositCreditor interface {
+	CreditXRPLDeposit(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, delivery *xrpl.PaymentDelivery) (*models.AssetTransaction, error)
+}
+
+var (
+	_ LedgerEventHandler      = (*PaymentConfirmationService)(nil)
+	_ TransactionEventHandler = (*PaymentConfirmationService)(nil)
+	_ TransactionEventHandler = (*EscrowMonitoringService)(nil)
+	_ XRPLBalanceSyncer       = (*BalanceService)(nil)
+	_ XRPLDepositCreditor     = (*BalanceService)(nil)
+)
+
+// LedgerStreamService subscribes to the XRPL ledger stream for the platform's wallets and fans
+// events out to the escrow monitor, payment confirmations and XRPL balance sync
+type LedgerStreamService struct {
+	stream          *xrpl.SubscriptionClient
+	walletRepo      repository.WalletRepositoryInterface
+	balanceService  XRPLBalanceSyncer
+	depositCreditor XRPLDepositCreditor
+
+	mu                  sync.RWMutex
+	managedWallets      map[string]uuid.UUID // address -> enterprise ID
+	ledgerHandlers      []LedgerEventHandler
+	transactionHandlers []TransactionEventHandler
+}
+
+// NewLedgerStreamService creates a new ledger stream service
+func NewLedgerStreamService(
+	stream *xrpl.SubscriptionClient,
+	walletRepo repository.WalletRepositoryInterface,
+	balanceService XRPLBalanceSyncer,
+) *LedgerStreamService {
+	return &LedgerStreamService{
+		stream:         stream,
+		walletRepo:     walletRepo,
+		balanceService: balanceService,
+		managedWallets: make(map[string]uuid.UUID),
+	}
+}
+
+// AddLedgerHandler registers a consumer of closed ledgers
+func (s *LedgerStreamService) AddLedgerHandler(handler LedgerEventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ledgerHandlers = append(s.ledgerHandlers, handler)
+}
+
+// AddTransactionHandler registers a consumer of validated transactions
+func (s *LedgerStreamService) AddTransactionHandler(handler TransactionEventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactionHandlers = append(s.transactionHandlers, handler)
+}
+
+// SetDepositCreditor credits inbound payments to managed wallets by their delivered amount
+func (s *LedgerStreamService) SetDepositCreditor(creditor XRPLDepositCreditor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depositCreditor = creditor
+}
+
+// Start subscribes to every active wallet and runs the stream until the context is canceled
+func (s *LedgerStreamService) Start(ctx context.Context) error {
+	wallets, err := s.walletRepo.GetAllWallets()
+	if err != nil {
+		return err
+	}
+	for _, wallet := range wallets {
+		if wallet.Status != models.WalletStatusActive {
+			continue
+		}
+		if err := s.WatchWallet(wallet); err != nil {
+			return err
+		}
+	}
+
+	s.stream.OnLedger(func(event *xrpl.LedgerEvent) {
+		s.handleLedger(ctx, event)
+	})
+	s.stream.OnTransaction(func(event *xrpl.TransactionEvent) {
+		s.handleTransaction(ctx, event)
+	})
+
+	go func() {
+		if err := s.stream.Run(ctx); err != nil && err != context.Canceled {
+			log.Printf("XRPL ledger stream stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Started XRPL ledger stream for %d wallets", len(s.stream.Accounts()))
+	return nil
+}
+
+// WatchWallet adds a wallet to the account subscription and to balance sync
+func (s *LedgerStreamService) WatchWallet(wallet *models.Wallet) error {
+	s.mu.Lock()
+	s.managedWallets[wallet.Address] = wallet.EnterpriseID
+	s.mu.Unlock()
+
+	return s.stream.SubscribeAccounts(wallet.Address)
+}
+
+// Stop closes the stream connection
+func (s *LedgerStreamService) Stop() error {
+	return s.stream.Close()
+}
+
+func (s *LedgerStreamService) handleLedger(ctx context.Context, event *xrpl.LedgerEvent) {
+	s.mu.RLock()
+	handlers := s.ledgerHandlers
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler.HandleLedgerClosed(ctx, event)
+	}
+}
+
+func (s *LedgerStreamService) handleTransaction(ctx context.Context, event *xrpl.TransactionEvent) {
+	s.mu.RLock()
+	handlers := s.transactionHandlers
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler.HandleTransaction(ctx, event)
+	}
+
+	if event.Validated {
+		s.creditDeposit(ctx, event)
+		s.syncBalances(ctx, event)
+	}
+}
+
+// creditDeposit credits a managed wallet's enterprise for a payment received from outside the
+// platform. The credit is always the metadata's delivered_amount, never the Amount field, which a
+// partial payment can inflate arbitrarily.
+func (s *LedgerStreamService) creditDeposit(ctx context.Context, event *xrpl.TransactionEvent) {
+	if event.TransactionType != "Payment" || !event.Succeeded() {
+		return
+	}
+
+	s.mu.RLock()
+	creditor := s.depositCreditor
+	enterpriseID, managed := s.managedWallets[event.Destination]
+	_, internal := s.managedWallets[event.Account]
+	s.mu.RUnlock()
+	if creditor == nil || !managed || internal {
+		return
+	}
+
+	delivery, err := event.Delivery()
+	if err != nil {
+		log.Printf("Refusing to credit payment %s to %s: %v", event.Hash, event.Destination, err)
+		return
+	}
+	currency := "XRP"
+	if !delivery.Delivered.IsNative() {
+		currency = xrpl.CurrencyName(delivery.Delivered.Currency)
+	}
+	_, err = creditor.CreditXRPLDeposit(ctx, enterpriseID, currency, delivery)
+	switch {
+	// A quarantined deposit was recorded for review and is not a failure
+	case errors.Is(err, ErrDepositQuarantined):
+	case err != nil:
+		log.Printf("Failed to credit deposit %s to enterprise %s: %v", event.Hash, enterpriseID, err)
+	}
+}
+
+// syncBalances records the new XRP balance of each managed wallet the transaction changed
+func (s *LedgerStreamService) syncBalances(ctx context.Context, event *xrpl.TransactionEvent) {
+	for address, drops := range event.AccountBalances() {
+		s.mu.RLock()
+		enterpriseID, managed := s.managedWallets[address]
+		s.mu.RUnlock()
+		if !managed {
+			continue
+		}
+
+		if err := s.balanceService.SyncXRPLBalance(ctx, enterpriseID, "XRP", drops); err != nil {
+			log.Printf("Failed to sync XRPL balance of %s after %s: %v", address, event.Hash, err)
+		}
+	}
+}
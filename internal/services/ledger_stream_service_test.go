@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+type recordingBalanceSyncer struct {
+	synced map[uuid.UUID]string
+}
+
+func (r *recordingBalanceSyncer) SyncXRPLBalance(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, xrplBalance string) error {
+	r.synced[enterpriseID] = currencyCode + ":" + xrplBalance
+	return nil
+}
+
+type recordingDepositCreditor struct {
+	credited []string
+}
+
+func (r *recordingDepositCreditor) CreditXRPLDeposit(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, delivery *xrpl.PaymentDelivery) (*models.AssetTransaction, error) {
+	r.credited = append(r.credited, currencyCode+":"+delivery.Delivered.Value)
+	return &models.AssetTransaction{EnterpriseID: enterpriseID, CurrencyCode: currencyCode}, nil
+}
+
+type recordingStreamHandler struct {
+	ledgers      []uint32
+	transactions []string
+}
+
+func (r *recordingStreamHandler) HandleLedgerClosed(ctx context.Context, event *xrpl.LedgerEvent) {
+	r.ledgers = append(r.ledgers, event.LedgerIndex)
+}
+
+func (r *recordingStreamHandler) HandleTransaction(ctx context.Context, event *xrpl.TransactionEvent) {
+	r.transactions = append(r.transactions, event.Hash)
+}
+
+// escrowSyncRecorder records SyncEscrowStatus calls; other methods are not used by the monitor in event mode
+type escrowSyncRecorder struct {
+	SmartChequeXRPLServiceInterface
+	synced []string
+}
+
+func (r *escrowSyncRecorder) SyncEscrowStatus(ctx context.Context, smartChequeID string) error {
+	r.synced = append(r.synced, smartChequeID)
+	return nil
+}
+
+func escrowFinishEvent(createHash string, balances map[string]string) *xrpl.TransactionEvent {
+	nodes := []xrpl.AffectedNode{{DeletedNode: &xrpl.NodeChange{
+		LedgerEntryType: "Escrow",
+		FinalFields:     map[string]interface{}{"PreviousTxnID": createHash},
+	}}}
+	for account, balance := range balances {
+		nodes = append(nodes, xrpl.AffectedNode{ModifiedNode: &xrpl.NodeChange{
+			LedgerEntryType: "AccountRoot",
+			FinalFields:     map[string]interface{}{"Account": account, "Balance": balance},
+		}})
+	}
+
+	return &xrpl.TransactionEvent{
+		Hash:            "FINISHHASH",
+		TransactionType: "EscrowFinish",
+		EngineResult:    "tesSUCCESS",
+		Validated:       true,
+		Meta:            &xrpl.TransactionMeta{TransactionResult: "tesSUCCESS", AffectedNodes: nodes},
+	}
+}
+
+func TestLedgerStreamService_FansOutEvents(t *testing.T) {
+	syncer := &recordingBalanceSyncer{synced: make(map[uuid.UUID]string)}
+	service := NewLedgerStreamService(xrpl.NewSubscriptionClient(xrpl.StreamConfig{URL: "ws://localhost:0"}), nil, syncer)
+	handler := &recordingStreamHandler{}
+	service.AddLedgerHandler(handler)
+	service.AddTransactionHandler(handler)
+
+	enterpriseID := uuid.New()
+	require.NoError(t, service.WatchWallet(&models.Wallet{Address: "rManaged", EnterpriseID: enterpriseID}))
+	assert.Equal(t, []string{"rManaged"}, service.stream.Accounts())
+
+	ctx := context.Background()
+	service.handleLedger(ctx, &xrpl.LedgerEvent{LedgerIndex: 100})
+	service.handleTransaction(ctx, escrowFinishEvent("CREATEHASH", map[string]string{
+		"rManaged":     "26000000",
+		"rSomeoneElse": "5000000",
+	}))
+
+	assert.Equal(t, []uint32{100}, handler.ledgers)
+	assert.Equal(t, []string{"FINISHHASH"}, handler.transactions)
+	assert.Equal(t, map[uuid.UUID]string{enterpriseID: "XRP:26000000"}, syncer.synced)
+
+	// Unvalidated transactions reach handlers but never move balances
+	unvalidated := escrowFinishEvent("CREATEHASH", map[string]string{"rManaged": "1"})
+	unvalidated.Validated = false
+	service.handleTransaction(ctx, unvalidated)
+	assert.Equal(t, "XRP:26000000", syncer.synced[enterpriseID])
+}
+
+func TestLedgerStreamService_CreditsDeliveredAmount(t *testing.T) {
+	service := NewLedgerStreamService(xrpl.NewSubscriptionClient(xrpl.StreamConfig{URL: "ws://localhost:0"}), nil, &recordingBalanceSyncer{synced: make(map[uuid.UUID]string)})
+	creditor := &recordingDepositCreditor{}
+	service.SetDepositCreditor(creditor)
+	require.NoError(t, service.WatchWallet(&models.Wallet{Address: "rManaged", EnterpriseID: uuid.New()}))
+	require.NoError(t, service.WatchWallet(&models.Wallet{Address: "rTreasury", EnterpriseID: uuid.New()}))
+
+	payment := func(hash, from string, flags float64, delivered interface{}) *xrpl.TransactionEvent {
+		meta := &xrpl.TransactionMeta{TransactionResult: "tesSUCCESS"}
+		if amount, ok := delivered.(xrpl.Amount); ok {
+			meta.DeliveredAmount = &amount
+		}
+		return &xrpl.TransactionEvent{
+			Hash:            hash,
+			TransactionType: "Payment",
+			Account:         from,
+			Destination:     "rManaged",
+			EngineResult:    "tesSUCCESS",
+			Validated:       true,
+			Transaction: map[string]interface{}{
+				"Account":     from,
+				"Destination": "rManaged",
+				"Amount":      map[string]interface{}{"currency": "USD", "issuer": "rIssuer", "value": "1000000"},
+				"Flags":       flags,
+			},
+			Meta: meta,
+		}
+	}
+
+	ctx := context.Background()
+	service.handleTransaction(ctx, payment("PARTIAL", "rOutsider", float64(xrpl.PaymentFlagPartialPayment),
+		xrpl.Amount{Currency: "USD", Issuer: "rIssuer", Value: "0.5"}))
+	// A partial payment without delivered_amount is never credited at its Amount
+	service.handleTransaction(ctx, payment("UNKNOWN", "rOutsider", float64(xrpl.PaymentFlagPartialPayment), nil))
+	// Transfers between platform wallets are not deposits
+	service.handleTransaction(ctx, payment("INTERNAL", "rTreasury", 0, xrpl.Amount{Currency: "USD", Issuer: "rIssuer", Value: "1000000"}))
+
+	assert.Equal(t, []string{"USD:0.5"}, creditor.credited)
+}
+
+func TestEscrowMonitoringService_EventDriven(t *testing.T) {
+	smartChequeRepo := &mockSmartChequeRepoXRPL{}
+	smartChequeRepo.On("GetSmartChequeByID", context.Background(), "cheque-1").
+		Return(&models.SmartCheque{ID: "cheque-1", EscrowAddress: "CREATEHASH"}, nil)
+
+	recorder := &escrowSyncRecorder{}
+	monitor := NewEscrowMonitoringService(recorder, smartChequeRepo, time.Hour)
+	monitor.EnableEventDrivenMonitoring()
+
+	ctx := context.Background()
+	require.NoError(t, monitor.StartMonitoringForSmartCheque(ctx, "cheque-1"))
+	assert.Equal(t, []string{"cheque-1"}, monitor.GetMonitoredSmartCheques())
+
+	monitor.HandleTransaction(ctx, escrowFinishEvent("OTHERHASH", nil))
+	assert.Empty(t, recorder.synced)
+
+	monitor.HandleTransaction(ctx, escrowFinishEvent("CREATEHASH", nil))
+	assert.Equal(t, []string{"cheque-1"}, recorder.synced)
+
+	require.NoError(t, monitor.StopMonitoringForSmartCheque("cheque-1"))
+	monitor.HandleTransaction(ctx, escrowFinishEvent("CREATEHASH", nil))
+	assert.Equal(t, []string{"cheque-1"}, recorder.synced)
+}
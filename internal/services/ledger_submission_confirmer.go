@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// ledgerSubmissionPageSize is how many submitted transactions the confirmer reads at a time
+const ledgerSubmissionPageSize = 100
+
+// recordLedgerSubmission records a submitted transaction's result. Only a result from a validated
+// ledger confirms it; a provisional result leaves it submitted for the LedgerSubmissionConfirmer.
+func recordLedgerSubmission(transaction *models.Transaction, result *xrpl.TransactionResult) {
+	recordSubmission(transaction, result)
+	now := time.Now()
+	transaction.ProcessedAt = &now
+	if result == nil || !result.Validated {
+		transaction.Status = models.TransactionStatusSubmitted
+		return
+	}
+	transaction.Status = models.TransactionStatusConfirmed
+	transaction.ConfirmedAt = &now
+}
+
+// recordEscrowResolution records a submitted EscrowFinish or EscrowCancel of a milestone escrow. It
+// stays submitted even when its result already came from a validated ledger, so the escrow is
+// always resolved the same way: by the LedgerSubmissionConfirmer once it confirms the transaction.
+func recordEscrowResolution(transaction *models.Transaction, result *xrpl.TransactionResult) {
+	recordLedgerSubmission(transaction, result)
+	transaction.Status = models.TransactionStatusSubmitted
+	transaction.ConfirmedAt = nil
+}
+
+// settleEscrowResolution applies the outcome of a settled EscrowFinish or EscrowCancel to the
+// milestone escrow waiting on it, returning the smart check to save and whether the escrow was
+// resolved as status. A failed or expired transaction leaves the escrow active with the reason
+// recorded. It returns a nil smart check when no escrow waits on the transaction.
+func settleEscrowResolution(ctx context.Context, smartChequeRepo repository.SmartChequeRepositoryInterface, transaction *models.Transaction, status models.MilestoneEscrowStatus) (*models.SmartCheque, bool, error) {
+	if transaction.SmartChequeID == nil || transaction.MilestoneID == nil {
+		return nil, false, nil
+	}
+	smartCheque, err := smartChequeRepo.GetSmartChequeByID(ctx, *transaction.SmartChequeID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get smart check %s: %w", *transaction.SmartChequeID, err)
+	}
+	if smartCheque == nil {
+		return nil, false, nil
+	}
+	milestoneID := *transaction.MilestoneID
+	milestone := smartCheque.FindMilestone(milestoneID)
+	if milestone == nil || milestone.Escrow == nil || milestone.Escrow.PendingTransaction != transaction.TransactionHash {
+		return nil, false, nil
+	}
+
+	if transaction.Status == models.TransactionStatusConfirmed {
+		smartCheque.ResolveMilestoneEscrow(milestoneID, status, transaction.TransactionHash, time.Now())
+		return smartCheque, true, nil
+	}
+	smartCheque.FailMilestoneEscrowResolution(milestoneID, transaction.TransactionHash, transaction.LastError)
+	log.Printf("Warning: Escrow of smart check %s milestone %s stays active: %s transaction %s is %s: %s",
+		smartCheque.ID, milestoneID, transaction.Type, transaction.TransactionHash, transaction.Status, transaction.LastError)
+	return smartCheque, false, nil
+}
+
+// LedgerSubmissionObserver carries out what the outcome of a submitted transaction settles, such
+// as resolving the milestone escrow an EscrowFinish or EscrowCancel removes from the ledger
+type LedgerSubmissionObserver interface {
+	// SubmissionSettled is called once a transaction is confirmed, failed or expired, before that
+	// status is saved. An error leaves the transaction submitted, so it is settled again on the next
+	// pass; observers skip outcomes they already carried out.
+	SubmissionSettled(ctx context.Context, transaction *models.Transaction) error
+}
+
+// LedgerSubmissionConfirmer periodically looks up the smart check transactions recorded as
+// submitted, confirming those a validated ledger applied and failing those it rejected or that
+// expired past their LastLedgerSequence
+type LedgerSubmissionConfirmer struct {
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface
+	xrplService     *XRPLService
+	interval        time.Duration
+
+	mu        sync.Mutex
+	running   bool
+	stopChan  chan struct{}
+	observers []LedgerSubmissionObserver
+}
+
+// NewLedgerSubmissionConfirmer creates a confirmer that runs every interval once started
+func NewLedgerSubmissionConfirmer(
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface,
+	xrplService *XRPLService,
+	interval time.Duration,
+) *LedgerSubmissionConfirmer {
+	return &LedgerSubmissionConfirmer{
+		transactionRepo: transactionRepo,
+		xrplService:     xrplService,
+		interval:        interval,
+	}
+}
+
+// AddObserver has observer follow the outcome of every submitted transaction
+func (c *LedgerSubmissionConfirmer) AddObserver(observer LedgerSubmissionObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observers = append(c.observers, observer)
+}
+
+// Start confirms in the background until Stop is called or ctx is done
+func (c *LedgerSubmissionConfirmer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return fmt.Errorf("ledger submission confirmer is already running")
+	}
+	c.running = true
+	c.stopChan = make(chan struct{})
+
+	go c.run(ctx, c.stopChan)
+	log.Printf("Ledger submission confirmer started with interval %v", c.interval)
+	return nil
+}
+
+// Stop ends background confirmation
+func (c *LedgerSubmissionConfirmer) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.running {
+		return
+	}
+	close(c.stopChan)
+	c.running = false
+	log.Printf("Ledger submission confirmer stopped")
+}
+
+func (c *LedgerSubmissionConfirmer) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := c.Confirm(ctx); err != nil {
+				log.Printf("Error confirming ledger submissions: %v", err)
+			}
+		}
+	}
+}
+
+// Confirm looks up every submitted transaction once and returns how many reached a final status.
+// A transaction that cannot be looked up is logged and left submitted for the next pass.
+func (c *LedgerSubmissionConfirmer) Confirm(ctx context.Context) (int, error) {
+	// Collect first: confirming a transaction moves it out of the status being paged through
+	var submitted []*models.Transaction
+	for offset := 0; ; offset += ledgerSubmissionPageSize {
+		transactions, err := c.transactionRepo.GetTransactionsByStatus(models.TransactionStatusSubmitted, ledgerSubmissionPageSize, offset)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list submitted transactions: %w", err)
+		}
+		submitted = append(submitted, transactions...)
+		if len(transactions) < ledgerSubmissionPageSize {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	observers := append([]LedgerSubmissionObserver(nil), c.observers...)
+	c.mu.Unlock()
+
+	settled := 0
+	for _, transaction := range submitted {
+		if err := ctx.Err(); err != nil {
+			return settled, err
+		}
+		final, err := c.confirm(ctx, transaction, observers)
+		if err != nil {
+			log.Printf("Warning: Failed to confirm transaction %s: %v", transaction.TransactionHash, err)
+			continue
+		}
+		if final {
+			settled++
+		}
+	}
+	return settled, nil
+}
+
+// confirm records the validated outcome of a submitted transaction, reporting whether it has one yet
+func (c *LedgerSubmissionConfirmer) confirm(ctx context.Context, transaction *models.Transaction, observers []LedgerSubmissionObserver) (bool, error) {
+	if transaction.TransactionHash == "" {
+		return false, fmt.Errorf("transaction %s has no hash", transaction.ID)
+	}
+	var lastLedgerSequence uint32
+	if transaction.LastLedgerSequence != nil {
+		lastLedgerSequence = *transaction.LastLedgerSequence
+	}
+
+	result, err := c.xrplService.CheckValidation(transaction.TransactionHash, lastLedgerSequence)
+	switch {
+	case result == nil && err == nil:
+		return false, nil
+	case errors.Is(err, xrpl.ErrTransactionExpired):
+		transaction.Status = models.TransactionStatusExpired
+		transaction.LastError = err.Error()
+	case result == nil:
+		return false, err
+	case err != nil:
+		// Validated with a failure result; the ledger applied only the fee
+		recordSubmission(transaction, result)
+		transaction.Status = models.TransactionStatusFailed
+		transaction.LastError = err.Error()
+	default:
+		recordSubmission(transaction, result)
+		transaction.Status = models.TransactionStatusConfirmed
+		now := time.Now()
+		transaction.ConfirmedAt = &now
+	}
+
+	for _, observer := range observers {
+		if err := observer.SubmissionSettled(ctx, transaction); err != nil {
+			return false, fmt.Errorf("failed to settle %s transaction %s: %w", transaction.Type, transaction.TransactionHash, err)
+		}
+	}
+	if err := c.transactionRepo.UpdateTransaction(transaction); err != nil {
+		return false, fmt.Errorf("failed to record outcome of transaction %s: %w", transaction.TransactionHash, err)
+	}
+	log.Printf("%s transaction %s is %s", transaction.Type, transaction.TransactionHash, transaction.Status)
+	return true, nil
+}
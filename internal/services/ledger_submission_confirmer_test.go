@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
+)
+
+// recordedSubmissions serves a LedgerSubmissionConfirmer the transactions a test recorded
+type recordedSubmissions struct {
+	repository.SmartChequeTransactionRepositoryInterface
+	transactions []*models.Transaction
+}
+
+func (r *recordedSubmissions) GetTransactionsByStatus(status models.TransactionStatus, limit, offset int) ([]*models.Transaction, error) {
+	var matching []*models.Transaction
+	for _, transaction := range r.transactions {
+		if transaction.Status == status {
+			matching = append(matching, transaction)
+		}
+	}
+	if offset >= len(matching) {
+		return nil, nil
+	}
+	matching = matching[offset:]
+	if len(matching) > limit {
+		matching = matching[:limit]
+	}
+	return matching, nil
+}
+
+func (r *recordedSubmissions) UpdateTransaction(*models.Transaction) error {
+	return nil
+}
+
+// confirmRecorded closes the ledger and has a LedgerSubmissionConfirmer followed by observers
+// settle the transactions recorded through transactionRepo, returning how many it settled
+func confirmRecorded(t *testing.T, ledger *simulator.Ledger, xrplService *XRPLService, transactionRepo *mockTransactionRepoXRPL, observers ...LedgerSubmissionObserver) int {
+	t.Helper()
+	ledger.CloseLedger()
+	recorded := &recordedSubmissions{}
+	for _, call := range transactionRepo.Calls {
+		if call.Method == "CreateTransaction" {
+			recorded.transactions = append(recorded.transactions, call.Arguments.Get(0).(*models.Transaction))
+		}
+	}
+
+	confirmer := NewLedgerSubmissionConfirmer(recorded, xrplService, 0)
+	for _, observer := range observers {
+		confirmer.AddObserver(observer)
+	}
+	settled, err := confirmer.Confirm(context.Background())
+	require.NoError(t, err)
+	return settled
+}
+
+func TestLedgerSubmissionConfirmer_ConfirmsValidatedSubmissions(t *testing.T) {
+	f := newSealedEscrowFixture(t, approverKeys{})
+	ctx := context.Background()
+	f.milestone().Status = models.MilestoneStatusVerified
+	service := NewPaymentExecutionService(nil, f.smartChequeRepo, f.transactionRepo, f.xrplService, f.vault, f.eventBus, &PaymentExecutionConfig{})
+	_, err := service.ReleaseMilestoneEscrow(ctx, f.authorization(PaymentAuthStatusApproved))
+	require.NoError(t, err)
+	finishes := f.recorded(models.TransactionTypeEscrowFinish)
+	require.Len(t, finishes, 1)
+	finish := finishes[0]
+	require.Equal(t, models.TransactionStatusSubmitted, finish.Status)
+
+	f.transactionRepo.On("GetTransactionsByStatus", models.TransactionStatusSubmitted, ledgerSubmissionPageSize, 0).Return([]*models.Transaction{finish}, nil)
+	f.transactionRepo.On("UpdateTransaction", finish).Return(nil)
+	confirmer := NewLedgerSubmissionConfirmer(f.transactionRepo, f.xrplService, 0)
+
+	// A submission no validated ledger holds yet stays submitted
+	settled, err := confirmer.Confirm(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, settled)
+	assert.Equal(t, models.TransactionStatusSubmitted, finish.Status)
+	f.transactionRepo.AssertNotCalled(t, "UpdateTransaction", mock.Anything)
+
+	f.ledger.CloseLedger()
+	settled, err = confirmer.Confirm(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	assert.Equal(t, models.TransactionStatusConfirmed, finish.Status)
+	assert.NotNil(t, finish.ConfirmedAt)
+	require.NotNil(t, finish.LedgerIndex)
+	f.transactionRepo.AssertCalled(t, "UpdateTransaction", finish)
+}
+
+func TestLedgerSubmissionConfirmer_ExpiresLostSubmissions(t *testing.T) {
+	f := newSealedEscrowFixture(t, approverKeys{})
+	ctx := context.Background()
+	lastLedgerSequence := uint32(1)
+	lost := models.NewTransaction(models.TransactionTypeEscrowCancel, f.payer.Address(), f.payer.Address(), "10", "XRP", f.smartCheque.PayerID, f.smartCheque.PayerID)
+	lost.SmartChequeID = &f.smartCheque.ID
+	lost.TransactionHash = "0F7E4E2B4F3E0C7A3B9A1D5E6F708192A3B4C5D6E7F8091A2B3C4D5E6F708192"
+	lost.LastLedgerSequence = &lastLedgerSequence
+	lost.Status = models.TransactionStatusSubmitted
+
+	f.transactionRepo.On("GetTransactionsByStatus", models.TransactionStatusSubmitted, ledgerSubmissionPageSize, 0).Return([]*models.Transaction{lost}, nil)
+	f.transactionRepo.On("UpdateTransaction", lost).Return(nil)
+
+	// The ledger passed the submission's LastLedgerSequence without applying it
+	settled, err := NewLedgerSubmissionConfirmer(f.transactionRepo, f.xrplService, 0).Confirm(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	assert.Equal(t, models.TransactionStatusExpired, lost.Status)
+	assert.NotEmpty(t, lost.LastError)
+	assert.Nil(t, lost.ConfirmedAt)
+}
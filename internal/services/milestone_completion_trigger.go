@@ -226,14 +226,14 @@ func (s *milestoneCompletionTriggerService) PublishMilestoneCompletedEvent(ctx c
 	}
 
 	var smartChequeID string
-	var amount float64
+	var amount models.Money
 	if smartCheque != nil {
 		smartChequeID = smartCheque.ID
 		amount = smartCheque.Amount
 	}
 
 	// Create and publish the event
-	event := messaging.NewMilestoneCompletedEvent(milestoneID, smartChequeID, amount)
+	event := messaging.NewMilestoneCompletedEvent(milestoneID, smartChequeID, amount.String())
 	event.Data["contract_id"] = milestone.ContractID
 	event.Data["milestone_description"] = milestone.TriggerConditions
 	event.Data["verified_at"] = milestone.UpdatedAt.Format(time.RFC3339)
@@ -47,16 +47,28 @@ type MilestoneProgress struct {
 type milestoneProgressionService struct {
 	milestoneRepo   repository.MilestoneRepositoryInterface
 	smartChequeRepo repository.SmartChequeRepositoryInterface
+	channels        MilestoneChannelReleaser
 }
 
 // NewMilestoneProgressionService creates a new milestone progression service
 func NewMilestoneProgressionService(
 	milestoneRepo repository.MilestoneRepositoryInterface,
 	smartChequeRepo repository.SmartChequeRepositoryInterface,
+) MilestoneProgressionServiceInterface {
+	return NewMilestoneProgressionServiceWithChannels(milestoneRepo, smartChequeRepo, nil)
+}
+
+// NewMilestoneProgressionServiceWithChannels creates a milestone progression service that releases
+// payment channel claims as payment_channel milestones progress
+func NewMilestoneProgressionServiceWithChannels(
+	milestoneRepo repository.MilestoneRepositoryInterface,
+	smartChequeRepo repository.SmartChequeRepositoryInterface,
+	channels MilestoneChannelReleaser,
 ) MilestoneProgressionServiceInterface {
 	return &milestoneProgressionService{
 		milestoneRepo:   milestoneRepo,
 		smartChequeRepo: smartChequeRepo,
+		channels:        channels,
 	}
 }
 
@@ -168,6 +180,10 @@ func (s *milestoneProgressionService) CompleteMilestone(ctx context.Context, mil
 		return fmt.Errorf("failed to update milestone %s: %w", milestoneID, err)
 	}
 
+	if err := s.releaseChannelClaim(ctx, milestoneID, milestone.PercentageComplete); err != nil {
+		return err
+	}
+
 	// Trigger payment release if associated with a smart check
 	smartCheque, err := s.smartChequeRepo.GetSmartChequesByMilestone(ctx, milestoneID)
 	if err == nil && smartCheque != nil {
@@ -245,6 +261,26 @@ func (s *milestoneProgressionService) UpdateMilestoneProgress(ctx context.Contex
 		return fmt.Errorf("failed to update milestone %s: %w", milestoneID, err)
 	}
 
+	return s.releaseChannelClaim(ctx, milestoneID, percentageComplete)
+}
+
+// releaseChannelClaim pays out the progress of a payment_channel milestone as a channel claim
+func (s *milestoneProgressionService) releaseChannelClaim(ctx context.Context, milestoneID string, percentageComplete float64) error {
+	if s.channels == nil {
+		return nil
+	}
+	smartCheque, err := s.smartChequeRepo.GetSmartChequesByMilestone(ctx, milestoneID)
+	if err != nil || smartCheque == nil {
+		return nil
+	}
+	for _, chequeMilestone := range smartCheque.Milestones {
+		if chequeMilestone.ID != milestoneID || chequeMilestone.PaymentMode != models.MilestonePaymentModeChannel {
+			continue
+		}
+		if _, err := s.channels.ReleaseProgress(ctx, milestoneID, percentageComplete); err != nil {
+			return fmt.Errorf("failed to release channel claim for milestone %s: %w", milestoneID, err)
+		}
+	}
 	return nil
 }
 
@@ -133,7 +133,7 @@ func (s *milestoneSmartChequeService) GenerateSmartChequeFromMilestone(ctx conte
 
 	// Calculate amount based on milestone (using a placeholder for now)
 	// In a real implementation, this would be based on the contract terms
-	amount := 1000.0 // Placeholder amount
+	amount := models.MustParseMoney("1000", models.CurrencyUSDT) // Placeholder amount
 
 	// Create milestone for the smart check
 	smartChequeMilestone := models.Milestone{
@@ -381,7 +381,10 @@ func (s *milestoneSmartChequeService) ProcessPartialPayment(ctx context.Context,
 	}
 
 	// Calculate partial amount
-	partialAmount := smartCheque.Amount * (percentage / 100.0)
+	partialAmount, err := percentageOf(smartCheque.Amount, percentage)
+	if err != nil {
+		return fmt.Errorf("failed to calculate partial amount for milestone %s: %w", milestoneID, err)
+	}
 
 	// In a real implementation, we would:
 	// 1. Create a partial smart check or modify existing escrow
@@ -389,7 +392,7 @@ func (s *milestoneSmartChequeService) ProcessPartialPayment(ctx context.Context,
 	// 3. Update the milestone progress
 	// 4. Update the smart check status
 
-	log.Printf("Processing partial payment for milestone %s: %.2f%% (Amount: %.2f)", milestoneID, percentage, partialAmount)
+	log.Printf("Processing partial payment for milestone %s: %.2f%% (Amount: %s)", milestoneID, percentage, partialAmount)
 
 	// Update milestone progress
 	milestone.PercentageComplete = percentage
@@ -250,8 +250,26 @@ func (m *mockXRPLService) HealthCheck() error {
 	return args.Error(0)
 }
 
-func (m *mockXRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount float64, currency string, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestoneSecret)
+func (m *mockXRPLService) GetAccountReserve(address string) (*xrpl.AccountReserve, error) {
+	args := m.Called(address)
+	reserve, _ := args.Get(0).(*xrpl.AccountReserve)
+	return reserve, args.Error(1)
+}
+
+func (m *mockXRPLService) DeleteAccount(address, destination string) (*xrpl.TransactionResult, error) {
+	args := m.Called(address, destination)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLService) WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error) {
+	args := m.Called(ctx, hash, lastLedgerSequence)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestoneSecret)
 	return args.Get(0).(*xrpl.TransactionResult), args.String(1), args.Error(2)
 }
 
@@ -270,14 +288,59 @@ func (m *mockXRPLService) GetEscrowStatus(ownerAddress string, sequence string)
 	return args.Get(0).(*xrpl.EscrowInfo), args.Error(1)
 }
 
+func (m *mockXRPLService) ListEscrows(ownerAddress string) ([]xrpl.EscrowInfo, error) {
+	args := m.Called(ownerAddress)
+	return args.Get(0).([]xrpl.EscrowInfo), args.Error(1)
+}
+
+func (m *mockXRPLService) FindEscrowResolution(ownerAddress string, sequence, sinceLedger uint32) (*xrpl.EscrowResolution, error) {
+	args := m.Called(ownerAddress, sequence, sinceLedger)
+	return args.Get(0).(*xrpl.EscrowResolution), args.Error(1)
+}
+
+func (m *mockXRPLService) GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*xrpl.PaymentDelivery), args.Error(1)
+}
+
+func (m *mockXRPLService) CreateSmartChequeCheck(payerAddress, payeeAddress string, amount models.Money, validFor time.Duration, invoiceID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, validFor, invoiceID)
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *mockXRPLService) CashSmartChequeCheck(payeeAddress, checkID string, amount models.Money, minimum bool) (*xrpl.TransactionResult, error) {
+	args := m.Called(payeeAddress, checkID, amount, minimum)
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *mockXRPLService) CancelSmartChequeCheck(accountAddress, checkID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(accountAddress, checkID)
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *mockXRPLService) GetCheck(checkID string) (*xrpl.CheckInfo, error) {
+	args := m.Called(checkID)
+	return args.Get(0).(*xrpl.CheckInfo), args.Error(1)
+}
+
+func (m *mockXRPLService) FindCheckResolution(sourceAddress, checkID string, sinceLedger uint32) (*xrpl.CheckResolution, error) {
+	args := m.Called(sourceAddress, checkID, sinceLedger)
+	return args.Get(0).(*xrpl.CheckResolution), args.Error(1)
+}
+
+func (m *mockXRPLService) SendPayment(fromAddress, toAddress string, amount models.Money) (*xrpl.TransactionResult, error) {
+	args := m.Called(fromAddress, toAddress, amount)
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
 func (m *mockXRPLService) GenerateCondition(secret string) (condition string, fulfillment string, err error) {
 	args := m.Called(secret)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
-func (m *mockXRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount float64, currency string, milestones []models.Milestone) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestones)
-	return args.Get(0).(*xrpl.TransactionResult), args.String(1), args.Error(2)
+func (m *mockXRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount models.Money, milestones []models.Milestone) ([]models.MilestoneEscrowFunding, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestones)
+	return args.Get(0).([]models.MilestoneEscrowFunding), args.Error(1)
 }
 
 func TestGenerateSmartChequeFromMilestone(t *testing.T) {
@@ -378,7 +441,7 @@ func TestTriggerPaymentRelease(t *testing.T) {
 		ID:            "sc-test-milestone-1",
 		PayerID:       "payer-1",
 		PayeeID:       "payee-1",
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		EscrowAddress: "escrow-address-123",
 		Status:        models.SmartChequeStatusLocked,
@@ -571,7 +634,7 @@ func TestTriggerPaymentReleaseWithSmartCheque(t *testing.T) {
 		ID:            "sc-test-milestone-1",
 		PayerID:       "payer-1",
 		PayeeID:       "payee-1",
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		EscrowAddress: "escrow-address-123",
 		Status:        models.SmartChequeStatusLocked,
@@ -636,7 +699,7 @@ func TestHandleMilestoneFailure(t *testing.T) {
 		ID:            "sc-test-milestone-1",
 		PayerID:       "payer-1",
 		PayeeID:       "payee-1",
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		EscrowAddress: "escrow-address-123",
 		Status:        models.SmartChequeStatusLocked,
@@ -701,7 +764,7 @@ func TestProcessPartialPayment(t *testing.T) {
 		ID:            "sc-test-milestone-1",
 		PayerID:       "payer-1",
 		PayeeID:       "payee-1",
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		EscrowAddress: "escrow-address-123",
 		Status:        models.SmartChequeStatusLocked,
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+var (
+	ErrSignerListNotConfigured = errors.New("no multi-signing wallet configured")
+	ErrQuorumUnavailable       = errors.New("no multi-signing wallet enforces the required approvals")
+	ErrMultiSignSessionClosed  = errors.New("multi-sign session is no longer collecting signatures")
+	ErrNotAWalletSigner        = errors.New("approver is not a signer of the wallet")
+	ErrQuorumNotMet            = errors.New("collected signatures do not meet the quorum")
+)
+
+// MultiSignClient is the subset of XRPL operations multi-signing needs; *XRPLService satisfies it
+type MultiSignClient interface {
+	SetSignerList(account string, quorum uint32, entries []xrpl.SignerEntry) (*xrpl.TransactionResult, error)
+	PrepareMultiSigned(tx xrpl.Transaction, signerCount int) (xrpl.Transaction, error)
+	SubmitMultiSigned(tx xrpl.Transaction, signers []xrpl.Signer) (*xrpl.TransactionResult, error)
+}
+
+var _ MultiSignClient = (*XRPLService)(nil)
+
+// WalletSigner maps an approver to the XRPL account they sign with and its weight
+type WalletSigner struct {
+	ApproverID uuid.UUID `json:"approver_id"`
+	Account    string    `json:"account"`
+	Weight     uint16    `json:"weight"`
+}
+
+// SignerListConfig is the signer list of a multi-signing enterprise wallet
+type SignerListConfig struct {
+	EnterpriseID  uuid.UUID      `json:"enterprise_id"`
+	WalletAddress string         `json:"wallet_address"`
+	Quorum        uint32         `json:"quorum"`
+	Signers       []WalletSigner `json:"signers"`
+	ConfiguredAt  time.Time      `json:"configured_at"`
+}
+
+// signer returns the signer entry of an approver
+func (c *SignerListConfig) signer(approverID uuid.UUID) (WalletSigner, bool) {
+	for _, signer := range c.Signers {
+		if signer.ApproverID == approverID {
+			return signer, true
+		}
+	}
+	return WalletSigner{}, false
+}
+
+// MultiSignStatus represents the state of a multi-sign session
+type MultiSignStatus string
+
+const (
+	MultiSignStatusCollecting MultiSignStatus = "collecting"
+	MultiSignStatusSubmitted  MultiSignStatus = "submitted"
+	MultiSignStatusFailed     MultiSignStatus = "failed"
+)
+
+// MultiSignSession collects approver signatures over one prepared transaction
+type MultiSignSession struct {
+	ID              uuid.UUID                  `json:"id"`
+	EnterpriseID    uuid.UUID                  `json:"enterprise_id"`
+	WalletAddress   string                     `json:"wallet_address"`
+	Transaction     xrpl.Transaction           `json:"transaction"`
+	RequiredWeight  uint32                     `json:"required_weight"`
+	CollectedWeight uint32                     `json:"collected_weight"`
+	Signatures      map[uuid.UUID]*xrpl.Signer `json:"signatures"`
+	Status          MultiSignStatus            `json:"status"`
+	Result          *xrpl.TransactionResult    `json:"result,omitempty"`
+	FailureReason   string                     `json:"failure_reason,omitempty"`
+	CreatedAt       time.Time                  `json:"created_at"`
+	SubmittedAt     *time.Time                 `json:"submitted_at,omitempty"`
+}
+
+// QuorumMet reports whether the collected signature weight authorizes the transaction
+func (s *MultiSignSession) QuorumMet() bool {
+	return s.CollectedWeight >= s.RequiredWeight
+}
+
+// MultiSignService configures signer lists on enterprise wallets and gathers approver signatures
+// until a transaction carries the weight its wallet's signer list requires
+type MultiSignService struct {
+	client      MultiSignClient
+	keyProvider xrpl.KeyProvider
+
+	mu          sync.RWMutex
+	signerLists map[string]*SignerListConfig // wallet address -> signer list
+	sessions    map[uuid.UUID]*MultiSignSession
+}
+
+// NewMultiSignService creates a new multi-sign service. keyProvider supplies signing keys for
+// approvers whose signer keys the platform holds and may be nil when approvers sign externally.
+func NewMultiSignService(client MultiSignClient, keyProvider xrpl.KeyProvider) *MultiSignService {
+	return &MultiSignService{
+		client:      client,
+		keyProvider: keyProvider,
+		signerLists: make(map[string]*SignerListConfig),
+		sessions:    make(map[uuid.UUID]*MultiSignSession),
+	}
+}
+
+// ConfigureSignerList sets the signer list of an enterprise wallet on the ledger and records it
+func (s *MultiSignService) ConfigureSignerList(ctx context.Context, enterpriseID uuid.UUID, walletAddress string, quorum uint32, signers []WalletSigner) (*xrpl.TransactionResult, error) {
+	entries := make([]xrpl.SignerEntry, 0, len(signers))
+	approvers := make(map[uuid.UUID]bool, len(signers))
+	for _, signer := range signers {
+		if approvers[signer.ApproverID] {
+			return nil, fmt.Errorf("approver %s listed more than once", signer.ApproverID)
+		}
+		approvers[signer.ApproverID] = true
+		entries = append(entries, xrpl.SignerEntry{Account: signer.Account, SignerWeight: signer.Weight})
+	}
+
+	result, err := s.client.SetSignerList(walletAddress, quorum, entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set signer list on %s: %w", walletAddress, err)
+	}
+
+	s.RegisterSignerList(&SignerListConfig{
+		EnterpriseID:  enterpriseID,
+		WalletAddress: walletAddress,
+		Quorum:        quorum,
+		Signers:       signers,
+		ConfiguredAt:  time.Now(),
+	})
+
+	log.Printf("Configured %d-signer list with quorum %d on %s: %s", len(signers), quorum, walletAddress, result.TransactionID)
+	return result, nil
+}
+
+// RegisterSignerList records a signer list that is already in place on the ledger
+func (s *MultiSignService) RegisterSignerList(config *SignerListConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signerLists[config.WalletAddress] = config
+}
+
+// SelectWallet returns the enterprise's multi-signing wallet whose quorum most closely covers
+// requiredApprovals, so the ledger itself rejects transactions with fewer approvals
+func (s *MultiSignService) SelectWallet(enterpriseID uuid.UUID, requiredApprovals int) (*SignerListConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []*SignerListConfig
+	configured := false
+	for _, config := range s.signerLists {
+		if config.EnterpriseID != enterpriseID {
+			continue
+		}
+		configured = true
+		if requiredApprovals > 0 && config.Quorum >= uint32(requiredApprovals) {
+			candidates = append(candidates, config)
+		}
+	}
+	if !configured {
+		return nil, fmt.Errorf("%w for enterprise %s", ErrSignerListNotConfigured, enterpriseID)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %d approvals for enterprise %s", ErrQuorumUnavailable, requiredApprovals, enterpriseID)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Quorum != candidates[j].Quorum {
+			return candidates[i].Quorum < candidates[j].Quorum
+		}
+		return candidates[i].WalletAddress < candidates[j].WalletAddress
+	})
+	return candidates[0], nil
+}
+
+// BeginSession prepares a transaction from a multi-signing wallet and opens it for signatures
+func (s *MultiSignService) BeginSession(ctx context.Context, sessionID uuid.UUID, tx xrpl.Transaction) (*MultiSignSession, error) {
+	account, _ := tx["Account"].(string)
+
+	s.mu.RLock()
+	config, ok := s.signerLists[account]
+	_, exists := s.sessions[sessionID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSignerListNotConfigured, account)
+	}
+	if exists {
+		return nil, fmt.Errorf("multi-sign session %s already exists", sessionID)
+	}
+
+	// The fee covers every listed signer so any subset meeting the quorum can submit
+	prepared, err := s.client.PrepareMultiSigned(tx, len(config.Signers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare multi-signed transaction: %w", err)
+	}
+
+	session := &MultiSignSession{
+		ID:             sessionID,
+		EnterpriseID:   config.EnterpriseID,
+		WalletAddress:  account,
+		Transaction:    prepared,
+		RequiredWeight: config.Quorum,
+		Signatures:     make(map[uuid.UUID]*xrpl.Signer),
+		Status:         MultiSignStatusCollecting,
+		CreatedAt:      time.Now(),
+	}
+
+	s.mu.Lock()
+	s.sessions[sessionID] = session
+	s.mu.Unlock()
+	return session, nil
+}
+
+// AddSignature records an approver's signature after checking it against the wallet's signer list
+func (s *MultiSignService) AddSignature(ctx context.Context, sessionID, approverID uuid.UUID, signature *xrpl.Signer) (*MultiSignSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, config, err := s.collectingSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := config.signer(approverID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotAWalletSigner, approverID)
+	}
+	if signature.Account != signer.Account {
+		return nil, fmt.Errorf("approver %s signs with %s, not %s", approverID, signer.Account, signature.Account)
+	}
+	if _, signed := session.Signatures[approverID]; signed {
+		return nil, fmt.Errorf("approver %s has already signed", approverID)
+	}
+	if err := xrpl.VerifyMultiSignature(session.Transaction, *signature); err != nil {
+		return nil, fmt.Errorf("invalid signature from approver %s: %w", approverID, err)
+	}
+
+	session.Signatures[approverID] = signature
+	session.CollectedWeight += uint32(signer.Weight)
+	return session, nil
+}
+
+// SignAsApprover signs a session with an approver's platform-held signer key
+func (s *MultiSignService) SignAsApprover(ctx context.Context, sessionID, approverID uuid.UUID) (*MultiSignSession, error) {
+	if s.keyProvider == nil {
+		return nil, fmt.Errorf("cannot sign for approver %s: %w", approverID, xrpl.ErrSigningUnavailable)
+	}
+
+	s.mu.RLock()
+	session, config, err := s.collectingSession(sessionID)
+	var tx xrpl.Transaction
+	if err == nil {
+		tx = session.Transaction
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := config.signer(approverID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotAWalletSigner, approverID)
+	}
+	keyPair, err := s.keyProvider.SigningKey(signer.Account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signer key for approver %s: %w", approverID, err)
+	}
+	signature, err := xrpl.MultiSignTransaction(tx, keyPair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign for approver %s: %w", approverID, err)
+	}
+
+	return s.AddSignature(ctx, sessionID, approverID, signature)
+}
+
+// Submit assembles the collected signatures and submits the transaction once the quorum is met
+func (s *MultiSignService) Submit(ctx context.Context, sessionID uuid.UUID) (*xrpl.TransactionResult, error) {
+	s.mu.Lock()
+	session, _, err := s.collectingSession(sessionID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if !session.QuorumMet() {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: weight %d of %d", ErrQuorumNotMet, session.CollectedWeight, session.RequiredWeight)
+	}
+
+	signers := make([]xrpl.Signer, 0, len(session.Signatures))
+	for _, signature := range session.Signatures {
+		signers = append(signers, *signature)
+	}
+	tx := session.Transaction
+	// Leave collecting before releasing the lock so the transaction is submitted once
+	session.Status = MultiSignStatusSubmitted
+	s.mu.Unlock()
+
+	result, err := s.client.SubmitMultiSigned(tx, signers)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		session.Status = MultiSignStatusFailed
+		session.FailureReason = err.Error()
+		return result, fmt.Errorf("failed to submit multi-signed transaction: %w", err)
+	}
+	session.Result = result
+	session.SubmittedAt = timePtr(time.Now())
+
+	log.Printf("Submitted multi-signed transaction from %s with %d signatures: %s", session.WalletAddress, len(signers), result.TransactionID)
+	return result, nil
+}
+
+// GetSession returns a multi-sign session
+func (s *MultiSignService) GetSession(sessionID uuid.UUID) (*MultiSignSession, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[sessionID]
+	return session, ok
+}
+
+// collectingSession returns a session still collecting signatures and its wallet's signer list.
+// The caller must hold s.mu.
+func (s *MultiSignService) collectingSession(sessionID uuid.UUID) (*MultiSignSession, *SignerListConfig, error) {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, nil, fmt.Errorf("multi-sign session not found: %s", sessionID)
+	}
+	if session.Status != MultiSignStatusCollecting {
+		return nil, nil, fmt.Errorf("%w: %s", ErrMultiSignSessionClosed, session.Status)
+	}
+	return session, s.signerLists[session.WalletAddress], nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// approverKeys holds approver signer keys the way the wallet service would
+type approverKeys map[string]*xrpl.KeyPair
+
+func (k approverKeys) SigningKey(address string) (*xrpl.KeyPair, error) {
+	keyPair, ok := k[address]
+	if !ok {
+		return nil, fmt.Errorf("no key for %s", address)
+	}
+	return keyPair, nil
+}
+
+type permissiveUserService struct{}
+
+func (permissiveUserService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+func (permissiveUserService) GetEnterpriseUsers(ctx context.Context, enterpriseID uuid.UUID) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (permissiveUserService) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	return true, nil
+}
+
+// recordingTreasury records withdrawals booked after approval; other methods are unused
+type recordingTreasury struct {
+	TreasuryServiceInterface
+	withdrawals []*WithdrawalRequest
+}
+
+func (r *recordingTreasury) WithdrawFunds(ctx context.Context, req *WithdrawalRequest) (*models.AssetTransaction, error) {
+	r.withdrawals = append(r.withdrawals, req)
+	return &models.AssetTransaction{ID: uuid.New()}, nil
+}
+
+func newTestKeyPair(t *testing.T) *xrpl.KeyPair {
+	t.Helper()
+	seed, err := xrpl.GenerateSeed(xrpl.KeyTypeSecp256k1)
+	require.NoError(t, err)
+	keyPair, err := xrpl.DeriveKeyPair(seed)
+	require.NoError(t, err)
+	return keyPair
+}
+
+func TestMultiSignedWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	xrplService := NewXRPLService(XRPLConfig{NetworkURL: "https://s.altnet.rippletest.net:51234", TestNet: true})
+	require.NoError(t, xrplService.Initialize())
+
+	enterpriseID := uuid.New()
+	approvers := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	keys := approverKeys{}
+	signers := make([]WalletSigner, len(approvers))
+	signerKeys := make([]*xrpl.KeyPair, len(approvers))
+	for i, approverID := range approvers {
+		signerKeys[i] = newTestKeyPair(t)
+		keys[signerKeys[i].Address()] = signerKeys[i]
+		signers[i] = WalletSigner{ApproverID: approverID, Account: signerKeys[i].Address(), Weight: 1}
+	}
+	// The third approver signs externally
+	delete(keys, signerKeys[2].Address())
+
+	hotWallet, warmWallet := newTestKeyPair(t).Address(), newTestKeyPair(t).Address()
+	multiSign := NewMultiSignService(xrplService, keys)
+	_, err := multiSign.ConfigureSignerList(ctx, enterpriseID, hotWallet, 1, signers[:1])
+	require.NoError(t, err)
+	_, err = multiSign.ConfigureSignerList(ctx, enterpriseID, warmWallet, 2, signers)
+	require.NoError(t, err)
+
+	assetRepo := &TestMockAssetRepository{}
+	assetRepo.On("GetAssetByCurrency", mock.Anything, "XRP").Return(&models.SupportedAsset{CurrencyCode: "XRP", AssetType: models.AssetTypeNative, DecimalPlaces: 6}, nil)
+	balanceRepo := &TestMockBalanceRepository{}
+	balanceRepo.On("GetBalance", mock.Anything, enterpriseID, "XRP").Return(&models.EnterpriseBalance{AvailableBalance: "1000000000"}, nil)
+	treasury := &recordingTreasury{}
+
+	service := NewWithdrawalAuthorizationServiceWithMultiSign(assetRepo, balanceRepo, treasury, permissiveUserService{}, nil, &AuthorizationConfig{
+		LowAmountThreshold:    "1000000",
+		MediumAmountThreshold: "10000000",
+		HighAmountThreshold:   "100000000",
+		LowAmountApprovals:    1,
+		MediumAmountApprovals: 2,
+		HighAmountApprovals:   3,
+		TimeLockThreshold:     "1000000000000",
+		RiskScoreThreshold:    2,
+	}, multiSign)
+
+	destination := newTestKeyPair(t).Address()
+	authorization, err := service.CreateWithdrawalRequest(ctx, &WithdrawalAuthorizationRequest{
+		EnterpriseID:      enterpriseID,
+		InitiatedByUserID: uuid.New(),
+		CurrencyCode:      "XRP",
+		Amount:            "15000000",
+		Destination:       destination,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, authorization.RequiredApprovals)
+	assert.Equal(t, warmWallet, authorization.MultiSignWallet, "the 2-of-3 wallet enforces two approvals on-ledger")
+
+	session, ok := multiSign.GetSession(authorization.ID)
+	require.True(t, ok)
+	assert.Equal(t, "15000000", session.Transaction["Amount"])
+	assert.Equal(t, "40", session.Transaction["Fee"])
+
+	// First approval is signed with the approver's platform-held key
+	_, err = service.ApproveWithdrawal(ctx, &WithdrawalApprovalRequest{RequestID: authorization.ID, ApproverID: approvers[0], ApprovalType: "approve"})
+	require.NoError(t, err)
+	assert.Equal(t, WithdrawalAuthStatusPending, authorization.Status)
+	assert.Equal(t, uint32(1), session.CollectedWeight)
+
+	// Non-signers and forged signatures do not count as approvals
+	_, err = service.ApproveWithdrawal(ctx, &WithdrawalApprovalRequest{RequestID: authorization.ID, ApproverID: uuid.New(), ApprovalType: "approve"})
+	assert.ErrorIs(t, err, ErrNotAWalletSigner)
+	forged, err := xrpl.MultiSignTransaction(session.Transaction, signerKeys[0])
+	require.NoError(t, err)
+	forged.Account = signerKeys[2].Address()
+	_, err = service.ApproveWithdrawal(ctx, &WithdrawalApprovalRequest{RequestID: authorization.ID, ApproverID: approvers[2], ApprovalType: "approve", Signature: forged})
+	assert.Error(t, err)
+	assert.Equal(t, 1, authorization.CurrentApprovals)
+	assert.Empty(t, treasury.withdrawals)
+
+	// The second signature meets the quorum and submits the multi-signed payment
+	signature, err := xrpl.MultiSignTransaction(session.Transaction, signerKeys[2])
+	require.NoError(t, err)
+	_, err = service.ApproveWithdrawal(ctx, &WithdrawalApprovalRequest{RequestID: authorization.ID, ApproverID: approvers[2], ApprovalType: "approve", Signature: signature})
+	require.NoError(t, err)
+
+	assert.Equal(t, WithdrawalAuthStatusProcessing, authorization.Status)
+	assert.Equal(t, MultiSignStatusSubmitted, session.Status)
+	assert.NotEmpty(t, authorization.LedgerTxID)
+	require.Len(t, treasury.withdrawals, 1)
+	assert.Equal(t, AssetTransactionSourceXRPLWallet, treasury.withdrawals[0].Destination)
+
+	// No wallet enforces three approvals, so a high-value withdrawal cannot be created
+	_, err = service.CreateWithdrawalRequest(ctx, &WithdrawalAuthorizationRequest{
+		EnterpriseID:      enterpriseID,
+		InitiatedByUserID: uuid.New(),
+		CurrencyCode:      "XRP",
+		Amount:            "150000000",
+		Destination:       destination,
+	})
+	assert.ErrorIs(t, err, ErrQuorumUnavailable)
+}
+
+func TestMultiSignService_SubmitRequiresQuorum(t *testing.T) {
+	ctx := context.Background()
+	xrplService := NewXRPLService(XRPLConfig{NetworkURL: "https://s.altnet.rippletest.net:51234", TestNet: true})
+	require.NoError(t, xrplService.Initialize())
+
+	signerKey := newTestKeyPair(t)
+	approverID := uuid.New()
+	multiSign := NewMultiSignService(xrplService, approverKeys{signerKey.Address(): signerKey})
+	wallet := newTestKeyPair(t).Address()
+	multiSign.RegisterSignerList(&SignerListConfig{
+		EnterpriseID:  uuid.New(),
+		WalletAddress: wallet,
+		Quorum:        2,
+		Signers:       []WalletSigner{{ApproverID: approverID, Account: signerKey.Address(), Weight: 1}, {ApproverID: uuid.New(), Account: newTestKeyPair(t).Address(), Weight: 1}},
+	})
+
+	payment := xrpl.Transaction{"TransactionType": "Payment", "Account": wallet, "Destination": signerKey.Address(), "Amount": "1000"}
+	sessionID := uuid.New()
+	_, err := multiSign.BeginSession(ctx, sessionID, payment)
+	require.NoError(t, err)
+	_, err = multiSign.BeginSession(ctx, sessionID, payment)
+	assert.Error(t, err)
+
+	_, err = multiSign.SignAsApprover(ctx, sessionID, approverID)
+	require.NoError(t, err)
+	_, err = multiSign.SignAsApprover(ctx, sessionID, approverID)
+	assert.Error(t, err, "an approver signs once")
+
+	_, err = multiSign.Submit(ctx, sessionID)
+	assert.ErrorIs(t, err, ErrQuorumNotMet)
+
+	_, err = multiSign.BeginSession(ctx, uuid.New(), xrpl.Transaction{"TransactionType": "Payment", "Account": signerKey.Address()})
+	assert.ErrorIs(t, err, ErrSignerListNotConfigured)
+}
@@ -558,7 +558,7 @@ func (s *PaymentAuthorizationService) InitiatePaymentFromMilestone(ctx context.C
 		SmartChequeID: smartCheque.ID,
 		MilestoneID:   milestoneID,
 		EnterpriseID:  uuid.New(), // TODO: Extract from contract parties
-		Amount:        fmt.Sprintf("%f", smartCheque.Amount),
+		Amount:        smartCheque.Amount.String(),
 		Currency:      string(smartCheque.Currency),
 		Purpose:       fmt.Sprintf("Milestone completion: %s", milestone.TriggerConditions),
 		Reference:     fmt.Sprintf("Milestone %s for contract %s", milestoneID, contract.ID),
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Payment channel errors
+var (
+	ErrNotChannelMilestone = errors.New("milestone does not pay out through a payment channel")
+	ErrChannelClosed       = errors.New("milestone payment channel is closed")
+)
+
+// MilestoneChannelReleaser releases the share of a payment_channel milestone matching its progress
+type MilestoneChannelReleaser interface {
+	ReleaseProgress(ctx context.Context, milestoneID string, percentageComplete float64) (*models.MilestoneChannel, error)
+}
+
+// PaymentChannelConfig controls how long channels stay open and how often claims are redeemed
+type PaymentChannelConfig struct {
+	// SettleDelay is how long the payee has to redeem its last claim after the payer asks to
+	// close the channel; defaults to 24 hours
+	SettleDelay time.Duration
+	// Lifetime sets the channel's CancelAfter, after which the payer recovers what is unclaimed;
+	// defaults to 90 days
+	Lifetime time.Duration
+	// RedeemInterval is how often the payee redeems its latest claim on the ledger; defaults to 24 hours
+	RedeemInterval time.Duration
+	// ExpiryMargin redeems outstanding claims early when the channel expires within it; defaults to 6 hours
+	ExpiryMargin time.Duration
+}
+
+// PaymentChannelService streams time-and-materials milestones through XRP payment channels. The
+// payer funds one channel per milestone and, as progress is reported, signs off-ledger claims for
+// the matching share of it. The payee verifies each claim and redeems the latest one on the ledger
+// every RedeemInterval, or sooner when the channel is about to expire.
+type PaymentChannelService struct {
+	xrplService     *XRPLService
+	smartChequeRepo repository.SmartChequeRepositoryInterface
+	keys            xrpl.KeyProvider
+	config          PaymentChannelConfig
+
+	// mu serializes updates to channel milestones, which are stored inside their smart cheque
+	mu sync.Mutex
+}
+
+// NewPaymentChannelService creates a payment channel service; keys provides the payers' keys that sign claims
+func NewPaymentChannelService(xrplService *XRPLService, smartChequeRepo repository.SmartChequeRepositoryInterface, keys xrpl.KeyProvider, config PaymentChannelConfig) *PaymentChannelService {
+	if config.SettleDelay <= 0 {
+		config.SettleDelay = 24 * time.Hour
+	}
+	if config.Lifetime <= 0 {
+		config.Lifetime = 90 * 24 * time.Hour
+	}
+	if config.RedeemInterval <= 0 {
+		config.RedeemInterval = 24 * time.Hour
+	}
+	if config.ExpiryMargin <= 0 {
+		config.ExpiryMargin = 6 * time.Hour
+	}
+	return &PaymentChannelService{
+		xrplService:     xrplService,
+		smartChequeRepo: smartChequeRepo,
+		keys:            keys,
+		config:          config,
+	}
+}
+
+// OpenMilestoneChannel funds a channel of xrpAmount from payer to payee for a payment_channel milestone
+func (s *PaymentChannelService) OpenMilestoneChannel(ctx context.Context, milestoneID, payerAccount, payeeAccount string, xrpAmount float64) (*models.MilestoneChannel, error) {
+	if xrpAmount <= 0 {
+		return nil, fmt.Errorf("channel amount must be greater than 0")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cheque, milestone, err := s.channelMilestone(ctx, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+	if milestone.Channel != nil && milestone.Channel.ClosedAt == nil {
+		return nil, fmt.Errorf("milestone %s already has open channel %s", milestoneID, milestone.Channel.ChannelID)
+	}
+
+	payerKey, err := s.keys.SigningKey(payerAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel key of %s: %w", payerAccount, err)
+	}
+	drops := int64(math.Round(xrpAmount * 1000000))
+	cancelAfter := s.xrplService.getLedgerTimeOffset(s.config.Lifetime)
+	result, err := s.xrplService.CreatePaymentChannel(&xrpl.PaymentChannelCreate{
+		Account:     payerAccount,
+		Destination: payeeAccount,
+		Amount:      xrpl.XRPAmount(drops),
+		SettleDelay: uint32(s.config.SettleDelay.Seconds()),
+		PublicKey:   payerKey.PublicKeyHex(),
+		CancelAfter: cancelAfter,
+	})
+	if err != nil {
+		return nil, err
+	}
+	channelID, err := xrpl.PaymentChannelIndex(payerAccount, payeeAccount, result.Sequence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive channel ID: %w", err)
+	}
+
+	expiresAt := ledgerTime(cancelAfter)
+	milestone.Channel = &models.MilestoneChannel{
+		ChannelID:    channelID,
+		PayerAccount: payerAccount,
+		PayeeAccount: payeeAccount,
+		PublicKey:    payerKey.PublicKeyHex(),
+		Amount:       drops,
+		ExpiresAt:    &expiresAt,
+	}
+	if err := s.saveCheque(ctx, cheque); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Opened payment channel %s for milestone %s: %d drops from %s to %s", channelID, milestoneID, drops, payerAccount, payeeAccount)
+	return milestone.Channel, nil
+}
+
+// ReleaseProgress signs a claim for the share of the milestone's channel matching percentageComplete.
+// Claims are cumulative, so progress that does not raise the authorized amount releases nothing.
+func (s *PaymentChannelService) ReleaseProgress(ctx context.Context, milestoneID string, percentageComplete float64) (*models.MilestoneChannel, error) {
+	if percentageComplete < 0 || percentageComplete > 100 {
+		return nil, fmt.Errorf("invalid percentage: %f", percentageComplete)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cheque, milestone, err := s.channelMilestone(ctx, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+	channel := milestone.Channel
+	if channel == nil {
+		return nil, fmt.Errorf("milestone %s has no payment channel open", milestoneID)
+	}
+	if channel.ClosedAt != nil {
+		return nil, ErrChannelClosed
+	}
+
+	authorized := int64(math.Floor(float64(channel.Amount) * percentageComplete / 100))
+	if authorized <= channel.AuthorizedAmount {
+		return channel, nil
+	}
+
+	payerKey, err := s.keys.SigningKey(channel.PayerAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel key of %s: %w", channel.PayerAccount, err)
+	}
+	signature, err := xrpl.SignPaymentChannelClaim(channel.ChannelID, uint64(authorized), payerKey)
+	if err != nil {
+		return nil, err
+	}
+	// The payee only accepts claims it can redeem
+	if err := s.VerifyClaim(channel, authorized, signature); err != nil {
+		return nil, err
+	}
+
+	channel.AuthorizedAmount = authorized
+	channel.ClaimSignature = signature
+	if err := s.saveCheque(ctx, cheque); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// VerifyClaim checks, as the payee, that a claim was signed with the channel's key and that the
+// channel on the ledger holds enough XRP to pay it
+func (s *PaymentChannelService) VerifyClaim(channel *models.MilestoneChannel, amount int64, signature string) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: claim amount must be positive", xrpl.ErrInvalidClaim)
+	}
+	if err := xrpl.VerifyPaymentChannelClaim(channel.ChannelID, uint64(amount), signature, channel.PublicKey); err != nil {
+		return err
+	}
+
+	onLedger, err := s.xrplService.GetPaymentChannel(channel.ChannelID)
+	if err != nil {
+		return err
+	}
+	if onLedger.PublicKeyHex != "" && !strings.EqualFold(onLedger.PublicKeyHex, channel.PublicKey) {
+		return fmt.Errorf("%w: channel %s is keyed to a different public key", xrpl.ErrInvalidClaim, channel.ChannelID)
+	}
+	funded, err := strconv.ParseInt(onLedger.Amount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid channel amount %q: %w", onLedger.Amount, err)
+	}
+	if amount > funded {
+		return fmt.Errorf("%w: claim of %d drops exceeds the %d drops in channel %s", xrpl.ErrInvalidClaim, amount, funded, channel.ChannelID)
+	}
+	return nil
+}
+
+// RedeemDue redeems the latest claim of every open channel milestone that is due, closes channels
+// whose milestone has been paid in full and records channels that closed on the ledger
+func (s *PaymentChannelService) RedeemDue(ctx context.Context) error {
+	statuses := []models.SmartChequeStatus{models.SmartChequeStatusLocked, models.SmartChequeStatusInProgress, models.SmartChequeStatusCompleted}
+	const pageSize = 100
+	for _, status := range statuses {
+		for offset := 0; ; offset += pageSize {
+			cheques, err := s.smartChequeRepo.GetSmartChequesByStatus(ctx, status, pageSize, offset)
+			if err != nil {
+				return fmt.Errorf("failed to list %s smart cheques: %w", status, err)
+			}
+			for _, cheque := range cheques {
+				for i := range cheque.Milestones {
+					channel := cheque.Milestones[i].Channel
+					if channel == nil || channel.ClosedAt != nil {
+						continue
+					}
+					if err := s.redeemMilestone(ctx, cheque.Milestones[i].ID); err != nil {
+						log.Printf("Failed to redeem payment channel %s: %v", channel.ChannelID, err)
+					}
+				}
+			}
+			if len(cheques) < pageSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Start redeems due claims every RedeemInterval until ctx is canceled
+func (s *PaymentChannelService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.RedeemInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.RedeemDue(ctx); err != nil {
+					log.Printf("Error redeeming payment channel claims: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// redeemMilestone brings one milestone's channel up to date with the ledger
+func (s *PaymentChannelService) redeemMilestone(ctx context.Context, milestoneID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cheque, milestone, err := s.channelMilestone(ctx, milestoneID)
+	if err != nil {
+		return err
+	}
+	channel := milestone.Channel
+	if channel == nil || channel.ClosedAt != nil {
+		return nil
+	}
+
+	onLedger, err := s.xrplService.GetPaymentChannel(channel.ChannelID)
+	if errors.Is(err, xrpl.ErrEntryNotFound) {
+		// The channel expired and was closed, returning whatever was not redeemed to the payer
+		now := time.Now()
+		channel.ClosedAt = &now
+		log.Printf("Payment channel %s of milestone %s closed with %d of %d authorized drops redeemed", channel.ChannelID, milestoneID, channel.RedeemedAmount, channel.AuthorizedAmount)
+		return s.saveCheque(ctx, cheque)
+	}
+	if err != nil {
+		return err
+	}
+	if onLedger.Expiration != 0 {
+		expiresAt := ledgerTime(onLedger.Expiration)
+		if channel.ExpiresAt == nil || expiresAt.Before(*channel.ExpiresAt) {
+			channel.ExpiresAt = &expiresAt
+		}
+	}
+
+	now := time.Now()
+	expiring := channel.ExpiresAt != nil && now.Add(s.config.ExpiryMargin).After(*channel.ExpiresAt)
+	if expiring && channel.ExpiresAt.Before(now) {
+		// Past expiry any claim closes the channel, refunding the payer
+		_, err := s.xrplService.ClaimPaymentChannel(&xrpl.PaymentChannelClaim{Account: channel.PayerAccount, Channel: channel.ChannelID})
+		if err != nil {
+			return err
+		}
+		channel.ClosedAt = &now
+		return s.saveCheque(ctx, cheque)
+	}
+
+	due := channel.RedeemedAt == nil || now.Sub(*channel.RedeemedAt) >= s.config.RedeemInterval
+	paidInFull := channel.AuthorizedAmount == channel.Amount
+	if channel.AuthorizedAmount <= channel.RedeemedAmount || !(due || expiring || paidInFull) {
+		return nil
+	}
+
+	balance := xrpl.XRPAmount(channel.AuthorizedAmount)
+	claim := &xrpl.PaymentChannelClaim{
+		Account:   channel.PayeeAccount,
+		Channel:   channel.ChannelID,
+		Balance:   &balance,
+		Signature: channel.ClaimSignature,
+		PublicKey: channel.PublicKey,
+	}
+	// Once the whole channel is redeemed the payee closes it, freeing the payer's reserve
+	if paidInFull {
+		claim.Flags = xrpl.PaymentChannelClaimFlagClose
+	}
+	result, err := s.xrplService.ClaimPaymentChannel(claim)
+	if err != nil {
+		return err
+	}
+
+	channel.RedeemedAmount = channel.AuthorizedAmount
+	channel.RedeemedAt = &now
+	if paidInFull {
+		channel.ClosedAt = &now
+	}
+	log.Printf("Redeemed %d drops from payment channel %s for milestone %s, TxID: %s", channel.RedeemedAmount, channel.ChannelID, milestoneID, result.TransactionID)
+	return s.saveCheque(ctx, cheque)
+}
+
+// channelMilestone loads the smart cheque holding a milestone and the milestone within it
+func (s *PaymentChannelService) channelMilestone(ctx context.Context, milestoneID string) (*models.SmartCheque, *models.Milestone, error) {
+	cheque, err := s.smartChequeRepo.GetSmartChequesByMilestone(ctx, milestoneID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get smart cheque of milestone %s: %w", milestoneID, err)
+	}
+	if cheque == nil {
+		return nil, nil, fmt.Errorf("no smart cheque holds milestone %s", milestoneID)
+	}
+	for i := range cheque.Milestones {
+		milestone := &cheque.Milestones[i]
+		if milestone.ID != milestoneID {
+			continue
+		}
+		if milestone.PaymentMode != models.MilestonePaymentModeChannel {
+			return nil, nil, ErrNotChannelMilestone
+		}
+		return cheque, milestone, nil
+	}
+	return nil, nil, fmt.Errorf("milestone %s not found in smart cheque %s", milestoneID, cheque.ID)
+}
+
+func (s *PaymentChannelService) saveCheque(ctx context.Context, cheque *models.SmartCheque) error {
+	cheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, cheque); err != nil {
+		return fmt.Errorf("failed to update smart cheque %s: %w", cheque.ID, err)
+	}
+	return nil
+}
+
+// ledgerTime converts seconds since the Ripple epoch into a time
+func ledgerTime(seconds uint32) time.Time {
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(seconds) * time.Second)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository/mocks"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+func TestPaymentChannelService_StreamsMilestoneProgress(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	keys := approverKeys{payer.Address(): payer, payee.Address(): payee}
+	xrplService, ledger := newSimulatedXRPLService(t, keys)
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	cheque := &models.SmartCheque{
+		ID:     "cheque-1",
+		Status: models.SmartChequeStatusInProgress,
+		Milestones: []models.Milestone{
+			{ID: "fixed", Amount: models.MustParseMoney("10", models.CurrencyUSDT), VerificationMethod: models.VerificationMethodManual},
+			{ID: "hours", Amount: models.MustParseMoney("40", models.CurrencyUSDT), VerificationMethod: models.VerificationMethodManual, PaymentMode: models.MilestonePaymentModeChannel},
+		},
+	}
+	smartChequeRepo := &mocks.SmartChequeRepositoryInterface{}
+	smartChequeRepo.On("GetSmartChequesByMilestone", mock.Anything, mock.Anything).Return(cheque, nil)
+	smartChequeRepo.On("UpdateSmartCheque", mock.Anything, cheque).Return(nil)
+	smartChequeRepo.On("GetSmartChequesByStatus", mock.Anything, models.SmartChequeStatusInProgress, 100, 0).Return([]*models.SmartCheque{cheque}, nil)
+	smartChequeRepo.On("GetSmartChequesByStatus", mock.Anything, mock.Anything, 100, 0).Return([]*models.SmartCheque{}, nil)
+
+	channels := NewPaymentChannelService(xrplService, smartChequeRepo, keys, PaymentChannelConfig{})
+	ctx := context.Background()
+
+	_, err := channels.OpenMilestoneChannel(ctx, "fixed", payer.Address(), payee.Address(), 10)
+	assert.ErrorIs(t, err, ErrNotChannelMilestone)
+	channel, err := channels.OpenMilestoneChannel(ctx, "hours", payer.Address(), payee.Address(), 40)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	milestoneRepo := &mocks.MilestoneRepositoryInterface{}
+	milestoneRepo.On("GetMilestoneByID", mock.Anything, "hours").Return(&models.ContractMilestone{ID: "hours", Status: "in_progress"}, nil)
+	milestoneRepo.On("UpdateMilestone", mock.Anything, mock.AnythingOfType("*models.ContractMilestone")).Return(nil)
+	progression := NewMilestoneProgressionServiceWithChannels(milestoneRepo, smartChequeRepo, channels)
+
+	require.NoError(t, progression.UpdateMilestoneProgress(ctx, "hours", 25))
+	assert.Equal(t, int64(10000000), channel.AuthorizedAmount)
+	signature := channel.ClaimSignature
+	require.NoError(t, progression.UpdateMilestoneProgress(ctx, "hours", 20))
+	assert.Equal(t, signature, channel.ClaimSignature, "claims never go down")
+
+	forged, err := xrpl.SignPaymentChannelClaim(channel.ChannelID, 40000000, payee)
+	require.NoError(t, err)
+	assert.ErrorIs(t, channels.VerifyClaim(channel, 40000000, forged), xrpl.ErrInvalidClaim)
+	overdrawn, err := xrpl.SignPaymentChannelClaim(channel.ChannelID, 50000000, payer)
+	require.NoError(t, err)
+	assert.ErrorIs(t, channels.VerifyClaim(channel, 50000000, overdrawn), xrpl.ErrInvalidClaim)
+
+	require.NoError(t, channels.RedeemDue(ctx))
+	ledger.CloseLedger()
+	assert.Equal(t, int64(10000000), channel.RedeemedAmount)
+	onLedger, err := xrplService.GetPaymentChannel(channel.ChannelID)
+	require.NoError(t, err)
+	assert.Equal(t, "10000000", onLedger.Balance)
+
+	// Nothing is redeemed again until the redemption interval passes
+	require.NoError(t, progression.UpdateMilestoneProgress(ctx, "hours", 50))
+	require.NoError(t, channels.RedeemDue(ctx))
+	assert.Equal(t, int64(10000000), channel.RedeemedAmount)
+
+	// A fully released channel is redeemed and closed straight away
+	require.NoError(t, progression.UpdateMilestoneProgress(ctx, "hours", 100))
+	require.NoError(t, channels.RedeemDue(ctx))
+	ledger.CloseLedger()
+	assert.NotNil(t, channel.ClosedAt)
+	_, err = xrplService.GetPaymentChannel(channel.ChannelID)
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+
+	balance, _ := ledger.Balance(payee.Address())
+	assert.Greater(t, balance, int64(59999000))
+	assert.LessOrEqual(t, balance, int64(60000000))
+	_, err = channels.ReleaseProgress(ctx, "hours", 100)
+	assert.ErrorIs(t, err, ErrChannelClosed)
+}
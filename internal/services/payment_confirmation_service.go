@@ -11,6 +11,7 @@ import (
 
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 // PaymentConfirmationServiceInterface defines the interface for payment confirmation operations
@@ -107,6 +108,8 @@ type TransactionConfirmation struct {
 	CompletedAt           *time.Time                        `json:"completed_at,omitempty"`
 	Error                 string                            `json:"error,omitempty"`
 	CheckHistory          []*ConfirmationCheck              `json:"check_history"`
+	// Delivery is what a tracked Payment actually delivered, taken from the validated ledger stream
+	Delivery *xrpl.PaymentDelivery `json:"delivery,omitempty"`
 }
 
 // ConfirmationCheck represents a single confirmation check attempt
@@ -141,6 +144,7 @@ type TransactionConfirmationStatus struct {
 	LastCheckedAt         time.Time                         `json:"last_checked_at"`
 	CompletedAt           *time.Time                        `json:"completed_at,omitempty"`
 	Error                 string                            `json:"error,omitempty"`
+	Delivery              *xrpl.PaymentDelivery             `json:"delivery,omitempty"`
 }
 
 // TransactionStatus represents the status of a blockchain transaction
@@ -232,6 +236,7 @@ func (s *PaymentConfirmationService) GetTransactionConfirmationStatus(ctx contex
 		LastCheckedAt:         confirmation.LastCheckedAt,
 		CompletedAt:           confirmation.CompletedAt,
 		Error:                 confirmation.Error,
+		Delivery:              confirmation.Delivery,
 	}, nil
 }
 
@@ -366,6 +371,35 @@ func (s *PaymentConfirmationService) UpdateConfirmationRequirements(ctx context.
 	return nil
 }
 
+// HandleLedgerClosed rechecks active confirmations each time the ledger stream reports a closed
+// ledger, replacing the background ticker when the stream is available
+func (s *PaymentConfirmationService) HandleLedgerClosed(ctx context.Context, event *xrpl.LedgerEvent) {
+	if err := s.checkAllConfirmations(ctx); err != nil {
+		log.Printf("Error checking confirmations at ledger %d: %v", event.LedgerIndex, err)
+	}
+}
+
+// HandleTransaction checks a tracked transaction as soon as the stream reports it validated
+func (s *PaymentConfirmationService) HandleTransaction(ctx context.Context, event *xrpl.TransactionEvent) {
+	if !event.Validated {
+		return
+	}
+
+	s.confirmationMutex.Lock()
+	defer s.confirmationMutex.Unlock()
+
+	confirmation, exists := s.activeConfirmations[event.Hash]
+	if !exists {
+		return
+	}
+	if event.TransactionType == "Payment" {
+		s.recordDelivery(ctx, confirmation, event)
+	}
+	if err := s.checkSingleConfirmation(ctx, confirmation); err != nil {
+		log.Printf("Error checking confirmation for %s: %v", event.Hash, err)
+	}
+}
+
 // Helper methods
 
 func (s *PaymentConfirmationService) monitorConfirmations() {
@@ -469,6 +503,26 @@ func (s *PaymentConfirmationService) recordConfirmationCheck(confirmation *Trans
 	}
 }
 
+// recordDelivery keeps what a tracked payment actually delivered and raises a review event when
+// that is less than its Amount
+func (s *PaymentConfirmationService) recordDelivery(ctx context.Context, confirmation *TransactionConfirmation, event *xrpl.TransactionEvent) {
+	delivery, err := event.Delivery()
+	if err != nil {
+		confirmation.Error = err.Error()
+		log.Printf("Cannot determine what payment %s delivered: %v", event.Hash, err)
+		return
+	}
+	confirmation.Delivery = delivery
+
+	if delivery.PartialPayment || delivery.Short() {
+		log.Printf("Payment %s declared %s but delivered %s", event.Hash, delivery.Amount, delivery.Delivered)
+		s.publishConfirmationEvent(ctx, "payment.confirmation.review_required", confirmation, map[string]interface{}{
+			"declared_amount": delivery.Amount.Value,
+			"partial_payment": delivery.PartialPayment,
+		})
+	}
+}
+
 func (s *PaymentConfirmationService) updateSmartChequeFromConfirmation(ctx context.Context, confirmation *TransactionConfirmation) error {
 	// Get the payment execution to find the SmartCheque
 	_, err := s.paymentExecService.GetPaymentExecutionStatus(ctx, confirmation.PaymentExecutionID)
@@ -497,6 +551,9 @@ func (s *PaymentConfirmationService) publishConfirmationEvent(ctx context.Contex
 		},
 	}
 
+	if confirmation.Delivery != nil {
+		event.Data["delivered_amount"] = confirmation.Delivery.Delivered.Value
+	}
 	for k, v := range additionalData {
 		event.Data[k] = v
 	}
@@ -20,6 +20,7 @@ type PaymentExecutionServiceInterface interface {
 	// Payment Execution
 	ExecutePayment(ctx context.Context, paymentRequestID uuid.UUID) (*PaymentExecutionResult, error)
 	ExecutePaymentFromAuthorization(ctx context.Context, authorizationID uuid.UUID) (*PaymentExecutionResult, error)
+	ReleaseMilestoneEscrow(ctx context.Context, auth *PaymentAuthorization) (*PaymentExecutionResult, error)
 
 	// Batch Payment Execution
 	ExecuteBulkPayments(ctx context.Context, paymentRequestIDs []uuid.UUID) (*BulkPaymentExecutionResult, error)
@@ -39,37 +40,70 @@ type PaymentExecutionServiceInterface interface {
 	// Status Management
 	GetPaymentExecutionStatus(ctx context.Context, executionID uuid.UUID) (*PaymentExecutionStatus, error)
 	UpdatePaymentExecutionStatus(ctx context.Context, executionID uuid.UUID, status PaymentExecutionStatusType, details string) error
+
+	// StateMachine returns the state machine that moves smart checks to the status their released
+	// escrows settle them in
+	StateMachine() *SmartChequeStateMachine
+
+	// SubmissionSettled resolves the milestone escrow of a submitted EscrowFinish once the
+	// LedgerSubmissionConfirmer settles it
+	SubmissionSettled(ctx context.Context, transaction *models.Transaction) error
 }
 
 // PaymentExecutionService implements the payment execution service interface
 type PaymentExecutionService struct {
 	paymentAuthService PaymentAuthorizationServiceInterface
 	smartChequeRepo    repository.SmartChequeRepositoryInterface
-	transactionRepo    repository.TransactionRepositoryInterface
+	transactionRepo    repository.SmartChequeTransactionRepositoryInterface
 	xrplService        repository.XRPLServiceInterface
+	fulfillmentVault   *FulfillmentVault
 	messagingClient    messaging.EventBus
 	executionConfig    *PaymentExecutionConfig
 	activeExecutions   map[uuid.UUID]*PaymentExecution
 	executionMutex     sync.RWMutex
+
+	// settlementService converts released payments into the payee's settlement currency
+	settlementService *CrossCurrencySettlementService
+	// stateMachine follows the ledger; it has no escrow effects since payment execution moves the funds itself
+	stateMachine *SmartChequeStateMachine
 }
 
 // NewPaymentExecutionService creates a new payment execution service instance
 func NewPaymentExecutionService(
 	paymentAuthService PaymentAuthorizationServiceInterface,
 	smartChequeRepo repository.SmartChequeRepositoryInterface,
-	transactionRepo repository.TransactionRepositoryInterface,
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface,
+	xrplService repository.XRPLServiceInterface,
+	fulfillmentVault *FulfillmentVault,
+	messagingClient messaging.EventBus,
+	config *PaymentExecutionConfig,
+) PaymentExecutionServiceInterface {
+	return NewPaymentExecutionServiceWithSettlement(paymentAuthService, smartChequeRepo, transactionRepo, xrplService, fulfillmentVault, messagingClient, config, nil)
+}
+
+// NewPaymentExecutionServiceWithSettlement creates a payment execution service that settles
+// milestone releases in the smart cheque's settlement currency through settlement
+func NewPaymentExecutionServiceWithSettlement(
+	paymentAuthService PaymentAuthorizationServiceInterface,
+	smartChequeRepo repository.SmartChequeRepositoryInterface,
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface,
 	xrplService repository.XRPLServiceInterface,
+	fulfillmentVault *FulfillmentVault,
 	messagingClient messaging.EventBus,
 	config *PaymentExecutionConfig,
+	settlement *CrossCurrencySettlementService,
 ) PaymentExecutionServiceInterface {
 	service := &PaymentExecutionService{
 		paymentAuthService: paymentAuthService,
 		smartChequeRepo:    smartChequeRepo,
 		transactionRepo:    transactionRepo,
 		xrplService:        xrplService,
+		fulfillmentVault:   fulfillmentVault,
 		messagingClient:    messagingClient,
 		executionConfig:    config,
 		activeExecutions:   make(map[uuid.UUID]*PaymentExecution),
+		settlementService:  settlement,
+		stateMachine:       NewSmartChequeStateMachine(nil),
 	}
 
 	// Start background monitoring if enabled
@@ -114,6 +148,9 @@ type PaymentExecution struct {
 	TransactionID    string                     `json:"transaction_id,omitempty"`
 	Fulfillment      *PaymentFulfillment        `json:"fulfillment,omitempty"`
 	Steps            []*PaymentExecutionStep    `json:"steps"`
+	// Settlement describes the conversion into the payee's settlement currency, made once the
+	// release validated
+	Settlement *SettlementResult `json:"settlement,omitempty"`
 }
 
 // PaymentExecutionStep represents a step in the payment execution process
@@ -133,7 +170,7 @@ type PaymentFulfillment struct {
 	SmartChequeID string    `json:"smart_check_id"`
 	MilestoneID   string    `json:"milestone_id"`
 	Condition     string    `json:"condition"`
-	Fulfillment   string    `json:"fulfillment"`
+	Fulfillment   string    `json:"fulfillment,omitempty"`
 	Sequence      uint32    `json:"sequence"`
 	GeneratedAt   time.Time `json:"generated_at"`
 }
@@ -200,25 +237,8 @@ const (
 func (s *PaymentExecutionService) ExecutePayment(ctx context.Context, paymentRequestID uuid.UUID) (*PaymentExecutionResult, error) {
 	log.Printf("Starting payment execution for payment request: %s", paymentRequestID)
 
-	// Create execution record
-	executionID := uuid.New()
-	execution := &PaymentExecution{
-		ID:               executionID,
-		PaymentRequestID: paymentRequestID,
-		Status:           PaymentExecutionStatusPending,
-		StartedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-		Attempts:         0,
-		Steps:            make([]*PaymentExecutionStep, 0),
-	}
-
-	// Store execution
-	s.executionMutex.Lock()
-	s.activeExecutions[executionID] = execution
-	s.executionMutex.Unlock()
-
 	// Execute the payment
-	return s.executePaymentInternal(ctx, execution)
+	return s.executePaymentInternal(ctx, s.startExecution(paymentRequestID))
 }
 
 // ExecutePaymentFromAuthorization executes a payment from an approved authorization
@@ -239,6 +259,37 @@ func (s *PaymentExecutionService) ExecutePaymentFromAuthorization(ctx context.Co
 	return s.ExecutePayment(ctx, authorizationID)
 }
 
+// ReleaseMilestoneEscrow finishes the escrow of a milestone approved for payment with the
+// fulfillment sealed when it was funded. The caller records the submitted finish on the smart
+// cheque; once a validated ledger applies it, the escrow is resolved and the proceeds are routed
+// and settled like any other payment.
+func (s *PaymentExecutionService) ReleaseMilestoneEscrow(ctx context.Context, auth *PaymentAuthorization) (*PaymentExecutionResult, error) {
+	log.Printf("Starting escrow release for smart cheque %s milestone %s", auth.SmartChequeID, auth.MilestoneID)
+
+	execution := s.startExecution(auth.ID)
+	execution.Status = PaymentExecutionStatusProcessing
+	s.addExecutionStep(execution, "validation", "Validating payment authorization", "in_progress")
+	return s.executeAuthorized(ctx, execution, auth)
+}
+
+// startExecution creates and stores the record of a payment execution
+func (s *PaymentExecutionService) startExecution(paymentRequestID uuid.UUID) *PaymentExecution {
+	execution := &PaymentExecution{
+		ID:               uuid.New(),
+		PaymentRequestID: paymentRequestID,
+		Status:           PaymentExecutionStatusPending,
+		StartedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		Attempts:         0,
+		Steps:            make([]*PaymentExecutionStep, 0),
+	}
+
+	s.executionMutex.Lock()
+	s.activeExecutions[execution.ID] = execution
+	s.executionMutex.Unlock()
+	return execution
+}
+
 // executePaymentInternal performs the actual payment execution
 func (s *PaymentExecutionService) executePaymentInternal(ctx context.Context, execution *PaymentExecution) (*PaymentExecutionResult, error) {
 	execution.Status = PaymentExecutionStatusProcessing
@@ -254,6 +305,17 @@ func (s *PaymentExecutionService) executePaymentInternal(ctx context.Context, ex
 		return s.createFailedResult(execution, fmt.Errorf("failed to get payment authorization: %w", err))
 	}
 
+	result, err := s.executeAuthorized(ctx, execution, auth)
+	if err != nil {
+		return result, err
+	}
+
+	s.recordMilestoneEscrowFinish(ctx, auth, result.TransactionID)
+	return result, nil
+}
+
+// executeAuthorized releases the escrowed payment of an approved authorization and pays it out
+func (s *PaymentExecutionService) executeAuthorized(ctx context.Context, execution *PaymentExecution, auth *PaymentAuthorization) (*PaymentExecutionResult, error) {
 	// Validate authorization status
 	if auth.Status != PaymentAuthStatusApproved {
 		s.updateExecutionStep(execution, "validation", "failed", "Payment not approved")
@@ -279,16 +341,17 @@ func (s *PaymentExecutionService) executePaymentInternal(ctx context.Context, ex
 	s.addExecutionStep(execution, "xrpl_transaction", "Executing XRPL escrow finish", "in_progress")
 
 	// Execute XRPL escrow finish
-	transactionResult, err := s.executeXRPLEscrowFinish(ctx, auth, fulfillment)
+	transactionResult, escrow, err := s.executeSealedEscrowFinish(ctx, auth)
 	if err != nil {
 		s.updateExecutionStep(execution, "xrpl_transaction", "failed", err.Error())
 		return s.createFailedResult(execution, fmt.Errorf("failed to execute XRPL transaction: %w", err))
 	}
 
 	execution.TransactionID = transactionResult.TransactionID
+	s.recordEscrowFinish(auth, escrow, transactionResult)
 	s.updateExecutionStep(execution, "xrpl_transaction", "completed", fmt.Sprintf("Transaction submitted: %s", transactionResult.TransactionID))
 
-	// Add confirmation step
+	// Add confirmation step; the proceeds are routed and settled by SubmissionSettled once the finish validates
 	s.addExecutionStep(execution, "confirmation", "Waiting for blockchain confirmation", "in_progress")
 
 	// Update execution status
@@ -305,7 +368,7 @@ func (s *PaymentExecutionService) executePaymentInternal(ctx context.Context, ex
 		Currency:         auth.Currency,
 		ExecutedAt:       time.Now(),
 		Confirmations:    0,
-		Fee:              "0.00001", // Placeholder fee
+		Fee:              transactionFee(transactionResult),
 		Steps:            execution.Steps,
 	}
 
@@ -315,36 +378,235 @@ func (s *PaymentExecutionService) executePaymentInternal(ctx context.Context, ex
 	return result, nil
 }
 
-// executeXRPLEscrowFinish executes the XRPL escrow finish transaction
-func (s *PaymentExecutionService) executeXRPLEscrowFinish(ctx context.Context, auth *PaymentAuthorization, fulfillment *PaymentFulfillment) (*xrpl.TransactionResult, error) {
-	// Get the SmartCheque to find escrow details
-	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, auth.SmartChequeID)
+// transactionFee renders the XRP cost a submitted transaction was signed with, empty when the
+// submission did not report one
+func transactionFee(result *xrpl.TransactionResult) string {
+	fee, err := platformAmount(xrpl.Amount{Value: result.Fee}, models.CurrencyXRP)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get smart check: %w", err)
+		return ""
 	}
+	return fee.String()
+}
 
-	if smartCheque.EscrowAddress == "" {
-		return nil, fmt.Errorf("smart check has no escrow address")
+// executeSealedEscrowFinish verifies the milestone, releases its sealed fulfillment from the
+// vault and finishes the escrow recorded at creation time, returning the escrow it finished.
+// The fulfillment never leaves this call.
+func (s *PaymentExecutionService) executeSealedEscrowFinish(ctx context.Context, auth *PaymentAuthorization) (*xrpl.TransactionResult, *models.EscrowFulfillment, error) {
+	if err := s.ValidatePaymentCondition(ctx, auth.SmartChequeID, auth.MilestoneID, "", ""); err != nil {
+		return nil, nil, fmt.Errorf("payment verification failed: %w", err)
 	}
 
-	// Use the escrow address as both payee and owner for the escrow finish
-	// In a real implementation, these would be determined from the escrow creation
-	payeeAddress := smartCheque.EscrowAddress
-	ownerAddress := smartCheque.EscrowAddress
+	escrow, fulfillment, err := s.fulfillmentVault.releaseForEscrowFinish(ctx, auth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to release escrow fulfillment: %w", err)
+	}
 
-	// Execute the escrow finish
 	result, err := s.xrplService.CompleteSmartChequeMilestone(
-		payeeAddress,
-		ownerAddress,
-		fulfillment.Sequence,
-		fulfillment.Condition,
-		fulfillment.Fulfillment,
+		escrow.EscrowDestination,
+		escrow.EscrowOwner,
+		escrow.OfferSequence,
+		escrow.Condition,
+		fulfillment,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("XRPL escrow finish failed: %w", err)
+		return nil, nil, fmt.Errorf("XRPL escrow finish failed: %w", err)
 	}
 
-	return result, nil
+	return result, escrow, nil
+}
+
+// recordEscrowFinish records the submitted finish of a milestone escrow; it is confirmed once a
+// validated ledger applies it
+func (s *PaymentExecutionService) recordEscrowFinish(auth *PaymentAuthorization, escrow *models.EscrowFulfillment, result *xrpl.TransactionResult) {
+	transaction := models.NewTransaction(
+		models.TransactionTypeEscrowFinish,
+		escrow.EscrowOwner,
+		escrow.EscrowDestination,
+		auth.Amount,
+		auth.Currency,
+		auth.EnterpriseID.String(),
+		auth.InitiatedByUserID.String(),
+	)
+	transaction.SmartChequeID = &auth.SmartChequeID
+	transaction.MilestoneID = &auth.MilestoneID
+	recordEscrowResolution(transaction, result)
+
+	if err := s.transactionRepo.CreateTransaction(transaction); err != nil {
+		log.Printf("Failed to record escrow finish of smart check %s milestone %s: %v", auth.SmartChequeID, auth.MilestoneID, err)
+	}
+}
+
+// recordMilestoneEscrowFinish records the finish submitted for a released milestone on its escrow,
+// which stays active until the finish validates
+func (s *PaymentExecutionService) recordMilestoneEscrowFinish(ctx context.Context, auth *PaymentAuthorization, transactionID string) {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, auth.SmartChequeID)
+	if err != nil || smartCheque == nil {
+		log.Printf("Failed to record release of smart check %s milestone %s: %v", auth.SmartChequeID, auth.MilestoneID, err)
+		return
+	}
+	if !smartCheque.SubmitMilestoneEscrowResolution(auth.MilestoneID, transactionID) {
+		return
+	}
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		log.Printf("Failed to record release of smart check %s milestone %s: %v", auth.SmartChequeID, auth.MilestoneID, err)
+	}
+}
+
+// SubmissionSettled marks a milestone escrow finished once a validated ledger applied its
+// EscrowFinish and derives the smart cheque status from its milestone escrows; the other
+// milestones stay locked. The released proceeds are then routed to their holder and settled. A
+// finish that failed or expired leaves the escrow active and fails the execution that submitted it.
+func (s *PaymentExecutionService) SubmissionSettled(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type != models.TransactionTypeEscrowFinish {
+		return nil
+	}
+	smartCheque, resolved, err := settleEscrowResolution(ctx, s.smartChequeRepo, transaction, models.MilestoneEscrowStatusFinished)
+	if err != nil || smartCheque == nil {
+		return err
+	}
+	milestoneID := *transaction.MilestoneID
+
+	// The escrow is finished on the ledger whether or not the state machine follows it
+	if status, ok := smartCheque.StatusFromMilestoneEscrows(); ok && resolved {
+		if err := s.stateMachine.Transition(ctx, smartCheque, status, "milestone escrow finished", nil); err != nil {
+			log.Printf("Smart check %s stays %s after its milestone %s escrow was finished: %v", smartCheque.ID, smartCheque.Status, milestoneID, err)
+		}
+	}
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to record release of smart check %s milestone %s: %w", smartCheque.ID, milestoneID, err)
+	}
+
+	// The escrow is resolved once saved, so the proceeds are paid out only once
+	execution := s.confirmingExecution(transaction.TransactionHash)
+	if resolved {
+		// Proceeds endorsed after the escrow was funded are paid on to their holder, who is settled instead
+		payee := s.routeToHolder(ctx, execution, smartCheque, transaction, transaction.ToAddress)
+
+		// The payee already holds the released funds, so a failed conversion leaves them in the cheque currency
+		settlement := s.settleInPreferredCurrency(ctx, execution, smartCheque, transaction, payee)
+		if execution != nil {
+			execution.Settlement = settlement
+		}
+	}
+	s.settleExecution(ctx, execution, transaction)
+	return nil
+}
+
+// confirmingExecution returns the tracked execution waiting for the transaction to validate, or
+// nil when none is
+func (s *PaymentExecutionService) confirmingExecution(transactionID string) *PaymentExecution {
+	s.executionMutex.RLock()
+	defer s.executionMutex.RUnlock()
+	for _, execution := range s.activeExecutions {
+		if execution.TransactionID == transactionID && execution.Status == PaymentExecutionStatusConfirming {
+			return execution
+		}
+	}
+	return nil
+}
+
+// settleExecution completes or fails the execution that submitted a settled transaction, when it
+// is still tracked
+func (s *PaymentExecutionService) settleExecution(ctx context.Context, execution *PaymentExecution, transaction *models.Transaction) {
+	if execution == nil {
+		return
+	}
+	s.executionMutex.Lock()
+	eventType := "payment.execution.completed"
+	if transaction.Status == models.TransactionStatusConfirmed {
+		execution.Status = PaymentExecutionStatusCompleted
+		s.updateExecutionStep(execution, "confirmation", "completed", "")
+	} else {
+		eventType = "payment.execution.failed"
+		execution.Status = PaymentExecutionStatusFailed
+		execution.LastError = transaction.LastError
+		s.updateExecutionStep(execution, "confirmation", "failed", transaction.LastError)
+	}
+	execution.UpdatedAt = time.Now()
+	s.executionMutex.Unlock()
+
+	s.publishPaymentExecutionEvent(ctx, eventType, execution, nil)
+}
+
+// routeToHolder forwards a milestone payment released to its escrow's destination on to the
+// enterprise the milestone was endorsed to, returning the account that now holds the funds. A failed
+// forward leaves them with the escrow's destination and is recorded for an operator to retry.
+func (s *PaymentExecutionService) routeToHolder(ctx context.Context, execution *PaymentExecution, smartCheque *models.SmartCheque, release *models.Transaction, releasedTo string) string {
+	milestoneID := *release.MilestoneID
+	milestone := smartCheque.FindMilestone(milestoneID)
+	if milestone == nil || milestone.Holder == nil || milestone.Escrow == nil || milestone.Escrow.Destination != releasedTo {
+		return releasedTo
+	}
+
+	s.addExecutionStep(execution, "endorsement_routing", fmt.Sprintf("Routing proceeds to holder %s", milestone.Holder.EnterpriseID), "in_progress")
+	holder, forward, err := forwardEndorsedProceeds(s.xrplService, smartCheque, milestone, releasedTo)
+	if forward != nil {
+		if recordErr := s.transactionRepo.CreateTransaction(forward); recordErr != nil {
+			log.Printf("Failed to record forward of smart check %s milestone %s: %v", smartCheque.ID, milestoneID, recordErr)
+		}
+	}
+	switch {
+	case err != nil:
+		log.Printf("Endorsement routing of smart check %s milestone %s failed: %v", smartCheque.ID, milestoneID, err)
+		s.updateExecutionStep(execution, "endorsement_routing", "failed", err.Error())
+	case forward == nil:
+		s.updateExecutionStep(execution, "endorsement_routing", "completed", "Escrow released to the holder")
+	default:
+		s.updateExecutionStep(execution, "endorsement_routing", "completed", fmt.Sprintf("Forwarded to the holder: %s", forward.TransactionHash))
+	}
+	return holder
+}
+
+// settleInPreferredCurrency converts a released milestone payment into the smart cheque's
+// settlement currency, recording the conversion as a payout transaction. It returns nil when no
+// conversion is configured or the conversion failed.
+func (s *PaymentExecutionService) settleInPreferredCurrency(ctx context.Context, execution *PaymentExecution, smartCheque *models.SmartCheque, release *models.Transaction, payee string) *SettlementResult {
+	if s.settlementService == nil {
+		return nil
+	}
+	settlementCurrency := string(smartCheque.SettlementCurrency)
+	if settlementCurrency == "" || settlementCurrency == release.Currency {
+		return nil
+	}
+	milestoneID := *release.MilestoneID
+
+	s.addExecutionStep(execution, "settlement", fmt.Sprintf("Converting %s %s into %s", release.Amount, release.Currency, settlementCurrency), "in_progress")
+
+	amount, err := models.ParseMoney(release.Amount, models.Currency(release.Currency))
+	if err != nil {
+		s.updateExecutionStep(execution, "settlement", "failed", fmt.Sprintf("invalid amount %q", release.Amount))
+		return nil
+	}
+
+	payout := models.NewTransaction(models.TransactionTypePayment, payee, payee, release.Amount, release.Currency, release.EnterpriseID, release.UserID)
+	payout.SmartChequeID = &smartCheque.ID
+	payout.MilestoneID = &milestoneID
+	payout.Metadata["release_transaction_id"] = release.TransactionHash
+	if err := s.transactionRepo.CreateTransaction(payout); err != nil {
+		s.updateExecutionStep(execution, "settlement", "failed", err.Error())
+		return nil
+	}
+
+	validated := &xrpl.TransactionResult{TransactionID: release.TransactionHash}
+	if release.LastLedgerSequence != nil {
+		validated.LastLedgerSequence = *release.LastLedgerSequence
+	}
+	settlement, err := s.settlementService.SettleRelease(ctx, validated, payee, amount, settlementCurrency, payout)
+	if err != nil {
+		log.Printf("Settlement of smart check %s milestone %s failed: %v", smartCheque.ID, milestoneID, err)
+		payout.Status = models.TransactionStatusFailed
+		payout.LastError = err.Error()
+		if updateErr := s.transactionRepo.UpdateTransaction(payout); updateErr != nil {
+			log.Printf("Failed to record failed settlement %s: %v", payout.ID, updateErr)
+		}
+		s.updateExecutionStep(execution, "settlement", "failed", err.Error())
+		return nil
+	}
+
+	s.updateExecutionStep(execution, "settlement", "completed", fmt.Sprintf("Delivered %s %s at %s", settlement.DeliveredAmount, settlementCurrency, settlement.ExecutedRate))
+	return settlement
 }
 
 // GeneratePaymentFulfillment generates the condition and fulfillment for payment release
@@ -355,26 +617,18 @@ func (s *PaymentExecutionService) GeneratePaymentFulfillment(ctx context.Context
 		return nil, fmt.Errorf("failed to get smart check: %w", err)
 	}
 
-	// Generate condition and fulfillment based on milestone verification
-	// This is a simplified implementation - in production, this would use
-	// cryptographic proofs and milestone verification data
-	secret := fmt.Sprintf("payment_%s_%s_%d", smartChequeID, milestoneID, time.Now().Unix())
-	condition, fulfillment, err := s.xrplService.GenerateCondition(secret)
+	// Sealed fulfillments stay in the vault; only the condition and escrow sequence are returned
+	sealed, err := s.fulfillmentVault.Lookup(ctx, smartChequeID, milestoneID, FulfillmentActorPaymentExecution)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate condition: %w", err)
+		return nil, fmt.Errorf("failed to look up sealed fulfillment: %w", err)
 	}
 
-	// Use sequence number 1 as default - in production this would be retrieved
-	// from the original escrow creation transaction
-	sequence := uint32(1)
-
 	return &PaymentFulfillment{
 		SmartChequeID: smartChequeID,
 		MilestoneID:   milestoneID,
-		Condition:     condition,
-		Fulfillment:   fulfillment,
-		Sequence:      sequence,
-		GeneratedAt:   time.Now(),
+		Condition:     sealed.Condition,
+		Sequence:      sealed.OfferSequence,
+		GeneratedAt:   sealed.CreatedAt,
 	}, nil
 }
 
@@ -404,11 +658,12 @@ func (s *PaymentExecutionService) ValidatePaymentCondition(ctx context.Context,
 		return fmt.Errorf("milestone is not verified (status: %s)", milestone.Status)
 	}
 
-	// Additional validation could include:
-	// - Checking condition format
-	// - Verifying fulfillment matches condition
-	// - Validating cryptographic proofs
-	// - Checking authorization status
+	// Verify the fulfillment satisfies the escrow's PREIMAGE-SHA-256 condition
+	if fulfillment != "" {
+		if err := xrpl.ValidateFulfillment(condition, fulfillment); err != nil {
+			return fmt.Errorf("payment condition validation failed: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -561,6 +816,12 @@ func (s *PaymentExecutionService) CancelPaymentExecution(ctx context.Context, ex
 	return nil
 }
 
+// StateMachine returns the state machine that moves smart checks to the status their released
+// escrows settle them in
+func (s *PaymentExecutionService) StateMachine() *SmartChequeStateMachine {
+	return s.stateMachine
+}
+
 // GetPaymentExecutionStatus gets the current status of a payment execution
 func (s *PaymentExecutionService) GetPaymentExecutionStatus(ctx context.Context, executionID uuid.UUID) (*PaymentExecutionStatus, error) {
 	return s.MonitorPaymentExecution(ctx, executionID)
@@ -589,6 +850,10 @@ func (s *PaymentExecutionService) UpdatePaymentExecutionStatus(ctx context.Conte
 // Helper methods
 
 func (s *PaymentExecutionService) addExecutionStep(execution *PaymentExecution, stepType, description, status string) {
+	// Ledger outcomes settled after a restart have no execution to record steps on
+	if execution == nil {
+		return
+	}
 	step := &PaymentExecutionStep{
 		ID:          uuid.New(),
 		StepType:    stepType,
@@ -602,6 +867,9 @@ func (s *PaymentExecutionService) addExecutionStep(execution *PaymentExecution,
 }
 
 func (s *PaymentExecutionService) updateExecutionStep(execution *PaymentExecution, stepType, status, errorMsg string) {
+	if execution == nil {
+		return
+	}
 	for _, step := range execution.Steps {
 		if step.StepType == stepType {
 			step.Status = status
@@ -647,17 +915,8 @@ func (s *PaymentExecutionService) checkExecutionStatuses() {
 	s.executionMutex.Lock()
 	defer s.executionMutex.Unlock()
 
+	// Confirming executions are completed or failed by SubmissionSettled once their finish validates
 	for executionID, execution := range s.activeExecutions {
-		if execution.Status == PaymentExecutionStatusConfirming {
-			// Check if transaction is confirmed
-			// TODO: Implement blockchain confirmation checking
-			if time.Since(execution.UpdatedAt) > s.executionConfig.ConfirmationTimeout {
-				execution.Status = PaymentExecutionStatusCompleted
-				execution.UpdatedAt = time.Now()
-				log.Printf("Payment execution completed: %s", executionID)
-			}
-		}
-
 		// Clean up old executions
 		if time.Since(execution.UpdatedAt) > 24*time.Hour {
 			delete(s.activeExecutions, executionID)
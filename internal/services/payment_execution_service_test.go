@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
+)
+
+// memoryPaymentAuthorizations serves payment authorizations from memory
+type memoryPaymentAuthorizations struct {
+	PaymentAuthorizationServiceInterface
+	auths map[uuid.UUID]*PaymentAuthorization
+}
+
+func (a *memoryPaymentAuthorizations) GetPaymentAuthorizationRequest(ctx context.Context, requestID uuid.UUID) (*PaymentAuthorization, error) {
+	auth, ok := a.auths[requestID]
+	if !ok {
+		return nil, fmt.Errorf("payment authorization not found: %s", requestID)
+	}
+	return auth, nil
+}
+
+// sealedEscrowFixture is a smart cheque whose milestone escrow is funded on a simulated ledger,
+// with the escrow's fulfillment sealed in the vault
+type sealedEscrowFixture struct {
+	ledger          *simulator.Ledger
+	xrplService     *XRPLService
+	vault           *FulfillmentVault
+	vaultRepo       *inMemoryFulfillmentVaultRepository
+	smartChequeRepo *mockSmartChequeRepoXRPL
+	transactionRepo *mockTransactionRepoXRPL
+	eventBus        *TestMockEventBus
+	payer, payee    *xrpl.KeyPair
+	smartCheque     *models.SmartCheque
+}
+
+func newSealedEscrowFixture(t *testing.T, keys approverKeys) *sealedEscrowFixture {
+	t.Helper()
+	f := &sealedEscrowFixture{payer: newTestKeyPair(t), payee: newTestKeyPair(t)}
+	keys[f.payer.Address()] = f.payer
+	keys[f.payee.Address()] = f.payee
+	f.xrplService, f.ledger = newSimulatedXRPLService(t, keys)
+	require.NoError(t, f.ledger.Fund(f.payer.Address(), 100000000))
+	require.NoError(t, f.ledger.Fund(f.payee.Address(), 20000000))
+	f.vault, f.vaultRepo = newTestFulfillmentVault(t)
+
+	f.smartCheque = &models.SmartCheque{
+		ID:       uuid.New().String(),
+		PayerID:  uuid.New().String(),
+		PayeeID:  uuid.New().String(),
+		Amount:   models.MustParseMoney("10", models.CurrencyXRP),
+		Currency: models.CurrencyXRP,
+		Status:   models.SmartChequeStatusLocked,
+		Milestones: []models.Milestone{
+			{
+				ID:                 uuid.New().String(),
+				Amount:             models.MustParseMoney("10", models.CurrencyXRP),
+				VerificationMethod: models.VerificationMethodManual,
+				Status:             models.MilestoneStatusPending,
+			},
+		},
+	}
+	fundings, err := f.xrplService.CreateSmartChequeEscrowWithMilestones(f.payer.Address(), f.payee.Address(), f.smartCheque.Amount, f.smartCheque.Milestones)
+	require.NoError(t, err)
+	require.Len(t, fundings, 1)
+	escrow := fundings[0].Escrow
+	reference := EscrowReference{Owner: escrow.Owner, Destination: escrow.Destination, OfferSequence: escrow.OfferSequence}
+	require.NoError(t, f.vault.Seal(context.Background(), f.smartCheque.ID, fundings[0].MilestoneID, reference, escrow.Condition, fundings[0].Fulfillment, FulfillmentActorEscrowCreation))
+	f.smartCheque.EscrowAddress = escrow.Owner
+	f.smartCheque.Milestones[0].Escrow = &escrow
+	f.ledger.CloseLedger()
+	f.ledger.AdvanceTime(2 * time.Hour)
+	f.ledger.CloseLedger()
+
+	f.smartChequeRepo = &mockSmartChequeRepoXRPL{}
+	f.smartChequeRepo.On("GetSmartChequeByID", mock.Anything, f.smartCheque.ID).Return(f.smartCheque, nil)
+	f.smartChequeRepo.On("UpdateSmartCheque", mock.Anything, f.smartCheque).Return(nil).Maybe()
+	f.transactionRepo = &mockTransactionRepoXRPL{}
+	f.transactionRepo.On("CreateTransaction", mock.Anything).Return(nil).Maybe()
+	f.eventBus = &TestMockEventBus{}
+	f.eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+	return f
+}
+
+func (f *sealedEscrowFixture) milestone() *models.Milestone {
+	return &f.smartCheque.Milestones[0]
+}
+
+// recorded lists the transactions the fixture's payment execution recorded, of a type
+func (f *sealedEscrowFixture) recorded(transactionType models.TransactionType) []*models.Transaction {
+	var transactions []*models.Transaction
+	for _, call := range f.transactionRepo.Calls {
+		if call.Method != "CreateTransaction" {
+			continue
+		}
+		if transaction := call.Arguments.Get(0).(*models.Transaction); transaction.Type == transactionType {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions
+}
+
+// authorization is an authorization to pay the fixture's milestone
+func (f *sealedEscrowFixture) authorization(status PaymentAuthStatus) *PaymentAuthorization {
+	milestone := f.milestone()
+	return &PaymentAuthorization{
+		ID:            uuid.New(),
+		SmartChequeID: f.smartCheque.ID,
+		MilestoneID:   milestone.ID,
+		Amount:        milestone.Amount.String(),
+		Currency:      string(f.smartCheque.Currency),
+		Status:        status,
+	}
+}
+
+func TestPaymentExecutionService_ExecutePaymentFinishesSealedEscrow(t *testing.T) {
+	f := newSealedEscrowFixture(t, approverKeys{})
+	ctx := context.Background()
+	auth := f.authorization(PaymentAuthStatusApproved)
+	authorizations := &memoryPaymentAuthorizations{auths: map[uuid.UUID]*PaymentAuthorization{auth.ID: auth}}
+	service := NewPaymentExecutionService(authorizations, f.smartChequeRepo, f.transactionRepo, f.xrplService, f.vault, f.eventBus, &PaymentExecutionConfig{})
+	history := &memoryTransitionHistory{}
+	service.StateMachine().SetHistory(history)
+
+	// The fulfillment is only released for a verified milestone
+	_, err := service.ExecutePayment(ctx, auth.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "milestone is not verified")
+
+	f.milestone().Status = models.MilestoneStatusVerified
+	result, err := service.ExecutePayment(ctx, auth.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.TransactionID)
+	// The result reports the cost the finish was signed with, which its fulfillment raises above 10 drops
+	assert.Equal(t, "0.000350", result.Fee)
+
+	// The finish is recorded as submitted and the escrow waits on it until a validated ledger applies it
+	finishes := f.recorded(models.TransactionTypeEscrowFinish)
+	require.Len(t, finishes, 1)
+	assert.Equal(t, result.TransactionID, finishes[0].TransactionHash)
+	assert.Equal(t, models.TransactionStatusSubmitted, finishes[0].Status)
+	assert.Nil(t, finishes[0].ConfirmedAt)
+	escrow := f.milestone().Escrow
+	assert.Equal(t, models.MilestoneEscrowStatusActive, escrow.Status)
+	assert.Equal(t, result.TransactionID, escrow.PendingTransaction)
+	assert.Equal(t, models.SmartChequeStatusLocked, f.smartCheque.Status)
+	assert.Empty(t, history.transitions)
+
+	// The escrow was finished with the sealed fulfillment and the payee holds its funds
+	assert.Equal(t, 1, confirmRecorded(t, f.ledger, f.xrplService, f.transactionRepo, service))
+	balance, _ := f.ledger.Balance(f.payee.Address())
+	assert.Greater(t, balance, int64(29999000))
+	_, err = f.xrplService.GetEscrowStatus(escrow.Owner, fmt.Sprint(escrow.OfferSequence))
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+	assert.Equal(t, models.MilestoneEscrowStatusFinished, escrow.Status)
+	assert.Equal(t, result.TransactionID, escrow.ResolvedBy)
+	assert.Empty(t, escrow.PendingTransaction)
+	assert.Equal(t, models.TransactionStatusConfirmed, finishes[0].Status)
+	assert.Equal(t, models.SmartChequeStatusCompleted, f.smartCheque.Status)
+	require.Len(t, history.transitions, 1)
+	assert.Equal(t, models.SmartChequeStatusLocked, history.transitions[0].FromStatus)
+	assert.Equal(t, models.SmartChequeStatusCompleted, history.transitions[0].ToStatus)
+	status, err := service.MonitorPaymentExecution(ctx, result.ExecutionID)
+	require.NoError(t, err)
+	assert.Equal(t, PaymentExecutionStatusCompleted, status.Status)
+
+	// The release is counted and logged against payment execution
+	assert.Equal(t, 1, f.vaultRepo.records[f.smartCheque.ID+"/"+f.milestone().ID].ReleaseCount)
+	logs, err := f.vault.GetAccessLog(ctx, f.smartCheque.ID, f.milestone().ID, 10, 0)
+	require.NoError(t, err)
+	var releases []*models.FulfillmentAccessLog
+	for _, entry := range logs {
+		if entry.Action == models.FulfillmentAccessRelease {
+			releases = append(releases, entry)
+		}
+	}
+	require.Len(t, releases, 1)
+	assert.Equal(t, FulfillmentActorPaymentExecution, releases[0].Actor)
+	assert.True(t, releases[0].Success)
+}
+
+func TestPaymentExecutionService_ExpiredFinishLeavesEscrowActive(t *testing.T) {
+	f := newSealedEscrowFixture(t, approverKeys{})
+	ctx := context.Background()
+	auth := f.authorization(PaymentAuthStatusApproved)
+	authorizations := &memoryPaymentAuthorizations{auths: map[uuid.UUID]*PaymentAuthorization{auth.ID: auth}}
+	service := NewPaymentExecutionService(authorizations, f.smartChequeRepo, f.transactionRepo, f.xrplService, f.vault, f.eventBus, &PaymentExecutionConfig{})
+	f.milestone().Status = models.MilestoneStatusVerified
+
+	// The server acknowledged the finish but it never reached a ledger
+	f.ledger.DropNextSubmit()
+	result, err := service.ExecutePayment(ctx, auth.ID)
+	require.NoError(t, err)
+	finishes := f.recorded(models.TransactionTypeEscrowFinish)
+	require.Len(t, finishes, 1)
+	for f.ledger.LedgerIndex() <= *finishes[0].LastLedgerSequence {
+		f.ledger.CloseLedger()
+	}
+
+	assert.Equal(t, 1, confirmRecorded(t, f.ledger, f.xrplService, f.transactionRepo, service))
+	assert.Equal(t, models.TransactionStatusExpired, finishes[0].Status)
+	escrow := f.milestone().Escrow
+	assert.Equal(t, models.MilestoneEscrowStatusActive, escrow.Status)
+	assert.Empty(t, escrow.PendingTransaction)
+	assert.NotEmpty(t, escrow.LastError)
+	assert.Equal(t, models.SmartChequeStatusLocked, f.smartCheque.Status)
+	status, err := service.MonitorPaymentExecution(ctx, result.ExecutionID)
+	require.NoError(t, err)
+	assert.Equal(t, PaymentExecutionStatusFailed, status.Status)
+
+	// The escrow still holds the funds, so the release can be retried
+	retried, err := service.ExecutePayment(ctx, auth.ID)
+	require.NoError(t, err)
+	confirmRecorded(t, f.ledger, f.xrplService, f.transactionRepo, service)
+	assert.Equal(t, models.MilestoneEscrowStatusFinished, escrow.Status)
+	assert.Equal(t, retried.TransactionID, escrow.ResolvedBy)
+	assert.Equal(t, models.SmartChequeStatusCompleted, f.smartCheque.Status)
+}
+
+func TestPaymentExecutionService_ReleaseMilestoneEscrowRequiresApproval(t *testing.T) {
+	f := newSealedEscrowFixture(t, approverKeys{})
+	ctx := context.Background()
+	service := NewPaymentExecutionService(nil, f.smartChequeRepo, f.transactionRepo, f.xrplService, f.vault, f.eventBus, &PaymentExecutionConfig{})
+	f.milestone().Status = models.MilestoneStatusVerified
+
+	// A pending authorization does not release the fulfillment
+	_, err := service.ReleaseMilestoneEscrow(ctx, f.authorization(PaymentAuthStatusPending))
+	require.Error(t, err)
+	assert.Equal(t, 0, f.vaultRepo.records[f.smartCheque.ID+"/"+f.milestone().ID].ReleaseCount)
+
+	result, err := service.ReleaseMilestoneEscrow(ctx, f.authorization(PaymentAuthStatusApproved))
+	require.NoError(t, err)
+	f.ledger.CloseLedger()
+	assert.NotEmpty(t, result.TransactionID)
+	assert.Equal(t, 1, f.vaultRepo.records[f.smartCheque.ID+"/"+f.milestone().ID].ReleaseCount)
+
+	// The caller records the finished escrow; the release itself leaves the smart cheque alone
+	assert.Equal(t, models.MilestoneEscrowStatusActive, f.milestone().Escrow.Status)
+	balance, _ := f.ledger.Balance(f.payee.Address())
+	assert.Greater(t, balance, int64(29999000))
+}
+
+func TestPaymentExecutionService_ExecutePaymentRoutesProceedsToHolder(t *testing.T) {
+	holder := newTestKeyPair(t)
+	f := newSealedEscrowFixture(t, approverKeys{holder.Address(): holder})
+	require.NoError(t, f.ledger.Fund(holder.Address(), 20000000))
+	ctx := context.Background()
+
+	// The milestone was endorsed after its escrow was funded to the payee
+	f.milestone().Status = models.MilestoneStatusVerified
+	f.milestone().Holder = &models.MilestoneHolder{EnterpriseID: uuid.New().String(), Address: holder.Address(), EndorsementID: uuid.New().String()}
+	auth := f.authorization(PaymentAuthStatusApproved)
+	authorizations := &memoryPaymentAuthorizations{auths: map[uuid.UUID]*PaymentAuthorization{auth.ID: auth}}
+	service := NewPaymentExecutionService(authorizations, f.smartChequeRepo, f.transactionRepo, f.xrplService, f.vault, f.eventBus, &PaymentExecutionConfig{})
+
+	payeeBefore, _ := f.ledger.Balance(f.payee.Address())
+	result, err := service.ExecutePayment(ctx, auth.ID)
+	require.NoError(t, err)
+
+	// Nothing is forwarded before the finish validates
+	f.ledger.CloseLedger()
+	holderBalance, _ := f.ledger.Balance(holder.Address())
+	assert.Equal(t, int64(20000000), holderBalance)
+	assert.Empty(t, f.recorded(models.TransactionTypePayment))
+	confirmRecorded(t, f.ledger, f.xrplService, f.transactionRepo, service)
+	f.ledger.CloseLedger()
+
+	// The holder received the released payment and the payee kept only what the fees left over
+	holderBalance, _ = f.ledger.Balance(holder.Address())
+	assert.Equal(t, int64(30000000), holderBalance)
+	payeeAfter, _ := f.ledger.Balance(f.payee.Address())
+	assert.LessOrEqual(t, payeeAfter, payeeBefore)
+	assert.Less(t, payeeBefore-payeeAfter, int64(1000))
+
+	var routing *PaymentExecutionStep
+	for _, step := range service.(*PaymentExecutionService).activeExecutions[result.ExecutionID].Steps {
+		if step.StepType == "endorsement_routing" {
+			routing = step
+		}
+	}
+	require.NotNil(t, routing)
+	assert.Equal(t, "completed", routing.Status)
+
+	// The forward is recorded as submitted against the endorsement that made the holder
+	forwards := f.recorded(models.TransactionTypePayment)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, f.payee.Address(), forwards[0].FromAddress)
+	assert.Equal(t, holder.Address(), forwards[0].ToAddress)
+	assert.Equal(t, "10", forwards[0].Amount)
+	assert.Equal(t, f.milestone().Holder.EndorsementID, forwards[0].Metadata["endorsement_id"])
+	assert.Equal(t, models.TransactionStatusSubmitted, forwards[0].Status)
+}
+
+func TestPaymentExecutionService_ExecutePaymentSettlesInSettlementCurrency(t *testing.T) {
+	payer := newTestKeyPair(t)
+	xrplService, ledger, payee := newSettlementMarket(t, payer)
+	vault, _ := newTestFulfillmentVault(t)
+	ctx := context.Background()
+
+	// A USDC smart cheque whose payee is paid out in XRP
+	smartCheque := &models.SmartCheque{
+		ID:                 uuid.New().String(),
+		PayerID:            uuid.New().String(),
+		PayeeID:            uuid.New().String(),
+		Amount:             models.MustParseMoney("100", models.CurrencyUSDC),
+		Currency:           models.CurrencyUSDC,
+		SettlementCurrency: models.CurrencyXRP,
+		Status:             models.SmartChequeStatusLocked,
+		Milestones: []models.Milestone{
+			{ID: uuid.New().String(), Amount: models.MustParseMoney("100", models.CurrencyUSDC), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusVerified},
+		},
+	}
+	fundings, err := xrplService.CreateSmartChequeEscrowWithMilestones(payer.Address(), payee, smartCheque.Amount, smartCheque.Milestones)
+	require.NoError(t, err)
+	escrow := fundings[0].Escrow
+	reference := EscrowReference{Owner: escrow.Owner, Destination: escrow.Destination, OfferSequence: escrow.OfferSequence}
+	require.NoError(t, vault.Seal(ctx, smartCheque.ID, smartCheque.Milestones[0].ID, reference, escrow.Condition, fundings[0].Fulfillment, FulfillmentActorEscrowCreation))
+	smartCheque.EscrowAddress = escrow.Owner
+	smartCheque.Milestones[0].Escrow = &escrow
+	ledger.CloseLedger()
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+
+	smartChequeRepo := &mockSmartChequeRepoXRPL{}
+	smartChequeRepo.On("GetSmartChequeByID", mock.Anything, smartCheque.ID).Return(smartCheque, nil)
+	smartChequeRepo.On("UpdateSmartCheque", mock.Anything, smartCheque).Return(nil)
+	transactionRepo := &mockTransactionRepoXRPL{}
+	transactionRepo.On("CreateTransaction", mock.Anything).Return(nil)
+	transactionRepo.On("UpdateTransaction", mock.Anything).Return(nil)
+	eventBus := &TestMockEventBus{}
+	eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+
+	auth := &PaymentAuthorization{
+		ID:            uuid.New(),
+		SmartChequeID: smartCheque.ID,
+		MilestoneID:   smartCheque.Milestones[0].ID,
+		Amount:        "100",
+		Currency:      string(models.CurrencyUSDC),
+		Status:        PaymentAuthStatusApproved,
+	}
+	authorizations := &memoryPaymentAuthorizations{auths: map[uuid.UUID]*PaymentAuthorization{auth.ID: auth}}
+	settlement := NewCrossCurrencySettlementService(xrplService, transactionRepo, CrossCurrencySettlementConfig{})
+	service := NewPaymentExecutionServiceWithSettlement(authorizations, smartChequeRepo, transactionRepo, xrplService, vault, eventBus, &PaymentExecutionConfig{}, settlement)
+
+	xrpBefore, _ := ledger.Balance(payee)
+	result, err := service.ExecutePayment(ctx, auth.ID)
+	require.NoError(t, err)
+
+	// The release is converted only once its finish validates
+	assert.Equal(t, result.TransactionID, smartCheque.Milestones[0].Escrow.PendingTransaction)
+	confirmRecorded(t, ledger, xrplService, transactionRepo, service)
+	assert.Equal(t, models.MilestoneEscrowStatusFinished, smartCheque.Milestones[0].Escrow.Status)
+
+	// The released USDC was converted through the order book at 2 XRP per USDC
+	settled := service.(*PaymentExecutionService).activeExecutions[result.ExecutionID].Settlement
+	require.NotNil(t, settled)
+	assert.Equal(t, "USDC", settled.SourceCurrency)
+	assert.Equal(t, "XRP", settled.DestinationCurrency)
+	assert.Equal(t, "100.000000", settled.SourceAmount.String())
+	assert.Equal(t, "200.000000", settled.DeliveredAmount.String())
+	xrpAfter, _ := ledger.Balance(payee)
+	assert.Greater(t, xrpAfter-xrpBefore, int64(199999000))
+
+	// The payout records what the conversion delivered
+	var payout *models.Transaction
+	for _, call := range transactionRepo.Calls {
+		if call.Method != "UpdateTransaction" {
+			continue
+		}
+		if transaction := call.Arguments.Get(0).(*models.Transaction); transaction.Type == models.TransactionTypePayment {
+			payout = transaction
+		}
+	}
+	require.NotNil(t, payout)
+	assert.Equal(t, settled.TransactionID, payout.TransactionHash)
+	assert.Equal(t, result.TransactionID, payout.Metadata["release_transaction_id"])
+	assert.Equal(t, "200.000000", payout.DeliveredAmount)
+	assert.Equal(t, "XRP", payout.DeliveredCurrency)
+	assert.Equal(t, escrow.TransactionID, smartCheque.Milestones[0].Escrow.TransactionID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ type ReconciliationServiceInterface interface {
 	PerformReconciliation(ctx context.Context, req *ReconciliationRequest) (*ReconciliationResult, error)
 	ScheduleReconciliation(ctx context.Context, schedule *ReconciliationSchedule) error
 	GetReconciliationStatus(ctx context.Context, reconciliationID uuid.UUID) (*ReconciliationStatus, error)
+	DetectDepositOverCredits(ctx context.Context) ([]*DepositOverCredit, error)
+	DetectUncreditedLedgerDeposits(ctx context.Context, account string, sinceLedger uint32) ([]*UncreditedLedgerDeposit, error)
 
 	// Discrepancy Management
 	GetDiscrepancies(ctx context.Context, enterpriseID *uuid.UUID, limit, offset int) ([]*BalanceDiscrepancy, error)
@@ -43,6 +46,10 @@ type ReconciliationService struct {
 	xrplService     repository.XRPLServiceInterface
 	messagingClient messaging.EventBus
 	config          *ReconciliationConfig
+
+	// ledgerRepo holds the indexed on-ledger history of managed wallets, when available
+	ledgerRepo repository.LedgerTransactionRepositoryInterface
+	walletRepo repository.WalletRepositoryInterface
 }
 
 // NewReconciliationService creates a new reconciliation service instance
@@ -52,6 +59,20 @@ func NewReconciliationService(
 	xrplService repository.XRPLServiceInterface,
 	messagingClient messaging.EventBus,
 	config *ReconciliationConfig,
+) ReconciliationServiceInterface {
+	return NewReconciliationServiceWithLedgerHistory(balanceRepo, assetRepo, xrplService, messagingClient, config, nil, nil)
+}
+
+// NewReconciliationServiceWithLedgerHistory creates a reconciliation service that can also compare
+// the platform's deposits against the indexed on-ledger history of managed wallets
+func NewReconciliationServiceWithLedgerHistory(
+	balanceRepo repository.BalanceRepositoryInterface,
+	assetRepo repository.AssetRepositoryInterface,
+	xrplService repository.XRPLServiceInterface,
+	messagingClient messaging.EventBus,
+	config *ReconciliationConfig,
+	ledgerRepo repository.LedgerTransactionRepositoryInterface,
+	walletRepo repository.WalletRepositoryInterface,
 ) ReconciliationServiceInterface {
 	return &ReconciliationService{
 		balanceRepo:     balanceRepo,
@@ -59,6 +80,8 @@ func NewReconciliationService(
 		xrplService:     xrplService,
 		messagingClient: messagingClient,
 		config:          config,
+		ledgerRepo:      ledgerRepo,
+		walletRepo:      walletRepo,
 	}
 }
 
@@ -177,6 +200,33 @@ type BalanceDiscrepancy struct {
 	Resolution         *DiscrepancyResolution `json:"resolution,omitempty"`
 }
 
+// DepositOverCredit is a historical deposit credited with more than its XRPL payment delivered
+type DepositOverCredit struct {
+	AssetTransactionID uuid.UUID           `json:"asset_transaction_id"`
+	EnterpriseID       uuid.UUID           `json:"enterprise_id"`
+	CurrencyCode       string              `json:"currency_code"`
+	TransactionHash    string              `json:"transaction_hash"`
+	CreditedAmount     string              `json:"credited_amount"`
+	DeliveredAmount    string              `json:"delivered_amount"`
+	OverCreditAmount   string              `json:"over_credit_amount"`
+	PartialPayment     bool                `json:"partial_payment"`
+	Severity           DiscrepancySeverity `json:"severity"`
+	DetectedAt         time.Time           `json:"detected_at"`
+}
+
+// UncreditedLedgerDeposit is a payment the ledger delivered to a managed wallet from outside the
+// platform that no deposit credited
+type UncreditedLedgerDeposit struct {
+	Account           string    `json:"account"`
+	TransactionHash   string    `json:"transaction_hash"`
+	Sender            string    `json:"sender"`
+	LedgerIndex       uint32    `json:"ledger_index"`
+	DeliveredValue    string    `json:"delivered_value"`
+	DeliveredCurrency string    `json:"delivered_currency"`
+	LedgerTime        time.Time `json:"ledger_time"`
+	DetectedAt        time.Time `json:"detected_at"`
+}
+
 type DiscrepancyResolution struct {
 	ID               uuid.UUID                 `json:"id"`
 	DiscrepancyID    uuid.UUID                 `json:"discrepancy_id"`
@@ -554,6 +604,201 @@ func (s *ReconciliationService) reconcileBalance(ctx context.Context, enterprise
 	return discrepancy, nil
 }
 
+// DetectDepositOverCredits replays every recorded XRPL deposit against what its payment actually
+// delivered on the ledger and reports deposits credited with more, such as partial payments
+// credited at their declared Amount
+func (s *ReconciliationService) DetectDepositOverCredits(ctx context.Context) ([]*DepositOverCredit, error) {
+	batchSize := s.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	findings := []*DepositOverCredit{}
+	for offset := 0; ; offset += batchSize {
+		deposits, err := s.balanceRepo.GetAssetTransactionsByType(ctx, models.AssetTransactionTypeDeposit, batchSize, offset)
+		if err != nil {
+			return findings, fmt.Errorf("failed to get deposits: %w", err)
+		}
+
+		for _, deposit := range deposits {
+			finding, err := s.checkDepositDelivery(ctx, deposit)
+			if err != nil {
+				fmt.Printf("Warning: Failed to check delivery of deposit %s: %v\n", deposit.ID, err)
+				continue
+			}
+			if finding == nil {
+				continue
+			}
+
+			findings = append(findings, finding)
+			s.sendOverCreditAlert(ctx, finding)
+		}
+
+		if len(deposits) < batchSize {
+			return findings, nil
+		}
+	}
+}
+
+// checkDepositDelivery compares a deposit's credited amount with its payment's delivered amount
+func (s *ReconciliationService) checkDepositDelivery(ctx context.Context, deposit *models.AssetTransaction) (*DepositOverCredit, error) {
+	hash := depositTransactionHash(deposit)
+	if hash == "" {
+		return nil, nil
+	}
+
+	delivery, err := s.xrplService.GetPaymentDelivery(hash)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := s.assetRepo.GetAssetByCurrency(ctx, deposit.CurrencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported currency %s: %w", deposit.CurrencyCode, err)
+	}
+
+	credited, ok := new(big.Int).SetString(deposit.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid credited amount: %s", deposit.Amount)
+	}
+	// A delivery in another currency or from another issuer credited nothing real
+	delivered := big.NewInt(0)
+	if units, err := baseUnits(asset, delivery.Delivered); err == nil {
+		delivered.SetString(units, 10)
+	}
+
+	overCredit := new(big.Int).Sub(credited, delivered)
+	if overCredit.Sign() <= 0 {
+		return nil, nil
+	}
+
+	overCreditPercent := 100.0
+	if credited.Sign() > 0 {
+		percent, _ := new(big.Float).Quo(new(big.Float).SetInt(overCredit), new(big.Float).SetInt(credited)).Float64()
+		overCreditPercent = percent * 100
+	}
+
+	return &DepositOverCredit{
+		AssetTransactionID: deposit.ID,
+		EnterpriseID:       deposit.EnterpriseID,
+		CurrencyCode:       deposit.CurrencyCode,
+		TransactionHash:    hash,
+		CreditedAmount:     credited.String(),
+		DeliveredAmount:    delivered.String(),
+		OverCreditAmount:   overCredit.String(),
+		PartialPayment:     delivery.PartialPayment,
+		Severity:           s.determineDiscrepancySeverity(overCredit, overCreditPercent),
+		DetectedAt:         time.Now(),
+	}, nil
+}
+
+// DetectUncreditedLedgerDeposits compares the payments the indexed ledger history shows a managed
+// wallet receiving since sinceLedger against the recorded deposits, and reports payments from
+// outside the platform that were never credited
+func (s *ReconciliationService) DetectUncreditedLedgerDeposits(ctx context.Context, account string, sinceLedger uint32) ([]*UncreditedLedgerDeposit, error) {
+	if s.ledgerRepo == nil {
+		return nil, fmt.Errorf("no indexed ledger history is configured")
+	}
+	batchSize := s.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	credited := make(map[string]bool)
+	for offset := 0; ; offset += batchSize {
+		deposits, err := s.balanceRepo.GetAssetTransactionsByType(ctx, models.AssetTransactionTypeDeposit, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deposits: %w", err)
+		}
+		for _, deposit := range deposits {
+			if hash := depositTransactionHash(deposit); hash != "" {
+				credited[strings.ToUpper(hash)] = true
+			}
+		}
+		if len(deposits) < batchSize {
+			break
+		}
+	}
+
+	findings := []*UncreditedLedgerDeposit{}
+	for offset := 0; ; offset += batchSize {
+		transactions, err := s.ledgerRepo.GetLedgerTransactionsByAccount(ctx, account, sinceLedger, batchSize, offset)
+		if err != nil {
+			return findings, fmt.Errorf("failed to get ledger history: %w", err)
+		}
+		for _, tx := range transactions {
+			if tx.TransactionType != "Payment" || !tx.Succeeded() || !tx.Inbound() || tx.DeliveredValue == "" {
+				continue
+			}
+			if credited[strings.ToUpper(tx.TransactionHash)] || s.isManagedWallet(tx.Sender) {
+				continue
+			}
+			currency := tx.DeliveredCurrency
+			if currency == "" {
+				currency = "XRP"
+			}
+			findings = append(findings, &UncreditedLedgerDeposit{
+				Account:           account,
+				TransactionHash:   tx.TransactionHash,
+				Sender:            tx.Sender,
+				LedgerIndex:       tx.LedgerIndex,
+				DeliveredValue:    tx.DeliveredValue,
+				DeliveredCurrency: currency,
+				LedgerTime:        tx.LedgerTime,
+				DetectedAt:        time.Now(),
+			})
+		}
+		if len(transactions) < batchSize {
+			return findings, nil
+		}
+	}
+}
+
+// isManagedWallet reports whether an address is one of the platform's wallets, whose transfers
+// between each other are not deposits
+func (s *ReconciliationService) isManagedWallet(address string) bool {
+	if s.walletRepo == nil {
+		return false
+	}
+	wallet, err := s.walletRepo.GetByAddress(address)
+	return err == nil && wallet != nil
+}
+
+// depositTransactionHash returns the XRPL payment a deposit was credited from, if any
+func depositTransactionHash(deposit *models.AssetTransaction) string {
+	if deposit.ExternalTxHash != nil {
+		return *deposit.ExternalTxHash
+	}
+	hash, _ := deposit.Metadata["external_tx_hash"].(string)
+	return hash
+}
+
+func (s *ReconciliationService) sendOverCreditAlert(ctx context.Context, finding *DepositOverCredit) {
+	if s.messagingClient == nil {
+		return
+	}
+
+	event := &messaging.Event{
+		Type:   "reconciliation.deposit.over_credit",
+		Source: "reconciliation-service",
+		Data: map[string]interface{}{
+			"asset_transaction_id": finding.AssetTransactionID.String(),
+			"enterprise_id":        finding.EnterpriseID.String(),
+			"currency_code":        finding.CurrencyCode,
+			"transaction_hash":     finding.TransactionHash,
+			"credited_amount":      finding.CreditedAmount,
+			"delivered_amount":     finding.DeliveredAmount,
+			"over_credit_amount":   finding.OverCreditAmount,
+			"partial_payment":      finding.PartialPayment,
+			"severity":             finding.Severity,
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.messagingClient.PublishEvent(ctx, event); err != nil {
+		fmt.Printf("Warning: Failed to publish over-credit alert: %v\n", err)
+	}
+}
+
 func (s *ReconciliationService) getXRPLBalance(_ context.Context, _ uuid.UUID, _ string) string {
 	// In a real implementation, this would query XRPL
 	// For now, return a simulated balance that might have discrepancies
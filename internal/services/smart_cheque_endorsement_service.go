@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/messaging"
+)
+
+// ErrEndorsementNotAllowed is returned for an endorsement the smart check, its milestones or the
+// acting enterprise do not permit
+var ErrEndorsementNotAllowed = errors.New("endorsement not allowed")
+
+// ErrEndorsementNotFound is returned when an endorsement does not exist
+var ErrEndorsementNotFound = errors.New("endorsement not found")
+
+// SmartChequeEndorsementServiceInterface defines the interface for assigning smart check proceeds
+// to other enterprises
+type SmartChequeEndorsementServiceInterface interface {
+	// Endorse assigns the proceeds of unpaid milestones from their holder to another enterprise
+	Endorse(ctx context.Context, request *EndorseSmartChequeRequest) (*models.SmartChequeEndorsement, error)
+
+	// AcknowledgeEndorsement records the payer accepting or declining an endorsement its contract
+	// makes wait for acknowledgement
+	AcknowledgeEndorsement(ctx context.Context, smartChequeID string, endorsementID uuid.UUID, payerID string, accept bool, notes string) (*models.SmartChequeEndorsement, error)
+
+	// GetEndorsementChain returns a smart check's endorsements in sequence order
+	GetEndorsementChain(ctx context.Context, smartChequeID string) ([]*models.SmartChequeEndorsement, error)
+}
+
+// EndorseSmartChequeRequest assigns milestone proceeds to an endorsee paid at EndorseeAddress
+type EndorseSmartChequeRequest struct {
+	SmartChequeID   string
+	EndorserID      string
+	EndorseeID      string
+	EndorseeAddress string
+	// MilestoneIDs are the milestones assigned; every unpaid milestone the endorser holds when empty
+	MilestoneIDs []string
+	Notes        string
+}
+
+// endorsableStatuses are the statuses in which a smart check still has proceeds to come
+var endorsableStatuses = map[models.SmartChequeStatus]bool{
+	models.SmartChequeStatusCreated:    true,
+	models.SmartChequeStatusLocked:     true,
+	models.SmartChequeStatusInProgress: true,
+}
+
+// smartChequeEndorsementService implements SmartChequeEndorsementServiceInterface. An effective
+// endorsement makes the endorsee the holder of its milestones: escrows funded afterwards are
+// destined to the holder, and payouts of escrows funded before are forwarded to it.
+type smartChequeEndorsementService struct {
+	smartChequeRepo repository.SmartChequeRepositoryInterface
+	endorsementRepo repository.SmartChequeEndorsementRepositoryInterface
+	contractRepo    repository.ContractRepositoryInterface
+	eventBus        messaging.EventBus
+}
+
+// NewSmartChequeEndorsementService creates a new Smart Check endorsement service. Without a
+// contract repository no endorsement waits for the payer's acknowledgement.
+func NewSmartChequeEndorsementService(
+	smartChequeRepo repository.SmartChequeRepositoryInterface,
+	endorsementRepo repository.SmartChequeEndorsementRepositoryInterface,
+	contractRepo repository.ContractRepositoryInterface,
+	eventBus messaging.EventBus,
+) SmartChequeEndorsementServiceInterface {
+	return &smartChequeEndorsementService{
+		smartChequeRepo: smartChequeRepo,
+		endorsementRepo: endorsementRepo,
+		contractRepo:    contractRepo,
+		eventBus:        eventBus,
+	}
+}
+
+// Endorse assigns the proceeds of unpaid milestones from their holder to another enterprise. The
+// endorsement takes effect at once unless the contract behind the smart check requires the payer
+// to acknowledge it.
+func (s *smartChequeEndorsementService) Endorse(ctx context.Context, request *EndorseSmartChequeRequest) (*models.SmartChequeEndorsement, error) {
+	if request.EndorseeID == "" || request.EndorseeAddress == "" {
+		return nil, fmt.Errorf("%w: endorsee and its address are required", ErrEndorsementNotAllowed)
+	}
+	if request.EndorseeID == request.EndorserID {
+		return nil, fmt.Errorf("%w: an enterprise cannot endorse to itself", ErrEndorsementNotAllowed)
+	}
+
+	smartCheque, err := s.getSmartCheque(ctx, request.SmartChequeID)
+	if err != nil {
+		return nil, err
+	}
+	if smartCheque.Settlement() != models.SettlementModeEscrow {
+		return nil, fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartCheque.ID, smartCheque.Settlement())
+	}
+	if !endorsableStatuses[smartCheque.Status] {
+		return nil, fmt.Errorf("%w: smart check %s is %s", ErrEndorsementNotAllowed, smartCheque.ID, smartCheque.Status)
+	}
+
+	chain, err := s.endorsementRepo.GetEndorsementsBySmartChequeID(ctx, smartCheque.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement chain: %w", err)
+	}
+
+	milestoneIDs := request.MilestoneIDs
+	if len(milestoneIDs) == 0 {
+		for i := range smartCheque.Milestones {
+			milestone := &smartCheque.Milestones[i]
+			if smartCheque.MilestoneHolderID(milestone) == request.EndorserID && endorsableMilestone(milestone) {
+				milestoneIDs = append(milestoneIDs, milestone.ID)
+			}
+		}
+		if len(milestoneIDs) == 0 {
+			return nil, fmt.Errorf("%w: %s holds no unpaid milestones of smart check %s", ErrEndorsementNotAllowed, request.EndorserID, smartCheque.ID)
+		}
+	}
+	amount, err := s.checkMilestones(smartCheque, chain, request.EndorserID, milestoneIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	endorsement := &models.SmartChequeEndorsement{
+		ID:                      uuid.New(),
+		SmartChequeID:           smartCheque.ID,
+		Sequence:                len(chain) + 1,
+		EndorserID:              request.EndorserID,
+		EndorseeID:              request.EndorseeID,
+		EndorseeAddress:         request.EndorseeAddress,
+		MilestoneIDs:            milestoneIDs,
+		Amount:                  amount,
+		Currency:                smartCheque.Currency,
+		Status:                  models.EndorsementStatusEffective,
+		RequiresAcknowledgement: s.requiresAcknowledgement(ctx, smartCheque),
+		Notes:                   request.Notes,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if endorsement.RequiresAcknowledgement {
+		endorsement.Status = models.EndorsementStatusPendingAcknowledgement
+	}
+
+	if err := s.endorsementRepo.CreateEndorsement(ctx, endorsement); err != nil {
+		return nil, fmt.Errorf("failed to record endorsement: %w", err)
+	}
+	if endorsement.Status == models.EndorsementStatusEffective {
+		if err := s.applyEndorsement(ctx, smartCheque, endorsement); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publish(ctx, smartCheque, endorsement)
+	log.Printf("Smart Check %s endorsement %d: %s assigned %s %s to %s (%s)",
+		smartCheque.ID, endorsement.Sequence, endorsement.EndorserID, amount, smartCheque.Currency, endorsement.EndorseeID, endorsement.Status)
+	return endorsement, nil
+}
+
+// AcknowledgeEndorsement records the payer accepting or declining an endorsement waiting for its
+// acknowledgement. An accepted endorsement takes effect provided its milestones are still unpaid
+// and held by the endorser.
+func (s *smartChequeEndorsementService) AcknowledgeEndorsement(ctx context.Context, smartChequeID string, endorsementID uuid.UUID, payerID string, accept bool, notes string) (*models.SmartChequeEndorsement, error) {
+	endorsement, err := s.endorsementRepo.GetEndorsementByID(ctx, endorsementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement: %w", err)
+	}
+	if endorsement == nil || endorsement.SmartChequeID != smartChequeID {
+		return nil, fmt.Errorf("%w: %s of smart check %s", ErrEndorsementNotFound, endorsementID, smartChequeID)
+	}
+	if endorsement.Status != models.EndorsementStatusPendingAcknowledgement {
+		return nil, fmt.Errorf("%w: endorsement %s is %s", ErrEndorsementNotAllowed, endorsementID, endorsement.Status)
+	}
+
+	smartCheque, err := s.getSmartCheque(ctx, endorsement.SmartChequeID)
+	if err != nil {
+		return nil, err
+	}
+	if payerID != smartCheque.PayerID {
+		return nil, fmt.Errorf("%w: only the payer of smart check %s can acknowledge its endorsements", ErrEndorsementNotAllowed, smartCheque.ID)
+	}
+
+	endorsement.Status = models.EndorsementStatusDeclined
+	if accept {
+		if !endorsableStatuses[smartCheque.Status] {
+			return nil, fmt.Errorf("%w: smart check %s is %s", ErrEndorsementNotAllowed, smartCheque.ID, smartCheque.Status)
+		}
+		// The endorsement under acknowledgement no longer stands in the way of its own milestones
+		if _, err := s.checkMilestones(smartCheque, nil, endorsement.EndorserID, endorsement.MilestoneIDs); err != nil {
+			return nil, err
+		}
+		endorsement.Status = models.EndorsementStatusEffective
+	}
+
+	now := time.Now()
+	endorsement.AcknowledgedBy = payerID
+	endorsement.AcknowledgedAt = &now
+	if notes != "" {
+		endorsement.Notes = notes
+	}
+	endorsement.UpdatedAt = now
+	if err := s.endorsementRepo.UpdateEndorsement(ctx, endorsement); err != nil {
+		return nil, fmt.Errorf("failed to record acknowledgement: %w", err)
+	}
+	if endorsement.Status == models.EndorsementStatusEffective {
+		if err := s.applyEndorsement(ctx, smartCheque, endorsement); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publish(ctx, smartCheque, endorsement)
+	return endorsement, nil
+}
+
+// GetEndorsementChain returns a smart check's endorsements in sequence order
+func (s *smartChequeEndorsementService) GetEndorsementChain(ctx context.Context, smartChequeID string) ([]*models.SmartChequeEndorsement, error) {
+	if _, err := s.getSmartCheque(ctx, smartChequeID); err != nil {
+		return nil, err
+	}
+	chain, err := s.endorsementRepo.GetEndorsementsBySmartChequeID(ctx, smartChequeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endorsement chain: %w", err)
+	}
+	return chain, nil
+}
+
+func (s *smartChequeEndorsementService) getSmartCheque(ctx context.Context, smartChequeID string) (*models.SmartCheque, error) {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, smartChequeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart check: %w", err)
+	}
+	if smartCheque == nil {
+		return nil, fmt.Errorf("smart check not found: %s", smartChequeID)
+	}
+	return smartCheque, nil
+}
+
+// checkMilestones verifies that the endorser holds every milestone, that none has been paid and
+// that no endorsement in chain is waiting for acknowledgement on them. It returns what the
+// milestones pay out.
+func (s *smartChequeEndorsementService) checkMilestones(smartCheque *models.SmartCheque, chain []*models.SmartChequeEndorsement, endorserID string, milestoneIDs []string) (models.Money, error) {
+	pending := make(map[string]bool)
+	for _, endorsement := range chain {
+		if endorsement.Status == models.EndorsementStatusPendingAcknowledgement {
+			for _, id := range endorsement.MilestoneIDs {
+				pending[id] = true
+			}
+		}
+	}
+
+	amount := models.ZeroMoney(smartCheque.Currency)
+	seen := make(map[string]bool)
+	for _, id := range milestoneIDs {
+		milestone := smartCheque.FindMilestone(id)
+		switch {
+		case milestone == nil:
+			return amount, fmt.Errorf("%w: milestone %s is not part of smart check %s", ErrEndorsementNotAllowed, id, smartCheque.ID)
+		case seen[id]:
+			return amount, fmt.Errorf("%w: milestone %s is listed twice", ErrEndorsementNotAllowed, id)
+		case smartCheque.MilestoneHolderID(milestone) != endorserID:
+			return amount, fmt.Errorf("%w: %s does not hold milestone %s", ErrEndorsementNotAllowed, endorserID, id)
+		case !endorsableMilestone(milestone):
+			return amount, fmt.Errorf("%w: milestone %s has no proceeds left to assign", ErrEndorsementNotAllowed, id)
+		case pending[id]:
+			return amount, fmt.Errorf("%w: milestone %s has an endorsement waiting for acknowledgement", ErrEndorsementNotAllowed, id)
+		}
+		seen[id] = true
+
+		var err error
+		if amount, err = amount.Add(milestone.Amount.WithCurrency(smartCheque.Currency)); err != nil {
+			return amount, err
+		}
+	}
+	return amount, nil
+}
+
+// endorsableMilestone reports whether a milestone still has proceeds to come that can be
+// redirected. Payment channels stream to the payee's channel destination, which cannot change.
+func endorsableMilestone(milestone *models.Milestone) bool {
+	if milestone.Status == models.MilestoneStatusVerified || milestone.PaymentMode == models.MilestonePaymentModeChannel {
+		return false
+	}
+	return milestone.Escrow == nil || milestone.Escrow.Status == models.MilestoneEscrowStatusActive
+}
+
+// requiresAcknowledgement reports whether the contract behind a smart check makes assignments
+// wait for the payer. A contract that cannot be read is assumed to require it.
+func (s *smartChequeEndorsementService) requiresAcknowledgement(ctx context.Context, smartCheque *models.SmartCheque) bool {
+	if s.contractRepo == nil || smartCheque.ContractHash == "" {
+		return false
+	}
+	contract, err := s.contractRepo.GetContractByID(ctx, smartCheque.ContractHash)
+	if err != nil || contract == nil {
+		log.Printf("Contract %s of Smart Check %s could not be read, requiring acknowledgement of endorsements: %v", smartCheque.ContractHash, smartCheque.ID, err)
+		return true
+	}
+	return contract.AssignmentRequiresConsent
+}
+
+// applyEndorsement makes the endorsee the holder of the endorsement's milestones
+func (s *smartChequeEndorsementService) applyEndorsement(ctx context.Context, smartCheque *models.SmartCheque, endorsement *models.SmartChequeEndorsement) error {
+	for _, id := range endorsement.MilestoneIDs {
+		if milestone := smartCheque.FindMilestone(id); milestone != nil {
+			milestone.Holder = &models.MilestoneHolder{
+				EnterpriseID:  endorsement.EndorseeID,
+				Address:       endorsement.EndorseeAddress,
+				EndorsementID: endorsement.ID.String(),
+			}
+		}
+	}
+
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("endorsement %s recorded but its milestones could not be reassigned: %w", endorsement.ID, err)
+	}
+	return nil
+}
+
+func (s *smartChequeEndorsementService) publish(ctx context.Context, smartCheque *models.SmartCheque, endorsement *models.SmartChequeEndorsement) {
+	if s.eventBus == nil {
+		return
+	}
+	event := messaging.NewSmartChequeEndorsedEvent(smartCheque.ID, smartCheque.PayerID, endorsement.EndorserID, endorsement.EndorseeID, string(endorsement.Status), endorsement.MilestoneIDs)
+	if err := s.eventBus.PublishEvent(ctx, event); err != nil {
+		log.Printf("Failed to publish endorsement %s of Smart Check %s: %v", endorsement.ID, smartCheque.ID, err)
+	}
+}
+
+// forwardEndorsedProceeds pays a released milestone on to its holder when the milestone was
+// endorsed after its escrow was funded, so that the escrow released to an earlier holder. The
+// platform signs for the managed wallet the escrow released to. It returns the account holding the
+// funds and the record of the forwarding payment, which is nil when there was nothing to forward.
+func forwardEndorsedProceeds(xrplService repository.XRPLServiceInterface, smartCheque *models.SmartCheque, milestone *models.Milestone, releasedTo string) (string, *models.Transaction, error) {
+	holder := milestone.Holder
+	if holder == nil || holder.Address == "" || holder.Address == releasedTo {
+		return releasedTo, nil, nil
+	}
+
+	amount := milestone.Amount.WithCurrency(smartCheque.Currency)
+	forward := models.NewTransaction(
+		models.TransactionTypePayment,
+		releasedTo,
+		holder.Address,
+		amount.String(),
+		string(smartCheque.Currency),
+		holder.EnterpriseID,
+		smartCheque.PayerID, // Using payer ID as user ID for now
+	)
+	forward.SmartChequeID = &smartCheque.ID
+	forward.MilestoneID = &milestone.ID
+	forward.Metadata["endorsement_id"] = holder.EndorsementID
+
+	result, err := xrplService.SendPayment(releasedTo, holder.Address, amount)
+	if err != nil {
+		forward.Status = models.TransactionStatusFailed
+		forward.LastError = err.Error()
+		return releasedTo, forward, fmt.Errorf("failed to forward milestone %s proceeds to holder %s: %w", milestone.ID, holder.EnterpriseID, err)
+	}
+
+	recordLedgerSubmission(forward, result)
+	return holder.Address, forward, nil
+}
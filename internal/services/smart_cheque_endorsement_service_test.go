@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	repomocks "github.com/smart-payment-infrastructure/internal/repository/mocks"
+)
+
+// memoryEndorsementRepository keeps endorsements in memory
+type memoryEndorsementRepository struct {
+	endorsements []*models.SmartChequeEndorsement
+}
+
+func (r *memoryEndorsementRepository) CreateEndorsement(_ context.Context, endorsement *models.SmartChequeEndorsement) error {
+	r.endorsements = append(r.endorsements, endorsement)
+	return nil
+}
+
+func (r *memoryEndorsementRepository) UpdateEndorsement(_ context.Context, endorsement *models.SmartChequeEndorsement) error {
+	for i, existing := range r.endorsements {
+		if existing.ID == endorsement.ID {
+			r.endorsements[i] = endorsement
+			return nil
+		}
+	}
+	return errors.New("endorsement not found")
+}
+
+func (r *memoryEndorsementRepository) GetEndorsementByID(_ context.Context, id uuid.UUID) (*models.SmartChequeEndorsement, error) {
+	for _, endorsement := range r.endorsements {
+		if endorsement.ID == id {
+			return endorsement, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryEndorsementRepository) GetEndorsementsBySmartChequeID(_ context.Context, smartChequeID string) ([]*models.SmartChequeEndorsement, error) {
+	var chain []*models.SmartChequeEndorsement
+	for _, endorsement := range r.endorsements {
+		if endorsement.SmartChequeID == smartChequeID {
+			chain = append(chain, endorsement)
+		}
+	}
+	return chain, nil
+}
+
+func TestSmartChequeEndorsementService_Chain(t *testing.T) {
+	ctx := context.Background()
+	smartCheque := escrowedSmartCheque(models.SmartChequeStatusInProgress, time.Now())
+	smartCheque.ContractHash = uuid.New().String()
+	first, second := smartCheque.Milestones[0].ID, smartCheque.Milestones[1].ID
+
+	smartChequeRepo := &mockSmartChequeRepoXRPL{}
+	smartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	smartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	contractRepo := &repomocks.ContractRepositoryInterface{}
+	contractRepo.On("GetContractByID", ctx, smartCheque.ContractHash).Return(&models.Contract{ID: smartCheque.ContractHash}, nil)
+	eventBus := &TestMockEventBus{}
+	eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+	endorsements := &memoryEndorsementRepository{}
+	service := NewSmartChequeEndorsementService(smartChequeRepo, endorsements, contractRepo, eventBus)
+
+	financier, bank := uuid.New().String(), uuid.New().String()
+
+	// The payee sells the second milestone to a financier
+	endorsement, err := service.Endorse(ctx, &EndorseSmartChequeRequest{
+		SmartChequeID:   smartCheque.ID,
+		EndorserID:      smartCheque.PayeeID,
+		EndorseeID:      financier,
+		EndorseeAddress: "rFinancier",
+		MilestoneIDs:    []string{second},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.EndorsementStatusEffective, endorsement.Status)
+	assert.Equal(t, 1, endorsement.Sequence)
+	assert.True(t, models.MustParseMoney("20", models.CurrencyXRP).Equal(endorsement.Amount))
+	assert.Nil(t, smartCheque.Milestones[0].Holder)
+	require.NotNil(t, smartCheque.Milestones[1].Holder)
+	assert.Equal(t, financier, smartCheque.Milestones[1].Holder.EnterpriseID)
+	assert.Equal(t, "rFinancier", smartCheque.Milestones[1].Holder.Address)
+
+	// Only the holder can endorse a milestone on
+	_, err = service.Endorse(ctx, &EndorseSmartChequeRequest{
+		SmartChequeID: smartCheque.ID, EndorserID: smartCheque.PayeeID, EndorseeID: bank, EndorseeAddress: "rBank",
+		MilestoneIDs: []string{second},
+	})
+	assert.ErrorIs(t, err, ErrEndorsementNotAllowed)
+
+	// Without milestones named, the financier endorses everything it holds
+	endorsement, err = service.Endorse(ctx, &EndorseSmartChequeRequest{
+		SmartChequeID: smartCheque.ID, EndorserID: financier, EndorseeID: bank, EndorseeAddress: "rBank",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, endorsement.Sequence)
+	assert.Equal(t, []string{second}, endorsement.MilestoneIDs)
+	assert.Equal(t, bank, smartCheque.MilestoneHolderID(&smartCheque.Milestones[1]))
+
+	// A milestone already paid has no proceeds left to assign
+	smartCheque.ResolveMilestoneEscrow(first, models.MilestoneEscrowStatusFinished, "finish", time.Now())
+	_, err = service.Endorse(ctx, &EndorseSmartChequeRequest{
+		SmartChequeID: smartCheque.ID, EndorserID: smartCheque.PayeeID, EndorseeID: bank, EndorseeAddress: "rBank",
+		MilestoneIDs: []string{first},
+	})
+	assert.ErrorIs(t, err, ErrEndorsementNotAllowed)
+
+	chain, err := service.GetEndorsementChain(ctx, smartCheque.ID)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, smartCheque.PayeeID, chain[0].EndorserID)
+	assert.Equal(t, financier, chain[1].EndorserID)
+	assert.Len(t, eventBus.GetPublishedEvents(), 2)
+}
+
+func TestSmartChequeEndorsementService_PayerAcknowledgement(t *testing.T) {
+	ctx := context.Background()
+	smartCheque := escrowedSmartCheque(models.SmartChequeStatusLocked, time.Now())
+	smartCheque.ContractHash = uuid.New().String()
+
+	smartChequeRepo := &mockSmartChequeRepoXRPL{}
+	smartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	smartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	contractRepo := &repomocks.ContractRepositoryInterface{}
+	contractRepo.On("GetContractByID", ctx, smartCheque.ContractHash).Return(&models.Contract{ID: smartCheque.ContractHash, AssignmentRequiresConsent: true}, nil)
+	service := NewSmartChequeEndorsementService(smartChequeRepo, &memoryEndorsementRepository{}, contractRepo, nil)
+
+	financier := uuid.New().String()
+	endorse := func() (*models.SmartChequeEndorsement, error) {
+		return service.Endorse(ctx, &EndorseSmartChequeRequest{
+			SmartChequeID: smartCheque.ID, EndorserID: smartCheque.PayeeID, EndorseeID: financier, EndorseeAddress: "rFinancier",
+		})
+	}
+
+	// The contract makes the assignment wait for the payer
+	endorsement, err := endorse()
+	require.NoError(t, err)
+	assert.Equal(t, models.EndorsementStatusPendingAcknowledgement, endorsement.Status)
+	assert.True(t, endorsement.RequiresAcknowledgement)
+	assert.True(t, models.MustParseMoney("30", models.CurrencyXRP).Equal(endorsement.Amount))
+	for _, milestone := range smartCheque.Milestones {
+		assert.Nil(t, milestone.Holder)
+	}
+
+	// Nothing else can be assigned while it waits
+	_, err = endorse()
+	assert.ErrorIs(t, err, ErrEndorsementNotAllowed)
+
+	// Only the payer acknowledges, and only on the endorsement's own Smart Check
+	_, err = service.AcknowledgeEndorsement(ctx, smartCheque.ID, endorsement.ID, smartCheque.PayeeID, true, "")
+	assert.ErrorIs(t, err, ErrEndorsementNotAllowed)
+	_, err = service.AcknowledgeEndorsement(ctx, uuid.New().String(), endorsement.ID, smartCheque.PayerID, true, "")
+	assert.ErrorIs(t, err, ErrEndorsementNotFound)
+
+	// A declined endorsement leaves the proceeds with the payee, who may try again
+	declined, err := service.AcknowledgeEndorsement(ctx, smartCheque.ID, endorsement.ID, smartCheque.PayerID, false, "financier not approved")
+	require.NoError(t, err)
+	assert.Equal(t, models.EndorsementStatusDeclined, declined.Status)
+	assert.Equal(t, smartCheque.PayerID, declined.AcknowledgedBy)
+	assert.Nil(t, smartCheque.Milestones[0].Holder)
+	_, err = service.AcknowledgeEndorsement(ctx, smartCheque.ID, endorsement.ID, smartCheque.PayerID, true, "")
+	assert.ErrorIs(t, err, ErrEndorsementNotAllowed)
+
+	endorsement, err = endorse()
+	require.NoError(t, err)
+	assert.Equal(t, 2, endorsement.Sequence)
+	accepted, err := service.AcknowledgeEndorsement(ctx, smartCheque.ID, endorsement.ID, smartCheque.PayerID, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.EndorsementStatusEffective, accepted.Status)
+	require.NotNil(t, accepted.AcknowledgedAt)
+	for _, milestone := range smartCheque.Milestones {
+		require.NotNil(t, milestone.Holder)
+		assert.Equal(t, financier, milestone.Holder.EnterpriseID)
+		assert.Equal(t, endorsement.ID.String(), milestone.Holder.EndorsementID)
+	}
+}
+
+func TestSmartChequeEndorsement_RoutesPayoutsToHolder(t *testing.T) {
+	payer, payee, financier := newTestKeyPair(t), newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee, financier.Address(): financier})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+	require.NoError(t, ledger.Fund(financier.Address(), 20000000))
+
+	ctx := context.Background()
+	smartCheque := &models.SmartCheque{
+		ID:       uuid.New().String(),
+		PayerID:  uuid.New().String(),
+		PayeeID:  uuid.New().String(),
+		Amount:   models.MustParseMoney("25", models.CurrencyXRP),
+		Currency: models.CurrencyXRP,
+		Status:   models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{ID: uuid.New().String(), Amount: models.MustParseMoney("10", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+			{ID: uuid.New().String(), Amount: models.MustParseMoney("15", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+		},
+	}
+	smartChequeRepo := &mockSmartChequeRepoXRPL{}
+	smartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	smartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	transactionRepo := &mockTransactionRepoXRPL{}
+	var records []*models.Transaction
+	transactionRepo.On("CreateTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args.Get(0).(*models.Transaction))
+	}).Return(nil)
+
+	service, _ := newTestSmartChequeXRPLService(t, smartChequeRepo, transactionRepo, xrplService, &mockMilestoneRepoXRPL{})
+	endorsements := NewSmartChequeEndorsementService(smartChequeRepo, &memoryEndorsementRepository{}, nil, nil)
+	financierID := uuid.New().String()
+	endorse := func(milestoneID string) {
+		_, err := endorsements.Endorse(ctx, &EndorseSmartChequeRequest{
+			SmartChequeID: smartCheque.ID, EndorserID: smartCheque.PayeeID, EndorseeID: financierID, EndorseeAddress: financier.Address(),
+			MilestoneIDs: []string{milestoneID},
+		})
+		require.NoError(t, err)
+	}
+
+	// The first milestone is sold before funding, so its escrow is destined to the financier
+	endorse(smartCheque.Milestones[0].ID)
+	require.NoError(t, service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address()))
+	ledger.CloseLedger()
+	transactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return(records, nil)
+	assert.Equal(t, financier.Address(), smartCheque.Milestones[0].Escrow.Destination)
+	assert.Equal(t, payee.Address(), smartCheque.Milestones[1].Escrow.Destination)
+
+	// The second is sold once its escrow is on the ledger, so its payout is forwarded
+	endorse(smartCheque.Milestones[1].ID)
+
+	financierBefore, _ := ledger.Balance(financier.Address())
+	payeeBefore, _ := ledger.Balance(payee.Address())
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	for _, milestone := range smartCheque.Milestones {
+		require.NoError(t, service.CompleteMilestonePayment(ctx, smartCheque.ID, milestone.ID))
+		confirmRecorded(t, ledger, xrplService, transactionRepo, escrowObservers(service)...)
+	}
+	// The forward is submitted once the finish validates, and confirmed on the next pass
+	confirmRecorded(t, ledger, xrplService, transactionRepo, escrowObservers(service)...)
+
+	assert.Equal(t, models.SmartChequeStatusCompleted, smartCheque.Status)
+	financierAfter, _ := ledger.Balance(financier.Address())
+	payeeAfter, _ := ledger.Balance(payee.Address())
+	// Both milestones reached the financier, less the fee of finishing the escrow destined to it
+	assert.Greater(t, financierAfter-financierBefore, int64(24999000))
+	// The payee kept nothing beyond the fees of finishing its escrow and forwarding the payout
+	assert.LessOrEqual(t, payeeAfter, payeeBefore)
+	assert.Less(t, payeeBefore-payeeAfter, int64(1000))
+
+	var forwards []*models.Transaction
+	for _, record := range records {
+		if record.Type == models.TransactionTypePayment {
+			forwards = append(forwards, record)
+		}
+	}
+	require.Len(t, forwards, 1)
+	assert.Equal(t, payee.Address(), forwards[0].FromAddress)
+	assert.Equal(t, financier.Address(), forwards[0].ToAddress)
+	assert.Equal(t, "15", forwards[0].Amount)
+	// The forward is confirmed once a validated ledger applies it, not from its provisional result
+	assert.Equal(t, models.TransactionStatusConfirmed, forwards[0].Status)
+	assert.NotNil(t, forwards[0].ConfirmedAt)
+}
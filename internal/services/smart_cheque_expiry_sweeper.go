@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+)
+
+// expirySweepPageSize is how many Smart Checks of a status the sweeper reads at a time
+const expirySweepPageSize = 100
+
+// sweptStatuses are the statuses in which a Smart Check may hold escrows waiting for their CancelAfter
+var sweptStatuses = []models.SmartChequeStatus{
+	models.SmartChequeStatusLocked,
+	models.SmartChequeStatusInProgress,
+	models.SmartChequeStatusCancelled,
+	models.SmartChequeStatusExpired,
+}
+
+// SmartChequeExpirySweeper periodically cancels milestone escrows whose CancelAfter has passed,
+// returning their funds to the payer and expiring or settling the Smart Checks they belong to
+type SmartChequeExpirySweeper struct {
+	smartChequeRepo repository.SmartChequeRepositoryInterface
+	xrplService     SmartChequeXRPLServiceInterface
+	interval        time.Duration
+
+	mu       sync.Mutex
+	running  bool
+	stopChan chan struct{}
+}
+
+// NewSmartChequeExpirySweeper creates a sweeper that runs every interval once started
+func NewSmartChequeExpirySweeper(
+	smartChequeRepo repository.SmartChequeRepositoryInterface,
+	xrplService SmartChequeXRPLServiceInterface,
+	interval time.Duration,
+) *SmartChequeExpirySweeper {
+	return &SmartChequeExpirySweeper{
+		smartChequeRepo: smartChequeRepo,
+		xrplService:     xrplService,
+		interval:        interval,
+	}
+}
+
+// Start sweeps in the background until Stop is called or ctx is done
+func (s *SmartChequeExpirySweeper) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("expiry sweeper is already running")
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+
+	go s.run(ctx, s.stopChan)
+	log.Printf("Smart Check expiry sweeper started with interval %v", s.interval)
+	return nil
+}
+
+// Stop ends background sweeping
+func (s *SmartChequeExpirySweeper) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	log.Printf("Smart Check expiry sweeper stopped")
+}
+
+func (s *SmartChequeExpirySweeper) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				log.Printf("Error sweeping expired Smart Check escrows: %v", err)
+			}
+		}
+	}
+}
+
+// Sweep cancels every lapsed milestone escrow once and returns how many were cancelled. A Smart
+// Check that fails is logged and skipped so that the others are still swept.
+func (s *SmartChequeExpirySweeper) Sweep(ctx context.Context) (int, error) {
+	// Collect first: expiring a Smart Check moves it between the statuses being paged through
+	var ids []string
+	for _, status := range sweptStatuses {
+		for offset := 0; ; offset += expirySweepPageSize {
+			smartCheques, err := s.smartChequeRepo.GetSmartChequesByStatus(ctx, status, expirySweepPageSize, offset)
+			if err != nil {
+				return 0, fmt.Errorf("failed to list %s smart checks: %w", status, err)
+			}
+			for _, smartCheque := range smartCheques {
+				if smartCheque.HasMilestoneEscrows() {
+					ids = append(ids, smartCheque.ID)
+				}
+			}
+			if len(smartCheques) < expirySweepPageSize {
+				break
+			}
+		}
+	}
+
+	cancelled := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return cancelled, err
+		}
+		n, err := s.xrplService.ExpireSmartChequeEscrows(ctx, id)
+		cancelled += n
+		if err != nil {
+			log.Printf("Warning: Failed to expire escrows of Smart Check %s: %v", id, err)
+		}
+	}
+
+	if cancelled > 0 {
+		log.Printf("Expiry sweep submitted cancellations of %d lapsed milestone escrows", cancelled)
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+func TestSmartChequeExpirySweeper_ReturnsLapsedEscrows(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, xrplService, &mockMilestoneRepoXRPL{})
+	history := &memoryTransitionHistory{}
+	service.StateMachine().SetHistory(history)
+	service.StateMachine().now = ledger.Now
+	sweeper := NewSmartChequeExpirySweeper(mockSmartChequeRepo, service, time.Hour)
+
+	ctx := context.Background()
+	var records []*models.Transaction
+	mockTransactionRepo.On("CreateTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args.Get(0).(*models.Transaction))
+	}).Return(nil)
+	fund := func() *models.SmartCheque {
+		smartCheque := &models.SmartCheque{
+			ID:       uuid.New().String(),
+			PayerID:  uuid.New().String(),
+			PayeeID:  uuid.New().String(),
+			Amount:   models.MustParseMoney("25", models.CurrencyXRP),
+			Currency: models.CurrencyXRP,
+			Status:   models.SmartChequeStatusCreated,
+			Milestones: []models.Milestone{
+				{ID: uuid.New().String(), Amount: models.MustParseMoney("10", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+				{ID: uuid.New().String(), Amount: models.MustParseMoney("15", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+			},
+		}
+		mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+		mockSmartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+		records = nil
+		require.NoError(t, service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address()))
+		ledger.CloseLedger()
+		mockTransactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return(records, nil)
+		return smartCheque
+	}
+
+	// The first Smart Check pays one milestone and is called off before the other lapses; the
+	// second is left to run out of time
+	calledOff, lapsing := fund(), fund()
+	assert.Equal(t, models.SmartChequeStatusLocked, lapsing.Status)
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	require.NoError(t, service.CompleteMilestonePayment(ctx, calledOff.ID, calledOff.Milestones[0].ID))
+	// The four escrow creations validate along with the finish
+	assert.Equal(t, 5, confirmRecorded(t, ledger, xrplService, mockTransactionRepo, escrowObservers(service)...))
+	require.NoError(t, service.CancelSmartChequeEscrowWithReason(ctx, calledOff.ID, CancellationReasonMutualAgreement, "work dropped"))
+	assert.Equal(t, models.SmartChequeStatusCancelled, calledOff.Status)
+	assert.Equal(t, models.MilestoneEscrowStatusActive, calledOff.Milestones[1].Escrow.Status)
+
+	mockSmartChequeRepo.On("GetSmartChequesByStatus", ctx, models.SmartChequeStatusLocked, expirySweepPageSize, 0).Return([]*models.SmartCheque{lapsing}, nil)
+	mockSmartChequeRepo.On("GetSmartChequesByStatus", ctx, models.SmartChequeStatusCancelled, expirySweepPageSize, 0).Return([]*models.SmartCheque{calledOff}, nil)
+	mockSmartChequeRepo.On("GetSmartChequesByStatus", ctx, mock.Anything, expirySweepPageSize, 0).Return([]*models.SmartCheque{}, nil)
+
+	// Nothing can be cancelled before CancelAfter
+	cancelled, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cancelled)
+	assert.Equal(t, models.SmartChequeStatusCancelled, calledOff.Status)
+	assert.Equal(t, models.SmartChequeStatusLocked, lapsing.Status)
+
+	payerBefore, _ := ledger.Balance(payer.Address())
+	ledger.AdvanceTime(31 * 24 * time.Hour)
+	ledger.CloseLedger()
+
+	cancelled, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, cancelled)
+
+	// The escrows are resolved once their cancellations validate
+	assert.Equal(t, models.MilestoneEscrowStatusActive, calledOff.Milestones[1].Escrow.Status)
+	assert.Equal(t, 3, confirmRecorded(t, ledger, xrplService, mockTransactionRepo, escrowObservers(service)...))
+
+	// The paid milestone stays with the payee; the lapsed escrows went back to the payer
+	assert.Equal(t, models.SmartChequeStatusPartiallyPaid, calledOff.Status)
+	assert.Equal(t, models.MilestoneEscrowStatusFinished, calledOff.Milestones[0].Escrow.Status)
+	assert.Equal(t, models.MilestoneEscrowStatusCancelled, calledOff.Milestones[1].Escrow.Status)
+	assert.Equal(t, models.SmartChequeStatusRefunded, lapsing.Status)
+	for _, milestone := range lapsing.Milestones {
+		assert.Equal(t, models.MilestoneEscrowStatusCancelled, milestone.Escrow.Status)
+		_, err := xrplService.GetEscrowStatus(payer.Address(), strconv.FormatUint(uint64(milestone.Escrow.OfferSequence), 10))
+		assert.True(t, errors.Is(err, xrpl.ErrEntryNotFound))
+	}
+	payerAfter, _ := ledger.Balance(payer.Address())
+	assert.Greater(t, payerAfter-payerBefore, int64(39990000))
+
+	// Every step of both Smart Checks is in the status history
+	transitions, err := service.StateMachine().GetHistory(ctx, lapsing.ID)
+	require.NoError(t, err)
+	var path []models.SmartChequeStatus
+	for _, transition := range transitions {
+		path = append(path, transition.ToStatus)
+	}
+	assert.Equal(t, []models.SmartChequeStatus{models.SmartChequeStatusLocked, models.SmartChequeStatusExpired, models.SmartChequeStatusRefunded}, path)
+
+	transitions, err = service.StateMachine().GetHistory(ctx, calledOff.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, transitions)
+	assert.Equal(t, models.SmartChequeStatusPartiallyPaid, transitions[len(transitions)-1].ToStatus)
+}
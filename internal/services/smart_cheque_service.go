@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -60,7 +61,7 @@ type SmartChequeServiceInterface interface {
 type CreateSmartChequeRequest struct {
 	PayerID      string             `json:"payer_id" binding:"required"`
 	PayeeID      string             `json:"payee_id" binding:"required"`
-	Amount       float64            `json:"amount" binding:"required,gt=0"`
+	Amount       models.Money       `json:"amount" binding:"required"`
 	Currency     models.Currency    `json:"currency" binding:"required"`
 	Milestones   []models.Milestone `json:"milestones"`
 	ContractHash string             `json:"contract_hash"`
@@ -68,14 +69,30 @@ type CreateSmartChequeRequest struct {
 
 // UpdateSmartChequeRequest represents the request to update a smart check
 type UpdateSmartChequeRequest struct {
-	PayerID       *string                   `json:"payer_id,omitempty"`
-	PayeeID       *string                   `json:"payee_id,omitempty"`
-	Amount        *float64                  `json:"amount,omitempty"`
-	Currency      *models.Currency          `json:"currency,omitempty"`
-	Milestones    *[]models.Milestone       `json:"milestones,omitempty"`
-	EscrowAddress *string                   `json:"escrow_address,omitempty"`
-	Status        *models.SmartChequeStatus `json:"status,omitempty"`
-	ContractHash  *string                   `json:"contract_hash,omitempty"`
+	PayerID      *string                   `json:"payer_id,omitempty"`
+	PayeeID      *string                   `json:"payee_id,omitempty"`
+	Amount       *models.Money             `json:"amount,omitempty"`
+	Currency     *models.Currency          `json:"currency,omitempty"`
+	Milestones   *[]models.Milestone       `json:"milestones,omitempty"`
+	Status       *models.SmartChequeStatus `json:"status,omitempty"`
+	ContractHash *string                   `json:"contract_hash,omitempty"`
+}
+
+// ledgerSettledStatuses are the statuses a smart check reaches only once the ledger funds, pays
+// out or refunds it, so clients cannot request them
+var ledgerSettledStatuses = map[models.SmartChequeStatus]bool{
+	models.SmartChequeStatusLocked:        true,
+	models.SmartChequeStatusCompleted:     true,
+	models.SmartChequeStatusPartiallyPaid: true,
+	models.SmartChequeStatusRefunded:      true,
+}
+
+// validateRequestedStatus refuses a status a client asked for that only the ledger settles
+func validateRequestedStatus(status models.SmartChequeStatus) error {
+	if ledgerSettledStatuses[status] {
+		return fmt.Errorf("%w: a smart check is %s only once the ledger settles it", ErrInvalidStatusTransition, status)
+	}
+	return nil
 }
 
 // SmartChequeStatistics represents statistics about smart checks
@@ -124,17 +141,29 @@ type AuditLogEntry struct {
 	UserAgent     string    `json:"user_agent,omitempty"`
 }
 
+// ErrSmartChequeLocked is returned for an edit a smart check no longer accepts once its funds are locked
+var ErrSmartChequeLocked = errors.New("smart check funds are locked")
+
 // smartChequeService implements SmartChequeServiceInterface
 type smartChequeService struct {
 	smartChequeRepo repository.SmartChequeRepositoryInterface
 	auditRepo       repository.AuditRepositoryInterface // Add audit repository
+	stateMachine    *SmartChequeStateMachine
 }
 
-// NewSmartChequeService creates a new smart check service
+// NewSmartChequeService creates a new smart check service. Its status changes go through a state
+// machine without escrow effects, so transitions that move funds are refused.
 func NewSmartChequeService(smartChequeRepo repository.SmartChequeRepositoryInterface, auditRepo repository.AuditRepositoryInterface) SmartChequeServiceInterface {
+	return NewSmartChequeServiceWithStateMachine(smartChequeRepo, auditRepo, NewSmartChequeStateMachine(nil))
+}
+
+// NewSmartChequeServiceWithStateMachine creates a smart check service whose status changes go
+// through stateMachine
+func NewSmartChequeServiceWithStateMachine(smartChequeRepo repository.SmartChequeRepositoryInterface, auditRepo repository.AuditRepositoryInterface, stateMachine *SmartChequeStateMachine) SmartChequeServiceInterface {
 	return &smartChequeService{
 		smartChequeRepo: smartChequeRepo,
 		auditRepo:       auditRepo,
+		stateMachine:    stateMachine,
 	}
 }
 
@@ -178,7 +207,7 @@ func (s *smartChequeService) validateCreateRequest(request *CreateSmartChequeReq
 		return fmt.Errorf("payee_id is required")
 	}
 
-	if request.Amount <= 0 {
+	if !request.Amount.IsPositive() {
 		return fmt.Errorf("amount must be greater than 0")
 	}
 
@@ -190,6 +219,13 @@ func (s *smartChequeService) validateCreateRequest(request *CreateSmartChequeReq
 		return fmt.Errorf("invalid currency: %s", request.Currency)
 	}
 
+	// Hold the amounts at the currency's precision so they sum exactly
+	amount, err := request.Amount.Denominate(request.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+	request.Amount = amount
+
 	// Validate contract hash if provided (basic validation)
 	if request.ContractHash != "" {
 		// Allow both UUID format and other formats for backward compatibility
@@ -200,18 +236,25 @@ func (s *smartChequeService) validateCreateRequest(request *CreateSmartChequeReq
 	}
 
 	// Validate milestones
-	var totalMilestoneAmount float64
+	totalMilestoneAmount := models.ZeroMoney(request.Currency)
 	for i, milestone := range request.Milestones {
 		if err := s.validateMilestone(milestone, i); err != nil {
 			return err
 		}
-		totalMilestoneAmount += milestone.Amount
+		milestoneAmount, err := milestone.Amount.Denominate(request.Currency)
+		if err != nil {
+			return fmt.Errorf("milestone %d: invalid amount: %w", i, err)
+		}
+		request.Milestones[i].Amount = milestoneAmount
+		if totalMilestoneAmount, err = totalMilestoneAmount.Add(milestoneAmount); err != nil {
+			return fmt.Errorf("milestone %d: %w", i, err)
+		}
 	}
 
 	// Validate that milestone amounts sum up to total smart check amount
 	// Only validate if we have milestones
-	if len(request.Milestones) > 0 && totalMilestoneAmount != request.Amount {
-		return fmt.Errorf("sum of milestone amounts (%f) must equal smart check amount (%f)", totalMilestoneAmount, request.Amount)
+	if len(request.Milestones) > 0 && !totalMilestoneAmount.Equal(request.Amount) {
+		return fmt.Errorf("sum of milestone amounts (%s) must equal smart check amount (%s)", totalMilestoneAmount, request.Amount)
 	}
 
 	return nil
@@ -227,7 +270,7 @@ func (s *smartChequeService) validateMilestone(milestone models.Milestone, index
 		return fmt.Errorf("milestone %d: description is required", index)
 	}
 
-	if milestone.Amount <= 0 {
+	if !milestone.Amount.IsPositive() {
 		return fmt.Errorf("milestone %d: amount must be greater than 0", index)
 	}
 
@@ -331,6 +374,10 @@ func (s *smartChequeService) validateUpdateRequest(id string, request *UpdateSma
 
 	// Validate status transition if provided
 	if request.Status != nil {
+		if err := validateRequestedStatus(*request.Status); err != nil {
+			return err
+		}
+
 		// Get current smart check to validate status transition
 		current, err := s.smartChequeRepo.GetSmartChequeByID(context.Background(), id)
 		if err != nil {
@@ -357,44 +404,9 @@ func (s *smartChequeService) validateUpdateRequest(id string, request *UpdateSma
 	return nil
 }
 
-// validateStatusTransition validates that a status transition is allowed
+// validateStatusTransition validates that the state machine allows a status transition
 func (s *smartChequeService) validateStatusTransition(from, to models.SmartChequeStatus) error {
-	// Define valid status transitions
-	validTransitions := map[models.SmartChequeStatus][]models.SmartChequeStatus{
-		models.SmartChequeStatusCreated: {
-			models.SmartChequeStatusLocked,
-			models.SmartChequeStatusInProgress,
-			models.SmartChequeStatusDisputed,
-		},
-		models.SmartChequeStatusLocked: {
-			models.SmartChequeStatusInProgress,
-			models.SmartChequeStatusDisputed,
-		},
-		models.SmartChequeStatusInProgress: {
-			models.SmartChequeStatusCompleted,
-			models.SmartChequeStatusDisputed,
-		},
-		models.SmartChequeStatusCompleted: {
-			models.SmartChequeStatusDisputed,
-		},
-		models.SmartChequeStatusDisputed: {
-			models.SmartChequeStatusInProgress,
-			models.SmartChequeStatusCompleted,
-		},
-	}
-
-	// Check if transition is valid
-	if allowedTransitions, exists := validTransitions[from]; exists {
-		for _, allowed := range allowedTransitions {
-			if to == allowed {
-				return nil
-			}
-		}
-		return fmt.Errorf("invalid status transition from %s to %s", from, to)
-	}
-
-	// If from status is not in the map, allow any transition (for new statuses)
-	return nil
+	return ValidateSmartChequeTransition(from, to)
 }
 
 // GetSmartCheque retrieves a smart check by ID
@@ -432,7 +444,41 @@ func (s *smartChequeService) UpdateSmartCheque(ctx context.Context, id string, r
 		return nil, fmt.Errorf("smart check not found: %s", id)
 	}
 
-	// Update fields if provided
+	if err := s.applyUpdate(ctx, smartCheque, request); err != nil {
+		return nil, err
+	}
+
+	// Save to repository
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return nil, fmt.Errorf("failed to update smart check: %w", err)
+	}
+
+	return smartCheque, nil
+}
+
+// applyUpdate applies an update request to a stored smart check, moving it to a requested status
+// through the state machine
+func (s *smartChequeService) applyUpdate(ctx context.Context, smartCheque *models.SmartCheque, request *UpdateSmartChequeRequest) error {
+	if err := applySmartChequeUpdate(smartCheque, request); err != nil {
+		return err
+	}
+	if request.Status != nil {
+		return s.stateMachine.Transition(ctx, smartCheque, *request.Status, "smart check updated", nil)
+	}
+	return nil
+}
+
+// applySmartChequeUpdate applies the fields an update request provides to a stored smart check,
+// except its status, which only the state machine changes. The terms of the payment can only be
+// edited before the funds are locked, and milestones keep the escrow, channel and holder the
+// platform recorded for them.
+func applySmartChequeUpdate(smartCheque *models.SmartCheque, request *UpdateSmartChequeRequest) error {
+	if smartCheque.Status != models.SmartChequeStatusCreated {
+		if field := lockedField(request); field != "" {
+			return fmt.Errorf("%w: %s of smart check %s cannot be edited while it is %s", ErrSmartChequeLocked, field, smartCheque.ID, smartCheque.Status)
+		}
+	}
+
 	if request.PayerID != nil {
 		smartCheque.PayerID = *request.PayerID
 	}
@@ -450,30 +496,53 @@ func (s *smartChequeService) UpdateSmartCheque(ctx context.Context, id string, r
 	}
 
 	if request.Milestones != nil {
-		smartCheque.Milestones = *request.Milestones
-	}
-
-	if request.EscrowAddress != nil {
-		smartCheque.EscrowAddress = *request.EscrowAddress
-	}
-
-	if request.Status != nil {
-		smartCheque.Status = *request.Status
+		smartCheque.Milestones = keepMilestoneState(smartCheque.Milestones, *request.Milestones)
 	}
 
 	if request.ContractHash != nil {
 		smartCheque.ContractHash = *request.ContractHash
 	}
 
-	// Update timestamp
 	smartCheque.UpdatedAt = time.Now()
+	return nil
+}
 
-	// Save to repository
-	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
-		return nil, fmt.Errorf("failed to update smart check: %w", err)
+// lockedField names the first field of an update request that is fixed once funds are locked
+func lockedField(request *UpdateSmartChequeRequest) string {
+	switch {
+	case request.PayerID != nil:
+		return "payer_id"
+	case request.PayeeID != nil:
+		return "payee_id"
+	case request.Amount != nil:
+		return "amount"
+	case request.Currency != nil:
+		return "currency"
+	case request.Milestones != nil:
+		return "milestones"
+	case request.Status != nil:
+		return "status"
+	}
+	return ""
+}
+
+// keepMilestoneState returns the edited milestones carrying the escrow, channel and holder stored
+// for the milestone of the same ID; clients never set these themselves
+func keepMilestoneState(stored, edited []models.Milestone) []models.Milestone {
+	byID := make(map[string]*models.Milestone, len(stored))
+	for i := range stored {
+		byID[stored[i].ID] = &stored[i]
 	}
 
-	return smartCheque, nil
+	milestones := make([]models.Milestone, len(edited))
+	for i, milestone := range edited {
+		milestone.Escrow, milestone.Channel, milestone.Holder = nil, nil, nil
+		if previous, ok := byID[milestone.ID]; ok {
+			milestone.Escrow, milestone.Channel, milestone.Holder = previous.Escrow, previous.Channel, previous.Holder
+		}
+		milestones[i] = milestone
+	}
+	return milestones
 }
 
 // DeleteSmartCheque deletes a smart check
@@ -492,6 +561,11 @@ func (s *smartChequeService) DeleteSmartCheque(ctx context.Context, id string) e
 		return fmt.Errorf("smart check not found: %s", id)
 	}
 
+	// Once funded, the record is what settles the escrow and accounts for its funds
+	if smartCheque.Status != models.SmartChequeStatusCreated {
+		return fmt.Errorf("%w: smart check %s cannot be deleted while it is %s", ErrSmartChequeLocked, id, smartCheque.Status)
+	}
+
 	// Delete from repository
 	if err := s.smartChequeRepo.DeleteSmartCheque(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete smart check: %w", err)
@@ -547,12 +621,7 @@ func (s *smartChequeService) ListSmartChequesByPayee(ctx context.Context, payeeI
 // ListSmartChequesByStatus lists smart checks by status
 func (s *smartChequeService) ListSmartChequesByStatus(ctx context.Context, status models.SmartChequeStatus, limit, offset int) ([]*models.SmartCheque, error) {
 	// Validate status
-	switch status {
-	case models.SmartChequeStatusCreated, models.SmartChequeStatusLocked,
-		models.SmartChequeStatusInProgress, models.SmartChequeStatusCompleted,
-		models.SmartChequeStatusDisputed:
-		// Valid status
-	default:
+	if !status.IsValid() {
 		return nil, fmt.Errorf("invalid status: %s", status)
 	}
 
@@ -579,14 +648,12 @@ func (s *smartChequeService) UpdateSmartChequeStatus(ctx context.Context, id str
 	}
 
 	// Validate status
-	switch status {
-	case models.SmartChequeStatusCreated, models.SmartChequeStatusLocked,
-		models.SmartChequeStatusInProgress, models.SmartChequeStatusCompleted,
-		models.SmartChequeStatusDisputed:
-		// Valid status
-	default:
+	if !status.IsValid() {
 		return fmt.Errorf("invalid status: %s", status)
 	}
+	if err := validateRequestedStatus(status); err != nil {
+		return err
+	}
 
 	// Get existing smart check
 	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, id)
@@ -598,16 +665,17 @@ func (s *smartChequeService) UpdateSmartChequeStatus(ctx context.Context, id str
 		return fmt.Errorf("smart check not found: %s", id)
 	}
 
-	// Update status
-	smartCheque.Status = status
-	smartCheque.UpdatedAt = time.Now()
+	transitionErr := s.stateMachine.Transition(ctx, smartCheque, status, "status updated", nil)
+	if transitionErr != nil && errors.Is(transitionErr, ErrInvalidStatusTransition) {
+		return transitionErr
+	}
 
-	// Save to repository
+	// An effect may have moved funds before failing, which the smart check must keep
 	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
 		return fmt.Errorf("failed to update smart check status: %w", err)
 	}
 
-	return nil
+	return transitionErr
 }
 
 // GetSmartChequeStatistics returns statistics about smart checks
@@ -759,7 +827,7 @@ func (s *smartChequeService) CreateSmartChequeBatch(ctx context.Context, request
 		auditEntry := &AuditLogEntry{
 			SmartChequeID: smartCheque.ID,
 			Action:        "smart_check_created",
-			Details:       fmt.Sprintf("Smart check created with amount %f %s", smartCheque.Amount, smartCheque.Currency),
+			Details:       fmt.Sprintf("Smart check created with amount %s %s", smartCheque.Amount, smartCheque.Currency),
 			Timestamp:     time.Now(),
 		}
 		_ = s.CreateAuditLog(ctx, auditEntry) // Log error but don't fail the operation
@@ -820,42 +888,14 @@ func (s *smartChequeService) UpdateSmartChequeBatch(ctx context.Context, updates
 			continue
 		}
 
-		// Update fields if provided
-		if request.PayerID != nil {
-			smartCheque.PayerID = *request.PayerID
-		}
-
-		if request.PayeeID != nil {
-			smartCheque.PayeeID = *request.PayeeID
-		}
-
-		if request.Amount != nil {
-			smartCheque.Amount = *request.Amount
-		}
-
-		if request.Currency != nil {
-			smartCheque.Currency = *request.Currency
-		}
-
-		if request.Milestones != nil {
-			smartCheque.Milestones = *request.Milestones
-		}
-
-		if request.EscrowAddress != nil {
-			smartCheque.EscrowAddress = *request.EscrowAddress
-		}
-
-		if request.Status != nil {
-			smartCheque.Status = *request.Status
-		}
-
-		if request.ContractHash != nil {
-			smartCheque.ContractHash = *request.ContractHash
+		if err := s.applyUpdate(ctx, smartCheque, request); err != nil {
+			batchResult.Success = false
+			batchResult.Error = err.Error()
+			result.FailureCount++
+			result.Results = append(result.Results, batchResult)
+			continue
 		}
 
-		// Update timestamp
-		smartCheque.UpdatedAt = time.Now()
-
 		smartCheques = append(smartCheques, smartCheque)
 		batchResult.Success = true
 		result.SuccessCount++
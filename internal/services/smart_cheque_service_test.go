@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -38,8 +40,8 @@ func TestSmartChequeService_GetSmartChequeAnalytics(t *testing.T) {
 	mockRepo.On("GetSmartChequeAmountStatistics", ctx).Return(10000.0, 1000.0, 5000.0, 100.0, nil)
 
 	recentActivity := []*models.SmartCheque{
-		{ID: "1", Amount: 1000.0, Currency: models.CurrencyUSDT},
-		{ID: "2", Amount: 2000.0, Currency: models.CurrencyUSDC},
+		{ID: "1", Amount: models.MustParseMoney("1000", models.CurrencyUSDT), Currency: models.CurrencyUSDT},
+		{ID: "2", Amount: models.MustParseMoney("2000", models.CurrencyUSDC), Currency: models.CurrencyUSDC},
 	}
 	mockRepo.On("GetRecentSmartCheques", ctx, 10).Return(recentActivity, nil)
 
@@ -80,19 +82,19 @@ func TestSmartChequeService_CreateSmartChequeBatch(t *testing.T) {
 		{
 			PayerID:  "payer1",
 			PayeeID:  "payee1",
-			Amount:   1000.0,
+			Amount:   models.MustParseMoney("1000", models.CurrencyUSDT),
 			Currency: models.CurrencyUSDT,
 			Milestones: []models.Milestone{
-				{ID: "m1", Description: "Milestone 1", Amount: 1000.0, VerificationMethod: "manual", Status: models.MilestoneStatusPending},
+				{ID: "m1", Description: "Milestone 1", Amount: models.MustParseMoney("1000", models.CurrencyUSDT), VerificationMethod: "manual", Status: models.MilestoneStatusPending},
 			},
 		},
 		{
 			PayerID:  "payer2",
 			PayeeID:  "payee2",
-			Amount:   2000.0,
+			Amount:   models.MustParseMoney("2000", models.CurrencyUSDC),
 			Currency: models.CurrencyUSDC,
 			Milestones: []models.Milestone{
-				{ID: "m2", Description: "Milestone 2", Amount: 2000.0, VerificationMethod: "manual", Status: models.MilestoneStatusPending},
+				{ID: "m2", Description: "Milestone 2", Amount: models.MustParseMoney("2000", models.CurrencyUSDC), VerificationMethod: "manual", Status: models.MilestoneStatusPending},
 			},
 		},
 	}
@@ -117,6 +119,45 @@ func TestSmartChequeService_CreateSmartChequeBatch(t *testing.T) {
 	mockAuditRepo.AssertExpectations(t)
 }
 
+func TestSmartChequeService_CreateSmartChequeSumsMilestonesExactly(t *testing.T) {
+	mockRepo := &mocks.SmartChequeRepositoryInterface{}
+	service := NewSmartChequeService(mockRepo, &mocks.AuditRepositoryInterface{})
+	ctx := context.Background()
+
+	mockRepo.On("CreateSmartCheque", ctx, mock.AnythingOfType("*models.SmartCheque")).Return(nil)
+
+	// 0.1 + 0.2 is not 0.3 in float64, but it is as money
+	var request CreateSmartChequeRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"payer_id": "payer", "payee_id": "payee", "amount": 0.3, "currency": "USDT",
+		"milestones": [
+			{"id": "m1", "description": "Design", "amount": 0.1, "verification_method": "manual", "status": "pending"},
+			{"id": "m2", "description": "Build", "amount": "0.2", "verification_method": "manual", "status": "pending"}
+		]
+	}`), &request))
+
+	smartCheque, err := service.CreateSmartCheque(ctx, &request)
+	require.NoError(t, err)
+	assert.Equal(t, "0.300000", smartCheque.Amount.String())
+	assert.Equal(t, models.CurrencyUSDT, smartCheque.Amount.Currency())
+	assert.Equal(t, "0.200000", smartCheque.Milestones[1].Amount.String())
+
+	// Amounts finer than the currency, or milestones that do not add up, are rejected
+	request.Amount = models.MustParseMoney("0.3000001", "")
+	_, err = service.CreateSmartCheque(ctx, &request)
+	assert.ErrorIs(t, err, models.ErrMoneyPrecision)
+
+	request.Amount = models.MustParseMoney("0.31", "")
+	_, err = service.CreateSmartCheque(ctx, &request)
+	assert.ErrorContains(t, err, "sum of milestone amounts (0.300000) must equal smart check amount (0.310000)")
+
+	request.Amount = models.MustParseMoney("0.3", models.CurrencyUSDC)
+	_, err = service.CreateSmartCheque(ctx, &request)
+	assert.ErrorIs(t, err, models.ErrCurrencyMismatch)
+
+	mockRepo.AssertNumberOfCalls(t, "CreateSmartCheque", 1)
+}
+
 func TestSmartChequeService_UpdateSmartChequeBatch(t *testing.T) {
 	mockRepo := &mocks.SmartChequeRepositoryInterface{}
 	mockAuditRepo := &mocks.AuditRepositoryInterface{}
@@ -234,3 +275,133 @@ func TestSmartChequeService_GetAuditTrail(t *testing.T) {
 	// Verify mock expectations
 	mockAuditRepo.AssertExpectations(t)
 }
+
+func TestSmartChequeService_UpdateSmartChequeKeepsServerState(t *testing.T) {
+	mockRepo := &mocks.SmartChequeRepositoryInterface{}
+	service := NewSmartChequeService(mockRepo, &mocks.AuditRepositoryInterface{})
+	ctx := context.Background()
+
+	holder := &models.MilestoneHolder{EnterpriseID: "financier", Address: "rFinancier", EndorsementID: uuid.New().String()}
+	smartCheque := &models.SmartCheque{
+		ID:      "sc-1",
+		PayerID: "payer",
+		PayeeID: "payee",
+		Status:  models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{ID: "m1", Description: "Design", Amount: models.MustParseMoney("10", models.CurrencyUSDT), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending, Holder: holder},
+		},
+	}
+	mockRepo.On("GetSmartChequeByID", ctx, "sc-1").Return(smartCheque, nil)
+	mockRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+
+	// A client cannot reroute a milestone by writing its holder or escrow
+	var edited []models.Milestone
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "m1", "description": "Design v2", "amount": "10", "verification_method": "manual", "status": "pending",
+		 "holder": {"enterprise_id": "attacker", "address": "rAttacker"}, "escrow": {"destination": "rAttacker"}},
+		{"id": "m2", "description": "Build", "amount": "5", "verification_method": "manual", "status": "pending",
+		 "holder": {"enterprise_id": "attacker", "address": "rAttacker"}}
+	]`), &edited))
+	updated, err := service.UpdateSmartCheque(ctx, "sc-1", &UpdateSmartChequeRequest{Milestones: &edited})
+	require.NoError(t, err)
+	require.Len(t, updated.Milestones, 2)
+	assert.Equal(t, "Design v2", updated.Milestones[0].Description)
+	assert.Equal(t, holder, updated.Milestones[0].Holder)
+	assert.Nil(t, updated.Milestones[0].Escrow)
+	assert.Nil(t, updated.Milestones[1].Holder)
+
+	// Once the funds are locked the terms are fixed
+	smartCheque.Status = models.SmartChequeStatusLocked
+	payee, amount := "attacker", models.MustParseMoney("1", models.CurrencyUSDT)
+	cancelled := models.SmartChequeStatusCancelled
+	for _, request := range []*UpdateSmartChequeRequest{
+		{Milestones: &edited}, {PayeeID: &payee}, {Amount: &amount}, {Status: &cancelled},
+	} {
+		_, err = service.UpdateSmartCheque(ctx, "sc-1", request)
+		assert.ErrorIs(t, err, ErrSmartChequeLocked)
+	}
+	assert.Equal(t, "payee", smartCheque.PayeeID)
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	mockRepo.AssertNumberOfCalls(t, "UpdateSmartCheque", 1)
+}
+
+func TestSmartChequeService_UpdateRefusesLedgerSettledStatuses(t *testing.T) {
+	mockRepo := &mocks.SmartChequeRepositoryInterface{}
+	service := NewSmartChequeService(mockRepo, &mocks.AuditRepositoryInterface{})
+	ctx := context.Background()
+
+	smartCheque := &models.SmartCheque{ID: "sc-1", PayerID: "payer", PayeeID: "payee", Status: models.SmartChequeStatusCreated}
+	mockRepo.On("GetSmartChequeByID", ctx, "sc-1").Return(smartCheque, nil)
+
+	// A client cannot claim an escrow of its own to lock an unfunded smart check
+	var request UpdateSmartChequeRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"escrow_address": "rAttacker", "status": "locked"}`), &request))
+	_, err := service.UpdateSmartCheque(ctx, "sc-1", &request)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	// Nor settle one it never funded
+	for _, status := range []models.SmartChequeStatus{
+		models.SmartChequeStatusCompleted, models.SmartChequeStatusPartiallyPaid, models.SmartChequeStatusRefunded,
+	} {
+		_, err = service.UpdateSmartCheque(ctx, "sc-1", &UpdateSmartChequeRequest{Status: &status})
+		assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+		assert.ErrorIs(t, service.UpdateSmartChequeStatus(ctx, "sc-1", status), ErrInvalidStatusTransition)
+	}
+	assert.ErrorIs(t, service.UpdateSmartChequeStatus(ctx, "sc-1", models.SmartChequeStatusLocked), ErrInvalidStatusTransition)
+
+	assert.Empty(t, smartCheque.EscrowAddress)
+	assert.Equal(t, models.SmartChequeStatusCreated, smartCheque.Status)
+	mockRepo.AssertNotCalled(t, "UpdateSmartCheque", mock.Anything, mock.Anything)
+}
+
+func TestSmartChequeService_DeleteSmartChequeOnlyBeforeFunding(t *testing.T) {
+	mockRepo := &mocks.SmartChequeRepositoryInterface{}
+	service := NewSmartChequeService(mockRepo, &mocks.AuditRepositoryInterface{})
+	ctx := context.Background()
+
+	smartCheque := &models.SmartCheque{ID: "sc-1", PayerID: "payer", PayeeID: "payee", Status: models.SmartChequeStatusLocked, EscrowAddress: "rPayer"}
+	mockRepo.On("GetSmartChequeByID", ctx, "sc-1").Return(smartCheque, nil)
+	mockRepo.On("DeleteSmartCheque", ctx, "sc-1").Return(nil)
+
+	// The record of a funded smart check is what settles its escrow
+	assert.ErrorIs(t, service.DeleteSmartCheque(ctx, "sc-1"), ErrSmartChequeLocked)
+	mockRepo.AssertNotCalled(t, "DeleteSmartCheque", ctx, "sc-1")
+
+	smartCheque.Status = models.SmartChequeStatusCreated
+	require.NoError(t, service.DeleteSmartCheque(ctx, "sc-1"))
+	mockRepo.AssertCalled(t, "DeleteSmartCheque", ctx, "sc-1")
+}
+
+func TestSmartChequeService_UpdateSmartChequeStatusUsesStateMachine(t *testing.T) {
+	mockRepo := &mocks.SmartChequeRepositoryInterface{}
+	history := &memoryTransitionHistory{}
+	stateMachine := NewSmartChequeStateMachine(nil)
+	stateMachine.SetHistory(history)
+	service := NewSmartChequeServiceWithStateMachine(mockRepo, &mocks.AuditRepositoryInterface{}, stateMachine)
+	ctx := context.Background()
+
+	smartCheque := escrowedSmartCheque(models.SmartChequeStatusLocked, time.Now())
+	mockRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	mockRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+
+	// Funds still escrowed keep the smart check from completing
+	err := service.UpdateSmartChequeStatus(ctx, smartCheque.ID, models.SmartChequeStatusCompleted)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	// Cancelling has to return the escrowed funds, which a plain status update cannot do
+	err = service.UpdateSmartChequeStatus(ctx, smartCheque.ID, models.SmartChequeStatusCancelled)
+	assert.Error(t, err)
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	assert.Empty(t, history.transitions)
+
+	require.NoError(t, service.UpdateSmartChequeStatus(ctx, smartCheque.ID, models.SmartChequeStatusInProgress))
+	assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+	require.Len(t, history.transitions, 1)
+	assert.Equal(t, models.SmartChequeStatusLocked, history.transitions[0].FromStatus)
+	assert.Equal(t, models.SmartChequeStatusInProgress, history.transitions[0].ToStatus)
+
+	// A transition the history cannot record leaves the status unchanged
+	history.err = errors.New("database unavailable")
+	err = service.UpdateSmartChequeStatus(ctx, smartCheque.ID, models.SmartChequeStatusDisputed)
+	assert.ErrorIs(t, err, history.err)
+	assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+}
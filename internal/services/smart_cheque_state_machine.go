@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// ErrInvalidStatusTransition is returned for a status change the Smart Check state machine does not allow
+var ErrInvalidStatusTransition = errors.New("invalid smart check status transition")
+
+// SmartChequeTransitionEffect is work a status transition carries out beyond changing the status
+type SmartChequeTransitionEffect string
+
+const (
+	// SmartChequeEffectCancelEscrows cancels the escrows whose CancelAfter has passed, returning
+	// their funds to the payer
+	SmartChequeEffectCancelEscrows SmartChequeTransitionEffect = "cancel_escrows"
+	// SmartChequeEffectReleaseFunds releases the escrows of verified milestones to the payee
+	SmartChequeEffectReleaseFunds SmartChequeTransitionEffect = "release_funds"
+	// SmartChequeEffectNotify announces the transition to the Smart Check's parties
+	SmartChequeEffectNotify SmartChequeTransitionEffect = "notify"
+)
+
+// SmartChequeTransitionEffects moves the funds a transition's effects call for. Effects work on
+// the Smart Check in memory; whoever requested the transition saves it.
+type SmartChequeTransitionEffects interface {
+	CancelEscrows(ctx context.Context, smartCheque *models.SmartCheque, transition *models.SmartChequeStatusTransition) error
+	ReleaseFunds(ctx context.Context, smartCheque *models.SmartCheque, transition *models.SmartChequeStatusTransition) error
+}
+
+// SmartChequeTransitionGuard rejects a transition the Smart Check is not in a state to make at the given time
+type SmartChequeTransitionGuard func(smartCheque *models.SmartCheque, at time.Time) error
+
+// smartChequeTransition is a row of the transition table
+type smartChequeTransition struct {
+	guards  []SmartChequeTransitionGuard
+	effects []SmartChequeTransitionEffect
+}
+
+var (
+	notifyParties    = []SmartChequeTransitionEffect{SmartChequeEffectNotify}
+	cancelAndNotify  = []SmartChequeTransitionEffect{SmartChequeEffectCancelEscrows, SmartChequeEffectNotify}
+	releaseAndNotify = []SmartChequeTransitionEffect{SmartChequeEffectReleaseFunds, SmartChequeEffectNotify}
+)
+
+// smartChequeTransitions is every status change a Smart Check may make, keyed by source and destination
+var smartChequeTransitions = map[models.SmartChequeStatus]map[models.SmartChequeStatus]smartChequeTransition{
+	models.SmartChequeStatusCreated: {
+		models.SmartChequeStatusLocked:     {guards: []SmartChequeTransitionGuard{guardFunded}},
+		models.SmartChequeStatusInProgress: {},
+		models.SmartChequeStatusCompleted:  {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:   {effects: notifyParties},
+		models.SmartChequeStatusCancelled:  {effects: notifyParties},
+		models.SmartChequeStatusFrozen:     {effects: notifyParties},
+	},
+	models.SmartChequeStatusLocked: {
+		models.SmartChequeStatusInProgress:    {},
+		models.SmartChequeStatusCompleted:     {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusPartiallyPaid: {guards: []SmartChequeTransitionGuard{guardNothingEscrowed, guardSomethingPaid}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:      {effects: notifyParties},
+		models.SmartChequeStatusCancelled:     {effects: cancelAndNotify},
+		models.SmartChequeStatusExpired:       {guards: []SmartChequeTransitionGuard{guardEscrowLapsed}, effects: cancelAndNotify},
+		models.SmartChequeStatusRefunded:      {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusFrozen:        {effects: notifyParties},
+	},
+	models.SmartChequeStatusInProgress: {
+		models.SmartChequeStatusCompleted:     {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusPartiallyPaid: {guards: []SmartChequeTransitionGuard{guardNothingEscrowed, guardSomethingPaid}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:      {effects: notifyParties},
+		models.SmartChequeStatusCancelled:     {effects: cancelAndNotify},
+		models.SmartChequeStatusExpired:       {guards: []SmartChequeTransitionGuard{guardEscrowLapsed}, effects: cancelAndNotify},
+		models.SmartChequeStatusRefunded:      {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusFrozen:        {effects: notifyParties},
+	},
+	models.SmartChequeStatusCompleted: {
+		models.SmartChequeStatusDisputed: {effects: notifyParties},
+	},
+	models.SmartChequeStatusDisputed: {
+		models.SmartChequeStatusInProgress:    {},
+		models.SmartChequeStatusCompleted:     {guards: []SmartChequeTransitionGuard{guardMilestonesVerified}, effects: releaseAndNotify},
+		models.SmartChequeStatusPartiallyPaid: {guards: []SmartChequeTransitionGuard{guardNothingEscrowed, guardSomethingPaid}, effects: notifyParties},
+		models.SmartChequeStatusCancelled:     {effects: cancelAndNotify},
+		models.SmartChequeStatusExpired:       {guards: []SmartChequeTransitionGuard{guardEscrowLapsed}, effects: cancelAndNotify},
+		models.SmartChequeStatusRefunded:      {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusFrozen:        {effects: notifyParties},
+	},
+	models.SmartChequeStatusPartiallyPaid: {
+		models.SmartChequeStatusDisputed: {effects: notifyParties},
+	},
+	models.SmartChequeStatusCancelled: {
+		// Nothing is released once a Smart Check is called off, so only milestones paid before then
+		// leave it partially paid
+		models.SmartChequeStatusPartiallyPaid: {guards: []SmartChequeTransitionGuard{guardNothingEscrowed, guardSomethingPaid}, effects: notifyParties},
+		models.SmartChequeStatusRefunded:      {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:      {effects: notifyParties},
+		models.SmartChequeStatusFrozen:        {effects: notifyParties},
+	},
+	models.SmartChequeStatusExpired: {
+		models.SmartChequeStatusCompleted:     {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusPartiallyPaid: {guards: []SmartChequeTransitionGuard{guardNothingEscrowed, guardSomethingPaid}, effects: notifyParties},
+		models.SmartChequeStatusRefunded:      {guards: []SmartChequeTransitionGuard{guardNothingEscrowed}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:      {effects: notifyParties},
+		models.SmartChequeStatusFrozen:        {effects: notifyParties},
+	},
+	models.SmartChequeStatusFrozen: {
+		models.SmartChequeStatusLocked:     {guards: []SmartChequeTransitionGuard{guardEscrowsAgree(models.SmartChequeStatusLocked)}, effects: notifyParties},
+		models.SmartChequeStatusInProgress: {guards: []SmartChequeTransitionGuard{guardEscrowsAgree(models.SmartChequeStatusInProgress)}, effects: notifyParties},
+		models.SmartChequeStatusDisputed:   {effects: notifyParties},
+		models.SmartChequeStatusCancelled:  {effects: cancelAndNotify},
+		models.SmartChequeStatusExpired:    {guards: []SmartChequeTransitionGuard{guardEscrowLapsed}, effects: cancelAndNotify},
+	},
+}
+
+// ValidateSmartChequeTransition checks the transition table for a move from one status to another,
+// without the guards that depend on the Smart Check itself
+func ValidateSmartChequeTransition(from, to models.SmartChequeStatus) error {
+	_, err := lookupSmartChequeTransition(from, to)
+	return err
+}
+
+func lookupSmartChequeTransition(from, to models.SmartChequeStatus) (smartChequeTransition, error) {
+	if !to.IsValid() {
+		return smartChequeTransition{}, fmt.Errorf("%w: unknown status %s", ErrInvalidStatusTransition, to)
+	}
+	transition, ok := smartChequeTransitions[from][to]
+	if !ok {
+		return smartChequeTransition{}, fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, from, to)
+	}
+	return transition, nil
+}
+
+// guardFunded requires an escrow or check to hold the Smart Check's funds
+func guardFunded(smartCheque *models.SmartCheque, _ time.Time) error {
+	if smartCheque.EscrowAddress == "" && smartCheque.CheckID == "" {
+		return fmt.Errorf("smart check has no escrow or check holding its funds")
+	}
+	return nil
+}
+
+// guardNothingEscrowed requires every milestone escrow to be finished or cancelled
+func guardNothingEscrowed(smartCheque *models.SmartCheque, _ time.Time) error {
+	if active := smartCheque.ActiveMilestoneEscrows(); len(active) > 0 {
+		return fmt.Errorf("%d milestone escrows still hold funds", len(active))
+	}
+	return nil
+}
+
+// guardSomethingPaid requires a milestone to have been paid out
+func guardSomethingPaid(smartCheque *models.SmartCheque, _ time.Time) error {
+	if !smartCheque.HasPaidMilestones() {
+		return fmt.Errorf("no milestone was paid out")
+	}
+	return nil
+}
+
+// guardMilestonesVerified requires every milestone to be verified
+func guardMilestonesVerified(smartCheque *models.SmartCheque, _ time.Time) error {
+	for _, milestone := range smartCheque.Milestones {
+		if milestone.Status != models.MilestoneStatusVerified {
+			return fmt.Errorf("milestone %s is not verified", milestone.ID)
+		}
+	}
+	return nil
+}
+
+// guardEscrowLapsed requires a milestone escrow to have passed its CancelAfter. A Smart Check
+// funded by a single escrow keeps no CancelAfter and expires on request.
+func guardEscrowLapsed(smartCheque *models.SmartCheque, at time.Time) error {
+	if !smartCheque.HasMilestoneEscrows() {
+		return nil
+	}
+	for _, escrow := range smartCheque.ActiveMilestoneEscrows() {
+		if escrowLapsed(escrow, at) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no milestone escrow has passed its CancelAfter")
+}
+
+// guardEscrowsAgree requires the milestone escrows to put the Smart Check in status, so that
+// unfreezing returns it to where the ledger left it
+func guardEscrowsAgree(status models.SmartChequeStatus) SmartChequeTransitionGuard {
+	return func(smartCheque *models.SmartCheque, _ time.Time) error {
+		if derived, ok := smartCheque.StatusFromMilestoneEscrows(); ok && derived != status {
+			return fmt.Errorf("milestone escrows put the smart check in %s", derived)
+		}
+		return nil
+	}
+}
+
+// escrowLapsed reports whether the ledger lets the payer cancel an escrow at the given time
+func escrowLapsed(escrow *models.MilestoneEscrow, at time.Time) bool {
+	return escrow.CancelAfter != 0 && at.After(xrpl.FromRippleTime(escrow.CancelAfter))
+}
+
+// SmartChequeStateMachine moves Smart Checks between statuses by the transition table, checking
+// each transition's guards, carrying out its effects and recording it in the status history
+type SmartChequeStateMachine struct {
+	effects  SmartChequeTransitionEffects
+	history  repository.SmartChequeTransitionRepositoryInterface
+	eventBus messaging.EventBus
+	now      func() time.Time
+}
+
+// NewSmartChequeStateMachine creates a state machine whose escrow effects are carried out by
+// effects. History and notifications are skipped until configured.
+func NewSmartChequeStateMachine(effects SmartChequeTransitionEffects) *SmartChequeStateMachine {
+	return &SmartChequeStateMachine{effects: effects, now: time.Now}
+}
+
+// SetHistory records every transition in history
+func (m *SmartChequeStateMachine) SetHistory(history repository.SmartChequeTransitionRepositoryInterface) {
+	m.history = history
+}
+
+// SetEventBus announces transitions that notify the parties on eventBus
+func (m *SmartChequeStateMachine) SetEventBus(eventBus messaging.EventBus) {
+	m.eventBus = eventBus
+}
+
+// Transition moves the Smart Check to status to for reason, carrying out the transition's effects.
+// The Smart Check keeps its status when a guard, an effect or recording the transition fails;
+// staying in the same status is not a transition and does nothing.
+func (m *SmartChequeStateMachine) Transition(ctx context.Context, smartCheque *models.SmartCheque, to models.SmartChequeStatus, reason string, metadata map[string]interface{}) error {
+	from := smartCheque.Status
+	if from == to {
+		return nil
+	}
+
+	transition, err := lookupSmartChequeTransition(from, to)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	for _, guard := range transition.guards {
+		if err := guard(smartCheque, now); err != nil {
+			return fmt.Errorf("%w from %s to %s: %v", ErrInvalidStatusTransition, from, to, err)
+		}
+	}
+
+	record := models.NewSmartChequeStatusTransition(smartCheque, to, reason)
+	for key, value := range metadata {
+		record.Metadata[key] = value
+	}
+
+	var notify bool
+	for _, effect := range transition.effects {
+		switch effect {
+		case SmartChequeEffectCancelEscrows:
+			err = m.escrowEffects().CancelEscrows(ctx, smartCheque, record)
+		case SmartChequeEffectReleaseFunds:
+			err = m.escrowEffects().ReleaseFunds(ctx, smartCheque, record)
+		case SmartChequeEffectNotify:
+			notify = true
+		}
+		if err != nil {
+			return fmt.Errorf("failed to move smart check %s from %s to %s: %w", smartCheque.ID, from, to, err)
+		}
+	}
+
+	record.CreatedAt = now
+	if m.history != nil {
+		if err := m.history.CreateStatusTransition(ctx, record); err != nil {
+			return fmt.Errorf("failed to record smart check %s moving from %s to %s: %w", smartCheque.ID, from, to, err)
+		}
+	}
+	smartCheque.Status = to
+	smartCheque.UpdatedAt = now
+	if notify && m.eventBus != nil {
+		event := messaging.NewSmartChequeStatusChangedEvent(smartCheque.ID, smartCheque.PayerID, smartCheque.PayeeID, string(from), string(to), reason)
+		if err := m.eventBus.PublishEvent(ctx, event); err != nil {
+			log.Printf("Warning: Failed to announce status change of Smart Check %s: %v", smartCheque.ID, err)
+		}
+	}
+
+	log.Printf("Smart Check %s moved from %s to %s: %s", smartCheque.ID, from, to, reason)
+	return nil
+}
+
+// GetHistory returns the Smart Check's recorded transitions, oldest first
+func (m *SmartChequeStateMachine) GetHistory(ctx context.Context, smartChequeID string) ([]*models.SmartChequeStatusTransition, error) {
+	if m.history == nil {
+		return nil, fmt.Errorf("status history is not recorded")
+	}
+	return m.history.GetStatusTransitions(ctx, smartChequeID)
+}
+
+// escrowEffects returns the effects implementation, or one that refuses to move funds when none is configured
+func (m *SmartChequeStateMachine) escrowEffects() SmartChequeTransitionEffects {
+	if m.effects == nil {
+		return noEscrowEffects{}
+	}
+	return m.effects
+}
+
+// noEscrowEffects refuses every effect that moves funds
+type noEscrowEffects struct{}
+
+func (noEscrowEffects) CancelEscrows(context.Context, *models.SmartCheque, *models.SmartChequeStatusTransition) error {
+	return fmt.Errorf("no escrow effects configured to cancel escrows")
+}
+
+func (noEscrowEffects) ReleaseFunds(context.Context, *models.SmartCheque, *models.SmartChequeStatusTransition) error {
+	return fmt.Errorf("no escrow effects configured to release funds")
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// recordingEffects resolves milestone escrows in memory the way the XRPL service would
+type recordingEffects struct {
+	cancelled []string
+	released  []string
+	err       error
+}
+
+func (e *recordingEffects) CancelEscrows(_ context.Context, smartCheque *models.SmartCheque, transition *models.SmartChequeStatusTransition) error {
+	if e.err != nil {
+		return e.err
+	}
+	for _, milestone := range smartCheque.Milestones {
+		if milestone.Escrow != nil && milestone.Escrow.Status == models.MilestoneEscrowStatusActive {
+			smartCheque.ResolveMilestoneEscrow(milestone.ID, models.MilestoneEscrowStatusCancelled, "cancel_"+milestone.ID, time.Now())
+			e.cancelled = append(e.cancelled, transition.Reason)
+		}
+	}
+	return nil
+}
+
+func (e *recordingEffects) ReleaseFunds(_ context.Context, smartCheque *models.SmartCheque, _ *models.SmartChequeStatusTransition) error {
+	for _, milestone := range smartCheque.Milestones {
+		if milestone.Escrow != nil && milestone.Escrow.Status == models.MilestoneEscrowStatusActive {
+			smartCheque.ResolveMilestoneEscrow(milestone.ID, models.MilestoneEscrowStatusFinished, "finish_"+milestone.ID, time.Now())
+			e.released = append(e.released, milestone.ID)
+		}
+	}
+	return nil
+}
+
+// memoryTransitionHistory keeps recorded transitions in memory
+type memoryTransitionHistory struct {
+	transitions []*models.SmartChequeStatusTransition
+	err         error
+}
+
+func (h *memoryTransitionHistory) CreateStatusTransition(_ context.Context, transition *models.SmartChequeStatusTransition) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.transitions = append(h.transitions, transition)
+	return nil
+}
+
+func (h *memoryTransitionHistory) GetStatusTransitions(_ context.Context, smartChequeID string) ([]*models.SmartChequeStatusTransition, error) {
+	var transitions []*models.SmartChequeStatusTransition
+	for _, transition := range h.transitions {
+		if transition.SmartChequeID == smartChequeID {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions, nil
+}
+
+// escrowedSmartCheque has two milestones with escrows that lapse an hour after ledgerNow
+func escrowedSmartCheque(status models.SmartChequeStatus, ledgerNow time.Time) *models.SmartCheque {
+	cancelAfter := xrpl.ToRippleTime(ledgerNow.Add(time.Hour))
+	smartCheque := &models.SmartCheque{
+		ID:            uuid.New().String(),
+		PayerID:       uuid.New().String(),
+		PayeeID:       uuid.New().String(),
+		Amount:        models.MustParseMoney("30", models.CurrencyXRP),
+		Currency:      models.CurrencyXRP,
+		Status:        status,
+		EscrowAddress: "rPayer",
+	}
+	for i, amount := range []string{"10", "20"} {
+		smartCheque.Milestones = append(smartCheque.Milestones, models.Milestone{
+			ID:     uuid.New().String(),
+			Amount: models.MustParseMoney(amount, models.CurrencyXRP),
+			Status: models.MilestoneStatusPending,
+			Escrow: &models.MilestoneEscrow{
+				Owner:         "rPayer",
+				Destination:   "rPayee",
+				OfferSequence: uint32(10 + i),
+				CancelAfter:   cancelAfter,
+				Status:        models.MilestoneEscrowStatusActive,
+			},
+		})
+	}
+	return smartCheque
+}
+
+func TestValidateSmartChequeTransition(t *testing.T) {
+	tests := []struct {
+		from, to models.SmartChequeStatus
+		allowed  bool
+	}{
+		{models.SmartChequeStatusCreated, models.SmartChequeStatusLocked, true},
+		{models.SmartChequeStatusLocked, models.SmartChequeStatusInProgress, true},
+		{models.SmartChequeStatusInProgress, models.SmartChequeStatusCompleted, true},
+		{models.SmartChequeStatusInProgress, models.SmartChequeStatusPartiallyPaid, true},
+		{models.SmartChequeStatusLocked, models.SmartChequeStatusCancelled, true},
+		{models.SmartChequeStatusInProgress, models.SmartChequeStatusExpired, true},
+		{models.SmartChequeStatusCancelled, models.SmartChequeStatusRefunded, true},
+		{models.SmartChequeStatusExpired, models.SmartChequeStatusPartiallyPaid, true},
+		{models.SmartChequeStatusDisputed, models.SmartChequeStatusFrozen, true},
+		{models.SmartChequeStatusFrozen, models.SmartChequeStatusInProgress, true},
+		{models.SmartChequeStatusCompleted, models.SmartChequeStatusDisputed, true},
+
+		{models.SmartChequeStatusCreated, models.SmartChequeStatusRefunded, false},
+		{models.SmartChequeStatusLocked, models.SmartChequeStatusCreated, false},
+		{models.SmartChequeStatusCompleted, models.SmartChequeStatusInProgress, false},
+		{models.SmartChequeStatusCompleted, models.SmartChequeStatusCancelled, false},
+		{models.SmartChequeStatusRefunded, models.SmartChequeStatusLocked, false},
+		{models.SmartChequeStatusRefunded, models.SmartChequeStatusDisputed, false},
+		{models.SmartChequeStatusFrozen, models.SmartChequeStatusCompleted, false},
+		{models.SmartChequeStatusCancelled, models.SmartChequeStatusInProgress, false},
+		{models.SmartChequeStatusCancelled, models.SmartChequeStatusCompleted, false},
+		{models.SmartChequeStatusLocked, models.SmartChequeStatus("archived"), false},
+		{models.SmartChequeStatus("archived"), models.SmartChequeStatusLocked, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := ValidateSmartChequeTransition(tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+			}
+		})
+	}
+}
+
+func TestSmartChequeStateMachine_Guards(t *testing.T) {
+	ledgerNow := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	machine := NewSmartChequeStateMachine(&recordingEffects{})
+	machine.now = func() time.Time { return ledgerNow }
+	ctx := context.Background()
+
+	// Funds still escrowed keep a Smart Check from settling
+	smartCheque := escrowedSmartCheque(models.SmartChequeStatusInProgress, ledgerNow)
+	err := machine.Transition(ctx, smartCheque, models.SmartChequeStatusCompleted, "done", nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	err = machine.Transition(ctx, smartCheque, models.SmartChequeStatusRefunded, "refund", nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+
+	// Nothing expires before its CancelAfter
+	err = machine.Transition(ctx, smartCheque, models.SmartChequeStatusExpired, CancellationReasonExpired, nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	// Partially paid needs a milestone to have been paid out
+	for _, milestone := range smartCheque.Milestones {
+		smartCheque.ResolveMilestoneEscrow(milestone.ID, models.MilestoneEscrowStatusCancelled, "cancel", ledgerNow)
+	}
+	err = machine.Transition(ctx, smartCheque, models.SmartChequeStatusPartiallyPaid, "settled", nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	require.NoError(t, machine.Transition(ctx, smartCheque, models.SmartChequeStatusRefunded, "settled", nil))
+
+	// A frozen Smart Check only unfreezes to the status its escrows agree with
+	frozen := escrowedSmartCheque(models.SmartChequeStatusFrozen, ledgerNow)
+	err = machine.Transition(ctx, frozen, models.SmartChequeStatusInProgress, "review cleared", nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	require.NoError(t, machine.Transition(ctx, frozen, models.SmartChequeStatusLocked, "review cleared", nil))
+}
+
+func TestSmartChequeStateMachine_EffectsAndHistory(t *testing.T) {
+	ledgerNow := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	effects := &recordingEffects{}
+	history := &memoryTransitionHistory{}
+	eventBus := &TestMockEventBus{}
+	eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+
+	machine := NewSmartChequeStateMachine(effects)
+	machine.SetHistory(history)
+	machine.SetEventBus(eventBus)
+	machine.now = func() time.Time { return ledgerNow.Add(2 * time.Hour) }
+	ctx := context.Background()
+
+	smartCheque := escrowedSmartCheque(models.SmartChequeStatusLocked, ledgerNow)
+	require.NoError(t, machine.Transition(ctx, smartCheque, models.SmartChequeStatusExpired, CancellationReasonExpired, map[string]interface{}{"notes": "lapsed"}))
+	assert.Equal(t, models.SmartChequeStatusExpired, smartCheque.Status)
+	assert.Equal(t, []string{CancellationReasonExpired, CancellationReasonExpired}, effects.cancelled)
+	require.NoError(t, machine.Transition(ctx, smartCheque, models.SmartChequeStatusRefunded, "escrows cancelled", nil))
+
+	// Staying put is not a transition
+	require.NoError(t, machine.Transition(ctx, smartCheque, models.SmartChequeStatusRefunded, "again", nil))
+
+	recorded, err := machine.GetHistory(ctx, smartCheque.ID)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, models.SmartChequeStatusLocked, recorded[0].FromStatus)
+	assert.Equal(t, models.SmartChequeStatusExpired, recorded[0].ToStatus)
+	assert.Equal(t, "lapsed", recorded[0].Metadata["notes"])
+	assert.Equal(t, models.SmartChequeStatusRefunded, recorded[1].ToStatus)
+
+	events := eventBus.GetPublishedEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, messaging.EventTypeSmartChequeStatusChanged, events[1].Type)
+	assert.Equal(t, string(models.SmartChequeStatusRefunded), events[1].Data["to_status"])
+
+	// Resolving a dispute in the payee's favour releases the verified milestones
+	disputed := escrowedSmartCheque(models.SmartChequeStatusDisputed, ledgerNow)
+	err = machine.Transition(ctx, disputed, models.SmartChequeStatusCompleted, "resolved", nil)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	for i := range disputed.Milestones {
+		disputed.Milestones[i].Status = models.MilestoneStatusVerified
+	}
+	require.NoError(t, machine.Transition(ctx, disputed, models.SmartChequeStatusCompleted, "resolved", nil))
+	assert.Len(t, effects.released, 2)
+
+	// A failing effect leaves the status and the history untouched
+	effects.err = errors.New("ledger unavailable")
+	failing := escrowedSmartCheque(models.SmartChequeStatusInProgress, ledgerNow)
+	err = machine.Transition(ctx, failing, models.SmartChequeStatusCancelled, CancellationReasonMutualAgreement, nil)
+	assert.Error(t, err)
+	assert.Equal(t, models.SmartChequeStatusInProgress, failing.Status)
+	recorded, err = machine.GetHistory(ctx, failing.ID)
+	require.NoError(t, err)
+	assert.Empty(t, recorded)
+
+	// So does a transition the history cannot record
+	effects.err = nil
+	history.err = errors.New("database unavailable")
+	unrecorded := escrowedSmartCheque(models.SmartChequeStatusLocked, ledgerNow)
+	err = machine.Transition(ctx, unrecorded, models.SmartChequeStatusInProgress, "work started", nil)
+	assert.ErrorIs(t, err, history.err)
+	assert.Equal(t, models.SmartChequeStatusLocked, unrecorded.Status)
+}
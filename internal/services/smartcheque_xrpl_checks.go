@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// A Smart Check settled by XRPL Check leaves the funds with the payer until the payee cashes it:
+// the ledger only records the payer's authorization to pull up to the Smart Check amount.
+
+// checkReference identifies the XRPL Check written for a Smart Check from its CheckCreate record
+type checkReference struct {
+	payer     string
+	payee     string
+	createdIn uint32
+}
+
+// checkLedgerState is what the validated ledger says about a Smart Check's XRPL Check: the check
+// while it can still be cashed, or the transaction that cashed or cancelled it
+type checkLedgerState struct {
+	reference  checkReference
+	check      *xrpl.CheckInfo
+	resolution *xrpl.CheckResolution
+}
+
+// IssueCheckForSmartCheque writes an XRPL Check the payee can cash for the Smart Check amount until
+// validFor has passed; a zero validFor issues a check that never expires
+func (s *smartChequeXRPLService) IssueCheckForSmartCheque(ctx context.Context, smartChequeID, payerWalletAddress, payeeWalletAddress string, validFor time.Duration) error {
+	smartCheque, err := s.checkSmartCheque(ctx, smartChequeID)
+	if err != nil {
+		return err
+	}
+	if smartCheque.CheckID != "" {
+		return fmt.Errorf("smart check %s already has XRPL check %s", smartChequeID, smartCheque.CheckID)
+	}
+
+	if !s.xrplService.ValidateAddress(payerWalletAddress) {
+		return fmt.Errorf("invalid payer wallet address: %s", payerWalletAddress)
+	}
+	if !s.xrplService.ValidateAddress(payeeWalletAddress) {
+		return fmt.Errorf("invalid payee wallet address: %s", payeeWalletAddress)
+	}
+
+	// The invoice ID ties the check on the ledger back to the Smart Check
+	invoice := sha256.Sum256([]byte(smartChequeID))
+	invoiceID := strings.ToUpper(hex.EncodeToString(invoice[:]))
+
+	result, err := s.xrplService.CreateSmartChequeCheck(
+		payerWalletAddress,
+		payeeWalletAddress,
+		smartCheque.Amount.WithCurrency(smartCheque.Currency),
+		validFor,
+		invoiceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create XRPL check: %w", err)
+	}
+
+	checkID, err := xrpl.CheckIndex(payerWalletAddress, result.Sequence)
+	if err != nil {
+		return fmt.Errorf("check %s created but its ID could not be derived: %w", result.TransactionID, err)
+	}
+
+	smartCheque.CheckID = checkID
+	s.followLedger(ctx, smartCheque, models.SmartChequeStatusLocked, "XRPL check issued")
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check with check info: %w", err)
+	}
+
+	transaction := models.NewTransaction(
+		models.TransactionTypeCheckCreate,
+		payerWalletAddress,
+		payeeWalletAddress,
+		smartCheque.Amount.String(),
+		string(smartCheque.Currency),
+		smartCheque.PayerID,
+		smartCheque.PayerID,
+	)
+	transaction.SmartChequeID = &smartChequeID
+	transaction.TransactionHash = result.TransactionID
+	// The payer and sequence derive the check ID for later cashing, cancellation and status lookups
+	transaction.Sequence = &result.Sequence
+	if result.LedgerIndex > 0 {
+		transaction.LedgerIndex = &result.LedgerIndex
+	}
+	transaction.Metadata = map[string]interface{}{
+		"check_id":   checkID,
+		"invoice_id": invoiceID,
+	}
+	s.recordCheckTransaction(transaction)
+
+	log.Printf("Issued XRPL check %s for Smart Check %s with transaction ID %s", checkID, smartChequeID, result.TransactionID)
+	return nil
+}
+
+// CashSmartChequeCheck cashes the Smart Check's XRPL Check as its payee. An exact cash pays amount or
+// fails; a minimum cash pays as much as the payer can cover, failing below amount. A zero amount
+// stands for the full Smart Check amount.
+func (s *smartChequeXRPLService) CashSmartChequeCheck(ctx context.Context, smartChequeID string, amount models.Money, minimum bool) error {
+	smartCheque, err := s.checkSmartCheque(ctx, smartChequeID)
+	if err != nil {
+		return err
+	}
+	if smartCheque.CheckID == "" {
+		return fmt.Errorf("%w: smart check %s has no XRPL check", ErrCheckNotTracked, smartChequeID)
+	}
+	if smartCheque.Status != models.SmartChequeStatusLocked && smartCheque.Status != models.SmartChequeStatusInProgress {
+		return fmt.Errorf("smart check status %s does not allow cashing its check", smartCheque.Status)
+	}
+	if amount.Sign() <= 0 {
+		amount = smartCheque.Amount
+	}
+	amount, err = amount.Denominate(smartCheque.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid cash amount: %w", err)
+	}
+	exceeds, err := amount.Cmp(smartCheque.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid cash amount: %w", err)
+	}
+	if exceeds > 0 {
+		return fmt.Errorf("cannot cash %s from a smart check of %s", amount, smartCheque.Amount)
+	}
+
+	reference, err := s.checkReference(smartCheque)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.xrplService.CashSmartChequeCheck(reference.payee, smartCheque.CheckID, amount, minimum)
+	if err != nil {
+		return fmt.Errorf("failed to cash XRPL check: %w", err)
+	}
+
+	// What a minimum cash delivered is only known from the validated ledger, so sync settles it
+	if !minimum && exceeds >= 0 {
+		s.followLedger(ctx, smartCheque, models.SmartChequeStatusCompleted, "XRPL check cashed")
+		smartCheque.UpdatedAt = time.Now()
+		if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+			return fmt.Errorf("failed to update smart check: %w", err)
+		}
+	}
+
+	cashMode := "exact"
+	if minimum {
+		cashMode = "minimum"
+	}
+	transaction := models.NewTransaction(
+		models.TransactionTypeCheckCash,
+		reference.payer,
+		reference.payee,
+		amount.String(),
+		string(smartCheque.Currency),
+		smartCheque.PayerID,
+		smartCheque.PayeeID,
+	)
+	transaction.SmartChequeID = &smartChequeID
+	transaction.TransactionHash = result.TransactionID
+	transaction.Metadata = map[string]interface{}{
+		"check_id":  smartCheque.CheckID,
+		"cash_mode": cashMode,
+	}
+	s.recordCheckTransaction(transaction)
+
+	log.Printf("Cashed XRPL check %s for Smart Check %s (%s %s) with transaction ID %s",
+		smartCheque.CheckID, smartChequeID, cashMode, amount, result.TransactionID)
+	return nil
+}
+
+// CancelSmartChequeCheck cancels the Smart Check's XRPL Check as its payer; nothing moves since
+// the funds never left the payer's account
+func (s *smartChequeXRPLService) CancelSmartChequeCheck(ctx context.Context, smartChequeID, reason, notes string) error {
+	smartCheque, err := s.checkSmartCheque(ctx, smartChequeID)
+	if err != nil {
+		return err
+	}
+	if smartCheque.CheckID == "" {
+		return fmt.Errorf("%w: smart check %s has no XRPL check", ErrCheckNotTracked, smartChequeID)
+	}
+	if err := s.validateEscrowCancellation(smartCheque); err != nil {
+		return fmt.Errorf("check cancellation validation failed: %w", err)
+	}
+
+	reference, err := s.checkReference(smartCheque)
+	if err != nil {
+		return err
+	}
+	return s.cancelCheck(ctx, smartCheque, reference, reason, notes)
+}
+
+// cancelCheck cancels a Smart Check's XRPL Check and records the outcome
+func (s *smartChequeXRPLService) cancelCheck(ctx context.Context, smartCheque *models.SmartCheque, reference checkReference, reason, notes string) error {
+	result, err := s.xrplService.CancelSmartChequeCheck(reference.payer, smartCheque.CheckID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel XRPL check: %w", err)
+	}
+
+	s.followLedger(ctx, smartCheque, s.determineStatusAfterCancellation(smartCheque, reason), reason)
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+
+	transaction := models.NewTransaction(
+		models.TransactionTypeCheckCancel,
+		reference.payer,
+		reference.payee,
+		smartCheque.Amount.String(),
+		string(smartCheque.Currency),
+		smartCheque.PayerID,
+		smartCheque.PayerID,
+	)
+	transaction.SmartChequeID = &smartCheque.ID
+	transaction.TransactionHash = result.TransactionID
+	transaction.Metadata = map[string]interface{}{
+		"check_id":            smartCheque.CheckID,
+		"cancellation_reason": reason,
+		"cancellation_notes":  notes,
+	}
+	s.recordCheckTransaction(transaction)
+
+	log.Printf("Cancelled XRPL check %s for Smart Check %s with reason '%s', transaction ID: %s",
+		smartCheque.CheckID, smartCheque.ID, reason, result.TransactionID)
+	return nil
+}
+
+// PaySmartChequeDirect settles a direct Smart Check with a single payment of its full amount
+func (s *smartChequeXRPLService) PaySmartChequeDirect(ctx context.Context, smartChequeID, payerWalletAddress, payeeWalletAddress string) error {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, smartChequeID)
+	if err != nil {
+		return fmt.Errorf("failed to get smart check: %w", err)
+	}
+	if smartCheque == nil {
+		return fmt.Errorf("smart check not found: %s", smartChequeID)
+	}
+	if smartCheque.Settlement() != models.SettlementModeDirect {
+		return fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
+	if smartCheque.Status == models.SmartChequeStatusCompleted {
+		return fmt.Errorf("smart check %s is already paid", smartChequeID)
+	}
+	if !releasable(smartCheque.Status) {
+		return fmt.Errorf("%w: smart check %s is %s", ErrInvalidStatusTransition, smartChequeID, smartCheque.Status)
+	}
+
+	if !s.xrplService.ValidateAddress(payerWalletAddress) {
+		return fmt.Errorf("invalid payer wallet address: %s", payerWalletAddress)
+	}
+	if !s.xrplService.ValidateAddress(payeeWalletAddress) {
+		return fmt.Errorf("invalid payee wallet address: %s", payeeWalletAddress)
+	}
+
+	result, err := s.xrplService.SendPayment(payerWalletAddress, payeeWalletAddress, smartCheque.Amount.WithCurrency(smartCheque.Currency))
+	if err != nil {
+		return fmt.Errorf("failed to pay smart check: %w", err)
+	}
+
+	s.followLedger(ctx, smartCheque, models.SmartChequeStatusCompleted, "paid directly")
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+
+	transaction := models.NewTransaction(
+		models.TransactionTypePayment,
+		payerWalletAddress,
+		payeeWalletAddress,
+		smartCheque.Amount.String(),
+		string(smartCheque.Currency),
+		smartCheque.PayerID,
+		smartCheque.PayerID,
+	)
+	transaction.SmartChequeID = &smartChequeID
+	transaction.TransactionHash = result.TransactionID
+	s.recordCheckTransaction(transaction)
+
+	log.Printf("Paid Smart Check %s directly with transaction ID %s", smartChequeID, result.TransactionID)
+	return nil
+}
+
+// checkSmartCheque loads a Smart Check that settles by XRPL Check
+func (s *smartChequeXRPLService) checkSmartCheque(ctx context.Context, smartChequeID string) (*models.SmartCheque, error) {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, smartChequeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart check: %w", err)
+	}
+	if smartCheque == nil {
+		return nil, fmt.Errorf("smart check not found: %s", smartChequeID)
+	}
+	if smartCheque.Settlement() != models.SettlementModeXRPLCheck {
+		return nil, fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
+	return smartCheque, nil
+}
+
+// recordCheckTransaction saves a confirmed transaction record; the ledger stays authoritative if it fails
+func (s *smartChequeXRPLService) recordCheckTransaction(transaction *models.Transaction) {
+	transaction.Status = models.TransactionStatusConfirmed
+	now := time.Now()
+	transaction.ConfirmedAt = &now
+	if err := s.transactionRepo.CreateTransaction(transaction); err != nil {
+		log.Printf("Warning: Failed to save transaction record: %v", err)
+	}
+}
+
+// checkReference finds the CheckCreate record whose payer and sequence derive the Smart Check's check ID
+func (s *smartChequeXRPLService) checkReference(smartCheque *models.SmartCheque) (checkReference, error) {
+	transactions, err := s.transactionRepo.GetTransactionsBySmartChequeID(smartCheque.ID, 100, 0)
+	if err != nil {
+		return checkReference{}, fmt.Errorf("failed to get check transactions: %w", err)
+	}
+
+	for _, tx := range transactions {
+		if tx.Type != models.TransactionTypeCheckCreate || tx.Sequence == nil {
+			continue
+		}
+		checkID, err := xrpl.CheckIndex(tx.FromAddress, *tx.Sequence)
+		if err != nil || checkID != smartCheque.CheckID {
+			continue
+		}
+		reference := checkReference{payer: tx.FromAddress, payee: tx.ToAddress}
+		if tx.LedgerIndex != nil {
+			reference.createdIn = *tx.LedgerIndex
+		}
+		return reference, nil
+	}
+	return checkReference{}, fmt.Errorf("%w: no CheckCreate record for %s", ErrCheckNotTracked, smartCheque.CheckID)
+}
+
+// checkState looks the check up in the validated ledger and, when it is gone, finds the
+// transaction that cashed or cancelled it
+func (s *smartChequeXRPLService) checkState(smartCheque *models.SmartCheque) (*checkLedgerState, error) {
+	reference, err := s.checkReference(smartCheque)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &checkLedgerState{reference: reference}
+	check, err := s.xrplService.GetCheck(smartCheque.CheckID)
+	if err == nil {
+		state.check = check
+		return state, nil
+	}
+	if !errors.Is(err, xrpl.ErrEntryNotFound) {
+		return nil, err
+	}
+
+	resolution, err := s.xrplService.FindCheckResolution(reference.payer, smartCheque.CheckID, reference.createdIn)
+	if err != nil && !errors.Is(err, xrpl.ErrEntryNotFound) {
+		return nil, err
+	}
+	state.resolution = resolution
+	return state, nil
+}
+
+// syncCheckStatus updates a Smart Check from its XRPL Check: an expired check is cancelled, a check
+// cashed for the full amount completes the Smart Check, a short one disputes it and a cancelled one
+// refunds it
+func (s *smartChequeXRPLService) syncCheckStatus(ctx context.Context, smartCheque *models.SmartCheque) error {
+	if smartCheque.CheckID == "" {
+		return fmt.Errorf("%w: smart check %s has no XRPL check", ErrCheckNotTracked, smartCheque.ID)
+	}
+
+	state, err := s.checkState(smartCheque)
+	if err != nil {
+		log.Printf("Warning: Failed to get XRPL check status for Smart Check %s: %v", smartCheque.ID, err)
+		return nil
+	}
+
+	active := smartCheque.Status == models.SmartChequeStatusLocked || smartCheque.Status == models.SmartChequeStatusInProgress
+	if !active {
+		return nil
+	}
+
+	switch {
+	case state.check != nil:
+		// Expiration is in seconds since the Ripple epoch
+		currentTime := time.Since(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).Seconds()
+		if state.check.Expiration == 0 || uint32(currentTime) <= state.check.Expiration {
+			return nil
+		}
+		return s.cancelCheck(ctx, smartCheque, state.reference, CancellationReasonExpired, "check expired before it was cashed")
+	case state.resolution == nil:
+		return nil
+	case state.resolution.Cashed():
+		delivered := models.ZeroMoney(smartCheque.Currency)
+		if state.resolution.DeliveredAmount != nil {
+			if delivered, err = platformAmount(*state.resolution.DeliveredAmount, smartCheque.Currency); err != nil {
+				return err
+			}
+		}
+		cmp, err := delivered.Cmp(smartCheque.Amount)
+		if err != nil {
+			return err
+		}
+		if cmp >= 0 {
+			s.followLedger(ctx, smartCheque, models.SmartChequeStatusCompleted, fmt.Sprintf("check cashed by %s", state.resolution.TransactionID))
+		} else {
+			s.followLedger(ctx, smartCheque, models.SmartChequeStatusDisputed,
+				fmt.Sprintf("check cashed by %s for %s of %s", state.resolution.TransactionID, delivered, smartCheque.Amount))
+		}
+	default:
+		s.followLedger(ctx, smartCheque, models.SmartChequeStatusRefunded, fmt.Sprintf("check cancelled by %s", state.resolution.TransactionID))
+	}
+
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+	return nil
+}
@@ -2,16 +2,33 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
+// ErrEscrowNotTracked is returned when a Smart Check's escrow cannot be identified in the ledger
+var ErrEscrowNotTracked = errors.New("escrow owner and sequence are not recorded")
+
+// ErrCheckNotTracked is returned when a Smart Check's XRPL Check cannot be identified in the ledger
+var ErrCheckNotTracked = errors.New("check payer and sequence are not recorded")
+
+// ErrMilestoneAlreadyReleased is returned when a milestone's payment was already released
+var ErrMilestoneAlreadyReleased = errors.New("milestone payment already released")
+
+// ErrSettlementMode is returned for an operation that does not apply to a Smart Check's settlement mode
+var ErrSettlementMode = errors.New("operation does not apply to the smart check's settlement mode")
+
 // SmartChequeXRPLServiceInterface defines the interface for Smart Check XRPL integration operations
 type SmartChequeXRPLServiceInterface interface {
 	// CreateEscrowForSmartCheque creates an XRPL escrow for a Smart Check
@@ -40,29 +57,65 @@ type SmartChequeXRPLServiceInterface interface {
 
 	// GetXRPLTransactionHistory retrieves XRPL transaction history for a Smart Check
 	GetXRPLTransactionHistory(ctx context.Context, smartChequeID string) ([]*models.Transaction, error)
+
+	// IssueCheckForSmartCheque issues an XRPL Check for a Smart Check settled by check
+	IssueCheckForSmartCheque(ctx context.Context, smartChequeID, payerWalletAddress, payeeWalletAddress string, validFor time.Duration) error
+
+	// CashSmartChequeCheck cashes a Smart Check's XRPL Check for an exact or a minimum amount
+	CashSmartChequeCheck(ctx context.Context, smartChequeID string, amount models.Money, minimum bool) error
+
+	// CancelSmartChequeCheck cancels a Smart Check's XRPL Check with reason and optional notes
+	CancelSmartChequeCheck(ctx context.Context, smartChequeID, reason, notes string) error
+
+	// PaySmartChequeDirect settles a direct Smart Check with a single payment
+	PaySmartChequeDirect(ctx context.Context, smartChequeID, payerWalletAddress, payeeWalletAddress string) error
+
+	// StateMachine returns the state machine that moves Smart Checks between statuses
+	StateMachine() *SmartChequeStateMachine
+
+	// TransitionSmartCheque moves a Smart Check to a new status, carrying out the transition's effects
+	TransitionSmartCheque(ctx context.Context, smartChequeID string, status models.SmartChequeStatus, reason string) error
+
+	// ExpireSmartChequeEscrows cancels the milestone escrows whose CancelAfter has passed and
+	// returns how many cancellations were submitted
+	ExpireSmartChequeEscrows(ctx context.Context, smartChequeID string) (int, error)
+
+	// SubmissionSettled resolves the milestone escrow of a submitted EscrowCancel once the
+	// LedgerSubmissionConfirmer settles it
+	SubmissionSettled(ctx context.Context, transaction *models.Transaction) error
 }
 
 // smartChequeXRPLService implements SmartChequeXRPLServiceInterface
 type smartChequeXRPLService struct {
 	smartChequeRepo repository.SmartChequeRepositoryInterface
-	transactionRepo repository.TransactionRepositoryInterface
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface
 	xrplService     repository.XRPLServiceInterface
 	milestoneRepo   repository.MilestoneRepositoryInterface
+	vault           *FulfillmentVault
+	payments        PaymentExecutionServiceInterface
+	stateMachine    *SmartChequeStateMachine
 }
 
-// NewSmartChequeXRPLService creates a new Smart Check XRPL service
+// NewSmartChequeXRPLService creates a new Smart Check XRPL service. Escrow fulfillments are sealed
+// in the vault when the escrows are funded and only payment execution releases them.
 func NewSmartChequeXRPLService(
 	smartChequeRepo repository.SmartChequeRepositoryInterface,
-	transactionRepo repository.TransactionRepositoryInterface,
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface,
 	xrplService repository.XRPLServiceInterface,
 	milestoneRepo repository.MilestoneRepositoryInterface,
+	vault *FulfillmentVault,
+	payments PaymentExecutionServiceInterface,
 ) SmartChequeXRPLServiceInterface {
-	return &smartChequeXRPLService{
+	service := &smartChequeXRPLService{
 		smartChequeRepo: smartChequeRepo,
 		transactionRepo: transactionRepo,
 		xrplService:     xrplService,
 		milestoneRepo:   milestoneRepo,
+		vault:           vault,
+		payments:        payments,
 	}
+	service.stateMachine = NewSmartChequeStateMachine(service)
+	return service
 }
 
 // CreateEscrowForSmartCheque creates an XRPL escrow for a Smart Check
@@ -75,6 +128,9 @@ func (s *smartChequeXRPLService) CreateEscrowForSmartCheque(ctx context.Context,
 	if smartCheque == nil {
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
+	if smartCheque.Settlement() != models.SettlementModeEscrow {
+		return fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
 
 	// Validate wallet addresses
 	if !s.xrplService.ValidateAddress(payerWalletAddress) {
@@ -84,52 +140,107 @@ func (s *smartChequeXRPLService) CreateEscrowForSmartCheque(ctx context.Context,
 		return fmt.Errorf("invalid payee wallet address: %s", payeeWalletAddress)
 	}
 
-	// Create the XRPL escrow with milestone-based conditions
-	result, fulfillment, err := s.xrplService.CreateSmartChequeEscrowWithMilestones(
+	// Fund each milestone as an escrow of its own. A retry after a partial failure skips the
+	// milestones whose escrow is already on the ledger.
+	var unfunded []models.Milestone
+	for _, milestone := range smartCheque.Milestones {
+		if milestone.Escrow == nil {
+			unfunded = append(unfunded, milestone)
+		}
+	}
+	fundings, fundErr := s.xrplService.CreateSmartChequeEscrowWithMilestones(
 		payerWalletAddress,
 		payeeWalletAddress,
-		smartCheque.Amount,
-		string(smartCheque.Currency),
-		smartCheque.Milestones,
+		smartCheque.Amount.WithCurrency(smartCheque.Currency),
+		unfunded,
 	)
-	if err != nil {
-		return fmt.Errorf("failed to create XRPL escrow: %w", err)
+	if fundErr != nil && len(fundings) == 0 {
+		return fmt.Errorf("failed to create XRPL escrow: %w", fundErr)
 	}
 
-	// Update the Smart Check with escrow information
-	smartCheque.EscrowAddress = result.TransactionID
-	smartCheque.Status = models.SmartChequeStatusLocked
+	// Escrows already on the ledger are recorded even when a later milestone failed to fund
+	var recordErr error
+	for _, funding := range fundings {
+		if err := s.recordMilestoneEscrow(ctx, smartCheque, funding); err != nil && recordErr == nil {
+			recordErr = err
+		}
+	}
+
+	// The payer's account owns every milestone escrow of the Smart Check
+	smartCheque.EscrowAddress = payerWalletAddress
+	if fundErr == nil {
+		s.followLedger(ctx, smartCheque, models.SmartChequeStatusLocked, "milestone escrows funded")
+	}
 	smartCheque.UpdatedAt = time.Now()
 
 	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
 		return fmt.Errorf("failed to update smart check with escrow info: %w", err)
 	}
+	if fundErr != nil {
+		return fmt.Errorf("funded %d milestone escrows before failing: %w", len(fundings), fundErr)
+	}
+	if recordErr != nil {
+		return recordErr
+	}
+
+	log.Printf("Created %d XRPL milestone escrows for Smart Check %s owned by %s", len(fundings), smartChequeID, payerWalletAddress)
+	return nil
+}
+
+// recordMilestoneEscrow maps a funded escrow onto its milestone, seals its fulfillment and records
+// the EscrowCreate that identifies it in the ledger
+func (s *smartChequeXRPLService) recordMilestoneEscrow(ctx context.Context, smartCheque *models.SmartCheque, funding models.MilestoneEscrowFunding) error {
+	var milestone *models.Milestone
+	for i := range smartCheque.Milestones {
+		if smartCheque.Milestones[i].ID == funding.MilestoneID {
+			milestone = &smartCheque.Milestones[i]
+			break
+		}
+	}
+	if milestone == nil {
+		return fmt.Errorf("escrow %s funds unknown milestone %s", funding.Escrow.TransactionID, funding.MilestoneID)
+	}
+	escrow := funding.Escrow
+	milestone.Escrow = &escrow
+
+	// Seal the fulfillment; only payment execution can release it
+	reference := EscrowReference{
+		Owner:         escrow.Owner,
+		Destination:   escrow.Destination,
+		OfferSequence: escrow.OfferSequence,
+	}
+	if err := s.vault.Seal(ctx, smartCheque.ID, milestone.ID, reference, escrow.Condition, funding.Fulfillment, FulfillmentActorEscrowCreation); err != nil {
+		return fmt.Errorf("escrow %s created but its fulfillment could not be sealed: %w", escrow.TransactionID, err)
+	}
 
 	// Create a transaction record for tracking
 	transaction := models.NewTransaction(
 		models.TransactionTypeEscrowCreate,
-		payerWalletAddress,
-		payeeWalletAddress,
-		fmt.Sprintf("%f", smartCheque.Amount),
+		escrow.Owner,
+		escrow.Destination,
+		milestone.Amount.String(),
 		string(smartCheque.Currency),
 		smartCheque.PayerID,
 		smartCheque.PayerID, // Using payer ID as user ID for now
 	)
 
 	// Set XRPL-specific fields
-	transaction.SmartChequeID = &smartChequeID
-	transaction.TransactionHash = result.TransactionID
-	transaction.Fulfillment = fulfillment
-	transaction.Status = models.TransactionStatusConfirmed
-	now := time.Now()
-	transaction.ConfirmedAt = &now
+	transaction.SmartChequeID = &smartCheque.ID
+	transaction.MilestoneID = &milestone.ID
+	transaction.Condition = escrow.Condition
+	// The owner and sequence identify the escrow in the ledger for later status lookups
+	recordLedgerSubmission(transaction, &xrpl.TransactionResult{
+		TransactionID:      escrow.TransactionID,
+		LedgerIndex:        escrow.LedgerIndex,
+		Validated:          funding.Validated,
+		Sequence:           escrow.OfferSequence,
+		LastLedgerSequence: funding.LastLedgerSequence,
+	})
 
 	// Save the transaction
 	if err := s.transactionRepo.CreateTransaction(transaction); err != nil {
 		log.Printf("Warning: Failed to save transaction record: %v", err)
 	}
-
-	log.Printf("Created XRPL escrow for Smart Check %s with transaction ID %s", smartChequeID, result.TransactionID)
 	return nil
 }
 
@@ -143,6 +254,9 @@ func (s *smartChequeXRPLService) CompleteMilestonePayment(ctx context.Context, s
 	if smartCheque == nil {
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
+	if smartCheque.Settlement() != models.SettlementModeEscrow {
+		return fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
 
 	// Check if escrow address exists
 	if smartCheque.EscrowAddress == "" {
@@ -150,63 +264,89 @@ func (s *smartChequeXRPLService) CompleteMilestonePayment(ctx context.Context, s
 	}
 
 	// Find the milestone in the smart check
-	var milestone *models.Milestone
-	for _, m := range smartCheque.Milestones {
-		if m.ID == milestoneID {
-			milestone = &m
-			break
-		}
-	}
-
+	milestone := smartCheque.FindMilestone(milestoneID)
 	if milestone == nil {
 		return fmt.Errorf("milestone not found in smart check: %s", milestoneID)
 	}
-
-	// For now, we'll use a simple approach to complete the milestone
-	// In a real implementation, this would involve more complex logic
-	// including retrieving the fulfillment from the original escrow creation
-
-	// Generate condition and fulfillment (in a real implementation, these would be retrieved)
-	milestoneSecret := fmt.Sprintf("smartcheque_%s_secret_%d", smartChequeID, time.Now().Unix())
-	condition, fulfillment, err := s.xrplService.GenerateCondition(milestoneSecret)
-	if err != nil {
-		return fmt.Errorf("failed to generate condition: %w", err)
-	}
-
-	// Complete the XRPL escrow
-	// Note: This is a simplified implementation. In reality, we would need the actual
-	// sequence number and other details from the original escrow creation
-	result, err := s.xrplService.CompleteSmartChequeMilestone(
-		smartCheque.EscrowAddress, // Using escrow address as payee for this example
-		smartCheque.EscrowAddress, // Using escrow address as owner for this example
-		1,                         // Sequence number - would need to be retrieved from original transaction
-		condition,
-		fulfillment,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to complete XRPL escrow: %w", err)
+	if escrow := milestone.Escrow; escrow != nil {
+		// Only this milestone's escrow is finished; the other milestones stay locked
+		if escrow.Status != models.MilestoneEscrowStatusActive {
+			return fmt.Errorf("%w: escrow of milestone %s is %s", ErrMilestoneAlreadyReleased, milestoneID, escrow.Status)
+		}
+		if escrow.Resolving() {
+			return fmt.Errorf("%w: escrow of milestone %s waits for transaction %s", ErrMilestoneAlreadyReleased, milestoneID, escrow.PendingTransaction)
+		}
+	} else if milestone.Status == models.MilestoneStatusVerified {
+		return fmt.Errorf("%w: milestone %s of smart check %s", ErrMilestoneAlreadyReleased, milestoneID, smartChequeID)
+	}
+	if !releasable(smartCheque.Status) {
+		return fmt.Errorf("%w: smart check %s is %s", ErrInvalidStatusTransition, smartChequeID, smartCheque.Status)
 	}
 
 	// Update milestone status
-	milestone.Status = models.MilestoneStatusVerified
+	previousStatus, previousCompletedAt := milestone.Status, milestone.CompletedAt
 	now := time.Now()
+	milestone.Status = models.MilestoneStatusVerified
 	milestone.CompletedAt = &now
 	milestone.UpdatedAt = now
 
+	var transactionID string
+	var legacyFinish *xrpl.TransactionResult
+	if milestone.Escrow != nil {
+		// Payment execution only releases the sealed fulfillment of a verified milestone
+		smartCheque.UpdatedAt = now
+		if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+			return fmt.Errorf("failed to update smart check: %w", err)
+		}
+
+		result, err := s.releaseMilestoneEscrow(ctx, smartCheque, milestone)
+		if err != nil {
+			milestone.Status, milestone.CompletedAt = previousStatus, previousCompletedAt
+			smartCheque.UpdatedAt = time.Now()
+			if updateErr := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); updateErr != nil {
+				log.Printf("Warning: Milestone %s stays verified after its release failed: %v", milestoneID, updateErr)
+			}
+			return err
+		}
+		transactionID = result.TransactionID
+	} else {
+		// A Smart Check funded by a single escrow keeps no fulfillment per milestone
+
+		// Generate condition and fulfillment (in a real implementation, these would be retrieved)
+		milestoneSecret := fmt.Sprintf("smartcheque_%s_secret_%d", smartChequeID, time.Now().Unix())
+		condition, fulfillment, err := s.xrplService.GenerateCondition(milestoneSecret)
+		if err != nil {
+			return fmt.Errorf("failed to generate condition: %w", err)
+		}
+
+		// Complete the XRPL escrow
+		// Note: This is a simplified implementation. In reality, we would need the actual
+		// sequence number and other details from the original escrow creation
+		result, err := s.xrplService.CompleteSmartChequeMilestone(
+			smartCheque.EscrowAddress, // Using escrow address as payee for this example
+			smartCheque.EscrowAddress, // Using escrow address as owner for this example
+			1,                         // Sequence number - would need to be retrieved from original transaction
+			condition,
+			fulfillment,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to complete XRPL escrow: %w", err)
+		}
+		transactionID = result.TransactionID
+		legacyFinish = result
+	}
+
 	// Update Smart Check status if all milestones are completed
 	allCompleted := true
-	for i, m := range smartCheque.Milestones {
-		if m.ID == milestoneID {
-			smartCheque.Milestones[i].Status = models.MilestoneStatusVerified
-			smartCheque.Milestones[i].CompletedAt = &now
-		}
-		if smartCheque.Milestones[i].Status != models.MilestoneStatusVerified {
+	for _, m := range smartCheque.Milestones {
+		if m.Status != models.MilestoneStatusVerified {
 			allCompleted = false
 		}
 	}
 
-	if allCompleted {
-		smartCheque.Status = models.SmartChequeStatusCompleted
+	// Milestone escrows settle the Smart Check once their finishes validate
+	if !smartCheque.HasMilestoneEscrows() && allCompleted {
+		s.followLedger(ctx, smartCheque, models.SmartChequeStatusCompleted, "all milestones paid")
 	}
 
 	smartCheque.UpdatedAt = time.Now()
@@ -214,33 +354,71 @@ func (s *smartChequeXRPLService) CompleteMilestonePayment(ctx context.Context, s
 		return fmt.Errorf("failed to update smart check: %w", err)
 	}
 
-	// Create a transaction record for tracking
+	// Payment execution records the finish of a milestone's own escrow
+	if legacyFinish != nil {
+		s.recordEscrowFinish(smartCheque, milestone, smartCheque.EscrowAddress, smartCheque.EscrowAddress, legacyFinish)
+	}
+
+	log.Printf("Completed milestone payment for Smart Check %s, milestone %s with transaction ID %s",
+		smartChequeID, milestoneID, transactionID)
+	return nil
+}
+
+// releaseMilestoneEscrow has payment execution finish a verified milestone's escrow with its sealed
+// fulfillment, paying endorsed proceeds on to their holder. Completing the milestone is the payer's
+// approval of its payment. The escrow waits on the finish until a validated ledger applies it.
+func (s *smartChequeXRPLService) releaseMilestoneEscrow(ctx context.Context, smartCheque *models.SmartCheque, milestone *models.Milestone) (*PaymentExecutionResult, error) {
+	payerID, err := uuid.Parse(smartCheque.PayerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payer of smart check %s: %w", smartCheque.ID, err)
+	}
+
+	now := time.Now()
+	auth := &PaymentAuthorization{
+		ID:                uuid.New(),
+		SmartChequeID:     smartCheque.ID,
+		MilestoneID:       milestone.ID,
+		EnterpriseID:      payerID,
+		InitiatedByUserID: payerID,
+		Amount:            milestone.Amount.String(),
+		Currency:          string(smartCheque.Currency),
+		Purpose:           fmt.Sprintf("Release of milestone %s", milestone.ID),
+		Status:            PaymentAuthStatusApproved,
+		RequiredApprovals: 1,
+		CurrentApprovals:  1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	result, err := s.payments.ReleaseMilestoneEscrow(ctx, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish escrow of milestone %s: %w", milestone.ID, err)
+	}
+	smartCheque.SubmitMilestoneEscrowResolution(milestone.ID, result.TransactionID)
+	return result, nil
+}
+
+// recordEscrowFinish records the release of a milestone's funds for tracking
+func (s *smartChequeXRPLService) recordEscrowFinish(smartCheque *models.SmartCheque, milestone *models.Milestone, fromAddress, toAddress string, result *xrpl.TransactionResult) {
 	transaction := models.NewTransaction(
 		models.TransactionTypeEscrowFinish,
-		smartCheque.EscrowAddress,
-		smartCheque.EscrowAddress,
-		fmt.Sprintf("%f", milestone.Amount),
+		fromAddress,
+		toAddress,
+		milestone.Amount.String(),
 		string(smartCheque.Currency),
 		smartCheque.PayerID,
 		smartCheque.PayerID, // Using payer ID as user ID for now
 	)
 
 	// Set XRPL-specific fields
-	transaction.SmartChequeID = &smartChequeID
-	transaction.MilestoneID = &milestoneID
-	transaction.TransactionHash = result.TransactionID
-	transaction.Status = models.TransactionStatusConfirmed
-	now = time.Now()
-	transaction.ConfirmedAt = &now
+	transaction.SmartChequeID = &smartCheque.ID
+	transaction.MilestoneID = &milestone.ID
+	recordLedgerSubmission(transaction, result)
 
 	// Save the transaction
 	if err := s.transactionRepo.CreateTransaction(transaction); err != nil {
 		log.Printf("Warning: Failed to save transaction record: %v", err)
 	}
-
-	log.Printf("Completed milestone payment for Smart Check %s, milestone %s with transaction ID %s",
-		smartChequeID, milestoneID, result.TransactionID)
-	return nil
 }
 
 // CancelSmartChequeEscrow cancels the XRPL escrow for a Smart Check with refund calculation
@@ -258,6 +436,9 @@ func (s *smartChequeXRPLService) CancelSmartChequeEscrowWithReason(ctx context.C
 	if smartCheque == nil {
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
+	if smartCheque.Settlement() != models.SettlementModeEscrow {
+		return fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
 
 	// Check if escrow address exists
 	if smartCheque.EscrowAddress == "" {
@@ -269,6 +450,10 @@ func (s *smartChequeXRPLService) CancelSmartChequeEscrowWithReason(ctx context.C
 		return fmt.Errorf("escrow cancellation validation failed: %w", err)
 	}
 
+	if smartCheque.HasMilestoneEscrows() {
+		return s.cancelMilestoneEscrows(ctx, smartCheque, reason, notes)
+	}
+
 	// Calculate refund amount based on completed milestones
 	refundAmount := s.calculateRefundAmount(smartCheque)
 
@@ -283,8 +468,7 @@ func (s *smartChequeXRPLService) CancelSmartChequeEscrowWithReason(ctx context.C
 	}
 
 	// Update Smart Check status based on cancellation reason
-	newStatus := s.determineStatusAfterCancellation(smartCheque, reason)
-	smartCheque.Status = newStatus
+	s.followLedger(ctx, smartCheque, s.determineStatusAfterCancellation(smartCheque, reason), reason)
 	smartCheque.UpdatedAt = time.Now()
 
 	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
@@ -292,15 +476,230 @@ func (s *smartChequeXRPLService) CancelSmartChequeEscrowWithReason(ctx context.C
 	}
 
 	// Create transaction records for cancellation and refund
-	if err := s.createCancellationTransactions(ctx, smartCheque, result.TransactionID, refundAmount, reason, notes); err != nil {
+	if err := s.createCancellationTransactions(ctx, smartCheque, "", result, refundAmount, reason, notes); err != nil {
 		log.Printf("Warning: Failed to create cancellation transaction records: %v", err)
 	}
 
-	log.Printf("Canceled XRPL escrow for Smart Check %s with reason '%s', refund amount: %f, transaction ID: %s",
+	log.Printf("Canceled XRPL escrow for Smart Check %s with reason '%s', refund amount: %s, transaction ID: %s",
 		smartChequeID, reason, refundAmount, result.TransactionID)
 	return nil
 }
 
+// cancelMilestoneEscrows calls the Smart Check off. The ledger only lets the payer cancel an
+// escrow once its CancelAfter has passed, so the escrows that have lapsed are cancelled now and the
+// rest are left for the expiry sweeper; milestones already released stay paid out. The Smart Check
+// settles once the cancellations validate.
+func (s *smartChequeXRPLService) cancelMilestoneEscrows(ctx context.Context, smartCheque *models.SmartCheque, reason, notes string) error {
+	before := len(smartCheque.ResolvingMilestoneEscrows())
+	metadata := map[string]interface{}{"notes": notes}
+	transitionErr := s.stateMachine.Transition(ctx, smartCheque, s.determineStatusAfterCancellation(smartCheque, reason), reason, metadata)
+	cancelled := len(smartCheque.ResolvingMilestoneEscrows()) - before
+	if transitionErr == nil {
+		s.settleCancellation(ctx, smartCheque, reason)
+	}
+
+	// Cancellations submitted before a failure may still be applied, so the Smart Check is saved regardless
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+	if transitionErr != nil {
+		if cancelled > 0 {
+			return fmt.Errorf("submitted cancellations of %d milestone escrows before failing: %w", cancelled, transitionErr)
+		}
+		return transitionErr
+	}
+
+	log.Printf("Smart Check %s is %s with reason '%s': %d milestone escrow cancellations submitted, %d escrows waiting for CancelAfter",
+		smartCheque.ID, smartCheque.Status, reason, cancelled, len(smartCheque.ActiveMilestoneEscrows())-len(smartCheque.ResolvingMilestoneEscrows()))
+	return nil
+}
+
+// settleCancellation moves a cancelled or expired Smart Check on to its outcome once no milestone
+// escrow holds funds any more
+func (s *smartChequeXRPLService) settleCancellation(ctx context.Context, smartCheque *models.SmartCheque, reason string) {
+	if len(smartCheque.ActiveMilestoneEscrows()) > 0 {
+		return
+	}
+	if status, ok := smartCheque.StatusFromMilestoneEscrows(); ok {
+		s.followLedger(ctx, smartCheque, status, reason)
+	}
+}
+
+// CancelEscrows cancels the Smart Check's milestone escrows whose CancelAfter has passed,
+// recording a cancellation for each. The escrows stay active until a validated ledger applies the
+// cancellations. It is the state machine's cancel_escrows effect.
+func (s *smartChequeXRPLService) CancelEscrows(ctx context.Context, smartCheque *models.SmartCheque, transition *models.SmartChequeStatusTransition) error {
+	notes, _ := transition.Metadata["notes"].(string)
+	now := s.stateMachine.now()
+	for i := range smartCheque.Milestones {
+		milestone := &smartCheque.Milestones[i]
+		escrow := milestone.Escrow
+		if escrow == nil || escrow.Status != models.MilestoneEscrowStatusActive || escrow.Resolving() || !escrowLapsed(escrow, now) {
+			continue
+		}
+
+		result, err := s.xrplService.CancelSmartCheque(escrow.Owner, escrow.Owner, escrow.OfferSequence)
+		if err != nil {
+			return fmt.Errorf("failed to cancel escrow of milestone %s: %w", milestone.ID, err)
+		}
+		smartCheque.SubmitMilestoneEscrowResolution(milestone.ID, result.TransactionID)
+
+		amount := milestone.Amount.WithCurrency(smartCheque.Currency)
+		if err := s.createCancellationTransactions(ctx, smartCheque, milestone.ID, result, amount, transition.Reason, notes); err != nil {
+			log.Printf("Warning: Failed to create cancellation transaction record: %v", err)
+		}
+	}
+	return nil
+}
+
+// ReleaseFunds finishes the escrows of the Smart Check's verified milestones still holding funds.
+// It is the state machine's release_funds effect; payment execution releases the sealed fulfillments.
+func (s *smartChequeXRPLService) ReleaseFunds(ctx context.Context, smartCheque *models.SmartCheque, _ *models.SmartChequeStatusTransition) error {
+	for i := range smartCheque.Milestones {
+		milestone := &smartCheque.Milestones[i]
+		escrow := milestone.Escrow
+		if escrow == nil || escrow.Status != models.MilestoneEscrowStatusActive || escrow.Resolving() || milestone.Status != models.MilestoneStatusVerified {
+			continue
+		}
+		if _, err := s.releaseMilestoneEscrow(ctx, smartCheque, milestone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransitionSmartCheque moves a Smart Check to a new status through the state machine and saves it
+func (s *smartChequeXRPLService) TransitionSmartCheque(ctx context.Context, smartChequeID string, status models.SmartChequeStatus, reason string) error {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, smartChequeID)
+	if err != nil {
+		return fmt.Errorf("failed to get smart check: %w", err)
+	}
+	if smartCheque == nil {
+		return fmt.Errorf("smart check not found: %s", smartChequeID)
+	}
+
+	transitionErr := s.stateMachine.Transition(ctx, smartCheque, status, reason, nil)
+	if transitionErr != nil && errors.Is(transitionErr, ErrInvalidStatusTransition) {
+		return transitionErr
+	}
+	// An effect may have moved funds before failing, which the Smart Check must keep
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+	return transitionErr
+}
+
+// ExpireSmartChequeEscrows cancels the milestone escrows whose CancelAfter has passed. A Smart
+// Check all of whose remaining escrows have lapsed expires; one called off earlier settles once
+// its last cancellation validates. Frozen Smart Checks are left alone.
+func (s *smartChequeXRPLService) ExpireSmartChequeEscrows(ctx context.Context, smartChequeID string) (int, error) {
+	smartCheque, err := s.smartChequeRepo.GetSmartChequeByID(ctx, smartChequeID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get smart check: %w", err)
+	}
+	if smartCheque == nil {
+		return 0, fmt.Errorf("smart check not found: %s", smartChequeID)
+	}
+
+	active := smartCheque.ActiveMilestoneEscrows()
+	before := len(smartCheque.ResolvingMilestoneEscrows())
+	now := s.stateMachine.now()
+	lapsed, cancellable := 0, 0
+	for _, escrow := range active {
+		if escrowLapsed(escrow, now) {
+			lapsed++
+			if !escrow.Resolving() {
+				cancellable++
+			}
+		}
+	}
+	// Escrows already waiting for a cancellation to validate are left to the LedgerSubmissionConfirmer
+	if cancellable == 0 {
+		return 0, nil
+	}
+
+	var expireErr error
+	metadata := map[string]interface{}{"notes": "cancel_after passed before the milestones were completed"}
+	switch smartCheque.Status {
+	case models.SmartChequeStatusLocked, models.SmartChequeStatusInProgress:
+		if lapsed == len(active) {
+			expireErr = s.stateMachine.Transition(ctx, smartCheque, models.SmartChequeStatusExpired, CancellationReasonExpired, metadata)
+		} else {
+			// Milestones that can still be finished keep the Smart Check running
+			record := models.NewSmartChequeStatusTransition(smartCheque, smartCheque.Status, CancellationReasonExpired)
+			record.Metadata = metadata
+			expireErr = s.CancelEscrows(ctx, smartCheque, record)
+		}
+	case models.SmartChequeStatusCancelled, models.SmartChequeStatusExpired:
+		record := models.NewSmartChequeStatusTransition(smartCheque, smartCheque.Status, CancellationReasonExpired)
+		record.Metadata = metadata
+		expireErr = s.CancelEscrows(ctx, smartCheque, record)
+	default:
+		return 0, nil
+	}
+
+	cancelled := len(smartCheque.ResolvingMilestoneEscrows()) - before
+	if expireErr == nil {
+		s.settleCancellation(ctx, smartCheque, CancellationReasonExpired)
+	}
+	if cancelled > 0 || expireErr == nil {
+		smartCheque.UpdatedAt = time.Now()
+		if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+			return cancelled, fmt.Errorf("failed to update smart check: %w", err)
+		}
+	}
+	if expireErr != nil {
+		return cancelled, fmt.Errorf("failed to expire escrows of smart check %s: %w", smartChequeID, expireErr)
+	}
+	return cancelled, nil
+}
+
+// SubmissionSettled marks a milestone escrow cancelled once a validated ledger applied its
+// EscrowCancel, moving a Smart Check called off to its outcome once no escrow holds funds any more.
+// A cancellation that failed or expired leaves the escrow active for the next sweep.
+func (s *smartChequeXRPLService) SubmissionSettled(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type != models.TransactionTypeEscrowCancel {
+		return nil
+	}
+	smartCheque, resolved, err := settleEscrowResolution(ctx, s.smartChequeRepo, transaction, models.MilestoneEscrowStatusCancelled)
+	if err != nil || smartCheque == nil {
+		return err
+	}
+	if resolved {
+		reason, _ := transaction.Metadata["cancellation_reason"].(string)
+		s.settleCancellation(ctx, smartCheque, reason)
+	}
+	smartCheque.UpdatedAt = time.Now()
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+	return nil
+}
+
+// StateMachine returns the state machine that moves Smart Checks between statuses
+func (s *smartChequeXRPLService) StateMachine() *SmartChequeStateMachine {
+	return s.stateMachine
+}
+
+// followLedger moves the Smart Check to the status a ledger operation settled it in. The ledger is
+// authoritative, so a transition the state machine refuses is logged rather than failing the operation.
+func (s *smartChequeXRPLService) followLedger(ctx context.Context, smartCheque *models.SmartCheque, status models.SmartChequeStatus, reason string) {
+	if err := s.stateMachine.Transition(ctx, smartCheque, status, reason, nil); err != nil {
+		log.Printf("Warning: Smart Check %s stays %s after the ledger settled it as %s: %v", smartCheque.ID, smartCheque.Status, status, err)
+	}
+}
+
+// releasable reports whether a Smart Check in status may still pay out milestones
+func releasable(status models.SmartChequeStatus) bool {
+	switch status {
+	case models.SmartChequeStatusCreated, models.SmartChequeStatusLocked,
+		models.SmartChequeStatusInProgress, models.SmartChequeStatusDisputed:
+		return true
+	}
+	return false
+}
+
 // PartialRefundEscrow performs a partial refund based on completed milestones
 func (s *smartChequeXRPLService) PartialRefundEscrow(ctx context.Context, smartChequeID string, refundPercentage float64) error {
 	// Get the Smart Check
@@ -311,14 +710,24 @@ func (s *smartChequeXRPLService) PartialRefundEscrow(ctx context.Context, smartC
 	if smartCheque == nil {
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
+	if smartCheque.Settlement() != models.SettlementModeEscrow {
+		return fmt.Errorf("%w: smart check %s settles by %s", ErrSettlementMode, smartChequeID, smartCheque.Settlement())
+	}
 
 	// Validate partial refund is possible
 	if err := s.validatePartialRefund(smartCheque, refundPercentage); err != nil {
 		return fmt.Errorf("partial refund validation failed: %w", err)
 	}
+	// Each milestone escrow pays out or refunds whole, so the unpaid ones are cancelled instead
+	if smartCheque.HasMilestoneEscrows() {
+		return fmt.Errorf("%w: smart check %s funds each milestone separately; cancel its remaining escrows instead", ErrSettlementMode, smartChequeID)
+	}
 
 	// Calculate refund amount
-	refundAmount := smartCheque.Amount * (refundPercentage / 100)
+	refundAmount, err := percentageOf(smartCheque.Amount, refundPercentage)
+	if err != nil {
+		return fmt.Errorf("failed to calculate refund amount: %w", err)
+	}
 
 	// In a real implementation, this would involve:
 	// 1. Finishing the escrow with partial fulfillment
@@ -334,8 +743,8 @@ func (s *smartChequeXRPLService) PartialRefundEscrow(ctx context.Context, smartC
 		return fmt.Errorf("failed to perform partial refund: %w", err)
 	}
 
-	// Update Smart Check status
-	smartCheque.Status = models.SmartChequeStatusDisputed
+	// The paid milestones stay with the payee and the rest went back to the payer
+	s.followLedger(ctx, smartCheque, models.SmartChequeStatusPartiallyPaid, fmt.Sprintf("%.2f%% refunded", refundPercentage))
 	smartCheque.UpdatedAt = time.Now()
 
 	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
@@ -343,11 +752,11 @@ func (s *smartChequeXRPLService) PartialRefundEscrow(ctx context.Context, smartC
 	}
 
 	// Create partial refund transaction record
-	if err := s.createPartialRefundTransaction(ctx, smartCheque, result.TransactionID, refundAmount, refundPercentage); err != nil {
+	if err := s.createPartialRefundTransaction(ctx, smartCheque, result, refundAmount, refundPercentage); err != nil {
 		log.Printf("Warning: Failed to create partial refund transaction record: %v", err)
 	}
 
-	log.Printf("Performed partial refund for Smart Check %s: %f%% (%f %s), transaction ID: %s",
+	log.Printf("Performed partial refund for Smart Check %s: %f%% (%s %s), transaction ID: %s",
 		smartChequeID, refundPercentage, refundAmount, smartCheque.Currency, result.TransactionID)
 	return nil
 }
@@ -359,10 +768,7 @@ func (s *smartChequeXRPLService) validateEscrowCancellation(smartCheque *models.
 		return fmt.Errorf("cannot cancel completed smart check")
 	}
 
-	// Check if escrow has expired
-	// In a real implementation, this would check the escrow's cancel_after time
-	// For now, we'll allow cancellation if the Smart Check is in appropriate states
-
+	// Only funds still locked, or held by a dispute, can be called back
 	validStatuses := []models.SmartChequeStatus{
 		models.SmartChequeStatusLocked,
 		models.SmartChequeStatusInProgress,
@@ -387,6 +793,9 @@ func (s *smartChequeXRPLService) validatePartialRefund(smartCheque *models.Smart
 	if smartCheque.Status == models.SmartChequeStatusCompleted {
 		return fmt.Errorf("cannot perform partial refund on completed smart check")
 	}
+	if !releasable(smartCheque.Status) {
+		return fmt.Errorf("smart check status %s does not allow a partial refund", smartCheque.Status)
+	}
 
 	// Check if some milestones are completed to justify partial refund
 	completedMilestones := 0
@@ -408,19 +817,24 @@ func (s *smartChequeXRPLService) validatePartialRefund(smartCheque *models.Smart
 }
 
 // calculateRefundAmount calculates the refund amount based on completed milestones
-func (s *smartChequeXRPLService) calculateRefundAmount(smartCheque *models.SmartCheque) float64 {
-	completedAmount := 0.0
-	totalAmount := 0.0
+func (s *smartChequeXRPLService) calculateRefundAmount(smartCheque *models.SmartCheque) models.Money {
+	completedAmount := models.ZeroMoney(smartCheque.Currency)
+	totalAmount := models.ZeroMoney(smartCheque.Currency)
 
 	for _, milestone := range smartCheque.Milestones {
-		totalAmount += milestone.Amount
+		var err error
+		if totalAmount, err = totalAmount.Add(milestone.Amount); err != nil {
+			return smartCheque.Amount
+		}
 		if milestone.Status == models.MilestoneStatusVerified {
-			completedAmount += milestone.Amount
+			if completedAmount, err = completedAmount.Add(milestone.Amount); err != nil {
+				return smartCheque.Amount
+			}
 		}
 	}
 
 	// If no milestones are defined or amounts don't match, return full amount
-	if totalAmount == 0 || totalAmount != smartCheque.Amount {
+	if totalAmount.IsZero() || !totalAmount.Equal(smartCheque.Amount) {
 		return smartCheque.Amount
 	}
 
@@ -428,29 +842,34 @@ func (s *smartChequeXRPLService) calculateRefundAmount(smartCheque *models.Smart
 	return completedAmount
 }
 
-// determineStatusAfterCancellation determines the appropriate status after cancellation
+// percentageOf returns percentage of amount to the basis point, rounded so that it and the
+// remainder still add up to amount
+func percentageOf(amount models.Money, percentage float64) (models.Money, error) {
+	basisPoints := int64(math.Round(percentage * 100))
+	shares, err := amount.Allocate(basisPoints, 10000-basisPoints)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return shares[0], nil
+}
+
+// determineStatusAfterCancellation determines the status a Smart Check moves to when it is called
+// off. While milestone escrows still wait for their CancelAfter it is cancelled, or expired when the
+// reason is that time ran out; once nothing is escrowed it is refunded, or partially paid when some
+// milestones were paid out first.
 func (s *smartChequeXRPLService) determineStatusAfterCancellation(smartCheque *models.SmartCheque, reason string) models.SmartChequeStatus {
-	switch reason {
-	case CancellationReasonTimeout:
-		return models.SmartChequeStatusDisputed
-	case CancellationReasonDispute:
-		return models.SmartChequeStatusDisputed
-	case CancellationReasonMutualAgreement:
-		// Check if any progress was made
-		hasCompletedMilestones := false
-		for _, milestone := range smartCheque.Milestones {
-			if milestone.Status == models.MilestoneStatusVerified {
-				hasCompletedMilestones = true
-				break
-			}
-		}
-		if hasCompletedMilestones {
-			return models.SmartChequeStatusDisputed
+	if len(smartCheque.ActiveMilestoneEscrows()) > 0 {
+		switch reason {
+		case CancellationReasonTimeout, CancellationReasonExpired:
+			return models.SmartChequeStatusExpired
+		default:
+			return models.SmartChequeStatusCancelled
 		}
-		return models.SmartChequeStatusDisputed
-	default:
-		return models.SmartChequeStatusDisputed
 	}
+	if smartCheque.HasPaidMilestones() {
+		return models.SmartChequeStatusPartiallyPaid
+	}
+	return models.SmartChequeStatusRefunded
 }
 
 // Cancellation reason constants
@@ -461,8 +880,9 @@ const (
 	CancellationReasonExpired         = "expired"
 )
 
-// createCancellationTransactions creates transaction records for cancellation
-func (s *smartChequeXRPLService) createCancellationTransactions(ctx context.Context, smartCheque *models.SmartCheque, txHash string, refundAmount float64, reason, notes string) error {
+// createCancellationTransactions creates transaction records for cancellation, of a single
+// milestone's escrow when milestoneID is set
+func (s *smartChequeXRPLService) createCancellationTransactions(ctx context.Context, smartCheque *models.SmartCheque, milestoneID string, result *xrpl.TransactionResult, refundAmount models.Money, reason, notes string) error {
 	// Check if context is canceled
 	select {
 	case <-ctx.Done():
@@ -475,28 +895,30 @@ func (s *smartChequeXRPLService) createCancellationTransactions(ctx context.Cont
 		models.TransactionTypeEscrowCancel,
 		smartCheque.EscrowAddress,
 		smartCheque.PayerID, // Refund goes back to payer
-		fmt.Sprintf("%f", refundAmount),
+		refundAmount.String(),
 		string(smartCheque.Currency),
 		smartCheque.PayerID,
 		smartCheque.PayerID,
 	)
 
 	cancelTx.SmartChequeID = &smartCheque.ID
-	cancelTx.TransactionHash = txHash
-	cancelTx.Status = models.TransactionStatusConfirmed
+	if milestoneID != "" {
+		cancelTx.MilestoneID = &milestoneID
+		recordEscrowResolution(cancelTx, result)
+	} else {
+		recordLedgerSubmission(cancelTx, result)
+	}
 	cancelTx.Metadata = map[string]interface{}{
 		"cancellation_reason": reason,
 		"cancellation_notes":  notes,
 		"refund_amount":       refundAmount,
 	}
-	now := time.Now()
-	cancelTx.ConfirmedAt = &now
 
 	return s.transactionRepo.CreateTransaction(cancelTx)
 }
 
 // createPartialRefundTransaction creates transaction record for partial refund
-func (s *smartChequeXRPLService) createPartialRefundTransaction(ctx context.Context, smartCheque *models.SmartCheque, txHash string, refundAmount, refundPercentage float64) error {
+func (s *smartChequeXRPLService) createPartialRefundTransaction(ctx context.Context, smartCheque *models.SmartCheque, result *xrpl.TransactionResult, refundAmount models.Money, refundPercentage float64) error {
 	// Check if context is canceled
 	select {
 	case <-ctx.Done():
@@ -509,23 +931,22 @@ func (s *smartChequeXRPLService) createPartialRefundTransaction(ctx context.Cont
 		models.TransactionTypeEscrowCancel,
 		smartCheque.EscrowAddress,
 		smartCheque.PayerID,
-		fmt.Sprintf("%f", refundAmount),
+		refundAmount.String(),
 		string(smartCheque.Currency),
 		smartCheque.PayerID,
 		smartCheque.PayerID,
 	)
 
 	refundTx.SmartChequeID = &smartCheque.ID
-	refundTx.TransactionHash = txHash
-	refundTx.Status = models.TransactionStatusConfirmed
+	recordLedgerSubmission(refundTx, result)
 	refundTx.Metadata = map[string]interface{}{
 		"refund_type":       "partial",
 		"refund_percentage": refundPercentage,
 		"refund_amount":     refundAmount,
-		"remaining_amount":  smartCheque.Amount - refundAmount,
 	}
-	now := time.Now()
-	refundTx.ConfirmedAt = &now
+	if remaining, err := smartCheque.Amount.Sub(refundAmount); err == nil {
+		refundTx.Metadata["remaining_amount"] = remaining
+	}
 
 	return s.transactionRepo.CreateTransaction(refundTx)
 }
@@ -541,13 +962,25 @@ func (s *smartChequeXRPLService) SyncEscrowStatus(ctx context.Context, smartCheq
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
 
+	switch smartCheque.Settlement() {
+	case models.SettlementModeXRPLCheck:
+		return s.syncCheckStatus(ctx, smartCheque)
+	case models.SettlementModeDirect:
+		// A direct payment settles in one transaction and leaves nothing on the ledger to follow
+		return nil
+	}
+
 	// Check if escrow address exists
 	if smartCheque.EscrowAddress == "" {
 		return fmt.Errorf("smart check has no escrow address")
 	}
 
-	// Get escrow status from XRPL using the transaction hash as sequence
-	escrowInfo, err := s.xrplService.GetEscrowStatus(smartCheque.EscrowAddress, smartCheque.EscrowAddress)
+	if smartCheque.HasMilestoneEscrows() {
+		return s.syncMilestoneEscrows(ctx, smartCheque)
+	}
+
+	// Read the escrow from the validated ledger
+	state, err := s.escrowState(smartCheque)
 	if err != nil {
 		log.Printf("Warning: Failed to get XRPL escrow status for Smart Check %s: %v", smartChequeID, err)
 		// Don't return error here, just log and continue with available data
@@ -555,7 +988,7 @@ func (s *smartChequeXRPLService) SyncEscrowStatus(ctx context.Context, smartCheq
 	}
 
 	// Update Smart Check status based on XRPL escrow status
-	if err := s.updateSmartChequeFromEscrowStatus(ctx, smartCheque, escrowInfo); err != nil {
+	if err := s.updateSmartChequeFromEscrowStatus(ctx, smartCheque, state); err != nil {
 		return fmt.Errorf("failed to update smart check from escrow status: %w", err)
 	}
 
@@ -563,41 +996,102 @@ func (s *smartChequeXRPLService) SyncEscrowStatus(ctx context.Context, smartCheq
 	return nil
 }
 
-// updateSmartChequeFromEscrowStatus updates the Smart Check status based on XRPL escrow status
-func (s *smartChequeXRPLService) updateSmartChequeFromEscrowStatus(ctx context.Context, smartCheque *models.SmartCheque, escrowInfo *xrpl.EscrowInfo) error {
-	// Determine the current escrow state based on available information
-	// In a real implementation, this would involve checking:
-	// - If escrow exists in ledger
-	// - If condition is fulfilled
-	// - If escrow has been finished or canceled
-	// - Current balance and flags
+// escrowLedgerState is what the validated ledger says about a Smart Check's escrow: the escrow
+// entry while it holds funds, or the transaction that removed it once it was finished or cancelled
+type escrowLedgerState struct {
+	reference  EscrowReference
+	escrow     *xrpl.EscrowInfo
+	resolution *xrpl.EscrowResolution
+}
 
-	// For now, we'll use a simplified state determination
-	// This would be enhanced with actual XRPL ledger queries in production
+// missing reports whether the escrow is gone from the ledger without a known resolution
+func (e *escrowLedgerState) missing() bool {
+	return e.escrow == nil && e.resolution == nil
+}
 
-	// Check if escrow is still active (not finished or canceled)
-	isActive := escrowInfo.Flags == 0 // Simplified check
+// escrowReference identifies a Smart Check's escrow by owner and sequence from its EscrowCreate
+// record, returning the ledger it was validated in when known
+func (s *smartChequeXRPLService) escrowReference(smartCheque *models.SmartCheque) (EscrowReference, uint32, error) {
+	transactions, err := s.transactionRepo.GetTransactionsBySmartChequeID(smartCheque.ID, 100, 0)
+	if err != nil {
+		return EscrowReference{}, 0, fmt.Errorf("failed to get escrow transactions: %w", err)
+	}
 
-	if !isActive {
-		// Escrow is no longer active - check if it was completed or canceled
-		if smartCheque.Status == models.SmartChequeStatusLocked {
-			// Assume completion if we have verified milestones
-			allMilestonesVerified := true
-			for _, milestone := range smartCheque.Milestones {
-				if milestone.Status != models.MilestoneStatusVerified {
-					allMilestonesVerified = false
-					break
-				}
-			}
+	for _, tx := range transactions {
+		if tx.Type != models.TransactionTypeEscrowCreate || tx.TransactionHash != smartCheque.EscrowAddress {
+			continue
+		}
+		if tx.Sequence == nil {
+			return EscrowReference{}, 0, fmt.Errorf("%w: escrow %s has no recorded sequence", ErrEscrowNotTracked, smartCheque.EscrowAddress)
+		}
+		var createdIn uint32
+		if tx.LedgerIndex != nil {
+			createdIn = *tx.LedgerIndex
+		}
+		return EscrowReference{Owner: tx.FromAddress, Destination: tx.ToAddress, OfferSequence: *tx.Sequence}, createdIn, nil
+	}
+	return EscrowReference{}, 0, fmt.Errorf("%w: no EscrowCreate record for %s", ErrEscrowNotTracked, smartCheque.EscrowAddress)
+}
 
-			if allMilestonesVerified {
-				smartCheque.Status = models.SmartChequeStatusCompleted
-				log.Printf("Updated Smart Check %s status to completed based on escrow status", smartCheque.ID)
-			} else {
-				smartCheque.Status = models.SmartChequeStatusDisputed
-				log.Printf("Updated Smart Check %s status to disputed - escrow finished but milestones not verified", smartCheque.ID)
+// escrowState looks the escrow up in the validated ledger and, when it is gone, finds the
+// transaction that finished or cancelled it
+func (s *smartChequeXRPLService) escrowState(smartCheque *models.SmartCheque) (*escrowLedgerState, error) {
+	reference, createdIn, err := s.escrowReference(smartCheque)
+	if err != nil {
+		return nil, err
+	}
+	return s.ledgerEscrowState(reference, createdIn)
+}
+
+// milestoneEscrowState looks up the escrow funding a single milestone
+func (s *smartChequeXRPLService) milestoneEscrowState(escrow *models.MilestoneEscrow) (*escrowLedgerState, error) {
+	reference := EscrowReference{Owner: escrow.Owner, Destination: escrow.Destination, OfferSequence: escrow.OfferSequence}
+	return s.ledgerEscrowState(reference, escrow.LedgerIndex)
+}
+
+// ledgerEscrowState reads an escrow created in ledger createdIn, or its resolution once it is gone
+func (s *smartChequeXRPLService) ledgerEscrowState(reference EscrowReference, createdIn uint32) (*escrowLedgerState, error) {
+	state := &escrowLedgerState{reference: reference}
+	escrowInfo, err := s.xrplService.GetEscrowStatus(reference.Owner, strconv.FormatUint(uint64(reference.OfferSequence), 10))
+	if err == nil {
+		state.escrow = escrowInfo
+		return state, nil
+	}
+	if !errors.Is(err, xrpl.ErrEntryNotFound) {
+		return nil, err
+	}
+
+	resolution, err := s.xrplService.FindEscrowResolution(reference.Owner, reference.OfferSequence, createdIn)
+	if err != nil && !errors.Is(err, xrpl.ErrEntryNotFound) {
+		return nil, err
+	}
+	state.resolution = resolution
+	return state, nil
+}
+
+// updateSmartChequeFromEscrowStatus updates the Smart Check status from the escrow's state in the ledger
+func (s *smartChequeXRPLService) updateSmartChequeFromEscrowStatus(ctx context.Context, smartCheque *models.SmartCheque, state *escrowLedgerState) error {
+	active := smartCheque.Status == models.SmartChequeStatusLocked || smartCheque.Status == models.SmartChequeStatusInProgress
+
+	switch {
+	case state.resolution == nil || !active:
+		// The escrow still holds the funds, or the Smart Check already reflects its outcome
+	case state.resolution.Finished():
+		allMilestonesVerified := true
+		for _, milestone := range smartCheque.Milestones {
+			if milestone.Status != models.MilestoneStatusVerified {
+				allMilestonesVerified = false
+				break
 			}
 		}
+
+		if allMilestonesVerified {
+			s.followLedger(ctx, smartCheque, models.SmartChequeStatusCompleted, fmt.Sprintf("escrow finished by %s", state.resolution.TransactionID))
+		} else {
+			s.followLedger(ctx, smartCheque, models.SmartChequeStatusDisputed, fmt.Sprintf("escrow finished by %s but milestones not verified", state.resolution.TransactionID))
+		}
+	default:
+		s.followLedger(ctx, smartCheque, s.determineStatusAfterCancellation(smartCheque, CancellationReasonExpired), fmt.Sprintf("escrow cancelled by %s", state.resolution.TransactionID))
 	}
 
 	smartCheque.UpdatedAt = time.Now()
@@ -610,6 +1104,61 @@ func (s *smartChequeXRPLService) updateSmartChequeFromEscrowStatus(ctx context.C
 	return nil
 }
 
+// syncMilestoneEscrows records the escrows the ledger shows finished or cancelled on their
+// milestones and derives the Smart Check status from them. An escrow released before its
+// milestone was verified leaves the Smart Check disputed.
+func (s *smartChequeXRPLService) syncMilestoneEscrows(ctx context.Context, smartCheque *models.SmartCheque) error {
+	unverifiedRelease := false
+	for _, milestone := range smartCheque.Milestones {
+		// An escrow waiting for a submitted finish or cancellation is resolved by the
+		// LedgerSubmissionConfirmer, which also carries out the rest of the release
+		escrow := milestone.Escrow
+		if escrow == nil || escrow.Status != models.MilestoneEscrowStatusActive || escrow.Resolving() {
+			continue
+		}
+
+		state, err := s.milestoneEscrowState(escrow)
+		if err != nil {
+			log.Printf("Warning: Failed to get XRPL escrow status for Smart Check %s milestone %s: %v", smartCheque.ID, milestone.ID, err)
+			continue
+		}
+		if state.resolution == nil {
+			continue
+		}
+
+		status := models.MilestoneEscrowStatusCancelled
+		if state.resolution.Finished() {
+			status = models.MilestoneEscrowStatusFinished
+			if milestone.Status != models.MilestoneStatusVerified {
+				unverifiedRelease = true
+				log.Printf("Escrow of Smart Check %s milestone %s finished by %s but the milestone is not verified", smartCheque.ID, milestone.ID, state.resolution.TransactionID)
+			}
+		}
+		smartCheque.ResolveMilestoneEscrow(milestone.ID, status, state.resolution.TransactionID, time.Now())
+	}
+
+	// Only a Smart Check whose escrows still hold funds follows the ledger; one called off settles
+	// once its last escrow is resolved
+	switch smartCheque.Status {
+	case models.SmartChequeStatusLocked, models.SmartChequeStatusInProgress:
+		if unverifiedRelease {
+			s.followLedger(ctx, smartCheque, models.SmartChequeStatusDisputed, "escrow released before its milestone was verified")
+		} else if status, ok := smartCheque.StatusFromMilestoneEscrows(); ok {
+			s.followLedger(ctx, smartCheque, status, "milestone escrows resolved on the ledger")
+		}
+	case models.SmartChequeStatusCancelled, models.SmartChequeStatusExpired:
+		s.settleCancellation(ctx, smartCheque, "milestone escrows resolved on the ledger")
+	}
+	smartCheque.UpdatedAt = time.Now()
+
+	if err := s.smartChequeRepo.UpdateSmartCheque(ctx, smartCheque); err != nil {
+		return fmt.Errorf("failed to update smart check: %w", err)
+	}
+
+	log.Printf("Successfully synced milestone escrows for Smart Check %s", smartCheque.ID)
+	return nil
+}
+
 // MonitorEscrowStatus continuously monitors escrow status and updates Smart Check accordingly
 func (s *smartChequeXRPLService) MonitorEscrowStatus(ctx context.Context, smartChequeID string, interval time.Duration) error {
 	ticker := time.NewTicker(interval)
@@ -655,8 +1204,13 @@ func (s *smartChequeXRPLService) GetEscrowHealthStatus(ctx context.Context, smar
 		return status, nil
 	}
 
+	if smartCheque.HasMilestoneEscrows() {
+		s.analyzeMilestoneEscrowHealth(smartCheque, status)
+		return status, nil
+	}
+
 	// Get escrow status from XRPL
-	escrowInfo, err := s.xrplService.GetEscrowStatus(smartCheque.EscrowAddress, smartCheque.EscrowAddress)
+	state, err := s.escrowState(smartCheque)
 	if err != nil {
 		status.Health = "sync_error"
 		status.Message = fmt.Sprintf("Failed to get XRPL escrow status: %v", err)
@@ -664,9 +1218,10 @@ func (s *smartChequeXRPLService) GetEscrowHealthStatus(ctx context.Context, smar
 	}
 
 	// Analyze escrow health
-	status.Health = s.analyzeEscrowHealth(smartCheque, escrowInfo)
-	status.Message = s.generateHealthMessage(status.Health, smartCheque, escrowInfo)
-	status.EscrowInfo = escrowInfo
+	status.Health = s.analyzeEscrowHealth(smartCheque, state)
+	status.Message = s.generateHealthMessage(status.Health, smartCheque, state)
+	status.EscrowInfo = state.escrow
+	status.Resolution = state.resolution
 
 	return status, nil
 }
@@ -679,21 +1234,120 @@ type EscrowHealthStatus struct {
 	Message       string           `json:"message"`
 	LastSync      time.Time        `json:"last_sync"`
 	EscrowInfo    *xrpl.EscrowInfo `json:"escrow_info,omitempty"`
+	// Resolution is the transaction that finished or cancelled an escrow no longer in the ledger
+	Resolution *xrpl.EscrowResolution `json:"resolution,omitempty"`
+	// MilestoneEscrows is the health of each milestone's escrow when milestones are escrowed separately
+	MilestoneEscrows []MilestoneEscrowHealth `json:"milestone_escrows,omitempty"`
+}
+
+// MilestoneEscrowHealth represents the ledger state of the escrow funding one milestone
+type MilestoneEscrowHealth struct {
+	MilestoneID string                 `json:"milestone_id"`
+	Health      string                 `json:"health"`
+	EscrowInfo  *xrpl.EscrowInfo       `json:"escrow_info,omitempty"`
+	Resolution  *xrpl.EscrowResolution `json:"resolution,omitempty"`
+}
+
+// analyzeMilestoneEscrowHealth reports the health of every milestone escrow and rolls them up into
+// the health of the Smart Check
+func (s *smartChequeXRPLService) analyzeMilestoneEscrowHealth(smartCheque *models.SmartCheque, status *EscrowHealthStatus) {
+	var states []*escrowLedgerState
+	for _, milestone := range smartCheque.Milestones {
+		if milestone.Escrow == nil {
+			continue
+		}
+
+		state, err := s.milestoneEscrowState(milestone.Escrow)
+		if err != nil {
+			status.Health = "sync_error"
+			status.Message = fmt.Sprintf("Failed to get XRPL escrow status of milestone %s: %v", milestone.ID, err)
+			return
+		}
+		health := ledgerEscrowHealth(state)
+		if health == "" {
+			health = "active"
+		}
+		states = append(states, state)
+		status.MilestoneEscrows = append(status.MilestoneEscrows, MilestoneEscrowHealth{
+			MilestoneID: milestone.ID,
+			Health:      health,
+			EscrowInfo:  state.escrow,
+			Resolution:  state.resolution,
+		})
+	}
+
+	// A single milestone escrow is reported like the escrow of the whole Smart Check
+	if len(states) == 1 {
+		status.Health = s.analyzeEscrowHealth(smartCheque, states[0])
+		status.Message = s.generateHealthMessage(status.Health, smartCheque, states[0])
+		status.EscrowInfo = states[0].escrow
+		status.Resolution = states[0].resolution
+		return
+	}
+
+	released, canceled, expired := 0, 0, 0
+	for i, state := range states {
+		switch status.MilestoneEscrows[i].Health {
+		case "missing":
+			status.Health = "missing"
+			status.Message = s.generateHealthMessage(status.Health, smartCheque, state)
+			return
+		case "released":
+			released++
+		case "canceled":
+			canceled++
+		case "expired":
+			expired++
+		}
+	}
+
+	switch {
+	case released == len(states):
+		status.Health = "released"
+		status.Message = fmt.Sprintf("All %d milestone escrows were released to the payee", released)
+	case released+canceled == len(states):
+		status.Health = "canceled"
+		status.Message = fmt.Sprintf("%d of %d milestone escrows were canceled and returned to the payer", canceled, len(states))
+	case expired > 0:
+		status.Health = "expired"
+		status.Message = fmt.Sprintf("%d milestone escrows have expired and can be canceled", expired)
+	default:
+		status.Health = milestoneProgressHealth(smartCheque)
+		status.Message = s.generateHealthMessage(status.Health, smartCheque, nil)
+	}
 }
 
 // analyzeEscrowHealth analyzes the health of an escrow based on various factors
-func (s *smartChequeXRPLService) analyzeEscrowHealth(smartCheque *models.SmartCheque, escrowInfo *xrpl.EscrowInfo) string {
-	// Check escrow flags and status
-	if escrowInfo.Flags != 0 {
-		return "inactive"
+func (s *smartChequeXRPLService) analyzeEscrowHealth(smartCheque *models.SmartCheque, state *escrowLedgerState) string {
+	if health := ledgerEscrowHealth(state); health != "" {
+		return health
 	}
+	return milestoneProgressHealth(smartCheque)
+}
 
-	// Check if escrow is expired
-	currentTime := time.Now().Unix()
-	if escrowInfo.CancelAfter > 0 && uint32(currentTime) > escrowInfo.CancelAfter {
+// ledgerEscrowHealth reports an escrow that is gone from the ledger or expired, and an empty
+// health for one still holding its funds
+func ledgerEscrowHealth(state *escrowLedgerState) string {
+	// An escrow gone from the ledger was finished, cancelled or never validated
+	switch {
+	case state.missing():
+		return "missing"
+	case state.resolution != nil && state.resolution.Finished():
+		return "released"
+	case state.resolution != nil:
+		return "canceled"
+	}
+
+	// Check if escrow is expired; CancelAfter is in seconds since the Ripple epoch
+	currentTime := time.Since(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).Seconds()
+	if state.escrow.CancelAfter > 0 && uint32(currentTime) > state.escrow.CancelAfter {
 		return "expired"
 	}
+	return ""
+}
 
+// milestoneProgressHealth reports the health of escrowed funds from milestone completion
+func milestoneProgressHealth(smartCheque *models.SmartCheque) string {
 	// Check milestone completion status
 	completedMilestones := 0
 	for _, milestone := range smartCheque.Milestones {
@@ -714,10 +1368,7 @@ func (s *smartChequeXRPLService) analyzeEscrowHealth(smartCheque *models.SmartCh
 }
 
 // generateHealthMessage generates a human-readable health message
-func (s *smartChequeXRPLService) generateHealthMessage(health string, smartCheque *models.SmartCheque, escrowInfo *xrpl.EscrowInfo) string {
-	// escrowInfo parameter is available for future use in generating more detailed health messages
-	_ = escrowInfo // Explicitly ignore to satisfy linter
-
+func (s *smartChequeXRPLService) generateHealthMessage(health string, smartCheque *models.SmartCheque, state *escrowLedgerState) string {
 	switch health {
 	case "active":
 		return "Escrow is active and monitoring milestones"
@@ -731,8 +1382,15 @@ func (s *smartChequeXRPLService) generateHealthMessage(health string, smartChequ
 		return fmt.Sprintf("%d of %d milestones completed", completed, len(smartCheque.Milestones))
 	case "ready_for_release":
 		return "All milestones completed, escrow ready for release"
-	case "inactive":
-		return "Escrow is no longer active"
+	case "released":
+		return fmt.Sprintf("Escrow was released to the payee by transaction %s in ledger %d",
+			state.resolution.TransactionID, state.resolution.LedgerIndex)
+	case "canceled":
+		return fmt.Sprintf("Escrow was canceled and returned to the payer by transaction %s in ledger %d",
+			state.resolution.TransactionID, state.resolution.LedgerIndex)
+	case "missing":
+		return fmt.Sprintf("Escrow %d of %s is not in the validated ledger and no transaction removing it was found",
+			state.reference.OfferSequence, state.reference.Owner)
 	case "expired":
 		return "Escrow has expired and can be canceled"
 	case "sync_error":
@@ -759,6 +1417,8 @@ type EscrowMonitoringService struct {
 	smartChequeRepo        repository.SmartChequeRepositoryInterface
 	monitoringInterval     time.Duration
 	activeMonitors         map[string]context.CancelFunc
+	eventDriven            bool
+	escrowIndex            map[string]string // escrow creation hash -> Smart Check ID
 	mu                     sync.RWMutex
 }
 
@@ -773,6 +1433,36 @@ func NewEscrowMonitoringService(
 		smartChequeRepo:        smartChequeRepo,
 		monitoringInterval:     monitoringInterval,
 		activeMonitors:         make(map[string]context.CancelFunc),
+		escrowIndex:            make(map[string]string),
+	}
+}
+
+// EnableEventDrivenMonitoring switches from per-cheque polling to syncing escrows when the
+// ledger stream reports a transaction that created, finished or canceled them
+func (m *EscrowMonitoringService) EnableEventDrivenMonitoring() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventDriven = true
+}
+
+// HandleTransaction syncs every monitored Smart Check whose escrow the transaction touched
+func (m *EscrowMonitoringService) HandleTransaction(ctx context.Context, event *xrpl.TransactionEvent) {
+	if !event.Validated {
+		return
+	}
+
+	for _, escrowHash := range event.EscrowCreateHashes() {
+		m.mu.RLock()
+		smartChequeID, ok := m.escrowIndex[escrowHash]
+		m.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		log.Printf("Escrow %s for Smart Check %s changed by %s %s", escrowHash, smartChequeID, event.TransactionType, event.Hash)
+		if err := m.smartChequeXRPLService.SyncEscrowStatus(ctx, smartChequeID); err != nil {
+			log.Printf("Error syncing escrow status for Smart Check %s: %v", smartChequeID, err)
+		}
 	}
 }
 
@@ -795,8 +1485,32 @@ func (m *EscrowMonitoringService) StartMonitoringForSmartCheque(ctx context.Cont
 	if smartCheque == nil {
 		return fmt.Errorf("smart check not found: %s", smartChequeID)
 	}
-	if smartCheque.EscrowAddress == "" {
-		return fmt.Errorf("smart check has no escrow address: %s", smartChequeID)
+	if smartCheque.EscrowAddress == "" && smartCheque.CheckID == "" {
+		return fmt.Errorf("smart check has no escrow address or check: %s", smartChequeID)
+	}
+
+	// With the ledger stream the escrow is synced when its transactions arrive; no poller is needed
+	if m.eventDriven && smartCheque.EscrowAddress != "" {
+		// Each milestone escrow is watched by the hash of the EscrowCreate that funded it
+		escrowHashes := []string{smartCheque.EscrowAddress}
+		if smartCheque.HasMilestoneEscrows() {
+			escrowHashes = escrowHashes[:0]
+			for _, milestone := range smartCheque.Milestones {
+				if milestone.Escrow != nil {
+					escrowHashes = append(escrowHashes, milestone.Escrow.TransactionID)
+				}
+			}
+		}
+		for _, escrowHash := range escrowHashes {
+			m.escrowIndex[escrowHash] = smartChequeID
+		}
+		m.activeMonitors[smartChequeID] = func() {
+			for _, escrowHash := range escrowHashes {
+				delete(m.escrowIndex, escrowHash)
+			}
+		}
+		log.Printf("Watching %d escrows for Smart Check %s on the ledger stream", len(escrowHashes), smartChequeID)
+		return nil
 	}
 
 	// Create monitoring context
@@ -862,7 +1576,7 @@ func (m *EscrowMonitoringService) StartMonitoringAllActiveEscrows(ctx context.Co
 
 	startedCount := 0
 	for _, smartCheque := range smartCheques {
-		if smartCheque.EscrowAddress != "" {
+		if smartCheque.EscrowAddress != "" || smartCheque.CheckID != "" {
 			if err := m.StartMonitoringForSmartCheque(ctx, smartCheque.ID); err != nil {
 				log.Printf("Failed to start monitoring for Smart Check %s: %v", smartCheque.ID, err)
 			} else {
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
@@ -357,20 +360,37 @@ func (m *mockXRPLServiceXRPL) HealthCheck() error {
 	return args.Error(0)
 }
 
-func (m *mockXRPLServiceXRPL) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount float64, currency string, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestoneSecret)
+func (m *mockXRPLServiceXRPL) GetAccountReserve(address string) (*xrpl.AccountReserve, error) {
+	args := m.Called(address)
+	reserve, _ := args.Get(0).(*xrpl.AccountReserve)
+	return reserve, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) DeleteAccount(address, destination string) (*xrpl.TransactionResult, error) {
+	args := m.Called(address, destination)
 	result, _ := args.Get(0).(*xrpl.TransactionResult)
-	fulfillment, _ := args.Get(1).(string)
-	return result, fulfillment, args.Error(2)
+	return result, args.Error(1)
 }
 
-func (m *mockXRPLServiceXRPL) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount float64, currency string, milestones []models.Milestone) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestones)
+func (m *mockXRPLServiceXRPL) WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error) {
+	args := m.Called(ctx, hash, lastLedgerSequence)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestoneSecret)
 	result, _ := args.Get(0).(*xrpl.TransactionResult)
 	fulfillment, _ := args.Get(1).(string)
 	return result, fulfillment, args.Error(2)
 }
 
+func (m *mockXRPLServiceXRPL) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount models.Money, milestones []models.Milestone) ([]models.MilestoneEscrowFunding, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestones)
+	fundings, _ := args.Get(0).([]models.MilestoneEscrowFunding)
+	return fundings, args.Error(1)
+}
+
 func (m *mockXRPLServiceXRPL) CompleteSmartChequeMilestone(payeeAddress, ownerAddress string, sequence uint32, condition, fulfillment string) (*xrpl.TransactionResult, error) {
 	args := m.Called(payeeAddress, ownerAddress, sequence, condition, fulfillment)
 	result, _ := args.Get(0).(*xrpl.TransactionResult)
@@ -389,6 +409,60 @@ func (m *mockXRPLServiceXRPL) GetEscrowStatus(ownerAddress string, sequence stri
 	return escrowInfo, args.Error(1)
 }
 
+func (m *mockXRPLServiceXRPL) ListEscrows(ownerAddress string) ([]xrpl.EscrowInfo, error) {
+	args := m.Called(ownerAddress)
+	escrows, _ := args.Get(0).([]xrpl.EscrowInfo)
+	return escrows, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) FindEscrowResolution(ownerAddress string, sequence, sinceLedger uint32) (*xrpl.EscrowResolution, error) {
+	args := m.Called(ownerAddress, sequence, sinceLedger)
+	resolution, _ := args.Get(0).(*xrpl.EscrowResolution)
+	return resolution, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error) {
+	args := m.Called(hash)
+	delivery, _ := args.Get(0).(*xrpl.PaymentDelivery)
+	return delivery, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) CreateSmartChequeCheck(payerAddress, payeeAddress string, amount models.Money, validFor time.Duration, invoiceID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, validFor, invoiceID)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) CashSmartChequeCheck(payeeAddress, checkID string, amount models.Money, minimum bool) (*xrpl.TransactionResult, error) {
+	args := m.Called(payeeAddress, checkID, amount, minimum)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) CancelSmartChequeCheck(accountAddress, checkID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(accountAddress, checkID)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) GetCheck(checkID string) (*xrpl.CheckInfo, error) {
+	args := m.Called(checkID)
+	result, _ := args.Get(0).(*xrpl.CheckInfo)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) FindCheckResolution(sourceAddress, checkID string, sinceLedger uint32) (*xrpl.CheckResolution, error) {
+	args := m.Called(sourceAddress, checkID, sinceLedger)
+	result, _ := args.Get(0).(*xrpl.CheckResolution)
+	return result, args.Error(1)
+}
+
+func (m *mockXRPLServiceXRPL) SendPayment(fromAddress, toAddress string, amount models.Money) (*xrpl.TransactionResult, error) {
+	args := m.Called(fromAddress, toAddress, amount)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
 func (m *mockXRPLServiceXRPL) GenerateCondition(secret string) (condition string, fulfillment string, err error) {
 	args := m.Called(secret)
 	condition, _ = args.Get(0).(string)
@@ -615,6 +689,48 @@ func (m *mockMilestoneRepoXRPL) GetUpcomingMilestones(ctx context.Context, daysA
 	return args.Get(0).([]*models.ContractMilestone), args.Error(1)
 }
 
+// newTestSmartChequeXRPLService creates the service with an in-memory fulfillment vault and a
+// payment execution service releasing escrows through the same repositories and ledger
+func newTestSmartChequeXRPLService(
+	t *testing.T,
+	smartChequeRepo repository.SmartChequeRepositoryInterface,
+	transactionRepo repository.SmartChequeTransactionRepositoryInterface,
+	xrplService repository.XRPLServiceInterface,
+	milestoneRepo repository.MilestoneRepositoryInterface,
+) (SmartChequeXRPLServiceInterface, *FulfillmentVault) {
+	t.Helper()
+	vault, _ := newTestFulfillmentVault(t)
+	eventBus := &TestMockEventBus{}
+	eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+	payments := NewPaymentExecutionService(nil, smartChequeRepo, transactionRepo, xrplService, vault, eventBus, &PaymentExecutionConfig{})
+	return NewSmartChequeXRPLService(smartChequeRepo, transactionRepo, xrplService, milestoneRepo, vault, payments), vault
+}
+
+// escrowObservers returns the observers that resolve the milestone escrows service finishes and cancels
+func escrowObservers(service SmartChequeXRPLServiceInterface) []LedgerSubmissionObserver {
+	return []LedgerSubmissionObserver{service, service.(*smartChequeXRPLService).payments}
+}
+
+// confirmLastRecorded confirms the last transaction of txType recorded through transactionRepo
+// and has observers settle it, as the confirmer does once a validated ledger holds it
+func confirmLastRecorded(t *testing.T, transactionRepo *mockTransactionRepoXRPL, txType models.TransactionType, observers ...LedgerSubmissionObserver) {
+	t.Helper()
+	var last *models.Transaction
+	for _, call := range transactionRepo.Calls {
+		if call.Method != "CreateTransaction" {
+			continue
+		}
+		if transaction := call.Arguments.Get(0).(*models.Transaction); transaction.Type == txType {
+			last = transaction
+		}
+	}
+	require.NotNil(t, last, "no %s transaction was recorded", txType)
+	last.Status = models.TransactionStatusConfirmed
+	for _, observer := range observers {
+		require.NoError(t, observer.SubmissionSettled(context.Background(), last))
+	}
+}
+
 // TestSmartChequeXRPLService_CreateEscrowForSmartCheque tests the CreateEscrowForSmartCheque method
 func TestSmartChequeXRPLService_CreateEscrowForSmartCheque(t *testing.T) {
 	// Create mocks
@@ -624,25 +740,30 @@ func TestSmartChequeXRPLService_CreateEscrowForSmartCheque(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, vault := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	// Test data
 	smartChequeID := uuid.New().String()
 	payerAddress := "rPayerAddress123456789"
 	payeeAddress := "rPayeeAddress123456789"
 
+	milestoneID := uuid.New().String()
+	condition, fulfillment := newTestCondition(t)
 	smartCheque := &models.SmartCheque{
 		ID:       smartChequeID,
 		PayerID:  uuid.New().String(),
 		PayeeID:  uuid.New().String(),
-		Amount:   100.0,
+		Amount:   models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency: models.CurrencyUSDT,
 		Status:   models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{
+				ID:                 milestoneID,
+				Amount:             models.MustParseMoney("100", models.CurrencyUSDT),
+				VerificationMethod: models.VerificationMethodManual,
+				Status:             models.MilestoneStatusPending,
+			},
+		},
 	}
 
 	// Set up mock expectations
@@ -650,20 +771,29 @@ func TestSmartChequeXRPLService_CreateEscrowForSmartCheque(t *testing.T) {
 	mockXRPLService.On("ValidateAddress", payerAddress).Return(true)
 	mockXRPLService.On("ValidateAddress", payeeAddress).Return(true)
 
-	transactionResult := &xrpl.TransactionResult{
-		TransactionID: uuid.New().String(),
-		ResultCode:    "tesSUCCESS",
-		Validated:     true,
-		LedgerIndex:   12345,
+	funding := models.MilestoneEscrowFunding{
+		MilestoneID: milestoneID,
+		Escrow: models.MilestoneEscrow{
+			TransactionID: uuid.New().String(),
+			Owner:         payerAddress,
+			Destination:   payeeAddress,
+			OfferSequence: 7,
+			LedgerIndex:   12345,
+			Condition:     condition,
+			Status:        models.MilestoneEscrowStatusActive,
+		},
+		Fulfillment: fulfillment,
 	}
 
-	mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, 100.0, "USDT", smartCheque.Milestones).Return(transactionResult, "fulfillment123", nil)
+	mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, models.MustParseMoney("100", models.CurrencyUSDT), smartCheque.Milestones).Return([]models.MilestoneEscrowFunding{funding}, nil)
 	mockSmartChequeRepo.On("UpdateSmartCheque", mock.Anything, mock.MatchedBy(func(sc *models.SmartCheque) bool {
-		return sc.EscrowAddress == transactionResult.TransactionID && sc.Status == models.SmartChequeStatusLocked
+		return sc.EscrowAddress == payerAddress && sc.Status == models.SmartChequeStatusLocked &&
+			sc.Milestones[0].Escrow != nil && sc.Milestones[0].Escrow.OfferSequence == 7
 	})).Return(nil)
 
 	mockTransactionRepo.On("CreateTransaction", mock.MatchedBy(func(tx *models.Transaction) bool {
-		return tx.Type == models.TransactionTypeEscrowCreate && tx.TransactionHash == transactionResult.TransactionID
+		return tx.Type == models.TransactionTypeEscrowCreate && tx.TransactionHash == funding.Escrow.TransactionID &&
+			tx.MilestoneID != nil && *tx.MilestoneID == milestoneID && tx.Fulfillment == ""
 	})).Return(nil)
 
 	// Execute the method
@@ -674,6 +804,95 @@ func TestSmartChequeXRPLService_CreateEscrowForSmartCheque(t *testing.T) {
 	mockSmartChequeRepo.AssertExpectations(t)
 	mockXRPLService.AssertExpectations(t)
 	mockTransactionRepo.AssertExpectations(t)
+
+	// The fulfillment is sealed in the vault rather than kept with the transaction record
+	sealed, err := vault.Lookup(context.Background(), smartChequeID, milestoneID, FulfillmentActorPaymentExecution)
+	require.NoError(t, err)
+	assert.Equal(t, condition, sealed.Condition)
+	assert.Equal(t, uint32(7), sealed.OfferSequence)
+}
+
+func TestSmartChequeXRPLService_CreateEscrowForSmartCheque_RetrySkipsFundedMilestones(t *testing.T) {
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	mockXRPLService := &mockXRPLServiceXRPL{}
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, &mockMilestoneRepoXRPL{})
+	ctx := context.Background()
+
+	payerAddress := "rPayerAddress123456789"
+	payeeAddress := "rPayeeAddress123456789"
+	smartCheque := &models.SmartCheque{
+		ID:       uuid.New().String(),
+		PayerID:  uuid.New().String(),
+		PayeeID:  uuid.New().String(),
+		Amount:   models.MustParseMoney("100", models.CurrencyXRP),
+		Currency: models.CurrencyXRP,
+		Status:   models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{ID: uuid.New().String(), Amount: models.MustParseMoney("40", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+			{ID: uuid.New().String(), Amount: models.MustParseMoney("60", models.CurrencyXRP), VerificationMethod: models.VerificationMethodManual, Status: models.MilestoneStatusPending},
+		},
+	}
+	first, second := smartCheque.Milestones[0].ID, smartCheque.Milestones[1].ID
+	funding := func(milestoneID string, sequence uint32) models.MilestoneEscrowFunding {
+		condition, fulfillment := newTestCondition(t)
+		return models.MilestoneEscrowFunding{
+			MilestoneID: milestoneID,
+			Escrow: models.MilestoneEscrow{
+				TransactionID: uuid.New().String(),
+				Owner:         payerAddress,
+				Destination:   payeeAddress,
+				OfferSequence: sequence,
+				Condition:     condition,
+				Status:        models.MilestoneEscrowStatusActive,
+			},
+			Fulfillment: fulfillment,
+		}
+	}
+	fundingMilestones := func(ids ...string) interface{} {
+		return mock.MatchedBy(func(milestones []models.Milestone) bool {
+			if len(milestones) != len(ids) {
+				return false
+			}
+			for i, milestone := range milestones {
+				if milestone.ID != ids[i] {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	mockSmartChequeRepo.On("GetSmartChequeByID", mock.Anything, smartCheque.ID).Return(smartCheque, nil)
+	mockSmartChequeRepo.On("UpdateSmartCheque", mock.Anything, smartCheque).Return(nil)
+	mockXRPLService.On("ValidateAddress", mock.Anything).Return(true)
+	mockTransactionRepo.On("CreateTransaction", mock.Anything).Return(nil)
+
+	// The second milestone fails to fund after the first escrow reached the ledger
+	mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, smartCheque.Amount, fundingMilestones(first, second)).
+		Return([]models.MilestoneEscrowFunding{funding(first, 7)}, fmt.Errorf("tecUNFUNDED")).Once()
+	err := service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payerAddress, payeeAddress)
+	require.Error(t, err)
+	assert.Equal(t, models.SmartChequeStatusCreated, smartCheque.Status)
+	require.NotNil(t, smartCheque.Milestones[0].Escrow)
+	assert.Nil(t, smartCheque.Milestones[1].Escrow)
+
+	// The retry only funds the milestone that has no escrow yet
+	mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, smartCheque.Amount, fundingMilestones(second)).
+		Return([]models.MilestoneEscrowFunding{funding(second, 8)}, nil).Once()
+	require.NoError(t, service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payerAddress, payeeAddress))
+	mockXRPLService.AssertExpectations(t)
+
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	assert.Equal(t, uint32(7), smartCheque.Milestones[0].Escrow.OfferSequence)
+	assert.Equal(t, uint32(8), smartCheque.Milestones[1].Escrow.OfferSequence)
+	var creates int
+	for _, call := range mockTransactionRepo.Calls {
+		if call.Method == "CreateTransaction" && call.Arguments.Get(0).(*models.Transaction).Type == models.TransactionTypeEscrowCreate {
+			creates++
+		}
+	}
+	assert.Equal(t, 2, creates)
 }
 
 // TestSmartChequeXRPLService_CreateEscrowForSmartCheque_SmartChequeNotFound tests the case when smart check is not found
@@ -685,12 +904,7 @@ func TestSmartChequeXRPLService_CreateEscrowForSmartCheque_SmartChequeNotFound(t
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	// Test data
 	smartChequeID := uuid.New().String()
@@ -718,12 +932,7 @@ func TestSmartChequeXRPLService_CompleteMilestonePayment(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	// Test data
 	smartChequeID := uuid.New().String()
@@ -733,14 +942,14 @@ func TestSmartChequeXRPLService_CompleteMilestonePayment(t *testing.T) {
 		ID:            smartChequeID,
 		PayerID:       uuid.New().String(),
 		PayeeID:       uuid.New().String(),
-		Amount:        100.0,
+		Amount:        models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		Status:        models.SmartChequeStatusLocked,
 		EscrowAddress: "rEscrowAddress123456789",
 		Milestones: []models.Milestone{
 			{
 				ID:     milestoneID,
-				Amount: 100.0,
+				Amount: models.MustParseMoney("100", models.CurrencyUSDT),
 				Status: models.MilestoneStatusPending,
 			},
 		},
@@ -774,6 +983,11 @@ func TestSmartChequeXRPLService_CompleteMilestonePayment(t *testing.T) {
 	mockSmartChequeRepo.AssertExpectations(t)
 	mockXRPLService.AssertExpectations(t)
 	mockTransactionRepo.AssertExpectations(t)
+
+	// A released milestone is never paid twice
+	err = service.CompleteMilestonePayment(context.Background(), smartChequeID, milestoneID)
+	assert.ErrorIs(t, err, ErrMilestoneAlreadyReleased)
+	mockXRPLService.AssertNumberOfCalls(t, "CompleteSmartChequeMilestone", 1)
 }
 
 // TestSmartChequeXRPLService_CancelSmartChequeEscrow tests the CancelSmartChequeEscrow method
@@ -785,12 +999,7 @@ func TestSmartChequeXRPLService_CancelSmartChequeEscrow(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	// Test data
 	smartChequeID := uuid.New().String()
@@ -799,7 +1008,7 @@ func TestSmartChequeXRPLService_CancelSmartChequeEscrow(t *testing.T) {
 		ID:            smartChequeID,
 		PayerID:       uuid.New().String(),
 		PayeeID:       uuid.New().String(),
-		Amount:        100.0,
+		Amount:        models.MustParseMoney("100", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		Status:        models.SmartChequeStatusLocked,
 		EscrowAddress: "rEscrowAddress123456789",
@@ -817,7 +1026,7 @@ func TestSmartChequeXRPLService_CancelSmartChequeEscrow(t *testing.T) {
 
 	mockXRPLService.On("CancelSmartCheque", "rEscrowAddress123456789", "rEscrowAddress123456789", uint32(1)).Return(transactionResult, nil)
 	mockSmartChequeRepo.On("UpdateSmartCheque", mock.Anything, mock.MatchedBy(func(sc *models.SmartCheque) bool {
-		return sc.Status == models.SmartChequeStatusDisputed
+		return sc.Status == models.SmartChequeStatusRefunded
 	})).Return(nil)
 
 	mockTransactionRepo.On("CreateTransaction", mock.MatchedBy(func(tx *models.Transaction) bool {
@@ -843,12 +1052,7 @@ func TestSmartChequeXRPLService_GetXRPLTransactionHistory(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	// Test data
 	smartChequeID := uuid.New().String()
@@ -886,12 +1090,7 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	ctx := context.Background()
 	smartChequeID := uuid.New().String()
@@ -905,7 +1104,7 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 		ID:            smartChequeID,
 		PayerID:       uuid.New().String(),
 		PayeeID:       uuid.New().String(),
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		Status:        models.SmartChequeStatusCreated,
 		EscrowAddress: "",
@@ -913,14 +1112,14 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 			{
 				ID:                 milestoneID1,
 				Description:        "Deliver goods",
-				Amount:             500.0,
+				Amount:             models.MustParseMoney("500", models.CurrencyUSDT),
 				VerificationMethod: models.VerificationMethodManual,
 				Status:             models.MilestoneStatusPending,
 			},
 			{
 				ID:                 milestoneID2,
 				Description:        "Complete installation",
-				Amount:             500.0,
+				Amount:             models.MustParseMoney("500", models.CurrencyUSDT),
 				VerificationMethod: models.VerificationMethodOracle,
 				OracleConfig:       &models.OracleConfig{Type: "api", Endpoint: "https://api.example.com/verify"},
 				Status:             models.MilestoneStatusPending,
@@ -928,6 +1127,14 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 		},
 	}
 
+	// Each milestone escrow has a condition of its own, whose fulfillment is sealed in the vault
+	var conditions, fulfillments []string
+	for range smartCheque.Milestones {
+		condition, fulfillment := newTestCondition(t)
+		conditions = append(conditions, condition)
+		fulfillments = append(fulfillments, fulfillment)
+	}
+
 	// Phase 1: Create Escrow
 	t.Run("Phase 1: Create Escrow", func(t *testing.T) {
 		// Set up mock expectations
@@ -935,20 +1142,31 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 		mockXRPLService.On("ValidateAddress", payerAddress).Return(true)
 		mockXRPLService.On("ValidateAddress", payeeAddress).Return(true)
 
-		escrowResult := &xrpl.TransactionResult{
-			TransactionID: uuid.New().String(),
-			ResultCode:    "tesSUCCESS",
-			Validated:     true,
+		// Each milestone is funded by an escrow of its own
+		var fundings []models.MilestoneEscrowFunding
+		for i, milestone := range smartCheque.Milestones {
+			fundings = append(fundings, models.MilestoneEscrowFunding{
+				MilestoneID: milestone.ID,
+				Escrow: models.MilestoneEscrow{
+					TransactionID: uuid.New().String(),
+					Owner:         payerAddress,
+					Destination:   payeeAddress,
+					OfferSequence: uint32(10 + i),
+					Condition:     conditions[i],
+					Status:        models.MilestoneEscrowStatusActive,
+				},
+				Fulfillment: fulfillments[i],
+			})
 		}
-		mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, 1000.0, "USDT", smartCheque.Milestones).Return(escrowResult, "fulfillment123", nil)
+		mockXRPLService.On("CreateSmartChequeEscrowWithMilestones", payerAddress, payeeAddress, models.MustParseMoney("1000", models.CurrencyUSDT), smartCheque.Milestones).Return(fundings, nil)
 
 		mockSmartChequeRepo.On("UpdateSmartCheque", ctx, mock.MatchedBy(func(sc *models.SmartCheque) bool {
-			return sc.EscrowAddress == escrowResult.TransactionID && sc.Status == models.SmartChequeStatusLocked
+			return sc.EscrowAddress == payerAddress && sc.Status == models.SmartChequeStatusLocked
 		})).Return(nil)
 
 		mockTransactionRepo.On("CreateTransaction", mock.MatchedBy(func(tx *models.Transaction) bool {
-			return tx.Type == models.TransactionTypeEscrowCreate && tx.TransactionHash == escrowResult.TransactionID
-		})).Return(nil)
+			return tx.Type == models.TransactionTypeEscrowCreate && tx.Fulfillment == ""
+		})).Return(nil).Twice()
 
 		// Execute escrow creation
 		err := service.CreateEscrowForSmartCheque(ctx, smartChequeID, payerAddress, payeeAddress)
@@ -962,19 +1180,15 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 
 	// Phase 2: Complete First Milestone
 	t.Run("Phase 2: Complete First Milestone", func(t *testing.T) {
-		// Update Smart Check status to locked with escrow
-		smartCheque.Status = models.SmartChequeStatusLocked
-		smartCheque.EscrowAddress = "escrow_tx_123"
-
 		mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartChequeID).Return(smartCheque, nil)
-		mockXRPLService.On("GenerateCondition", mock.AnythingOfType("string")).Return("condition123", "fulfillment123", nil)
 
+		// Only the first milestone's escrow is finished, with its own sealed fulfillment
 		finishResult := &xrpl.TransactionResult{
 			TransactionID: uuid.New().String(),
 			ResultCode:    "tesSUCCESS",
 			Validated:     true,
 		}
-		mockXRPLService.On("CompleteSmartChequeMilestone", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(finishResult, nil)
+		mockXRPLService.On("CompleteSmartChequeMilestone", payeeAddress, payerAddress, uint32(10), conditions[0], fulfillments[0]).Return(finishResult, nil).Once()
 
 		mockSmartChequeRepo.On("UpdateSmartCheque", ctx, mock.Anything).Return(nil)
 
@@ -984,9 +1198,17 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 
 		// Execute milestone completion
 		err := service.CompleteMilestonePayment(ctx, smartChequeID, milestoneID1)
+		assert.NoError(t, err)
+
+		// The escrow stays active until its finish validates
+		assert.True(t, smartCheque.Milestones[0].Escrow.Resolving())
+		assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+		confirmLastRecorded(t, mockTransactionRepo, models.TransactionTypeEscrowFinish, escrowObservers(service)...)
 
 		// Assert results
-		assert.NoError(t, err)
+		assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+		assert.Equal(t, models.MilestoneEscrowStatusFinished, smartCheque.Milestones[0].Escrow.Status)
+		assert.Equal(t, models.MilestoneEscrowStatusActive, smartCheque.Milestones[1].Escrow.Status)
 		mockSmartChequeRepo.AssertExpectations(t)
 		mockXRPLService.AssertExpectations(t)
 		mockTransactionRepo.AssertExpectations(t)
@@ -994,29 +1216,24 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 
 	// Phase 3: Complete Second Milestone (Full Completion)
 	t.Run("Phase 3: Complete Second Milestone", func(t *testing.T) {
-		// Update first milestone as completed
-		smartCheque.Milestones[0].Status = models.MilestoneStatusVerified
-		smartCheque.Status = models.SmartChequeStatusInProgress
-
-		mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartChequeID).Return(smartCheque, nil)
-		mockXRPLService.On("GenerateCondition", mock.AnythingOfType("string")).Return("condition456", "fulfillment456", nil)
-
 		finishResult := &xrpl.TransactionResult{
 			TransactionID: uuid.New().String(),
 			ResultCode:    "tesSUCCESS",
 			Validated:     true,
 		}
-		mockXRPLService.On("CompleteSmartChequeMilestone", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(finishResult, nil)
-
-		mockSmartChequeRepo.On("UpdateSmartCheque", ctx, mock.Anything).Return(nil)
-
-		mockTransactionRepo.On("CreateTransaction", mock.Anything).Return(nil)
+		mockXRPLService.On("CompleteSmartChequeMilestone", payeeAddress, payerAddress, uint32(11), conditions[1], fulfillments[1]).Return(finishResult, nil).Once()
 
 		// Execute final milestone completion
 		err := service.CompleteMilestonePayment(ctx, smartChequeID, milestoneID2)
+		assert.NoError(t, err)
+		confirmLastRecorded(t, mockTransactionRepo, models.TransactionTypeEscrowFinish, escrowObservers(service)...)
 
 		// Assert results
-		assert.NoError(t, err)
+		assert.Equal(t, models.SmartChequeStatusCompleted, smartCheque.Status)
+
+		// A milestone whose escrow was finished cannot be released again
+		err = service.CompleteMilestonePayment(ctx, smartChequeID, milestoneID2)
+		assert.ErrorIs(t, err, ErrMilestoneAlreadyReleased)
 		mockSmartChequeRepo.AssertExpectations(t)
 		mockXRPLService.AssertExpectations(t)
 		mockTransactionRepo.AssertExpectations(t)
@@ -1024,20 +1241,14 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 
 	// Phase 4: Verify Health Status
 	t.Run("Phase 4: Verify Health Status", func(t *testing.T) {
-		// Update Smart Check to completed state
-		smartCheque.Status = models.SmartChequeStatusCompleted
-		smartCheque.Milestones[0].Status = models.MilestoneStatusVerified
-		smartCheque.Milestones[1].Status = models.MilestoneStatusVerified
-
-		mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartChequeID).Return(smartCheque, nil)
-
-		escrowInfo := &xrpl.EscrowInfo{
-			Account:     "escrow_tx_123",
-			Destination: payeeAddress,
-			Amount:      "1000000000", // 1000 XRP in drops
-			Flags:       1,            // Finished
+		// The finished escrows are gone from the ledger; their finishes are found in the payer's history
+		for i, milestone := range smartCheque.Milestones {
+			escrow := milestone.Escrow
+			sequence := strconv.FormatUint(uint64(escrow.OfferSequence), 10)
+			mockXRPLService.On("GetEscrowStatus", payerAddress, sequence).Return(nil, fmt.Errorf("failed to get escrow info: %w", xrpl.ErrEntryNotFound))
+			resolution := &xrpl.EscrowResolution{TransactionID: fmt.Sprintf("finish_tx_%d", i), TransactionType: "EscrowFinish", Account: payeeAddress, LedgerIndex: 90210, ResultCode: "tesSUCCESS"}
+			mockXRPLService.On("FindEscrowResolution", payerAddress, escrow.OfferSequence, escrow.LedgerIndex).Return(resolution, nil)
 		}
-		mockXRPLService.On("GetEscrowStatus", "escrow_tx_123", "escrow_tx_123").Return(escrowInfo, nil)
 
 		// Execute health check
 		healthStatus, err := service.GetEscrowHealthStatus(ctx, smartChequeID)
@@ -1045,7 +1256,10 @@ func TestSmartChequeXRPLService_FullLifecycleIntegration(t *testing.T) {
 		// Assert results
 		assert.NoError(t, err)
 		assert.NotNil(t, healthStatus)
-		assert.Contains(t, []string{"active", "inactive", "ready_for_release"}, healthStatus.Health)
+		assert.Equal(t, "released", healthStatus.Health)
+		assert.Len(t, healthStatus.MilestoneEscrows, 2)
+		assert.Equal(t, "finish_tx_1", healthStatus.MilestoneEscrows[1].Resolution.TransactionID)
+		assert.Contains(t, healthStatus.Message, "All 2 milestone escrows were released")
 		mockSmartChequeRepo.AssertExpectations(t)
 		mockXRPLService.AssertExpectations(t)
 	})
@@ -1060,12 +1274,7 @@ func TestSmartChequeXRPLService_CancellationWorkflow(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	ctx := context.Background()
 	smartChequeID := uuid.New().String()
@@ -1075,7 +1284,7 @@ func TestSmartChequeXRPLService_CancellationWorkflow(t *testing.T) {
 		ID:            smartChequeID,
 		PayerID:       uuid.New().String(),
 		PayeeID:       uuid.New().String(),
-		Amount:        1000.0,
+		Amount:        models.MustParseMoney("1000", models.CurrencyUSDT),
 		Currency:      models.CurrencyUSDT,
 		Status:        models.SmartChequeStatusInProgress,
 		EscrowAddress: "escrow_tx_123",
@@ -1083,14 +1292,14 @@ func TestSmartChequeXRPLService_CancellationWorkflow(t *testing.T) {
 			{
 				ID:                 uuid.New().String(),
 				Description:        "Partial work completed",
-				Amount:             600.0,
+				Amount:             models.MustParseMoney("600", models.CurrencyUSDT),
 				VerificationMethod: models.VerificationMethodManual,
 				Status:             models.MilestoneStatusVerified, // Completed
 			},
 			{
 				ID:                 uuid.New().String(),
 				Description:        "Remaining work",
-				Amount:             400.0,
+				Amount:             models.MustParseMoney("400", models.CurrencyUSDT),
 				VerificationMethod: models.VerificationMethodManual,
 				Status:             models.MilestoneStatusPending, // Not completed
 			},
@@ -1116,13 +1325,20 @@ func TestSmartChequeXRPLService_CancellationWorkflow(t *testing.T) {
 
 		// Assert results
 		assert.NoError(t, err)
+		// The verified milestone stays paid and the rest goes back to the payer
+		assert.Equal(t, models.SmartChequeStatusPartiallyPaid, smartCheque.Status)
 		mockSmartChequeRepo.AssertExpectations(t)
 		mockXRPLService.AssertExpectations(t)
 		mockTransactionRepo.AssertExpectations(t)
 	})
 
 	t.Run("Partial Refund Validation", func(t *testing.T) {
+		// A settled Smart Check cannot be refunded again
+		err := service.PartialRefundEscrow(ctx, smartChequeID, 60.0)
+		assert.Error(t, err)
+
 		// Test partial refund
+		smartCheque.Status = models.SmartChequeStatusInProgress
 		mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartChequeID).Return(smartCheque, nil)
 		mockXRPLService.On("CancelSmartCheque", "escrow_tx_123", "escrow_tx_123", uint32(1)).Return(&xrpl.TransactionResult{
 			TransactionID: uuid.New().String(),
@@ -1134,10 +1350,11 @@ func TestSmartChequeXRPLService_CancellationWorkflow(t *testing.T) {
 		mockTransactionRepo.On("CreateTransaction", mock.Anything).Return(nil)
 
 		// Execute partial refund
-		err := service.PartialRefundEscrow(ctx, smartChequeID, 60.0) // 60% refund
+		err = service.PartialRefundEscrow(ctx, smartChequeID, 60.0) // 60% refund
 
 		// Assert results
 		assert.NoError(t, err)
+		assert.Equal(t, models.SmartChequeStatusPartiallyPaid, smartCheque.Status)
 		mockSmartChequeRepo.AssertExpectations(t)
 		mockXRPLService.AssertExpectations(t)
 	})
@@ -1152,12 +1369,7 @@ func TestSmartChequeXRPLService_ErrorScenarios(t *testing.T) {
 	mockMilestoneRepo := &mockMilestoneRepoXRPL{}
 
 	// Create service
-	service := NewSmartChequeXRPLService(
-		mockSmartChequeRepo,
-		mockTransactionRepo,
-		mockXRPLService,
-		mockMilestoneRepo,
-	)
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, mockMilestoneRepo)
 
 	ctx := context.Background()
 	smartChequeID := uuid.New().String()
@@ -1201,3 +1413,260 @@ func TestSmartChequeXRPLService_ErrorScenarios(t *testing.T) {
 		mockSmartChequeRepo.AssertExpectations(t)
 	})
 }
+
+func TestSmartChequeXRPLService_EscrowStatusFollowsLedger(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	service, vault := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, xrplService, &mockMilestoneRepoXRPL{})
+
+	ctx := context.Background()
+	smartCheque := &models.SmartCheque{
+		ID:       uuid.New().String(),
+		PayerID:  uuid.New().String(),
+		Amount:   models.MustParseMoney("25", models.CurrencyXRP),
+		Currency: models.CurrencyXRP,
+		Status:   models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{
+				ID:                 uuid.New().String(),
+				Amount:             models.MustParseMoney("25", models.CurrencyUSDT),
+				VerificationMethod: models.VerificationMethodOracle,
+				OracleConfig:       &models.OracleConfig{Type: "api", Config: map[string]interface{}{"endpoint": "https://api.example.com/verify"}},
+				Status:             models.MilestoneStatusPending,
+			},
+		},
+	}
+	mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	mockSmartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	var records []*models.Transaction
+	mockTransactionRepo.On("CreateTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args.Get(0).(*models.Transaction))
+	}).Return(nil)
+
+	require.NoError(t, service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address()))
+	ledger.CloseLedger()
+	require.Len(t, records, 1)
+	require.NotNil(t, records[0].Sequence)
+	mockTransactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return(records, nil)
+
+	health, err := service.GetEscrowHealthStatus(ctx, smartCheque.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "active", health.Health)
+	require.NotNil(t, health.EscrowInfo)
+	assert.Equal(t, "25000000", health.EscrowInfo.Amount.Value)
+	assert.Equal(t, payee.Address(), health.EscrowInfo.Destination)
+
+	// The payee finishes the escrow directly on the ledger, outside the platform
+	sealed, fulfillment, err := vault.releaseForEscrowFinish(ctx, &PaymentAuthorization{
+		SmartChequeID: smartCheque.ID,
+		MilestoneID:   smartCheque.Milestones[0].ID,
+		Status:        PaymentAuthStatusApproved,
+	})
+	require.NoError(t, err)
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	finished, err := xrplService.CompleteSmartChequeMilestone(payee.Address(), payer.Address(), *records[0].Sequence, sealed.Condition, fulfillment)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	smartCheque.Milestones[0].Status = models.MilestoneStatusVerified
+	require.NoError(t, service.SyncEscrowStatus(ctx, smartCheque.ID))
+	assert.Equal(t, models.SmartChequeStatusCompleted, smartCheque.Status)
+
+	health, err = service.GetEscrowHealthStatus(ctx, smartCheque.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "released", health.Health)
+	require.NotNil(t, health.Resolution)
+	assert.Equal(t, finished.TransactionID, health.Resolution.TransactionID)
+	assert.Nil(t, health.EscrowInfo)
+}
+
+func TestSmartChequeXRPLService_MilestoneEscrowsReleaseIndependently(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, xrplService, &mockMilestoneRepoXRPL{})
+
+	ctx := context.Background()
+	smartCheque := &models.SmartCheque{
+		ID:       uuid.New().String(),
+		PayerID:  uuid.New().String(),
+		Amount:   models.MustParseMoney("25", models.CurrencyXRP),
+		Currency: models.CurrencyXRP,
+		Status:   models.SmartChequeStatusCreated,
+		Milestones: []models.Milestone{
+			{
+				ID:                 uuid.New().String(),
+				Amount:             models.MustParseMoney("10", models.CurrencyXRP),
+				VerificationMethod: models.VerificationMethodManual,
+				Status:             models.MilestoneStatusPending,
+			},
+			{
+				ID:                 uuid.New().String(),
+				Amount:             models.MustParseMoney("15", models.CurrencyXRP),
+				VerificationMethod: models.VerificationMethodManual,
+				Status:             models.MilestoneStatusPending,
+			},
+		},
+	}
+	mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	mockSmartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	var records []*models.Transaction
+	mockTransactionRepo.On("CreateTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args.Get(0).(*models.Transaction))
+	}).Return(nil)
+	require.NoError(t, service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address()))
+	ledger.CloseLedger()
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	require.Len(t, records, 2)
+	mockTransactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return(records, nil)
+
+	// Each milestone holds its own escrow, condition and expiry window
+	first, second := smartCheque.Milestones[0].Escrow, smartCheque.Milestones[1].Escrow
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.NotEqual(t, first.OfferSequence, second.OfferSequence)
+	assert.NotEqual(t, first.Condition, second.Condition)
+	assert.Greater(t, first.CancelAfter, first.FinishAfter)
+	assert.Equal(t, smartCheque.Milestones[0].ID, *records[0].MilestoneID)
+
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+
+	// Completing the first milestone finishes only its escrow, once the finish validates
+	require.NoError(t, service.CompleteMilestonePayment(ctx, smartCheque.ID, smartCheque.Milestones[0].ID))
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	assert.True(t, first.Resolving())
+	confirmRecorded(t, ledger, xrplService, mockTransactionRepo, escrowObservers(service)...)
+	assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+	assert.Equal(t, models.MilestoneEscrowStatusFinished, first.Status)
+	assert.Equal(t, models.MilestoneEscrowStatusActive, second.Status)
+
+	balance, _ := ledger.Balance(payee.Address())
+	assert.Greater(t, balance, int64(29999000))
+	assert.LessOrEqual(t, balance, int64(30000000))
+
+	remaining, err := xrplService.GetEscrowStatus(payer.Address(), strconv.FormatUint(uint64(second.OfferSequence), 10))
+	require.NoError(t, err)
+	assert.Equal(t, "15000000", remaining.Amount.Value)
+
+	// The ledger agrees with the partial release
+	require.NoError(t, service.SyncEscrowStatus(ctx, smartCheque.ID))
+	assert.Equal(t, models.SmartChequeStatusInProgress, smartCheque.Status)
+
+	require.NoError(t, service.CompleteMilestonePayment(ctx, smartCheque.ID, smartCheque.Milestones[1].ID))
+	confirmRecorded(t, ledger, xrplService, mockTransactionRepo, escrowObservers(service)...)
+	assert.Equal(t, models.SmartChequeStatusCompleted, smartCheque.Status)
+
+	health, err := service.GetEscrowHealthStatus(ctx, smartCheque.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "released", health.Health)
+	assert.Len(t, health.MilestoneEscrows, 2)
+}
+
+func TestSmartChequeXRPLService_CheckSettlementOnSimulatedLedger(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, xrplService, &mockMilestoneRepoXRPL{})
+
+	ctx := context.Background()
+	smartCheque := &models.SmartCheque{
+		ID:             uuid.New().String(),
+		PayerID:        uuid.New().String(),
+		PayeeID:        uuid.New().String(),
+		Amount:         models.MustParseMoney("25", models.CurrencyXRP),
+		Currency:       models.CurrencyXRP,
+		Status:         models.SmartChequeStatusCreated,
+		SettlementMode: models.SettlementModeXRPLCheck,
+	}
+	mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	mockSmartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	var records []*models.Transaction
+	mockTransactionRepo.On("CreateTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args.Get(0).(*models.Transaction))
+	}).Return(nil)
+
+	err := service.CreateEscrowForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address())
+	assert.ErrorIs(t, err, ErrSettlementMode)
+
+	require.NoError(t, service.IssueCheckForSmartCheque(ctx, smartCheque.ID, payer.Address(), payee.Address(), 24*time.Hour))
+	ledger.CloseLedger()
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+	require.Len(t, records, 1)
+	mockTransactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return(records, nil)
+
+	check, err := xrplService.GetCheck(smartCheque.CheckID)
+	require.NoError(t, err)
+	assert.Equal(t, "25000000", check.SendMax.Value)
+	assert.Equal(t, payee.Address(), check.Destination)
+
+	// The check is still open, so syncing leaves the Smart Check locked
+	require.NoError(t, service.SyncEscrowStatus(ctx, smartCheque.ID))
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+
+	// Cashing for a minimum takes everything the check allows; the ledger decides the outcome
+	require.NoError(t, service.CashSmartChequeCheck(ctx, smartCheque.ID, models.MustParseMoney("20", models.CurrencyXRP), true))
+	ledger.CloseLedger()
+	assert.Equal(t, models.SmartChequeStatusLocked, smartCheque.Status)
+
+	require.NoError(t, service.SyncEscrowStatus(ctx, smartCheque.ID))
+	assert.Equal(t, models.SmartChequeStatusCompleted, smartCheque.Status)
+	balance, _ := ledger.Balance(payee.Address())
+	assert.Greater(t, balance, int64(44999000))
+	assert.LessOrEqual(t, balance, int64(45000000))
+}
+
+func TestSmartChequeXRPLService_SyncCancelsExpiredCheck(t *testing.T) {
+	mockSmartChequeRepo := &mockSmartChequeRepoXRPL{}
+	mockTransactionRepo := &mockTransactionRepoXRPL{}
+	mockXRPLService := &mockXRPLServiceXRPL{}
+	service, _ := newTestSmartChequeXRPLService(t, mockSmartChequeRepo, mockTransactionRepo, mockXRPLService, &mockMilestoneRepoXRPL{})
+
+	ctx := context.Background()
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	checkID, err := xrpl.CheckIndex(payer.Address(), 7)
+	require.NoError(t, err)
+	smartCheque := &models.SmartCheque{
+		ID:             uuid.New().String(),
+		PayerID:        uuid.New().String(),
+		Amount:         models.MustParseMoney("25", models.CurrencyXRP),
+		Currency:       models.CurrencyXRP,
+		Status:         models.SmartChequeStatusLocked,
+		SettlementMode: models.SettlementModeXRPLCheck,
+		CheckID:        checkID,
+	}
+	sequence := uint32(7)
+	created := models.NewTransaction(models.TransactionTypeCheckCreate, payer.Address(), payee.Address(), "25", "XRP", smartCheque.PayerID, smartCheque.PayerID)
+	created.Sequence = &sequence
+
+	mockSmartChequeRepo.On("GetSmartChequeByID", ctx, smartCheque.ID).Return(smartCheque, nil)
+	mockSmartChequeRepo.On("UpdateSmartCheque", ctx, smartCheque).Return(nil)
+	mockTransactionRepo.On("GetTransactionsBySmartChequeID", smartCheque.ID, 100, 0).Return([]*models.Transaction{created}, nil)
+	mockTransactionRepo.On("CreateTransaction", mock.MatchedBy(func(tx *models.Transaction) bool {
+		return tx.Type == models.TransactionTypeCheckCancel && tx.Metadata["cancellation_reason"] == CancellationReasonExpired
+	})).Return(nil)
+	mockXRPLService.On("GetCheck", checkID).Return(&xrpl.CheckInfo{Account: payer.Address(), Destination: payee.Address(), Expiration: 1}, nil)
+	mockXRPLService.On("CancelSmartChequeCheck", payer.Address(), checkID).Return(&xrpl.TransactionResult{TransactionID: "cancel_tx"}, nil)
+
+	require.NoError(t, service.SyncEscrowStatus(ctx, smartCheque.ID))
+	assert.Equal(t, models.SmartChequeStatusRefunded, smartCheque.Status)
+	mockXRPLService.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+
+	// A refunded Smart Check can no longer be cashed
+	assert.Error(t, service.CashSmartChequeCheck(ctx, smartCheque.ID, models.MustParseMoney("0", models.CurrencyUSDT), false))
+}
@@ -100,6 +100,14 @@ func (m *TestMockBalanceRepository) IsAssetInUse(ctx context.Context, currencyCo
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *TestMockBalanceRepository) GetAssetTransactionsByType(ctx context.Context, txType models.AssetTransactionType, limit, offset int) ([]*models.AssetTransaction, error) {
+	args := m.Called(ctx, txType, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AssetTransaction), args.Error(1)
+}
+
 func (m *TestMockBalanceRepository) FreezeBalance(ctx context.Context, enterpriseID uuid.UUID, currencyCode string, reason string) error {
 	args := m.Called(ctx, enterpriseID, currencyCode, reason)
 	return args.Error(0)
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// TicketPoolConfig configures which accounts submit with tickets and how many they keep
+type TicketPoolConfig struct {
+	// Accounts lists the hot accounts, such as treasury wallets, whose transactions use tickets
+	Accounts []string
+	// Target is how many unused tickets a top-up leaves an account with; defaults to 20
+	Target int
+	// LowWater is the unused ticket count below which an account is topped up; defaults to Target/4
+	LowWater int
+}
+
+// TicketPool hands out pre-allocated XRPL tickets so independent transactions from one account
+// can be submitted concurrently. A leased ticket stays with its transaction across retries until
+// the ledger consumes it or the transaction fails terminally and it is released.
+type TicketPool struct {
+	xrplService *XRPLService
+	config      TicketPoolConfig
+
+	// refillMu serializes top-ups so concurrent batches do not create tickets twice
+	refillMu sync.Mutex
+
+	mu        sync.Mutex
+	available map[string][]uint32
+	leased    map[string]map[uint32]bool
+}
+
+// NewTicketPool creates a ticket pool for the configured hot accounts
+func NewTicketPool(xrplService *XRPLService, config TicketPoolConfig) *TicketPool {
+	if config.Target <= 0 {
+		config.Target = 20
+	}
+	if config.Target > xrpl.MaxTicketsPerAccount {
+		config.Target = xrpl.MaxTicketsPerAccount
+	}
+	if config.LowWater <= 0 {
+		config.LowWater = config.Target / 4
+	}
+
+	pool := &TicketPool{
+		xrplService: xrplService,
+		config:      config,
+		available:   make(map[string][]uint32),
+		leased:      make(map[string]map[uint32]bool),
+	}
+	for _, account := range config.Accounts {
+		pool.available[account] = []uint32{}
+		pool.leased[account] = make(map[uint32]bool)
+	}
+	return pool
+}
+
+// Accounts returns the hot accounts the pool manages
+func (p *TicketPool) Accounts() []string {
+	return append([]string(nil), p.config.Accounts...)
+}
+
+// Enabled reports whether an account submits with tickets
+func (p *TicketPool) Enabled(account string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.leased[account]
+	return ok
+}
+
+// Available returns how many unused tickets an account has in the pool
+func (p *TicketPool) Available(account string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.available[account])
+}
+
+// Acquire leases the lowest unused ticket of an account, reporting false when none is left
+func (p *TicketPool) Acquire(account string) (uint32, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tickets := p.available[account]
+	if len(tickets) == 0 {
+		return 0, false
+	}
+	ticket := tickets[0]
+	p.available[account] = tickets[1:]
+	p.leased[account][ticket] = true
+	return ticket, true
+}
+
+// Release returns a leased ticket the ledger did not consume so another transaction can use it
+func (p *TicketPool) Release(account string, ticket uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.leased[account][ticket] {
+		return
+	}
+	delete(p.leased[account], ticket)
+	p.available[account] = insertTicket(p.available[account], ticket)
+}
+
+// Consume forgets a leased ticket the ledger has used up
+func (p *TicketPool) Consume(account string, ticket uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.leased[account], ticket)
+}
+
+// Sync reloads an account's unused tickets from the validated ledger, keeping leased tickets out of the pool
+func (p *TicketPool) Sync(account string) error {
+	tickets, err := p.xrplService.GetTickets(account)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	leased, ok := p.leased[account]
+	if !ok {
+		return fmt.Errorf("account %s does not use tickets", account)
+	}
+	available := make([]uint32, 0, len(tickets))
+	for _, ticket := range tickets {
+		if !leased[ticket] {
+			available = append(available, ticket)
+		}
+	}
+	p.available[account] = available
+	return nil
+}
+
+// Ensure tops up an account so at least needed tickets are unused, creating tickets on the ledger
+// when the pool runs low and waiting until they are validated
+func (p *TicketPool) Ensure(ctx context.Context, account string, needed int) error {
+	if !p.Enabled(account) {
+		return fmt.Errorf("account %s does not use tickets", account)
+	}
+
+	p.refillMu.Lock()
+	defer p.refillMu.Unlock()
+
+	p.mu.Lock()
+	available := len(p.available[account])
+	held := available + len(p.leased[account])
+	p.mu.Unlock()
+	if available >= needed && available >= p.config.LowWater {
+		return nil
+	}
+
+	count := p.config.Target
+	if needed > count {
+		count = needed
+	}
+	count -= available
+	if held+count > xrpl.MaxTicketsPerAccount {
+		count = xrpl.MaxTicketsPerAccount - held
+	}
+	if count <= 0 {
+		return fmt.Errorf("account %s already holds the maximum of %d tickets", account, xrpl.MaxTicketsPerAccount)
+	}
+
+	result, err := p.xrplService.CreateTickets(account, uint32(count))
+	if err != nil {
+		return err
+	}
+	if _, err := p.xrplService.WaitForValidation(ctx, result.TransactionID, result.LastLedgerSequence); err != nil {
+		return fmt.Errorf("tickets for %s were not created: %w", account, err)
+	}
+
+	log.Printf("Created %d tickets for %s", count, account)
+	return p.Sync(account)
+}
+
+// insertTicket adds a ticket to a sorted list
+func insertTicket(tickets []uint32, ticket uint32) []uint32 {
+	i := sort.Search(len(tickets), func(i int) bool { return tickets[i] >= ticket })
+	tickets = append(tickets, 0)
+	copy(tickets[i+1:], tickets[i:])
+	tickets[i] = ticket
+	return tickets
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketPool_EnsureAcquireRelease(t *testing.T) {
+	treasury := newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{treasury.Address(): treasury})
+	require.NoError(t, ledger.Fund(treasury.Address(), 100000000))
+	stop := ledger.AutoClose(20 * time.Millisecond)
+	defer stop()
+
+	pool := NewTicketPool(xrplService, TicketPoolConfig{Accounts: []string{treasury.Address()}, Target: 4})
+	assert.False(t, pool.Enabled(newTestKeyPair(t).Address()))
+	_, ok := pool.Acquire(treasury.Address())
+	assert.False(t, ok, "no tickets before the first top-up")
+
+	require.NoError(t, pool.Ensure(context.Background(), treasury.Address(), 2))
+	assert.Equal(t, 4, pool.Available(treasury.Address()))
+
+	first, ok := pool.Acquire(treasury.Address())
+	require.True(t, ok)
+	second, ok := pool.Acquire(treasury.Address())
+	require.True(t, ok)
+	assert.Less(t, first, second)
+
+	// Leased tickets stay out of the pool when it is reloaded from the ledger
+	require.NoError(t, pool.Sync(treasury.Address()))
+	assert.Equal(t, 2, pool.Available(treasury.Address()))
+
+	pool.Release(treasury.Address(), first)
+	pool.Consume(treasury.Address(), second)
+	assert.Equal(t, 3, pool.Available(treasury.Address()))
+	again, ok := pool.Acquire(treasury.Address())
+	require.True(t, ok)
+	assert.Equal(t, first, again, "released tickets are handed out first")
+
+	// Enough tickets above the low-water mark need no top-up
+	require.NoError(t, pool.Ensure(context.Background(), treasury.Address(), 1))
+	tickets, err := xrplService.GetTickets(treasury.Address())
+	require.NoError(t, err)
+	assert.Len(t, tickets, 4)
+}
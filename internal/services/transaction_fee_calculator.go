@@ -1,31 +1,170 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
+// ErrFeeCapExceeded is returned when the ledger charges more than a transaction is allowed to pay
+var ErrFeeCapExceeded = errors.New("network fee exceeds fee cap")
+
+// NetworkFeeSource reports the live open ledger transaction cost
+type NetworkFeeSource interface {
+	GetNetworkFees() (*xrpl.FeeInfo, error)
+}
+
+// FeeCaps bounds the fee in drops a transaction of each priority may pay
+type FeeCaps map[models.TransactionPriority]int64
+
 // FeeCalculator handles transaction fee calculation and optimization
 type FeeCalculator struct {
 	baseFeeDrops     int64   // Base fee in drops
 	reserveIncrement int64   // Reserve increment for account objects
 	maxFeeDrops      int64   // Maximum fee allowed
 	feeMultiplier    float64 // Multiplier for fee escalation
+
+	// network supplies live fees; without it fees are estimated from fixed costs
+	network NetworkFeeSource
+
+	capsMutex      sync.RWMutex
+	enterpriseCaps map[string]FeeCaps
 }
 
-// NewFeeCalculator creates a new fee calculator
+// NewFeeCalculator creates a fee calculator that estimates fees without live network data
 func NewFeeCalculator() *FeeCalculator {
+	return NewFeeCalculatorWithNetwork(nil)
+}
+
+// NewFeeCalculatorWithNetwork creates a fee calculator that prices transactions from the live open ledger cost
+func NewFeeCalculatorWithNetwork(network NetworkFeeSource) *FeeCalculator {
 	return &FeeCalculator{
 		baseFeeDrops:     10,      // 10 drops base fee
 		reserveIncrement: 5000000, // 5 XRP in drops
 		maxFeeDrops:      10000,   // 0.01 XRP maximum fee
 		feeMultiplier:    1.2,     // 20% fee escalation for retries
+		network:          network,
+		enterpriseCaps:   make(map[string]FeeCaps),
+	}
+}
+
+// SetEnterpriseFeeCaps configures the most an enterprise's transactions of each priority may pay.
+// Priorities without a cap are bounded by the calculator's maximum fee.
+func (f *FeeCalculator) SetEnterpriseFeeCaps(enterpriseID string, caps FeeCaps) {
+	f.capsMutex.Lock()
+	defer f.capsMutex.Unlock()
+
+	copied := make(FeeCaps, len(caps))
+	for priority, limit := range caps {
+		copied[priority] = limit
+	}
+	f.enterpriseCaps[enterpriseID] = copied
+}
+
+// FeeCap returns the most a transaction may pay in drops
+func (f *FeeCalculator) FeeCap(transaction *models.Transaction) int64 {
+	f.capsMutex.RLock()
+	defer f.capsMutex.RUnlock()
+
+	limit, ok := f.enterpriseCaps[transaction.EnterpriseID][transaction.Priority]
+	if !ok || limit <= 0 || limit > f.maxFeeDrops {
+		return f.maxFeeDrops
+	}
+	return limit
+}
+
+// ApplyNetworkFee prices a transaction from the live open ledger cost, scaled by its priority and
+// bounded by its fee cap, and records the cost the ledger required at the time
+func (f *FeeCalculator) ApplyNetworkFee(transaction *models.Transaction) error {
+	fees, err := f.networkFees()
+	if err != nil {
+		return err
+	}
+	required, err := f.requiredFee(transaction, fees.OpenLedgerFee)
+	if err != nil {
+		return err
+	}
+
+	feeCap := f.FeeCap(transaction)
+	if required > feeCap {
+		return fmt.Errorf("%w: transaction %s needs %d drops, cap is %d", ErrFeeCapExceeded, transaction.ID, required, feeCap)
+	}
+
+	// Priority buys headroom over the current cost; it never pays less than the ledger requires
+	fee := int64(math.Ceil(float64(required) * f.getPriorityMultiplier(transaction.Priority)))
+	if fee < required {
+		fee = required
 	}
+	if fee > feeCap {
+		fee = feeCap
+	}
+
+	transaction.Fee = strconv.FormatInt(fee, 10)
+	transaction.NetworkFee = strconv.FormatInt(required, 10)
+	log.Printf("Priced transaction %s at %d drops (open ledger cost %d, load factor %.2f, cap %d)",
+		transaction.ID, fee, required, fees.LoadFactor(), feeCap)
+	return nil
+}
+
+// BumpFee raises the fee of a transaction the ledger rejected as too low to at least the current
+// open ledger cost, failing with ErrFeeCapExceeded once it already pays its cap
+func (f *FeeCalculator) BumpFee(transaction *models.Transaction) error {
+	current, err := strconv.ParseInt(transaction.Fee, 10, 64)
+	if err != nil {
+		current = 0
+	}
+
+	feeCap := f.FeeCap(transaction)
+	if current >= feeCap {
+		return fmt.Errorf("%w: transaction %s already pays %d drops", ErrFeeCapExceeded, transaction.ID, current)
+	}
+
+	bumped := int64(math.Ceil(float64(current) * f.feeMultiplier))
+	if fees, err := f.networkFees(); err == nil {
+		if required, err := f.requiredFee(transaction, fees.OpenLedgerFee); err == nil {
+			transaction.NetworkFee = strconv.FormatInt(required, 10)
+			if bumped < required {
+				bumped = required
+			}
+		}
+	}
+	if bumped <= current {
+		bumped = current + 1
+	}
+	if bumped < f.baseFeeDrops {
+		bumped = f.baseFeeDrops
+	}
+	if bumped > feeCap {
+		bumped = feeCap
+	}
+
+	log.Printf("Bumped fee of transaction %s: %d -> %d drops", transaction.ID, current, bumped)
+	transaction.Fee = strconv.FormatInt(bumped, 10)
+	return nil
+}
+
+// networkFees fetches the live open ledger cost
+func (f *FeeCalculator) networkFees() (*xrpl.FeeInfo, error) {
+	if f.network == nil {
+		return nil, fmt.Errorf("no network fee source configured")
+	}
+	return f.network.GetNetworkFees()
+}
+
+// requiredFee scales a reference transaction cost to what a transaction must pay: an escrow
+// finish with a fulfillment pays extra for verifying it
+func (f *FeeCalculator) requiredFee(transaction *models.Transaction, referenceFee int64) (int64, error) {
+	if transaction.Type == models.TransactionTypeEscrowFinish && transaction.Fulfillment != "" {
+		return xrpl.EscrowFinishFee(referenceFee, transaction.Fulfillment)
+	}
+	return referenceFee, nil
 }
 
 // CalculateTransactionFee calculates the optimal fee for a single transaction
@@ -60,10 +199,10 @@ func (f *FeeCalculator) CalculateTransactionFee(transaction *models.Transaction,
 		adjustedFee *= retryMultiplier
 	}
 
-	// Ensure fee doesn't exceed maximum
+	// Ensure fee doesn't exceed the transaction's cap
 	finalFee := int64(math.Ceil(adjustedFee))
-	if finalFee > f.maxFeeDrops {
-		finalFee = f.maxFeeDrops
+	if feeCap := f.FeeCap(transaction); finalFee > feeCap {
+		finalFee = feeCap
 	}
 
 	log.Printf("Calculated fee for transaction %s: %d drops (base: %d, network: %.2f, priority: %.2f, retries: %d)",
@@ -123,21 +262,23 @@ func (f *FeeCalculator) OptimizeBatchFees(batch *models.TransactionBatch, transa
 	return nil
 }
 
-// EstimateNetworkLoad estimates current network load based on recent transaction data
+// EstimateNetworkLoad reports how far the open ledger cost is escalated above the reference cost:
+// 0 on an idle ledger and 1 when it costs twice the reference. Without live fees the ledger is
+// assumed idle and a rejected fee is bumped on submission.
 func (f *FeeCalculator) EstimateNetworkLoad() float64 {
-	// This is a simplified network load estimation
-	// In production, this would analyze:
-	// - Recent transaction confirmation times
-	// - Current fee levels being paid
-	// - Network ledger close times
-	// - Queue depth
-
-	// For now, return a random value between 0.1 and 0.8
-	// representing low to moderate network load
-	return 0.3 // Assuming moderate network load
+	if f.network == nil {
+		return 0
+	}
+	fees, err := f.network.GetNetworkFees()
+	if err != nil {
+		log.Printf("Failed to get network fees, assuming an idle ledger: %v", err)
+		return 0
+	}
+	return fees.LoadFactor() - 1
 }
 
-// CalculateFeeForRetry calculates an escalated fee for a retry attempt
+// CalculateFeeForRetry calculates the fee for a retry attempt. The fee is escalated unless the
+// recorded ledger outcome shows the previous fee was not why the transaction failed.
 func (f *FeeCalculator) CalculateFeeForRetry(originalTransaction *models.Transaction, retryCount int) (string, error) {
 	// Parse original fee
 	originalFee, err := strconv.ParseInt(originalTransaction.Fee, 10, 64)
@@ -147,14 +288,18 @@ func (f *FeeCalculator) CalculateFeeForRetry(originalTransaction *models.Transac
 		return f.CalculateTransactionFee(originalTransaction, networkLoad)
 	}
 
+	if originalTransaction.ResultCode != "" && !retryNeedsHigherFee(originalTransaction.ResultCode) {
+		return originalTransaction.Fee, nil
+	}
+
 	// Escalate fee for retry
 	escalationMultiplier := math.Pow(f.feeMultiplier, float64(retryCount))
 	escalatedFee := float64(originalFee) * escalationMultiplier
 
-	// Ensure fee doesn't exceed maximum
+	// Ensure fee doesn't exceed the transaction's cap
 	finalFee := int64(math.Ceil(escalatedFee))
-	if finalFee > f.maxFeeDrops {
-		finalFee = f.maxFeeDrops
+	if feeCap := f.FeeCap(originalTransaction); finalFee > feeCap {
+		finalFee = feeCap
 	}
 
 	log.Printf("Escalated fee for retry %d of transaction %s: %d -> %d drops",
@@ -163,6 +308,12 @@ func (f *FeeCalculator) CalculateFeeForRetry(originalTransaction *models.Transac
 	return strconv.FormatInt(finalFee, 10), nil
 }
 
+// retryNeedsHigherFee reports whether a ledger outcome calls for a higher fee: the fee was rejected
+// as too low, or the transaction expired waiting to be included
+func retryNeedsHigherFee(resultCode string) bool {
+	return xrpl.IsFeeRelated(resultCode) || resultCode == "tefMAX_LEDGER"
+}
+
 // ValidateFee validates if a fee is reasonable for a transaction
 func (f *FeeCalculator) ValidateFee(_ *models.Transaction, proposedFee string) error {
 	fee, err := strconv.ParseInt(proposedFee, 10, 64)
@@ -278,7 +429,9 @@ func (f *FeeCalculator) calculateBatchDiscount(batchSize int) float64 {
 	}
 }
 
-// AnalyzeFeeEfficiency analyzes the fee efficiency of completed transactions
+// AnalyzeFeeEfficiency compares the fees transactions paid with what the ledger required when they
+// were priced. A transaction the ledger applied carries the fee it was charged; one never applied
+// paid nothing and is compared on its planned fee.
 func (f *FeeCalculator) AnalyzeFeeEfficiency(transactions []*models.Transaction) map[string]interface{} {
 	if len(transactions) == 0 {
 		return map[string]interface{}{
@@ -288,6 +441,10 @@ func (f *FeeCalculator) AnalyzeFeeEfficiency(transactions []*models.Transaction)
 	}
 
 	totalFee := int64(0)
+	paidFee := int64(0)
+	excessFee := int64(0)
+	pricedCount := 0
+	paidCount := 0
 	overpaidCount := 0
 	underpaidCount := 0
 	optimalCount := 0
@@ -303,17 +460,18 @@ func (f *FeeCalculator) AnalyzeFeeEfficiency(transactions []*models.Transaction)
 		}
 
 		totalFee += fee
-
-		// Compare with optimal fee (simplified analysis)
-		optimalFee := f.baseFeeDrops
-		switch tx.Type {
-		case models.TransactionTypeEscrowCreate, models.TransactionTypeEscrowFinish, models.TransactionTypeEscrowCancel:
-			optimalFee = 12
-		case models.TransactionTypeWalletSetup:
-			optimalFee = 15
+		pricedCount++
+
+		required := f.recordedRequiredFee(tx)
+		if chargedByLedger(tx) {
+			paidCount++
+			paidFee += fee
+			if fee > required {
+				excessFee += fee - required
+			}
 		}
 
-		feeRatio := float64(fee) / float64(optimalFee)
+		feeRatio := float64(fee) / float64(required)
 		if feeRatio > 1.5 {
 			overpaidCount++
 		} else if feeRatio < 0.8 {
@@ -323,13 +481,43 @@ func (f *FeeCalculator) AnalyzeFeeEfficiency(transactions []*models.Transaction)
 		}
 	}
 
+	averageFee := int64(0)
+	if pricedCount > 0 {
+		averageFee = totalFee / int64(pricedCount)
+	}
+
 	return map[string]interface{}{
 		"total_transactions": len(transactions),
 		"total_fee_drops":    totalFee,
-		"average_fee_drops":  totalFee / int64(len(transactions)),
+		"average_fee_drops":  averageFee,
+		"paid_count":         paidCount,
+		"paid_fee_drops":     paidFee,
+		"excess_fee_drops":   excessFee,
 		"optimal_count":      optimalCount,
 		"overpaid_count":     overpaidCount,
 		"underpaid_count":    underpaidCount,
 		"efficiency_score":   float64(optimalCount) / float64(len(transactions)) * 100,
 	}
 }
+
+// recordedRequiredFee returns the open ledger cost recorded when a transaction was priced, or the
+// reference cost of its type when none was recorded
+func (f *FeeCalculator) recordedRequiredFee(transaction *models.Transaction) int64 {
+	if required, err := strconv.ParseInt(transaction.NetworkFee, 10, 64); err == nil && required > 0 {
+		return required
+	}
+	required, err := f.requiredFee(transaction, f.baseFeeDrops)
+	if err != nil {
+		return f.baseFeeDrops
+	}
+	return required
+}
+
+// chargedByLedger reports whether a transaction was applied by a validated ledger, which charges its fee
+// even when the transaction itself failed
+func chargedByLedger(transaction *models.Transaction) bool {
+	if transaction.LedgerIndex == nil {
+		return false
+	}
+	return transaction.ResultCode == "tesSUCCESS" || strings.HasPrefix(transaction.ResultCode, "tec")
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/google/uuid"
@@ -8,8 +9,18 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
+// staticFees is a NetworkFeeSource reporting a fixed open ledger cost
+type staticFees struct {
+	openLedgerFee int64
+}
+
+func (s staticFees) GetNetworkFees() (*xrpl.FeeInfo, error) {
+	return &xrpl.FeeInfo{BaseFee: 10, MinimumFee: 10, OpenLedgerFee: s.openLedgerFee}, nil
+}
+
 func TestNewFeeCalculator(t *testing.T) {
 	calculator := NewFeeCalculator()
 
@@ -430,6 +441,77 @@ func TestFeeCalculator_NetworkLoadImpact(t *testing.T) {
 	assert.LessOrEqual(t, mediumLoadInt, highLoadInt)
 }
 
+func TestFeeCalculator_ApplyNetworkFee(t *testing.T) {
+	calculator := NewFeeCalculatorWithNetwork(staticFees{openLedgerFee: 40})
+	calculator.SetEnterpriseFeeCaps("enterprise-1", FeeCaps{models.PriorityHigh: 50, models.PriorityLow: 30})
+
+	assert.InDelta(t, 3.0, calculator.EstimateNetworkLoad(), 0.001)
+
+	normal := &models.Transaction{ID: "normal", Type: models.TransactionTypePayment, Priority: models.PriorityNormal, EnterpriseID: "enterprise-1"}
+	require.NoError(t, calculator.ApplyNetworkFee(normal))
+	assert.Equal(t, "40", normal.Fee)
+	assert.Equal(t, "40", normal.NetworkFee)
+
+	// Priority pays above the open ledger cost up to the enterprise cap
+	high := &models.Transaction{ID: "high", Type: models.TransactionTypePayment, Priority: models.PriorityHigh, EnterpriseID: "enterprise-1"}
+	require.NoError(t, calculator.ApplyNetworkFee(high))
+	assert.Equal(t, "50", high.Fee)
+
+	low := &models.Transaction{ID: "low", Type: models.TransactionTypePayment, Priority: models.PriorityLow, EnterpriseID: "enterprise-1"}
+	assert.ErrorIs(t, calculator.ApplyNetworkFee(low), ErrFeeCapExceeded)
+	assert.Empty(t, low.Fee)
+
+	// Caps are per enterprise
+	other := &models.Transaction{ID: "other", Type: models.TransactionTypePayment, Priority: models.PriorityLow, EnterpriseID: "enterprise-2"}
+	require.NoError(t, calculator.ApplyNetworkFee(other))
+	assert.Equal(t, "40", other.Fee)
+
+	// An escrow finish pays the fulfillment surcharge on top of the open ledger cost
+	_, fulfillment, err := xrpl.NewClient("", true).GenerateCondition("milestone")
+	require.NoError(t, err)
+	finish := &models.Transaction{ID: "finish", Type: models.TransactionTypeEscrowFinish, Priority: models.PriorityNormal, Fulfillment: fulfillment}
+	require.NoError(t, calculator.ApplyNetworkFee(finish))
+	expected, err := xrpl.EscrowFinishFee(40, fulfillment)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(expected, 10), finish.Fee)
+}
+
+func TestFeeCalculator_BumpFee(t *testing.T) {
+	calculator := NewFeeCalculatorWithNetwork(staticFees{openLedgerFee: 25})
+	calculator.SetEnterpriseFeeCaps("enterprise-1", FeeCaps{models.PriorityNormal: 100})
+
+	tx := &models.Transaction{ID: "bumped", Type: models.TransactionTypePayment, Priority: models.PriorityNormal, EnterpriseID: "enterprise-1", Fee: "10"}
+	require.NoError(t, calculator.BumpFee(tx))
+	assert.Equal(t, "25", tx.Fee, "a bump reaches at least the open ledger cost")
+	assert.Equal(t, "25", tx.NetworkFee)
+
+	require.NoError(t, calculator.BumpFee(tx))
+	assert.Equal(t, "30", tx.Fee, "above the open ledger cost the fee escalates")
+
+	tx.Fee = "90"
+	require.NoError(t, calculator.BumpFee(tx))
+	assert.Equal(t, "100", tx.Fee)
+	assert.ErrorIs(t, calculator.BumpFee(tx), ErrFeeCapExceeded)
+}
+
+func TestFeeCalculator_AnalyzeFeeEfficiencyUsesPaidFees(t *testing.T) {
+	calculator := NewFeeCalculator()
+	ledgerIndex := uint32(90000)
+
+	transactions := []*models.Transaction{
+		{Fee: "30", NetworkFee: "30", ResultCode: "tesSUCCESS", LedgerIndex: &ledgerIndex},
+		{Fee: "60", NetworkFee: "30", ResultCode: "tecNO_DST_INSUF_XRP", LedgerIndex: &ledgerIndex},
+		{Fee: "30", NetworkFee: "30", ResultCode: "telINSUF_FEE_P"},
+	}
+
+	result := calculator.AnalyzeFeeEfficiency(transactions)
+	assert.Equal(t, 2, result["paid_count"])
+	assert.Equal(t, int64(90), result["paid_fee_drops"])
+	assert.Equal(t, int64(30), result["excess_fee_drops"])
+	assert.Equal(t, 2, result["optimal_count"])
+	assert.Equal(t, 1, result["overpaid_count"])
+}
+
 // Helper function to parse int64 from string (for testing)
 func parseInt64(t *testing.T, s string) int64 {
 	t.Helper()
@@ -2,9 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,8 +14,15 @@ import (
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
+// validationTimeout bounds how long the queue waits for a submission to be validated or expire
+const validationTimeout = 5 * time.Minute
+
+// maxFeeBumps bounds how often one attempt resubmits with a higher fee after the ledger rejects it as too low
+const maxFeeBumps = 3
+
 // TransactionQueueService manages transaction queuing, batching, and processing
 type TransactionQueueService struct {
 	transactionRepo       repository.TransactionRepositoryInterface
@@ -22,6 +30,10 @@ type TransactionQueueService struct {
 	messagingService      *messaging.Service
 	fraudDetectionService FraudDetectionServiceInterface
 	batchConfig           models.BatchConfig
+	// tickets lets hot accounts submit independent transactions out of order; nil disables tickets
+	tickets *TicketPool
+	// feeCalculator prices transactions from the live open ledger cost within enterprise fee caps
+	feeCalculator *FeeCalculator
 
 	// Queue management
 	processingQueue chan *models.Transaction
@@ -50,12 +62,32 @@ func NewTransactionQueueService(
 	fraudDetectionService FraudDetectionServiceInterface,
 	config models.BatchConfig,
 ) *TransactionQueueService {
+	return NewTransactionQueueServiceWithTickets(transactionRepo, xrplService, messagingService, fraudDetectionService, config, nil)
+}
+
+// NewTransactionQueueServiceWithTickets creates a transaction queue service that submits escrows and
+// payments from the pool's hot accounts with tickets
+func NewTransactionQueueServiceWithTickets(
+	transactionRepo repository.TransactionRepositoryInterface,
+	xrplService *XRPLService,
+	messagingService *messaging.Service,
+	fraudDetectionService FraudDetectionServiceInterface,
+	config models.BatchConfig,
+	tickets *TicketPool,
+) *TransactionQueueService {
+	feeCalculator := NewFeeCalculator()
+	if xrplService != nil {
+		feeCalculator = NewFeeCalculatorWithNetwork(xrplService)
+	}
+
 	return &TransactionQueueService{
 		transactionRepo:       transactionRepo,
 		xrplService:           xrplService,
 		messagingService:      messagingService,
 		fraudDetectionService: fraudDetectionService,
 		batchConfig:           config,
+		tickets:               tickets,
+		feeCalculator:         feeCalculator,
 		processingQueue:       make(chan *models.Transaction, 1000),
 		batchingQueue:         make(chan *models.Transaction, 1000),
 		activeBatches:         make(map[string]*models.TransactionBatch),
@@ -64,6 +96,11 @@ func NewTransactionQueueService(
 	}
 }
 
+// FeeCalculator returns the calculator pricing queued transactions, where enterprise fee caps are configured
+func (s *TransactionQueueService) FeeCalculator() *FeeCalculator {
+	return s.feeCalculator
+}
+
 // Start begins the transaction queue processing
 func (s *TransactionQueueService) Start() error {
 	s.stopMutex.Lock()
@@ -76,6 +113,15 @@ func (s *TransactionQueueService) Start() error {
 	s.isRunning = true
 	log.Println("Starting Transaction Queue Service...")
 
+	// Pick up tickets hot accounts already hold so they are not created again
+	if s.tickets != nil {
+		for _, account := range s.tickets.Accounts() {
+			if err := s.tickets.Sync(account); err != nil {
+				log.Printf("Warning: Failed to load tickets for %s: %v", account, err)
+			}
+		}
+	}
+
 	// Start background workers
 	s.wg.Add(4)
 	go s.queueProcessor()
@@ -227,6 +273,17 @@ func (s *TransactionQueueService) RetryFailedTransactions() error {
 			tx.RetryCount++
 			tx.UpdatedAt = time.Now()
 
+			// The fee only rises when the ledger outcome shows it was too low; a transaction that
+			// was never priced is priced from live fees when it is processed
+			if tx.Fee != "" {
+				fee, err := s.feeCalculator.CalculateFeeForRetry(tx, tx.RetryCount)
+				if err != nil {
+					log.Printf("Failed to calculate retry fee for transaction %s: %v", tx.ID, err)
+				} else {
+					tx.Fee = fee
+				}
+			}
+
 			if err := s.transactionRepo.UpdateTransaction(tx); err != nil {
 				log.Printf("Failed to update retry transaction %s: %v", tx.ID, err)
 				continue
@@ -266,6 +323,7 @@ func (s *TransactionQueueService) ExpireOldTransactions() error {
 			tx.Status == models.TransactionStatusBatched {
 			tx.Status = models.TransactionStatusExpired
 			tx.UpdatedAt = time.Now()
+			s.releaseTicket(tx)
 
 			if err := s.transactionRepo.UpdateTransaction(tx); err != nil {
 				log.Printf("Failed to update expired transaction %s: %v", tx.ID, err)
@@ -381,6 +439,8 @@ func (s *TransactionQueueService) statusMonitor() {
 				s.stats = stats
 				s.statsMutex.Unlock()
 			}
+
+			s.replenishTickets()
 		}
 	}
 }
@@ -493,18 +553,34 @@ func (s *TransactionQueueService) processBatch(batch *models.TransactionBatch) {
 		log.Printf("Failed to update batch status: %v", err)
 	}
 
-	// Process each transaction in the batch
+	// Process each transaction in the batch; those holding tickets do not wait on one another
 	successCount := 0
 	failureCount := 0
-
-	for _, tx := range transactions {
-		if s.processTransaction(tx) {
+	var countMutex sync.Mutex
+	record := func(success bool) {
+		countMutex.Lock()
+		defer countMutex.Unlock()
+		if success {
 			successCount++
 		} else {
 			failureCount++
 		}
 	}
 
+	ticketed, sequential := s.leaseBatchTickets(transactions)
+	var wg sync.WaitGroup
+	for _, tx := range ticketed {
+		wg.Add(1)
+		go func(tx *models.Transaction) {
+			defer wg.Done()
+			record(s.processTransaction(tx))
+		}(tx)
+	}
+	for _, tx := range sequential {
+		record(s.processTransaction(tx))
+	}
+	wg.Wait()
+
 	// Update batch completion status
 	batch.SuccessCount = successCount
 	batch.FailureCount = failureCount
@@ -598,10 +674,7 @@ func (s *TransactionQueueService) processTransaction(transaction *models.Transac
 
 	// Calculate fee if not already set
 	if transaction.Fee == "" {
-		feeCalculator := NewFeeCalculator()
-		networkLoad := feeCalculator.EstimateNetworkLoad()
-		fee, err := feeCalculator.CalculateTransactionFee(transaction, networkLoad)
-		if err != nil {
+		if err := s.priceTransaction(transaction); err != nil {
 			log.Printf("Failed to calculate fee for transaction %s: %v", transaction.ID, err)
 			transaction.SetError(fmt.Errorf("fee calculation failed: %w", err))
 			if err := s.transactionRepo.UpdateTransaction(transaction); err != nil {
@@ -609,29 +682,24 @@ func (s *TransactionQueueService) processTransaction(transaction *models.Transac
 			}
 			return false
 		}
-		transaction.Fee = fee
 	}
 
 	// Process based on transaction type
 	var err error
 	switch transaction.Type {
-	case models.TransactionTypeEscrowCreate:
-		err = s.processEscrowCreate(transaction)
-	case models.TransactionTypeEscrowFinish:
-		err = s.processEscrowFinish(transaction)
-	case models.TransactionTypeEscrowCancel:
-		err = s.processEscrowCancel(transaction)
-	case models.TransactionTypePayment:
-		err = s.processPayment(transaction)
 	case models.TransactionTypeWalletSetup:
+		// Wallet setup generates keys locally and submits nothing to the ledger
 		err = s.processWalletSetup(transaction)
 	default:
-		err = fmt.Errorf("unsupported transaction type: %s", transaction.Type)
+		err = s.submitToLedger(transaction)
 	}
 
 	if err != nil {
 		log.Printf("Transaction %s failed: %v", transaction.ID, err)
-		transaction.SetError(err)
+		s.recordFailure(transaction, err)
+		if !transaction.CanRetry() {
+			s.releaseTicket(transaction)
+		}
 		if err := s.transactionRepo.UpdateTransaction(transaction); err != nil {
 			log.Printf("Failed to update transaction with error: %v", err)
 		}
@@ -643,6 +711,10 @@ func (s *TransactionQueueService) processTransaction(transaction *models.Transac
 		return false
 	}
 
+	if transaction.TicketSequence != nil && s.tickets != nil {
+		s.tickets.Consume(transaction.FromAddress, *transaction.TicketSequence)
+	}
+
 	// Mark as confirmed
 	transaction.Status = models.TransactionStatusConfirmed
 	transaction.UpdatedAt = time.Now()
@@ -662,6 +734,167 @@ func (s *TransactionQueueService) processTransaction(transaction *models.Transac
 	return true
 }
 
+// submitToLedger submits a transaction and waits until the ledger decides its outcome. A previous
+// submission whose outcome was never learned is settled first so it is not applied twice.
+func (s *TransactionQueueService) submitToLedger(transaction *models.Transaction) error {
+	if transaction.TransactionHash != "" && transaction.LastLedgerSequence != nil {
+		err := s.awaitValidation(transaction)
+		if err == nil {
+			log.Printf("Earlier submission %s of transaction %s was validated", transaction.TransactionHash, transaction.ID)
+			return nil
+		}
+		if !errors.Is(err, xrpl.ErrTransactionExpired) {
+			return err
+		}
+		clearSubmission(transaction)
+	}
+	s.leaseTicket(transaction)
+
+	err := s.submitTransaction(transaction)
+	for bumps := 0; bumps < maxFeeBumps && feeRejected(err); bumps++ {
+		previousFee := transaction.Fee
+		if bumpErr := s.feeCalculator.BumpFee(transaction); bumpErr != nil {
+			log.Printf("Not resubmitting transaction %s with a higher fee: %v", transaction.ID, bumpErr)
+			break
+		}
+		log.Printf("Transaction %s fee of %s drops was too low, resubmitting with %s drops", transaction.ID, previousFee, transaction.Fee)
+		clearSubmission(transaction)
+		err = s.submitTransaction(transaction)
+	}
+
+	// Only a rejection the ledger can never revisit ends the attempt before validation
+	var txErr *xrpl.TransactionError
+	if err != nil && (!errors.As(err, &txErr) || xrpl.IsFinalRejection(txErr.Code) || transaction.TransactionHash == "") {
+		return err
+	}
+	return s.awaitValidation(transaction)
+}
+
+// submitTransaction builds and submits the ledger transaction for a queued transaction
+func (s *TransactionQueueService) submitTransaction(transaction *models.Transaction) error {
+	switch transaction.Type {
+	case models.TransactionTypeEscrowCreate:
+		return s.processEscrowCreate(transaction)
+	case models.TransactionTypeEscrowFinish:
+		return s.processEscrowFinish(transaction)
+	case models.TransactionTypeEscrowCancel:
+		return s.processEscrowCancel(transaction)
+	case models.TransactionTypePayment:
+		return s.processPayment(transaction)
+	default:
+		return fmt.Errorf("unsupported transaction type: %s", transaction.Type)
+	}
+}
+
+// priceTransaction sets the fee of a transaction from the live open ledger cost, falling back to an
+// estimate when live fees are unavailable. A cost above the transaction's fee cap is an error so the
+// transaction waits for the load to fall instead of overpaying.
+func (s *TransactionQueueService) priceTransaction(transaction *models.Transaction) error {
+	err := s.feeCalculator.ApplyNetworkFee(transaction)
+	if err == nil || errors.Is(err, ErrFeeCapExceeded) {
+		return err
+	}
+
+	log.Printf("Live fees unavailable for transaction %s, estimating: %v", transaction.ID, err)
+	fee, err := s.feeCalculator.CalculateTransactionFee(transaction, s.feeCalculator.EstimateNetworkLoad())
+	if err != nil {
+		return err
+	}
+	transaction.Fee = fee
+	return nil
+}
+
+// feeRejected reports whether the ledger refused a submission because its fee was too low
+func feeRejected(err error) bool {
+	var txErr *xrpl.TransactionError
+	return errors.As(err, &txErr) && xrpl.IsFeeRelated(txErr.Code)
+}
+
+// awaitValidation waits for the recorded submission to be validated or to expire and records its final result
+func (s *TransactionQueueService) awaitValidation(transaction *models.Transaction) error {
+	var lastLedgerSequence uint32
+	if transaction.LastLedgerSequence != nil {
+		lastLedgerSequence = *transaction.LastLedgerSequence
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+	defer cancel()
+
+	result, err := s.xrplService.WaitForValidation(ctx, transaction.TransactionHash, lastLedgerSequence)
+	if result != nil {
+		transaction.ResultCode = result.ResultCode
+		transaction.LedgerIndex = &result.LedgerIndex
+		// The validated transaction carries the fee the ledger actually charged
+		if result.Fee != "" {
+			transaction.Fee = result.Fee
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("transaction %s was not validated: %w", transaction.TransactionHash, err)
+	}
+	return nil
+}
+
+// recordSubmission stores the ledger identity of a submitted transaction
+func recordSubmission(transaction *models.Transaction, result *xrpl.TransactionResult) {
+	if result == nil {
+		return
+	}
+
+	transaction.TransactionHash = result.TransactionID
+	transaction.ResultCode = result.ResultCode
+	if result.Sequence > 0 {
+		sequence := result.Sequence
+		transaction.Sequence = &sequence
+	}
+	if result.LastLedgerSequence > 0 {
+		lastLedgerSequence := result.LastLedgerSequence
+		transaction.LastLedgerSequence = &lastLedgerSequence
+	}
+	if result.LedgerIndex > 0 {
+		ledgerIndex := result.LedgerIndex
+		transaction.LedgerIndex = &ledgerIndex
+	}
+}
+
+// clearSubmission forgets a submission that can no longer be applied so a retry builds a new one
+func clearSubmission(transaction *models.Transaction) {
+	transaction.TransactionHash = ""
+	transaction.Sequence = nil
+	transaction.LastLedgerSequence = nil
+	transaction.LedgerIndex = nil
+}
+
+// recordFailure marks a failed attempt retriable or terminal according to the ledger outcome
+func (s *TransactionQueueService) recordFailure(transaction *models.Transaction, err error) {
+	var txErr *xrpl.TransactionError
+	switch {
+	case errors.As(err, &txErr):
+		transaction.ResultCode = txErr.Code
+		if txErr.Class() == xrpl.ResultClassTerminal {
+			transaction.SetTerminalError(err)
+			return
+		}
+		// The ticket is gone; the retry leases another one or uses the account sequence
+		if txErr.Code == "tefNO_TICKET" && transaction.TicketSequence != nil {
+			if s.tickets != nil {
+				s.tickets.Consume(transaction.FromAddress, *transaction.TicketSequence)
+			}
+			transaction.TicketSequence = nil
+		}
+		transaction.SetError(err)
+		clearSubmission(transaction)
+	case errors.Is(err, xrpl.ErrTransactionExpired):
+		// rippled reports the same result when a transaction is submitted past its LastLedgerSequence
+		transaction.ResultCode = "tefMAX_LEDGER"
+		transaction.SetError(err)
+		clearSubmission(transaction)
+	default:
+		// The outcome is unknown; a recorded submission is kept so the retry settles it first
+		transaction.SetError(err)
+	}
+}
+
 // processEscrowCreate handles escrow creation transactions
 func (s *TransactionQueueService) processEscrowCreate(transaction *models.Transaction) error {
 	if !s.xrplService.initialized {
@@ -675,28 +908,25 @@ func (s *TransactionQueueService) processEscrowCreate(transaction *models.Transa
 	}
 
 	// Parse amount
-	amount, err := strconv.ParseFloat(transaction.Amount, 64)
+	amount, err := models.ParseMoney(transaction.Amount, models.Currency(transaction.Currency))
 	if err != nil {
 		return fmt.Errorf("invalid amount: %w", err)
 	}
 
 	// Create escrow
-	result, fulfillment, err := s.xrplService.CreateSmartChequeEscrow(
+	result, fulfillment, err := s.xrplService.CreateSmartChequeEscrowWithOptions(
 		transaction.FromAddress,
 		transaction.ToAddress,
 		amount,
-		transaction.Currency,
 		milestoneSecret,
+		submitOptions(transaction),
 	)
+	recordSubmission(transaction, result)
 	if err != nil {
 		return fmt.Errorf("failed to create escrow: %w", err)
 	}
 
-	// Update transaction with XRPL details
-	transaction.TransactionHash = result.TransactionID
-	transaction.LedgerIndex = &result.LedgerIndex
 	transaction.Fulfillment = fulfillment
-
 	return nil
 }
 
@@ -710,20 +940,19 @@ func (s *TransactionQueueService) processEscrowFinish(transaction *models.Transa
 		return fmt.Errorf("offer sequence is required for escrow finish")
 	}
 
-	result, err := s.xrplService.CompleteSmartChequeMilestone(
+	result, err := s.xrplService.CompleteSmartChequeMilestoneWithOptions(
 		transaction.ToAddress,
 		transaction.FromAddress,
 		*transaction.OfferSequence,
 		transaction.Condition,
 		transaction.Fulfillment,
+		submitOptions(transaction),
 	)
+	recordSubmission(transaction, result)
 	if err != nil {
 		return fmt.Errorf("failed to finish escrow: %w", err)
 	}
 
-	transaction.TransactionHash = result.TransactionID
-	transaction.LedgerIndex = &result.LedgerIndex
-
 	return nil
 }
 
@@ -737,36 +966,141 @@ func (s *TransactionQueueService) processEscrowCancel(transaction *models.Transa
 		return fmt.Errorf("offer sequence is required for escrow cancel")
 	}
 
-	result, err := s.xrplService.CancelSmartCheque(
+	result, err := s.xrplService.CancelSmartChequeWithOptions(
 		transaction.FromAddress,
 		transaction.FromAddress,
 		*transaction.OfferSequence,
+		submitOptions(transaction),
 	)
+	recordSubmission(transaction, result)
 	if err != nil {
 		return fmt.Errorf("failed to cancel escrow: %w", err)
 	}
 
-	transaction.TransactionHash = result.TransactionID
-	transaction.LedgerIndex = &result.LedgerIndex
-
 	return nil
 }
 
 // processPayment handles regular payment transactions
-// Returns error which is always nil in this implementation
 func (s *TransactionQueueService) processPayment(transaction *models.Transaction) error {
-	// For now, simulate payment processing
-	// In production, this would use XRPL payment transactions
+	if !s.xrplService.initialized {
+		return fmt.Errorf("XRPL service not initialized")
+	}
+
+	amount, err := models.ParseMoney(transaction.Amount, models.Currency(transaction.Currency))
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
 	log.Printf("Processing payment: %s %s from %s to %s",
 		transaction.Amount, transaction.Currency,
 		transaction.FromAddress, transaction.ToAddress)
 
-	// Simulate transaction hash
-	transaction.TransactionHash = fmt.Sprintf("payment_%s_%d", transaction.ID, time.Now().Unix())
+	result, err := s.xrplService.SendPaymentWithOptions(transaction.FromAddress, transaction.ToAddress, amount, submitOptions(transaction))
+	recordSubmission(transaction, result)
+	if err != nil {
+		return fmt.Errorf("failed to send payment: %w", err)
+	}
 
 	return nil
 }
 
+// ticketEligible reports whether a transaction type is independent of earlier transactions from
+// the same account, so it can be submitted out of order with a ticket
+func ticketEligible(transaction *models.Transaction) bool {
+	return transaction.Type == models.TransactionTypeEscrowCreate || transaction.Type == models.TransactionTypePayment
+}
+
+// submitOptions returns the ticket and fee a transaction is submitted with. Without a ticket the
+// account sequence is used.
+func submitOptions(transaction *models.Transaction) SubmitOptions {
+	options := SubmitOptions{Fee: transaction.Fee}
+	if transaction.TicketSequence != nil {
+		options.Ticket = *transaction.TicketSequence
+	}
+	return options
+}
+
+// leaseTicket gives an eligible transaction from a hot account a ticket when one is available.
+// A transaction keeps its ticket across retries so a resubmission never needs a new one.
+func (s *TransactionQueueService) leaseTicket(transaction *models.Transaction) bool {
+	if transaction.TicketSequence != nil {
+		return true
+	}
+	if s.tickets == nil || !ticketEligible(transaction) || !s.tickets.Enabled(transaction.FromAddress) {
+		return false
+	}
+
+	ticket, ok := s.tickets.Acquire(transaction.FromAddress)
+	if !ok {
+		return false
+	}
+	transaction.TicketSequence = &ticket
+	return true
+}
+
+// releaseTicket reclaims the ticket of a transaction that will not be retried. A validated tec
+// result consumed the ticket, a rejected or never submitted transaction left it unused, and a
+// submission whose outcome is unknown keeps it since it may still be validated.
+func (s *TransactionQueueService) releaseTicket(transaction *models.Transaction) {
+	if transaction.TicketSequence == nil || s.tickets == nil {
+		return
+	}
+
+	ticket := *transaction.TicketSequence
+	switch {
+	case strings.HasPrefix(transaction.ResultCode, "tec"):
+		s.tickets.Consume(transaction.FromAddress, ticket)
+	case transaction.TransactionHash == "" || xrpl.IsFinalRejection(transaction.ResultCode):
+		s.tickets.Release(transaction.FromAddress, ticket)
+		transaction.TicketSequence = nil
+	}
+}
+
+// leaseBatchTickets tops up the tickets of the hot accounts in a batch and leases one to each of
+// their eligible transactions. It returns the transactions holding tickets and the rest.
+func (s *TransactionQueueService) leaseBatchTickets(transactions []*models.Transaction) (ticketed, sequential []*models.Transaction) {
+	if s.tickets == nil {
+		return nil, transactions
+	}
+
+	needed := make(map[string]int)
+	for _, tx := range transactions {
+		if tx.TicketSequence == nil && ticketEligible(tx) && s.tickets.Enabled(tx.FromAddress) {
+			needed[tx.FromAddress]++
+		}
+	}
+	for account, count := range needed {
+		ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+		if err := s.tickets.Ensure(ctx, account, count); err != nil {
+			log.Printf("Failed to top up tickets for %s, falling back to sequences: %v", account, err)
+		}
+		cancel()
+	}
+
+	for _, tx := range transactions {
+		if s.leaseTicket(tx) {
+			ticketed = append(ticketed, tx)
+		} else {
+			sequential = append(sequential, tx)
+		}
+	}
+	return ticketed, sequential
+}
+
+// replenishTickets tops up hot accounts whose unused tickets ran low
+func (s *TransactionQueueService) replenishTickets() {
+	if s.tickets == nil {
+		return
+	}
+	for _, account := range s.tickets.Accounts() {
+		ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+		if err := s.tickets.Ensure(ctx, account, 0); err != nil {
+			log.Printf("Failed to replenish tickets for %s: %v", account, err)
+		}
+		cancel()
+	}
+}
+
 // processWalletSetup handles wallet setup transactions
 func (s *TransactionQueueService) processWalletSetup(transaction *models.Transaction) error {
 	if !s.xrplService.initialized {
@@ -792,10 +1126,9 @@ func (s *TransactionQueueService) processWalletSetup(transaction *models.Transac
 
 // optimizeBatchFees optimizes fees for a batch of transactions
 func (s *TransactionQueueService) optimizeBatchFees(batch *models.TransactionBatch, transactions []*models.Transaction) {
-	feeCalculator := NewFeeCalculator()
-	networkLoad := feeCalculator.EstimateNetworkLoad()
+	networkLoad := s.feeCalculator.EstimateNetworkLoad()
 
-	if err := feeCalculator.OptimizeBatchFees(batch, transactions, networkLoad); err != nil {
+	if err := s.feeCalculator.OptimizeBatchFees(batch, transactions, networkLoad); err != nil {
 		log.Printf("Failed to optimize batch fees: %v", err)
 	}
 }
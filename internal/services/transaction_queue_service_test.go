@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository/mocks"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+func newTestQueueService(t *testing.T) (*TransactionQueueService, *mocks.TransactionRepositoryInterface) {
+	t.Helper()
+	xrplService := NewXRPLService(XRPLConfig{NetworkURL: "https://s.altnet.rippletest.net:51234", TestNet: true})
+	require.NoError(t, xrplService.Initialize())
+
+	repo := new(mocks.TransactionRepositoryInterface)
+	repo.On("UpdateTransaction", mock.Anything).Return(nil)
+	return NewTransactionQueueService(repo, xrplService, nil, nil, models.DefaultBatchConfig()), repo
+}
+
+func TestTransactionQueue_ProcessTransactionAwaitsValidation(t *testing.T) {
+	service, _ := newTestQueueService(t)
+	payer, payee := newTestKeyPair(t).Address(), newTestKeyPair(t).Address()
+
+	payment := models.NewTransaction(models.TransactionTypePayment, payer, payee, "12.5", "XRP", "enterprise-1", "user-1")
+	require.True(t, service.processTransaction(payment))
+	assert.Equal(t, models.TransactionStatusConfirmed, payment.Status)
+	assert.Equal(t, "tesSUCCESS", payment.ResultCode)
+	assert.NotEmpty(t, payment.TransactionHash)
+	require.NotNil(t, payment.LedgerIndex)
+
+	// A submission whose outcome was never learned is settled instead of being sent again
+	pending := models.NewTransaction(models.TransactionTypeEscrowCreate, payer, payee, "10", "XRP", "enterprise-1", "user-1")
+	lastLedgerSequence := uint32(12360)
+	pending.TransactionHash = "PREVIOUS"
+	pending.LastLedgerSequence = &lastLedgerSequence
+	require.True(t, service.processTransaction(pending))
+	assert.Equal(t, "PREVIOUS", pending.TransactionHash)
+	assert.Empty(t, pending.Fulfillment, "no new escrow was created")
+}
+
+func TestTransactionQueue_RecordFailure(t *testing.T) {
+	service, _ := newTestQueueService(t)
+
+	cases := []struct {
+		name          string
+		err           error
+		resultCode    string
+		retriable     bool
+		keepsHash     bool
+		retryFeeRises bool
+	}{
+		{"validated with tec", &xrpl.TransactionError{Code: "tecUNFUNDED_PAYMENT"}, "tecUNFUNDED_PAYMENT", false, true, false},
+		{"malformed", &xrpl.TransactionError{Code: "temBAD_AMOUNT"}, "temBAD_AMOUNT", false, true, false},
+		{"past sequence", &xrpl.TransactionError{Code: "tefPAST_SEQ"}, "tefPAST_SEQ", true, false, false},
+		{"fee too low", &xrpl.TransactionError{Code: "telINSUF_FEE_P"}, "telINSUF_FEE_P", true, false, true},
+		{"expired", fmt.Errorf("not validated: %w", xrpl.ErrTransactionExpired), "tefMAX_LEDGER", true, false, true},
+		{"unknown outcome", errors.New("connection reset"), "tesSUCCESS", true, true, false},
+	}
+
+	calculator := NewFeeCalculator()
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lastLedgerSequence := uint32(100)
+			tx := &models.Transaction{
+				ID:                 "tx",
+				Type:               models.TransactionTypePayment,
+				Fee:                "10",
+				MaxRetries:         3,
+				TransactionHash:    "ABC123",
+				ResultCode:         "tesSUCCESS",
+				LastLedgerSequence: &lastLedgerSequence,
+			}
+
+			service.recordFailure(tx, tt.err)
+			assert.Equal(t, models.TransactionStatusFailed, tx.Status)
+			assert.Equal(t, tt.resultCode, tx.ResultCode)
+			assert.Equal(t, tt.retriable, tx.CanRetry())
+			assert.Equal(t, tt.keepsHash, tx.TransactionHash != "")
+
+			fee, err := calculator.CalculateFeeForRetry(tx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.retryFeeRises, fee != "10")
+		})
+	}
+}
+
+func TestTransactionQueue_RetryFailedTransactions(t *testing.T) {
+	service, repo := newTestQueueService(t)
+
+	underpaid := &models.Transaction{ID: "underpaid", Status: models.TransactionStatusFailed, Fee: "10", MaxRetries: 3, ResultCode: "telINSUF_FEE_P"}
+	reordered := &models.Transaction{ID: "reordered", Status: models.TransactionStatusFailed, Fee: "10", MaxRetries: 3, ResultCode: "tefPAST_SEQ"}
+	repo.On("GetRetriableTransactions").Return([]*models.Transaction{underpaid, reordered}, nil)
+
+	require.NoError(t, service.RetryFailedTransactions())
+	assert.Equal(t, "12", underpaid.Fee)
+	assert.Equal(t, "10", reordered.Fee)
+	assert.Equal(t, models.TransactionStatusQueued, reordered.Status)
+	assert.Equal(t, 1, reordered.RetryCount)
+	assert.Len(t, service.processingQueue, 2)
+}
+
+func TestTransactionQueue_EscrowLifecycleOnSimulatedLedger(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+	stop := ledger.AutoClose(20 * time.Millisecond)
+	defer stop()
+
+	repo := new(mocks.TransactionRepositoryInterface)
+	repo.On("UpdateTransaction", mock.Anything).Return(nil)
+	service := NewTransactionQueueService(repo, xrplService, nil, nil, models.DefaultBatchConfig())
+
+	create := models.NewTransaction(models.TransactionTypeEscrowCreate, payer.Address(), payee.Address(), "30", "XRP", "enterprise-1", "user-1")
+	create.Metadata = map[string]interface{}{"milestone_secret": "delivery-accepted"}
+	require.True(t, service.processTransaction(create))
+	assert.Equal(t, "tesSUCCESS", create.ResultCode)
+	require.NotNil(t, create.Sequence)
+	require.NotNil(t, create.LedgerIndex)
+
+	condition, err := xrpl.ConditionFromFulfillment(create.Fulfillment)
+	require.NoError(t, err)
+	// Escrow time conditions are checked against the last closed ledger
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+
+	finish := models.NewTransaction(models.TransactionTypeEscrowFinish, payer.Address(), payee.Address(), "30", "XRP", "enterprise-1", "user-1")
+	finish.OfferSequence = create.Sequence
+	finish.Condition = condition
+	finish.Fulfillment = create.Fulfillment
+	require.True(t, service.processTransaction(finish), finish.LastError)
+	assert.Equal(t, models.TransactionStatusConfirmed, finish.Status)
+	assert.Equal(t, "tesSUCCESS", finish.ResultCode)
+
+	_, err = xrplService.GetEscrowStatus(payer.Address(), strconv.FormatUint(uint64(*create.Sequence), 10))
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+}
+
+func TestTransactionQueue_BatchSubmitsWithTickets(t *testing.T) {
+	treasury := newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{treasury.Address(): treasury})
+	require.NoError(t, ledger.Fund(treasury.Address(), 1000000000))
+	stop := ledger.AutoClose(20 * time.Millisecond)
+	defer stop()
+
+	pool := NewTicketPool(xrplService, TicketPoolConfig{Accounts: []string{treasury.Address()}, Target: 6})
+	repo := new(mocks.TransactionRepositoryInterface)
+	repo.On("UpdateTransaction", mock.Anything).Return(nil)
+	repo.On("UpdateTransactionBatch", mock.Anything).Return(nil)
+	service := NewTransactionQueueServiceWithTickets(repo, xrplService, nil, nil, models.DefaultBatchConfig(), pool)
+
+	batch := models.NewTransactionBatch(models.PriorityNormal, 10)
+	payouts := make([]*models.Transaction, 4)
+	for i := range payouts {
+		payouts[i] = models.NewTransaction(models.TransactionTypePayment, treasury.Address(), newTestKeyPair(t).Address(), "25", "XRP", "enterprise-1", "user-1")
+	}
+	repo.On("GetTransactionsByBatchID", batch.ID).Return(payouts, nil)
+
+	service.processBatch(batch)
+	assert.Equal(t, 4, batch.SuccessCount)
+	tickets := make(map[uint32]bool)
+	for _, payout := range payouts {
+		assert.Equal(t, models.TransactionStatusConfirmed, payout.Status)
+		require.NotNil(t, payout.TicketSequence)
+		assert.Equal(t, *payout.TicketSequence, *payout.Sequence)
+		tickets[*payout.TicketSequence] = true
+	}
+	assert.Len(t, tickets, 4, "every payout used its own ticket")
+	assert.Equal(t, 2, pool.Available(treasury.Address()))
+
+	// A rejected transaction returns its unused ticket to the pool
+	rejected := models.NewTransaction(models.TransactionTypePayment, treasury.Address(), newTestKeyPair(t).Address(), "25", "XRP", "enterprise-1", "user-1")
+	ledger.FailNextSubmit("temMALFORMED")
+	require.False(t, service.processTransaction(rejected))
+	assert.False(t, rejected.CanRetry())
+	assert.Nil(t, rejected.TicketSequence)
+	assert.Equal(t, 2, pool.Available(treasury.Address()))
+
+	// A validated failure consumes it
+	unfunded := models.NewTransaction(models.TransactionTypePayment, treasury.Address(), newTestKeyPair(t).Address(), "1", "XRP", "enterprise-1", "user-1")
+	require.False(t, service.processTransaction(unfunded))
+	assert.Equal(t, "tecNO_DST_INSUF_XRP", unfunded.ResultCode)
+	require.NotNil(t, unfunded.TicketSequence)
+	assert.Equal(t, 1, pool.Available(treasury.Address()))
+	remaining, err := xrplService.GetTickets(treasury.Address())
+	require.NoError(t, err)
+	assert.NotContains(t, remaining, *unfunded.TicketSequence)
+}
+
+func TestTransactionQueue_FeesFollowOpenLedgerCost(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	xrplService, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+	stop := ledger.AutoClose(20 * time.Millisecond)
+	defer stop()
+
+	repo := new(mocks.TransactionRepositoryInterface)
+	repo.On("UpdateTransaction", mock.Anything).Return(nil)
+	service := NewTransactionQueueService(repo, xrplService, nil, nil, models.DefaultBatchConfig())
+	service.FeeCalculator().SetEnterpriseFeeCaps("enterprise-1", FeeCaps{models.PriorityLow: 20})
+	ledger.SetLoadFactor(3)
+
+	payment := models.NewTransaction(models.TransactionTypePayment, payer.Address(), payee.Address(), "1", "XRP", "enterprise-1", "user-1")
+	require.True(t, service.processTransaction(payment), payment.LastError)
+	assert.Equal(t, "30", payment.NetworkFee)
+	assert.Equal(t, "30", payment.Fee)
+
+	// A fee the open ledger rejects as too low is bumped to the current cost and resubmitted
+	stale := models.NewTransaction(models.TransactionTypePayment, payer.Address(), payee.Address(), "2", "XRP", "enterprise-1", "user-1")
+	stale.Fee = "10"
+	require.True(t, service.processTransaction(stale), stale.LastError)
+	assert.Equal(t, "tesSUCCESS", stale.ResultCode)
+	assert.Equal(t, "30", stale.Fee, "the fee recorded is the one the ledger charged")
+
+	// Low priority payments of the enterprise wait rather than pay more than their cap
+	capped := models.NewTransaction(models.TransactionTypePayment, payer.Address(), payee.Address(), "3", "XRP", "enterprise-1", "user-1")
+	capped.Priority = models.PriorityLow
+	assert.False(t, service.processTransaction(capped))
+	assert.Contains(t, capped.LastError, ErrFeeCapExceeded.Error())
+	assert.True(t, capped.CanRetry())
+	assert.Empty(t, capped.TransactionHash)
+
+	ledger.SetLoadFactor(1)
+	require.True(t, service.processTransaction(capped), capped.LastError)
+	assert.Equal(t, "10", capped.Fee)
+
+	analysis := service.FeeCalculator().AnalyzeFeeEfficiency([]*models.Transaction{payment, stale, capped})
+	assert.Equal(t, 3, analysis["paid_count"])
+	assert.Equal(t, int64(70), analysis["paid_fee_drops"])
+	assert.Equal(t, int64(0), analysis["excess_fee_drops"])
+	assert.Equal(t, 3, analysis["optimal_count"])
+}
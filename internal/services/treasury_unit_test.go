@@ -7,6 +7,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 // TestMintingBurningServiceUnitTests tests the core business logic
@@ -130,6 +135,42 @@ func TestReconciliationDiscrepancySeverity(t *testing.T) {
 	assert.Contains(t, causes, "System synchronization delay")
 }
 
+func TestReconciliationDetectsDepositOverCredits(t *testing.T) {
+	issuer := newTestKeyPair(t).Address()
+	usdt, err := xrpl.NewIssuedCurrency("USDT", issuer)
+	require.NoError(t, err)
+	asset := createTestAsset("USDT", models.AssetTypeStablecoin, true)
+	asset.IssuerAddress = &issuer
+	declared, err := usdt.Amount("100")
+	require.NoError(t, err)
+	delivered, err := usdt.Amount("0.5")
+	require.NoError(t, err)
+
+	exploited := &models.AssetTransaction{ID: uuid.New(), EnterpriseID: uuid.New(), CurrencyCode: "USDT", Amount: "100000000", Metadata: map[string]interface{}{"external_tx_hash": "PARTIAL"}}
+	honest := &models.AssetTransaction{ID: uuid.New(), EnterpriseID: uuid.New(), CurrencyCode: "USDT", Amount: "500000", Metadata: map[string]interface{}{"external_tx_hash": "FULL"}}
+	manual := &models.AssetTransaction{ID: uuid.New(), CurrencyCode: "USDT", Amount: "1"}
+
+	balanceRepo := &TestMockBalanceRepository{}
+	balanceRepo.On("GetAssetTransactionsByType", mock.Anything, models.AssetTransactionTypeDeposit, 2, 0).Return([]*models.AssetTransaction{exploited, honest}, nil)
+	balanceRepo.On("GetAssetTransactionsByType", mock.Anything, models.AssetTransactionTypeDeposit, 2, 2).Return([]*models.AssetTransaction{manual}, nil)
+	assetRepo := &TestMockAssetRepository{}
+	assetRepo.On("GetAssetByCurrency", mock.Anything, "USDT").Return(asset, nil)
+	xrplService := &MockXRPLService{}
+	xrplService.On("GetPaymentDelivery", "PARTIAL").Return(&xrpl.PaymentDelivery{Amount: declared, Delivered: delivered, PartialPayment: true}, nil)
+	xrplService.On("GetPaymentDelivery", "FULL").Return(&xrpl.PaymentDelivery{Amount: delivered, Delivered: delivered}, nil)
+
+	service := NewReconciliationService(balanceRepo, assetRepo, xrplService, nil, &ReconciliationConfig{CriticalThreshold: "1000", BatchSize: 2})
+	findings, err := service.DetectDepositOverCredits(context.Background())
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, exploited.ID, findings[0].AssetTransactionID)
+	assert.Equal(t, "500000", findings[0].DeliveredAmount)
+	assert.Equal(t, "99500000", findings[0].OverCreditAmount)
+	assert.True(t, findings[0].PartialPayment)
+	assert.Equal(t, DiscrepancySeverityCritical, findings[0].Severity)
+	xrplService.AssertExpectations(t)
+}
+
 func TestTreasuryServiceBalanceCalculations(t *testing.T) {
 	// Test reconciliation configuration setup
 	config := &ReconciliationConfig{
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// defaultTrustLineLimit is used for assets that do not configure a trust line limit
+const defaultTrustLineLimit = "1000000000"
+
+var (
+	ErrAssetNotIssued   = errors.New("asset is not an issued currency")
+	ErrWalletNotEnabled = errors.New("wallet cannot transact")
+)
+
+// TrustLineClient is the subset of XRPL operations trust line management needs; *XRPLService satisfies it
+type TrustLineClient interface {
+	SetTrustLine(account string, limit xrpl.Amount, flags uint32) (*xrpl.TransactionResult, error)
+	GetTrustLines(account, issuer string) ([]xrpl.TrustLine, error)
+}
+
+var _ TrustLineClient = (*XRPLService)(nil)
+
+// TrustLineService manages the trust lines enterprise wallets hold to the issuers of supported assets
+type TrustLineService struct {
+	client     TrustLineClient
+	walletRepo repository.WalletRepositoryInterface
+	assetRepo  repository.AssetRepository
+}
+
+// NewTrustLineService creates a new trust line service
+func NewTrustLineService(client TrustLineClient, walletRepo repository.WalletRepositoryInterface, assetRepo repository.AssetRepository) *TrustLineService {
+	return &TrustLineService{
+		client:     client,
+		walletRepo: walletRepo,
+		assetRepo:  assetRepo,
+	}
+}
+
+// EnsureTrustLine opens a trust line from the wallet to the asset's issuer at the asset's configured
+// limit, leaving an existing line alone when its limit already covers it
+func (s *TrustLineService) EnsureTrustLine(ctx context.Context, walletID uuid.UUID, currencyCode string) (*xrpl.TransactionResult, error) {
+	wallet, asset, issued, err := s.resolve(ctx, walletID, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := defaultTrustLineLimit
+	if asset.TrustLineLimit != nil && *asset.TrustLineLimit != "" {
+		limit = *asset.TrustLineLimit
+	}
+
+	line, err := s.findLine(wallet.Address, issued)
+	if err != nil {
+		return nil, err
+	}
+	if line != nil && compareDecimal(line.Limit, limit) >= 0 {
+		return nil, nil
+	}
+
+	// Holders set NoRipple so the platform's balances never ripple between issuers
+	return s.submit(wallet, issued, limit, xrpl.TrustSetFlagSetNoRipple)
+}
+
+// SetTrustLineLimit changes the limit of the wallet's trust line for an asset, creating it if needed
+func (s *TrustLineService) SetTrustLineLimit(ctx context.Context, walletID uuid.UUID, currencyCode, limit string) (*xrpl.TransactionResult, error) {
+	wallet, _, issued, err := s.resolve(ctx, walletID, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.submit(wallet, issued, limit, 0)
+}
+
+// FreezeTrustLine sets or clears the freeze flag on the wallet's trust line for an asset
+func (s *TrustLineService) FreezeTrustLine(ctx context.Context, walletID uuid.UUID, currencyCode string, freeze bool) (*xrpl.TransactionResult, error) {
+	wallet, _, issued, err := s.resolve(ctx, walletID, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := s.findLine(wallet.Address, issued)
+	if err != nil {
+		return nil, err
+	}
+	if line == nil {
+		return nil, fmt.Errorf("wallet %s has no %s trust line", wallet.Address, currencyCode)
+	}
+
+	flags := xrpl.TrustSetFlagClearFreeze
+	if freeze {
+		flags = xrpl.TrustSetFlagSetFreeze
+	}
+	// TrustSet always restates the limit, so keep the current one
+	return s.submit(wallet, issued, line.Limit, flags)
+}
+
+// GetTrustLines lists every trust line held by a wallet
+func (s *TrustLineService) GetTrustLines(ctx context.Context, walletID uuid.UUID) ([]xrpl.TrustLine, error) {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return s.client.GetTrustLines(wallet.Address, "")
+}
+
+func (s *TrustLineService) resolve(ctx context.Context, walletID uuid.UUID, currencyCode string) (*models.Wallet, *models.SupportedAsset, xrpl.IssuedCurrency, error) {
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, nil, xrpl.IssuedCurrency{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if !wallet.CanTransact() {
+		return nil, nil, xrpl.IssuedCurrency{}, fmt.Errorf("%w: %s", ErrWalletNotEnabled, wallet.Address)
+	}
+
+	asset, err := s.assetRepo.GetAssetByCurrency(ctx, currencyCode)
+	if err != nil {
+		return nil, nil, xrpl.IssuedCurrency{}, fmt.Errorf("failed to get asset %s: %w", currencyCode, err)
+	}
+	issued, err := issuedCurrencyForAsset(asset)
+	if err != nil {
+		return nil, nil, xrpl.IssuedCurrency{}, err
+	}
+	return wallet, asset, issued, nil
+}
+
+// findLine returns the wallet's trust line for an issued currency, or nil when it has none
+func (s *TrustLineService) findLine(address string, issued xrpl.IssuedCurrency) (*xrpl.TrustLine, error) {
+	lines, err := s.client.GetTrustLines(address, issued.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		if lines[i].Currency == issued.Currency && lines[i].Peer == issued.Issuer {
+			return &lines[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *TrustLineService) submit(wallet *models.Wallet, issued xrpl.IssuedCurrency, limit string, flags uint32) (*xrpl.TransactionResult, error) {
+	amount, err := issued.Amount(limit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust line limit: %w", err)
+	}
+
+	result, err := s.client.SetTrustLine(wallet.Address, amount, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Trust line set for wallet %s: %s, Flags: %#x, TxID: %s", wallet.Address, amount, flags, result.TransactionID)
+	return result, nil
+}
+
+// issuedCurrencyForAsset returns the on-ledger currency of an issued asset, preferring its configured hex code
+func issuedCurrencyForAsset(asset *models.SupportedAsset) (xrpl.IssuedCurrency, error) {
+	if !asset.RequiresTrustLine() {
+		return xrpl.IssuedCurrency{}, fmt.Errorf("%w: %s", ErrAssetNotIssued, asset.CurrencyCode)
+	}
+
+	currency := asset.CurrencyCode
+	if asset.CurrencyHex != nil && *asset.CurrencyHex != "" {
+		currency = *asset.CurrencyHex
+	}
+	issued, err := xrpl.NewIssuedCurrency(currency, *asset.IssuerAddress)
+	if err != nil {
+		return xrpl.IssuedCurrency{}, fmt.Errorf("invalid XRPL configuration for %s: %w", asset.CurrencyCode, err)
+	}
+	return issued, nil
+}
+
+// compareDecimal compares two decimal strings, treating unparsable values as zero
+func compareDecimal(a, b string) int {
+	x, _, errA := big.ParseFloat(a, 10, 128, big.ToNearestEven)
+	y, _, errB := big.ParseFloat(b, 10, 128, big.ToNearestEven)
+	if errA != nil {
+		x = new(big.Float)
+	}
+	if errB != nil {
+		y = new(big.Float)
+	}
+	return x.Cmp(y)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+const (
+	testTrustHolder = "rN7n7otQDd6FczFgLdSqtcsAUxDkw6fzRH"
+	testTrustIssuer = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+)
+
+// recordingTrustLineClient serves a fixed set of trust lines and records TrustSet submissions
+type recordingTrustLineClient struct {
+	lines     []xrpl.TrustLine
+	submitted []xrpl.TrustSet
+}
+
+func (c *recordingTrustLineClient) SetTrustLine(account string, limit xrpl.Amount, flags uint32) (*xrpl.TransactionResult, error) {
+	c.submitted = append(c.submitted, xrpl.TrustSet{Account: account, LimitAmount: limit, Flags: flags})
+	return &xrpl.TransactionResult{TransactionID: "TRUSTSET", ResultCode: "tesSUCCESS", Validated: true}, nil
+}
+
+func (c *recordingTrustLineClient) GetTrustLines(account, issuer string) ([]xrpl.TrustLine, error) {
+	return c.lines, nil
+}
+
+func newTestTrustLineService(t *testing.T, client *recordingTrustLineClient, wallet *models.Wallet) *TrustLineService {
+	t.Helper()
+
+	walletRepo := &MockWalletRepositoryInterface{}
+	walletRepo.On("GetByID", wallet.ID).Return(wallet, nil)
+
+	issuer, limit := testTrustIssuer, "5000"
+	assetRepo := &MockAssetRepository{}
+	assetRepo.On("GetAssetByCurrency", mock.Anything, "USDT").Return(&models.SupportedAsset{
+		CurrencyCode:   "USDT",
+		AssetType:      models.AssetTypeStablecoin,
+		IssuerAddress:  &issuer,
+		TrustLineLimit: &limit,
+	}, nil)
+	assetRepo.On("GetAssetByCurrency", mock.Anything, "XRP").Return(&models.SupportedAsset{
+		CurrencyCode: "XRP",
+		AssetType:    models.AssetTypeNative,
+	}, nil)
+
+	return NewTrustLineService(client, walletRepo, assetRepo)
+}
+
+func TestTrustLineService_EnsureTrustLine(t *testing.T) {
+	wallet := &models.Wallet{ID: uuid.New(), Address: testTrustHolder, Status: models.WalletStatusActive, IsWhitelisted: true}
+	client := &recordingTrustLineClient{}
+	service := newTestTrustLineService(t, client, wallet)
+	ctx := context.Background()
+
+	result, err := service.EnsureTrustLine(ctx, wallet.ID, "USDT")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Len(t, client.submitted, 1)
+	assert.Equal(t, xrpl.Amount{Currency: "5553445400000000000000000000000000000000", Issuer: testTrustIssuer, Value: "5000"}, client.submitted[0].LimitAmount)
+	assert.Equal(t, xrpl.TrustSetFlagSetNoRipple, client.submitted[0].Flags)
+
+	// A line already at the configured limit is left alone
+	client.lines = []xrpl.TrustLine{{Peer: testTrustIssuer, Currency: "5553445400000000000000000000000000000000", Limit: "5000.0"}}
+	result, err = service.EnsureTrustLine(ctx, wallet.ID, "USDT")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Len(t, client.submitted, 1)
+
+	_, err = service.EnsureTrustLine(ctx, wallet.ID, "XRP")
+	assert.ErrorIs(t, err, ErrAssetNotIssued)
+}
+
+func TestTrustLineService_LimitsAndFreeze(t *testing.T) {
+	wallet := &models.Wallet{ID: uuid.New(), Address: testTrustHolder, Status: models.WalletStatusActive, IsWhitelisted: true}
+	client := &recordingTrustLineClient{}
+	service := newTestTrustLineService(t, client, wallet)
+	ctx := context.Background()
+
+	_, err := service.FreezeTrustLine(ctx, wallet.ID, "USDT", true)
+	assert.Error(t, err, "freezing requires an existing line")
+
+	_, err = service.SetTrustLineLimit(ctx, wallet.ID, "USDT", "250000.5")
+	require.NoError(t, err)
+	assert.Equal(t, "250000.5", client.submitted[0].LimitAmount.Value)
+
+	_, err = service.SetTrustLineLimit(ctx, wallet.ID, "USDT", "lots")
+	assert.Error(t, err)
+
+	client.lines = []xrpl.TrustLine{{Peer: testTrustIssuer, Currency: "5553445400000000000000000000000000000000", Limit: "250000.5"}}
+	_, err = service.FreezeTrustLine(ctx, wallet.ID, "USDT", true)
+	require.NoError(t, err)
+	_, err = service.FreezeTrustLine(ctx, wallet.ID, "USDT", false)
+	require.NoError(t, err)
+
+	require.Len(t, client.submitted, 3)
+	assert.Equal(t, xrpl.TrustSetFlagSetFreeze, client.submitted[1].Flags)
+	assert.Equal(t, xrpl.TrustSetFlagClearFreeze, client.submitted[2].Flags)
+	assert.Equal(t, "250000.5", client.submitted[2].LimitAmount.Value)
+}
+
+func TestTrustLineService_RejectsInactiveWallet(t *testing.T) {
+	wallet := &models.Wallet{ID: uuid.New(), Address: testTrustHolder, Status: models.WalletStatusSuspended}
+	client := &recordingTrustLineClient{}
+	service := newTestTrustLineService(t, client, wallet)
+
+	_, err := service.EnsureTrustLine(context.Background(), wallet.ID, "USDT")
+	assert.ErrorIs(t, err, ErrWalletNotEnabled)
+	assert.Empty(t, client.submitted)
+}
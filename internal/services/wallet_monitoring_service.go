@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -9,12 +11,43 @@ import (
 
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
+	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 // WalletMonitoringService handles wallet monitoring and health checks
 type WalletMonitoringService struct {
 	walletRepo  repository.WalletRepositoryInterface
 	xrplService repository.XRPLServiceInterface
+	eventBus    messaging.EventBus
+	config      WalletMonitoringConfig
+}
+
+// WalletMonitoringConfig sets when a wallet's spendable XRP is reported as nearing its reserve
+type WalletMonitoringConfig struct {
+	// ReserveHeadroomIncrements warns when the XRP above the reserve funds fewer than this many
+	// more owned objects, such as escrows; below one increment the alert is critical
+	ReserveHeadroomIncrements int64
+}
+
+// DefaultWalletMonitoringConfig returns the default wallet monitoring configuration
+func DefaultWalletMonitoringConfig() WalletMonitoringConfig {
+	return WalletMonitoringConfig{
+		ReserveHeadroomIncrements: 5,
+	}
+}
+
+// WalletReserveAlert reports a wallet whose spendable XRP is close to its reserve
+type WalletReserveAlert struct {
+	WalletID       uuid.UUID     `json:"wallet_id"`
+	EnterpriseID   uuid.UUID     `json:"enterprise_id"`
+	Address        string        `json:"address"`
+	Severity       AlertSeverity `json:"severity"`
+	BalanceDrops   int64         `json:"balance_drops"`
+	ReserveDrops   int64         `json:"reserve_drops"`
+	SpendableDrops int64         `json:"spendable_drops"`
+	OwnerCount     uint32        `json:"owner_count"`
+	Timestamp      time.Time     `json:"timestamp"`
 }
 
 // WalletHealthStatus represents the health status of wallets
@@ -41,9 +74,17 @@ type WalletActivityInfo struct {
 
 // NewWalletMonitoringService creates a new wallet monitoring service
 func NewWalletMonitoringService(walletRepo repository.WalletRepositoryInterface, xrplService repository.XRPLServiceInterface) *WalletMonitoringService {
+	return NewWalletMonitoringServiceWithAlerts(walletRepo, xrplService, nil, DefaultWalletMonitoringConfig())
+}
+
+// NewWalletMonitoringServiceWithAlerts creates a wallet monitoring service that publishes reserve
+// alerts to eventBus; a nil eventBus only logs them
+func NewWalletMonitoringServiceWithAlerts(walletRepo repository.WalletRepositoryInterface, xrplService repository.XRPLServiceInterface, eventBus messaging.EventBus, config WalletMonitoringConfig) *WalletMonitoringService {
 	return &WalletMonitoringService{
 		walletRepo:  walletRepo,
 		xrplService: xrplService,
+		eventBus:    eventBus,
+		config:      config,
 	}
 }
 
@@ -181,6 +222,88 @@ func (s *WalletMonitoringService) MonitorInactiveWallets(inactiveDays int) ([]Wa
 	return inactiveWallets, nil
 }
 
+// CheckReserveHeadroom reports the active wallets whose XRP above the reserve could fund few
+// more owned objects, publishing a wallet.reserve.low event for each
+func (s *WalletMonitoringService) CheckReserveHeadroom() ([]WalletReserveAlert, error) {
+	allWallets, err := s.walletRepo.GetAllWallets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all wallets: %w", err)
+	}
+
+	var alerts []WalletReserveAlert
+	for _, wallet := range allWallets {
+		if wallet.Status != models.WalletStatusActive {
+			continue
+		}
+
+		reserve, err := s.xrplService.GetAccountReserve(wallet.Address)
+		if err != nil {
+			if !errors.Is(err, xrpl.ErrAccountNotFound) {
+				log.Printf("Warning: Could not check reserve of wallet %s: %v", wallet.Address, err)
+			}
+			continue
+		}
+
+		spendable := reserve.Spendable()
+		var severity AlertSeverity
+		switch {
+		case spendable < reserve.Settings.IncrementDrops:
+			severity = AlertSeverityCritical
+		case spendable < s.config.ReserveHeadroomIncrements*reserve.Settings.IncrementDrops:
+			severity = AlertSeverityWarning
+		default:
+			continue
+		}
+
+		alert := WalletReserveAlert{
+			WalletID:       wallet.ID,
+			EnterpriseID:   wallet.EnterpriseID,
+			Address:        wallet.Address,
+			Severity:       severity,
+			BalanceDrops:   reserve.BalanceDrops,
+			ReserveDrops:   reserve.ReserveDrops,
+			SpendableDrops: spendable,
+			OwnerCount:     reserve.OwnerCount,
+			Timestamp:      time.Now(),
+		}
+		alerts = append(alerts, alert)
+		s.publishReserveAlert(alert)
+	}
+
+	if len(alerts) > 0 {
+		log.Printf("Found %d wallets nearing their XRP reserve", len(alerts))
+	}
+	return alerts, nil
+}
+
+// publishReserveAlert logs a reserve alert and publishes it when an event bus is configured
+func (s *WalletMonitoringService) publishReserveAlert(alert WalletReserveAlert) {
+	log.Printf("Wallet %s has %d drops above its %d drop reserve (%s)",
+		alert.Address, alert.SpendableDrops, alert.ReserveDrops, alert.Severity)
+	if s.eventBus == nil {
+		return
+	}
+
+	event := &messaging.Event{
+		Type:   "wallet.reserve.low",
+		Source: "wallet-monitoring-service",
+		Data: map[string]interface{}{
+			"wallet_id":       alert.WalletID.String(),
+			"enterprise_id":   alert.EnterpriseID.String(),
+			"address":         alert.Address,
+			"severity":        string(alert.Severity),
+			"balance_drops":   alert.BalanceDrops,
+			"reserve_drops":   alert.ReserveDrops,
+			"spendable_drops": alert.SpendableDrops,
+			"owner_count":     alert.OwnerCount,
+		},
+		Timestamp: alert.Timestamp.Format(time.RFC3339),
+	}
+	if err := s.eventBus.PublishEvent(context.Background(), event); err != nil {
+		log.Printf("Failed to publish reserve alert for wallet %s: %v", alert.Address, err)
+	}
+}
+
 // GetWalletMetrics returns basic metrics about wallet usage
 func (s *WalletMonitoringService) GetWalletMetrics() (map[string]interface{}, error) {
 	status, err := s.GetWalletHealthStatus()
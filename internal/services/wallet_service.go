@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,17 +12,28 @@ import (
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/crypto"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
+// accountDeleteValidationTimeout bounds how long retiring a wallet waits for its AccountDelete to
+// validate
+const accountDeleteValidationTimeout = 2 * time.Minute
+
 type WalletService struct {
 	walletRepo     repository.WalletRepositoryInterface
 	enterpriseRepo repository.EnterpriseRepositoryInterface
 	xrplService    repository.XRPLServiceInterface
 	encryptor      *crypto.Encryptor
+	treasury       string
 }
 
+// Verify that WalletService can supply signing keys to the XRPL client
+var _ xrpl.KeyProvider = (*WalletService)(nil)
+
 type WalletServiceConfig struct {
 	EncryptionKey string
+	// TreasuryAddress receives the XRP swept from retired wallets
+	TreasuryAddress string
 }
 
 func NewWalletService(
@@ -39,6 +52,7 @@ func NewWalletService(
 		enterpriseRepo: enterpriseRepo,
 		xrplService:    xrplService,
 		encryptor:      encryptor,
+		treasury:       config.TreasuryAddress,
 	}, nil
 }
 
@@ -85,6 +99,9 @@ func (s *WalletService) CreateWalletForEnterprise(enterpriseID uuid.UUID, networ
 		NetworkType:         networkType,
 		Metadata:            make(models.WalletMetadata),
 	}
+	if xrplWallet.KeyType != "" {
+		wallet.Metadata["key_type"] = xrplWallet.KeyType
+	}
 
 	// Store in database
 	if err := s.walletRepo.Create(wallet); err != nil {
@@ -165,6 +182,82 @@ func (s *WalletService) SuspendWallet(walletID uuid.UUID, reason string) error {
 	return nil
 }
 
+// RetireWallet deletes the XRPL account of a deactivated wallet, sweeping its XRP above the
+// deletion fee to treasury, and marks the wallet retired once the deletion validates. A wallet
+// whose account was never funded is retired without a sweep.
+func (s *WalletService) RetireWallet(walletID uuid.UUID) (*xrpl.TransactionResult, error) {
+	if s.treasury == "" {
+		return nil, fmt.Errorf("no treasury address configured to sweep retired wallets into")
+	}
+
+	wallet, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if wallet.Status != models.WalletStatusDeactivated {
+		return nil, fmt.Errorf("only deactivated wallets can be retired (status: %s)", wallet.Status)
+	}
+	if wallet.Metadata == nil {
+		wallet.Metadata = make(models.WalletMetadata)
+	}
+
+	var result *xrpl.TransactionResult
+	_, err = s.xrplService.GetAccountReserve(wallet.Address)
+	switch {
+	case errors.Is(err, xrpl.ErrAccountNotFound):
+		log.Printf("Wallet %s was never funded, retiring without a sweep", wallet.Address)
+	case err != nil:
+		return nil, err
+	default:
+		// Mark the wallet as retiring so the key provider signs the AccountDelete for it
+		wallet.Metadata["retiring_at"] = time.Now().Format(time.RFC3339)
+		if err := s.walletRepo.Update(wallet); err != nil {
+			return nil, fmt.Errorf("failed to mark wallet retiring: %w", err)
+		}
+
+		result, err = s.deleteAccount(wallet.Address)
+		if err != nil {
+			// The account is still on the ledger, so the wallet stops signing for it again
+			delete(wallet.Metadata, "retiring_at")
+			if updateErr := s.walletRepo.Update(wallet); updateErr != nil {
+				log.Printf("Failed to clear retiring mark of wallet %s: %v", wallet.Address, updateErr)
+			}
+			return result, err
+		}
+		wallet.Metadata["account_delete_tx"] = result.TransactionID
+		if delivered := result.DeliveredAmount; delivered != nil && delivered.IsNative() {
+			wallet.Metadata["swept_balance_drops"] = delivered.Value
+		}
+	}
+
+	wallet.Status = models.WalletStatusRetired
+	wallet.Metadata["retired_at"] = time.Now().Format(time.RFC3339)
+	delete(wallet.Metadata, "retiring_at")
+	if err := s.walletRepo.Update(wallet); err != nil {
+		return result, fmt.Errorf("failed to retire wallet: %w", err)
+	}
+
+	log.Printf("Retired wallet %s into treasury %s", wallet.Address, s.treasury)
+	return result, nil
+}
+
+// deleteAccount submits the AccountDelete sweeping an account into treasury and waits for it to
+// validate, returning the validated result with what it delivered
+func (s *WalletService) deleteAccount(address string) (*xrpl.TransactionResult, error) {
+	submitted, err := s.xrplService.DeleteAccount(address, s.treasury)
+	if err != nil {
+		return submitted, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountDeleteValidationTimeout)
+	defer cancel()
+	validated, err := s.xrplService.WaitForValidation(ctx, submitted.TransactionID, submitted.LastLedgerSequence)
+	if err != nil {
+		return submitted, fmt.Errorf("account deletion %s of %s did not validate: %w", submitted.TransactionID, address, err)
+	}
+	return validated, nil
+}
+
 func (s *WalletService) GetWalletByID(walletID uuid.UUID) (*models.WalletResponse, error) {
 	wallet, err := s.walletRepo.GetByID(walletID)
 	if err != nil {
@@ -251,3 +344,34 @@ func (s *WalletService) GetDecryptedPrivateKey(walletID uuid.UUID) (string, erro
 
 	return privateKey, nil
 }
+
+// SigningKey derives the keypair of a wallet from its encrypted seed so transactions
+// can be signed in-process. The decrypted keys are never returned to API callers.
+func (s *WalletService) SigningKey(address string) (*xrpl.KeyPair, error) {
+	wallet, err := s.walletRepo.GetByAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	// A deactivated wallet being retired still signs the AccountDelete that sweeps it
+	retiring := wallet.Status == models.WalletStatusDeactivated && wallet.Metadata["retiring_at"] != ""
+	if !wallet.CanTransact() && !retiring {
+		return nil, fmt.Errorf("wallet cannot transact (status: %s, whitelisted: %v)", wallet.Status, wallet.IsWhitelisted)
+	}
+
+	seed, err := s.encryptor.Decrypt(wallet.EncryptedSeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt seed: %w", err)
+	}
+
+	keyPair, err := xrpl.DeriveKeyPair(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive signing key: %w", err)
+	}
+
+	if keyPair.Address() != wallet.Address {
+		return nil, fmt.Errorf("stored seed does not match wallet address %s", wallet.Address)
+	}
+
+	return keyPair, nil
+}
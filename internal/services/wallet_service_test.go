@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/smart-payment-infrastructure/internal/models"
+	"github.com/smart-payment-infrastructure/pkg/crypto"
 	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
@@ -179,8 +182,26 @@ func (m *MockXRPLService) HealthCheck() error {
 	return args.Error(0)
 }
 
-func (m *MockXRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount float64, currency string, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestoneSecret)
+func (m *MockXRPLService) GetAccountReserve(address string) (*xrpl.AccountReserve, error) {
+	args := m.Called(address)
+	reserve, _ := args.Get(0).(*xrpl.AccountReserve)
+	return reserve, args.Error(1)
+}
+
+func (m *MockXRPLService) DeleteAccount(address, destination string) (*xrpl.TransactionResult, error) {
+	args := m.Called(address, destination)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *MockXRPLService) WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error) {
+	args := m.Called(ctx, hash, lastLedgerSequence)
+	result, _ := args.Get(0).(*xrpl.TransactionResult)
+	return result, args.Error(1)
+}
+
+func (m *MockXRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestoneSecret)
 	if args.Get(0) == nil {
 		return nil, "", args.Error(2)
 	}
@@ -211,17 +232,89 @@ func (m *MockXRPLService) GetEscrowStatus(ownerAddress string, sequence string)
 	return args.Get(0).(*xrpl.EscrowInfo), args.Error(1)
 }
 
+func (m *MockXRPLService) ListEscrows(ownerAddress string) ([]xrpl.EscrowInfo, error) {
+	args := m.Called(ownerAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]xrpl.EscrowInfo), args.Error(1)
+}
+
+func (m *MockXRPLService) FindEscrowResolution(ownerAddress string, sequence, sinceLedger uint32) (*xrpl.EscrowResolution, error) {
+	args := m.Called(ownerAddress, sequence, sinceLedger)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.EscrowResolution), args.Error(1)
+}
+
+func (m *MockXRPLService) GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.PaymentDelivery), args.Error(1)
+}
+
+func (m *MockXRPLService) CreateSmartChequeCheck(payerAddress, payeeAddress string, amount models.Money, validFor time.Duration, invoiceID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, validFor, invoiceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *MockXRPLService) CashSmartChequeCheck(payeeAddress, checkID string, amount models.Money, minimum bool) (*xrpl.TransactionResult, error) {
+	args := m.Called(payeeAddress, checkID, amount, minimum)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *MockXRPLService) CancelSmartChequeCheck(accountAddress, checkID string) (*xrpl.TransactionResult, error) {
+	args := m.Called(accountAddress, checkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
+func (m *MockXRPLService) GetCheck(checkID string) (*xrpl.CheckInfo, error) {
+	args := m.Called(checkID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.CheckInfo), args.Error(1)
+}
+
+func (m *MockXRPLService) FindCheckResolution(sourceAddress, checkID string, sinceLedger uint32) (*xrpl.CheckResolution, error) {
+	args := m.Called(sourceAddress, checkID, sinceLedger)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.CheckResolution), args.Error(1)
+}
+
+func (m *MockXRPLService) SendPayment(fromAddress, toAddress string, amount models.Money) (*xrpl.TransactionResult, error) {
+	args := m.Called(fromAddress, toAddress, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*xrpl.TransactionResult), args.Error(1)
+}
+
 func (m *MockXRPLService) GenerateCondition(secret string) (condition string, fulfillment string, err error) {
 	args := m.Called(secret)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
-func (m *MockXRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount float64, currency string, milestones []models.Milestone) (*xrpl.TransactionResult, string, error) {
-	args := m.Called(payerAddress, payeeAddress, amount, currency, milestones)
+func (m *MockXRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount models.Money, milestones []models.Milestone) ([]models.MilestoneEscrowFunding, error) {
+	args := m.Called(payerAddress, payeeAddress, amount, milestones)
 	if args.Get(0) == nil {
-		return nil, "", args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(*xrpl.TransactionResult), args.String(1), args.Error(2)
+	return args.Get(0).([]models.MilestoneEscrowFunding), args.Error(1)
 }
 
 func TestWalletService_CreateWalletForEnterprise(t *testing.T) {
@@ -566,3 +659,160 @@ func TestWalletService_ValidateWalletAddress(t *testing.T) {
 	// Verify mocks
 	mockXRPLService.AssertExpectations(t)
 }
+
+func TestWalletService_SigningKey(t *testing.T) {
+	// Setup mocks
+	mockWalletRepo := &MockWalletRepositoryInterface{}
+	mockEnterpriseRepo := &MockEnterpriseRepositoryInterface{}
+	mockXRPLService := &MockXRPLService{}
+
+	encryptionKey := "12345678901234567890123456789012"
+	service, err := NewWalletService(
+		mockWalletRepo,
+		mockEnterpriseRepo,
+		mockXRPLService,
+		WalletServiceConfig{
+			EncryptionKey: encryptionKey,
+		},
+	)
+	require.NoError(t, err)
+
+	// Test data
+	seed := "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"
+	address := "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+
+	encryptor, err := crypto.NewEncryptor(encryptionKey)
+	require.NoError(t, err)
+	encryptedSeed, err := encryptor.Encrypt(seed)
+	require.NoError(t, err)
+
+	wallet := &models.Wallet{
+		ID:            uuid.New(),
+		Address:       address,
+		EncryptedSeed: encryptedSeed,
+		Status:        models.WalletStatusActive,
+		IsWhitelisted: true,
+	}
+	suspended := &models.Wallet{
+		ID:            uuid.New(),
+		Address:       "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY",
+		EncryptedSeed: encryptedSeed,
+		Status:        models.WalletStatusSuspended,
+	}
+
+	// Setup expectations
+	mockWalletRepo.On("GetByAddress", address).Return(wallet, nil)
+	mockWalletRepo.On("GetByAddress", suspended.Address).Return(suspended, nil)
+
+	// Execute
+	keyPair, err := service.SigningKey(address)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, address, keyPair.Address())
+	assert.Equal(t, xrpl.KeyTypeSecp256k1, keyPair.KeyType)
+
+	_, err = service.SigningKey(suspended.Address)
+	assert.Error(t, err)
+
+	// Verify mocks
+	mockWalletRepo.AssertExpectations(t)
+}
+
+func TestWalletService_RetireWallet(t *testing.T) {
+	mockWalletRepo := &MockWalletRepositoryInterface{}
+	mockXRPLService := &MockXRPLService{}
+	treasury := "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+
+	service, err := NewWalletService(mockWalletRepo, &MockEnterpriseRepositoryInterface{}, mockXRPLService, WalletServiceConfig{
+		EncryptionKey:   "12345678901234567890123456789012",
+		TreasuryAddress: treasury,
+	})
+	require.NoError(t, err)
+
+	active := &models.Wallet{ID: uuid.New(), Address: "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY", Status: models.WalletStatusActive}
+	funded := &models.Wallet{ID: uuid.New(), Address: "rN7n7otQDd6FczFgLdSqtcsAUxDkw6fzRH", Status: models.WalletStatusDeactivated}
+	unfunded := &models.Wallet{ID: uuid.New(), Address: "rLHzPsX6oXkzU2qL12kHCH8G8cnZv1rBJh", Status: models.WalletStatusDeactivated}
+
+	mockWalletRepo.On("GetByID", active.ID).Return(active, nil)
+	mockWalletRepo.On("GetByID", funded.ID).Return(funded, nil)
+	mockWalletRepo.On("GetByID", unfunded.ID).Return(unfunded, nil)
+	mockWalletRepo.On("Update", mock.AnythingOfType("*models.Wallet")).Return(nil)
+	mockXRPLService.On("GetAccountReserve", funded.Address).Return(&xrpl.AccountReserve{Account: funded.Address, BalanceDrops: 25000000}, nil)
+	mockXRPLService.On("GetAccountReserve", unfunded.Address).Return(nil, xrpl.ErrAccountNotFound)
+
+	// Only deactivated wallets are retired
+	_, err = service.RetireWallet(active.ID)
+	assert.Error(t, err)
+
+	// A deletion the network refuses leaves the wallet deactivated and no longer signing
+	mockXRPLService.On("DeleteAccount", funded.Address, treasury).Return(nil, xrpl.ErrAccountNotDeletable).Once()
+	_, err = service.RetireWallet(funded.ID)
+	assert.ErrorIs(t, err, xrpl.ErrAccountNotDeletable)
+	assert.Equal(t, models.WalletStatusDeactivated, funded.Status)
+	assert.Empty(t, funded.Metadata["retiring_at"])
+
+	// So does one that validates with a failure result
+	submitted := &xrpl.TransactionResult{TransactionID: "DELETE_TX", LastLedgerSequence: 120, ResultCode: "tesSUCCESS"}
+	mockXRPLService.On("DeleteAccount", funded.Address, treasury).Return(submitted, nil)
+	rejected := &xrpl.TransactionError{TransactionID: "DELETE_TX", Code: "tecHAS_OBLIGATIONS", Message: "validated with a failure result"}
+	mockXRPLService.On("WaitForValidation", mock.Anything, "DELETE_TX", uint32(120)).Return(nil, rejected).Once()
+	_, err = service.RetireWallet(funded.ID)
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, models.WalletStatusDeactivated, funded.Status)
+	assert.Empty(t, funded.Metadata["retiring_at"])
+	assert.Empty(t, funded.Metadata["account_delete_tx"])
+
+	// The sweep records what the validated deletion delivered, which is the balance less its fee
+	delivered := xrpl.XRPAmount(22000000)
+	validated := &xrpl.TransactionResult{TransactionID: "DELETE_TX", Validated: true, ResultCode: "tesSUCCESS", DeliveredAmount: &delivered}
+	mockXRPLService.On("WaitForValidation", mock.Anything, "DELETE_TX", uint32(120)).Return(validated, nil).Once()
+	result, err := service.RetireWallet(funded.ID)
+	require.NoError(t, err)
+	assert.Equal(t, validated, result)
+	assert.Equal(t, models.WalletStatusRetired, funded.Status)
+	assert.Equal(t, "DELETE_TX", funded.Metadata["account_delete_tx"])
+	assert.Equal(t, "22000000", funded.Metadata["swept_balance_drops"])
+	assert.Empty(t, funded.Metadata["retiring_at"])
+
+	// An account that was never funded has nothing to sweep
+	result, err = service.RetireWallet(unfunded.ID)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, models.WalletStatusRetired, unfunded.Status)
+	mockXRPLService.AssertNotCalled(t, "DeleteAccount", unfunded.Address, treasury)
+
+	mockXRPLService.AssertExpectations(t)
+}
+
+func TestWalletMonitoringService_CheckReserveHeadroom(t *testing.T) {
+	mockWalletRepo := &MockWalletRepositoryInterface{}
+	mockXRPLService := &MockXRPLService{}
+	eventBus := &TestMockEventBus{}
+	service := NewWalletMonitoringServiceWithAlerts(mockWalletRepo, mockXRPLService, eventBus, DefaultWalletMonitoringConfig())
+
+	settings := xrpl.ReserveSettings{BaseDrops: 10000000, IncrementDrops: 2000000}
+	healthy := &models.Wallet{ID: uuid.New(), Address: "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY", Status: models.WalletStatusActive}
+	low := &models.Wallet{ID: uuid.New(), Address: "rN7n7otQDd6FczFgLdSqtcsAUxDkw6fzRH", Status: models.WalletStatusActive}
+	drained := &models.Wallet{ID: uuid.New(), Address: "rLHzPsX6oXkzU2qL12kHCH8G8cnZv1rBJh", Status: models.WalletStatusActive}
+	retired := &models.Wallet{ID: uuid.New(), Address: "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", Status: models.WalletStatusRetired}
+
+	mockWalletRepo.On("GetAllWallets").Return([]*models.Wallet{healthy, low, drained, retired}, nil)
+	mockXRPLService.On("GetAccountReserve", healthy.Address).Return(&xrpl.AccountReserve{BalanceDrops: 100000000, ReserveDrops: 10000000, Settings: settings}, nil)
+	mockXRPLService.On("GetAccountReserve", low.Address).Return(&xrpl.AccountReserve{BalanceDrops: 18000000, OwnerCount: 1, ReserveDrops: 12000000, Settings: settings}, nil)
+	mockXRPLService.On("GetAccountReserve", drained.Address).Return(&xrpl.AccountReserve{BalanceDrops: 12500000, OwnerCount: 1, ReserveDrops: 12000000, Settings: settings}, nil)
+	eventBus.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+
+	alerts, err := service.CheckReserveHeadroom()
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, low.Address, alerts[0].Address)
+	assert.Equal(t, AlertSeverityWarning, alerts[0].Severity)
+	assert.Equal(t, int64(6000000), alerts[0].SpendableDrops)
+	assert.Equal(t, drained.Address, alerts[1].Address)
+	assert.Equal(t, AlertSeverityCritical, alerts[1].Severity)
+
+	require.Len(t, eventBus.GetPublishedEvents(), 2)
+	assert.Equal(t, "wallet.reserve.low", eventBus.GetPublishedEvents()[0].Type)
+	mockXRPLService.AssertNotCalled(t, "GetAccountReserve", retired.Address)
+}
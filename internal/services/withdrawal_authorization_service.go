@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +14,7 @@ import (
 	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/repository"
 	"github.com/smart-payment-infrastructure/pkg/messaging"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 // WithdrawalAuthorizationServiceInterface defines the interface for withdrawal authorization operations
@@ -47,6 +51,10 @@ type WithdrawalAuthorizationService struct {
 	userService         UserServiceInterface
 	messagingClient     messaging.EventBus
 	authorizationConfig *AuthorizationConfig
+	multiSign           *MultiSignService
+
+	mu       sync.RWMutex
+	requests map[uuid.UUID]*WithdrawalAuthorization
 }
 
 // NewWithdrawalAuthorizationService creates a new withdrawal authorization service instance
@@ -57,6 +65,20 @@ func NewWithdrawalAuthorizationService(
 	userService UserServiceInterface,
 	messagingClient messaging.EventBus,
 	config *AuthorizationConfig,
+) WithdrawalAuthorizationServiceInterface {
+	return NewWithdrawalAuthorizationServiceWithMultiSign(assetRepo, balanceRepo, treasuryService, userService, messagingClient, config, nil)
+}
+
+// NewWithdrawalAuthorizationServiceWithMultiSign creates a withdrawal authorization service whose
+// withdrawals from multi-signing enterprise wallets are signed on-ledger by each approver
+func NewWithdrawalAuthorizationServiceWithMultiSign(
+	assetRepo repository.AssetRepositoryInterface,
+	balanceRepo repository.BalanceRepositoryInterface,
+	treasuryService TreasuryServiceInterface,
+	userService UserServiceInterface,
+	messagingClient messaging.EventBus,
+	config *AuthorizationConfig,
+	multiSign *MultiSignService,
 ) WithdrawalAuthorizationServiceInterface {
 	return &WithdrawalAuthorizationService{
 		assetRepo:           assetRepo,
@@ -65,6 +87,8 @@ func NewWithdrawalAuthorizationService(
 		userService:         userService,
 		messagingClient:     messagingClient,
 		authorizationConfig: config,
+		multiSign:           multiSign,
+		requests:            make(map[uuid.UUID]*WithdrawalAuthorization),
 	}
 }
 
@@ -115,6 +139,8 @@ type WithdrawalApprovalRequest struct {
 	ApprovalType string    `json:"approval_type" validate:"required"` // "approve", "reject"
 	Comments     string    `json:"comments,omitempty"`
 	AuthToken    string    `json:"auth_token,omitempty"`
+	// Signature over the withdrawal's multi-signed transaction; the approver's platform-held key signs when empty
+	Signature *xrpl.Signer `json:"signature,omitempty"`
 }
 
 type WithdrawalRejectionRequest struct {
@@ -161,6 +187,8 @@ type WithdrawalAuthorization struct {
 	RiskScore         float64               `json:"risk_score"`
 	TimeLocked        bool                  `json:"time_locked"`
 	TimeLockExpiresAt *time.Time            `json:"time_lock_expires_at,omitempty"`
+	MultiSignWallet   string                `json:"multi_sign_wallet,omitempty"`
+	LedgerTxID        string                `json:"ledger_tx_id,omitempty"`
 	CreatedAt         time.Time             `json:"created_at"`
 	UpdatedAt         time.Time             `json:"updated_at"`
 	ProcessedAt       *time.Time            `json:"processed_at,omitempty"`
@@ -335,6 +363,17 @@ func (s *WithdrawalAuthorizationService) CreateWithdrawalRequest(ctx context.Con
 		authorization.Status = WithdrawalAuthStatusTimeLocked
 	}
 
+	// Withdrawals from multi-signing wallets collect one ledger signature per approval
+	if s.multiSign != nil {
+		if err := s.beginMultiSign(ctx, authorization); err != nil && !errors.Is(err, ErrSignerListNotConfigured) {
+			return nil, fmt.Errorf("failed to prepare multi-signed withdrawal: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.requests[authorization.ID] = authorization
+	s.mu.Unlock()
+
 	// Publish withdrawal request event
 	if s.messagingClient != nil {
 		event := &messaging.Event{
@@ -476,6 +515,16 @@ func (s *WithdrawalAuthorizationService) ApproveWithdrawal(ctx context.Context,
 		}
 	}
 
+	// An approval of a multi-signed withdrawal only counts with the approver's ledger signature
+	quorumMet := true
+	if authorization.MultiSignWallet != "" {
+		session, err := s.collectSignature(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		quorumMet = session.QuorumMet()
+	}
+
 	// Create approval record
 	approval := &WithdrawalApproval{
 		ID:           uuid.New(),
@@ -492,7 +541,7 @@ func (s *WithdrawalAuthorizationService) ApproveWithdrawal(ctx context.Context,
 	authorization.UpdatedAt = time.Now()
 
 	// Check if we have enough approvals
-	if authorization.CurrentApprovals >= authorization.RequiredApprovals {
+	if authorization.CurrentApprovals >= authorization.RequiredApprovals && quorumMet {
 		// Check time lock status
 		if authorization.TimeLocked && authorization.TimeLockExpiresAt != nil && time.Now().Before(*authorization.TimeLockExpiresAt) {
 			authorization.Status = WithdrawalAuthStatusTimeLocked
@@ -593,12 +642,22 @@ func (s *WithdrawalAuthorizationService) RejectWithdrawal(ctx context.Context, r
 
 // processApprovedWithdrawal triggers the actual withdrawal processing
 func (s *WithdrawalAuthorizationService) processApprovedWithdrawal(ctx context.Context, authorization *WithdrawalAuthorization) error {
+	destination := AssetTransactionSourceExternal
+	if authorization.MultiSignWallet != "" {
+		result, err := s.multiSign.Submit(ctx, authorization.ID)
+		if err != nil {
+			return err
+		}
+		authorization.LedgerTxID = result.TransactionID
+		destination = AssetTransactionSourceXRPLWallet
+	}
+
 	// Create withdrawal request for treasury service
 	withdrawalReq := &WithdrawalRequest{
 		EnterpriseID:    authorization.EnterpriseID,
 		CurrencyCode:    authorization.CurrencyCode,
 		Amount:          authorization.Amount,
-		Destination:     AssetTransactionSourceExternal,
+		Destination:     destination,
 		Purpose:         fmt.Sprintf("Authorized withdrawal: %s", authorization.Purpose),
 		Reference:       authorization.Reference,
 		RequireApproval: false, // Already approved through authorization workflow
@@ -617,11 +676,81 @@ func (s *WithdrawalAuthorizationService) processApprovedWithdrawal(ctx context.C
 	return nil
 }
 
+// beginMultiSign opens a signature collection for the withdrawal payment from the enterprise
+// wallet whose signer list enforces the withdrawal's required approvals
+func (s *WithdrawalAuthorizationService) beginMultiSign(ctx context.Context, authorization *WithdrawalAuthorization) error {
+	wallet, err := s.multiSign.SelectWallet(authorization.EnterpriseID, authorization.RequiredApprovals)
+	if err != nil {
+		return err
+	}
+	if !xrpl.IsValidClassicAddress(authorization.Destination) {
+		return fmt.Errorf("multi-signed withdrawals need an XRPL destination address, got %s", authorization.Destination)
+	}
+
+	asset, err := s.assetRepo.GetAssetByCurrency(ctx, authorization.CurrencyCode)
+	if err != nil {
+		return fmt.Errorf("unsupported currency: %s", authorization.CurrencyCode)
+	}
+	amount, err := ledgerAmount(asset, authorization.Amount)
+	if err != nil {
+		return err
+	}
+	payment, err := xrpl.TransactionFromStruct("Payment", &xrpl.Payment{
+		Account:     wallet.WalletAddress,
+		Destination: authorization.Destination,
+		Amount:      amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.multiSign.BeginSession(ctx, authorization.ID, payment); err != nil {
+		return err
+	}
+	authorization.MultiSignWallet = wallet.WalletAddress
+	return nil
+}
+
+// collectSignature adds the approver's signature to the withdrawal's multi-sign session
+func (s *WithdrawalAuthorizationService) collectSignature(ctx context.Context, req *WithdrawalApprovalRequest) (*MultiSignSession, error) {
+	if req.Signature != nil {
+		return s.multiSign.AddSignature(ctx, req.RequestID, req.ApproverID, req.Signature)
+	}
+	return s.multiSign.SignAsApprover(ctx, req.RequestID, req.ApproverID)
+}
+
+// ledgerAmount converts an amount in an asset's smallest units into an XRPL amount
+func ledgerAmount(asset *models.SupportedAsset, amount string) (xrpl.Amount, error) {
+	units, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return xrpl.Amount{}, fmt.Errorf("invalid amount format: %s", amount)
+	}
+	if asset.IsNative() {
+		return xrpl.Amount{Value: units.String()}, nil
+	}
+
+	issued, err := issuedCurrencyForAsset(asset)
+	if err != nil {
+		return xrpl.Amount{}, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.DecimalPlaces)), nil)
+	value := new(big.Rat).SetFrac(units, scale).FloatString(asset.DecimalPlaces)
+	if strings.Contains(value, ".") {
+		value = strings.TrimRight(strings.TrimRight(value, "0"), ".")
+	}
+	return issued.Amount(value)
+}
+
 // GetWithdrawalRequest retrieves a withdrawal request by ID
 func (s *WithdrawalAuthorizationService) GetWithdrawalRequest(_ context.Context, requestID uuid.UUID) (*WithdrawalAuthorization, error) {
-	// In a real implementation, this would query the database
-	// For now, return a placeholder
-	return nil, fmt.Errorf("withdrawal request not found: %s", requestID.String())
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	authorization, ok := s.requests[requestID]
+	if !ok {
+		return nil, fmt.Errorf("withdrawal request not found: %s", requestID.String())
+	}
+	return authorization, nil
 }
 
 // GetPendingWithdrawalRequests retrieves pending withdrawal requests for an enterprise
@@ -647,6 +776,9 @@ func (s *WithdrawalAuthorizationService) CheckAuthorizationStatus(ctx context.Co
 			nextSteps = append(nextSteps, fmt.Sprintf("Requires %d more approval(s)", remainingApprovals))
 			// In a real implementation, you'd get the list of eligible approvers
 		}
+		if session, ok := s.multiSignSession(authorization); ok && !session.QuorumMet() {
+			nextSteps = append(nextSteps, fmt.Sprintf("Requires signer weight %d of %d on %s", session.RequiredWeight-session.CollectedWeight, session.RequiredWeight, session.WalletAddress))
+		}
 	}
 
 	if authorization.TimeLocked && authorization.TimeLockExpiresAt != nil {
@@ -673,6 +805,14 @@ func (s *WithdrawalAuthorizationService) CheckAuthorizationStatus(ctx context.Co
 	}, nil
 }
 
+// multiSignSession returns the signature collection of a multi-signed withdrawal
+func (s *WithdrawalAuthorizationService) multiSignSession(authorization *WithdrawalAuthorization) (*MultiSignSession, bool) {
+	if s.multiSign == nil || authorization.MultiSignWallet == "" {
+		return nil, false
+	}
+	return s.multiSign.GetSession(authorization.ID)
+}
+
 // AssessWithdrawalRisk performs risk assessment for a withdrawal request
 func (s *WithdrawalAuthorizationService) AssessWithdrawalRisk(ctx context.Context, req *WithdrawalRiskAssessmentRequest) (*WithdrawalRiskScore, error) {
 	riskFactors := []*WithdrawalRiskFactor{}
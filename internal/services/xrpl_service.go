@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -14,6 +15,11 @@ import (
 type XRPLService struct {
 	client      *xrpl.Client
 	initialized bool
+
+	// issuedAssets maps platform currency codes to their on-ledger issued currency
+	issuedAssets map[string]xrpl.IssuedCurrency
+	// tokenEscrowEnabled is set when the network has the TokenEscrow amendment
+	tokenEscrowEnabled bool
 }
 
 // Verify that XRPLService implements repository.XRPLServiceInterface
@@ -22,16 +28,59 @@ var _ repository.XRPLServiceInterface = (*XRPLService)(nil)
 type XRPLConfig struct {
 	NetworkURL string
 	TestNet    bool
+	// Mode selects the ledger transport ("simulator", "jsonrpc" or "sandbox"); empty means simulator
+	Mode string
+	// PollInterval overrides how often submitted transactions are checked for validation
+	PollInterval time.Duration
 }
 
 func NewXRPLService(config XRPLConfig) *XRPLService {
-	client := xrpl.NewClient(config.NetworkURL, config.TestNet)
+	client := xrpl.NewClientWithMode(config.NetworkURL, config.TestNet, xrpl.Mode(config.Mode))
+	if config.PollInterval > 0 {
+		client.PollInterval = config.PollInterval
+	}
 	return &XRPLService{
-		client: client,
+		client:       client,
+		issuedAssets: make(map[string]xrpl.IssuedCurrency),
+	}
+}
+
+// ConfigureIssuedAssets registers the issuers of the platform's non-native assets
+func (s *XRPLService) ConfigureIssuedAssets(assets []*models.SupportedAsset) error {
+	for _, asset := range assets {
+		if !asset.RequiresTrustLine() {
+			continue
+		}
+		issued, err := issuedCurrencyForAsset(asset)
+		if err != nil {
+			return err
+		}
+		s.issuedAssets[asset.CurrencyCode] = issued
 	}
+	return nil
+}
+
+// IssuedCurrency returns the configured issued currency for a platform currency code
+func (s *XRPLService) IssuedCurrency(currency string) (xrpl.IssuedCurrency, bool) {
+	issued, ok := s.issuedAssets[currency]
+	return issued, ok
+}
+
+// TokenEscrowEnabled reports whether escrows of issued currencies are available on the network
+func (s *XRPLService) TokenEscrowEnabled() bool {
+	return s.tokenEscrowEnabled
+}
+
+// SetKeyProvider configures the source of signing keys used to sign transactions locally
+func (s *XRPLService) SetKeyProvider(provider xrpl.KeyProvider) {
+	s.client.SetKeyProvider(provider)
 }
 
 func (s *XRPLService) Initialize() error {
+	if _, err := xrpl.ParseMode(string(s.client.Mode)); err != nil {
+		return fmt.Errorf("invalid XRPL configuration: %w", err)
+	}
+
 	if err := s.client.Connect(); err != nil {
 		return fmt.Errorf("failed to connect to XRPL: %w", err)
 	}
@@ -40,6 +89,12 @@ func (s *XRPLService) Initialize() error {
 		return fmt.Errorf("XRPL health check failed: %w", err)
 	}
 
+	enabled, err := s.client.AmendmentEnabled(xrpl.AmendmentTokenEscrow)
+	if err != nil {
+		log.Printf("Failed to check %s amendment, token escrows disabled: %v", xrpl.AmendmentTokenEscrow, err)
+	}
+	s.tokenEscrowEnabled = enabled
+
 	s.initialized = true
 	log.Println("XRPL service initialized successfully")
 	return nil
@@ -68,6 +123,75 @@ func (s *XRPLService) GetAccountInfo(address string) (interface{}, error) {
 	return accountInfo, nil
 }
 
+// GetAccountReserve reports how much of an account's XRP its reserve locks at the live reserve settings
+func (s *XRPLService) GetAccountReserve(address string) (*xrpl.AccountReserve, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	reserve, err := s.client.GetAccountReserve(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account reserve for %s: %w", address, err)
+	}
+	return reserve, nil
+}
+
+// DeleteAccount deletes an account and sweeps its recoverable XRP to destination
+func (s *XRPLService) DeleteAccount(address, destination string) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.DeleteAccount(&xrpl.AccountDelete{Account: address, Destination: destination})
+	if err != nil {
+		return result, fmt.Errorf("failed to delete account %s: %w", address, err)
+	}
+
+	log.Printf("XRPL account %s deleted into %s: %s", address, destination, result.TransactionID)
+	return result, nil
+}
+
+// SetAccountFlag enables an AccountSet flag on an account, such as requiring destination tags
+func (s *XRPLService) SetAccountFlag(address string, flag uint32) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.SetAccountFlags(&xrpl.AccountSet{Account: address, SetFlag: flag})
+	if err != nil {
+		return result, fmt.Errorf("failed to set flag %d on %s: %w", flag, address, err)
+	}
+
+	log.Printf("XRPL account %s set flag %d: %s", address, flag, result.TransactionID)
+	return result, nil
+}
+
+// ensureEscrowReserve fails fast when the payer cannot fund escrows locking drops of XRP on top of
+// the owner reserve each escrow adds, rather than letting the ledger reject them
+func (s *XRPLService) ensureEscrowReserve(payerAddress string, escrows uint32, drops int64) error {
+	reserve, err := s.client.GetAccountReserve(payerAddress)
+	if err != nil {
+		return fmt.Errorf("failed to check reserve of %s: %w", payerAddress, err)
+	}
+
+	if err := reserve.Covers(escrows, drops); err != nil {
+		return fmt.Errorf("cannot fund escrow: %w", err)
+	}
+	return nil
+}
+
+// escrowDrops returns the XRP an escrow amount locks in drops; issued currencies lock none
+func escrowDrops(amount xrpl.Amount) (int64, error) {
+	if !amount.IsNative() {
+		return 0, nil
+	}
+	drops, err := strconv.ParseInt(amount.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escrow amount %q: %w", amount.Value, err)
+	}
+	return drops, nil
+}
+
 func (s *XRPLService) HealthCheck() error {
 	if !s.initialized {
 		return fmt.Errorf("XRPL service not initialized")
@@ -75,14 +199,39 @@ func (s *XRPLService) HealthCheck() error {
 	return s.client.HealthCheck()
 }
 
+// SubmitOptions carries how a transaction is submitted; the zero value uses the account's next
+// sequence and pays the current open ledger cost
+type SubmitOptions struct {
+	// Ticket submits with one of the account's tickets instead of its next sequence when non-zero
+	Ticket uint32
+	// Fee is the transaction cost in drops; empty pays the open ledger cost
+	Fee string
+}
+
 // CreateSmartChequeEscrow creates an escrow for a Smart Check with basic milestone support
-func (s *XRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount float64, currency string, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
+func (s *XRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string) (*xrpl.TransactionResult, string, error) {
+	return s.CreateSmartChequeEscrowWithOptions(payerAddress, payeeAddress, amount, milestoneSecret, SubmitOptions{})
+}
+
+// CreateSmartChequeEscrowWithOptions creates a Smart Check escrow submitted with the given ticket and fee
+func (s *XRPLService) CreateSmartChequeEscrowWithOptions(payerAddress, payeeAddress string, amount models.Money, milestoneSecret string, options SubmitOptions) (*xrpl.TransactionResult, string, error) {
 	if !s.initialized {
 		return nil, "", fmt.Errorf("XRPL service not initialized")
 	}
 
-	// Convert amount to drops (for XRP) or appropriate format
-	amountStr := s.formatAmount(amount, currency)
+	// Convert amount to drops (for XRP) or an issued-currency amount
+	escrowAmount, err := s.buildAmount(amount)
+	if err != nil {
+		return nil, "", err
+	}
+
+	drops, err := escrowDrops(escrowAmount)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.ensureEscrowReserve(payerAddress, 1, drops); err != nil {
+		return nil, "", err
+	}
 
 	// Generate condition and fulfillment for milestone completion
 	condition, fulfillment, err := s.client.GenerateCondition(milestoneSecret)
@@ -94,119 +243,192 @@ func (s *XRPLService) CreateSmartChequeEscrow(payerAddress, payeeAddress string,
 	escrow := &xrpl.EscrowCreate{
 		Account:     payerAddress,
 		Destination: payeeAddress,
-		Amount:      amountStr,
+		Amount:      escrowAmount,
 		Condition:   condition,
 		// Set cancel after 30 days (approximate ledger time)
 		CancelAfter: s.getLedgerTimeOffset(30 * 24 * time.Hour),
 		// Allow finish after 1 hour minimum
-		FinishAfter: s.getLedgerTimeOffset(1 * time.Hour),
+		FinishAfter:    s.getLedgerTimeOffset(1 * time.Hour),
+		TicketSequence: options.Ticket,
+		Fee:            options.Fee,
 	}
 
 	// Create the escrow
 	result, err := s.client.CreateEscrow(escrow)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create escrow: %w", err)
+		return result, "", fmt.Errorf("failed to create escrow: %w", err)
 	}
 
-	log.Printf("Smart Check escrow created: %s, Amount: %s %s", result.TransactionID, amountStr, currency)
+	log.Printf("Smart Check escrow created: %s, Amount: %s %s", result.TransactionID, escrowAmount, amount.Currency())
 	return result, fulfillment, nil
 }
 
-// CreateSmartChequeEscrowWithMilestones creates an escrow with milestone-based conditions
-func (s *XRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount float64, currency string, milestones []models.Milestone) (*xrpl.TransactionResult, string, error) {
+// CreateSmartChequeEscrowWithMilestones funds every escrow milestone as an escrow of its own, with
+// its own condition, FinishAfter and CancelAfter, so that each milestone is released or cancelled
+// without touching the others. Payment channel milestones are funded through their channel instead.
+// When a milestone fails to fund, the escrows already created are returned with the error.
+func (s *XRPLService) CreateSmartChequeEscrowWithMilestones(payerAddress, payeeAddress string, amount models.Money, milestones []models.Milestone) ([]models.MilestoneEscrowFunding, error) {
 	if !s.initialized {
-		return nil, "", fmt.Errorf("XRPL service not initialized")
+		return nil, fmt.Errorf("XRPL service not initialized")
 	}
 
-	// Convert amount to drops (for XRP) or appropriate format
-	amountStr := s.formatAmount(amount, currency)
+	// Price and validate every milestone before the first escrow is funded
+	type milestoneEscrow struct {
+		condition xrpl.MilestoneCondition
+		escrow    *xrpl.EscrowCreate
+	}
+	var pending []milestoneEscrow
+	total := models.ZeroMoney(amount.Currency())
+	var totalDrops int64
+	for _, milestone := range milestones {
+		if milestone.PaymentMode == models.MilestonePaymentModeChannel {
+			continue
+		}
 
-	// Convert milestones to XRPL milestone conditions
-	xrplMilestones := make([]xrpl.MilestoneCondition, len(milestones))
-	for i, milestone := range milestones {
-		xrplMilestones[i] = xrpl.MilestoneCondition{
+		milestoneAmount := milestone.Amount.WithCurrency(amount.Currency())
+		escrowAmount, err := s.buildAmount(milestoneAmount)
+		if err != nil {
+			return nil, fmt.Errorf("milestone %s: %w", milestone.ID, err)
+		}
+		if total, err = total.Add(milestoneAmount); err != nil {
+			return nil, fmt.Errorf("milestone %s: %w", milestone.ID, err)
+		}
+		drops, err := escrowDrops(escrowAmount)
+		if err != nil {
+			return nil, err
+		}
+		totalDrops += drops
+
+		condition := xrpl.MilestoneCondition{
 			MilestoneID:        milestone.ID,
 			VerificationMethod: string(milestone.VerificationMethod),
-			OracleConfig:       milestone.OracleConfig.Config,
-			Amount:             s.formatAmount(milestone.Amount, currency),
+			Amount:             escrowAmount.Value,
+		}
+		if milestone.OracleConfig != nil {
+			condition.OracleConfig = milestone.OracleConfig.Config
 		}
+
+		// Proceeds endorsed before funding are escrowed straight to their holder
+		destination := payeeAddress
+		if milestone.Holder != nil && milestone.Holder.Address != "" {
+			destination = milestone.Holder.Address
+		}
+
+		finishAfter, cancelAfter := s.milestoneEscrowWindow(milestone)
+		pending = append(pending, milestoneEscrow{
+			condition: condition,
+			escrow: &xrpl.EscrowCreate{
+				Account:     payerAddress,
+				Destination: destination,
+				Amount:      escrowAmount,
+				FinishAfter: finishAfter,
+				CancelAfter: cancelAfter,
+			},
+		})
 	}
 
-	// Set escrow parameters with dynamic timing based on milestones
-	escrow := &xrpl.EscrowCreate{
-		Account:     payerAddress,
-		Destination: payeeAddress,
-		Amount:      amountStr,
-		// Set cancel after based on longest milestone duration
-		CancelAfter: s.calculateCancelAfter(milestones),
-		// Allow finish after 1 hour minimum
-		FinishAfter: s.getLedgerTimeOffset(1 * time.Hour),
+	if len(pending) == 0 {
+		return nil, fmt.Errorf("smart check has no escrow milestones to fund")
+	}
+	conditions := make([]xrpl.MilestoneCondition, len(pending))
+	for i, p := range pending {
+		conditions[i] = p.condition
+	}
+	if err := s.client.ValidateMilestoneConditions(conditions); err != nil {
+		return nil, fmt.Errorf("milestone validation failed: %w", err)
+	}
+	if cmp, err := total.Cmp(amount); err != nil {
+		return nil, fmt.Errorf("invalid milestone amounts: %w", err)
+	} else if cmp > 0 {
+		return nil, fmt.Errorf("milestones total %s, more than the smart check amount %s", total, amount)
 	}
 
-	// Create the escrow with validated milestone conditions
-	result, err := s.client.CreateConditionalEscrowWithValidation(escrow, xrplMilestones)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create validated escrow with milestones: %w", err)
+	// Every escrow is an owner object of its own on top of the funds it locks
+	if err := s.ensureEscrowReserve(payerAddress, uint32(len(pending)), totalDrops); err != nil {
+		return nil, err
 	}
 
-	// Generate fulfillment for the compound condition
-	compoundSecret := s.client.GenerateCompoundSecret(xrplMilestones)
-	_, fulfillment, err := s.client.GenerateCondition(compoundSecret)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate fulfillment: %w", err)
+	fundings := make([]models.MilestoneEscrowFunding, 0, len(pending))
+	for _, p := range pending {
+		result, fulfillment, err := s.client.CreateMilestoneEscrow(p.escrow, p.condition)
+		if err != nil {
+			return fundings, fmt.Errorf("failed to create escrow for milestone %s: %w", p.condition.MilestoneID, err)
+		}
+
+		fundings = append(fundings, models.MilestoneEscrowFunding{
+			MilestoneID: p.condition.MilestoneID,
+			Escrow: models.MilestoneEscrow{
+				TransactionID: result.TransactionID,
+				Owner:         payerAddress,
+				Destination:   p.escrow.Destination,
+				OfferSequence: result.Sequence,
+				LedgerIndex:   result.LedgerIndex,
+				Condition:     p.escrow.Condition,
+				FinishAfter:   p.escrow.FinishAfter,
+				CancelAfter:   p.escrow.CancelAfter,
+				Status:        models.MilestoneEscrowStatusActive,
+			},
+			Fulfillment:        fulfillment,
+			Validated:          result.Validated,
+			LastLedgerSequence: result.LastLedgerSequence,
+		})
+		log.Printf("Smart Check milestone %s escrow created: %s, Amount: %s %s", p.condition.MilestoneID, result.TransactionID, p.escrow.Amount, amount.Currency())
 	}
 
-	log.Printf("Smart Check escrow with %d validated milestones created: %s, Amount: %s %s", len(milestones), result.TransactionID, amountStr, currency)
-	return result, fulfillment, nil
+	return fundings, nil
 }
 
-// calculateCancelAfter calculates the cancel time based on milestone durations
-func (s *XRPLService) calculateCancelAfter(milestones []models.Milestone) uint32 {
-	// Default cancel after 30 days
-	defaultDuration := 30 * 24 * time.Hour
-
-	// If we have milestones with end dates, use the latest one plus buffer
-	var latestEndDate *time.Time
-	for _, milestone := range milestones {
-		if milestone.EstimatedEndDate != nil {
-			if latestEndDate == nil || milestone.EstimatedEndDate.After(*latestEndDate) {
-				latestEndDate = milestone.EstimatedEndDate
-			}
-		}
+// milestoneEscrowWindow returns the FinishAfter and CancelAfter of a milestone's escrow. It can be
+// finished an hour from now, or once the milestone is due to start when that is later, and
+// cancelled a week after the milestone is due to end, or after 30 days without an end date.
+func (s *XRPLService) milestoneEscrowWindow(milestone models.Milestone) (finishAfter, cancelAfter uint32) {
+	finishAfter = s.getLedgerTimeOffset(1 * time.Hour)
+	if start := milestone.EstimatedStartDate; start != nil && time.Until(*start) > time.Hour {
+		finishAfter = s.getLedgerTimeOffset(time.Until(*start))
 	}
 
-	if latestEndDate != nil {
-		// Add 7 days buffer to the latest milestone end date
-		cancelTime := latestEndDate.Add(7 * 24 * time.Hour)
-		if cancelTime.After(time.Now()) {
-			duration := time.Until(cancelTime)
-			return s.getLedgerTimeOffset(duration)
+	cancelAfter = s.getLedgerTimeOffset(30 * 24 * time.Hour)
+	if end := milestone.EstimatedEndDate; end != nil {
+		// Add 7 days buffer to the milestone end date
+		if cancelTime := end.Add(7 * 24 * time.Hour); cancelTime.After(time.Now()) {
+			cancelAfter = s.getLedgerTimeOffset(time.Until(cancelTime))
 		}
 	}
 
-	// Fall back to default
-	return s.getLedgerTimeOffset(defaultDuration)
+	// The escrow must stay finishable for a while before the payer can take it back
+	if cancelAfter <= finishAfter {
+		cancelAfter = finishAfter + uint32((7 * 24 * time.Hour).Seconds())
+	}
+	return finishAfter, cancelAfter
 }
 
 // CompleteSmartChequeMilestone releases funds for a completed milestone
 func (s *XRPLService) CompleteSmartChequeMilestone(payeeAddress, ownerAddress string, sequence uint32, condition, fulfillment string) (*xrpl.TransactionResult, error) {
+	return s.CompleteSmartChequeMilestoneWithOptions(payeeAddress, ownerAddress, sequence, condition, fulfillment, SubmitOptions{})
+}
+
+// CompleteSmartChequeMilestoneWithOptions releases funds for a completed milestone, submitted with the
+// given ticket and fee. A fee for a finish with a fulfillment must already include its surcharge.
+func (s *XRPLService) CompleteSmartChequeMilestoneWithOptions(payeeAddress, ownerAddress string, sequence uint32, condition, fulfillment string, options SubmitOptions) (*xrpl.TransactionResult, error) {
 	if !s.initialized {
 		return nil, fmt.Errorf("XRPL service not initialized")
 	}
 
 	// Create escrow finish transaction
 	finish := &xrpl.EscrowFinish{
-		Account:       payeeAddress,
-		Owner:         ownerAddress,
-		OfferSequence: sequence,
-		Condition:     condition,
-		Fulfillment:   fulfillment,
+		Account:        payeeAddress,
+		Owner:          ownerAddress,
+		OfferSequence:  sequence,
+		Condition:      condition,
+		Fulfillment:    fulfillment,
+		TicketSequence: options.Ticket,
+		Fee:            options.Fee,
 	}
 
 	// Finish the escrow
 	result, err := s.client.FinishEscrow(finish)
 	if err != nil {
-		return nil, fmt.Errorf("failed to finish escrow: %w", err)
+		return result, fmt.Errorf("failed to finish escrow: %w", err)
 	}
 
 	log.Printf("Smart Check milestone completed: %s, Sequence: %d", result.TransactionID, sequence)
@@ -215,21 +437,28 @@ func (s *XRPLService) CompleteSmartChequeMilestone(payeeAddress, ownerAddress st
 
 // CancelSmartCheque cancels a Smart Check escrow
 func (s *XRPLService) CancelSmartCheque(accountAddress, ownerAddress string, sequence uint32) (*xrpl.TransactionResult, error) {
+	return s.CancelSmartChequeWithOptions(accountAddress, ownerAddress, sequence, SubmitOptions{})
+}
+
+// CancelSmartChequeWithOptions cancels a Smart Check escrow, submitted with the given ticket and fee
+func (s *XRPLService) CancelSmartChequeWithOptions(accountAddress, ownerAddress string, sequence uint32, options SubmitOptions) (*xrpl.TransactionResult, error) {
 	if !s.initialized {
 		return nil, fmt.Errorf("XRPL service not initialized")
 	}
 
 	// Create escrow cancel transaction
 	cancel := &xrpl.EscrowCancel{
-		Account:       accountAddress,
-		Owner:         ownerAddress,
-		OfferSequence: sequence,
+		Account:        accountAddress,
+		Owner:          ownerAddress,
+		OfferSequence:  sequence,
+		TicketSequence: options.Ticket,
+		Fee:            options.Fee,
 	}
 
 	// Cancel the escrow
 	result, err := s.client.CancelEscrow(cancel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to cancel escrow: %w", err)
+		return result, fmt.Errorf("failed to cancel escrow: %w", err)
 	}
 
 	log.Printf("Smart Check canceled: %s, Sequence: %d", result.TransactionID, sequence)
@@ -250,18 +479,455 @@ func (s *XRPLService) GetEscrowStatus(ownerAddress string, sequence string) (*xr
 	return escrowInfo, nil
 }
 
-// formatAmount converts amount to appropriate format based on currency
-func (s *XRPLService) formatAmount(amount float64, currency string) string {
-	switch currency {
+// ListEscrows returns every escrow an account owns in the validated ledger
+func (s *XRPLService) ListEscrows(ownerAddress string) ([]xrpl.EscrowInfo, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	escrows, err := s.client.ListEscrows(ownerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escrows: %w", err)
+	}
+	return escrows, nil
+}
+
+// FindEscrowResolution finds the validated transaction that finished or cancelled an escrow
+// no longer in the ledger, searching the owner's history from sinceLedger
+func (s *XRPLService) FindEscrowResolution(ownerAddress string, sequence, sinceLedger uint32) (*xrpl.EscrowResolution, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	resolution, err := s.client.FindEscrowResolution(ownerAddress, sequence, sinceLedger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve escrow: %w", err)
+	}
+	return resolution, nil
+}
+
+// CreateSmartChequeCheck writes an XRPL Check the payee can cash for up to amount until validFor
+// has passed; the check ID follows from the result's Sequence through xrpl.CheckIndex
+func (s *XRPLService) CreateSmartChequeCheck(payerAddress, payeeAddress string, amount models.Money, validFor time.Duration, invoiceID string) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	sendMax, err := s.paymentAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	check := &xrpl.CheckCreate{
+		Account:     payerAddress,
+		Destination: payeeAddress,
+		SendMax:     sendMax,
+		InvoiceID:   invoiceID,
+	}
+	if validFor > 0 {
+		check.Expiration = s.getLedgerTimeOffset(validFor)
+	}
+
+	result, err := s.client.CreateCheck(check)
+	if err != nil {
+		return result, fmt.Errorf("failed to create check: %w", err)
+	}
+	log.Printf("Smart Check XRPL Check created: %s, SendMax: %s %s", result.TransactionID, sendMax, amount.Currency())
+	return result, nil
+}
+
+// CashSmartChequeCheck cashes a check as its payee, for exactly amount or, when minimum is set,
+// for as much as the payer can cover but no less than amount
+func (s *XRPLService) CashSmartChequeCheck(payeeAddress, checkID string, amount models.Money, minimum bool) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	cashAmount, err := s.paymentAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	cash := &xrpl.CheckCash{Account: payeeAddress, CheckID: checkID}
+	if minimum {
+		cash.DeliverMin = &cashAmount
+	} else {
+		cash.Amount = &cashAmount
+	}
+
+	result, err := s.client.CashCheck(cash)
+	if err != nil {
+		return result, fmt.Errorf("failed to cash check %s: %w", checkID, err)
+	}
+	return result, nil
+}
+
+// CancelSmartChequeCheck removes a check without paying it out
+func (s *XRPLService) CancelSmartChequeCheck(accountAddress, checkID string) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.CancelCheck(&xrpl.CheckCancel{Account: accountAddress, CheckID: checkID})
+	if err != nil {
+		return result, fmt.Errorf("failed to cancel check %s: %w", checkID, err)
+	}
+	return result, nil
+}
+
+// GetCheck looks up a check in the validated ledger
+func (s *XRPLService) GetCheck(checkID string) (*xrpl.CheckInfo, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	check, err := s.client.GetCheck(checkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check %s: %w", checkID, err)
+	}
+	return check, nil
+}
+
+// FindCheckResolution finds the validated transaction that cashed or cancelled a check
+func (s *XRPLService) FindCheckResolution(sourceAddress, checkID string, sinceLedger uint32) (*xrpl.CheckResolution, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	resolution, err := s.client.FindCheckResolution(sourceAddress, checkID, sinceLedger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find check resolution: %w", err)
+	}
+	return resolution, nil
+}
+
+// GetPaymentDelivery looks up what a validated payment declared and what it actually delivered
+func (s *XRPLService) GetPaymentDelivery(hash string) (*xrpl.PaymentDelivery, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	delivery, err := s.client.GetPaymentDelivery(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// GetAccountTransactions reads one page of an account's validated history
+func (s *XRPLService) GetAccountTransactions(request *xrpl.AccountTransactionsRequest) (*xrpl.AccountTransactionsPage, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	page, err := s.client.GetAccountTransactions(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions of %s: %w", request.Account, err)
+	}
+	return page, nil
+}
+
+// ledgerValue renders amount as an XRPL amount value: whole drops for XRP, the exact decimal for
+// issued currencies. XRP finer than a drop cannot be represented and is rejected.
+func ledgerValue(amount models.Money) (string, error) {
+	if amount.Currency() != models.CurrencyXRP {
+		return amount.String(), nil
+	}
+	drops, err := amount.Rescale(models.CurrencyXRP.Scale())
+	if err != nil {
+		return "", fmt.Errorf("XRP amount %s is finer than a drop: %w", amount, err)
+	}
+	return drops.Units().String(), nil
+}
+
+// buildAmount converts amount into an XRPL escrow amount, resolving the issuer of non-XRP currencies
+func (s *XRPLService) buildAmount(amount models.Money) (xrpl.Amount, error) {
+	ledgerAmount, err := s.paymentAmount(amount)
+	if err != nil {
+		return xrpl.Amount{}, err
+	}
+	if !ledgerAmount.IsNative() && !s.tokenEscrowEnabled {
+		return xrpl.Amount{}, fmt.Errorf("%s escrow unavailable: %w", amount.Currency(), xrpl.ErrAmendmentDisabled)
+	}
+	return ledgerAmount, nil
+}
+
+// paymentAmount converts amount into an XRPL amount, resolving the issuer of non-XRP currencies
+func (s *XRPLService) paymentAmount(amount models.Money) (xrpl.Amount, error) {
+	value, err := ledgerValue(amount)
+	if err != nil {
+		return xrpl.Amount{}, err
+	}
+	switch currency := string(amount.Currency()); currency {
+	case "":
+		return xrpl.Amount{}, fmt.Errorf("amount %s has no currency", amount)
 	case "XRP":
-		// Convert XRP to drops (1 XRP = 1,000,000 drops)
-		drops := int64(amount * 1000000)
-		return strconv.FormatInt(drops, 10)
+		return xrpl.Amount{Value: value}, nil
 	default:
-		// For other currencies (USDT, USDC, etc.), use the amount as-is
-		// In production, this would handle currency-specific formatting
-		return fmt.Sprintf("%.6f", amount)
+		issued, ok := s.issuedAssets[currency]
+		if !ok {
+			return xrpl.Amount{}, fmt.Errorf("no XRPL issuer configured for %s", currency)
+		}
+		return issued.Amount(value)
+	}
+}
+
+// SendPayment submits a direct payment of amount between two accounts
+func (s *XRPLService) SendPayment(fromAddress, toAddress string, amount models.Money) (*xrpl.TransactionResult, error) {
+	return s.SendPaymentWithOptions(fromAddress, toAddress, amount, SubmitOptions{})
+}
+
+// SendPaymentWithOptions submits a payment with the given ticket and fee
+func (s *XRPLService) SendPaymentWithOptions(fromAddress, toAddress string, amount models.Money, options SubmitOptions) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	paymentAmount, err := s.paymentAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.SendPayment(&xrpl.Payment{
+		Account:        fromAddress,
+		Destination:    toAddress,
+		Amount:         paymentAmount,
+		TicketSequence: options.Ticket,
+		Fee:            options.Fee,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to send payment: %w", err)
+	}
+	return result, nil
+}
+
+// SubmitPayment submits a prepared payment, such as a cross-currency payment carrying SendMax and paths
+func (s *XRPLService) SubmitPayment(payment *xrpl.Payment) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.SendPayment(payment)
+	if err != nil {
+		return result, fmt.Errorf("failed to send payment: %w", err)
+	}
+	return result, nil
+}
+
+// FindPaths quotes how the ledger's order books can fund a payment
+func (s *XRPLService) FindPaths(request *xrpl.PathFindRequest) (*xrpl.PathQuote, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	quote, err := s.client.FindPaths(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payment paths: %w", err)
+	}
+	return quote, nil
+}
+
+// ledgerCurrency returns the on-ledger form of a platform currency; XRP has no issuer
+func (s *XRPLService) ledgerCurrency(currency string) (xrpl.IssuedCurrency, error) {
+	if currency == "XRP" {
+		return xrpl.IssuedCurrency{Currency: "XRP"}, nil
+	}
+
+	issued, ok := s.issuedAssets[currency]
+	if !ok {
+		return xrpl.IssuedCurrency{}, fmt.Errorf("no XRPL issuer configured for %s", currency)
+	}
+	return issued, nil
+}
+
+// platformAmount converts an XRPL amount of a platform currency back into Money: XRP for drops,
+// the exact value otherwise
+func platformAmount(amount xrpl.Amount, currency models.Currency) (models.Money, error) {
+	if amount.IsNative() {
+		drops, err := strconv.ParseInt(amount.Value, 10, 64)
+		if err != nil {
+			return models.Money{}, fmt.Errorf("invalid XRPL amount %q: %w", amount.Value, err)
+		}
+		return models.NewMoney(drops, models.CurrencyXRP.Scale(), models.CurrencyXRP), nil
+	}
+	value, err := models.ParseMoney(amount.Value, currency)
+	if err != nil {
+		return models.Money{}, fmt.Errorf("invalid XRPL amount %q: %w", amount.Value, err)
+	}
+	return value, nil
+}
+
+// WaitForValidation waits until a submitted transaction is validated or expires past lastLedgerSequence
+func (s *XRPLService) WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+	return s.client.WaitForValidation(ctx, hash, lastLedgerSequence)
+}
+
+// CheckValidation looks up a submitted transaction once, returning nil and no error while it is still pending
+func (s *XRPLService) CheckValidation(hash string, lastLedgerSequence uint32) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+	return s.client.CheckValidation(hash, lastLedgerSequence)
+}
+
+// GetNetworkFees retrieves the live open ledger transaction cost
+func (s *XRPLService) GetNetworkFees() (*xrpl.FeeInfo, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	fees, err := s.client.GetFee()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network fees: %w", err)
+	}
+	return fees, nil
+}
+
+// CreateTickets sets aside count sequence numbers of account as tickets for out-of-order submission
+func (s *XRPLService) CreateTickets(account string, count uint32) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.CreateTickets(&xrpl.TicketCreate{Account: account, TicketCount: count})
+	if err != nil {
+		return result, fmt.Errorf("failed to create tickets: %w", err)
+	}
+	return result, nil
+}
+
+// GetTickets lists the unused tickets of an account in the validated ledger
+func (s *XRPLService) GetTickets(account string) ([]uint32, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	tickets, err := s.client.GetTickets(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets for %s: %w", account, err)
+	}
+	return tickets, nil
+}
+
+// CreatePaymentChannel opens an XRP payment channel; its ID follows from the result's Sequence
+func (s *XRPLService) CreatePaymentChannel(channel *xrpl.PaymentChannelCreate) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.CreatePaymentChannel(channel)
+	if err != nil {
+		return result, fmt.Errorf("failed to create payment channel: %w", err)
+	}
+	return result, nil
+}
+
+// FundPaymentChannel adds XRP to a payment channel or moves its expiration
+func (s *XRPLService) FundPaymentChannel(fund *xrpl.PaymentChannelFund) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.FundPaymentChannel(fund)
+	if err != nil {
+		return result, fmt.Errorf("failed to fund payment channel %s: %w", fund.Channel, err)
+	}
+	return result, nil
+}
+
+// ClaimPaymentChannel redeems a signed claim against a payment channel, or renews or closes it
+func (s *XRPLService) ClaimPaymentChannel(claim *xrpl.PaymentChannelClaim) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.ClaimPaymentChannel(claim)
+	if err != nil {
+		return result, fmt.Errorf("failed to claim payment channel %s: %w", claim.Channel, err)
+	}
+	return result, nil
+}
+
+// GetPaymentChannel looks up a payment channel in the validated ledger
+func (s *XRPLService) GetPaymentChannel(channelID string) (*xrpl.PaymentChannel, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	channel, err := s.client.GetPaymentChannel(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment channel %s: %w", channelID, err)
+	}
+	return channel, nil
+}
+
+// ListPaymentChannels lists the open payment channels from account, optionally only those to destination
+func (s *XRPLService) ListPaymentChannels(account, destination string) ([]xrpl.PaymentChannel, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	channels, err := s.client.ListPaymentChannels(account, destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment channels of %s: %w", account, err)
+	}
+	return channels, nil
+}
+
+// SetTrustLine creates or updates a trust line from account to the issuer of limit
+func (s *XRPLService) SetTrustLine(account string, limit xrpl.Amount, flags uint32) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.SetTrustLine(&xrpl.TrustSet{Account: account, LimitAmount: limit, Flags: flags})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set trust line: %w", err)
+	}
+	return result, nil
+}
+
+// GetTrustLines lists the trust lines an account holds with an issuer, or all of them when issuer is empty
+func (s *XRPLService) GetTrustLines(account, issuer string) ([]xrpl.TrustLine, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	lines, err := s.client.GetTrustLines(account, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust lines for %s: %w", account, err)
+	}
+	return lines, nil
+}
+
+// SetSignerList configures the signer list that authorizes multi-signed transactions from account
+func (s *XRPLService) SetSignerList(account string, quorum uint32, entries []xrpl.SignerEntry) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+
+	result, err := s.client.SetSignerList(account, quorum, entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set signer list: %w", err)
+	}
+	return result, nil
+}
+
+// PrepareMultiSigned fills in the fields every signer of a multi-signed transaction signs over
+func (s *XRPLService) PrepareMultiSigned(tx xrpl.Transaction, signerCount int) (xrpl.Transaction, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
+	}
+	return s.client.PrepareMultiSigned(tx, signerCount)
+}
+
+// SubmitMultiSigned submits a prepared transaction with its collected signatures
+func (s *XRPLService) SubmitMultiSigned(tx xrpl.Transaction, signers []xrpl.Signer) (*xrpl.TransactionResult, error) {
+	if !s.initialized {
+		return nil, fmt.Errorf("XRPL service not initialized")
 	}
+	return s.client.SubmitMultiSigned(tx, signers)
 }
 
 // getLedgerTimeOffset calculates ledger time offset (mock implementation)
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -8,7 +11,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/pkg/xrpl"
+	"github.com/smart-payment-infrastructure/pkg/xrpl/simulator"
 )
 
 func TestNewXRPLService(t *testing.T) {
@@ -132,12 +137,15 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 	payeeWallet, err := service.CreateWallet()
 	require.NoError(t, err)
 
+	issuerWallet, err := service.CreateWallet()
+	require.NoError(t, err)
+	require.NoError(t, service.ConfigureIssuedAssets(testIssuedAssets(issuerWallet.Address)))
+
 	tests := []struct {
 		name            string
 		payerAddress    string
 		payeeAddress    string
-		amount          float64
-		currency        string
+		amount          models.Money
 		milestoneSecret string
 		expectError     bool
 	}{
@@ -145,8 +153,7 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 			name:            "valid XRP escrow",
 			payerAddress:    payerWallet.Address,
 			payeeAddress:    payeeWallet.Address,
-			amount:          10.0,
-			currency:        "XRP",
+			amount:          models.MustParseMoney("10", models.CurrencyXRP),
 			milestoneSecret: "milestone_secret_123",
 			expectError:     false,
 		},
@@ -154,17 +161,23 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 			name:            "valid USDT escrow",
 			payerAddress:    payerWallet.Address,
 			payeeAddress:    payeeWallet.Address,
-			amount:          1000.50,
-			currency:        "USDT",
+			amount:          models.MustParseMoney("1000.5", models.CurrencyUSDT),
 			milestoneSecret: "usdt_milestone_secret",
 			expectError:     false,
 		},
+		{
+			name:            "issued currency without issuer",
+			payerAddress:    payerWallet.Address,
+			payeeAddress:    payeeWallet.Address,
+			amount:          models.MustParseMoney("100", "EUR"),
+			milestoneSecret: "eur_milestone_secret",
+			expectError:     true,
+		},
 		{
 			name:            "invalid payer address",
 			payerAddress:    "invalid_address",
 			payeeAddress:    payeeWallet.Address,
-			amount:          10.0,
-			currency:        "XRP",
+			amount:          models.MustParseMoney("10", models.CurrencyXRP),
 			milestoneSecret: "secret",
 			expectError:     true,
 		},
@@ -172,8 +185,7 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 			name:            "invalid payee address",
 			payerAddress:    payerWallet.Address,
 			payeeAddress:    "invalid_address",
-			amount:          10.0,
-			currency:        "XRP",
+			amount:          models.MustParseMoney("10", models.CurrencyXRP),
 			milestoneSecret: "secret",
 			expectError:     true,
 		},
@@ -181,8 +193,7 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 			name:            "empty milestone secret",
 			payerAddress:    payerWallet.Address,
 			payeeAddress:    payeeWallet.Address,
-			amount:          10.0,
-			currency:        "XRP",
+			amount:          models.MustParseMoney("10", models.CurrencyXRP),
 			milestoneSecret: "",
 			expectError:     true,
 		},
@@ -191,7 +202,7 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result, fulfillment, err := service.CreateSmartChequeEscrow(
-				tt.payerAddress, tt.payeeAddress, tt.amount, tt.currency, tt.milestoneSecret)
+				tt.payerAddress, tt.payeeAddress, tt.amount, tt.milestoneSecret)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -209,6 +220,46 @@ func TestXRPLService_CreateSmartChequeEscrow(t *testing.T) {
 	}
 }
 
+// testIssuedAssets returns USDT and USDC assets issued by issuer
+func testIssuedAssets(issuer string) []*models.SupportedAsset {
+	return []*models.SupportedAsset{
+		{CurrencyCode: "XRP", AssetType: models.AssetTypeNative},
+		{CurrencyCode: "USDT", AssetType: models.AssetTypeStablecoin, IssuerAddress: &issuer},
+		{CurrencyCode: "USDC", AssetType: models.AssetTypeStablecoin, IssuerAddress: &issuer},
+	}
+}
+
+func TestXRPLService_IssuedCurrencyAmounts(t *testing.T) {
+	service := NewXRPLService(XRPLConfig{NetworkURL: "https://s.altnet.rippletest.net:51234", TestNet: true})
+	require.NoError(t, service.Initialize())
+	assert.True(t, service.TokenEscrowEnabled())
+
+	issuer, err := service.CreateWallet()
+	require.NoError(t, err)
+	require.NoError(t, service.ConfigureIssuedAssets(testIssuedAssets(issuer.Address)))
+
+	usdt, ok := service.IssuedCurrency("USDT")
+	require.True(t, ok)
+	assert.Equal(t, "5553445400000000000000000000000000000000", usdt.Currency)
+	_, ok = service.IssuedCurrency("XRP")
+	assert.False(t, ok)
+
+	amount, err := service.buildAmount(models.MustParseMoney("1000.50", models.CurrencyUSDT))
+	require.NoError(t, err)
+	assert.Equal(t, xrpl.Amount{Currency: usdt.Currency, Issuer: issuer.Address, Value: "1000.50"}, amount)
+
+	amount, err = service.buildAmount(models.MustParseMoney("2.5", models.CurrencyXRP))
+	require.NoError(t, err)
+	assert.Equal(t, xrpl.XRPAmount(2500000), amount)
+
+	// Networks without TokenEscrow cannot escrow issued currencies
+	service.tokenEscrowEnabled = false
+	_, err = service.buildAmount(models.MustParseMoney("1000.50", models.CurrencyUSDT))
+	assert.ErrorIs(t, err, xrpl.ErrAmendmentDisabled)
+	_, err = service.buildAmount(models.MustParseMoney("2.5", models.CurrencyXRP))
+	assert.NoError(t, err)
+}
+
 func TestXRPLService_CompleteSmartChequeMilestone(t *testing.T) {
 	config := XRPLConfig{
 		NetworkURL: "https://s.altnet.rippletest.net:51234",
@@ -229,7 +280,7 @@ func TestXRPLService_CompleteSmartChequeMilestone(t *testing.T) {
 	// First, create an escrow
 	milestoneSecret := "milestone_completion_secret"
 	escrowResult, fulfillment, err := service.CreateSmartChequeEscrow(
-		payerWallet.Address, payeeWallet.Address, 50.0, "XRP", milestoneSecret)
+		payerWallet.Address, payeeWallet.Address, models.MustParseMoney("50", models.CurrencyXRP), milestoneSecret)
 	require.NoError(t, err)
 	require.NotNil(t, escrowResult)
 
@@ -412,46 +463,44 @@ func TestXRPLService_GetEscrowStatus(t *testing.T) {
 	}
 }
 
-func TestXRPLService_FormatAmount(t *testing.T) {
-	config := XRPLConfig{
-		NetworkURL: "https://s.altnet.rippletest.net:51234",
-		TestNet:    true,
-	}
-
-	service := NewXRPLService(config)
-
+func TestLedgerValue(t *testing.T) {
 	tests := []struct {
 		name     string
-		amount   float64
-		currency string
+		amount   models.Money
 		expected string
 	}{
 		{
 			name:     "XRP amount",
-			amount:   10.5,
-			currency: "XRP",
+			amount:   models.MustParseMoney("10.5", models.CurrencyXRP),
 			expected: "10500000", // 10.5 * 1,000,000 drops
 		},
+		{
+			name:     "XRP amount a float would truncate",
+			amount:   models.MustParseMoney("0.29", models.CurrencyXRP),
+			expected: "290000",
+		},
 		{
 			name:     "USDT amount",
-			amount:   1000.123456,
-			currency: "USDT",
+			amount:   models.MustParseMoney("1000.123456", models.CurrencyUSDT),
 			expected: "1000.123456",
 		},
 		{
 			name:     "USDC amount",
-			amount:   500.0,
-			currency: "USDC",
+			amount:   models.MustParseMoney("500.000000", models.CurrencyUSDC),
 			expected: "500.000000",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := service.formatAmount(tt.amount, tt.currency)
+			result, err := ledgerValue(tt.amount)
+			require.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
+
+	_, err := ledgerValue(models.MustParseMoney("0.0000001", models.CurrencyXRP))
+	assert.ErrorIs(t, err, models.ErrMoneyPrecision)
 }
 
 func TestXRPLService_EscrowIntegrationFlow(t *testing.T) {
@@ -475,7 +524,7 @@ func TestXRPLService_EscrowIntegrationFlow(t *testing.T) {
 	// Step 2: Create Smart Check escrow
 	milestoneSecret := "integration_test_secret_12345"
 	escrowResult, fulfillment, err := service.CreateSmartChequeEscrow(
-		payerWallet.Address, payeeWallet.Address, 25.0, "XRP", milestoneSecret)
+		payerWallet.Address, payeeWallet.Address, models.MustParseMoney("25", models.CurrencyXRP), milestoneSecret)
 	require.NoError(t, err)
 	require.NotNil(t, escrowResult)
 	require.NotEmpty(t, fulfillment)
@@ -592,7 +641,7 @@ func TestXRPLService_DisputeResolutionTypes(t *testing.T) {
 		escrowInfo := &xrpl.EscrowInfo{
 			Account:     "test-account",
 			Destination: "test-destination",
-			Amount:      "1000",
+			Amount:      xrpl.Amount{Value: "1000"},
 			Sequence:    123,
 		}
 
@@ -606,3 +655,81 @@ func TestXRPLService_DisputeResolutionTypes(t *testing.T) {
 		assert.Contains(t, err.Error(), "XRPL service not initialized")
 	}
 }
+
+// newSimulatedXRPLService connects an XRPL service to an in-process simulated ledger
+func newSimulatedXRPLService(t *testing.T, keys approverKeys) (*XRPLService, *simulator.Ledger) {
+	t.Helper()
+	return newSimulatedXRPLServiceWithConfig(t, keys, simulator.Config{})
+}
+
+// newSimulatedXRPLServiceWithConfig connects an XRPL service to a simulated ledger built from config
+func newSimulatedXRPLServiceWithConfig(t *testing.T, keys approverKeys, config simulator.Config) (*XRPLService, *simulator.Ledger) {
+	t.Helper()
+	ledger := simulator.New(config)
+	server := httptest.NewServer(ledger.Handler())
+	t.Cleanup(server.Close)
+
+	service := NewXRPLService(XRPLConfig{NetworkURL: server.URL, TestNet: true, Mode: string(xrpl.ModeJSONRPC), PollInterval: 10 * time.Millisecond})
+	service.SetKeyProvider(keys)
+	require.NoError(t, service.Initialize())
+	return service, ledger
+}
+
+func TestXRPLService_EscrowLifecycleOnSimulatedLedger(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	service, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 100000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	created, fulfillment, err := service.CreateSmartChequeEscrow(payer.Address(), payee.Address(), models.MustParseMoney("25", models.CurrencyXRP), "milestone-secret")
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	escrow, err := service.GetEscrowStatus(payer.Address(), strconv.FormatUint(uint64(created.Sequence), 10))
+	require.NoError(t, err)
+	assert.Equal(t, "25000000", escrow.Amount.Value)
+	condition, err := xrpl.ConditionFromFulfillment(fulfillment)
+	require.NoError(t, err)
+
+	// FinishAfter is an hour away, so the ledger refuses an early release
+	_, err = service.CompleteSmartChequeMilestone(payee.Address(), payer.Address(), created.Sequence, condition, fulfillment)
+	var txErr *xrpl.TransactionError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, "tecNO_PERMISSION", txErr.Code)
+	ledger.CloseLedger()
+
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	finished, err := service.CompleteSmartChequeMilestone(payee.Address(), payer.Address(), created.Sequence, condition, fulfillment)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	validated, err := service.WaitForValidation(context.Background(), finished.TransactionID, finished.LastLedgerSequence)
+	require.NoError(t, err)
+	assert.Equal(t, "tesSUCCESS", validated.ResultCode)
+	_, err = service.GetEscrowStatus(payer.Address(), strconv.FormatUint(uint64(created.Sequence), 10))
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+	balance, _ := ledger.Balance(payee.Address())
+	assert.Greater(t, balance, int64(44000000))
+}
+
+func TestXRPLService_EscrowReservePreflight(t *testing.T) {
+	payer, payee := newTestKeyPair(t), newTestKeyPair(t)
+	service, ledger := newSimulatedXRPLService(t, approverKeys{payer.Address(): payer, payee.Address(): payee})
+	require.NoError(t, ledger.Fund(payer.Address(), 30000000))
+	require.NoError(t, ledger.Fund(payee.Address(), 20000000))
+
+	// 30 XRP less the 10 XRP base reserve and the 2 XRP the escrow adds leaves 18 XRP to lock up
+	_, _, err := service.CreateSmartChequeEscrow(payer.Address(), payee.Address(), models.MustParseMoney("19", models.CurrencyXRP), "milestone-secret")
+	assert.ErrorIs(t, err, xrpl.ErrInsufficientReserve)
+
+	_, _, err = service.CreateSmartChequeEscrow(payer.Address(), payee.Address(), models.MustParseMoney("17", models.CurrencyXRP), "milestone-secret")
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	reserve, err := service.GetAccountReserve(payer.Address())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), reserve.OwnerCount)
+	assert.Equal(t, int64(12000000), reserve.ReserveDrops)
+	assert.Less(t, reserve.Spendable(), int64(1000000))
+}
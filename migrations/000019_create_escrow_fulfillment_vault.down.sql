@@ -0,0 +1,4 @@
+-- Drop escrow fulfillment vault tables
+DROP TRIGGER IF EXISTS update_escrow_fulfillments_updated_at ON escrow_fulfillments;
+DROP TABLE IF EXISTS escrow_fulfillment_access_logs;
+DROP TABLE IF EXISTS escrow_fulfillments;
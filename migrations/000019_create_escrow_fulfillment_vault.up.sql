@@ -0,0 +1,36 @@
+-- Sealed escrow fulfillments; the preimage is stored encrypted only
+CREATE TABLE escrow_fulfillments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    smart_cheque_id VARCHAR(255) NOT NULL,
+    milestone_id VARCHAR(255) NOT NULL,
+    condition TEXT NOT NULL,
+    encrypted_fulfillment TEXT NOT NULL,
+    escrow_owner VARCHAR(35) NOT NULL,
+    escrow_destination VARCHAR(35) NOT NULL,
+    offer_sequence BIGINT NOT NULL,
+    release_count INTEGER NOT NULL DEFAULT 0,
+    last_released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_escrow_fulfillments_cheque_milestone UNIQUE (smart_cheque_id, milestone_id)
+);
+
+-- Audit trail of every vault access, including denied releases
+CREATE TABLE escrow_fulfillment_access_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    smart_cheque_id VARCHAR(255) NOT NULL,
+    milestone_id VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('seal', 'lookup', 'release')),
+    actor VARCHAR(100) NOT NULL,
+    success BOOLEAN NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_escrow_fulfillments_smart_cheque_id ON escrow_fulfillments(smart_cheque_id);
+CREATE INDEX idx_escrow_fulfillment_access_logs_cheque_milestone ON escrow_fulfillment_access_logs(smart_cheque_id, milestone_id, created_at DESC);
+
+CREATE TRIGGER update_escrow_fulfillments_updated_at
+    BEFORE UPDATE ON escrow_fulfillments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS result_code;
+ALTER TABLE transactions DROP COLUMN IF EXISTS last_ledger_sequence;
@@ -0,0 +1,4 @@
+-- Track the ledger outcome of queued transactions: the last ledger a submission
+-- can be included in and the engine result it was validated or rejected with
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS last_ledger_sequence INTEGER;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS result_code VARCHAR(32);
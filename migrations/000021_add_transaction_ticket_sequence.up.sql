@@ -0,0 +1,3 @@
+-- Record the XRPL ticket a queued transaction is submitted with, so it is
+-- reused on retry and returned to the pool when the transaction fails
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS ticket_sequence INTEGER;
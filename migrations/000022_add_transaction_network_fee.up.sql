@@ -0,0 +1,3 @@
+-- Record the open ledger cost a queued transaction was priced against, so
+-- fee efficiency compares what was paid with what the ledger required
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS network_fee VARCHAR(255);
@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS delivered_currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS delivered_amount;
+ALTER TABLE smart_cheques DROP COLUMN IF EXISTS settlement_currency;
@@ -0,0 +1,6 @@
+-- Cross-currency settlement: the currency a cheque's payee is paid in, and the
+-- amount and rate a converting payout actually delivered on the ledger
+ALTER TABLE smart_cheques ADD COLUMN IF NOT EXISTS settlement_currency VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS delivered_amount VARCHAR(255);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS delivered_currency VARCHAR(10);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS exchange_rate VARCHAR(255);
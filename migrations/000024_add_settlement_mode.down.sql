@@ -0,0 +1,2 @@
+ALTER TABLE smart_cheques DROP COLUMN IF EXISTS check_id;
+ALTER TABLE smart_cheques DROP COLUMN IF EXISTS settlement_mode;
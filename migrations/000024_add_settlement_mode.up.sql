@@ -0,0 +1,4 @@
+-- Settlement modes: a cheque settles through an escrow (the default), a native XRPL Check the payee
+-- cashes, or a direct payment; check_id is the ledger ID of the Check an xrpl_check cheque was issued as
+ALTER TABLE smart_cheques ADD COLUMN IF NOT EXISTS settlement_mode VARCHAR(20) NOT NULL DEFAULT 'escrow';
+ALTER TABLE smart_cheques ADD COLUMN IF NOT EXISTS check_id VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+UPDATE wallets SET status = 'deactivated' WHERE status = 'retired';
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check CHECK (status IN ('pending', 'active', 'suspended', 'deactivated'));
@@ -0,0 +1,3 @@
+-- Retired wallets have had their XRPL account deleted and their XRP swept back to treasury
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check CHECK (status IN ('pending', 'active', 'suspended', 'deactivated', 'retired'));
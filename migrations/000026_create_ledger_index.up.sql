@@ -0,0 +1,34 @@
+-- Validated XRPL transactions that affected managed accounts, backfilled from account_tx so
+-- reconciliation and audit see transactions made outside the platform too
+CREATE TABLE ledger_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account VARCHAR(35) NOT NULL,
+    transaction_hash VARCHAR(64) NOT NULL,
+    ledger_index BIGINT NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    sender VARCHAR(35) NOT NULL,
+    destination VARCHAR(35) NOT NULL DEFAULT '',
+    sequence BIGINT NOT NULL DEFAULT 0,
+    amount_value VARCHAR(64) NOT NULL DEFAULT '',
+    amount_currency VARCHAR(40) NOT NULL DEFAULT '',
+    amount_issuer VARCHAR(35) NOT NULL DEFAULT '',
+    delivered_value VARCHAR(64) NOT NULL DEFAULT '',
+    delivered_currency VARCHAR(40) NOT NULL DEFAULT '',
+    delivered_issuer VARCHAR(35) NOT NULL DEFAULT '',
+    partial_payment BOOLEAN NOT NULL DEFAULT false,
+    fee_drops BIGINT NOT NULL DEFAULT 0,
+    result_code VARCHAR(32) NOT NULL,
+    ledger_time TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_ledger_transactions_account_hash UNIQUE (account, transaction_hash)
+);
+
+-- The last validated ledger indexed for each account
+CREATE TABLE ledger_checkpoints (
+    account VARCHAR(35) PRIMARY KEY,
+    ledger_index BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_transactions_account_ledger ON ledger_transactions(account, ledger_index);
+CREATE INDEX idx_ledger_transactions_hash ON ledger_transactions(transaction_hash);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quarantined_deposits;
+DROP TABLE IF EXISTS destination_tags;
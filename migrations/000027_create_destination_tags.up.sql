@@ -0,0 +1,34 @@
+-- Destination tags allocated on shared receiving wallets; deposits carrying a tag are credited to
+-- the enterprise it was allocated to
+CREATE TABLE destination_tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_address VARCHAR(35) NOT NULL,
+    tag BIGINT NOT NULL CHECK (tag > 0 AND tag <= 4294967295),
+    enterprise_id UUID NOT NULL REFERENCES enterprises(id) ON DELETE CASCADE,
+    smart_cheque_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_destination_tags_wallet_tag UNIQUE (wallet_address, tag)
+);
+
+-- One enterprise-wide tag per enterprise and wallet, and one tag per smart cheque
+CREATE UNIQUE INDEX uq_destination_tags_enterprise ON destination_tags(wallet_address, enterprise_id) WHERE smart_cheque_id IS NULL;
+CREATE UNIQUE INDEX uq_destination_tags_smart_cheque ON destination_tags(smart_cheque_id) WHERE smart_cheque_id IS NOT NULL;
+
+-- Deposits into shared receiving wallets that no tag routes, held until released to an enterprise
+CREATE TABLE quarantined_deposits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_address VARCHAR(35) NOT NULL,
+    transaction_hash VARCHAR(64) NOT NULL UNIQUE,
+    sender VARCHAR(35) NOT NULL,
+    destination_tag BIGINT,
+    delivered_value VARCHAR(64) NOT NULL,
+    delivered_currency VARCHAR(40) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'released')),
+    enterprise_id UUID REFERENCES enterprises(id),
+    asset_transaction_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_quarantined_deposits_status ON quarantined_deposits(status, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS smart_cheque_status_transitions;
+ALTER TABLE smart_cheques DROP CONSTRAINT IF EXISTS smart_cheques_status_check;
+ALTER TABLE smart_cheques ADD CONSTRAINT smart_cheques_status_check
+    CHECK (status IN ('created', 'locked', 'in_progress', 'completed', 'disputed'));
@@ -0,0 +1,20 @@
+-- Smart cheques can now be partially paid, cancelled, expired, refunded or frozen
+ALTER TABLE smart_cheques DROP CONSTRAINT IF EXISTS status_check;
+ALTER TABLE smart_cheques DROP CONSTRAINT IF EXISTS smart_cheques_status_check;
+ALTER TABLE smart_cheques ADD CONSTRAINT smart_cheques_status_check CHECK (status IN (
+    'created', 'locked', 'in_progress', 'completed', 'disputed',
+    'partially_paid', 'cancelled', 'expired', 'refunded', 'frozen'
+));
+
+-- Every status a smart cheque moved through, with why it moved
+CREATE TABLE smart_cheque_status_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    smart_cheque_id UUID NOT NULL REFERENCES smart_cheques(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_smart_cheque_status_transitions_cheque ON smart_cheque_status_transitions(smart_cheque_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS smart_cheque_endorsements;
+ALTER TABLE contracts DROP COLUMN IF EXISTS assignment_requires_consent;
@@ -0,0 +1,25 @@
+-- Contracts can require the payer to acknowledge an assignment of the payee's proceeds
+ALTER TABLE contracts ADD COLUMN assignment_requires_consent BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The chain of endorsements assigning smart cheque proceeds from one enterprise to another
+CREATE TABLE smart_cheque_endorsements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    smart_cheque_id UUID NOT NULL REFERENCES smart_cheques(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    endorser_id UUID NOT NULL,
+    endorsee_id UUID NOT NULL,
+    endorsee_address VARCHAR(64) NOT NULL,
+    milestone_ids TEXT[] NOT NULL,
+    amount DECIMAL(20, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(30) NOT NULL CHECK (status IN ('pending_acknowledgement', 'effective', 'declined')),
+    requires_acknowledgement BOOLEAN NOT NULL DEFAULT FALSE,
+    acknowledged_by UUID,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (smart_cheque_id, sequence)
+);
+
+CREATE INDEX idx_smart_cheque_endorsements_endorsee ON smart_cheque_endorsements(endorsee_id);
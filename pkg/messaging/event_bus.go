@@ -77,6 +77,8 @@ const (
 	EventTypeEnterpriseKYBUpdated     = "enterprise.kyb_updated"
 	EventTypeSmartChequeCreated       = "smart_cheque.created"
 	EventTypeSmartChequeLocked        = "smart_cheque.locked"
+	EventTypeSmartChequeStatusChanged = "smart_cheque.status_changed"
+	EventTypeSmartChequeEndorsed      = "smart_cheque.endorsed"
 	EventTypeMilestoneCompleted       = "milestone.completed"
 	EventTypeMilestoneVerified        = "milestone.verified"
 	EventTypePaymentReleased          = "payment.released"
@@ -98,7 +100,8 @@ func NewEnterpriseRegisteredEvent(enterpriseID, legalName string) *Event {
 	}
 }
 
-func NewSmartChequeCreatedEvent(chequeID, payerID, payeeID string, amount float64, currency string) *Event {
+// NewSmartChequeCreatedEvent carries amount as the exact decimal string, e.g. "1000.000000"
+func NewSmartChequeCreatedEvent(chequeID, payerID, payeeID, amount, currency string) *Event {
 	return &Event{
 		Type:   EventTypeSmartChequeCreated,
 		Source: "orchestration-service",
@@ -112,7 +115,40 @@ func NewSmartChequeCreatedEvent(chequeID, payerID, payeeID string, amount float6
 	}
 }
 
-func NewMilestoneCompletedEvent(milestoneID, smartChequeID string, amount float64) *Event {
+func NewSmartChequeStatusChangedEvent(chequeID, payerID, payeeID, fromStatus, toStatus, reason string) *Event {
+	return &Event{
+		Type:   EventTypeSmartChequeStatusChanged,
+		Source: "orchestration-service",
+		Data: map[string]interface{}{
+			"check_id":    chequeID,
+			"payer_id":    payerID,
+			"payee_id":    payeeID,
+			"from_status": fromStatus,
+			"to_status":   toStatus,
+			"reason":      reason,
+		},
+	}
+}
+
+// NewSmartChequeEndorsedEvent announces an endorsement of a smart cheque's proceeds, either waiting
+// for the payer's acknowledgement or already effective
+func NewSmartChequeEndorsedEvent(chequeID, payerID, endorserID, endorseeID, status string, milestoneIDs []string) *Event {
+	return &Event{
+		Type:   EventTypeSmartChequeEndorsed,
+		Source: "orchestration-service",
+		Data: map[string]interface{}{
+			"check_id":      chequeID,
+			"payer_id":      payerID,
+			"endorser_id":   endorserID,
+			"endorsee_id":   endorseeID,
+			"status":        status,
+			"milestone_ids": milestoneIDs,
+		},
+	}
+}
+
+// NewMilestoneCompletedEvent carries amount as the exact decimal string, e.g. "250.000000"
+func NewMilestoneCompletedEvent(milestoneID, smartChequeID, amount string) *Event {
 	return &Event{
 		Type:   EventTypeMilestoneCompleted,
 		Source: "orchestration-service",
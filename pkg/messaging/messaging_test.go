@@ -51,15 +51,15 @@ func TestEventCreation(t *testing.T) {
 }
 
 func TestSmartChequeEventCreation(t *testing.T) {
-	event := NewSmartChequeCreatedEvent("sc-123", "payer-1", "payee-1", 1000.0, "USDT")
+	event := NewSmartChequeCreatedEvent("sc-123", "payer-1", "payee-1", "1000.000000", "USDT")
 
 	if event.Type != EventTypeSmartChequeCreated {
 		t.Errorf("Expected event type '%s', got '%s'", EventTypeSmartChequeCreated, event.Type)
 	}
 
-	amount, ok := event.Data["amount"].(float64)
-	if !ok || amount != 1000.0 {
-		t.Errorf("Expected amount 1000.0, got '%v'", amount)
+	amount, ok := event.Data["amount"].(string)
+	if !ok || amount != "1000.000000" {
+		t.Errorf("Expected amount '1000.000000', got '%v'", amount)
 	}
 
 	currency, ok := event.Data["currency"].(string)
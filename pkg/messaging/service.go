@@ -32,6 +32,14 @@ func NewService(redisAddr, redisPassword string, redisDB int) (*Service, error)
 	}, nil
 }
 
+// EventBus returns the event bus behind the service, for components that publish with a context
+func (s *Service) EventBus() EventBus {
+	if s == nil || s.eventBus == nil {
+		return nil
+	}
+	return s.eventBus
+}
+
 // PublishEvent publishes an event to the event bus
 func (s *Service) PublishEvent(event *Event) error {
 	// If service is nil or eventBus is nil, skip publishing
@@ -0,0 +1,76 @@
+package xrpl
+
+import (
+	"fmt"
+	"log"
+)
+
+// AccountSet flags, enabled with SetFlag and disabled with ClearFlag
+const (
+	AccountSetFlagRequireDest   uint32 = 1
+	AccountSetFlagRequireAuth   uint32 = 2
+	AccountSetFlagDisallowXRP   uint32 = 3
+	AccountSetFlagDisableMaster uint32 = 4
+	AccountSetFlagDefaultRipple uint32 = 8
+	AccountSetFlagDepositAuth   uint32 = 9
+)
+
+// AccountRoot flags reported in account_info
+const (
+	AccountFlagRequireDestTag uint32 = 0x00020000
+	AccountFlagRequireAuth    uint32 = 0x00040000
+	AccountFlagDisallowXRP    uint32 = 0x00080000
+	AccountFlagDisableMaster  uint32 = 0x00100000
+	AccountFlagDefaultRipple  uint32 = 0x00800000
+	AccountFlagDepositAuth    uint32 = 0x01000000
+)
+
+// AccountSet represents parameters for changing an account's settings
+type AccountSet struct {
+	Account   string `json:"Account"`
+	SetFlag   uint32 `json:"SetFlag,omitempty"`
+	ClearFlag uint32 `json:"ClearFlag,omitempty"`
+	Fee       string `json:"Fee,omitempty"`
+}
+
+// RequiresDestinationTag reports whether the ledger rejects payments to the account that carry no
+// destination tag
+func (i *AccountInfo) RequiresDestinationTag() bool {
+	return i.Flags&AccountFlagRequireDestTag != 0
+}
+
+// SetAccountFlags submits an AccountSet transaction enabling or disabling an account flag
+func (c *Client) SetAccountFlags(set *AccountSet) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(set.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", set.Account)
+	}
+	if set.SetFlag == 0 && set.ClearFlag == 0 {
+		return nil, fmt.Errorf("AccountSet must set or clear a flag")
+	}
+	if set.SetFlag != 0 && set.SetFlag == set.ClearFlag {
+		return nil, fmt.Errorf("cannot set and clear flag %d in the same AccountSet", set.SetFlag)
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("AccountSet", set)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Set account flags: %s, SetFlag: %d, ClearFlag: %d, TxID: %s", set.Account, set.SetFlag, set.ClearFlag, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
@@ -0,0 +1,54 @@
+package xrpl
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Amendments the platform gates features on
+const (
+	AmendmentTokenEscrow = "TokenEscrow"
+)
+
+// amendmentsIndex is the ledger index of the singleton Amendments entry
+const amendmentsIndex = "7DB0788C020F02780A673DC74757F23823FA3014C1866E72CC4CD8B226CD6EF4"
+
+// AmendmentID returns the ID of a named amendment, the SHA-512Half of its name
+func AmendmentID(name string) string {
+	return strings.ToUpper(hex.EncodeToString(sha512Half([]byte(name))))
+}
+
+// AmendmentEnabled reports whether an amendment is enabled in the latest validated ledger.
+// The simulator behaves as a network with every amendment enabled.
+func (c *Client) AmendmentEnabled(name string) (bool, error) {
+	if c.simulated() {
+		return true, nil
+	}
+
+	var result struct {
+		Node struct {
+			Amendments []string `json:"Amendments"`
+		} `json:"node"`
+	}
+	params := map[string]interface{}{
+		"index":        amendmentsIndex,
+		"ledger_index": "validated",
+	}
+	if err := c.call("ledger_entry", params, &result); err != nil {
+		// A ledger without the Amendments entry has no amendments enabled
+		if errors.Is(err, ErrEntryNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read enabled amendments: %w", err)
+	}
+
+	id := AmendmentID(name)
+	for _, enabled := range result.Node.Amendments {
+		if strings.EqualFold(enabled, id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,140 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	currencyCodeLength    = 20
+	standardCurrencyChars = 3
+)
+
+// Amount is an XRPL amount: XRP in drops when Currency is empty, otherwise an
+// issued-currency amount of Value units of Currency issued by Issuer
+type Amount struct {
+	Currency string `json:"currency"`
+	Issuer   string `json:"issuer,omitempty"`
+	Value    string `json:"value"`
+}
+
+// IssuedCurrency identifies a token on the ledger by its currency code and issuer
+type IssuedCurrency struct {
+	Currency string `json:"currency"`
+	Issuer   string `json:"issuer"`
+}
+
+// XRPAmount returns a native XRP amount of the given drops
+func XRPAmount(drops int64) Amount {
+	return Amount{Value: strconv.FormatInt(drops, 10)}
+}
+
+// NewIssuedCurrency validates an issuer and normalizes the currency into its ledger form
+func NewIssuedCurrency(currency, issuer string) (IssuedCurrency, error) {
+	code, err := CurrencyCode(currency)
+	if err != nil {
+		return IssuedCurrency{}, err
+	}
+	if _, err := DecodeAccountID(issuer); err != nil {
+		return IssuedCurrency{}, fmt.Errorf("invalid issuer %s for %s: %w", issuer, currency, err)
+	}
+	return IssuedCurrency{Currency: code, Issuer: issuer}, nil
+}
+
+// Amount returns an amount of this currency, validating that value fits the ledger's decimal format
+func (c IssuedCurrency) Amount(value string) (Amount, error) {
+	if _, err := encodeIssuedValue(value); err != nil {
+		return Amount{}, fmt.Errorf("invalid %s amount: %w", CurrencyName(c.Currency), err)
+	}
+	return Amount{Currency: c.Currency, Issuer: c.Issuer, Value: value}, nil
+}
+
+// IsNative reports whether the amount is XRP
+func (a Amount) IsNative() bool {
+	return a.Currency == ""
+}
+
+// String formats the amount for logs: drops for XRP, value, currency and issuer otherwise
+func (a Amount) String() string {
+	if a.IsNative() {
+		return a.Value
+	}
+	return fmt.Sprintf("%s %s/%s", a.Value, CurrencyName(a.Currency), a.Issuer)
+}
+
+// MarshalJSON encodes XRP as a drops string and issued currencies as an amount object
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.IsNative() {
+		return json.Marshal(a.Value)
+	}
+	type issued Amount
+	return json.Marshal(issued(a))
+}
+
+// UnmarshalJSON accepts either a drops string or an issued-currency amount object
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var drops string
+	if err := json.Unmarshal(data, &drops); err == nil {
+		*a = Amount{Value: drops}
+		return nil
+	}
+
+	type issued Amount
+	var object issued
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+	*a = Amount(object)
+	return nil
+}
+
+// CurrencyCode returns the ledger form of a currency code. Three-character codes are standard
+// codes; longer names such as USDT are encoded as 160-bit non-standard codes in hex.
+func CurrencyCode(currency string) (string, error) {
+	switch {
+	case len(currency) == standardCurrencyChars:
+		if currency == "XRP" {
+			return "", fmt.Errorf("XRP cannot be used as an issued currency code")
+		}
+		return currency, nil
+	case len(currency) == 2*currencyCodeLength && isHex(currency):
+		if _, err := EncodeCurrencyCode(strings.ToUpper(currency)); err != nil {
+			return "", err
+		}
+		return strings.ToUpper(currency), nil
+	case len(currency) > 0 && len(currency) <= currencyCodeLength:
+		code := make([]byte, currencyCodeLength)
+		copy(code, currency)
+		return strings.ToUpper(hex.EncodeToString(code)), nil
+	default:
+		return "", fmt.Errorf("invalid currency code: %q", currency)
+	}
+}
+
+// CurrencyName returns the readable name of a ledger currency code, decoding non-standard codes
+// that hold padded text
+func CurrencyName(code string) string {
+	if len(code) != 2*currencyCodeLength {
+		return code
+	}
+	raw, err := hex.DecodeString(code)
+	if err != nil || raw[0] == 0x00 {
+		return code
+	}
+
+	name := bytes.TrimRight(raw, "\x00")
+	if !utf8.Valid(name) || bytes.IndexByte(name, 0x00) >= 0 {
+		return code
+	}
+	return string(name)
+}
+
+func isHex(value string) bool {
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
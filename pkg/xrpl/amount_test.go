@@ -0,0 +1,171 @@
+package xrpl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyCode(t *testing.T) {
+	tests := []struct {
+		currency string
+		expected string
+	}{
+		{"USD", "USD"},
+		{"USDT", "5553445400000000000000000000000000000000"},
+		{"USDC", "5553444300000000000000000000000000000000"},
+		{"0158415500000000c1f76ff6ecb0bac600000000", "0158415500000000C1F76FF6ECB0BAC600000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			code, err := CurrencyCode(tt.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+
+	for _, invalid := range []string{"", "XRP", "ABCDEFGHIJKLMNOPQRSTUVWXYZ", "0000000000000000000000005852500000000000"} {
+		_, err := CurrencyCode(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	assert.Equal(t, "USDT", CurrencyName("5553445400000000000000000000000000000000"))
+	assert.Equal(t, "USD", CurrencyName("USD"))
+	assert.Equal(t, "0158415500000000C1F76FF6ECB0BAC600000000", CurrencyName("0158415500000000C1F76FF6ECB0BAC600000000"))
+}
+
+func TestAmount_JSON(t *testing.T) {
+	usdt, err := NewIssuedCurrency("USDT", genesisAccount)
+	require.NoError(t, err)
+	issued, err := usdt.Amount("1000.75")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(issued)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"currency":"5553445400000000000000000000000000000000","issuer":"`+genesisAccount+`","value":"1000.75"}`, string(data))
+
+	data, err = json.Marshal(XRPAmount(2500000))
+	require.NoError(t, err)
+	assert.Equal(t, `"2500000"`, string(data))
+
+	for _, amount := range []Amount{issued, XRPAmount(2500000)} {
+		data, err := json.Marshal(amount)
+		require.NoError(t, err)
+		var decoded Amount
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, amount, decoded)
+	}
+
+	_, err = usdt.Amount("not-a-number")
+	assert.Error(t, err)
+	_, err = NewIssuedCurrency("USDT", "rInvalid")
+	assert.Error(t, err)
+}
+
+func TestTrustSet_Encoding(t *testing.T) {
+	usdt, err := NewIssuedCurrency("USDT", genesisAccount)
+	require.NoError(t, err)
+	limit, err := usdt.Amount("1000000")
+	require.NoError(t, err)
+
+	tx, err := TransactionFromStruct("TrustSet", &TrustSet{Account: testAccount, LimitAmount: limit, Flags: TrustSetFlagSetNoRipple})
+	require.NoError(t, err)
+	tx["Fee"] = "12"
+	tx["Sequence"] = uint32(5)
+
+	encoded, err := EncodeTransaction(tx)
+	require.NoError(t, err)
+	currency, err := EncodeCurrencyCode(usdt.Currency)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), string(currency))
+
+	// Escrows carry issued amounts through the same codec path
+	escrow, err := TransactionFromStruct("EscrowCreate", &EscrowCreate{Account: genesisAccount, Destination: testAccount, Amount: limit, CancelAfter: 800086400})
+	require.NoError(t, err)
+	escrow["Fee"] = "12"
+	escrow["Sequence"] = uint32(6)
+	_, err = EncodeTransaction(escrow)
+	require.NoError(t, err)
+}
+
+func TestClient_SetTrustLineValidation(t *testing.T) {
+	client := NewClient("https://s.altnet.rippletest.net:51234", true)
+	require.NoError(t, client.Connect())
+
+	usdt, err := NewIssuedCurrency("USDT", genesisAccount)
+	require.NoError(t, err)
+	limit, err := usdt.Amount("1000")
+	require.NoError(t, err)
+
+	result, err := client.SetTrustLine(&TrustSet{Account: testAccount, LimitAmount: limit})
+	require.NoError(t, err)
+	assert.Equal(t, "tesSUCCESS", result.ResultCode)
+
+	_, err = client.SetTrustLine(&TrustSet{Account: testAccount, LimitAmount: XRPAmount(1000)})
+	assert.Error(t, err)
+	_, err = client.SetTrustLine(&TrustSet{Account: genesisAccount, LimitAmount: limit})
+	assert.Error(t, err)
+	_, err = client.SetTrustLine(&TrustSet{Account: testAccount, LimitAmount: limit, Flags: TrustSetFlagSetFreeze | TrustSetFlagClearFreeze})
+	assert.Error(t, err)
+}
+
+func TestJSONRPC_GetTrustLines(t *testing.T) {
+	server, requests := newTestRippled(t, map[string]interface{}{
+		"account_lines": map[string]interface{}{
+			"status":  "success",
+			"account": testAccount,
+			"lines": []interface{}{map[string]interface{}{
+				"account":   genesisAccount,
+				"balance":   "25.5",
+				"currency":  "5553445400000000000000000000000000000000",
+				"limit":     "1000",
+				"no_ripple": true,
+				"freeze":    true,
+			}},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	lines, err := client.GetTrustLines(testAccount, genesisAccount)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, genesisAccount, lines[0].Peer)
+	assert.Equal(t, "1000", lines[0].Limit)
+	assert.True(t, lines[0].NoRipple)
+	assert.True(t, lines[0].Freeze)
+
+	params := (*requests)[0].Params[0].(map[string]interface{})
+	assert.Equal(t, genesisAccount, params["peer"])
+	assert.Equal(t, "validated", params["ledger_index"])
+}
+
+func TestJSONRPC_AmendmentEnabled(t *testing.T) {
+	assert.Equal(t, "8CC0774A3BF66D1D22E76BBDA8E8A232E6B6313834301B3B23E8601196AE6455", AmendmentID("AMM"))
+
+	server, _ := newTestRippled(t, map[string]interface{}{
+		"ledger_entry": map[string]interface{}{
+			"status": "success",
+			"node": map[string]interface{}{
+				"LedgerEntryType": "Amendments",
+				"Amendments":      []interface{}{AmendmentID("AMM")},
+			},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	enabled, err := client.AmendmentEnabled("AMM")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = client.AmendmentEnabled(AmendmentTokenEscrow)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	simulator := NewClient("https://s.altnet.rippletest.net:51234", true)
+	enabled, err = simulator.AmendmentEnabled(AmendmentTokenEscrow)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
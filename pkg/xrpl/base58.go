@@ -0,0 +1,118 @@
+package xrpl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// xrplAlphabet is the base58 dictionary used by the XRP Ledger
+const xrplAlphabet = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+
+// ErrInvalidChecksum is returned when a base58check payload fails verification
+var ErrInvalidChecksum = errors.New("invalid base58 checksum")
+
+var (
+	bigRadix = big.NewInt(58)
+	bigZero  = big.NewInt(0)
+
+	alphabetIndex = func() [256]int {
+		var index [256]int
+		for i := range index {
+			index[i] = -1
+		}
+		for i := 0; i < len(xrplAlphabet); i++ {
+			index[xrplAlphabet[i]] = i
+		}
+		return index
+	}()
+)
+
+// base58Encode encodes bytes with the XRPL base58 alphabet
+func base58Encode(input []byte) string {
+	x := new(big.Int).SetBytes(input)
+	mod := new(big.Int)
+
+	encoded := make([]byte, 0, len(input)*138/100+1)
+	for x.Cmp(bigZero) > 0 {
+		x.DivMod(x, bigRadix, mod)
+		encoded = append(encoded, xrplAlphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as the first alphabet character
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, xrplAlphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+// base58Decode decodes a string encoded with the XRPL base58 alphabet
+func base58Decode(input string) ([]byte, error) {
+	if input == "" {
+		return nil, fmt.Errorf("empty base58 string")
+	}
+
+	x := new(big.Int)
+	for i := 0; i < len(input); i++ {
+		digit := alphabetIndex[input[i]]
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", input[i])
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(digit)))
+	}
+
+	leadingZeros := 0
+	for leadingZeros < len(input) && input[leadingZeros] == xrplAlphabet[0] {
+		leadingZeros++
+	}
+
+	decoded := x.Bytes()
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
+
+// checksum returns the first four bytes of a double SHA-256 of the payload
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// encodeBase58Check prefixes the payload, appends its checksum and base58 encodes it
+func encodeBase58Check(prefix, payload []byte) string {
+	data := make([]byte, 0, len(prefix)+len(payload)+4)
+	data = append(data, prefix...)
+	data = append(data, payload...)
+	data = append(data, checksum(data)...)
+	return base58Encode(data)
+}
+
+// decodeBase58Check verifies the checksum and prefix and returns the payload
+func decodeBase58Check(input string, prefix []byte, payloadLength int) ([]byte, error) {
+	data, err := base58Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(prefix)+payloadLength+4 {
+		return nil, fmt.Errorf("unexpected decoded length %d", len(data))
+	}
+
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(checksum(body), sum) {
+		return nil, ErrInvalidChecksum
+	}
+	if !bytes.Equal(body[:len(prefix)], prefix) {
+		return nil, fmt.Errorf("unexpected version prefix %X", body[:len(prefix)])
+	}
+
+	return body[len(prefix):], nil
+}
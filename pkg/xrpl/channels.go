@@ -0,0 +1,368 @@
+package xrpl
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// PaymentChannelClaim flags
+const (
+	// PaymentChannelClaimFlagRenew clears a channel's expiration; only its source may renew it
+	PaymentChannelClaimFlagRenew = 0x00010000
+	// PaymentChannelClaimFlagClose asks to close the channel, immediately when the destination
+	// closes it or nothing is left to claim, otherwise after its settle delay
+	PaymentChannelClaimFlagClose = 0x00020000
+)
+
+// claimPrefix is the hash prefix rippled puts in front of the data of a payment channel claim
+var claimPrefix = []byte{'C', 'L', 'M', 0x00}
+
+// PaymentChannelCreate represents parameters for opening an XRP payment channel
+type PaymentChannelCreate struct {
+	Account     string `json:"Account"`
+	Destination string `json:"Destination"`
+	// Amount is the XRP set aside in the channel
+	Amount Amount `json:"Amount"`
+	// SettleDelay is how many seconds the source must wait to close a channel with unclaimed XRP
+	SettleDelay uint32 `json:"SettleDelay"`
+	// PublicKey is the key whose signatures authorize claims against the channel
+	PublicKey      string `json:"PublicKey"`
+	CancelAfter    uint32 `json:"CancelAfter,omitempty"`
+	DestinationTag uint32 `json:"DestinationTag,omitempty"`
+	SourceTag      uint32 `json:"SourceTag,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// PaymentChannelFund represents parameters for adding XRP to a channel or extending its expiration
+type PaymentChannelFund struct {
+	Account string `json:"Account"`
+	Channel string `json:"Channel"`
+	Amount  Amount `json:"Amount"`
+	// Expiration moves the channel's expiration, which may not be sooner than its settle delay
+	Expiration     uint32 `json:"Expiration,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// PaymentChannelClaim represents parameters for redeeming a signed claim or closing a channel
+type PaymentChannelClaim struct {
+	Account string `json:"Account"`
+	Channel string `json:"Channel"`
+	// Balance is the total amount the destination will have received from the channel
+	Balance *Amount `json:"Balance,omitempty"`
+	// Amount is the total amount the signature authorizes
+	Amount *Amount `json:"Amount,omitempty"`
+	// Signature is the source's claim signature, required when the destination redeems
+	Signature      string `json:"Signature,omitempty"`
+	PublicKey      string `json:"PublicKey,omitempty"`
+	Flags          uint32 `json:"Flags,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// PaymentChannel is a channel as listed by account_channels; amounts are in drops
+type PaymentChannel struct {
+	ChannelID          string `json:"channel_id"`
+	Account            string `json:"account"`
+	DestinationAccount string `json:"destination_account"`
+	Amount             string `json:"amount"`
+	Balance            string `json:"balance"`
+	PublicKey          string `json:"public_key,omitempty"`
+	PublicKeyHex       string `json:"public_key_hex,omitempty"`
+	SettleDelay        uint32 `json:"settle_delay"`
+	Expiration         uint32 `json:"expiration,omitempty"`
+	CancelAfter        uint32 `json:"cancel_after,omitempty"`
+	DestinationTag     uint32 `json:"destination_tag,omitempty"`
+	SourceTag          uint32 `json:"source_tag,omitempty"`
+}
+
+// Remaining returns the drops still claimable from the channel
+func (p *PaymentChannel) Remaining() (int64, error) {
+	amount, err := strconv.ParseInt(p.Amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid channel amount %q: %w", p.Amount, err)
+	}
+	balance, err := strconv.ParseInt(p.Balance, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid channel balance %q: %w", p.Balance, err)
+	}
+	return amount - balance, nil
+}
+
+// PaymentChannelClaimData returns the data a channel's key signs to authorize a cumulative claim of drops
+func PaymentChannelClaimData(channelID string, drops uint64) ([]byte, error) {
+	channel, err := hex.DecodeString(channelID)
+	if err != nil || len(channel) != 32 {
+		return nil, fmt.Errorf("invalid channel ID %q", channelID)
+	}
+	data := append([]byte{}, claimPrefix...)
+	data = append(data, channel...)
+	return binary.BigEndian.AppendUint64(data, drops), nil
+}
+
+// SignPaymentChannelClaim signs an off-ledger claim authorizing the channel's destination to receive
+// up to drops in total; the payer hands the signature to the payee without touching the ledger
+func SignPaymentChannelClaim(channelID string, drops uint64, keyPair *KeyPair) (string, error) {
+	data, err := PaymentChannelClaimData(channelID, drops)
+	if err != nil {
+		return "", err
+	}
+	signature, err := signData(data, keyPair)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign channel claim: %w", err)
+	}
+	return strings.ToUpper(hex.EncodeToString(signature)), nil
+}
+
+// VerifyPaymentChannelClaim checks that a claim signature over drops was made by the channel's key
+func VerifyPaymentChannelClaim(channelID string, drops uint64, signatureHex, publicKeyHex string) error {
+	data, err := PaymentChannelClaimData(channelID, drops)
+	if err != nil {
+		return err
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidClaim)
+	}
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return fmt.Errorf("%w: malformed public key", ErrInvalidClaim)
+	}
+	if !verifySignature(data, signature, publicKey) {
+		return fmt.Errorf("%w: signature does not authorize %d drops", ErrInvalidClaim, drops)
+	}
+	return nil
+}
+
+// CreatePaymentChannel opens a payment channel; the channel ID follows from the result's Sequence
+// through PaymentChannelIndex
+func (c *Client) CreatePaymentChannel(channel *PaymentChannelCreate) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(channel.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", channel.Account)
+	}
+	if !c.ValidateAddress(channel.Destination) {
+		return nil, fmt.Errorf("invalid destination address: %s", channel.Destination)
+	}
+	destination, tag, err := ResolveDestination(channel.Destination, channel.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	channel.Destination, channel.DestinationTag = destination, tag
+	if channel.Account == channel.Destination {
+		return nil, fmt.Errorf("payment channel destination must differ from its source")
+	}
+	if !channel.Amount.IsNative() {
+		return nil, fmt.Errorf("payment channels can only hold XRP")
+	}
+	if _, err := hex.DecodeString(channel.PublicKey); err != nil || len(channel.PublicKey) != 66 {
+		return nil, fmt.Errorf("invalid channel public key: %s", channel.PublicKey)
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("PaymentChannelCreate", channel)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Created payment channel: %s -> %s, Amount: %s, TxID: %s", channel.Account, channel.Destination, channel.Amount, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// FundPaymentChannel adds XRP to a channel the account opened
+func (c *Client) FundPaymentChannel(fund *PaymentChannelFund) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(fund.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", fund.Account)
+	}
+	if !fund.Amount.IsNative() {
+		return nil, fmt.Errorf("payment channels can only hold XRP")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("PaymentChannelFund", fund)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Funded payment channel %s with %s, TxID: %s", fund.Channel, fund.Amount, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// ClaimPaymentChannel redeems a claim against a channel, or renews or closes it
+func (c *Client) ClaimPaymentChannel(claim *PaymentChannelClaim) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(claim.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", claim.Account)
+	}
+	if claim.Flags&PaymentChannelClaimFlagRenew != 0 && claim.Flags&PaymentChannelClaimFlagClose != 0 {
+		return nil, fmt.Errorf("a channel claim cannot both renew and close the channel")
+	}
+
+	// Check the signature locally rather than paying the fee for a rejected claim
+	if claim.Signature != "" {
+		if claim.Balance == nil || claim.PublicKey == "" {
+			return nil, fmt.Errorf("a signed channel claim needs a Balance and PublicKey")
+		}
+		authorized := claim.Balance
+		if claim.Amount != nil {
+			authorized = claim.Amount
+		}
+		drops, err := strconv.ParseUint(authorized.Value, 10, 64)
+		if err != nil || !authorized.IsNative() {
+			return nil, fmt.Errorf("invalid channel claim amount: %s", authorized)
+		}
+		if err := VerifyPaymentChannelClaim(claim.Channel, drops, claim.Signature, claim.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("PaymentChannelClaim", claim)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Claimed payment channel %s by %s, TxID: %s", claim.Channel, claim.Account, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// ListPaymentChannels lists the open channels from account in the validated ledger, optionally only
+// those to destination, following account_channels markers until the last page
+func (c *Client) ListPaymentChannels(account, destination string) ([]PaymentChannel, error) {
+	if !c.ValidateAddress(account) {
+		return nil, fmt.Errorf("invalid account address: %s", account)
+	}
+	if destination != "" && !c.ValidateAddress(destination) {
+		return nil, fmt.Errorf("invalid destination address: %s", destination)
+	}
+
+	if c.simulated() {
+		return []PaymentChannel{}, nil
+	}
+
+	channels := []PaymentChannel{}
+	var marker interface{}
+	for {
+		var result struct {
+			Channels []PaymentChannel `json:"channels"`
+			Marker   interface{}      `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":      account,
+			"ledger_index": "validated",
+		}
+		if destination != "" {
+			params["destination_account"] = destination
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_channels", params, &result); err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, result.Channels...)
+		if result.Marker == nil {
+			return channels, nil
+		}
+		marker = result.Marker
+	}
+}
+
+// GetPaymentChannel looks up a channel by ID in the validated ledger
+func (c *Client) GetPaymentChannel(channelID string) (*PaymentChannel, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+	if _, err := PaymentChannelClaimData(channelID, 0); err != nil {
+		return nil, err
+	}
+
+	// The offline simulation keeps no ledger to look channels up in
+	if c.simulated() {
+		return nil, fmt.Errorf("%w: payment channel %s", ErrEntryNotFound, channelID)
+	}
+
+	var result struct {
+		Node struct {
+			Account        string `json:"Account"`
+			Destination    string `json:"Destination"`
+			Amount         Amount `json:"Amount"`
+			Balance        Amount `json:"Balance"`
+			PublicKey      string `json:"PublicKey"`
+			SettleDelay    uint32 `json:"SettleDelay"`
+			Expiration     uint32 `json:"Expiration,omitempty"`
+			CancelAfter    uint32 `json:"CancelAfter,omitempty"`
+			DestinationTag uint32 `json:"DestinationTag,omitempty"`
+			SourceTag      uint32 `json:"SourceTag,omitempty"`
+		} `json:"node"`
+		Index string `json:"index"`
+	}
+	params := map[string]interface{}{
+		"payment_channel": channelID,
+		"ledger_index":    "validated",
+	}
+	if err := c.call("ledger_entry", params, &result); err != nil {
+		return nil, err
+	}
+
+	node := result.Node
+	return &PaymentChannel{
+		ChannelID:          result.Index,
+		Account:            node.Account,
+		DestinationAccount: node.Destination,
+		Amount:             node.Amount.Value,
+		Balance:            node.Balance.Value,
+		PublicKeyHex:       node.PublicKey,
+		SettleDelay:        node.SettleDelay,
+		Expiration:         node.Expiration,
+		CancelAfter:        node.CancelAfter,
+		DestinationTag:     node.DestinationTag,
+		SourceTag:          node.SourceTag,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package xrpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentChannelClaimSignatures(t *testing.T) {
+	channelID, err := PaymentChannelIndex(genesisAccount, testAccount, 7)
+	require.NoError(t, err)
+
+	for _, keyType := range []KeyType{KeyTypeSecp256k1, KeyTypeEd25519} {
+		t.Run(string(keyType), func(t *testing.T) {
+			payer := newTestSigner(t, keyType)
+			signature, err := SignPaymentChannelClaim(channelID, 1500000, payer)
+			require.NoError(t, err)
+
+			require.NoError(t, VerifyPaymentChannelClaim(channelID, 1500000, signature, payer.PublicKeyHex()))
+			assert.ErrorIs(t, VerifyPaymentChannelClaim(channelID, 2500000, signature, payer.PublicKeyHex()), ErrInvalidClaim, "a claim cannot be raised")
+			assert.ErrorIs(t, VerifyPaymentChannelClaim(channelID, 1500000, signature, newTestSigner(t, keyType).PublicKeyHex()), ErrInvalidClaim)
+
+			other, err := PaymentChannelIndex(genesisAccount, testAccount, 8)
+			require.NoError(t, err)
+			assert.ErrorIs(t, VerifyPaymentChannelClaim(other, 1500000, signature, payer.PublicKeyHex()), ErrInvalidClaim, "claims are bound to their channel")
+		})
+	}
+
+	_, err = PaymentChannelClaimData("ABCD", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+package xrpl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// CheckCreate represents parameters for writing a check its destination can later cash for up to SendMax
+type CheckCreate struct {
+	Account     string `json:"Account"`
+	Destination string `json:"Destination"`
+	// SendMax is the most the check can debit from the source, including any transfer fee
+	SendMax Amount `json:"SendMax"`
+	// Expiration is the ripple time after which the check can no longer be cashed
+	Expiration     uint32 `json:"Expiration,omitempty"`
+	InvoiceID      string `json:"InvoiceID,omitempty"`
+	DestinationTag uint32 `json:"DestinationTag,omitempty"`
+	SourceTag      uint32 `json:"SourceTag,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// CheckCash represents parameters for cashing a check; exactly one of Amount and DeliverMin is set
+type CheckCash struct {
+	Account string `json:"Account"`
+	CheckID string `json:"CheckID"`
+	// Amount cashes the check for exactly this much
+	Amount *Amount `json:"Amount,omitempty"`
+	// DeliverMin cashes the check for as much as possible, failing below this much
+	DeliverMin     *Amount `json:"DeliverMin,omitempty"`
+	TicketSequence uint32  `json:"TicketSequence,omitempty"`
+	Fee            string  `json:"Fee,omitempty"`
+}
+
+// CheckCancel represents parameters for removing a check without cashing it
+type CheckCancel struct {
+	Account        string `json:"Account"`
+	CheckID        string `json:"CheckID"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// CheckInfo represents a Check ledger entry
+type CheckInfo struct {
+	Account        string `json:"Account"`
+	Destination    string `json:"Destination"`
+	SendMax        Amount `json:"SendMax"`
+	Sequence       uint32 `json:"Sequence"`
+	Expiration     uint32 `json:"Expiration,omitempty"`
+	InvoiceID      string `json:"InvoiceID,omitempty"`
+	DestinationTag uint32 `json:"DestinationTag,omitempty"`
+	SourceTag      uint32 `json:"SourceTag,omitempty"`
+	PreviousTxnID  string `json:"PreviousTxnID"`
+	// Index is the ledger entry ID of the check, which CheckCash and CheckCancel refer to
+	Index string `json:"index,omitempty"`
+}
+
+// CheckResolution describes the validated transaction that removed a check from the ledger
+type CheckResolution struct {
+	TransactionID   string `json:"transaction_id"`
+	TransactionType string `json:"transaction_type"`
+	Account         string `json:"account"`
+	LedgerIndex     uint32 `json:"ledger_index"`
+	ResultCode      string `json:"result_code"`
+	// DeliveredAmount is what cashing the check paid its destination
+	DeliveredAmount *Amount `json:"delivered_amount,omitempty"`
+}
+
+// Cashed reports whether the check was paid to its destination rather than cancelled
+func (r *CheckResolution) Cashed() bool {
+	return r.TransactionType == "CheckCash"
+}
+
+// validCheckID reports whether id is a 256-bit ledger entry ID in hex
+func validCheckID(id string) bool {
+	raw, err := hex.DecodeString(id)
+	return err == nil && len(raw) == 32
+}
+
+// CreateCheck writes a check; the check ID follows from the result's Sequence through CheckIndex
+func (c *Client) CreateCheck(check *CheckCreate) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(check.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", check.Account)
+	}
+	if !c.ValidateAddress(check.Destination) {
+		return nil, fmt.Errorf("invalid destination address: %s", check.Destination)
+	}
+	destination, tag, err := ResolveDestination(check.Destination, check.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	check.Destination, check.DestinationTag = destination, tag
+	if check.Account == check.Destination {
+		return nil, fmt.Errorf("check destination must differ from its source")
+	}
+	if check.SendMax.Value == "" {
+		return nil, fmt.Errorf("check SendMax is required")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("CheckCreate", check)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Created check: %s -> %s, SendMax: %s, TxID: %s", check.Account, check.Destination, check.SendMax, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// CashCheck redeems a check as its destination, for an exact amount or for at least DeliverMin
+func (c *Client) CashCheck(cash *CheckCash) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(cash.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", cash.Account)
+	}
+	if !validCheckID(cash.CheckID) {
+		return nil, fmt.Errorf("invalid check ID: %s", cash.CheckID)
+	}
+	if (cash.Amount == nil) == (cash.DeliverMin == nil) {
+		return nil, fmt.Errorf("cashing a check needs exactly one of Amount and DeliverMin")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("CheckCash", cash)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Cashed check %s by %s, TxID: %s", cash.CheckID, cash.Account, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// CancelCheck removes a check; its source or destination may cancel it at any time, anyone once it expired
+func (c *Client) CancelCheck(cancel *CheckCancel) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(cancel.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", cancel.Account)
+	}
+	if !validCheckID(cancel.CheckID) {
+		return nil, fmt.Errorf("invalid check ID: %s", cancel.CheckID)
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("CheckCancel", cancel)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Cancelled check %s by %s, TxID: %s", cancel.CheckID, cancel.Account, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// GetCheck looks up a check by ID in the validated ledger
+func (c *Client) GetCheck(checkID string) (*CheckInfo, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+	if !validCheckID(checkID) {
+		return nil, fmt.Errorf("invalid check ID: %s", checkID)
+	}
+
+	// The offline simulation keeps no ledger to look checks up in
+	if c.simulated() {
+		return nil, fmt.Errorf("%w: check %s", ErrEntryNotFound, checkID)
+	}
+
+	var result struct {
+		Node  CheckInfo `json:"node"`
+		Index string    `json:"index"`
+	}
+	params := map[string]interface{}{
+		"check":        checkID,
+		"ledger_index": "validated",
+	}
+	if err := c.call("ledger_entry", params, &result); err != nil {
+		return nil, err
+	}
+	result.Node.Index = result.Index
+	return &result.Node, nil
+}
+
+// ListChecks lists the checks an account wrote or can cash in the validated ledger, following
+// account_objects markers until the last page
+func (c *Client) ListChecks(account string) ([]CheckInfo, error) {
+	if !c.ValidateAddress(account) {
+		return nil, fmt.Errorf("invalid account address: %s", account)
+	}
+
+	if c.simulated() {
+		return []CheckInfo{}, nil
+	}
+
+	checks := []CheckInfo{}
+	var marker interface{}
+	for {
+		var result struct {
+			AccountObjects []CheckInfo `json:"account_objects"`
+			Marker         interface{} `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":      account,
+			"type":         "check",
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_objects", params, &result); err != nil {
+			return nil, err
+		}
+
+		checks = append(checks, result.AccountObjects...)
+		if result.Marker == nil {
+			sort.Slice(checks, func(i, j int) bool { return checks[i].Sequence < checks[j].Sequence })
+			return checks, nil
+		}
+		marker = result.Marker
+	}
+}
+
+// FindCheckResolution searches the source's validated history from sinceLedger onwards for the
+// transaction that cashed or cancelled one of its checks. It returns ErrEntryNotFound when no
+// validated transaction has removed the check.
+func (c *Client) FindCheckResolution(source, checkID string, sinceLedger uint32) (*CheckResolution, error) {
+	if !c.ValidateAddress(source) {
+		return nil, fmt.Errorf("invalid source address: %s", source)
+	}
+	if !validCheckID(checkID) {
+		return nil, fmt.Errorf("invalid check ID: %s", checkID)
+	}
+
+	if c.simulated() {
+		return nil, fmt.Errorf("check %s has no recorded resolution: %w", checkID, ErrEntryNotFound)
+	}
+
+	entry, err := c.findDeletion(source, "Check", checkID, sinceLedger)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("check %s was not removed by a validated transaction: %w", checkID, ErrEntryNotFound)
+	}
+
+	tx := entry.transaction()
+	resolution := &CheckResolution{
+		TransactionID: entry.transactionID(),
+		LedgerIndex:   entry.validatedIn(),
+		ResultCode:    entry.Meta.TransactionResult,
+	}
+	resolution.TransactionType, _ = tx["TransactionType"].(string)
+	resolution.Account, _ = tx["Account"].(string)
+	if resolution.Cashed() {
+		resolution.DeliveredAmount = entry.Meta.DeliveredAmount
+	}
+	return resolution, nil
+}
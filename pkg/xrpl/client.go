@@ -7,15 +7,33 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Mode selects how the client reaches the ledger
+type Mode string
+
+const (
+	// ModeSimulator answers every request locally with simulated results
+	ModeSimulator Mode = "simulator"
+	// ModeJSONRPC talks to a rippled server over its JSON-RPC interface
+	ModeJSONRPC Mode = "jsonrpc"
+	// ModeSandbox talks JSON-RPC to an in-process ledger simulator started by the service
+	ModeSandbox Mode = "sandbox"
+)
+
 type Client struct {
 	NetworkURL string
 	TestNet    bool
-	httpClient *http.Client
+	Mode       Mode
+	// PollInterval is how often WaitForValidation checks on a transaction; zero uses DefaultPollInterval
+	PollInterval time.Duration
+	httpClient   *http.Client
+
+	keyProvider KeyProvider
+	sequences   *SequenceAllocator
 }
 
 type WalletInfo struct {
@@ -23,6 +41,7 @@ type WalletInfo struct {
 	PublicKey  string `json:"public_key"`
 	PrivateKey string `json:"private_key"`
 	Seed       string `json:"seed"`
+	KeyType    string `json:"key_type"`
 }
 
 type AccountInfo struct {
@@ -39,35 +58,71 @@ type AccountInfo struct {
 type EscrowCreate struct {
 	Account        string `json:"Account"`
 	Destination    string `json:"Destination"`
-	Amount         string `json:"Amount"`
+	Amount         Amount `json:"Amount"`
 	Condition      string `json:"Condition,omitempty"`
 	CancelAfter    uint32 `json:"CancelAfter,omitempty"`
 	FinishAfter    uint32 `json:"FinishAfter,omitempty"`
 	DestinationTag uint32 `json:"DestinationTag,omitempty"`
 	SourceTag      uint32 `json:"SourceTag,omitempty"`
+	// TicketSequence submits the transaction with a ticket instead of the next account sequence
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	// Fee is the transaction cost in drops; empty pays the current open ledger cost
+	Fee string `json:"Fee,omitempty"`
+}
+
+// Payment represents parameters for a direct XRPL payment
+type Payment struct {
+	Account        string `json:"Account"`
+	Destination    string `json:"Destination"`
+	Amount         Amount `json:"Amount"`
+	DestinationTag uint32 `json:"DestinationTag,omitempty"`
+	SourceTag      uint32 `json:"SourceTag,omitempty"`
+	// SendMax caps what the sender spends, in the source currency, on a cross-currency payment
+	SendMax *Amount `json:"SendMax,omitempty"`
+	// DeliverMin is the least a partial payment may deliver
+	DeliverMin *Amount `json:"DeliverMin,omitempty"`
+	// Paths are the rippling and order book paths a cross-currency payment may take
+	Paths []Path `json:"Paths,omitempty"`
+	Flags uint32 `json:"Flags,omitempty"`
+	// TicketSequence submits the transaction with a ticket instead of the next account sequence
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	// Fee is the transaction cost in drops; empty pays the current open ledger cost
+	Fee string `json:"Fee,omitempty"`
+}
+
+// PaymentFlagPartialPayment lets a payment deliver less than its Amount, down to DeliverMin
+const PaymentFlagPartialPayment = 0x00020000
+
+// CrossCurrency reports whether the payment spends a different currency than it delivers
+func (p *Payment) CrossCurrency() bool {
+	return p.SendMax != nil && (p.SendMax.Currency != p.Amount.Currency || p.SendMax.Issuer != p.Amount.Issuer)
 }
 
 // EscrowFinish represents parameters for finishing an XRPL escrow
 type EscrowFinish struct {
-	Account       string `json:"Account"`
-	Owner         string `json:"Owner"`
-	OfferSequence uint32 `json:"OfferSequence"`
-	Condition     string `json:"Condition,omitempty"`
-	Fulfillment   string `json:"Fulfillment,omitempty"`
+	Account        string `json:"Account"`
+	Owner          string `json:"Owner"`
+	OfferSequence  uint32 `json:"OfferSequence"`
+	Condition      string `json:"Condition,omitempty"`
+	Fulfillment    string `json:"Fulfillment,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
 }
 
 // EscrowCancel represents parameters for canceling an XRPL escrow
 type EscrowCancel struct {
-	Account       string `json:"Account"`
-	Owner         string `json:"Owner"`
-	OfferSequence uint32 `json:"OfferSequence"`
+	Account        string `json:"Account"`
+	Owner          string `json:"Owner"`
+	OfferSequence  uint32 `json:"OfferSequence"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
 }
 
 // EscrowInfo represents escrow information from the ledger
 type EscrowInfo struct {
 	Account         string `json:"Account"`
 	Destination     string `json:"Destination"`
-	Amount          string `json:"Amount"`
+	Amount          Amount `json:"Amount"`
 	Condition       string `json:"Condition,omitempty"`
 	CancelAfter     uint32 `json:"CancelAfter,omitempty"`
 	FinishAfter     uint32 `json:"FinishAfter,omitempty"`
@@ -75,7 +130,12 @@ type EscrowInfo struct {
 	OwnerNode       string `json:"OwnerNode"`
 	DestinationNode string `json:"DestinationNode"`
 	PreviousTxnID   string `json:"PreviousTxnID"`
-	Sequence        uint32 `json:"Sequence"`
+	// PreviousTxnLgrSeq is the ledger of the transaction that created the escrow
+	PreviousTxnLgrSeq uint32 `json:"PreviousTxnLgrSeq,omitempty"`
+	// Sequence is the sequence or ticket of the EscrowCreate, which identifies the escrow with its owner
+	Sequence uint32 `json:"Sequence"`
+	// Index is the ledger entry ID of the escrow
+	Index string `json:"index,omitempty"`
 }
 
 // TransactionResult represents the result of a submitted transaction
@@ -85,16 +145,62 @@ type TransactionResult struct {
 	Validated     bool   `json:"validated"`
 	ResultCode    string `json:"result_code"`
 	ResultMessage string `json:"result_message"`
+	// Sequence is the sequence the transaction consumed: its ticket when it was submitted with one
+	Sequence uint32 `json:"sequence,omitempty"`
+	// TicketSequence is set when the transaction was submitted with a ticket
+	TicketSequence uint32 `json:"ticket_sequence,omitempty"`
+	// LastLedgerSequence is the last ledger the transaction can be included in, when it has one
+	LastLedgerSequence uint32 `json:"last_ledger_sequence,omitempty"`
+	// Fee is the transaction cost in drops the transaction was signed with
+	Fee string `json:"fee,omitempty"`
+	// DeliveredAmount is what a validated payment actually delivered, which differs from its
+	// Amount for cross-currency and partial payments
+	DeliveredAmount *Amount `json:"delivered_amount,omitempty"`
+	// Meta describes the validated transaction's effect on the ledger
+	Meta *TransactionMeta `json:"meta,omitempty"`
 }
 
+// NewClient creates a client in simulator mode
 func NewClient(networkURL string, testNet bool) *Client {
-	return &Client{
-		NetworkURL: networkURL,
-		TestNet:    testNet,
+	return NewClientWithMode(networkURL, testNet, ModeSimulator)
+}
+
+// NewClientWithMode creates a client using the given transport mode
+func NewClientWithMode(networkURL string, testNet bool, mode Mode) *Client {
+	if mode == "" {
+		mode = ModeSimulator
+	}
+
+	client := &Client{
+		NetworkURL:   networkURL,
+		TestNet:      testNet,
+		Mode:         mode,
+		PollInterval: DefaultPollInterval,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	client.sequences = NewSequenceAllocator(client.accountSequence)
+	return client
+}
+
+// ParseMode converts a configuration value into a client mode
+func ParseMode(value string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(value))) {
+	case "", ModeSimulator:
+		return ModeSimulator, nil
+	case ModeJSONRPC:
+		return ModeJSONRPC, nil
+	case ModeSandbox:
+		return ModeSandbox, nil
+	default:
+		return "", fmt.Errorf("unsupported XRPL client mode: %s", value)
+	}
+}
+
+// simulated reports whether the client answers requests locally
+func (c *Client) simulated() bool {
+	return c.Mode == "" || c.Mode == ModeSimulator
 }
 
 func (c *Client) Connect() error {
@@ -103,7 +209,12 @@ func (c *Client) Connect() error {
 		return fmt.Errorf("invalid network URL format: %s", c.NetworkURL)
 	}
 
-	log.Printf("Connected to XRPL network: %s (TestNet: %v)", c.NetworkURL, c.TestNet)
+	if c.simulated() {
+		log.Printf("Connected to XRPL network: %s (TestNet: %v, Mode: simulator - ledger results are simulated)", c.NetworkURL, c.TestNet)
+		return nil
+	}
+
+	log.Printf("Connected to XRPL network: %s (TestNet: %v, Mode: %s)", c.NetworkURL, c.TestNet, c.Mode)
 	return nil
 }
 
@@ -112,74 +223,61 @@ func (c *Client) HealthCheck() error {
 		return fmt.Errorf("XRPL client not initialized")
 	}
 
+	if !c.simulated() {
+		info, err := c.GetServerInfo()
+		if err != nil {
+			return fmt.Errorf("XRPL server_info failed: %w", err)
+		}
+		if !info.IsSynced() {
+			return fmt.Errorf("%w: server state is %s", ErrServerNotReady, info.ServerState)
+		}
+	}
+
 	log.Println("XRPL client health check - OK")
 	return nil
 }
 
+// GenerateWallet creates a new secp256k1 wallet from a random family seed
 func (c *Client) GenerateWallet() (*WalletInfo, error) {
-	// Generate a random seed for the wallet
-	seedBytes := make([]byte, 16)
-	if _, err := rand.Read(seedBytes); err != nil {
-		return nil, fmt.Errorf("failed to generate random seed: %w", err)
-	}
-
-	// For now, create a simplified wallet structure
-	// In production, this would use proper XRPL key derivation
-	seed := hex.EncodeToString(seedBytes)
-
-	// Generate a mock XRPL address that passes validation
-	// XRPL addresses use Base58 encoding with specific alphabet (excluding 0, O, I, l)
-	const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
-
-	addressBytes := make([]byte, 24) // Generate 24 characters after 'r'
-	if _, err := rand.Read(addressBytes); err != nil {
-		return nil, fmt.Errorf("failed to generate address bytes: %w", err)
-	}
+	return c.GenerateWalletWithKeyType(KeyTypeSecp256k1)
+}
 
-	// Create a mock address that looks like an XRPL address
-	address := "r"
-	for _, b := range addressBytes {
-		address += string(base58Alphabet[int(b)%len(base58Alphabet)])
+// GenerateWalletWithKeyType creates a new wallet of the given key type from a random family seed
+func (c *Client) GenerateWalletWithKeyType(keyType KeyType) (*WalletInfo, error) {
+	seed, err := GenerateSeed(keyType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate random seed: %w", err)
 	}
 
-	// Generate mock keys
-	privateKeyBytes := make([]byte, 32)
-	if _, err := rand.Read(privateKeyBytes); err != nil {
-		return nil, fmt.Errorf("failed to generate private key: %w", err)
-	}
+	return c.WalletFromSeed(seed)
+}
 
-	publicKeyBytes := make([]byte, 33)
-	if _, err := rand.Read(publicKeyBytes); err != nil {
-		return nil, fmt.Errorf("failed to generate public key: %w", err)
+// WalletFromSeed derives the wallet keys and classic address for an existing family seed
+func (c *Client) WalletFromSeed(seed string) (*WalletInfo, error) {
+	keyPair, err := DeriveKeyPair(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keypair: %w", err)
 	}
 
 	return &WalletInfo{
-		Address:    address,
-		PublicKey:  hex.EncodeToString(publicKeyBytes),
-		PrivateKey: hex.EncodeToString(privateKeyBytes),
+		Address:    keyPair.Address(),
+		PublicKey:  keyPair.PublicKeyHex(),
+		PrivateKey: keyPair.PrivateKeyHex(),
 		Seed:       seed,
+		KeyType:    string(keyPair.KeyType),
 	}, nil
 }
 
+// ValidateAddress verifies a classic address or X-address, including its checksum
 func (c *Client) ValidateAddress(address string) bool {
-	// Basic XRPL address validation
-	if len(address) < 25 || len(address) > 34 {
-		return false
-	}
-
-	// Check if it starts with 'r' for classic addresses
-	if !strings.HasPrefix(address, "r") {
-		return false
-	}
-
-	// Basic regex pattern for XRPL addresses (Base58 characters after 'r')
-	pattern := `^r[1-9A-HJ-NP-Za-km-z]{24,33}$`
-	matched, err := regexp.MatchString(pattern, address)
-	if err != nil {
+	switch {
+	case strings.HasPrefix(address, "r"):
+		return IsValidClassicAddress(address)
+	case strings.HasPrefix(address, "X"), strings.HasPrefix(address, "T"):
+		return IsValidXAddress(address)
+	default:
 		return false
 	}
-
-	return matched
 }
 
 func (c *Client) GetAccountInfo(address string) (*AccountInfo, error) {
@@ -187,32 +285,56 @@ func (c *Client) GetAccountInfo(address string) (*AccountInfo, error) {
 		return nil, fmt.Errorf("XRPL client not connected")
 	}
 
-	// For now, return a mock account info since we need a full JSON-RPC implementation
-	// This would be replaced with actual XRPL API calls in production
+	if !c.simulated() {
+		return c.getAccountInfoRPC(address)
+	}
+
+	settings, err := c.GetReserveSettings()
+	if err != nil {
+		return nil, err
+	}
+
 	return &AccountInfo{
 		Account:     address,
 		Balance:     "1000000000", // 1000 XRP in drops
 		Flags:       0,
 		Sequence:    1,
 		OwnerCount:  0,
-		Reserve:     "10000000", // 10 XRP reserve
+		Reserve:     strconv.FormatInt(settings.Required(0), 10),
 		PreviousTxn: "",
 	}, nil
 }
 
 func (c *Client) SubmitTransaction(txBlob string) error {
+	_, err := c.SubmitSignedTransaction(txBlob)
+	return err
+}
+
+// SubmitSignedTransaction submits a signed transaction blob and returns the provisional result
+func (c *Client) SubmitSignedTransaction(txBlob string) (*TransactionResult, error) {
 	if c.httpClient == nil {
-		return fmt.Errorf("XRPL client not connected")
+		return nil, fmt.Errorf("XRPL client not connected")
 	}
 
-	// This would implement actual transaction submission
-	// For now, just validate the transaction blob format
 	if len(txBlob) == 0 {
-		return fmt.Errorf("empty transaction blob")
+		return nil, fmt.Errorf("empty transaction blob")
+	}
+
+	if !c.simulated() {
+		result, err := c.submitBlob(txBlob)
+		if err != nil {
+			return result, err
+		}
+		log.Printf("Transaction submitted: %s (%s)", result.TransactionID, result.ResultCode)
+		return result, nil
 	}
 
 	log.Printf("Transaction submitted successfully: %s", txBlob[:minInt(20, len(txBlob))]+"...")
-	return nil
+	return &TransactionResult{
+		TransactionID: c.generateTransactionID(),
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
 }
 
 // CreateEscrow creates an XRPL escrow transaction
@@ -222,7 +344,7 @@ func (c *Client) CreateEscrow(escrow *EscrowCreate) (*TransactionResult, error)
 	}
 
 	// Validate required fields
-	if escrow.Account == "" || escrow.Destination == "" || escrow.Amount == "" {
+	if escrow.Account == "" || escrow.Destination == "" || escrow.Amount.Value == "" {
 		return nil, fmt.Errorf("missing required escrow fields: Account, Destination, and Amount are required")
 	}
 
@@ -233,6 +355,25 @@ func (c *Client) CreateEscrow(escrow *EscrowCreate) (*TransactionResult, error)
 	if !c.ValidateAddress(escrow.Destination) {
 		return nil, fmt.Errorf("invalid destination address: %s", escrow.Destination)
 	}
+	destination, tag, err := ResolveDestination(escrow.Destination, escrow.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	escrow.Destination, escrow.DestinationTag = destination, tag
+
+	if escrow.Condition != "" {
+		if _, _, err := ParseCondition(escrow.Condition); err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("EscrowCreate", escrow)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
 
 	// Generate a mock transaction ID for simulation
 	txID := c.generateTransactionID()
@@ -268,6 +409,21 @@ func (c *Client) FinishEscrow(finish *EscrowFinish) (*TransactionResult, error)
 		return nil, fmt.Errorf("invalid owner address: %s", finish.Owner)
 	}
 
+	// Check the fulfillment locally rather than paying the fee for a rejected finish
+	if finish.Condition != "" || finish.Fulfillment != "" {
+		if err := ValidateFulfillment(finish.Condition, finish.Fulfillment); err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("EscrowFinish", finish)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
 	// Generate a mock transaction ID for simulation
 	txID := c.generateTransactionID()
 
@@ -302,6 +458,14 @@ func (c *Client) CancelEscrow(cancel *EscrowCancel) (*TransactionResult, error)
 		return nil, fmt.Errorf("invalid owner address: %s", cancel.Owner)
 	}
 
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("EscrowCancel", cancel)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
 	// Generate a mock transaction ID for simulation
 	txID := c.generateTransactionID()
 
@@ -317,6 +481,54 @@ func (c *Client) CancelEscrow(cancel *EscrowCancel) (*TransactionResult, error)
 	}, nil
 }
 
+// SendPayment submits a direct payment between two accounts
+func (c *Client) SendPayment(payment *Payment) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if payment.Account == "" || payment.Destination == "" || payment.Amount.Value == "" {
+		return nil, fmt.Errorf("missing required payment fields: Account, Destination, and Amount are required")
+	}
+	if !c.ValidateAddress(payment.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", payment.Account)
+	}
+	if !c.ValidateAddress(payment.Destination) {
+		return nil, fmt.Errorf("invalid destination address: %s", payment.Destination)
+	}
+	destination, tag, err := ResolveDestination(payment.Destination, payment.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	payment.Destination, payment.DestinationTag = destination, tag
+	// An account can only pay itself to convert one currency into another
+	if payment.Account == payment.Destination && !payment.CrossCurrency() {
+		return nil, fmt.Errorf("payment to self must convert between currencies")
+	}
+	if payment.DeliverMin != nil && payment.Flags&PaymentFlagPartialPayment == 0 {
+		return nil, fmt.Errorf("DeliverMin requires the partial payment flag")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("Payment", payment)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Sent payment: %s -> %s, Amount: %s, TxID: %s", payment.Account, payment.Destination, payment.Amount, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
 // GetEscrowInfo retrieves information about an escrow
 func (c *Client) GetEscrowInfo(owner, sequence string) (*EscrowInfo, error) {
 	if c.httpClient == nil {
@@ -328,19 +540,19 @@ func (c *Client) GetEscrowInfo(owner, sequence string) (*EscrowInfo, error) {
 		return nil, fmt.Errorf("invalid owner address: %s", owner)
 	}
 
-	// Mock escrow info for simulation - use different amounts for realistic testing
-	amount := "1000000" // Default 1 XRP in drops
-	switch sequence {
-	case "2":
-		amount = "5000000" // 5 XRP for sequence 2
-	case "3":
-		amount = "25000000" // 25 XRP for sequence 3
+	if !c.simulated() {
+		offerSequence, err := strconv.ParseUint(sequence, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid escrow sequence: %s", sequence)
+		}
+		return c.getEscrowInfoRPC(owner, uint32(offerSequence))
 	}
 
+	// The offline simulation keeps no ledger, so every escrow looks like a plain 1 XRP escrow
 	return &EscrowInfo{
 		Account:         owner,
 		Destination:     "rDestinationAddress123456789",
-		Amount:          amount,
+		Amount:          Amount{Value: "1000000"},
 		Condition:       "",
 		CancelAfter:     0,
 		FinishAfter:     0,
@@ -352,21 +564,37 @@ func (c *Client) GetEscrowInfo(owner, sequence string) (*EscrowInfo, error) {
 	}, nil
 }
 
-// GenerateCondition creates a cryptographic condition for escrow based on milestone verification method
+// GenerateCondition derives a PREIMAGE-SHA-256 condition and fulfillment from a secret.
+// The same secret always yields the same DER-encoded condition and fulfillment.
 func (c *Client) GenerateCondition(secret string) (condition string, fulfillment string, retErr error) {
 	if secret == "" {
 		return "", "", fmt.Errorf("secret cannot be empty")
 	}
 
-	// Create a SHA-256 based condition with additional entropy for security
-	hash := sha256.Sum256([]byte(secret + "smartcheque_condition"))
-	condition = hex.EncodeToString(hash[:])
-	fulfillment = secret
+	condition, fulfillment, err := conditionFromSecret(secret + "smartcheque_condition")
+	if err != nil {
+		return "", "", err
+	}
 
 	log.Printf("Generated condition: %s for secret", condition)
 	return condition, fulfillment, nil
 }
 
+// conditionFromSecret uses the SHA-256 digest of a secret as a fixed-size preimage
+func conditionFromSecret(secret string) (condition string, fulfillment string, err error) {
+	preimage := sha256.Sum256([]byte(secret))
+	return NewPreimageCondition(preimage[:])
+}
+
+// conditionNonce returns random hex so milestone secrets cannot be guessed from their inputs
+func conditionNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate condition nonce: %w", err)
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
 // GenerateMilestoneCondition creates a condition based on milestone verification method
 func (c *Client) GenerateMilestoneCondition(milestoneID string, verificationMethod string, oracleConfig map[string]interface{}) (condition string, fulfillment string, err error) {
 	if milestoneID == "" {
@@ -396,11 +624,17 @@ func (c *Client) generateOracleCondition(milestoneID string, oracleConfig map[st
 		}
 	}
 
+	nonce, err := conditionNonce()
+	if err != nil {
+		return "", "", err
+	}
+
 	// Create a more robust secret that includes oracle endpoint for verification
-	secret := fmt.Sprintf("oracle_%s_%s_%d", milestoneID, endpoint, time.Now().Unix())
-	hash := sha256.Sum256([]byte(secret + "oracle_verification"))
-	condition = hex.EncodeToString(hash[:])
-	fulfillment = secret
+	secret := fmt.Sprintf("oracle_%s_%s_%d_%s", milestoneID, endpoint, time.Now().Unix(), nonce)
+	condition, fulfillment, err = conditionFromSecret(secret + "oracle_verification")
+	if err != nil {
+		return "", "", err
+	}
 
 	log.Printf("Generated oracle condition for milestone %s with endpoint %s: %s", milestoneID, endpoint, condition)
 	return condition, fulfillment, nil
@@ -408,11 +642,17 @@ func (c *Client) generateOracleCondition(milestoneID string, oracleConfig map[st
 
 // generateManualCondition creates a condition for manual verification
 func (c *Client) generateManualCondition(milestoneID string) (condition string, fulfillment string, err error) {
+	nonce, err := conditionNonce()
+	if err != nil {
+		return "", "", err
+	}
+
 	// For manual verification, create a time-locked condition with manual approval
-	secret := fmt.Sprintf("manual_%s_%d", milestoneID, time.Now().Unix())
-	hash := sha256.Sum256([]byte(secret))
-	condition = hex.EncodeToString(hash[:])
-	fulfillment = secret
+	secret := fmt.Sprintf("manual_%s_%d_%s", milestoneID, time.Now().Unix(), nonce)
+	condition, fulfillment, err = conditionFromSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
 
 	log.Printf("Generated manual condition for milestone %s: %s", milestoneID, condition)
 	return condition, fulfillment, nil
@@ -423,59 +663,65 @@ func (c *Client) generateHybridCondition(milestoneID string, oracleConfig map[st
 	// oracleConfig parameter is available for future use in configuring oracle-based conditions
 	_ = oracleConfig // Explicitly ignore to satisfy linter
 
+	nonce, err := conditionNonce()
+	if err != nil {
+		return "", "", err
+	}
+
 	// For hybrid verification, create a compound condition requiring both oracle and manual approval
-	secret := fmt.Sprintf("hybrid_%s_%d", milestoneID, time.Now().Unix())
-	hash := sha256.Sum256([]byte(secret))
-	condition = hex.EncodeToString(hash[:])
-	fulfillment = secret
+	secret := fmt.Sprintf("hybrid_%s_%d_%s", milestoneID, time.Now().Unix(), nonce)
+	condition, fulfillment, err = conditionFromSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
 
 	log.Printf("Generated hybrid condition for milestone %s: %s", milestoneID, condition)
 	return condition, fulfillment, nil
 }
 
-// CreateEscrowWithMilestones creates an XRPL escrow with milestone-based conditions
-func (c *Client) CreateEscrowWithMilestones(escrow *EscrowCreate, milestones []MilestoneCondition) (*TransactionResult, error) {
+// CreateEscrowWithMilestones creates an XRPL escrow with milestone-based conditions and
+// returns the fulfillment that finishes it; callers are responsible for storing it securely
+func (c *Client) CreateEscrowWithMilestones(escrow *EscrowCreate, milestones []MilestoneCondition) (*TransactionResult, string, error) {
 	if c.httpClient == nil {
-		return nil, fmt.Errorf("XRPL client not connected")
+		return nil, "", fmt.Errorf("XRPL client not connected")
 	}
 
 	// Validate required fields
-	if escrow.Account == "" || escrow.Destination == "" || escrow.Amount == "" {
-		return nil, fmt.Errorf("missing required escrow fields: Account, Destination, and Amount are required")
+	if escrow.Account == "" || escrow.Destination == "" || escrow.Amount.Value == "" {
+		return nil, "", fmt.Errorf("missing required escrow fields: Account, Destination, and Amount are required")
 	}
 
 	// Validate addresses
 	if !c.ValidateAddress(escrow.Account) {
-		return nil, fmt.Errorf("invalid account address: %s", escrow.Account)
+		return nil, "", fmt.Errorf("invalid account address: %s", escrow.Account)
 	}
 	if !c.ValidateAddress(escrow.Destination) {
-		return nil, fmt.Errorf("invalid destination address: %s", escrow.Destination)
+		return nil, "", fmt.Errorf("invalid destination address: %s", escrow.Destination)
 	}
 
 	// If milestones are provided, set up conditional escrow
+	var fulfillment string
 	if len(milestones) > 0 {
 		// For multiple milestones, create a compound condition
 		compoundSecret := c.GenerateCompoundSecret(milestones)
-		condition, fulfillment, err := c.GenerateCondition(compoundSecret)
+		condition, compoundFulfillment, err := c.GenerateCondition(compoundSecret)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate compound condition: %w", err)
+			return nil, "", fmt.Errorf("failed to generate compound condition: %w", err)
 		}
 
 		escrow.Condition = condition
-
-		// Store fulfillment for later use
+		fulfillment = compoundFulfillment
 		log.Printf("Created compound condition for %d milestones: %s", len(milestones), condition)
-		_ = fulfillment // In production, this would be securely stored
 	}
 
 	// Create the escrow
 	result, err := c.CreateEscrow(escrow)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create escrow: %w", err)
+		return nil, "", fmt.Errorf("failed to create escrow: %w", err)
 	}
 
 	log.Printf("Created escrow with %d milestone conditions: %s", len(milestones), result.TransactionID)
-	return result, nil
+	return result, fulfillment, nil
 }
 
 // MilestoneCondition represents a milestone with its verification requirements
@@ -488,8 +734,13 @@ type MilestoneCondition struct {
 
 // GenerateCompoundSecret creates a compound secret for multiple milestones
 func (c *Client) GenerateCompoundSecret(milestones []MilestoneCondition) string {
-	// Create a compound secret that includes all milestone IDs, verification methods, and current timestamp
-	compoundData := fmt.Sprintf("compound_%d", time.Now().Unix())
+	nonce, err := conditionNonce()
+	if err != nil {
+		log.Printf("Failed to generate compound secret nonce: %v", err)
+	}
+
+	// Create a compound secret that includes all milestone IDs, verification methods, the current timestamp and a random nonce
+	compoundData := fmt.Sprintf("compound_%d_%s", time.Now().Unix(), nonce)
 
 	// Add each milestone's verification method and ID for uniqueness
 	for _, milestone := range milestones {
@@ -535,16 +786,39 @@ func (c *Client) ValidateMilestoneConditions(milestones []MilestoneCondition) er
 }
 
 // CreateConditionalEscrowWithValidation creates an escrow with validated milestone conditions
-func (c *Client) CreateConditionalEscrowWithValidation(escrow *EscrowCreate, milestones []MilestoneCondition) (*TransactionResult, error) {
+// and returns the fulfillment that finishes it
+func (c *Client) CreateConditionalEscrowWithValidation(escrow *EscrowCreate, milestones []MilestoneCondition) (*TransactionResult, string, error) {
 	// Validate milestone conditions first
 	if err := c.ValidateMilestoneConditions(milestones); err != nil {
-		return nil, fmt.Errorf("milestone validation failed: %w", err)
+		return nil, "", fmt.Errorf("milestone validation failed: %w", err)
 	}
 
 	// Create the escrow with validated conditions
 	return c.CreateEscrowWithMilestones(escrow, milestones)
 }
 
+// CreateMilestoneEscrow creates an escrow funding a single milestone, guarded by a condition
+// generated for the milestone's verification method, and returns the fulfillment that finishes it
+func (c *Client) CreateMilestoneEscrow(escrow *EscrowCreate, milestone MilestoneCondition) (*TransactionResult, string, error) {
+	if err := c.ValidateMilestoneConditions([]MilestoneCondition{milestone}); err != nil {
+		return nil, "", fmt.Errorf("milestone validation failed: %w", err)
+	}
+
+	condition, fulfillment, err := c.GenerateMilestoneCondition(milestone.MilestoneID, milestone.VerificationMethod, milestone.OracleConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate condition for milestone %s: %w", milestone.MilestoneID, err)
+	}
+	escrow.Condition = condition
+
+	result, err := c.CreateEscrow(escrow)
+	if err != nil {
+		return result, "", fmt.Errorf("failed to create escrow for milestone %s: %w", milestone.MilestoneID, err)
+	}
+
+	log.Printf("Created escrow for milestone %s: %s", milestone.MilestoneID, result.TransactionID)
+	return result, fulfillment, nil
+}
+
 // generateTransactionID creates a mock transaction ID
 func (c *Client) generateTransactionID() string {
 	// Generate random bytes for transaction
@@ -64,6 +64,26 @@ func TestValidateAddress(t *testing.T) {
 			address:  "",
 			expected: false,
 		},
+		{
+			name:     "invalid address - bad checksum",
+			address:  "rN7n7otQDd6FczFgLdSqtcsAUxDkw6fzRJ",
+			expected: false,
+		},
+		{
+			name:     "invalid address - base58 shaped but random",
+			address:  "rDestinationAddress123456789",
+			expected: false,
+		},
+		{
+			name:     "valid X-address",
+			address:  "X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu",
+			expected: true,
+		},
+		{
+			name:     "invalid X-address checksum",
+			address:  "X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fv",
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -158,7 +178,7 @@ func TestCreateEscrow(t *testing.T) {
 			escrow: &EscrowCreate{
 				Account:     payerWallet.Address,
 				Destination: payeeWallet.Address,
-				Amount:      "1000000", // 1 XRP in drops
+				Amount:      XRPAmount(1000000), // 1 XRP in drops
 			},
 			expectError: false,
 		},
@@ -167,8 +187,8 @@ func TestCreateEscrow(t *testing.T) {
 			escrow: &EscrowCreate{
 				Account:     payerWallet.Address,
 				Destination: payeeWallet.Address,
-				Amount:      "5000000", // 5 XRP in drops
-				Condition:   "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855",
+				Amount:      XRPAmount(5000000), // 5 XRP in drops
+				Condition:   "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
 				CancelAfter: 123456789,
 				FinishAfter: 123456700,
 			},
@@ -178,7 +198,7 @@ func TestCreateEscrow(t *testing.T) {
 			name: "missing account",
 			escrow: &EscrowCreate{
 				Destination: payeeWallet.Address,
-				Amount:      "1000000",
+				Amount:      XRPAmount(1000000),
 			},
 			expectError: true,
 			errorMsg:    "missing required escrow fields",
@@ -187,7 +207,7 @@ func TestCreateEscrow(t *testing.T) {
 			name: "missing destination",
 			escrow: &EscrowCreate{
 				Account: payerWallet.Address,
-				Amount:  "1000000",
+				Amount:  XRPAmount(1000000),
 			},
 			expectError: true,
 			errorMsg:    "missing required escrow fields",
@@ -206,7 +226,7 @@ func TestCreateEscrow(t *testing.T) {
 			escrow: &EscrowCreate{
 				Account:     "invalid_address",
 				Destination: payeeWallet.Address,
-				Amount:      "1000000",
+				Amount:      XRPAmount(1000000),
 			},
 			expectError: true,
 			errorMsg:    "invalid account address",
@@ -216,7 +236,7 @@ func TestCreateEscrow(t *testing.T) {
 			escrow: &EscrowCreate{
 				Account:     payerWallet.Address,
 				Destination: "invalid_address",
-				Amount:      "1000000",
+				Amount:      XRPAmount(1000000),
 			},
 			expectError: true,
 			errorMsg:    "invalid destination address",
@@ -271,7 +291,7 @@ func TestFinishEscrow(t *testing.T) {
 				Account:       payeeWallet.Address,
 				Owner:         payerWallet.Address,
 				OfferSequence: 1,
-				Condition:     "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855",
+				Condition:     "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
 				Fulfillment:   "A0028000",
 			},
 			expectError: false,
@@ -514,10 +534,13 @@ func TestGenerateCondition(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotEmpty(t, condition)
-				assert.Equal(t, tt.secret, fulfillment)
 
-				// Condition should be a valid hex string (SHA-256 hash = 64 chars)
-				assert.Equal(t, 64, len(condition))
+				// DER-encoded PREIMAGE-SHA-256 over a 32-byte preimage
+				assert.Equal(t, 78, len(condition))
+				assert.True(t, strings.HasPrefix(condition, "A0258020"))
+				assert.True(t, strings.HasSuffix(condition, "810120"))
+				assert.Equal(t, 72, len(fulfillment))
+				assert.NoError(t, ValidateFulfillment(condition, fulfillment))
 
 				// Test that same secret generates same condition
 				condition2, fulfillment2, err2 := client.GenerateCondition(tt.secret)
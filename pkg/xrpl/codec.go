@@ -0,0 +1,663 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Transaction is an XRPL transaction keyed by its canonical field names, as
+// found in tx_json. Values use the JSON representation rippled accepts.
+type Transaction map[string]interface{}
+
+// Serialized type codes from the XRPL binary format definitions
+const (
+	typeUInt16    = 1
+	typeUInt32    = 2
+	typeUInt64    = 3
+	typeHash128   = 4
+	typeHash256   = 5
+	typeAmount    = 6
+	typeBlob      = 7
+	typeAccountID = 8
+	typeSTObject  = 14
+	typeSTArray   = 15
+	typeUInt8     = 16
+	typeHash160   = 17
+	typePathSet   = 18
+)
+
+const (
+	objectEndMarker = 0xE1
+	arrayEndMarker  = 0xF1
+)
+
+// Hash prefixes used when signing and identifying transactions
+var (
+	prefixTransactionSign      = []byte{0x53, 0x54, 0x58, 0x00} // STX\0
+	prefixTransactionMultiSign = []byte{0x53, 0x4D, 0x54, 0x00} // SMT\0
+	prefixTransactionID        = []byte{0x54, 0x58, 0x4E, 0x00} // TXN\0
+)
+
+// fieldDefinition describes how a field is serialized
+type fieldDefinition struct {
+	typeCode  int
+	nth       int
+	isVL      bool
+	isSigning bool
+}
+
+// fieldDefinitions lists the fields used by the transaction types the platform submits
+var fieldDefinitions = map[string]fieldDefinition{
+	"TransactionType": {typeCode: typeUInt16, nth: 2, isSigning: true},
+	"SignerWeight":    {typeCode: typeUInt16, nth: 3, isSigning: true},
+
+	"NetworkID":          {typeCode: typeUInt32, nth: 1, isSigning: true},
+	"Flags":              {typeCode: typeUInt32, nth: 2, isSigning: true},
+	"SourceTag":          {typeCode: typeUInt32, nth: 3, isSigning: true},
+	"Sequence":           {typeCode: typeUInt32, nth: 4, isSigning: true},
+	"Expiration":         {typeCode: typeUInt32, nth: 10, isSigning: true},
+	"TransferRate":       {typeCode: typeUInt32, nth: 11, isSigning: true},
+	"DestinationTag":     {typeCode: typeUInt32, nth: 14, isSigning: true},
+	"QualityIn":          {typeCode: typeUInt32, nth: 20, isSigning: true},
+	"QualityOut":         {typeCode: typeUInt32, nth: 21, isSigning: true},
+	"OfferSequence":      {typeCode: typeUInt32, nth: 25, isSigning: true},
+	"LastLedgerSequence": {typeCode: typeUInt32, nth: 27, isSigning: true},
+	"SetFlag":            {typeCode: typeUInt32, nth: 33, isSigning: true},
+	"ClearFlag":          {typeCode: typeUInt32, nth: 34, isSigning: true},
+	"SignerQuorum":       {typeCode: typeUInt32, nth: 35, isSigning: true},
+	"CancelAfter":        {typeCode: typeUInt32, nth: 36, isSigning: true},
+	"FinishAfter":        {typeCode: typeUInt32, nth: 37, isSigning: true},
+	"SettleDelay":        {typeCode: typeUInt32, nth: 39, isSigning: true},
+	"TicketCount":        {typeCode: typeUInt32, nth: 40, isSigning: true},
+	"TicketSequence":     {typeCode: typeUInt32, nth: 41, isSigning: true},
+
+	"EmailHash": {typeCode: typeHash128, nth: 1, isSigning: true},
+
+	"AccountTxnID":  {typeCode: typeHash256, nth: 9, isSigning: true},
+	"InvoiceID":     {typeCode: typeHash256, nth: 17, isSigning: true},
+	"Channel":       {typeCode: typeHash256, nth: 22, isSigning: true},
+	"CheckID":       {typeCode: typeHash256, nth: 24, isSigning: true},
+	"WalletLocator": {typeCode: typeHash256, nth: 7, isSigning: true},
+
+	"Amount":      {typeCode: typeAmount, nth: 1, isSigning: true},
+	"Balance":     {typeCode: typeAmount, nth: 2, isSigning: true},
+	"LimitAmount": {typeCode: typeAmount, nth: 3, isSigning: true},
+	"TakerPays":   {typeCode: typeAmount, nth: 4, isSigning: true},
+	"TakerGets":   {typeCode: typeAmount, nth: 5, isSigning: true},
+	"Fee":         {typeCode: typeAmount, nth: 8, isSigning: true},
+	"SendMax":     {typeCode: typeAmount, nth: 9, isSigning: true},
+	"DeliverMin":  {typeCode: typeAmount, nth: 10, isSigning: true},
+
+	"PublicKey":     {typeCode: typeBlob, nth: 1, isVL: true, isSigning: true},
+	"MessageKey":    {typeCode: typeBlob, nth: 2, isVL: true, isSigning: true},
+	"SigningPubKey": {typeCode: typeBlob, nth: 3, isVL: true, isSigning: true},
+	"TxnSignature":  {typeCode: typeBlob, nth: 4, isVL: true, isSigning: false},
+	"Signature":     {typeCode: typeBlob, nth: 6, isVL: true, isSigning: false},
+	"Domain":        {typeCode: typeBlob, nth: 7, isVL: true, isSigning: true},
+	"MemoType":      {typeCode: typeBlob, nth: 12, isVL: true, isSigning: true},
+	"MemoData":      {typeCode: typeBlob, nth: 13, isVL: true, isSigning: true},
+	"MemoFormat":    {typeCode: typeBlob, nth: 14, isVL: true, isSigning: true},
+	"Fulfillment":   {typeCode: typeBlob, nth: 16, isVL: true, isSigning: true},
+	"Condition":     {typeCode: typeBlob, nth: 17, isVL: true, isSigning: true},
+
+	"Account":     {typeCode: typeAccountID, nth: 1, isVL: true, isSigning: true},
+	"Owner":       {typeCode: typeAccountID, nth: 2, isVL: true, isSigning: true},
+	"Destination": {typeCode: typeAccountID, nth: 3, isVL: true, isSigning: true},
+	"RegularKey":  {typeCode: typeAccountID, nth: 8, isVL: true, isSigning: true},
+
+	"Memo":        {typeCode: typeSTObject, nth: 10, isSigning: true},
+	"SignerEntry": {typeCode: typeSTObject, nth: 11, isSigning: true},
+	"Signer":      {typeCode: typeSTObject, nth: 16, isSigning: true},
+
+	"Signers":       {typeCode: typeSTArray, nth: 3, isSigning: false},
+	"SignerEntries": {typeCode: typeSTArray, nth: 4, isSigning: true},
+	"Memos":         {typeCode: typeSTArray, nth: 9, isSigning: true},
+
+	"TickSize": {typeCode: typeUInt8, nth: 16, isSigning: true},
+
+	"Paths": {typeCode: typePathSet, nth: 1, isSigning: true},
+}
+
+// transactionTypes maps transaction type names to their serialized codes
+var transactionTypes = map[string]uint16{
+	"Payment":              0,
+	"EscrowCreate":         1,
+	"EscrowFinish":         2,
+	"AccountSet":           3,
+	"EscrowCancel":         4,
+	"OfferCreate":          7,
+	"OfferCancel":          8,
+	"TicketCreate":         10,
+	"SignerListSet":        12,
+	"PaymentChannelCreate": 13,
+	"PaymentChannelFund":   14,
+	"PaymentChannelClaim":  15,
+	"CheckCreate":          16,
+	"CheckCash":            17,
+	"CheckCancel":          18,
+	"TrustSet":             20,
+	"AccountDelete":        21,
+}
+
+// EncodeTransaction serializes a transaction, including its signature fields,
+// into the canonical XRPL binary format
+func EncodeTransaction(tx Transaction) ([]byte, error) {
+	return encodeObject(tx, false)
+}
+
+// EncodeTransactionHex serializes a transaction and returns it as an uppercase hex blob
+func EncodeTransactionHex(tx Transaction) (string, error) {
+	encoded, err := EncodeTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(encoded)), nil
+}
+
+// encodeForSigning returns the prefixed data that a single signer signs
+func encodeForSigning(tx Transaction) ([]byte, error) {
+	encoded, err := encodeObject(tx, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, prefixTransactionSign...), encoded...), nil
+}
+
+// encodeForMultiSigning returns the prefixed data that one signer of a multi-signed transaction
+// signs; the signer's account ID is appended so each signature covers a distinct message
+func encodeForMultiSigning(tx Transaction, signerAccount string) ([]byte, error) {
+	accountID, err := DecodeAccountID(signerAccount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer account: %w", err)
+	}
+
+	encoded, err := encodeObject(tx, true)
+	if err != nil {
+		return nil, err
+	}
+	data := append(append([]byte{}, prefixTransactionMultiSign...), encoded...)
+	return append(data, accountID...), nil
+}
+
+// TransactionHash computes the transaction ID of a signed transaction blob
+func TransactionHash(signedBlob []byte) string {
+	data := append(append([]byte{}, prefixTransactionID...), signedBlob...)
+	return strings.ToUpper(hex.EncodeToString(sha512Half(data)))
+}
+
+// copyTransaction returns a shallow copy of a transaction
+func copyTransaction(tx Transaction) Transaction {
+	copied := make(Transaction, len(tx))
+	for key, value := range tx {
+		copied[key] = value
+	}
+	return copied
+}
+
+// TransactionFromStruct converts a builder struct with XRPL JSON tags into a Transaction
+func TransactionFromStruct(transactionType string, builder interface{}) (Transaction, error) {
+	data, err := json.Marshal(builder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", transactionType, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	tx := Transaction{}
+	if err := decoder.Decode(&tx); err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", transactionType, err)
+	}
+
+	tx["TransactionType"] = transactionType
+	return tx, nil
+}
+
+// encodeObject serializes the fields of an object in canonical order
+func encodeObject(object map[string]interface{}, signingOnly bool) ([]byte, error) {
+	type orderedField struct {
+		name  string
+		def   fieldDefinition
+		value interface{}
+	}
+
+	fields := make([]orderedField, 0, len(object))
+	for name, value := range object {
+		def, ok := fieldDefinitions[name]
+		if !ok {
+			// Lowercase keys such as hash are API metadata rather than serialized fields
+			if name != "" && name[0] >= 'a' && name[0] <= 'z' {
+				continue
+			}
+			return nil, fmt.Errorf("unsupported transaction field: %s", name)
+		}
+		if signingOnly && !def.isSigning {
+			continue
+		}
+		fields = append(fields, orderedField{name: name, def: def, value: value})
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].def.typeCode != fields[j].def.typeCode {
+			return fields[i].def.typeCode < fields[j].def.typeCode
+		}
+		return fields[i].def.nth < fields[j].def.nth
+	})
+
+	var buf bytes.Buffer
+	for _, field := range fields {
+		buf.Write(encodeFieldID(field.def.typeCode, field.def.nth))
+
+		encoded, err := encodeValue(field.name, field.def, field.value, signingOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", field.name, err)
+		}
+
+		if field.def.isVL {
+			buf.Write(encodeVLLength(len(encoded)))
+		}
+		buf.Write(encoded)
+
+		switch field.def.typeCode {
+		case typeSTObject:
+			buf.WriteByte(objectEndMarker)
+		case typeSTArray:
+			buf.WriteByte(arrayEndMarker)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeFieldID encodes the type and field codes into the one to three byte field header
+func encodeFieldID(typeCode, nth int) []byte {
+	switch {
+	case typeCode < 16 && nth < 16:
+		return []byte{byte(typeCode<<4 | nth)}
+	case typeCode >= 16 && nth < 16:
+		return []byte{byte(nth), byte(typeCode)}
+	case typeCode < 16 && nth >= 16:
+		return []byte{byte(typeCode << 4), byte(nth)}
+	default:
+		return []byte{0, byte(typeCode), byte(nth)}
+	}
+}
+
+// encodeVLLength encodes a variable length prefix
+func encodeVLLength(length int) []byte {
+	switch {
+	case length <= 192:
+		return []byte{byte(length)}
+	case length <= 12480:
+		length -= 193
+		return []byte{byte(193 + (length >> 8)), byte(length & 0xFF)}
+	default:
+		length -= 12481
+		return []byte{byte(241 + (length >> 16)), byte((length >> 8) & 0xFF), byte(length & 0xFF)}
+	}
+}
+
+// encodeValue serializes a single field value
+func encodeValue(name string, def fieldDefinition, value interface{}, signingOnly bool) ([]byte, error) {
+	switch def.typeCode {
+	case typeUInt8, typeUInt16, typeUInt32, typeUInt64:
+		return encodeUInt(name, def.typeCode, value)
+	case typeHash128, typeHash160, typeHash256:
+		return encodeHash(def.typeCode, value)
+	case typeAmount:
+		return encodeAmount(value)
+	case typeBlob:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected hex string, got %T", value)
+		}
+		return hex.DecodeString(str)
+	case typeAccountID:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected address string, got %T", value)
+		}
+		return DecodeAccountID(str)
+	case typeSTObject:
+		object, ok := asObject(value)
+		if !ok {
+			return nil, fmt.Errorf("expected object, got %T", value)
+		}
+		return encodeObject(object, signingOnly)
+	case typeSTArray:
+		return encodeArray(value, signingOnly)
+	case typePathSet:
+		return encodePathSet(value)
+	default:
+		return nil, fmt.Errorf("unsupported field type %d", def.typeCode)
+	}
+}
+
+// encodeArray serializes an array of single-key wrapper objects such as {"Memo": {...}}
+func encodeArray(value interface{}, signingOnly bool) ([]byte, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		if objects, isObjects := value.([]map[string]interface{}); isObjects {
+			for _, object := range objects {
+				items = append(items, object)
+			}
+		} else {
+			return nil, fmt.Errorf("expected array, got %T", value)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, item := range items {
+		wrapper, ok := asObject(item)
+		if !ok || len(wrapper) != 1 {
+			return nil, fmt.Errorf("array elements must be single-key objects")
+		}
+		for name, inner := range wrapper {
+			encoded, err := encodeObject(map[string]interface{}{name: inner}, signingOnly)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// asObject converts supported object representations into a map
+func asObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Transaction:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// encodeUInt serializes an unsigned integer field in big-endian order
+func encodeUInt(name string, typeCode int, value interface{}) ([]byte, error) {
+	if name == "TransactionType" {
+		if typeName, ok := value.(string); ok {
+			code, known := transactionTypes[typeName]
+			if !known {
+				return nil, fmt.Errorf("unsupported transaction type: %s", typeName)
+			}
+			value = uint64(code)
+		}
+	}
+
+	number, err := toUint64(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typeCode {
+	case typeUInt8:
+		if number > 0xFF {
+			return nil, fmt.Errorf("value %d overflows UInt8", number)
+		}
+		return []byte{byte(number)}, nil
+	case typeUInt16:
+		if number > 0xFFFF {
+			return nil, fmt.Errorf("value %d overflows UInt16", number)
+		}
+		buf := make([]byte, 2)
+		binary.BigEndian.PutUint16(buf, uint16(number))
+		return buf, nil
+	case typeUInt32:
+		if number > 0xFFFFFFFF {
+			return nil, fmt.Errorf("value %d overflows UInt32", number)
+		}
+		buf := make([]byte, 4)
+		binary.BigEndian.PutUint32(buf, uint32(number))
+		return buf, nil
+	default:
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, number)
+		return buf, nil
+	}
+}
+
+// toUint64 accepts the numeric representations produced by Go code and encoding/json
+func toUint64(value interface{}) (uint64, error) {
+	switch v := value.(type) {
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case uint:
+		return uint64(v), nil
+	case int:
+		if v < 0 {
+			return 0, fmt.Errorf("negative value %d", v)
+		}
+		return uint64(v), nil
+	case int64:
+		if v < 0 {
+			return 0, fmt.Errorf("negative value %d", v)
+		}
+		return uint64(v), nil
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return 0, fmt.Errorf("invalid integer value %v", v)
+		}
+		return uint64(v), nil
+	case json.Number:
+		return strconv.ParseUint(v.String(), 10, 64)
+	case string:
+		return strconv.ParseUint(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("unsupported integer type %T", value)
+	}
+}
+
+// encodeHash serializes a fixed-length hex hash
+func encodeHash(typeCode int, value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected hex string, got %T", value)
+	}
+	decoded, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := map[int]int{typeHash128: 16, typeHash160: 20, typeHash256: 32}[typeCode]
+	if len(decoded) != expected {
+		return nil, fmt.Errorf("expected %d byte hash, got %d", expected, len(decoded))
+	}
+	return decoded, nil
+}
+
+// encodeAmount serializes an XRP drops string or an issued currency object
+func encodeAmount(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return encodeXRPAmount(v)
+	case json.Number:
+		return encodeXRPAmount(v.String())
+	case Amount:
+		if v.IsNative() {
+			return encodeXRPAmount(v.Value)
+		}
+		return encodeIssuedAmount(v.Value, v.Currency, v.Issuer)
+	default:
+		object, ok := asObject(value)
+		if !ok {
+			return nil, fmt.Errorf("unsupported amount type %T", value)
+		}
+		currency, _ := object["currency"].(string)
+		issuer, _ := object["issuer"].(string)
+		amountValue, _ := object["value"].(string)
+		return encodeIssuedAmount(amountValue, currency, issuer)
+	}
+}
+
+// encodeXRPAmount serializes a drops amount with the positive bit set
+func encodeXRPAmount(drops string) ([]byte, error) {
+	value, err := strconv.ParseUint(drops, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XRP drops amount %q", drops)
+	}
+	if value > 100000000000000000 {
+		return nil, fmt.Errorf("XRP amount %s exceeds the maximum supply", drops)
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, value|0x4000000000000000)
+	return buf, nil
+}
+
+// Issued currency amounts carry a 54-bit mantissa normalized to 16 significant digits
+var (
+	minIssuedMantissa = big.NewInt(1000000000000000)
+	maxIssuedMantissa = big.NewInt(9999999999999999)
+	bigTen            = big.NewInt(10)
+)
+
+const (
+	minIssuedExponent = -96
+	maxIssuedExponent = 80
+)
+
+// encodeIssuedAmount serializes value, currency code and issuer
+func encodeIssuedAmount(value, currency, issuer string) ([]byte, error) {
+	amountBytes, err := encodeIssuedValue(value)
+	if err != nil {
+		return nil, err
+	}
+	currencyBytes, err := EncodeCurrencyCode(currency)
+	if err != nil {
+		return nil, err
+	}
+	issuerBytes, err := DecodeAccountID(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer: %w", err)
+	}
+
+	encoded := make([]byte, 0, 48)
+	encoded = append(encoded, amountBytes...)
+	encoded = append(encoded, currencyBytes...)
+	return append(encoded, issuerBytes...), nil
+}
+
+// encodeIssuedValue serializes the decimal value of an issued currency amount
+func encodeIssuedValue(value string) ([]byte, error) {
+	mantissa, exponent, negative, err := parseDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 8)
+	if mantissa.Sign() == 0 {
+		binary.BigEndian.PutUint64(buf, 0x8000000000000000)
+		return buf, nil
+	}
+
+	for mantissa.Cmp(minIssuedMantissa) < 0 {
+		mantissa.Mul(mantissa, bigTen)
+		exponent--
+	}
+	for mantissa.Cmp(maxIssuedMantissa) > 0 {
+		remainder := new(big.Int)
+		mantissa.QuoRem(mantissa, bigTen, remainder)
+		if remainder.Sign() != 0 {
+			return nil, fmt.Errorf("amount %s has more than 16 significant digits", value)
+		}
+		exponent++
+	}
+
+	if exponent < minIssuedExponent || exponent > maxIssuedExponent {
+		return nil, fmt.Errorf("amount %s is out of range", value)
+	}
+
+	word := uint64(0x8000000000000000)
+	if !negative {
+		word |= 0x4000000000000000
+	}
+	word |= uint64(exponent+97) << 54
+	word |= mantissa.Uint64()
+
+	binary.BigEndian.PutUint64(buf, word)
+	return buf, nil
+}
+
+// parseDecimal splits a decimal string such as "-12.5e3" into an integer mantissa and exponent
+func parseDecimal(value string) (*big.Int, int, bool, error) {
+	str := strings.TrimSpace(value)
+	if str == "" {
+		return nil, 0, false, fmt.Errorf("empty amount value")
+	}
+
+	negative := false
+	if str[0] == '-' || str[0] == '+' {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	exponent := 0
+	if idx := strings.IndexAny(str, "eE"); idx >= 0 {
+		exp, err := strconv.Atoi(str[idx+1:])
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("invalid amount value %q", value)
+		}
+		exponent = exp
+		str = str[:idx]
+	}
+
+	integerPart, fractionPart := str, ""
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		integerPart, fractionPart = str[:idx], str[idx+1:]
+	}
+	digits := strings.TrimLeft(integerPart+fractionPart, "0")
+	exponent -= len(fractionPart)
+
+	if digits == "" {
+		if integerPart+fractionPart == "" {
+			return nil, 0, false, fmt.Errorf("invalid amount value %q", value)
+		}
+		return new(big.Int), 0, false, nil
+	}
+
+	mantissa, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, 0, false, fmt.Errorf("invalid amount value %q", value)
+	}
+	return mantissa, exponent, negative, nil
+}
+
+// EncodeCurrencyCode converts a three-letter code or a 40-character hex code into its 160-bit form
+func EncodeCurrencyCode(currency string) ([]byte, error) {
+	switch len(currency) {
+	case 3:
+		if currency == "XRP" {
+			return nil, fmt.Errorf("XRP cannot be used as an issued currency code")
+		}
+		code := make([]byte, 20)
+		copy(code[12:], currency)
+		return code, nil
+	case 40:
+		code, err := hex.DecodeString(currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex currency code %s: %w", currency, err)
+		}
+		if code[0] == 0x00 {
+			return nil, fmt.Errorf("hex currency code %s must not start with 0x00", currency)
+		}
+		return code, nil
+	default:
+		return nil, fmt.Errorf("invalid currency code: %s", currency)
+	}
+}
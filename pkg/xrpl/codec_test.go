@@ -0,0 +1,244 @@
+package xrpl
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	genesisSeed    = "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"
+	genesisAccount = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+)
+
+func TestEncodeTransaction_CanonicalOrder(t *testing.T) {
+	tx := Transaction{
+		"Destination":     testAccount,
+		"Account":         genesisAccount,
+		"Fee":             "10",
+		"Amount":          "1000000",
+		"Sequence":        uint32(1),
+		"Flags":           uint32(0x80000000),
+		"TransactionType": "Payment",
+	}
+
+	encoded, err := EncodeTransactionHex(tx)
+	require.NoError(t, err)
+
+	accountID, err := DecodeAccountID(genesisAccount)
+	require.NoError(t, err)
+	destinationID, err := DecodeAccountID(testAccount)
+	require.NoError(t, err)
+
+	expected := "120000" + // TransactionType Payment
+		"2280000000" + // Flags tfFullyCanonicalSig
+		"2400000001" + // Sequence 1
+		"6140000000000F4240" + // Amount 1 XRP
+		"68400000000000000A" + // Fee 10 drops
+		"8114" + strings.ToUpper(hex.EncodeToString(accountID)) +
+		"8314" + strings.ToUpper(hex.EncodeToString(destinationID))
+	assert.Equal(t, expected, encoded)
+}
+
+func TestEncodeTransaction_FieldHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeCode int
+		nth      int
+		expected string
+	}{
+		{"both small", typeUInt32, 4, "24"},
+		{"large field", typeUInt32, 36, "2024"},
+		{"large type", typeUInt8, 1, "0110"},
+		{"both large", typeUInt8, 16, "001010"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, strings.ToUpper(hex.EncodeToString(encodeFieldID(tt.typeCode, tt.nth))))
+		})
+	}
+
+	assert.Equal(t, []byte{192}, encodeVLLength(192))
+	assert.Equal(t, []byte{193, 0}, encodeVLLength(193))
+	assert.Equal(t, []byte{240, 255}, encodeVLLength(12480))
+	assert.Equal(t, []byte{241, 0, 0}, encodeVLLength(12481))
+}
+
+func TestEncodeAmount_IssuedCurrency(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"1", "D4838D7EA4C68000"},
+		{"0", "8000000000000000"},
+		{"-1", "94838D7EA4C68000"},
+		{"1.5", "D485543DF729C000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			encoded, err := encodeIssuedValue(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.ToUpper(hex.EncodeToString(encoded)))
+		})
+	}
+
+	_, err := encodeIssuedValue("1.00000000000000001")
+	assert.Error(t, err)
+
+	amount, err := encodeAmount(map[string]interface{}{"currency": "USD", "issuer": genesisAccount, "value": "1"})
+	require.NoError(t, err)
+	assert.Len(t, amount, 48)
+	assert.Equal(t, []byte("USD"), amount[20:23])
+
+	_, err = EncodeCurrencyCode("XRP")
+	assert.Error(t, err)
+	code, err := EncodeCurrencyCode("0158415500000000C1F76FF6ECB0BAC600000000")
+	require.NoError(t, err)
+	assert.Len(t, code, 20)
+}
+
+func TestEncodeTransaction_SupportedTypes(t *testing.T) {
+	transactions := []Transaction{
+		{
+			"TransactionType": "EscrowCreate",
+			"Account":         genesisAccount,
+			"Destination":     testAccount,
+			"Amount":          "5000000",
+			"FinishAfter":     uint32(800000000),
+			"CancelAfter":     uint32(800086400),
+			"Condition":       "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
+		},
+		{
+			"TransactionType": "EscrowFinish",
+			"Account":         testAccount,
+			"Owner":           genesisAccount,
+			"OfferSequence":   uint32(7),
+			"Condition":       "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
+			"Fulfillment":     "A0028000",
+		},
+		{
+			"TransactionType": "EscrowCancel",
+			"Account":         genesisAccount,
+			"Owner":           genesisAccount,
+			"OfferSequence":   uint32(7),
+		},
+		{
+			"TransactionType": "TrustSet",
+			"Account":         testAccount,
+			"LimitAmount":     map[string]interface{}{"currency": "USD", "issuer": genesisAccount, "value": "1000"},
+			"Flags":           uint32(0x00020000),
+		},
+		{
+			"TransactionType": "SignerListSet",
+			"Account":         genesisAccount,
+			"SignerQuorum":    uint32(2),
+			"SignerEntries": []interface{}{
+				map[string]interface{}{"SignerEntry": map[string]interface{}{"Account": testAccount, "SignerWeight": 1}},
+				map[string]interface{}{"SignerEntry": map[string]interface{}{"Account": "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY", "SignerWeight": 1}},
+			},
+		},
+		{
+			"TransactionType": "AccountSet",
+			"Account":         genesisAccount,
+			"SetFlag":         uint32(8),
+			"Domain":          hex.EncodeToString([]byte("example.com")),
+		},
+	}
+
+	for _, tx := range transactions {
+		t.Run(tx["TransactionType"].(string), func(t *testing.T) {
+			tx["Fee"] = "12"
+			tx["Sequence"] = uint32(5)
+
+			encoded, err := EncodeTransaction(tx)
+			require.NoError(t, err)
+			assert.Equal(t, byte(0x12), encoded[0])
+			assert.Equal(t, transactionTypes[tx["TransactionType"].(string)], uint16(encoded[1])<<8|uint16(encoded[2]))
+		})
+	}
+
+	_, err := EncodeTransaction(Transaction{"TransactionType": "Payment", "Bogus": "1"})
+	assert.Error(t, err)
+	_, err = EncodeTransaction(Transaction{"TransactionType": "NFTokenMint"})
+	assert.Error(t, err)
+}
+
+func TestSignTransaction(t *testing.T) {
+	seeds := map[string]string{
+		"secp256k1": genesisSeed,
+		"ed25519":   "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2r",
+	}
+
+	for name, seed := range seeds {
+		t.Run(name, func(t *testing.T) {
+			keyPair, err := DeriveKeyPair(seed)
+			require.NoError(t, err)
+
+			tx, err := TransactionFromStruct("EscrowCancel", &EscrowCancel{
+				Account:       keyPair.Address(),
+				Owner:         keyPair.Address(),
+				OfferSequence: 7,
+			})
+			require.NoError(t, err)
+			tx["Fee"] = "12"
+			tx["Sequence"] = uint32(8)
+
+			signed, err := SignTransaction(tx, keyPair)
+			require.NoError(t, err)
+
+			blob, err := hex.DecodeString(signed.TxBlob)
+			require.NoError(t, err)
+			assert.Equal(t, TransactionHash(blob), signed.Hash)
+			assert.Len(t, signed.Hash, 64)
+
+			// The signature must verify over the transaction as it was signed
+			signedTx := copyTransaction(tx)
+			signedTx["SigningPubKey"] = keyPair.PublicKeyHex()
+			data, err := encodeForSigning(signedTx)
+			require.NoError(t, err)
+
+			signedTx["TxnSignature"] = extractSignature(t, blob, data)
+			signature, err := hex.DecodeString(signedTx["TxnSignature"].(string))
+			require.NoError(t, err)
+			assert.True(t, verifySignature(data, signature, keyPair.PublicKey))
+
+			reencoded, err := EncodeTransaction(signedTx)
+			require.NoError(t, err)
+			assert.Equal(t, blob, reencoded)
+
+			// The caller's transaction is left untouched
+			assert.NotContains(t, tx, "TxnSignature")
+		})
+	}
+}
+
+func TestSignTransaction_WrongAccount(t *testing.T) {
+	keyPair, err := DeriveKeyPair(genesisSeed)
+	require.NoError(t, err)
+
+	_, err = SignTransaction(Transaction{"TransactionType": "Payment", "Account": testAccount}, keyPair)
+	assert.Error(t, err)
+}
+
+// extractSignature recovers the TxnSignature field from a signed blob by
+// finding the bytes that are present in the blob but not in the signing data
+func extractSignature(t *testing.T, blob, signingData []byte) string {
+	t.Helper()
+
+	// TxnSignature (type 7, field 4) is the only field added after signing
+	unsigned := signingData[len(prefixTransactionSign):]
+	for i := range blob {
+		if i >= len(unsigned) || blob[i] != unsigned[i] {
+			require.Equal(t, byte(0x74), blob[i])
+			length := int(blob[i+1])
+			return strings.ToUpper(hex.EncodeToString(blob[i+2 : i+2+length]))
+		}
+	}
+	t.Fatal("signature not found in blob")
+	return ""
+}
@@ -0,0 +1,215 @@
+package xrpl
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PREIMAGE-SHA-256 is type 0 of the crypto-conditions draft, the only type the XRPL accepts
+const (
+	preimageConditionTag   = 0xA0
+	preimageFieldTag       = 0x80
+	fingerprintFieldTag    = 0x80
+	costFieldTag           = 0x81
+	preimageLength         = 32
+	maxFulfillmentLength   = 256
+	escrowFinishFeeUnits   = 33
+	fulfillmentBytesPerFee = 16
+)
+
+var (
+	ErrInvalidCondition   = errors.New("invalid crypto-condition")
+	ErrInvalidFulfillment = errors.New("invalid crypto-condition fulfillment")
+	ErrConditionMismatch  = errors.New("fulfillment does not match condition")
+)
+
+// GeneratePreimage returns a random 32-byte preimage
+func GeneratePreimage() ([]byte, error) {
+	preimage := make([]byte, preimageLength)
+	if _, err := rand.Read(preimage); err != nil {
+		return nil, fmt.Errorf("failed to generate preimage: %w", err)
+	}
+	return preimage, nil
+}
+
+// NewPreimageCondition builds the DER-encoded PREIMAGE-SHA-256 condition and
+// fulfillment for a preimage, both as uppercase hex
+func NewPreimageCondition(preimage []byte) (condition string, fulfillment string, err error) {
+	fulfillmentBytes := encodePreimageFulfillment(preimage)
+	if len(fulfillmentBytes) > maxFulfillmentLength {
+		return "", "", fmt.Errorf("%w: fulfillment exceeds %d bytes", ErrInvalidFulfillment, maxFulfillmentLength)
+	}
+
+	fingerprint := sha256.Sum256(preimage)
+	conditionBytes := encodePreimageCondition(fingerprint[:], len(preimage))
+
+	return strings.ToUpper(hex.EncodeToString(conditionBytes)), strings.ToUpper(hex.EncodeToString(fulfillmentBytes)), nil
+}
+
+// ConditionFromFulfillment derives the condition that a fulfillment satisfies
+func ConditionFromFulfillment(fulfillment string) (string, error) {
+	preimage, err := ParseFulfillment(fulfillment)
+	if err != nil {
+		return "", err
+	}
+	condition, _, err := NewPreimageCondition(preimage)
+	return condition, err
+}
+
+// ValidateFulfillment checks that a fulfillment is well formed and satisfies the condition
+func ValidateFulfillment(condition, fulfillment string) error {
+	fingerprint, cost, err := ParseCondition(condition)
+	if err != nil {
+		return err
+	}
+
+	preimage, err := ParseFulfillment(fulfillment)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(preimage)
+	if !bytes.Equal(digest[:], fingerprint) || cost != len(preimage) {
+		return ErrConditionMismatch
+	}
+	return nil
+}
+
+// ParseCondition decodes a PREIMAGE-SHA-256 condition into its fingerprint and cost
+func ParseCondition(condition string) (fingerprint []byte, cost int, err error) {
+	data, err := hex.DecodeString(condition)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+	}
+
+	body, rest, err := readDER(data, preimageConditionTag)
+	if err != nil || len(rest) != 0 {
+		return nil, 0, fmt.Errorf("%w: expected a PREIMAGE-SHA-256 condition", ErrInvalidCondition)
+	}
+
+	fingerprint, body, err = readDER(body, fingerprintFieldTag)
+	if err != nil || len(fingerprint) != sha256.Size {
+		return nil, 0, fmt.Errorf("%w: malformed fingerprint", ErrInvalidCondition)
+	}
+
+	costBytes, body, err := readDER(body, costFieldTag)
+	if err != nil || len(body) != 0 || len(costBytes) == 0 || len(costBytes) > 2 {
+		return nil, 0, fmt.Errorf("%w: malformed cost", ErrInvalidCondition)
+	}
+	for _, b := range costBytes {
+		cost = cost<<8 | int(b)
+	}
+
+	return fingerprint, cost, nil
+}
+
+// ParseFulfillment decodes a PREIMAGE-SHA-256 fulfillment and returns its preimage
+func ParseFulfillment(fulfillment string) ([]byte, error) {
+	data, err := hex.DecodeString(fulfillment)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFulfillment, err)
+	}
+	if len(data) > maxFulfillmentLength {
+		return nil, fmt.Errorf("%w: fulfillment exceeds %d bytes", ErrInvalidFulfillment, maxFulfillmentLength)
+	}
+
+	body, rest, err := readDER(data, preimageConditionTag)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: expected a PREIMAGE-SHA-256 fulfillment", ErrInvalidFulfillment)
+	}
+
+	preimage, rest, err := readDER(body, preimageFieldTag)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: malformed preimage", ErrInvalidFulfillment)
+	}
+	return preimage, nil
+}
+
+// EscrowFinishFee returns the transaction cost of an EscrowFinish that carries the
+// fulfillment: the base fee times 33 plus one base fee per 16 bytes of fulfillment
+func EscrowFinishFee(baseFeeDrops int64, fulfillment string) (int64, error) {
+	if fulfillment == "" {
+		return baseFeeDrops, nil
+	}
+
+	data, err := hex.DecodeString(fulfillment)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidFulfillment, err)
+	}
+	return baseFeeDrops * int64(escrowFinishFeeUnits+len(data)/fulfillmentBytesPerFee), nil
+}
+
+// encodePreimageFulfillment encodes the fulfillment SEQUENCE { preimage OCTET STRING }
+func encodePreimageFulfillment(preimage []byte) []byte {
+	return encodeDER(preimageConditionTag, encodeDER(preimageFieldTag, preimage))
+}
+
+// encodePreimageCondition encodes the condition SEQUENCE { fingerprint, cost }
+func encodePreimageCondition(fingerprint []byte, cost int) []byte {
+	body := encodeDER(fingerprintFieldTag, fingerprint)
+	body = append(body, encodeDER(costFieldTag, encodeDERUnsigned(cost))...)
+	return encodeDER(preimageConditionTag, body)
+}
+
+// encodeDER writes a tag, a DER length and the content
+func encodeDER(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	switch length := len(content); {
+	case length < 0x80:
+		out = append(out, byte(length))
+	case length <= 0xFF:
+		out = append(out, 0x81, byte(length))
+	default:
+		out = append(out, 0x82, byte(length>>8), byte(length))
+	}
+	return append(out, content...)
+}
+
+// encodeDERUnsigned encodes a non-negative integer with the minimal number of octets
+func encodeDERUnsigned(value int) []byte {
+	if value == 0 {
+		return []byte{0}
+	}
+
+	var out []byte
+	for value > 0 {
+		out = append([]byte{byte(value)}, out...)
+		value >>= 8
+	}
+	// A leading high bit would make the integer negative
+	if out[0]&0x80 != 0 {
+		out = append([]byte{0}, out...)
+	}
+	return out
+}
+
+// readDER reads one element with the expected tag and returns its content and the remaining bytes
+func readDER(data []byte, tag byte) (content []byte, rest []byte, err error) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, nil, fmt.Errorf("expected tag %#x", tag)
+	}
+
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		octets := length & 0x7F
+		if octets == 0 || octets > 2 || len(data) < 2+octets {
+			return nil, nil, fmt.Errorf("unsupported length encoding")
+		}
+		length = 0
+		for _, b := range data[2 : 2+octets] {
+			length = length<<8 | int(b)
+		}
+		offset += octets
+	}
+
+	if len(data) < offset+length {
+		return nil, nil, fmt.Errorf("truncated element")
+	}
+	return data[offset : offset+length], data[offset+length:], nil
+}
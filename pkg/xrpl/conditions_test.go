@@ -0,0 +1,88 @@
+package xrpl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPreimageCondition_KnownVector(t *testing.T) {
+	// Empty-preimage example from the crypto-conditions draft
+	condition, fulfillment, err := NewPreimageCondition([]byte{})
+	require.NoError(t, err)
+	assert.Equal(t, "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100", condition)
+	assert.Equal(t, "A0028000", fulfillment)
+
+	derived, err := ConditionFromFulfillment(fulfillment)
+	require.NoError(t, err)
+	assert.Equal(t, condition, derived)
+}
+
+func TestValidateFulfillment(t *testing.T) {
+	preimage, err := GeneratePreimage()
+	require.NoError(t, err)
+	condition, fulfillment, err := NewPreimageCondition(preimage)
+	require.NoError(t, err)
+
+	assert.NoError(t, ValidateFulfillment(condition, fulfillment))
+
+	parsed, err := ParseFulfillment(fulfillment)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(preimage, parsed))
+
+	fingerprint, cost, err := ParseCondition(condition)
+	require.NoError(t, err)
+	assert.Len(t, fingerprint, 32)
+	assert.Equal(t, 32, cost)
+
+	_, otherFulfillment, err := NewPreimageCondition([]byte("another preimage"))
+	require.NoError(t, err)
+	assert.True(t, errors.Is(ValidateFulfillment(condition, otherFulfillment), ErrConditionMismatch))
+
+	// Legacy plain SHA-256 conditions and raw secrets are rejected
+	assert.True(t, errors.Is(ValidateFulfillment(
+		"d727b4f670ad84dbd93910adffa04850265738249d94ee89ef01a8cd5ac6ca30", fulfillment), ErrInvalidCondition))
+	assert.True(t, errors.Is(ValidateFulfillment(condition, "milestone_secret"), ErrInvalidFulfillment))
+
+	_, _, err = NewPreimageCondition(make([]byte, 300))
+	assert.Error(t, err)
+}
+
+func TestEscrowFinishFee(t *testing.T) {
+	fee, err := EscrowFinishFee(10, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), fee)
+
+	// 4-byte fulfillment: 10 * (33 + 0)
+	fee, err = EscrowFinishFee(10, "A0028000")
+	require.NoError(t, err)
+	assert.Equal(t, int64(330), fee)
+
+	// 36-byte fulfillment for a 32-byte preimage: 10 * (33 + 2)
+	_, fulfillment, err := NewPreimageCondition(make([]byte, 32))
+	require.NoError(t, err)
+	fee, err = EscrowFinishFee(10, fulfillment)
+	require.NoError(t, err)
+	assert.Equal(t, int64(350), fee)
+}
+
+func TestGenerateMilestoneCondition_Finishable(t *testing.T) {
+	client := NewClient("https://s.altnet.rippletest.net:51234", true)
+
+	for _, method := range []string{"oracle", "manual", "hybrid"} {
+		t.Run(method, func(t *testing.T) {
+			condition, fulfillment, err := client.GenerateMilestoneCondition("milestone-1", method,
+				map[string]interface{}{"endpoint": "https://oracle.example.com"})
+			require.NoError(t, err)
+			assert.NoError(t, ValidateFulfillment(condition, fulfillment))
+
+			// Each call uses a fresh preimage
+			again, _, err := client.GenerateMilestoneCondition("milestone-1", method, nil)
+			require.NoError(t, err)
+			assert.NotEqual(t, condition, again)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldNames maps serialized type and field codes back to field names
+var fieldNames = func() map[[2]int]string {
+	names := make(map[[2]int]string, len(fieldDefinitions))
+	for name, def := range fieldDefinitions {
+		names[[2]int{def.typeCode, def.nth}] = name
+	}
+	return names
+}()
+
+// transactionTypeNames maps serialized transaction type codes back to their names
+var transactionTypeNames = func() map[uint64]string {
+	names := make(map[uint64]string, len(transactionTypes))
+	for name, code := range transactionTypes {
+		names[uint64(code)] = name
+	}
+	return names
+}()
+
+// DecodeTransactionHex parses a hex transaction blob into its JSON form
+func DecodeTransactionHex(blob string) (Transaction, error) {
+	data, err := hex.DecodeString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction blob: %w", err)
+	}
+	return DecodeTransaction(data)
+}
+
+// DecodeTransaction parses a serialized transaction into its JSON form. Only the fields
+// the platform encodes are understood.
+func DecodeTransaction(data []byte) (Transaction, error) {
+	decoder := &binaryDecoder{data: data}
+	object, err := decoder.readObject(false)
+	if err != nil {
+		return nil, err
+	}
+	return Transaction(object), nil
+}
+
+// binaryDecoder reads canonical XRPL binary data
+type binaryDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *binaryDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("unexpected end of data at byte %d", d.pos)
+	}
+	out := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return out, nil
+}
+
+func (d *binaryDecoder) readByte() (int, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return int(b[0]), nil
+}
+
+// readFieldID reads the one to three byte field header written by encodeFieldID
+func (d *binaryDecoder) readFieldID() (typeCode, nth int, err error) {
+	first, err := d.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typeCode, nth = first>>4, first&0x0F
+	if typeCode == 0 {
+		if typeCode, err = d.readByte(); err != nil {
+			return 0, 0, err
+		}
+	}
+	if nth == 0 {
+		if nth, err = d.readByte(); err != nil {
+			return 0, 0, err
+		}
+	}
+	return typeCode, nth, nil
+}
+
+// readVLLength reads a variable length prefix written by encodeVLLength
+func (d *binaryDecoder) readVLLength() (int, error) {
+	first, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case first <= 192:
+		return first, nil
+	case first <= 240:
+		second, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		return 193 + (first-193)*256 + second, nil
+	default:
+		rest, err := d.read(2)
+		if err != nil {
+			return 0, err
+		}
+		return 12481 + (first-241)*65536 + int(rest[0])*256 + int(rest[1]), nil
+	}
+}
+
+// readObject reads fields until the data ends or, for inner objects, until the end marker
+func (d *binaryDecoder) readObject(inner bool) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	for d.pos < len(d.data) {
+		if inner && d.data[d.pos] == objectEndMarker {
+			d.pos++
+			return object, nil
+		}
+
+		typeCode, nth, err := d.readFieldID()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := fieldNames[[2]int{typeCode, nth}]
+		if !ok {
+			return nil, fmt.Errorf("unsupported field type %d code %d", typeCode, nth)
+		}
+
+		value, err := d.readValue(name, fieldDefinitions[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		object[name] = value
+	}
+
+	if inner {
+		return nil, fmt.Errorf("object is missing its end marker")
+	}
+	return object, nil
+}
+
+// readValue reads one field value in the representation TransactionFromStruct produces
+func (d *binaryDecoder) readValue(name string, def fieldDefinition) (interface{}, error) {
+	switch def.typeCode {
+	case typeUInt8, typeUInt16, typeUInt32, typeUInt64:
+		size := map[int]int{typeUInt8: 1, typeUInt16: 2, typeUInt32: 4, typeUInt64: 8}[def.typeCode]
+		raw, err := d.read(size)
+		if err != nil {
+			return nil, err
+		}
+		padded := append(make([]byte, 8-size), raw...)
+		number := binary.BigEndian.Uint64(padded)
+		if name == "TransactionType" {
+			typeName, ok := transactionTypeNames[number]
+			if !ok {
+				return nil, fmt.Errorf("unsupported transaction type code %d", number)
+			}
+			return typeName, nil
+		}
+		if def.typeCode == typeUInt64 {
+			return number, nil
+		}
+		return uint32(number), nil
+	case typeHash128, typeHash160, typeHash256:
+		size := map[int]int{typeHash128: 16, typeHash160: 20, typeHash256: 32}[def.typeCode]
+		raw, err := d.read(size)
+		if err != nil {
+			return nil, err
+		}
+		return strings.ToUpper(hex.EncodeToString(raw)), nil
+	case typeAmount:
+		return d.readAmount()
+	case typeBlob, typeAccountID:
+		length, err := d.readVLLength()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		if def.typeCode == typeAccountID {
+			if len(raw) != 20 {
+				return nil, fmt.Errorf("account ID must be 20 bytes, got %d", len(raw))
+			}
+			return EncodeAccountID(raw), nil
+		}
+		return strings.ToUpper(hex.EncodeToString(raw)), nil
+	case typeSTObject:
+		return d.readObject(true)
+	case typeSTArray:
+		return d.readArray()
+	case typePathSet:
+		return d.readPathSet()
+	default:
+		return nil, fmt.Errorf("unsupported field type %d", def.typeCode)
+	}
+}
+
+// readArray reads single-key wrapper objects until the array end marker
+func (d *binaryDecoder) readArray() ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		if d.pos >= len(d.data) {
+			return nil, fmt.Errorf("array is missing its end marker")
+		}
+		if d.data[d.pos] == arrayEndMarker {
+			d.pos++
+			return items, nil
+		}
+
+		typeCode, nth, err := d.readFieldID()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := fieldNames[[2]int{typeCode, nth}]
+		if !ok || typeCode != typeSTObject {
+			return nil, fmt.Errorf("unsupported array element type %d code %d", typeCode, nth)
+		}
+		inner, err := d.readObject(true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, map[string]interface{}{name: inner})
+	}
+}
+
+// readAmount reads an XRP drops amount as a string or an issued amount as an object
+func (d *binaryDecoder) readAmount() (interface{}, error) {
+	raw, err := d.read(8)
+	if err != nil {
+		return nil, err
+	}
+	word := binary.BigEndian.Uint64(raw)
+
+	if word&0x8000000000000000 == 0 {
+		if word&0x4000000000000000 == 0 && word != 0 {
+			return nil, fmt.Errorf("negative XRP amounts are not supported")
+		}
+		return strconv.FormatUint(word&0x3FFFFFFFFFFFFFFF, 10), nil
+	}
+
+	currencyBytes, err := d.read(20)
+	if err != nil {
+		return nil, err
+	}
+	issuerBytes, err := d.read(20)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"currency": decodeCurrencyCode(currencyBytes),
+		"issuer":   EncodeAccountID(issuerBytes),
+		"value":    decodeIssuedValue(word),
+	}, nil
+}
+
+// decodeIssuedValue formats the mantissa and exponent of an issued amount as a decimal string
+func decodeIssuedValue(word uint64) string {
+	mantissa := word & 0x003FFFFFFFFFFFFF
+	if mantissa == 0 {
+		return "0"
+	}
+	exponent := int((word>>54)&0xFF) - 97
+	negative := word&0x4000000000000000 == 0
+
+	digits := strconv.FormatUint(mantissa, 10)
+	for strings.HasSuffix(digits, "0") {
+		digits = digits[:len(digits)-1]
+		exponent++
+	}
+
+	var value string
+	switch {
+	case exponent >= 0:
+		value = digits + strings.Repeat("0", exponent)
+	case -exponent >= len(digits):
+		value = "0." + strings.Repeat("0", -exponent-len(digits)) + digits
+	default:
+		split := len(digits) + exponent
+		value = digits[:split] + "." + digits[split:]
+	}
+	if negative {
+		value = "-" + value
+	}
+	return value
+}
+
+// decodeCurrencyCode returns a standard three-letter code or the 40-character hex code
+func decodeCurrencyCode(code []byte) string {
+	standard := bytes.Equal(code[:12], make([]byte, 12)) && bytes.Equal(code[15:], make([]byte, 5))
+	if standard && code[12] != 0 {
+		return string(code[12:15])
+	}
+	return strings.ToUpper(hex.EncodeToString(code))
+}
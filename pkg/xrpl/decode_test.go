@@ -0,0 +1,142 @@
+package xrpl
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTransaction_RoundTrip(t *testing.T) {
+	keyPair, err := DeriveKeyPair(genesisSeed)
+	require.NoError(t, err)
+
+	tx := Transaction{
+		"TransactionType":    "EscrowCreate",
+		"Account":            genesisAccount,
+		"Destination":        testAccount,
+		"Amount":             map[string]interface{}{"currency": "USD", "issuer": testAccount, "value": "-0.0125"},
+		"Fee":                "12",
+		"Flags":              uint32(0),
+		"Sequence":           uint32(5),
+		"LastLedgerSequence": uint32(90020),
+		"FinishAfter":        uint32(800000000),
+		"Condition":          "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
+		"Memos": []interface{}{
+			map[string]interface{}{"Memo": map[string]interface{}{"MemoData": "CAFE"}},
+		},
+	}
+	signed, err := SignTransaction(tx, keyPair)
+	require.NoError(t, err)
+
+	decoded, err := DecodeTransactionHex(signed.TxBlob)
+	require.NoError(t, err)
+	assert.Equal(t, "EscrowCreate", decoded["TransactionType"])
+	assert.Equal(t, testAccount, decoded["Destination"])
+	assert.Equal(t, uint32(90020), decoded["LastLedgerSequence"])
+	assert.Equal(t, map[string]interface{}{"currency": "USD", "issuer": testAccount, "value": "-0.0125"}, decoded["Amount"])
+	assert.Equal(t, keyPair.PublicKeyHex(), decoded["SigningPubKey"])
+	require.NoError(t, VerifyTransaction(decoded))
+
+	// Re-encoding the decoded form reproduces the signed blob
+	reencoded, err := EncodeTransactionHex(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, signed.TxBlob, reencoded)
+
+	decoded["Sequence"] = uint32(6)
+	assert.Error(t, VerifyTransaction(decoded))
+}
+
+func TestDecodeTransaction_PathSetRoundTrip(t *testing.T) {
+	sendMax := XRPAmount(1500000)
+	payment := &Payment{
+		Account:     genesisAccount,
+		Destination: genesisAccount,
+		Amount:      Amount{Currency: "USD", Issuer: testAccount, Value: "10"},
+		SendMax:     &sendMax,
+		Paths: []Path{
+			{{Currency: "USD", Issuer: testAccount}},
+			{{Account: testAccount}, {Currency: "XRP"}, {Currency: "USD", Issuer: testAccount}},
+		},
+		Flags: PaymentFlagPartialPayment,
+	}
+	tx, err := TransactionFromStruct("Payment", payment)
+	require.NoError(t, err)
+	tx["Sequence"] = uint32(1)
+
+	blob, err := EncodeTransaction(tx)
+	require.NoError(t, err)
+	decoded, err := DecodeTransaction(blob)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		[]interface{}{map[string]interface{}{"currency": "USD", "issuer": testAccount}},
+		[]interface{}{
+			map[string]interface{}{"account": testAccount},
+			map[string]interface{}{"currency": "XRP"},
+			map[string]interface{}{"currency": "USD", "issuer": testAccount},
+		},
+	}, decoded["Paths"])
+	assert.Equal(t, "1500000", decoded["SendMax"])
+	assert.Equal(t, uint32(PaymentFlagPartialPayment), decoded["Flags"])
+
+	reencoded, err := EncodeTransaction(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, blob, reencoded)
+
+	_, err = EncodeTransaction(Transaction{"TransactionType": "Payment", "Paths": []interface{}{[]interface{}{map[string]interface{}{}}}})
+	assert.Error(t, err, "a path step must name an account, currency or issuer")
+}
+
+func TestDecodeTransaction_Invalid(t *testing.T) {
+	_, err := DecodeTransactionHex("zz")
+	assert.Error(t, err)
+
+	blob, err := EncodeTransaction(Transaction{"TransactionType": "Payment", "Account": genesisAccount, "Amount": "1000"})
+	require.NoError(t, err)
+	_, err = DecodeTransaction(blob[:len(blob)-3])
+	assert.Error(t, err)
+
+	_, err = DecodeTransaction([]byte{0x12, 0x00, 0x63})
+	assert.Error(t, err, "unknown transaction type")
+}
+
+func TestDecodeIssuedValue(t *testing.T) {
+	values := map[string]string{
+		"1":         "1",
+		"1000":      "1000",
+		"0.000001":  "0.000001",
+		"1234.5678": "1234.5678",
+		"-42":       "-42",
+		"15e3":      "15000",
+		"0":         "0",
+	}
+	for value, expected := range values {
+		encoded, err := encodeIssuedValue(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, decodeIssuedValue(binary.BigEndian.Uint64(encoded)), value)
+	}
+}
+
+func TestLedgerEntryIndexes(t *testing.T) {
+	index, err := AccountRootIndex(genesisAccount)
+	require.NoError(t, err)
+	assert.Equal(t, "2B6AC232AA4C4BE41BF49D2459FA4A0347E1B543A4C92FCEE0821C0201E2E9A8", index)
+
+	first, err := EscrowIndex(genesisAccount, 7)
+	require.NoError(t, err)
+	second, err := EscrowIndex(genesisAccount, 8)
+	require.NoError(t, err)
+	assert.Len(t, first, 64)
+	assert.NotEqual(t, first, second)
+
+	// Trust line IDs do not depend on which side is asked about
+	forward, err := RippleStateIndex(genesisAccount, testAccount, "USD")
+	require.NoError(t, err)
+	backward, err := RippleStateIndex(testAccount, genesisAccount, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, forward, backward)
+
+	_, err = EscrowIndex("not-an-address", 1)
+	assert.Error(t, err)
+}
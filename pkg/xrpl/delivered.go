@@ -0,0 +1,169 @@
+package xrpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// DeliveredAmountUnavailable is the delivered_amount of payments validated before the ledger
+// started recording it in January 2014
+const DeliveredAmountUnavailable = "unavailable"
+
+// PaymentDelivery compares what a validated payment declared in its Amount field with what the
+// ledger actually delivered to its destination
+type PaymentDelivery struct {
+	TransactionID string `json:"transaction_id"`
+	Account       string `json:"account"`
+	Destination   string `json:"destination"`
+	LedgerIndex   uint32 `json:"ledger_index"`
+	ResultCode    string `json:"result_code"`
+	Amount        Amount `json:"amount"`
+	Delivered     Amount `json:"delivered"`
+	// PartialPayment is set when the payment carried tfPartialPayment, which lets it deliver less
+	// than Amount
+	PartialPayment bool `json:"partial_payment"`
+	// DestinationTag is nil when the payment carried no tag
+	DestinationTag *uint32 `json:"destination_tag,omitempty"`
+}
+
+// Short reports whether the payment delivered less than it declared
+func (d *PaymentDelivery) Short() bool {
+	declared, ok := new(big.Rat).SetString(d.Amount.Value)
+	delivered, deliveredOK := new(big.Rat).SetString(d.Delivered.Value)
+	if !ok || !deliveredOK {
+		return d.Delivered != d.Amount
+	}
+	return delivered.Cmp(declared) < 0
+}
+
+// IsPartialPayment reports whether a transaction's Flags carry tfPartialPayment
+func IsPartialPayment(tx map[string]interface{}) bool {
+	var flags uint64
+	switch v := tx["Flags"].(type) {
+	case float64:
+		flags = uint64(v)
+	case uint32:
+		flags = uint64(v)
+	case json.Number:
+		parsed, err := v.Int64()
+		if err != nil {
+			return false
+		}
+		flags = uint64(parsed)
+	}
+	return flags&PaymentFlagPartialPayment != 0
+}
+
+// DeliveredAmount returns what a validated payment delivered to its destination. It reads the
+// metadata's delivered_amount and only falls back to Amount for payments without
+// tfPartialPayment, whose Amount is always delivered in full.
+func DeliveredAmount(tx map[string]interface{}, meta *TransactionMeta) (Amount, error) {
+	if meta != nil && meta.DeliveredAmount != nil && meta.DeliveredAmount.Value != DeliveredAmountUnavailable {
+		return *meta.DeliveredAmount, nil
+	}
+	if IsPartialPayment(tx) {
+		hash, _ := tx["hash"].(string)
+		return Amount{}, fmt.Errorf("payment %s: %w", hash, ErrDeliveredAmountUnavailable)
+	}
+	return amountField(tx, "Amount")
+}
+
+// Delivery reads what a validated Payment event declared and delivered
+func (e *TransactionEvent) Delivery() (*PaymentDelivery, error) {
+	if e.TransactionType != "Payment" {
+		return nil, fmt.Errorf("transaction %s is a %s, not a Payment", e.Hash, e.TransactionType)
+	}
+	return paymentDelivery(e.Hash, e.LedgerIndex, e.Transaction, e.Meta)
+}
+
+// GetPaymentDelivery looks up a validated payment and what it actually delivered
+func (c *Client) GetPaymentDelivery(hash string) (*PaymentDelivery, error) {
+	if hash == "" {
+		return nil, fmt.Errorf("transaction hash cannot be empty")
+	}
+	if c.simulated() {
+		return nil, fmt.Errorf("payment %s: %w", hash, ErrTransactionNotFound)
+	}
+
+	var result map[string]interface{}
+	if err := c.call("tx", map[string]interface{}{"transaction": hash, "binary": false}, &result); err != nil {
+		return nil, err
+	}
+	if validated, _ := result["validated"].(bool); !validated {
+		return nil, fmt.Errorf("payment %s is not validated yet", hash)
+	}
+	if result["TransactionType"] != "Payment" {
+		return nil, fmt.Errorf("transaction %s is a %v, not a Payment", hash, result["TransactionType"])
+	}
+
+	var meta *TransactionMeta
+	if raw, err := json.Marshal(result["meta"]); err == nil {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, fmt.Errorf("invalid metadata for payment %s: %w", hash, err)
+		}
+	}
+	ledgerIndex, _ := result["ledger_index"].(float64)
+	return paymentDelivery(hash, uint32(ledgerIndex), result, meta)
+}
+
+// paymentDelivery builds a PaymentDelivery from a payment's JSON fields and metadata
+func paymentDelivery(hash string, ledgerIndex uint32, tx map[string]interface{}, meta *TransactionMeta) (*PaymentDelivery, error) {
+	amount, err := amountField(tx, "Amount")
+	if err != nil {
+		return nil, err
+	}
+	delivered, err := DeliveredAmount(tx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &PaymentDelivery{
+		TransactionID:  hash,
+		LedgerIndex:    ledgerIndex,
+		Amount:         amount,
+		Delivered:      delivered,
+		PartialPayment: IsPartialPayment(tx),
+	}
+	delivery.Account, _ = tx["Account"].(string)
+	delivery.Destination, _ = tx["Destination"].(string)
+	if tag, ok := uint32Field(tx, "DestinationTag"); ok {
+		delivery.DestinationTag = &tag
+	}
+	if meta != nil {
+		delivery.ResultCode = meta.TransactionResult
+	}
+	return delivery, nil
+}
+
+// uint32Field reads an unsigned integer field of a transaction's JSON form
+func uint32Field(tx map[string]interface{}, field string) (uint32, bool) {
+	switch v := tx[field].(type) {
+	case float64:
+		return uint32(v), true
+	case uint32:
+		return v, true
+	case json.Number:
+		parsed, err := strconv.ParseUint(v.String(), 10, 32)
+		return uint32(parsed), err == nil
+	}
+	return 0, false
+}
+
+// amountField decodes an amount field of a transaction's JSON form
+func amountField(tx map[string]interface{}, field string) (Amount, error) {
+	raw, ok := tx[field]
+	if !ok {
+		return Amount{}, fmt.Errorf("transaction has no %s", field)
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return Amount{}, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	var amount Amount
+	if err := json.Unmarshal(encoded, &amount); err != nil {
+		return Amount{}, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return amount, nil
+}
@@ -0,0 +1,75 @@
+package xrpl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeDeliveryFixture(t *testing.T, fixture string) (map[string]interface{}, *TransactionMeta) {
+	t.Helper()
+	var message struct {
+		Transaction map[string]interface{} `json:"transaction"`
+		Meta        *TransactionMeta       `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(fixture), &message))
+	return message.Transaction, message.Meta
+}
+
+func TestDeliveredAmount_PartialPaymentUsesMetadata(t *testing.T) {
+	tx, meta := decodeDeliveryFixture(t, `{
+		"transaction": {
+			"TransactionType": "Payment",
+			"Account": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe",
+			"Destination": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+			"Amount": {"currency": "USD", "issuer": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn", "value": "1000000"},
+			"Flags": 131072,
+			"hash": "C53ECF838647FA5A4C780377025FEC7999AB4182590510CA461444B207AB74A9"
+		},
+		"meta": {
+			"TransactionResult": "tesSUCCESS",
+			"AffectedNodes": [],
+			"delivered_amount": {"currency": "USD", "issuer": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn", "value": "0.01"}
+		}
+	}`)
+	assert.True(t, IsPartialPayment(tx))
+
+	delivered, err := DeliveredAmount(tx, meta)
+	require.NoError(t, err)
+	assert.Equal(t, "0.01", delivered.Value)
+
+	delivery, err := paymentDelivery("C53ECF83", 7, tx, meta)
+	require.NoError(t, err)
+	assert.True(t, delivery.PartialPayment)
+	assert.True(t, delivery.Short())
+	assert.Equal(t, "1000000", delivery.Amount.Value)
+
+	// Without a recorded delivered_amount a partial payment's Amount cannot be trusted
+	meta.DeliveredAmount = &Amount{Value: DeliveredAmountUnavailable}
+	_, err = DeliveredAmount(tx, meta)
+	assert.ErrorIs(t, err, ErrDeliveredAmountUnavailable)
+}
+
+func TestDeliveredAmount_FullPaymentFallsBackToAmount(t *testing.T) {
+	tx, meta := decodeDeliveryFixture(t, `{
+		"transaction": {
+			"TransactionType": "Payment",
+			"Account": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe",
+			"Destination": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+			"Amount": "25000000",
+			"Flags": 2147483648
+		},
+		"meta": {"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "delivered_amount": "unavailable"}
+	}`)
+	assert.False(t, IsPartialPayment(tx))
+
+	delivered, err := DeliveredAmount(tx, meta)
+	require.NoError(t, err)
+	assert.Equal(t, XRPAmount(25000000), delivered)
+
+	delivery, err := paymentDelivery("A1", 7, tx, meta)
+	require.NoError(t, err)
+	assert.False(t, delivery.Short())
+}
@@ -0,0 +1,113 @@
+package xrpl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Typed errors returned by the JSON-RPC transport. RPCError unwraps to one of
+// these so callers can use errors.Is without inspecting rippled error codes.
+var (
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrEntryNotFound       = errors.New("ledger entry not found")
+	ErrLedgerNotFound      = errors.New("ledger not found")
+	ErrInvalidParams       = errors.New("invalid request parameters")
+	ErrMalformedAccount    = errors.New("malformed account")
+	ErrServerBusy          = errors.New("server too busy")
+	ErrServerNotReady      = errors.New("server not ready")
+	ErrAmendmentBlocked    = errors.New("server is amendment blocked")
+	ErrInternal            = errors.New("internal server error")
+	ErrSigningUnavailable  = errors.New("no signing key provider is configured")
+)
+
+// ErrAmendmentDisabled is returned when a feature needs an amendment the network has not enabled
+var ErrAmendmentDisabled = errors.New("required amendment is not enabled on this network")
+
+// ErrNoPath is returned when path finding cannot find a way to deliver an amount
+var ErrNoPath = errors.New("no payment path found")
+
+// ErrDeliveredAmountUnavailable is returned when a partial payment was validated before the ledger
+// recorded delivered_amount, so what it delivered cannot be read from the transaction
+var ErrDeliveredAmountUnavailable = errors.New("delivered amount unavailable for partial payment")
+
+// ErrTransactionExpired is returned when a transaction's LastLedgerSequence passes before it is validated
+var ErrTransactionExpired = errors.New("transaction expired before it was validated")
+
+// ErrInsufficientReserve is returned when an account cannot cover an amount on top of its reserve
+var ErrInsufficientReserve = errors.New("insufficient XRP above the account reserve")
+
+// ErrAccountNotDeletable is returned when an account cannot be deleted yet or still owns obligations
+var ErrAccountNotDeletable = errors.New("account cannot be deleted")
+
+// ErrInvalidClaim is returned when a payment channel claim's signature does not authorize its amount
+var ErrInvalidClaim = errors.New("invalid payment channel claim")
+
+// ErrProvisionalResult matches a submission the server did not apply whose preliminary result is
+// not final: the transaction may still be validated, with that or another result, until its
+// LastLedgerSequence passes. Its sequence stays held until then.
+var ErrProvisionalResult = errors.New("transaction was not applied but may still be validated")
+
+// rpcErrorCodes maps rippled error tokens to their typed errors
+var rpcErrorCodes = map[string]error{
+	"actNotFound":         ErrAccountNotFound,
+	"srcActNotFound":      ErrAccountNotFound,
+	"txnNotFound":         ErrTransactionNotFound,
+	"entryNotFound":       ErrEntryNotFound,
+	"objectNotFound":      ErrEntryNotFound,
+	"lgrNotFound":         ErrLedgerNotFound,
+	"lgrIdxsInvalid":      ErrLedgerNotFound,
+	"invalidParams":       ErrInvalidParams,
+	"invalid_API_version": ErrInvalidParams,
+	"actMalformed":        ErrMalformedAccount,
+	"srcActMalformed":     ErrMalformedAccount,
+	"dstActMalformed":     ErrMalformedAccount,
+	"tooBusy":             ErrServerBusy,
+	"slowDown":            ErrServerBusy,
+	"noNetwork":           ErrServerNotReady,
+	"noCurrent":           ErrServerNotReady,
+	"noClosed":            ErrServerNotReady,
+	"notReady":            ErrServerNotReady,
+	"notSynced":           ErrServerNotReady,
+	"amendmentBlocked":    ErrAmendmentBlocked,
+	"internal":            ErrInternal,
+}
+
+// RPCError is an error reported by rippled in a JSON-RPC response
+type RPCError struct {
+	Method    string `json:"method"`
+	Code      string `json:"error"`
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"error_message"`
+}
+
+func (e *RPCError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("xrpl %s failed: %s (%s)", e.Method, e.Message, e.Code)
+	}
+	return fmt.Sprintf("xrpl %s failed: %s", e.Method, e.Code)
+}
+
+// Unwrap returns the typed error for the rippled error code, if one is known
+func (e *RPCError) Unwrap() error {
+	return rpcErrorCodes[e.Code]
+}
+
+// TransactionError is returned when rippled does not accept a submitted transaction, or a validated
+// ledger recorded it with a failure result
+type TransactionError struct {
+	TransactionID string `json:"transaction_id"`
+	Code          string `json:"engine_result"`
+	Message       string `json:"engine_result_message"`
+	// Provisional is set for a preliminary result that may still change before validation
+	Provisional bool `json:"provisional,omitempty"`
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction %s rejected: %s (%s)", e.TransactionID, e.Code, e.Message)
+}
+
+// Is matches ErrProvisionalResult while the rejection is only provisional
+func (e *TransactionError) Is(target error) bool {
+	return target == ErrProvisionalResult && e.Provisional
+}
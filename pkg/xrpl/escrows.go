@@ -0,0 +1,208 @@
+package xrpl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EscrowResolution describes the validated transaction that removed an escrow from the ledger
+type EscrowResolution struct {
+	TransactionID   string `json:"transaction_id"`
+	TransactionType string `json:"transaction_type"`
+	Account         string `json:"account"`
+	LedgerIndex     uint32 `json:"ledger_index"`
+	ResultCode      string `json:"result_code"`
+}
+
+// Finished reports whether the escrow was released to its destination rather than returned to its owner
+func (r *EscrowResolution) Finished() bool {
+	return r.TransactionType == "EscrowFinish"
+}
+
+// accountTxEntry is one transaction of an account_tx page; API version 2 sends tx_json and the
+// hash alongside it instead of tx
+type accountTxEntry struct {
+	Tx          map[string]interface{} `json:"tx"`
+	TxJSON      map[string]interface{} `json:"tx_json"`
+	Hash        string                 `json:"hash"`
+	LedgerIndex uint32                 `json:"ledger_index"`
+	Meta        *TransactionMeta       `json:"meta"`
+	Validated   bool                   `json:"validated"`
+}
+
+// ListEscrows lists every escrow an account owns in the validated ledger, following
+// account_objects markers until the last page
+func (c *Client) ListEscrows(owner string) ([]EscrowInfo, error) {
+	if !c.ValidateAddress(owner) {
+		return nil, fmt.Errorf("invalid owner address: %s", owner)
+	}
+
+	if c.simulated() {
+		return []EscrowInfo{}, nil
+	}
+
+	escrows := []EscrowInfo{}
+	var marker interface{}
+	for {
+		var result struct {
+			AccountObjects []EscrowInfo `json:"account_objects"`
+			Marker         interface{}  `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":      owner,
+			"type":         "escrow",
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_objects", params, &result); err != nil {
+			return nil, err
+		}
+
+		for _, escrow := range result.AccountObjects {
+			// Escrow entries are never modified, so the transaction that last touched one created it
+			sequence, err := c.escrowSequence(escrow.PreviousTxnID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to identify escrow %s: %w", escrow.Index, err)
+			}
+			escrow.Sequence = sequence
+			escrows = append(escrows, escrow)
+		}
+		if result.Marker == nil {
+			sort.Slice(escrows, func(i, j int) bool { return escrows[i].Sequence < escrows[j].Sequence })
+			return escrows, nil
+		}
+		marker = result.Marker
+	}
+}
+
+// FindEscrowResolution searches the owner's validated history from sinceLedger onwards for the
+// transaction that finished or cancelled an escrow. It returns ErrEntryNotFound when no validated
+// transaction has removed the escrow.
+func (c *Client) FindEscrowResolution(owner string, sequence, sinceLedger uint32) (*EscrowResolution, error) {
+	index, err := EscrowIndex(owner, sequence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner address: %s", owner)
+	}
+
+	if c.simulated() {
+		return nil, fmt.Errorf("escrow %s:%d has no recorded resolution: %w", owner, sequence, ErrEntryNotFound)
+	}
+
+	entry, err := c.findDeletion(owner, "Escrow", index, sinceLedger)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("escrow %s:%d was not removed by a validated transaction: %w", owner, sequence, ErrEntryNotFound)
+	}
+
+	tx := entry.transaction()
+	resolution := &EscrowResolution{
+		TransactionID: entry.transactionID(),
+		LedgerIndex:   entry.validatedIn(),
+		ResultCode:    entry.Meta.TransactionResult,
+	}
+	resolution.TransactionType, _ = tx["TransactionType"].(string)
+	resolution.Account, _ = tx["Account"].(string)
+	return resolution, nil
+}
+
+// findDeletion walks account's validated history from sinceLedger onwards for the transaction that
+// deleted the ledger entry of entryType with the given index. It returns nil when none did.
+func (c *Client) findDeletion(account, entryType, index string, sinceLedger uint32) (*accountTxEntry, error) {
+	var marker interface{}
+	for {
+		var result struct {
+			Transactions []accountTxEntry `json:"transactions"`
+			Marker       interface{}      `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":          account,
+			"ledger_index_min": -1,
+			"ledger_index_max": -1,
+			"forward":          true,
+			"binary":           false,
+		}
+		if sinceLedger > 0 {
+			params["ledger_index_min"] = sinceLedger
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_tx", params, &result); err != nil {
+			return nil, err
+		}
+
+		for i := range result.Transactions {
+			if entry := &result.Transactions[i]; entry.deletes(entryType, index) {
+				return entry, nil
+			}
+		}
+		if result.Marker == nil {
+			return nil, nil
+		}
+		marker = result.Marker
+	}
+}
+
+// deletes reports whether this validated transaction deleted the entry with the given type and ledger index
+func (e *accountTxEntry) deletes(entryType, index string) bool {
+	if !e.Validated || e.Meta == nil {
+		return false
+	}
+	for _, node := range e.Meta.AffectedNodes {
+		deleted := node.DeletedNode
+		if deleted != nil && deleted.LedgerEntryType == entryType && strings.EqualFold(deleted.LedgerIndex, index) {
+			return true
+		}
+	}
+	return false
+}
+
+// transaction returns the transaction's fields whichever API version sent them
+func (e *accountTxEntry) transaction() map[string]interface{} {
+	if e.Tx != nil {
+		return e.Tx
+	}
+	return e.TxJSON
+}
+
+// transactionID returns the transaction's hash
+func (e *accountTxEntry) transactionID() string {
+	if hash, _ := e.transaction()["hash"].(string); hash != "" {
+		return hash
+	}
+	return e.Hash
+}
+
+// validatedIn returns the ledger the transaction was validated in
+func (e *accountTxEntry) validatedIn() uint32 {
+	if e.LedgerIndex == 0 {
+		if ledgerIndex, ok := e.transaction()["ledger_index"].(float64); ok {
+			return uint32(ledgerIndex)
+		}
+	}
+	return e.LedgerIndex
+}
+
+// escrowSequence returns the sequence or ticket an EscrowCreate consumed, which identifies its escrow
+func (c *Client) escrowSequence(createHash string) (uint32, error) {
+	var result struct {
+		TransactionType string `json:"TransactionType"`
+		Sequence        uint32 `json:"Sequence"`
+		TicketSequence  uint32 `json:"TicketSequence"`
+	}
+	if err := c.call("tx", map[string]interface{}{"transaction": createHash, "binary": false}, &result); err != nil {
+		return 0, err
+	}
+	if result.TransactionType != "EscrowCreate" {
+		return 0, fmt.Errorf("transaction %s is a %s, not an EscrowCreate", createHash, result.TransactionType)
+	}
+	if result.Sequence == 0 {
+		return result.TicketSequence, nil
+	}
+	return result.Sequence, nil
+}
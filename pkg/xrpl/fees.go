@@ -0,0 +1,102 @@
+package xrpl
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FeeInfo is the transaction cost of the open ledger as reported by the fee command. Fees are in
+// drops for a reference transaction; transactions with a higher base cost pay proportionally more.
+type FeeInfo struct {
+	// BaseFee is the reference transaction cost of the validated ledger
+	BaseFee int64
+	// MinimumFee is the least a transaction can pay and still be queued
+	MinimumFee int64
+	// MedianFee is the median fee paid by transactions in recent ledgers
+	MedianFee int64
+	// OpenLedgerFee is the least a transaction can pay to be applied to the open ledger right away
+	OpenLedgerFee int64
+	// CurrentLedgerSize is how many transactions the open ledger holds
+	CurrentLedgerSize uint32
+	// ExpectedLedgerSize is how many transactions the open ledger takes before its cost escalates
+	ExpectedLedgerSize uint32
+	// CurrentQueueSize is how many transactions wait in the queue
+	CurrentQueueSize uint32
+	// LedgerCurrentIndex is the sequence of the open ledger
+	LedgerCurrentIndex uint32
+}
+
+// LoadFactor returns how many times the reference cost the open ledger currently charges
+func (f *FeeInfo) LoadFactor() float64 {
+	if f.BaseFee <= 0 || f.OpenLedgerFee <= f.BaseFee {
+		return 1
+	}
+	return float64(f.OpenLedgerFee) / float64(f.BaseFee)
+}
+
+// feeResult is the fee command response; rippled renders drops and sizes as strings
+type feeResult struct {
+	Drops struct {
+		BaseFee       string `json:"base_fee"`
+		MedianFee     string `json:"median_fee"`
+		MinimumFee    string `json:"minimum_fee"`
+		OpenLedgerFee string `json:"open_ledger_fee"`
+	} `json:"drops"`
+	CurrentLedgerSize  string `json:"current_ledger_size"`
+	ExpectedLedgerSize string `json:"expected_ledger_size"`
+	CurrentQueueSize   string `json:"current_queue_size"`
+	LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+}
+
+// GetFee retrieves the current open ledger transaction cost
+func (c *Client) GetFee() (*FeeInfo, error) {
+	if c.simulated() {
+		return &FeeInfo{
+			BaseFee:            10,
+			MinimumFee:         10,
+			MedianFee:          5000,
+			OpenLedgerFee:      10,
+			ExpectedLedgerSize: 1000,
+			LedgerCurrentIndex: 12348,
+		}, nil
+	}
+
+	var result feeResult
+	if err := c.call("fee", nil, &result); err != nil {
+		return nil, err
+	}
+
+	info := &FeeInfo{LedgerCurrentIndex: result.LedgerCurrentIndex}
+	drops := []struct {
+		name  string
+		value string
+		out   *int64
+	}{
+		{"base_fee", result.Drops.BaseFee, &info.BaseFee},
+		{"minimum_fee", result.Drops.MinimumFee, &info.MinimumFee},
+		{"median_fee", result.Drops.MedianFee, &info.MedianFee},
+		{"open_ledger_fee", result.Drops.OpenLedgerFee, &info.OpenLedgerFee},
+	}
+	for _, field := range drops {
+		value, err := strconv.ParseInt(field.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in fee result: %q", field.name, field.value)
+		}
+		*field.out = value
+	}
+
+	// Sizes are informational; a server that omits them reports zero
+	info.CurrentLedgerSize = parseSize(result.CurrentLedgerSize)
+	info.ExpectedLedgerSize = parseSize(result.ExpectedLedgerSize)
+	info.CurrentQueueSize = parseSize(result.CurrentQueueSize)
+	return info, nil
+}
+
+// parseSize parses a ledger or queue size, treating a missing or malformed value as zero
+func parseSize(value string) uint32 {
+	size, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(size)
+}
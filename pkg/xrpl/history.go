@@ -0,0 +1,173 @@
+package xrpl
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// rippleEpoch is 2000-01-01T00:00:00Z, where ledger close times start
+var rippleEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// FromRippleTime converts a ledger time in seconds since the Ripple epoch to a time
+func FromRippleTime(seconds uint32) time.Time {
+	return rippleEpoch.Add(time.Duration(seconds) * time.Second)
+}
+
+// ToRippleTime converts a time to a ledger time in seconds since the Ripple epoch
+func ToRippleTime(t time.Time) uint32 {
+	return uint32(t.Sub(rippleEpoch) / time.Second)
+}
+
+// LedgerTransaction is a validated transaction from an account's history, normalized across
+// transaction types and API versions
+type LedgerTransaction struct {
+	Hash            string `json:"hash"`
+	LedgerIndex     uint32 `json:"ledger_index"`
+	TransactionType string `json:"transaction_type"`
+	Account         string `json:"account"`
+	Destination     string `json:"destination,omitempty"`
+	Sequence        uint32 `json:"sequence"`
+	// Amount is the Amount field as submitted; Delivered is what a payment actually delivered
+	Amount         *Amount   `json:"amount,omitempty"`
+	Delivered      *Amount   `json:"delivered,omitempty"`
+	PartialPayment bool      `json:"partial_payment"`
+	FeeDrops       int64     `json:"fee_drops"`
+	ResultCode     string    `json:"result_code"`
+	CloseTime      time.Time `json:"close_time"`
+}
+
+// Succeeded reports whether the transaction applied with tesSUCCESS
+func (t *LedgerTransaction) Succeeded() bool {
+	return t.ResultCode == "tesSUCCESS"
+}
+
+// AccountTransactionsPage is one page of an account's validated history, oldest first. Marker is
+// nil on the last page and otherwise requests the next one.
+type AccountTransactionsPage struct {
+	Account        string              `json:"account"`
+	LedgerIndexMin uint32              `json:"ledger_index_min"`
+	LedgerIndexMax uint32              `json:"ledger_index_max"`
+	Transactions   []LedgerTransaction `json:"transactions"`
+	Marker         interface{}         `json:"marker,omitempty"`
+}
+
+// AccountTransactionsRequest selects a page of an account's validated history. Zero ledger bounds
+// select the earliest and latest validated ledgers, a zero Limit lets the server choose the page
+// size, and Marker continues from the previous page.
+type AccountTransactionsRequest struct {
+	Account   string
+	MinLedger uint32
+	MaxLedger uint32
+	Limit     uint32
+	Marker    interface{}
+}
+
+// GetAccountTransactions reads one page of the validated transactions that affected an account,
+// oldest first
+func (c *Client) GetAccountTransactions(request *AccountTransactionsRequest) (*AccountTransactionsPage, error) {
+	account := request.Account
+	if !c.ValidateAddress(account) {
+		return nil, fmt.Errorf("invalid account address: %s", account)
+	}
+	if request.MaxLedger != 0 && request.MinLedger > request.MaxLedger {
+		return nil, fmt.Errorf("ledger range %d-%d is empty", request.MinLedger, request.MaxLedger)
+	}
+
+	if c.simulated() {
+		return &AccountTransactionsPage{Account: account, Transactions: []LedgerTransaction{}}, nil
+	}
+
+	var result struct {
+		LedgerIndexMin uint32           `json:"ledger_index_min"`
+		LedgerIndexMax uint32           `json:"ledger_index_max"`
+		Transactions   []accountTxEntry `json:"transactions"`
+		Marker         interface{}      `json:"marker,omitempty"`
+	}
+	params := map[string]interface{}{
+		"account":          account,
+		"ledger_index_min": -1,
+		"ledger_index_max": -1,
+		"forward":          true,
+		"binary":           false,
+	}
+	if request.MinLedger > 0 {
+		params["ledger_index_min"] = request.MinLedger
+	}
+	if request.MaxLedger > 0 {
+		params["ledger_index_max"] = request.MaxLedger
+	}
+	if request.Limit > 0 {
+		params["limit"] = request.Limit
+	}
+	if request.Marker != nil {
+		params["marker"] = request.Marker
+	}
+	if err := c.call("account_tx", params, &result); err != nil {
+		return nil, err
+	}
+
+	page := &AccountTransactionsPage{
+		Account:        account,
+		LedgerIndexMin: result.LedgerIndexMin,
+		LedgerIndexMax: result.LedgerIndexMax,
+		Transactions:   make([]LedgerTransaction, 0, len(result.Transactions)),
+		Marker:         result.Marker,
+	}
+	for i := range result.Transactions {
+		entry := &result.Transactions[i]
+		if !entry.Validated {
+			continue
+		}
+		tx, err := entry.normalize()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction %s: %w", entry.transactionID(), err)
+		}
+		page.Transactions = append(page.Transactions, *tx)
+	}
+	return page, nil
+}
+
+// normalize reads the fields every consumer of account history needs from an account_tx entry
+func (e *accountTxEntry) normalize() (*LedgerTransaction, error) {
+	fields := e.transaction()
+	tx := &LedgerTransaction{
+		Hash:           e.transactionID(),
+		LedgerIndex:    e.validatedIn(),
+		PartialPayment: IsPartialPayment(fields),
+	}
+	tx.TransactionType, _ = fields["TransactionType"].(string)
+	tx.Account, _ = fields["Account"].(string)
+	tx.Destination, _ = fields["Destination"].(string)
+	if sequence, ok := fields["Sequence"].(float64); ok {
+		tx.Sequence = uint32(sequence)
+	}
+	if date, ok := fields["date"].(float64); ok {
+		tx.CloseTime = FromRippleTime(uint32(date))
+	}
+	if fee, ok := fields["Fee"].(string); ok {
+		drops, err := strconv.ParseInt(fee, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee %q: %w", fee, err)
+		}
+		tx.FeeDrops = drops
+	}
+	if e.Meta != nil {
+		tx.ResultCode = e.Meta.TransactionResult
+	}
+
+	if _, ok := fields["Amount"]; ok {
+		amount, err := amountField(fields, "Amount")
+		if err != nil {
+			return nil, err
+		}
+		tx.Amount = &amount
+	}
+	// A partial payment validated before delivered_amount was recorded has no known delivery
+	if tx.TransactionType == "Payment" && tx.Succeeded() {
+		if delivered, err := DeliveredAmount(fields, e.Meta); err == nil {
+			tx.Delivered = &delivered
+		}
+	}
+	return tx, nil
+}
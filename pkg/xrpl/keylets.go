@@ -0,0 +1,130 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Ledger entry namespaces used when deriving ledger entry IDs
+var (
+	spaceAccount     = []byte{0x00, 0x61} // a
+	spaceCheck       = []byte{0x00, 0x43} // C
+	spaceEscrow      = []byte{0x00, 0x75} // u
+	spaceOffer       = []byte{0x00, 0x6F} // o
+	spacePayChannel  = []byte{0x00, 0x78} // x
+	spaceRippleState = []byte{0x00, 0x72} // r
+	spaceSignerList  = []byte{0x00, 0x53} // S
+	spaceTicket      = []byte{0x00, 0x54} // T
+)
+
+// AccountRootIndex returns the ledger entry ID of an account's AccountRoot
+func AccountRootIndex(account string) (string, error) {
+	accountID, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid account %s: %w", account, err)
+	}
+	return ledgerIndex(spaceAccount, accountID), nil
+}
+
+// CheckIndex returns the ledger entry ID of the check written by account's CheckCreate with the given sequence
+func CheckIndex(account string, sequence uint32) (string, error) {
+	accountID, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid check source %s: %w", account, err)
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, sequence)
+	return ledgerIndex(spaceCheck, accountID, seq), nil
+}
+
+// EscrowIndex returns the ledger entry ID of the escrow created by owner's transaction with the given sequence
+func EscrowIndex(owner string, sequence uint32) (string, error) {
+	accountID, err := DecodeAccountID(owner)
+	if err != nil {
+		return "", fmt.Errorf("invalid escrow owner %s: %w", owner, err)
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, sequence)
+	return ledgerIndex(spaceEscrow, accountID, seq), nil
+}
+
+// PaymentChannelIndex returns the ledger entry ID of the channel opened from account to destination by
+// the PaymentChannelCreate with the given sequence
+func PaymentChannelIndex(account, destination string, sequence uint32) (string, error) {
+	source, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid channel source %s: %w", account, err)
+	}
+	dest, err := DecodeAccountID(destination)
+	if err != nil {
+		return "", fmt.Errorf("invalid channel destination %s: %w", destination, err)
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, sequence)
+	return ledgerIndex(spacePayChannel, source, dest, seq), nil
+}
+
+// RippleStateIndex returns the ledger entry ID of the trust line between two accounts for a currency
+func RippleStateIndex(account, peer, currency string) (string, error) {
+	first, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid account %s: %w", account, err)
+	}
+	second, err := DecodeAccountID(peer)
+	if err != nil {
+		return "", fmt.Errorf("invalid account %s: %w", peer, err)
+	}
+	code, err := EncodeCurrencyCode(currency)
+	if err != nil {
+		return "", err
+	}
+
+	// The entry is keyed by the numerically lower account first
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+	return ledgerIndex(spaceRippleState, first, second, code), nil
+}
+
+// ledgerIndex hashes a namespace and its key material into a ledger entry ID
+func ledgerIndex(space []byte, parts ...[]byte) string {
+	data := append([]byte{}, space...)
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	return strings.ToUpper(hex.EncodeToString(sha512Half(data)))
+}
+
+// SignerListIndex returns the ledger entry ID of an account's signer list
+func SignerListIndex(account string) (string, error) {
+	accountID, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid account %s: %w", account, err)
+	}
+	return ledgerIndex(spaceSignerList, accountID, make([]byte, 4)), nil
+}
+
+// TicketIndex returns the ledger entry ID of the ticket an account set aside for ticketSequence
+func TicketIndex(account string, ticketSequence uint32) (string, error) {
+	accountID, err := DecodeAccountID(account)
+	if err != nil {
+		return "", fmt.Errorf("invalid account %s: %w", account, err)
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, ticketSequence)
+	return ledgerIndex(spaceTicket, accountID, seq), nil
+}
+
+// OfferIndex returns the ledger entry ID of the offer placed by owner's transaction with the given sequence
+func OfferIndex(owner string, sequence uint32) (string, error) {
+	accountID, err := DecodeAccountID(owner)
+	if err != nil {
+		return "", fmt.Errorf("invalid offer owner %s: %w", owner, err)
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, sequence)
+	return ledgerIndex(spaceOffer, accountID, seq), nil
+}
@@ -0,0 +1,321 @@
+package xrpl
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // RIPEMD-160 is mandated by the XRPL AccountID format
+)
+
+// KeyType identifies the signing algorithm of an XRPL keypair
+type KeyType string
+
+const (
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+	KeyTypeEd25519   KeyType = "ed25519"
+)
+
+// Version prefixes for XRPL base58check encodings
+var (
+	accountIDPrefix       = []byte{0x00}
+	secp256k1SeedPrefix   = []byte{0x21}
+	ed25519SeedPrefix     = []byte{0x01, 0xE1, 0x4B}
+	xAddressMainnetPrefix = []byte{0x05, 0x44}
+	xAddressTestnetPrefix = []byte{0x04, 0x93}
+)
+
+const (
+	seedEntropyLength = 16
+	accountIDLength   = 20
+	ed25519KeyPrefix  = 0xED
+)
+
+// KeyPair holds the public and private keys derived from a family seed
+type KeyPair struct {
+	KeyType    KeyType
+	PublicKey  []byte
+	PrivateKey []byte
+}
+
+// PublicKeyHex returns the 33-byte public key as uppercase hex
+func (k *KeyPair) PublicKeyHex() string {
+	return strings.ToUpper(hex.EncodeToString(k.PublicKey))
+}
+
+// PrivateKeyHex returns the private key as uppercase hex, prefixed with 00
+// for secp256k1 keys and ED for ed25519 keys as rippled and xrpl.js expect
+func (k *KeyPair) PrivateKeyHex() string {
+	prefix := "00"
+	if k.KeyType == KeyTypeEd25519 {
+		prefix = "ED"
+	}
+	return prefix + strings.ToUpper(hex.EncodeToString(k.PrivateKey))
+}
+
+// Address returns the classic address of the keypair
+func (k *KeyPair) Address() string {
+	return EncodeAccountID(AccountIDFromPublicKey(k.PublicKey))
+}
+
+// GenerateSeed creates a new random family seed for the given key type
+func GenerateSeed(keyType KeyType) (string, error) {
+	entropy := make([]byte, seedEntropyLength)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("failed to generate seed entropy: %w", err)
+	}
+	return EncodeSeed(entropy, keyType)
+}
+
+// EncodeSeed encodes 16 bytes of entropy as an XRPL family seed
+func EncodeSeed(entropy []byte, keyType KeyType) (string, error) {
+	if len(entropy) != seedEntropyLength {
+		return "", fmt.Errorf("seed entropy must be %d bytes", seedEntropyLength)
+	}
+
+	switch keyType {
+	case KeyTypeSecp256k1:
+		return encodeBase58Check(secp256k1SeedPrefix, entropy), nil
+	case KeyTypeEd25519:
+		return encodeBase58Check(ed25519SeedPrefix, entropy), nil
+	default:
+		return "", fmt.Errorf("unsupported key type: %s", keyType)
+	}
+}
+
+// DecodeSeed decodes a family seed into its entropy and key type
+func DecodeSeed(seed string) ([]byte, KeyType, error) {
+	if strings.HasPrefix(seed, "sEd") {
+		if entropy, err := decodeBase58Check(seed, ed25519SeedPrefix, seedEntropyLength); err == nil {
+			return entropy, KeyTypeEd25519, nil
+		}
+	}
+
+	entropy, err := decodeBase58Check(seed, secp256k1SeedPrefix, seedEntropyLength)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid family seed: %w", err)
+	}
+	return entropy, KeyTypeSecp256k1, nil
+}
+
+// DeriveKeyPair derives the account keypair for a family seed
+func DeriveKeyPair(seed string) (*KeyPair, error) {
+	entropy, keyType, err := DecodeSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyType == KeyTypeEd25519 {
+		return deriveEd25519KeyPair(entropy), nil
+	}
+	return deriveSecp256k1KeyPair(entropy)
+}
+
+// deriveEd25519KeyPair uses the first half of SHA-512 of the entropy as the ed25519 seed
+func deriveEd25519KeyPair(entropy []byte) *KeyPair {
+	rawSeed := sha512Half(entropy)
+	privateKey := ed25519.NewKeyFromSeed(rawSeed)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	return &KeyPair{
+		KeyType:    KeyTypeEd25519,
+		PublicKey:  append([]byte{ed25519KeyPrefix}, publicKey...),
+		PrivateKey: rawSeed,
+	}
+}
+
+// deriveSecp256k1KeyPair follows the rippled family generator: a root key is
+// derived from the seed and the account key is root + an additional scalar
+// derived from the root public key and account index 0
+func deriveSecp256k1KeyPair(entropy []byte) (*KeyPair, error) {
+	rootPrivate, err := deriveScalar(entropy, nil)
+	if err != nil {
+		return nil, err
+	}
+	rootPublic := secp256k1.NewPrivateKey(rootPrivate).PubKey().SerializeCompressed()
+
+	accountIndex := make([]byte, 4)
+	additional, err := deriveScalar(rootPublic, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var accountScalar secp256k1.ModNScalar
+	accountScalar.Add2(rootPrivate, additional)
+	if accountScalar.IsZero() {
+		return nil, fmt.Errorf("derived secp256k1 private key is zero")
+	}
+
+	privateKey := secp256k1.NewPrivateKey(&accountScalar)
+	privateBytes := accountScalar.Bytes()
+
+	return &KeyPair{
+		KeyType:    KeyTypeSecp256k1,
+		PublicKey:  privateKey.PubKey().SerializeCompressed(),
+		PrivateKey: privateBytes[:],
+	}, nil
+}
+
+// deriveScalar hashes data || [discriminator] || counter until the result is a valid curve scalar
+func deriveScalar(data, discriminator []byte) (*secp256k1.ModNScalar, error) {
+	buf := make([]byte, 0, len(data)+len(discriminator)+4)
+	counter := make([]byte, 4)
+
+	for i := uint32(0); i < 0xFFFFFFFF; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		buf = append(buf[:0], data...)
+		buf = append(buf, discriminator...)
+		buf = append(buf, counter...)
+
+		var scalar secp256k1.ModNScalar
+		overflow := scalar.SetByteSlice(sha512Half(buf))
+		if !overflow && !scalar.IsZero() {
+			return &scalar, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to derive a valid secp256k1 scalar")
+}
+
+// sha512Half returns the first 32 bytes of the SHA-512 digest
+func sha512Half(data []byte) []byte {
+	sum := sha512.Sum512(data)
+	return sum[:32]
+}
+
+// AccountIDFromPublicKey computes RIPEMD-160(SHA-256(publicKey))
+func AccountIDFromPublicKey(publicKey []byte) []byte {
+	sha := sha256.Sum256(publicKey)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+// EncodeAccountID encodes a 20-byte AccountID as a classic address
+func EncodeAccountID(accountID []byte) string {
+	return encodeBase58Check(accountIDPrefix, accountID)
+}
+
+// DecodeAccountID decodes and checksum-verifies a classic address
+func DecodeAccountID(address string) ([]byte, error) {
+	if !strings.HasPrefix(address, "r") {
+		return nil, fmt.Errorf("classic address must start with r")
+	}
+	accountID, err := decodeBase58Check(address, accountIDPrefix, accountIDLength)
+	if err != nil {
+		return nil, fmt.Errorf("invalid classic address %s: %w", address, err)
+	}
+	return accountID, nil
+}
+
+// IsValidClassicAddress reports whether the address is a checksum-valid classic address
+func IsValidClassicAddress(address string) bool {
+	if len(address) < 25 || len(address) > 35 {
+		return false
+	}
+	_, err := DecodeAccountID(address)
+	return err == nil
+}
+
+// XAddress is the decoded form of an X-address
+type XAddress struct {
+	ClassicAddress string
+	Tag            uint32
+	HasTag         bool
+	TestNet        bool
+}
+
+// DecodeXAddress decodes an X-address into its classic address and destination tag
+func DecodeXAddress(xAddress string) (*XAddress, error) {
+	prefix := xAddressMainnetPrefix
+	testNet := false
+	if strings.HasPrefix(xAddress, "T") {
+		prefix = xAddressTestnetPrefix
+		testNet = true
+	} else if !strings.HasPrefix(xAddress, "X") {
+		return nil, fmt.Errorf("X-address must start with X or T")
+	}
+
+	// 20-byte AccountID, 1 flag byte and an 8-byte little-endian tag field
+	payload, err := decodeBase58Check(xAddress, prefix, accountIDLength+9)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X-address %s: %w", xAddress, err)
+	}
+
+	flag := payload[accountIDLength]
+	tagBytes := payload[accountIDLength+1:]
+	if flag > 1 {
+		return nil, fmt.Errorf("unsupported X-address tag flag %d", flag)
+	}
+	if binary.LittleEndian.Uint32(tagBytes[4:]) != 0 {
+		return nil, fmt.Errorf("64-bit X-address tags are not supported")
+	}
+
+	tag := binary.LittleEndian.Uint32(tagBytes[:4])
+	if flag == 0 && tag != 0 {
+		return nil, fmt.Errorf("X-address has a tag value without the tag flag")
+	}
+
+	return &XAddress{
+		ClassicAddress: EncodeAccountID(payload[:accountIDLength]),
+		Tag:            tag,
+		HasTag:         flag == 1,
+		TestNet:        testNet,
+	}, nil
+}
+
+// IsValidXAddress reports whether the address is a checksum-valid X-address
+func IsValidXAddress(address string) bool {
+	_, err := DecodeXAddress(address)
+	return err == nil
+}
+
+// EncodeXAddress encodes a classic address and, when HasTag is set, its destination tag as an X-address
+func EncodeXAddress(address *XAddress) (string, error) {
+	accountID, err := DecodeAccountID(address.ClassicAddress)
+	if err != nil {
+		return "", err
+	}
+	if !address.HasTag && address.Tag != 0 {
+		return "", fmt.Errorf("X-address has a tag value without the tag flag")
+	}
+
+	prefix := xAddressMainnetPrefix
+	if address.TestNet {
+		prefix = xAddressTestnetPrefix
+	}
+	payload := make([]byte, accountIDLength+9)
+	copy(payload, accountID)
+	if address.HasTag {
+		payload[accountIDLength] = 1
+	}
+	binary.LittleEndian.PutUint32(payload[accountIDLength+1:], address.Tag)
+	return encodeBase58Check(prefix, payload), nil
+}
+
+// ResolveDestination splits a destination given as a classic address or X-address into the classic
+// address and destination tag a transaction carries. A tag embedded in an X-address must agree with
+// any tag already set on the transaction.
+func ResolveDestination(address string, tag uint32) (string, uint32, error) {
+	if !strings.HasPrefix(address, "X") && !strings.HasPrefix(address, "T") {
+		return address, tag, nil
+	}
+
+	decoded, err := DecodeXAddress(address)
+	if err != nil {
+		return "", 0, err
+	}
+	if !decoded.HasTag {
+		return decoded.ClassicAddress, tag, nil
+	}
+	if tag != 0 && tag != decoded.Tag {
+		return "", 0, fmt.Errorf("destination tag %d conflicts with tag %d of X-address %s", tag, decoded.Tag, address)
+	}
+	return decoded.ClassicAddress, decoded.Tag, nil
+}
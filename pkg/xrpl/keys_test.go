@@ -0,0 +1,184 @@
+package xrpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveKeyPair_KnownVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed      string
+		keyType   KeyType
+		address   string
+		publicKey string
+	}{
+		{
+			// Genesis account derived from the "masterpassphrase" seed
+			name:      "secp256k1 genesis seed",
+			seed:      "snoPBrXtMeMyMHUVTgbuqAfg1SUTb",
+			keyType:   KeyTypeSecp256k1,
+			address:   "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+			publicKey: "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020",
+		},
+		{
+			name:      "ed25519 seed",
+			seed:      "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2r",
+			keyType:   KeyTypeEd25519,
+			address:   "rLUEXYuLiQptky37CqLcm9USQpPiz5rkpD",
+			publicKey: "ED01FA53FA5A7E77798F882ECE20B1ABC00BB358A9E55A202D0D0676BD0CE37A63",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPair, err := DeriveKeyPair(tt.seed)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.keyType, keyPair.KeyType)
+			assert.Equal(t, tt.publicKey, keyPair.PublicKeyHex())
+			assert.Equal(t, tt.address, keyPair.Address())
+			assert.Len(t, keyPair.PublicKey, 33)
+		})
+	}
+}
+
+func TestEncodeDecodeSeed(t *testing.T) {
+	entropy := []byte{0xDE, 0xDC, 0xE9, 0xCE, 0x67, 0xB4, 0x51, 0xD8, 0x52, 0xFD, 0x4E, 0x84, 0x6F, 0xCD, 0xE3, 0x1C}
+
+	for _, keyType := range []KeyType{KeyTypeSecp256k1, KeyTypeEd25519} {
+		seed, err := EncodeSeed(entropy, keyType)
+		require.NoError(t, err)
+		assert.Equal(t, byte('s'), seed[0])
+
+		decoded, decodedType, err := DecodeSeed(seed)
+		require.NoError(t, err)
+		assert.Equal(t, entropy, decoded)
+		assert.Equal(t, keyType, decodedType)
+	}
+
+	_, err := EncodeSeed(entropy[:8], KeyTypeSecp256k1)
+	assert.Error(t, err)
+
+	_, _, err = DecodeSeed("snoPBrXtMeMyMHUVTgbuqAfg1SUTc")
+	assert.Error(t, err)
+}
+
+func TestGenerateWallet_KeyTypes(t *testing.T) {
+	client := NewClient("https://s.altnet.rippletest.net:51234", true)
+
+	for _, keyType := range []KeyType{KeyTypeSecp256k1, KeyTypeEd25519} {
+		wallet, err := client.GenerateWalletWithKeyType(keyType)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(keyType), wallet.KeyType)
+		assert.True(t, IsValidClassicAddress(wallet.Address))
+
+		// The stored seed must reproduce the same keys
+		restored, err := client.WalletFromSeed(wallet.Seed)
+		require.NoError(t, err)
+		assert.Equal(t, wallet.Address, restored.Address)
+		assert.Equal(t, wallet.PublicKey, restored.PublicKey)
+		assert.Equal(t, wallet.PrivateKey, restored.PrivateKey)
+	}
+}
+
+func TestDecodeAccountID_Checksum(t *testing.T) {
+	accountID, err := DecodeAccountID("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	require.NoError(t, err)
+	assert.Len(t, accountID, 20)
+	assert.Equal(t, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", EncodeAccountID(accountID))
+
+	// Last character altered breaks the checksum
+	_, err = DecodeAccountID("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTj")
+	assert.Error(t, err)
+
+	// The all-zero AccountID encodes to the well-known black hole address
+	assert.Equal(t, "rrrrrrrrrrrrrrrrrrrrrhoLvTp", EncodeAccountID(make([]byte, 20)))
+}
+
+func TestDecodeXAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		xAddress string
+		classic  string
+		tag      uint32
+		hasTag   bool
+		testNet  bool
+	}{
+		{
+			name:     "mainnet without tag",
+			xAddress: "X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqZ",
+			classic:  "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+		},
+		{
+			name:     "mainnet with tag",
+			xAddress: "X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu",
+			classic:  "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+			tag:      1,
+			hasTag:   true,
+		},
+		{
+			name:     "testnet without tag",
+			xAddress: "T719a5UwUCnEs54UsxG9CJYYDhwmFCqkr7wxCcNcfZ6p5GZ",
+			classic:  "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+			testNet:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeXAddress(tt.xAddress)
+			require.NoError(t, err)
+			assert.Equal(t, tt.classic, decoded.ClassicAddress)
+			assert.Equal(t, tt.tag, decoded.Tag)
+			assert.Equal(t, tt.hasTag, decoded.HasTag)
+			assert.Equal(t, tt.testNet, decoded.TestNet)
+		})
+	}
+
+	_, err := DecodeXAddress("X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqY")
+	assert.Error(t, err)
+}
+
+func TestEncodeXAddress_RoundTrip(t *testing.T) {
+	encoded, err := EncodeXAddress(&XAddress{ClassicAddress: "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", Tag: 1, HasTag: true})
+	require.NoError(t, err)
+	assert.Equal(t, "X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu", encoded)
+
+	encoded, err = EncodeXAddress(&XAddress{ClassicAddress: "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", TestNet: true})
+	require.NoError(t, err)
+	assert.Equal(t, "T719a5UwUCnEs54UsxG9CJYYDhwmFCqkr7wxCcNcfZ6p5GZ", encoded)
+
+	encoded, err = EncodeXAddress(&XAddress{ClassicAddress: "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", Tag: 4294967295, HasTag: true})
+	require.NoError(t, err)
+	decoded, err := DecodeXAddress(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(4294967295), decoded.Tag)
+	assert.True(t, decoded.HasTag)
+
+	_, err = EncodeXAddress(&XAddress{ClassicAddress: "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", Tag: 1})
+	assert.Error(t, err)
+}
+
+func TestResolveDestination(t *testing.T) {
+	classic, tag, err := ResolveDestination("X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", classic)
+	assert.Equal(t, uint32(1), tag)
+
+	// A classic address or untagged X-address keeps the transaction's own tag
+	classic, tag, err = ResolveDestination("X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqZ", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", classic)
+	assert.Equal(t, uint32(7), tag)
+	classic, tag, err = ResolveDestination("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", classic)
+	assert.Equal(t, uint32(7), tag)
+
+	_, _, err = ResolveDestination("X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu", 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,305 @@
+package xrpl
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// MaxSignerEntries is the largest signer list the ledger accepts
+const MaxSignerEntries = 32
+
+// SignerEntry is one account allowed to sign for a multi-signing account, with its weight
+type SignerEntry struct {
+	Account      string `json:"Account"`
+	SignerWeight uint16 `json:"SignerWeight"`
+}
+
+// Signer is one signature of a multi-signed transaction
+type Signer struct {
+	Account       string `json:"Account"`
+	SigningPubKey string `json:"SigningPubKey"`
+	TxnSignature  string `json:"TxnSignature"`
+}
+
+// NewSignerListSet builds a SignerListSet transaction that requires quorum weight from entries
+// to authorize transactions of account
+func NewSignerListSet(account string, quorum uint32, entries []SignerEntry) (Transaction, error) {
+	if quorum == 0 {
+		return nil, fmt.Errorf("signer quorum must be positive")
+	}
+	if len(entries) == 0 || len(entries) > MaxSignerEntries {
+		return nil, fmt.Errorf("signer list must have between 1 and %d entries, got %d", MaxSignerEntries, len(entries))
+	}
+
+	var total uint32
+	seen := make(map[string]bool, len(entries))
+	wrapped := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if _, err := DecodeAccountID(entry.Account); err != nil {
+			return nil, fmt.Errorf("invalid signer %s: %w", entry.Account, err)
+		}
+		if entry.Account == account {
+			return nil, fmt.Errorf("an account cannot be its own signer")
+		}
+		if seen[entry.Account] {
+			return nil, fmt.Errorf("duplicate signer: %s", entry.Account)
+		}
+		if entry.SignerWeight == 0 {
+			return nil, fmt.Errorf("signer %s must have a positive weight", entry.Account)
+		}
+		seen[entry.Account] = true
+		total += uint32(entry.SignerWeight)
+		wrapped = append(wrapped, map[string]interface{}{"SignerEntry": map[string]interface{}{
+			"Account":      entry.Account,
+			"SignerWeight": entry.SignerWeight,
+		}})
+	}
+	if total < quorum {
+		return nil, fmt.Errorf("signer weights total %d, below quorum %d", total, quorum)
+	}
+
+	return Transaction{
+		"TransactionType": "SignerListSet",
+		"Account":         account,
+		"SignerQuorum":    quorum,
+		"SignerEntries":   wrapped,
+	}, nil
+}
+
+// SetSignerList configures the signer list of an account, signing with the account's own key
+func (c *Client) SetSignerList(account string, quorum uint32, entries []SignerEntry) (*TransactionResult, error) {
+	tx, err := NewSignerListSet(account, quorum, entries)
+	if err != nil {
+		return nil, err
+	}
+	return c.SignAndSubmit(tx)
+}
+
+// MultiSignedFee returns the cost of a transaction carrying signerCount signatures
+func MultiSignedFee(baseFee int64, signerCount int) int64 {
+	return baseFee * int64(1+signerCount)
+}
+
+// PrepareMultiSigned fills in Sequence, Fee and Flags of a transaction that signerCount signers
+// will sign. Every signer must sign the same prepared transaction.
+func (c *Client) PrepareMultiSigned(tx Transaction, signerCount int) (Transaction, error) {
+	account, _ := tx["Account"].(string)
+	if account == "" {
+		return nil, fmt.Errorf("transaction requires Account")
+	}
+	if signerCount < 1 || signerCount > MaxSignerEntries {
+		return nil, fmt.Errorf("signer count must be between 1 and %d", MaxSignerEntries)
+	}
+
+	prepared := copyTransaction(tx)
+	if _, ok := prepared["Sequence"]; !ok {
+		if c.simulated() {
+			prepared["Sequence"] = uint32(1)
+		} else {
+			sequence, err := c.accountSequence(account)
+			if err != nil {
+				return nil, err
+			}
+			prepared["Sequence"] = sequence
+		}
+	}
+	if _, ok := prepared["Fee"]; !ok {
+		fee, err := c.openLedgerFee()
+		if err != nil {
+			return nil, err
+		}
+		prepared["Fee"] = strconv.FormatInt(MultiSignedFee(fee, signerCount), 10)
+	}
+	if _, ok := prepared["Flags"]; !ok {
+		prepared["Flags"] = uint32(0)
+	}
+
+	// Multi-signed transactions carry an empty SigningPubKey
+	prepared["SigningPubKey"] = ""
+	delete(prepared, "TxnSignature")
+	delete(prepared, "Signers")
+	return prepared, nil
+}
+
+// MultiSignTransaction produces one signer's signature over a prepared transaction
+func MultiSignTransaction(tx Transaction, keyPair *KeyPair) (*Signer, error) {
+	if keyPair == nil {
+		return nil, fmt.Errorf("keypair is required")
+	}
+
+	data, err := multiSigningData(tx, keyPair.Address())
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signData(data, keyPair)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		Account:       keyPair.Address(),
+		SigningPubKey: keyPair.PublicKeyHex(),
+		TxnSignature:  strings.ToUpper(hex.EncodeToString(signature)),
+	}, nil
+}
+
+// VerifyMultiSignature checks that a signature was made over the transaction by the key that
+// controls the signer's account
+func VerifyMultiSignature(tx Transaction, signer Signer) error {
+	publicKey, err := hex.DecodeString(signer.SigningPubKey)
+	if err != nil {
+		return fmt.Errorf("invalid signing public key: %w", err)
+	}
+	if EncodeAccountID(AccountIDFromPublicKey(publicKey)) != signer.Account {
+		return fmt.Errorf("public key does not belong to signer %s", signer.Account)
+	}
+	signature, err := hex.DecodeString(signer.TxnSignature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	data, err := multiSigningData(tx, signer.Account)
+	if err != nil {
+		return err
+	}
+	if !verifySignature(data, signature, publicKey) {
+		return fmt.Errorf("signature of %s does not match the transaction", signer.Account)
+	}
+	return nil
+}
+
+// AssembleMultiSigned combines the signatures of a prepared transaction into a signed blob
+func AssembleMultiSigned(tx Transaction, signers []Signer) (*SignedTransaction, error) {
+	if len(signers) == 0 || len(signers) > MaxSignerEntries {
+		return nil, fmt.Errorf("multi-signed transactions need between 1 and %d signers", MaxSignerEntries)
+	}
+
+	type sortableSigner struct {
+		accountID []byte
+		signer    Signer
+	}
+	sorted := make([]sortableSigner, 0, len(signers))
+	for _, signer := range signers {
+		accountID, err := DecodeAccountID(signer.Account)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer %s: %w", signer.Account, err)
+		}
+		sorted = append(sorted, sortableSigner{accountID: accountID, signer: signer})
+	}
+
+	// The ledger requires signers ordered by numeric account ID
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].accountID, sorted[j].accountID) < 0
+	})
+
+	wrapped := make([]interface{}, 0, len(sorted))
+	for i, entry := range sorted {
+		if i > 0 && bytes.Equal(entry.accountID, sorted[i-1].accountID) {
+			return nil, fmt.Errorf("duplicate signer: %s", entry.signer.Account)
+		}
+		wrapped = append(wrapped, map[string]interface{}{"Signer": map[string]interface{}{
+			"Account":       entry.signer.Account,
+			"SigningPubKey": entry.signer.SigningPubKey,
+			"TxnSignature":  entry.signer.TxnSignature,
+		}})
+	}
+
+	assembled := copyTransaction(tx)
+	assembled["SigningPubKey"] = ""
+	delete(assembled, "TxnSignature")
+	assembled["Signers"] = wrapped
+
+	blob, err := EncodeTransaction(assembled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize multi-signed transaction: %w", err)
+	}
+	return &SignedTransaction{
+		TxBlob: strings.ToUpper(hex.EncodeToString(blob)),
+		Hash:   TransactionHash(blob),
+	}, nil
+}
+
+// SubmitMultiSigned assembles and submits a prepared transaction with its collected signatures
+func (c *Client) SubmitMultiSigned(tx Transaction, signers []Signer) (*TransactionResult, error) {
+	signed, err := AssembleMultiSigned(tx, signers)
+	if err != nil {
+		return nil, err
+	}
+	transactionType, _ := tx["TransactionType"].(string)
+	sequence, _ := toUint32(tx["Sequence"])
+
+	if c.simulated() {
+		log.Printf("Simulated multi-signed %s from %s with %d signers, TxID: %s", transactionType, tx["Account"], len(signers), signed.Hash)
+		return &TransactionResult{
+			TransactionID: signed.Hash,
+			LedgerIndex:   12345, // Mock ledger index
+			Validated:     true,
+			ResultCode:    "tesSUCCESS",
+			ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+			Sequence:      sequence,
+		}, nil
+	}
+
+	result, err := c.submitBlob(signed.TxBlob)
+	if result != nil {
+		if result.TransactionID == "" {
+			result.TransactionID = signed.Hash
+		}
+		result.Sequence = sequence
+	}
+	if err != nil {
+		return result, err
+	}
+
+	log.Printf("Submitted multi-signed %s from %s with %d signers: %s (%s)", transactionType, tx["Account"], len(signers), result.TransactionID, result.ResultCode)
+	return result, nil
+}
+
+// multiSigningData checks that a transaction is prepared for multi-signing and returns the data a signer signs
+func multiSigningData(tx Transaction, signerAccount string) ([]byte, error) {
+	for _, field := range []string{"Sequence", "Fee"} {
+		if _, ok := tx[field]; !ok {
+			return nil, fmt.Errorf("transaction must be prepared before multi-signing: missing %s", field)
+		}
+	}
+	if pubKey, _ := tx["SigningPubKey"].(string); pubKey != "" {
+		return nil, fmt.Errorf("multi-signed transactions must have an empty SigningPubKey")
+	}
+
+	signing := copyTransaction(tx)
+	signing["SigningPubKey"] = ""
+	delete(signing, "TxnSignature")
+	delete(signing, "Signers")
+
+	data, err := encodeForMultiSigning(signing, signerAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction for multi-signing: %w", err)
+	}
+	return data, nil
+}
+
+// verifySignature checks a signature over prefixed transaction data
+func verifySignature(data, signature, publicKey []byte) bool {
+	if len(publicKey) == 33 && publicKey[0] == ed25519KeyPrefix {
+		return ed25519.Verify(ed25519.PublicKey(publicKey[1:]), data, signature)
+	}
+
+	pubKey, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := ecdsa.ParseDERSignature(signature)
+	if err != nil {
+		return false
+	}
+	return sig.Verify(sha512Half(data), pubKey)
+}
@@ -0,0 +1,142 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T, keyType KeyType) *KeyPair {
+	t.Helper()
+	seed, err := GenerateSeed(keyType)
+	require.NoError(t, err)
+	keyPair, err := DeriveKeyPair(seed)
+	require.NoError(t, err)
+	return keyPair
+}
+
+func TestNewSignerListSet(t *testing.T) {
+	entries := []SignerEntry{{Account: testAccount, SignerWeight: 1}, {Account: "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY", SignerWeight: 2}}
+
+	tx, err := NewSignerListSet(genesisAccount, 3, entries)
+	require.NoError(t, err)
+	tx["Fee"] = "12"
+	tx["Sequence"] = uint32(1)
+	_, err = EncodeTransaction(tx)
+	require.NoError(t, err)
+
+	invalid := []struct {
+		name    string
+		quorum  uint32
+		entries []SignerEntry
+	}{
+		{"zero quorum", 0, entries},
+		{"unreachable quorum", 4, entries},
+		{"no signers", 1, nil},
+		{"self signer", 1, []SignerEntry{{Account: genesisAccount, SignerWeight: 1}}},
+		{"duplicate signer", 1, []SignerEntry{{Account: testAccount, SignerWeight: 1}, {Account: testAccount, SignerWeight: 1}}},
+		{"zero weight", 1, []SignerEntry{{Account: testAccount, SignerWeight: 0}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSignerListSet(genesisAccount, tt.quorum, tt.entries)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMultiSign_SignVerifyAssemble(t *testing.T) {
+	client := NewClient("https://s.altnet.rippletest.net:51234", true)
+	signers := []*KeyPair{newTestSigner(t, KeyTypeSecp256k1), newTestSigner(t, KeyTypeEd25519)}
+
+	payment, err := TransactionFromStruct("Payment", &Payment{Account: genesisAccount, Destination: testAccount, Amount: XRPAmount(1000000)})
+	require.NoError(t, err)
+
+	_, err = MultiSignTransaction(payment, signers[0])
+	assert.Error(t, err, "unprepared transactions cannot be signed")
+
+	prepared, err := client.PrepareMultiSigned(payment, len(signers))
+	require.NoError(t, err)
+	assert.Equal(t, "30", prepared["Fee"], "base fee for the transaction plus one per signer")
+	assert.Equal(t, "", prepared["SigningPubKey"])
+
+	var signatures []Signer
+	for _, keyPair := range signers {
+		signature, err := MultiSignTransaction(prepared, keyPair)
+		require.NoError(t, err)
+		require.NoError(t, VerifyMultiSignature(prepared, *signature))
+		signatures = append(signatures, *signature)
+	}
+
+	// A signature only covers the signer it was made for and the exact transaction
+	forged := signatures[0]
+	forged.Account = signers[1].Address()
+	assert.Error(t, VerifyMultiSignature(prepared, forged))
+	tampered := copyTransaction(prepared)
+	tampered["Amount"] = "2000000"
+	assert.Error(t, VerifyMultiSignature(tampered, signatures[0]))
+
+	signed, err := AssembleMultiSigned(prepared, signatures)
+	require.NoError(t, err)
+	blob, err := hex.DecodeString(signed.TxBlob)
+	require.NoError(t, err)
+	assert.Equal(t, TransactionHash(blob), signed.Hash)
+
+	// Signers are serialized in account ID order regardless of collection order
+	reversed, err := AssembleMultiSigned(prepared, []Signer{signatures[1], signatures[0]})
+	require.NoError(t, err)
+	assert.Equal(t, signed.TxBlob, reversed.TxBlob)
+	first, _ := DecodeAccountID(signatures[0].Account)
+	second, _ := DecodeAccountID(signatures[1].Account)
+	low, high := first, second
+	if bytes.Compare(first, second) > 0 {
+		low, high = second, first
+	}
+	assert.Less(t, bytes.Index(blob, low), bytes.Index(blob, high))
+
+	_, err = AssembleMultiSigned(prepared, []Signer{signatures[0], signatures[0]})
+	assert.Error(t, err)
+
+	result, err := client.SubmitMultiSigned(prepared, signatures)
+	require.NoError(t, err)
+	assert.Equal(t, signed.Hash, result.TransactionID)
+}
+
+func TestJSONRPC_SubmitMultiSigned(t *testing.T) {
+	server, requests := newTestRippled(t, map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":       "success",
+			"account_data": map[string]interface{}{"Account": genesisAccount, "Sequence": 9},
+		},
+		"server_info": serverInfoResult(),
+		"submit": map[string]interface{}{
+			"status":        "success",
+			"engine_result": "tesSUCCESS",
+			"tx_json":       map[string]interface{}{"hash": "MULTISIGNED"},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	signer := newTestSigner(t, KeyTypeSecp256k1)
+
+	payment, err := TransactionFromStruct("Payment", &Payment{Account: genesisAccount, Destination: testAccount, Amount: XRPAmount(1000000)})
+	require.NoError(t, err)
+	prepared, err := client.PrepareMultiSigned(payment, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(9), prepared["Sequence"])
+	assert.Equal(t, "20", prepared["Fee"])
+
+	signature, err := MultiSignTransaction(prepared, signer)
+	require.NoError(t, err)
+	result, err := client.SubmitMultiSigned(prepared, []Signer{*signature})
+	require.NoError(t, err)
+	assert.Equal(t, "MULTISIGNED", result.TransactionID)
+	assert.Equal(t, uint32(9), result.Sequence)
+
+	last := (*requests)[len(*requests)-1]
+	assert.Equal(t, "submit", last.Method)
+	assert.NotEmpty(t, last.Params[0].(map[string]interface{})["tx_blob"])
+}
@@ -0,0 +1,97 @@
+package xrpl
+
+import (
+	"fmt"
+	"log"
+)
+
+// OfferCreate represents parameters for placing an offer on the decentralized exchange: the
+// account sells TakerGets to whoever pays it TakerPays
+type OfferCreate struct {
+	Account   string `json:"Account"`
+	TakerPays Amount `json:"TakerPays"`
+	TakerGets Amount `json:"TakerGets"`
+	// OfferSequence replaces the account's earlier offer placed with that sequence
+	OfferSequence  uint32 `json:"OfferSequence,omitempty"`
+	Expiration     uint32 `json:"Expiration,omitempty"`
+	Flags          uint32 `json:"Flags,omitempty"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// OfferCancel represents parameters for removing an account's offer
+type OfferCancel struct {
+	Account        string `json:"Account"`
+	OfferSequence  uint32 `json:"OfferSequence"`
+	TicketSequence uint32 `json:"TicketSequence,omitempty"`
+	Fee            string `json:"Fee,omitempty"`
+}
+
+// CreateOffer places an offer; the sequence it consumes identifies the offer for later cancellation
+func (c *Client) CreateOffer(offer *OfferCreate) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(offer.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", offer.Account)
+	}
+	if offer.TakerPays.Value == "" || offer.TakerGets.Value == "" {
+		return nil, fmt.Errorf("offer requires TakerPays and TakerGets")
+	}
+	if offer.TakerPays.IsNative() && offer.TakerGets.IsNative() {
+		return nil, fmt.Errorf("an offer cannot exchange XRP for XRP")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("OfferCreate", offer)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Created offer: %s sells %s for %s, TxID: %s", offer.Account, offer.TakerGets, offer.TakerPays, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// CancelOffer removes an account's offer; cancelling an offer that was already consumed succeeds
+func (c *Client) CancelOffer(cancel *OfferCancel) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(cancel.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", cancel.Account)
+	}
+	if cancel.OfferSequence == 0 {
+		return nil, fmt.Errorf("offer sequence is required")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("OfferCancel", cancel)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Cancelled offer %s:%d, TxID: %s", cancel.Account, cancel.OfferSequence, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
@@ -0,0 +1,299 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Path step type bits and path set markers of the binary format
+const (
+	pathStepAccount  = 0x01
+	pathStepCurrency = 0x10
+	pathStepIssuer   = 0x20
+	pathSeparator    = 0xFF
+	pathSetEnd       = 0x00
+)
+
+// PathStep is one hop of a payment path: rippling through an account, or converting through the
+// order book of a currency and issuer. Currency "XRP" converts through XRP.
+type PathStep struct {
+	Account  string `json:"account,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Issuer   string `json:"issuer,omitempty"`
+}
+
+// Path is an ordered list of steps a payment can take between its source and destination
+type Path []PathStep
+
+// PathFindRequest asks how a source account can deliver an amount to a destination
+type PathFindRequest struct {
+	SourceAccount      string
+	DestinationAccount string
+	// DestinationAmount is what the destination should receive. A value of "-1" asks how much
+	// SendMax can deliver instead.
+	DestinationAmount Amount
+	// SendMax caps what the source spends; it is required with a "-1" destination amount
+	SendMax *Amount
+	// SourceCurrencies limits the currencies the source is willing to spend; "XRP" has no issuer
+	SourceCurrencies []IssuedCurrency
+}
+
+// DeliverAll reports whether the request asks how much SendMax can deliver
+func (r *PathFindRequest) DeliverAll() bool {
+	return r.DestinationAmount.Value == "-1"
+}
+
+// PathAlternative is one way the source can fund the payment
+type PathAlternative struct {
+	// SourceAmount is what the source spends, in the currency it pays with
+	SourceAmount Amount `json:"source_amount"`
+	// DestinationAmount is what the destination receives; it is only reported for requests that
+	// deliver as much as SendMax allows
+	DestinationAmount *Amount `json:"destination_amount,omitempty"`
+	Paths             []Path  `json:"paths_computed"`
+}
+
+// PathQuote lists the ways a payment can be made at the current open ledger
+type PathQuote struct {
+	SourceAccount      string            `json:"source_account"`
+	DestinationAccount string            `json:"destination_account"`
+	DestinationAmount  Amount            `json:"destination_amount"`
+	Alternatives       []PathAlternative `json:"alternatives"`
+	LedgerCurrentIndex uint32            `json:"ledger_current_index"`
+}
+
+// Best returns the alternative that spends the least, or that delivers the most when the
+// request asked to deliver as much as possible. Alternatives should share a source currency.
+func (q *PathQuote) Best() (*PathAlternative, error) {
+	var best *PathAlternative
+	var bestValue *big.Rat
+	for i := range q.Alternatives {
+		alternative := &q.Alternatives[i]
+		amount, deliverAll := alternative.SourceAmount, alternative.DestinationAmount != nil
+		if deliverAll {
+			amount = *alternative.DestinationAmount
+		}
+		value, ok := new(big.Rat).SetString(amount.Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount in path alternative: %q", amount.Value)
+		}
+		if best == nil || (deliverAll && value.Cmp(bestValue) > 0) || (!deliverAll && value.Cmp(bestValue) < 0) {
+			best, bestValue = alternative, value
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%s cannot pay %s to %s: %w", q.SourceAccount, q.DestinationAmount, q.DestinationAccount, ErrNoPath)
+	}
+	return best, nil
+}
+
+// FindPaths quotes the paths and source amounts that can deliver a payment with ripple_path_find.
+// It returns ErrNoPath when the ledger offers no way to make the payment.
+func (c *Client) FindPaths(request *PathFindRequest) (*PathQuote, error) {
+	if !c.ValidateAddress(request.SourceAccount) {
+		return nil, fmt.Errorf("invalid source address: %s", request.SourceAccount)
+	}
+	if !c.ValidateAddress(request.DestinationAccount) {
+		return nil, fmt.Errorf("invalid destination address: %s", request.DestinationAccount)
+	}
+	if request.DestinationAmount.Value == "" {
+		return nil, fmt.Errorf("destination amount is required")
+	}
+	if request.DeliverAll() && request.SendMax == nil {
+		return nil, fmt.Errorf("delivering as much as possible requires SendMax")
+	}
+
+	if c.simulated() {
+		return c.simulatedPaths(request)
+	}
+
+	params := map[string]interface{}{
+		"source_account":      request.SourceAccount,
+		"destination_account": request.DestinationAccount,
+		"destination_amount":  request.DestinationAmount,
+		"ledger_index":        "current",
+	}
+	if request.SendMax != nil {
+		params["send_max"] = request.SendMax
+	}
+	if len(request.SourceCurrencies) > 0 {
+		currencies := make([]map[string]string, 0, len(request.SourceCurrencies))
+		for _, currency := range request.SourceCurrencies {
+			entry := map[string]string{"currency": currency.Currency}
+			if currency.Issuer != "" {
+				entry["issuer"] = currency.Issuer
+			}
+			currencies = append(currencies, entry)
+		}
+		params["source_currencies"] = currencies
+	}
+
+	var quote PathQuote
+	if err := c.call("ripple_path_find", params, &quote); err != nil {
+		return nil, err
+	}
+	if len(quote.Alternatives) == 0 {
+		return nil, fmt.Errorf("%s cannot pay %s to %s: %w", request.SourceAccount, request.DestinationAmount, request.DestinationAccount, ErrNoPath)
+	}
+	return &quote, nil
+}
+
+// simulatedPaths quotes a direct payment when the source spends the destination currency; the
+// offline simulation keeps no order books to convert through
+func (c *Client) simulatedPaths(request *PathFindRequest) (*PathQuote, error) {
+	destination := request.DestinationAmount
+	spendable := len(request.SourceCurrencies) == 0
+	for _, currency := range request.SourceCurrencies {
+		if (currency.Currency == "XRP" && destination.IsNative()) || (currency.Currency == destination.Currency && currency.Issuer == destination.Issuer) {
+			spendable = true
+		}
+	}
+	if request.SendMax != nil && (request.SendMax.Currency != destination.Currency || request.SendMax.Issuer != destination.Issuer) {
+		spendable = false
+	}
+	if !spendable {
+		return nil, fmt.Errorf("%s cannot pay %s to %s: %w", request.SourceAccount, destination, request.DestinationAccount, ErrNoPath)
+	}
+
+	alternative := PathAlternative{SourceAmount: destination, Paths: []Path{}}
+	if request.DeliverAll() {
+		delivered := *request.SendMax
+		alternative.SourceAmount = delivered
+		alternative.DestinationAmount = &delivered
+	}
+	return &PathQuote{
+		SourceAccount:      request.SourceAccount,
+		DestinationAccount: request.DestinationAccount,
+		DestinationAmount:  destination,
+		Alternatives:       []PathAlternative{alternative},
+		LedgerCurrentIndex: 12348,
+	}, nil
+}
+
+// encodePathSet serializes a list of paths, separating paths with 0xFF and ending the set with 0x00
+func encodePathSet(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path set: %w", err)
+	}
+	var paths []Path
+	if err := json.Unmarshal(raw, &paths); err != nil {
+		return nil, fmt.Errorf("expected a list of paths, got %T", value)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("path set must contain at least one path")
+	}
+
+	var buf bytes.Buffer
+	for i, path := range paths {
+		if i > 0 {
+			buf.WriteByte(pathSeparator)
+		}
+		if len(path) == 0 {
+			return nil, fmt.Errorf("path %d has no steps", i)
+		}
+		for _, step := range path {
+			encoded, err := encodePathStep(step)
+			if err != nil {
+				return nil, fmt.Errorf("invalid step in path %d: %w", i, err)
+			}
+			buf.Write(encoded)
+		}
+	}
+	buf.WriteByte(pathSetEnd)
+	return buf.Bytes(), nil
+}
+
+// encodePathStep serializes a step's type byte followed by its account, currency and issuer
+func encodePathStep(step PathStep) ([]byte, error) {
+	var stepType byte
+	var body []byte
+	if step.Account != "" {
+		accountID, err := DecodeAccountID(step.Account)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account %s: %w", step.Account, err)
+		}
+		stepType |= pathStepAccount
+		body = append(body, accountID...)
+	}
+	if step.Currency != "" {
+		code := make([]byte, currencyCodeLength)
+		if step.Currency != "XRP" {
+			ledgerCode, err := CurrencyCode(step.Currency)
+			if err != nil {
+				return nil, err
+			}
+			if code, err = EncodeCurrencyCode(ledgerCode); err != nil {
+				return nil, err
+			}
+		}
+		stepType |= pathStepCurrency
+		body = append(body, code...)
+	}
+	if step.Issuer != "" {
+		issuerID, err := DecodeAccountID(step.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issuer %s: %w", step.Issuer, err)
+		}
+		stepType |= pathStepIssuer
+		body = append(body, issuerID...)
+	}
+	if stepType == 0 {
+		return nil, fmt.Errorf("path step names no account, currency or issuer")
+	}
+	return append([]byte{stepType}, body...), nil
+}
+
+// readPathSet reads paths until the path set end marker
+func (d *binaryDecoder) readPathSet() ([]interface{}, error) {
+	paths := []interface{}{}
+	path := []interface{}{}
+	for {
+		stepType, err := d.readByte()
+		if err != nil {
+			return nil, fmt.Errorf("path set is missing its end marker")
+		}
+		switch stepType {
+		case pathSetEnd, pathSeparator:
+			paths = append(paths, path)
+			if stepType == pathSetEnd {
+				return paths, nil
+			}
+			path = []interface{}{}
+			continue
+		}
+
+		step := map[string]interface{}{}
+		if stepType&pathStepAccount != 0 {
+			raw, err := d.read(20)
+			if err != nil {
+				return nil, err
+			}
+			step["account"] = EncodeAccountID(raw)
+		}
+		if stepType&pathStepCurrency != 0 {
+			raw, err := d.read(currencyCodeLength)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Equal(raw, make([]byte, currencyCodeLength)) {
+				step["currency"] = "XRP"
+			} else {
+				step["currency"] = decodeCurrencyCode(raw)
+			}
+		}
+		if stepType&pathStepIssuer != 0 {
+			raw, err := d.read(20)
+			if err != nil {
+				return nil, err
+			}
+			step["issuer"] = EncodeAccountID(raw)
+		}
+		if len(step) == 0 {
+			return nil, fmt.Errorf("unsupported path step type 0x%02X", stepType)
+		}
+		path = append(path, step)
+	}
+}
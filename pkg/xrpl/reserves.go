@@ -0,0 +1,237 @@
+package xrpl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AccountDeleteLedgerGap is how many ledgers must close after an account's current sequence
+// before the account can be deleted
+const AccountDeleteLedgerGap = 256
+
+// ReserveSettings are the reserves of the validated ledger in drops: every account keeps the base,
+// plus the increment for each object it owns
+type ReserveSettings struct {
+	BaseDrops      int64 `json:"base_drops"`
+	IncrementDrops int64 `json:"increment_drops"`
+}
+
+// Required returns the reserve of an account owning ownerCount objects
+func (r ReserveSettings) Required(ownerCount uint32) int64 {
+	return r.BaseDrops + int64(ownerCount)*r.IncrementDrops
+}
+
+// AccountReserve splits an account's XRP balance into what its reserve locks and what it can spend
+type AccountReserve struct {
+	Account      string          `json:"account"`
+	BalanceDrops int64           `json:"balance_drops"`
+	OwnerCount   uint32          `json:"owner_count"`
+	ReserveDrops int64           `json:"reserve_drops"`
+	Settings     ReserveSettings `json:"settings"`
+}
+
+// Spendable returns the XRP the account can send without dipping into its reserve
+func (r *AccountReserve) Spendable() int64 {
+	return r.SpendableWith(0)
+}
+
+// SpendableWith returns the XRP the account could still send after taking on newObjects more
+// owned objects, negative when the reserve for them is not covered
+func (r *AccountReserve) SpendableWith(newObjects uint32) int64 {
+	return r.BalanceDrops - r.Settings.Required(r.OwnerCount+newObjects)
+}
+
+// Covers checks that the account can take on newObjects owned objects and still send drops,
+// returning ErrInsufficientReserve with the shortfall when it cannot
+func (r *AccountReserve) Covers(newObjects uint32, drops int64) error {
+	if shortfall := drops - r.SpendableWith(newObjects); shortfall > 0 {
+		return fmt.Errorf("%w: %s holds %d drops against a reserve of %d drops for %d objects and is %d drops short of sending %d",
+			ErrInsufficientReserve, r.Account, r.BalanceDrops, r.Settings.Required(r.OwnerCount+newObjects),
+			r.OwnerCount+newObjects, shortfall, drops)
+	}
+	return nil
+}
+
+// GetReserveSettings reads the live base and owner reserves from the validated ledger
+func (c *Client) GetReserveSettings() (*ReserveSettings, error) {
+	info, err := c.GetServerInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserve settings: %w", err)
+	}
+	if info.ValidatedLedger == nil {
+		return nil, fmt.Errorf("%w: no validated ledger", ErrServerNotReady)
+	}
+	return &ReserveSettings{
+		BaseDrops:      xrpToDrops(info.ValidatedLedger.ReserveBaseXRP),
+		IncrementDrops: xrpToDrops(info.ValidatedLedger.ReserveIncXRP),
+	}, nil
+}
+
+// GetAccountReserve reads an account's balance and owner count from the validated ledger and
+// prices its reserve at the live reserve settings
+func (c *Client) GetAccountReserve(address string) (*AccountReserve, error) {
+	if !c.ValidateAddress(address) {
+		return nil, fmt.Errorf("invalid account address: %s", address)
+	}
+
+	settings, err := c.GetReserveSettings()
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.GetAccountInfo(address)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := strconv.ParseInt(info.Balance, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance %q for %s: %w", info.Balance, address, err)
+	}
+
+	return &AccountReserve{
+		Account:      address,
+		BalanceDrops: balance,
+		OwnerCount:   info.OwnerCount,
+		ReserveDrops: settings.Required(info.OwnerCount),
+		Settings:     *settings,
+	}, nil
+}
+
+// AccountDelete represents parameters for deleting an account and sending its XRP to Destination
+type AccountDelete struct {
+	Account        string `json:"Account"`
+	Destination    string `json:"Destination"`
+	DestinationTag uint32 `json:"DestinationTag,omitempty"`
+	// Fee is the transaction cost in drops; empty pays the owner reserve increment the network charges
+	Fee string `json:"Fee,omitempty"`
+}
+
+// accountObject is the type of an entry listed by account_objects
+type accountObject struct {
+	LedgerEntryType string `json:"LedgerEntryType"`
+}
+
+// DeletionBlockers lists the types of the objects that keep an account from being deleted, such
+// as escrows, payment channels, checks and trust lines; offers, tickets and signer lists are
+// removed with the account and do not block it
+func (c *Client) DeletionBlockers(address string) ([]string, error) {
+	if !c.ValidateAddress(address) {
+		return nil, fmt.Errorf("invalid account address: %s", address)
+	}
+
+	if c.simulated() {
+		return []string{}, nil
+	}
+
+	var result struct {
+		AccountObjects []accountObject `json:"account_objects"`
+	}
+	params := map[string]interface{}{
+		"account":                address,
+		"deletion_blockers_only": true,
+		"ledger_index":           "validated",
+	}
+	if err := c.call("account_objects", params, &result); err != nil {
+		return nil, err
+	}
+
+	blockers := make([]string, 0, len(result.AccountObjects))
+	for _, object := range result.AccountObjects {
+		blockers = append(blockers, object.LedgerEntryType)
+	}
+	return blockers, nil
+}
+
+// CheckAccountDeletable returns ErrAccountNotDeletable when the account still owns objects that
+// block deletion or its sequence is within AccountDeleteLedgerGap of the validated ledger
+func (c *Client) CheckAccountDeletable(address string) error {
+	if c.simulated() {
+		return nil
+	}
+
+	blockers, err := c.DeletionBlockers(address)
+	if err != nil {
+		return fmt.Errorf("failed to list deletion blockers: %w", err)
+	}
+	if len(blockers) > 0 {
+		return fmt.Errorf("%w: %s still owns %s", ErrAccountNotDeletable, address, strings.Join(blockers, ", "))
+	}
+
+	info, err := c.GetServerInfo()
+	if err != nil {
+		return err
+	}
+	if info.ValidatedLedger == nil {
+		return fmt.Errorf("%w: no validated ledger", ErrServerNotReady)
+	}
+	sequence, err := c.accountSequence(address)
+	if err != nil {
+		return err
+	}
+	if uint64(sequence)+AccountDeleteLedgerGap > uint64(info.ValidatedLedger.Seq) {
+		return fmt.Errorf("%w: %s can be deleted from ledger %d, the validated ledger is %d",
+			ErrAccountNotDeletable, address, uint64(sequence)+AccountDeleteLedgerGap, info.ValidatedLedger.Seq)
+	}
+	return nil
+}
+
+// DeleteAccount removes an account from the ledger and sends the XRP it holds, less the deletion
+// fee, to the destination. The account is checked for deletion blockers first.
+func (c *Client) DeleteAccount(deletion *AccountDelete) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(deletion.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", deletion.Account)
+	}
+	if !c.ValidateAddress(deletion.Destination) {
+		return nil, fmt.Errorf("invalid destination address: %s", deletion.Destination)
+	}
+	destination, tag, err := ResolveDestination(deletion.Destination, deletion.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	deletion.Destination, deletion.DestinationTag = destination, tag
+	if deletion.Account == deletion.Destination {
+		return nil, fmt.Errorf("an account cannot be deleted into itself")
+	}
+
+	if !c.simulated() {
+		if err := c.CheckAccountDeletable(deletion.Account); err != nil {
+			if errors.Is(err, ErrAccountNotFound) {
+				return nil, fmt.Errorf("%w: %s does not exist", ErrAccountNotDeletable, deletion.Account)
+			}
+			return nil, err
+		}
+		tx, err := TransactionFromStruct("AccountDelete", deletion)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Deleted account %s into %s, TxID: %s", deletion.Account, deletion.Destination, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// accountDeleteFee returns what the network charges to delete an account: the owner reserve
+// increment, scaled by the server load factor
+func accountDeleteFee(info *ServerInfo) int64 {
+	loadFactor := info.LoadFactor
+	if loadFactor < 1 {
+		loadFactor = 1
+	}
+	return int64(math.Ceil(float64(xrpToDrops(info.ValidatedLedger.ReserveIncXRP)) * loadFactor))
+}
@@ -0,0 +1,281 @@
+package xrpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// rpcRequest is the JSON-RPC envelope accepted by rippled
+type rpcRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// rpcResponse is the JSON-RPC envelope returned by rippled
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+// rpcStatus holds the status fields present on every rippled result
+type rpcStatus struct {
+	Status       string `json:"status"`
+	Error        string `json:"error"`
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// ServerInfo represents the subset of the server_info response used by the platform
+type ServerInfo struct {
+	BuildVersion    string           `json:"build_version"`
+	CompleteLedgers string           `json:"complete_ledgers"`
+	ServerState     string           `json:"server_state"`
+	LoadFactor      float64          `json:"load_factor"`
+	NetworkID       uint32           `json:"network_id,omitempty"`
+	ValidatedLedger *ValidatedLedger `json:"validated_ledger,omitempty"`
+}
+
+// ValidatedLedger describes the most recent validated ledger known to the server
+type ValidatedLedger struct {
+	Age            uint32  `json:"age"`
+	BaseFeeXRP     float64 `json:"base_fee_xrp"`
+	Hash           string  `json:"hash"`
+	ReserveBaseXRP float64 `json:"reserve_base_xrp"`
+	ReserveIncXRP  float64 `json:"reserve_inc_xrp"`
+	Seq            uint32  `json:"seq"`
+}
+
+// IsSynced reports whether the server is in a state where it can serve validated data
+func (s *ServerInfo) IsSynced() bool {
+	switch s.ServerState {
+	case "full", "proposing", "validating":
+		return true
+	default:
+		return false
+	}
+}
+
+// submitResult is the result of the submit command
+type submitResult struct {
+	EngineResult        string `json:"engine_result"`
+	EngineResultCode    int    `json:"engine_result_code"`
+	EngineResultMessage string `json:"engine_result_message"`
+	TxJSON              struct {
+		Hash string `json:"hash"`
+	} `json:"tx_json"`
+	Accepted bool `json:"accepted"`
+	Queued   bool `json:"queued"`
+}
+
+// txResult is the result of the tx command
+type txResult struct {
+	Hash        string          `json:"hash"`
+	LedgerIndex uint32          `json:"ledger_index"`
+	Validated   bool            `json:"validated"`
+	Fee         string          `json:"Fee"`
+	Meta        TransactionMeta `json:"meta"`
+}
+
+// call performs a JSON-RPC request and decodes the result into out
+func (c *Client) call(method string, params interface{}, out interface{}) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("XRPL client not connected")
+	}
+
+	request := rpcRequest{Method: method, Params: []interface{}{}}
+	if params != nil {
+		request.Params = append(request.Params, params)
+	} else {
+		request.Params = append(request.Params, map[string]interface{}{})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	resp, err := c.httpClient.Post(c.NetworkURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("xrpl %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests {
+		return &RPCError{Method: method, Code: "tooBusy", Message: resp.Status}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("xrpl %s request failed with HTTP status %s", method, resp.Status)
+	}
+
+	var envelope rpcResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+
+	var status rpcStatus
+	if err := json.Unmarshal(envelope.Result, &status); err != nil {
+		return fmt.Errorf("failed to decode %s status: %w", method, err)
+	}
+	if status.Status == "error" || status.Error != "" {
+		return &RPCError{
+			Method:    method,
+			Code:      status.Error,
+			ErrorCode: status.ErrorCode,
+			Message:   status.ErrorMessage,
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// GetServerInfo retrieves the server_info of the connected rippled server
+func (c *Client) GetServerInfo() (*ServerInfo, error) {
+	if c.simulated() {
+		return &ServerInfo{
+			BuildVersion:    "simulator",
+			CompleteLedgers: "1-12347",
+			ServerState:     "full",
+			LoadFactor:      1,
+			ValidatedLedger: &ValidatedLedger{
+				BaseFeeXRP:     0.00001,
+				ReserveBaseXRP: 10,
+				ReserveIncXRP:  2,
+				Seq:            12347,
+			},
+		}, nil
+	}
+
+	var result struct {
+		Info ServerInfo `json:"info"`
+	}
+	if err := c.call("server_info", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.Info, nil
+}
+
+// GetTransaction looks up a transaction by hash and reports its outcome
+func (c *Client) GetTransaction(hash string) (*TransactionResult, error) {
+	if hash == "" {
+		return nil, fmt.Errorf("transaction hash cannot be empty")
+	}
+
+	if c.simulated() {
+		return &TransactionResult{
+			TransactionID: hash,
+			LedgerIndex:   12347,
+			Validated:     true,
+			ResultCode:    "tesSUCCESS",
+			ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+		}, nil
+	}
+
+	var result txResult
+	if err := c.call("tx", map[string]interface{}{"transaction": hash, "binary": false}, &result); err != nil {
+		return nil, err
+	}
+
+	transaction := &TransactionResult{
+		TransactionID: result.Hash,
+		LedgerIndex:   result.LedgerIndex,
+		Validated:     result.Validated,
+		ResultCode:    result.Meta.TransactionResult,
+		Fee:           result.Fee,
+		Meta:          &result.Meta,
+	}
+	if delivered := result.Meta.DeliveredAmount; delivered != nil && delivered.Value != DeliveredAmountUnavailable {
+		transaction.DeliveredAmount = delivered
+	}
+	return transaction, nil
+}
+
+// submitBlob submits a signed transaction blob and maps the engine result
+func (c *Client) submitBlob(txBlob string) (*TransactionResult, error) {
+	var result submitResult
+	if err := c.call("submit", map[string]interface{}{"tx_blob": txBlob}, &result); err != nil {
+		return nil, err
+	}
+
+	outcome := &TransactionResult{
+		TransactionID: result.TxJSON.Hash,
+		ResultCode:    result.EngineResult,
+		ResultMessage: result.EngineResultMessage,
+	}
+
+	// tesSUCCESS and terQUEUED are provisionally applied. Anything else was not applied: a final
+	// rejection never will be, any other result is provisional and may still change.
+	if result.EngineResult != "tesSUCCESS" && result.EngineResult != "terQUEUED" {
+		return outcome, &TransactionError{
+			TransactionID: result.TxJSON.Hash,
+			Code:          result.EngineResult,
+			Message:       result.EngineResultMessage,
+			Provisional:   !IsFinalRejection(result.EngineResult),
+		}
+	}
+
+	return outcome, nil
+}
+
+// getAccountInfoRPC retrieves the validated account root for an address
+func (c *Client) getAccountInfoRPC(address string) (*AccountInfo, error) {
+	var result struct {
+		AccountData AccountInfo `json:"account_data"`
+	}
+	params := map[string]interface{}{
+		"account":      address,
+		"ledger_index": "validated",
+	}
+	if err := c.call("account_info", params, &result); err != nil {
+		return nil, err
+	}
+
+	settings, err := c.GetReserveSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	info := result.AccountData
+	info.Reserve = strconv.FormatInt(settings.Required(info.OwnerCount), 10)
+	return &info, nil
+}
+
+// getEscrowInfoRPC looks up an escrow ledger entry by owner and sequence
+func (c *Client) getEscrowInfoRPC(owner string, sequence uint32) (*EscrowInfo, error) {
+	var result struct {
+		Node EscrowInfo `json:"node"`
+	}
+	params := map[string]interface{}{
+		"escrow": map[string]interface{}{
+			"owner": owner,
+			"seq":   sequence,
+		},
+		"ledger_index": "validated",
+	}
+	if err := c.call("ledger_entry", params, &result); err != nil {
+		return nil, err
+	}
+
+	info := result.Node
+	info.Sequence = sequence
+	return &info, nil
+}
+
+// xrpToDrops converts an XRP value reported by rippled into drops
+func xrpToDrops(xrp float64) int64 {
+	return int64(math.Round(xrp * 1000000))
+}
@@ -0,0 +1,379 @@
+package xrpl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccount = "rN7n7otQDd6FczFgLdSqtcsAUxDkw6fzRH"
+
+// newTestRippled starts an httptest stand-in for rippled that answers
+// each JSON-RPC method with the given result object
+func newTestRippled(t *testing.T, results map[string]interface{}) (*httptest.Server, *[]rpcRequest) {
+	t.Helper()
+	var requests []rpcRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		result, ok := results[req.Method]
+		if !ok {
+			result = map[string]interface{}{"status": "error", "error": "unknownCmd", "error_code": 32}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func serverInfoResult() map[string]interface{} {
+	return map[string]interface{}{
+		"status": "success",
+		"info": map[string]interface{}{
+			"build_version":    "2.2.0",
+			"complete_ledgers": "32570-90000",
+			"server_state":     "full",
+			"load_factor":      1,
+			"validated_ledger": map[string]interface{}{
+				"age":              2,
+				"base_fee_xrp":     0.00001,
+				"hash":             "4482DEE5362332F54A4036ED57EE1767C9F33CF7CE5A6670355C16CECE381D46",
+				"reserve_base_xrp": 10,
+				"reserve_inc_xrp":  2,
+				"seq":              90000,
+			},
+		},
+	}
+}
+
+func TestJSONRPC_GetAccountInfo(t *testing.T) {
+	server, requests := newTestRippled(t, map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":    "success",
+			"validated": true,
+			"account_data": map[string]interface{}{
+				"Account":       testAccount,
+				"Balance":       "25000000",
+				"Flags":         0,
+				"OwnerCount":    3,
+				"Sequence":      42,
+				"PreviousTxnID": "A1B2",
+			},
+		},
+		"server_info": serverInfoResult(),
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	info, err := client.GetAccountInfo(testAccount)
+	require.NoError(t, err)
+
+	assert.Equal(t, testAccount, info.Account)
+	assert.Equal(t, "25000000", info.Balance)
+	assert.Equal(t, uint32(42), info.Sequence)
+	assert.Equal(t, uint32(3), info.OwnerCount)
+	assert.Equal(t, "A1B2", info.PreviousTxn)
+	// 10 XRP base reserve + 3 objects * 2 XRP
+	assert.Equal(t, "16000000", info.Reserve)
+
+	require.NotEmpty(t, *requests)
+	assert.Equal(t, "account_info", (*requests)[0].Method)
+}
+
+func TestJSONRPC_ErrorMapping(t *testing.T) {
+	server, _ := newTestRippled(t, map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":        "error",
+			"error":         "actNotFound",
+			"error_code":    19,
+			"error_message": "Account not found.",
+		},
+		"tx": map[string]interface{}{
+			"status":     "error",
+			"error":      "txnNotFound",
+			"error_code": 29,
+		},
+		"ledger_entry": map[string]interface{}{
+			"status":     "error",
+			"error":      "entryNotFound",
+			"error_code": 21,
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+
+	_, err := client.GetAccountInfo(testAccount)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrAccountNotFound))
+	var rpcErr *RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, 19, rpcErr.ErrorCode)
+
+	_, err = client.GetTransaction("DEADBEEF")
+	assert.True(t, errors.Is(err, ErrTransactionNotFound))
+
+	_, err = client.GetEscrowInfo(testAccount, "7")
+	assert.True(t, errors.Is(err, ErrEntryNotFound))
+}
+
+func TestJSONRPC_SubmitTransaction(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		server, requests := newTestRippled(t, map[string]interface{}{
+			"submit": map[string]interface{}{
+				"status":                "success",
+				"engine_result":         "tesSUCCESS",
+				"engine_result_code":    0,
+				"engine_result_message": "The transaction was applied. Only final in a validated ledger.",
+				"tx_json":               map[string]interface{}{"hash": "ABC123"},
+			},
+		})
+
+		client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+		result, err := client.SubmitSignedTransaction("1200002280000000")
+		require.NoError(t, err)
+		assert.Equal(t, "ABC123", result.TransactionID)
+		assert.Equal(t, "tesSUCCESS", result.ResultCode)
+		assert.False(t, result.Validated)
+
+		params := (*requests)[0].Params[0].(map[string]interface{})
+		assert.Equal(t, "1200002280000000", params["tx_blob"])
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		server, _ := newTestRippled(t, map[string]interface{}{
+			"submit": map[string]interface{}{
+				"status":                "success",
+				"engine_result":         "tefPAST_SEQ",
+				"engine_result_code":    -190,
+				"engine_result_message": "This sequence number has already passed.",
+				"tx_json":               map[string]interface{}{"hash": "ABC124"},
+			},
+		})
+
+		client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+		err := client.SubmitTransaction("1200002280000000")
+		require.Error(t, err)
+		var txErr *TransactionError
+		require.True(t, errors.As(err, &txErr))
+		assert.Equal(t, "tefPAST_SEQ", txErr.Code)
+	})
+}
+
+func TestJSONRPC_GetTransaction(t *testing.T) {
+	server, _ := newTestRippled(t, map[string]interface{}{
+		"tx": map[string]interface{}{
+			"status":       "success",
+			"hash":         "ABC123",
+			"ledger_index": 90001,
+			"validated":    true,
+			"Fee":          "12",
+			"meta":         map[string]interface{}{"TransactionResult": "tecNO_TARGET"},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	result, err := client.GetTransaction("ABC123")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(90001), result.LedgerIndex)
+	assert.True(t, result.Validated)
+	assert.Equal(t, "tecNO_TARGET", result.ResultCode)
+	assert.Equal(t, "12", result.Fee)
+}
+
+func TestJSONRPC_GetFee(t *testing.T) {
+	server, _ := newTestRippled(t, map[string]interface{}{
+		"fee": map[string]interface{}{
+			"status":               "success",
+			"current_ledger_size":  "56",
+			"current_queue_size":   "11",
+			"expected_ledger_size": "55",
+			"ledger_current_index": 90001,
+			"drops": map[string]interface{}{
+				"base_fee":        "10",
+				"median_fee":      "11000",
+				"minimum_fee":     "12",
+				"open_ledger_fee": "2900",
+			},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	fees, err := client.GetFee()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), fees.BaseFee)
+	assert.Equal(t, int64(12), fees.MinimumFee)
+	assert.Equal(t, int64(2900), fees.OpenLedgerFee)
+	assert.Equal(t, uint32(11), fees.CurrentQueueSize)
+	assert.Equal(t, uint32(55), fees.ExpectedLedgerSize)
+	assert.Equal(t, float64(290), fees.LoadFactor())
+}
+
+func TestJSONRPC_GetEscrowInfo(t *testing.T) {
+	server, requests := newTestRippled(t, map[string]interface{}{
+		"ledger_entry": map[string]interface{}{
+			"status": "success",
+			"node": map[string]interface{}{
+				"Account":         testAccount,
+				"Destination":     "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY",
+				"Amount":          "5000000",
+				"Condition":       "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100",
+				"CancelAfter":     800000000,
+				"FinishAfter":     790000000,
+				"Flags":           0,
+				"LedgerEntryType": "Escrow",
+				"OwnerNode":       "0",
+			},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	escrow, err := client.GetEscrowInfo(testAccount, "7")
+	require.NoError(t, err)
+	assert.Equal(t, XRPAmount(5000000), escrow.Amount)
+	assert.Equal(t, "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY", escrow.Destination)
+	assert.Equal(t, uint32(800000000), escrow.CancelAfter)
+	assert.Equal(t, uint32(7), escrow.Sequence)
+
+	params := (*requests)[0].Params[0].(map[string]interface{})
+	escrowParams := params["escrow"].(map[string]interface{})
+	assert.Equal(t, testAccount, escrowParams["owner"])
+	assert.Equal(t, float64(7), escrowParams["seq"])
+
+	_, err = client.GetEscrowInfo(testAccount, "not-a-number")
+	assert.Error(t, err)
+}
+
+func TestJSONRPC_HealthCheck(t *testing.T) {
+	server, _ := newTestRippled(t, map[string]interface{}{
+		"server_info": serverInfoResult(),
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	require.NoError(t, client.Connect())
+	assert.NoError(t, client.HealthCheck())
+
+	syncing := serverInfoResult()
+	syncing["info"].(map[string]interface{})["server_state"] = "syncing"
+	server, _ = newTestRippled(t, map[string]interface{}{"server_info": syncing})
+
+	client = NewClientWithMode(server.URL, true, ModeJSONRPC)
+	err := client.HealthCheck()
+	assert.True(t, errors.Is(err, ErrServerNotReady))
+}
+
+func TestJSONRPC_EscrowRequiresSigning(t *testing.T) {
+	server, _ := newTestRippled(t, map[string]interface{}{})
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+
+	_, err := client.CreateEscrow(&EscrowCreate{
+		Account:     testAccount,
+		Destination: "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY",
+		Amount:      XRPAmount(1000000),
+	})
+	assert.True(t, errors.Is(err, ErrSigningUnavailable))
+}
+
+// staticKeyProvider serves keypairs from memory for signing tests
+type staticKeyProvider map[string]*KeyPair
+
+func (p staticKeyProvider) SigningKey(address string) (*KeyPair, error) {
+	keyPair, ok := p[address]
+	if !ok {
+		return nil, errors.New("no key for address")
+	}
+	return keyPair, nil
+}
+
+func TestJSONRPC_EscrowSignedLocally(t *testing.T) {
+	keyPair, err := DeriveKeyPair("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	require.NoError(t, err)
+
+	server, requests := newTestRippled(t, map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":       "success",
+			"account_data": map[string]interface{}{"Account": keyPair.Address(), "Sequence": 17},
+		},
+		"server_info": serverInfoResult(),
+		"submit": map[string]interface{}{
+			"status":                "success",
+			"engine_result":         "tesSUCCESS",
+			"engine_result_message": "The transaction was applied.",
+			"tx_json":               map[string]interface{}{"hash": "ABC123"},
+		},
+	})
+
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	client.SetKeyProvider(staticKeyProvider{keyPair.Address(): keyPair})
+
+	result, err := client.CreateEscrow(&EscrowCreate{
+		Account:     keyPair.Address(),
+		Destination: "rPEPPER7kfTD9w2To4CQk6UCfuHM9c6GDY",
+		Amount:      XRPAmount(1000000),
+		FinishAfter: 800000000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ABC123", result.TransactionID)
+	assert.Equal(t, uint32(17), result.Sequence)
+
+	// account_info and server_info autofill the transaction before the blob is submitted
+	submitted := (*requests)[len(*requests)-1]
+	require.Equal(t, "submit", submitted.Method)
+	blob := submitted.Params[0].(map[string]interface{})["tx_blob"].(string)
+	assert.True(t, strings.HasPrefix(blob, "1200012200000000240000001120"), "unexpected blob header %s", blob)
+	assert.Contains(t, blob, "68400000000000000A")
+	assert.Contains(t, blob, "7321"+keyPair.PublicKeyHex())
+
+	// Finishing with a fulfillment pays the fulfillment-size surcharge: 10 * (33 + 36/16)
+	condition, fulfillment, err := client.GenerateCondition("milestone_secret")
+	require.NoError(t, err)
+	_, err = client.FinishEscrow(&EscrowFinish{
+		Account:       keyPair.Address(),
+		Owner:         keyPair.Address(),
+		OfferSequence: 17,
+		Condition:     condition,
+		Fulfillment:   fulfillment,
+	})
+	require.NoError(t, err)
+	blob = (*requests)[len(*requests)-1].Params[0].(map[string]interface{})["tx_blob"].(string)
+	assert.Contains(t, blob, "68400000000000015E")
+
+	_, err = client.FinishEscrow(&EscrowFinish{
+		Account:       keyPair.Address(),
+		Owner:         keyPair.Address(),
+		OfferSequence: 17,
+		Condition:     condition,
+		Fulfillment:   "A0028000",
+	})
+	assert.True(t, errors.Is(err, ErrConditionMismatch))
+
+	_, err = client.CancelEscrow(&EscrowCancel{
+		Account:       testAccount,
+		Owner:         testAccount,
+		OfferSequence: 17,
+	})
+	assert.Error(t, err)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeSimulator, mode)
+
+	mode, err = ParseMode("JSONRPC")
+	require.NoError(t, err)
+	assert.Equal(t, ModeJSONRPC, mode)
+
+	_, err = ParseMode("grpc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,299 @@
+package xrpl
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// KeyProvider supplies the signing keys for accounts whose keys the platform holds
+type KeyProvider interface {
+	SigningKey(address string) (*KeyPair, error)
+}
+
+// SignedTransaction is a serialized, signed transaction ready for submission
+type SignedTransaction struct {
+	TxBlob string `json:"tx_blob"`
+	Hash   string `json:"hash"`
+}
+
+// SignTransaction single-signs a transaction with the given keypair and returns the signed blob
+func SignTransaction(tx Transaction, keyPair *KeyPair) (*SignedTransaction, error) {
+	if keyPair == nil {
+		return nil, fmt.Errorf("keypair is required")
+	}
+
+	if account, ok := tx["Account"].(string); ok && account != keyPair.Address() {
+		return nil, fmt.Errorf("keypair for %s cannot sign for account %s", keyPair.Address(), account)
+	}
+
+	signing := copyTransaction(tx)
+	delete(signing, "TxnSignature")
+	signing["SigningPubKey"] = keyPair.PublicKeyHex()
+
+	data, err := encodeForSigning(signing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction for signing: %w", err)
+	}
+
+	signature, err := signData(data, keyPair)
+	if err != nil {
+		return nil, err
+	}
+	signing["TxnSignature"] = strings.ToUpper(hex.EncodeToString(signature))
+
+	blob, err := EncodeTransaction(signing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize signed transaction: %w", err)
+	}
+
+	return &SignedTransaction{
+		TxBlob: strings.ToUpper(hex.EncodeToString(blob)),
+		Hash:   TransactionHash(blob),
+	}, nil
+}
+
+// VerifyTransaction checks the signature of a single-signed transaction against its SigningPubKey.
+// It does not check that the key is authorized to sign for the account.
+func VerifyTransaction(tx Transaction) error {
+	pubKeyHex, _ := tx["SigningPubKey"].(string)
+	signatureHex, _ := tx["TxnSignature"].(string)
+	if pubKeyHex == "" || signatureHex == "" {
+		return fmt.Errorf("transaction is not single-signed")
+	}
+
+	publicKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid signing public key: %w", err)
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	data, err := encodeForSigning(tx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction for verification: %w", err)
+	}
+	if !verifySignature(data, signature, publicKey) {
+		return fmt.Errorf("signature does not match the transaction")
+	}
+	return nil
+}
+
+// signData signs prefixed transaction data: secp256k1 keys sign its SHA-512Half
+// digest with a canonical DER signature, ed25519 keys sign the data directly
+func signData(data []byte, keyPair *KeyPair) ([]byte, error) {
+	switch keyPair.KeyType {
+	case KeyTypeSecp256k1:
+		privateKey := secp256k1.PrivKeyFromBytes(keyPair.PrivateKey)
+		return ecdsa.Sign(privateKey, sha512Half(data)).Serialize(), nil
+	case KeyTypeEd25519:
+		privateKey := ed25519.NewKeyFromSeed(keyPair.PrivateKey)
+		return ed25519.Sign(privateKey, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", keyPair.KeyType)
+	}
+}
+
+// SetKeyProvider configures where the client obtains signing keys for local signing
+func (c *Client) SetKeyProvider(provider KeyProvider) {
+	c.keyProvider = provider
+}
+
+// SignAndSubmit autofills, signs and submits a transaction for an account whose key the client can obtain
+func (c *Client) SignAndSubmit(tx Transaction) (*TransactionResult, error) {
+	transactionType, _ := tx["TransactionType"].(string)
+	account, _ := tx["Account"].(string)
+	if transactionType == "" || account == "" {
+		return nil, fmt.Errorf("transaction requires TransactionType and Account")
+	}
+
+	if c.simulated() {
+		txID := c.generateTransactionID()
+		log.Printf("Simulated %s from %s, TxID: %s", transactionType, account, txID)
+		return &TransactionResult{
+			TransactionID: txID,
+			LedgerIndex:   12345, // Mock ledger index
+			Validated:     true,
+			ResultCode:    "tesSUCCESS",
+			ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+			Sequence:      1,
+		}, nil
+	}
+
+	if c.keyProvider == nil {
+		return nil, fmt.Errorf("cannot submit %s: %w", transactionType, ErrSigningUnavailable)
+	}
+
+	keyPair, err := c.keyProvider.SigningKey(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key for %s: %w", account, err)
+	}
+
+	prepared := copyTransaction(tx)
+	// A reserved sequence is only involved when autofill allocates one
+	_, hasSequence := prepared["Sequence"]
+	_, hasTicket := prepared["TicketSequence"]
+	allocated := !hasSequence && !hasTicket
+	if err := c.autofill(prepared); err != nil {
+		return nil, fmt.Errorf("failed to prepare %s: %w", transactionType, err)
+	}
+
+	signed, err := SignTransaction(prepared, keyPair)
+	if err != nil {
+		if allocated {
+			c.sequences.Reset(account)
+		}
+		return nil, fmt.Errorf("failed to sign %s: %w", transactionType, err)
+	}
+
+	result, err := c.submitBlob(signed.TxBlob)
+	// A reserved sequence the ledger will not use must be handed out again; one a pending
+	// transaction may still use is held until the transaction is validated or expires
+	if allocated && (result == nil || sequenceUnused(result.ResultCode)) {
+		c.sequences.Reset(account)
+	}
+	if result != nil {
+		if result.TransactionID == "" {
+			result.TransactionID = signed.Hash
+		}
+		if allocated && !sequenceUnused(result.ResultCode) {
+			sequence, _ := toUint32(prepared["Sequence"])
+			c.sequences.Hold(account, result.TransactionID, sequence)
+		}
+		result.Sequence, _ = toUint32(prepared["Sequence"])
+		if hasTicket {
+			result.TicketSequence, _ = toUint32(prepared["TicketSequence"])
+			result.Sequence = result.TicketSequence
+		}
+		result.LastLedgerSequence, _ = toUint32(prepared["LastLedgerSequence"])
+		result.Fee, _ = prepared["Fee"].(string)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	log.Printf("Submitted %s from %s: %s (%s)", transactionType, account, result.TransactionID, result.ResultCode)
+	return result, nil
+}
+
+// autofill sets Fee, Flags, LastLedgerSequence and Sequence when the transaction does not already
+// carry them. The sequence is reserved last so a failed lookup does not leave a gap. A transaction
+// using a ticket has a zero Sequence.
+func (c *Client) autofill(tx Transaction) error {
+	_, hasFee := tx["Fee"]
+	_, hasLastLedger := tx["LastLedgerSequence"]
+	if !hasFee || !hasLastLedger {
+		info, err := c.GetServerInfo()
+		if err != nil {
+			return err
+		}
+		if info.ValidatedLedger == nil {
+			return fmt.Errorf("%w: no validated ledger", ErrServerNotReady)
+		}
+
+		if !hasFee {
+			fee := loadScaledFee(info)
+
+			// EscrowFinish pays extra for verifying a fulfillment
+			if fulfillment, ok := tx["Fulfillment"].(string); ok && tx["TransactionType"] == "EscrowFinish" {
+				if fee, err = EscrowFinishFee(fee, fulfillment); err != nil {
+					return err
+				}
+			}
+			// AccountDelete costs the owner reserve increment instead of the reference fee
+			if tx["TransactionType"] == "AccountDelete" {
+				fee = accountDeleteFee(info)
+			}
+			tx["Fee"] = strconv.FormatInt(fee, 10)
+		}
+
+		// Bound how long the transaction can stay pending so its outcome is always decidable
+		if !hasLastLedger {
+			tx["LastLedgerSequence"] = info.ValidatedLedger.Seq + LastLedgerSequenceOffset
+		}
+	}
+
+	if _, ok := tx["Flags"]; !ok {
+		tx["Flags"] = uint32(0)
+	}
+
+	if _, ok := tx["Sequence"]; !ok {
+		if _, ok := tx["TicketSequence"]; ok {
+			tx["Sequence"] = uint32(0)
+			return nil
+		}
+
+		// TicketCreate consumes a sequence for every ticket it sets aside after its own
+		count := uint32(1)
+		if tx["TransactionType"] == "TicketCreate" {
+			tickets, err := toUint32(tx["TicketCount"])
+			if err != nil {
+				return fmt.Errorf("invalid TicketCount: %w", err)
+			}
+			count += tickets
+		}
+		sequence, err := c.sequences.Reserve(tx["Account"].(string), count)
+		if err != nil {
+			return err
+		}
+		tx["Sequence"] = sequence
+	}
+	return nil
+}
+
+// accountSequence returns the next sequence number of an account in the current open ledger
+func (c *Client) accountSequence(address string) (uint32, error) {
+	var result struct {
+		AccountData AccountInfo `json:"account_data"`
+	}
+	params := map[string]interface{}{
+		"account":      address,
+		"ledger_index": "current",
+	}
+	if err := c.call("account_info", params, &result); err != nil {
+		return 0, err
+	}
+	return result.AccountData.Sequence, nil
+}
+
+// openLedgerFee returns the base transaction cost in drops scaled by the server load factor
+func (c *Client) openLedgerFee() (int64, error) {
+	info, err := c.GetServerInfo()
+	if err != nil {
+		return 0, err
+	}
+	if info.ValidatedLedger == nil {
+		return 0, fmt.Errorf("%w: no validated ledger", ErrServerNotReady)
+	}
+	return loadScaledFee(info), nil
+}
+
+// loadScaledFee returns the base fee of the validated ledger scaled by the server load factor
+func loadScaledFee(info *ServerInfo) int64 {
+	loadFactor := info.LoadFactor
+	if loadFactor < 1 {
+		loadFactor = 1
+	}
+	return int64(math.Ceil(float64(xrpToDrops(info.ValidatedLedger.BaseFeeXRP)) * loadFactor))
+}
+
+// toUint32 converts a transaction field value into a uint32
+func toUint32(value interface{}) (uint32, error) {
+	number, err := toUint64(value)
+	if err != nil {
+		return 0, err
+	}
+	if number > math.MaxUint32 {
+		return 0, fmt.Errorf("value %d overflows UInt32", number)
+	}
+	return uint32(number), nil
+}
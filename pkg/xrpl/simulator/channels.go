@@ -0,0 +1,270 @@
+package simulator
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Payment channels hold XRP from their source that the destination redeems with claims the source
+// signs off the ledger. Claims carry the total paid out so far, so only the latest one matters.
+
+// preflightPaymentChannel validates the shape of the payment channel transactions
+func preflightPaymentChannel(tx xrpl.Transaction, account string) string {
+	switch tx["TransactionType"] {
+	case "PaymentChannelCreate":
+		amount, ok := amountValue(tx, "Amount")
+		if !ok || !amount.IsNative() || !positive(amount) {
+			return "temBAD_AMOUNT"
+		}
+		destination := stringValue(tx, "Destination")
+		if destination == "" {
+			return "temMALFORMED"
+		}
+		if destination == account {
+			return "temDST_IS_SRC"
+		}
+		if _, ok := tx["SettleDelay"].(uint32); !ok || !validChannelKey(stringValue(tx, "PublicKey")) {
+			return "temMALFORMED"
+		}
+	case "PaymentChannelFund":
+		amount, ok := amountValue(tx, "Amount")
+		if !ok || !amount.IsNative() || !positive(amount) {
+			return "temBAD_AMOUNT"
+		}
+		if stringValue(tx, "Channel") == "" {
+			return "temMALFORMED"
+		}
+	case "PaymentChannelClaim":
+		if stringValue(tx, "Channel") == "" {
+			return "temMALFORMED"
+		}
+		balance, hasBalance := amountValue(tx, "Balance")
+		if _, present := tx["Balance"]; present && (!hasBalance || !balance.IsNative() || !positive(balance)) {
+			return "temBAD_AMOUNT"
+		}
+		authorized, hasAmount := amountValue(tx, "Amount")
+		if _, present := tx["Amount"]; present && (!hasAmount || !authorized.IsNative() || !positive(authorized)) {
+			return "temBAD_AMOUNT"
+		}
+		if hasBalance && hasAmount && drops(balance) > drops(authorized) {
+			return "temBAD_AMOUNT"
+		}
+		flags, _ := tx["Flags"].(uint32)
+		if flags&xrpl.PaymentChannelClaimFlagRenew != 0 && flags&xrpl.PaymentChannelClaimFlagClose != 0 {
+			return "temMALFORMED"
+		}
+
+		signature := stringValue(tx, "Signature")
+		if signature == "" {
+			return "tesSUCCESS"
+		}
+		publicKey := stringValue(tx, "PublicKey")
+		if !hasBalance || !validChannelKey(publicKey) {
+			return "temMALFORMED"
+		}
+		if !hasAmount {
+			authorized = balance
+		}
+		if err := xrpl.VerifyPaymentChannelClaim(stringValue(tx, "Channel"), uint64(drops(authorized)), signature, publicKey); err != nil {
+			return "temBAD_SIGNATURE"
+		}
+	}
+	return "tesSUCCESS"
+}
+
+// validChannelKey reports whether a hex string is a compressed secp256k1 or an ed25519 public key
+func validChannelKey(publicKey string) bool {
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != 33 {
+		return false
+	}
+	return key[0] == 0x02 || key[0] == 0x03 || key[0] == 0xED
+}
+
+func (l *Ledger) applyPaymentChannelCreate(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	amount, _ := amountValue(tx, "Amount")
+	account := s.account(ctx.account)
+
+	if account.Balance < l.reserve(account.OwnerCount+1) {
+		return "tecINSUFFICIENT_RESERVE"
+	}
+	if account.Balance < l.reserve(account.OwnerCount+1)+drops(amount) {
+		return "tecUNFUNDED"
+	}
+
+	destinationAddress := stringValue(tx, "Destination")
+	destination := s.account(destinationAddress)
+	if destination == nil {
+		return "tecNO_DST"
+	}
+	if _, tagged := tx["DestinationTag"]; destination.Flags&lsfRequireDestTag != 0 && !tagged {
+		return "tecDST_TAG_NEEDED"
+	}
+	if destination.Flags&lsfDisallowXRP != 0 {
+		return "tecNO_TARGET"
+	}
+	cancelAfter, _ := tx["CancelAfter"].(uint32)
+	if cancelAfter != 0 && l.closeTime >= cancelAfter {
+		return "tecEXPIRED"
+	}
+
+	index, err := xrpl.PaymentChannelIndex(ctx.account, destinationAddress, sequenceValue(tx))
+	if err != nil {
+		return "temMALFORMED"
+	}
+	channel := &payChannelEntry{
+		Account:     ctx.account,
+		Destination: destinationAddress,
+		Amount:      drops(amount),
+		PublicKey:   strings.ToUpper(stringValue(tx, "PublicKey")),
+		SettleDelay: tx["SettleDelay"].(uint32),
+		CancelAfter: cancelAfter,
+	}
+	if tag, ok := tx["DestinationTag"].(uint32); ok {
+		channel.DestinationTag = &tag
+	}
+	if tag, ok := tx["SourceTag"].(uint32); ok {
+		channel.SourceTag = &tag
+	}
+	s.channels[index] = channel
+	account.Balance -= drops(amount)
+	account.OwnerCount++
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyPaymentChannelFund(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	index := strings.ToUpper(stringValue(tx, "Channel"))
+	channel := s.channels[index]
+	if channel == nil {
+		return "tecNO_ENTRY"
+	}
+	if channel.expired(l.closeTime) {
+		return closeChannel(s, index, channel)
+	}
+	if ctx.account != channel.Account {
+		return "tecNO_PERMISSION"
+	}
+
+	if expiration, ok := tx["Expiration"].(uint32); ok {
+		// The source may not shorten the time the destination has to redeem its last claim
+		earliest := l.closeTime + channel.SettleDelay
+		if channel.Expiration != 0 && channel.Expiration < earliest {
+			earliest = channel.Expiration
+		}
+		if expiration < earliest {
+			return "temBAD_EXPIRATION"
+		}
+		channel.Expiration = expiration
+	}
+
+	amount, _ := amountValue(tx, "Amount")
+	account := s.account(ctx.account)
+	if account.Balance < l.reserve(account.OwnerCount) {
+		return "tecINSUFFICIENT_RESERVE"
+	}
+	if account.Balance < l.reserve(account.OwnerCount)+drops(amount) {
+		return "tecUNFUNDED"
+	}
+	account.Balance -= drops(amount)
+	channel.Amount += drops(amount)
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyPaymentChannelClaim(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	index := strings.ToUpper(stringValue(tx, "Channel"))
+	channel := s.channels[index]
+	if channel == nil {
+		return "tecNO_TARGET"
+	}
+	if ctx.account != channel.Account && ctx.account != channel.Destination {
+		return "tecNO_PERMISSION"
+	}
+	if channel.expired(l.closeTime) {
+		return closeChannel(s, index, channel)
+	}
+
+	if balance, ok := amountValue(tx, "Balance"); ok {
+		signature := stringValue(tx, "Signature")
+		// The source can pay out without a claim; the destination needs the source's signature
+		if ctx.account == channel.Destination && signature == "" {
+			return "temBAD_SIGNATURE"
+		}
+		if signature != "" && !strings.EqualFold(stringValue(tx, "PublicKey"), channel.PublicKey) {
+			return "temBAD_SIGNER"
+		}
+		requested := drops(balance)
+		if requested > channel.Amount || requested <= channel.Balance {
+			return "tecUNFUNDED_PAYMENT"
+		}
+
+		destination := s.account(channel.Destination)
+		if destination == nil {
+			return "tecNO_DST"
+		}
+		if destination.Flags&lsfDepositAuth != 0 && ctx.account != channel.Destination {
+			return "tecNO_PERMISSION"
+		}
+		destination.Balance += requested - channel.Balance
+		channel.Balance = requested
+	}
+
+	flags, _ := tx["Flags"].(uint32)
+	if flags&xrpl.PaymentChannelClaimFlagRenew != 0 {
+		if ctx.account != channel.Account {
+			return "tecNO_PERMISSION"
+		}
+		channel.Expiration = 0
+	}
+	if flags&xrpl.PaymentChannelClaimFlagClose != 0 {
+		if ctx.account == channel.Destination || channel.Balance == channel.Amount {
+			return closeChannel(s, index, channel)
+		}
+		// The source has to give the destination its settle delay to redeem outstanding claims
+		settle := l.closeTime + channel.SettleDelay
+		if channel.Expiration == 0 || channel.Expiration > settle {
+			channel.Expiration = settle
+		}
+	}
+	return "tesSUCCESS"
+}
+
+// closeChannel removes a channel and returns its unclaimed XRP to the source
+func closeChannel(s *state, index string, channel *payChannelEntry) string {
+	source := s.account(channel.Account)
+	source.Balance += channel.Amount - channel.Balance
+	source.OwnerCount--
+	delete(s.channels, index)
+	return "tesSUCCESS"
+}
+
+// channelJSON renders a channel the way account_channels lists it
+func channelJSON(index string, channel *payChannelEntry) map[string]interface{} {
+	fields := map[string]interface{}{
+		"channel_id":          index,
+		"account":             channel.Account,
+		"destination_account": channel.Destination,
+		"amount":              strconv.FormatInt(channel.Amount, 10),
+		"balance":             strconv.FormatInt(channel.Balance, 10),
+		"public_key_hex":      channel.PublicKey,
+		"settle_delay":        channel.SettleDelay,
+	}
+	if channel.Expiration != 0 {
+		fields["expiration"] = channel.Expiration
+	}
+	if channel.CancelAfter != 0 {
+		fields["cancel_after"] = channel.CancelAfter
+	}
+	if channel.DestinationTag != nil {
+		fields["destination_tag"] = *channel.DestinationTag
+	}
+	if channel.SourceTag != nil {
+		fields["source_tag"] = *channel.SourceTag
+	}
+	return fields
+}
@@ -0,0 +1,177 @@
+package simulator
+
+import (
+	"strings"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Checks are deferred payments: the source authorizes its destination to pull up to SendMax, but
+// nothing leaves the source's account until the destination cashes the check.
+
+// preflightCheck validates the shape of the check transactions
+func preflightCheck(tx xrpl.Transaction, account string) string {
+	switch tx["TransactionType"] {
+	case "CheckCreate":
+		destination := stringValue(tx, "Destination")
+		if destination == "" {
+			return "temMALFORMED"
+		}
+		if destination == account {
+			return "temREDUNDANT"
+		}
+		sendMax, ok := amountValue(tx, "SendMax")
+		if !ok || !positive(sendMax) {
+			return "temBAD_AMOUNT"
+		}
+		if expiration, present := tx["Expiration"]; present && expiration.(uint32) == 0 {
+			return "temBAD_EXPIRATION"
+		}
+	case "CheckCash":
+		if stringValue(tx, "CheckID") == "" {
+			return "temMALFORMED"
+		}
+		amount, hasAmount := amountValue(tx, "Amount")
+		deliverMin, hasDeliverMin := amountValue(tx, "DeliverMin")
+		// The destination asks for an exact amount or for as much as it can get above a minimum
+		if hasAmount == hasDeliverMin {
+			return "temMALFORMED"
+		}
+		if (hasAmount && !positive(amount)) || (hasDeliverMin && !positive(deliverMin)) {
+			return "temBAD_AMOUNT"
+		}
+	case "CheckCancel":
+		if stringValue(tx, "CheckID") == "" {
+			return "temMALFORMED"
+		}
+	}
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyCheckCreate(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	account := s.account(ctx.account)
+
+	destinationAddress := stringValue(tx, "Destination")
+	destination := s.account(destinationAddress)
+	if destination == nil {
+		return "tecNO_DST"
+	}
+	if _, tagged := tx["DestinationTag"]; destination.Flags&lsfRequireDestTag != 0 && !tagged {
+		return "tecDST_TAG_NEEDED"
+	}
+	expiration, _ := tx["Expiration"].(uint32)
+	if expiration != 0 && l.closeTime >= expiration {
+		return "tecEXPIRED"
+	}
+	if account.Balance < l.reserve(account.OwnerCount+1) {
+		return "tecINSUFFICIENT_RESERVE"
+	}
+
+	sequence := sequenceValue(tx)
+	index, err := xrpl.CheckIndex(ctx.account, sequence)
+	if err != nil {
+		return "temMALFORMED"
+	}
+	sendMax, _ := amountValue(tx, "SendMax")
+	check := &checkEntry{
+		Account:     ctx.account,
+		Destination: destinationAddress,
+		SendMax:     sendMax,
+		Sequence:    sequence,
+		Expiration:  expiration,
+		InvoiceID:   strings.ToUpper(stringValue(tx, "InvoiceID")),
+	}
+	if tag, ok := tx["DestinationTag"].(uint32); ok {
+		check.DestinationTag = &tag
+	}
+	if tag, ok := tx["SourceTag"].(uint32); ok {
+		check.SourceTag = &tag
+	}
+	s.checks[index] = check
+	account.OwnerCount++
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyCheckCash(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	index := strings.ToUpper(stringValue(tx, "CheckID"))
+	check := s.checks[index]
+	if check == nil {
+		return "tecNO_ENTRY"
+	}
+	if ctx.account != check.Destination {
+		return "tecNO_PERMISSION"
+	}
+	if check.expired(l.closeTime) {
+		return "tecEXPIRED"
+	}
+	if s.account(check.Account) == nil {
+		return "tecNO_ENTRY"
+	}
+
+	requested, exact := amountValue(tx, "Amount")
+	if !exact {
+		requested, _ = amountValue(tx, "DeliverMin")
+	}
+	of := issueOf(check.SendMax)
+	if issueOf(requested) != of {
+		return "temMALFORMED"
+	}
+	limit := units(check.SendMax)
+	if units(requested).Cmp(limit) > 0 {
+		return "tecPATH_PARTIAL"
+	}
+
+	// Cashing for a minimum delivers everything the source can pay up to SendMax
+	value := units(requested)
+	available := l.funds(s, check.Account, of)
+	if !exact {
+		value = limit
+		if available != nil && available.Cmp(limit) < 0 {
+			value = available
+		}
+		if !of.native() {
+			value = roundValue(value, false)
+		}
+	}
+	if available != nil && available.Cmp(value) < 0 {
+		return "tecPATH_PARTIAL"
+	}
+	if value.Cmp(units(requested)) < 0 {
+		return "tecPATH_PARTIAL"
+	}
+	if !of.native() && s.line(check.Destination, of.Issuer, of.Currency) == nil && check.Destination != of.Issuer {
+		return "tecNO_LINE"
+	}
+	if result := l.move(s, check.Account, check.Destination, of, value); result != "tesSUCCESS" {
+		return result
+	}
+
+	delivered := of.amount(value)
+	ctx.delivered = &delivered
+	removeCheck(s, index, check)
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyCheckCancel(s *state, ctx *applyContext) string {
+	index := strings.ToUpper(stringValue(ctx.tx, "CheckID"))
+	check := s.checks[index]
+	if check == nil {
+		return "tecNO_ENTRY"
+	}
+	// Anyone may clean up an expired check; until then only its parties can cancel it
+	if !check.expired(l.closeTime) && ctx.account != check.Account && ctx.account != check.Destination {
+		return "tecNO_PERMISSION"
+	}
+	removeCheck(s, index, check)
+	return "tesSUCCESS"
+}
+
+// removeCheck deletes a check and releases the reserve it held on its source
+func removeCheck(s *state, index string, check *checkEntry) {
+	if source := s.account(check.Account); source != nil {
+		source.OwnerCount--
+	}
+	delete(s.checks, index)
+}
@@ -0,0 +1,323 @@
+// Package simulator provides an in-memory XRPL ledger behind the same JSON-RPC and WebSocket
+// surface as rippled. Ledgers close on a controllable clock, escrow rules are enforced and
+// failures can be injected, so services can exercise real transaction lifecycles offline.
+package simulator
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// rippleEpoch is the Unix time of 2000-01-01T00:00:00Z, where ledger close times start
+const rippleEpoch = 946684800
+
+// genesisLedger is the first validated ledger of a new simulator
+const genesisLedger = 2
+
+// Config configures a simulated ledger
+type Config struct {
+	// StartTime is the initial clock value; defaults to the current time
+	StartTime time.Time
+	// BaseFee is the reference transaction cost in drops; defaults to 10
+	BaseFee int64
+	// ReserveBase is the account reserve in drops; defaults to 10 XRP
+	ReserveBase int64
+	// ReserveIncrement is the reserve per owned object in drops; defaults to 2 XRP
+	ReserveIncrement int64
+	// Amendments lists the names of enabled amendments
+	Amendments []string
+	// Faucet credits an unknown account with this many drops the first time it is looked up with
+	// account_info or submits a transaction; 0 disables it
+	Faucet int64
+}
+
+// txRecord is a transaction applied to the ledger
+type txRecord struct {
+	hash        string
+	tx          xrpl.Transaction
+	result      string
+	ledgerIndex uint32
+	// closeTime is the close time of the ledger that validated the transaction, zero while pending
+	closeTime uint32
+	meta      map[string]interface{}
+}
+
+// Ledger is a simulated XRPL ledger. Submitted transactions apply to the open ledger and
+// become validated when it closes.
+type Ledger struct {
+	mu     sync.Mutex
+	config Config
+
+	now        time.Time
+	loadFactor float64
+	amendments map[string]bool
+
+	open       *state
+	validated  *state
+	openIndex  uint32
+	closeTime  uint32
+	ledgerHash string
+
+	pending []*txRecord
+	records map[string]*txRecord
+	// history holds validated transactions in the order they were applied
+	history []*txRecord
+
+	failRequests map[string][]string
+	failSubmits  []string
+	dropSubmits  int
+
+	subscribers map[*subscriber]struct{}
+}
+
+// New creates a ledger whose genesis ledger is already validated
+func New(config Config) *Ledger {
+	if config.StartTime.IsZero() {
+		config.StartTime = time.Now()
+	}
+	if config.BaseFee <= 0 {
+		config.BaseFee = 10
+	}
+	if config.ReserveBase <= 0 {
+		config.ReserveBase = 10000000
+	}
+	if config.ReserveIncrement <= 0 {
+		config.ReserveIncrement = 2000000
+	}
+
+	amendments := make(map[string]bool, len(config.Amendments))
+	for _, name := range config.Amendments {
+		amendments[name] = true
+	}
+
+	now := config.StartTime.UTC().Truncate(time.Second)
+	l := &Ledger{
+		config:       config,
+		now:          now,
+		loadFactor:   1,
+		amendments:   amendments,
+		open:         newState(),
+		validated:    newState(),
+		openIndex:    genesisLedger + 1,
+		closeTime:    rippleTime(now),
+		records:      make(map[string]*txRecord),
+		failRequests: make(map[string][]string),
+		subscribers:  make(map[*subscriber]struct{}),
+	}
+	l.ledgerHash = ledgerHash(genesisLedger, l.closeTime, "", nil)
+	return l
+}
+
+// Fund credits an account with drops in both the open and validated ledger, creating it if needed
+func (l *Ledger) Fund(address string, drops int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, s := range []*state{l.open, l.validated} {
+		account := s.account(address)
+		if account == nil {
+			var err error
+			if account, err = s.createAccount(address, l.openIndex); err != nil {
+				return err
+			}
+		}
+		account.Balance += drops
+	}
+	return nil
+}
+
+// Balance returns the validated XRP balance of an account in drops
+func (l *Ledger) Balance(address string) (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	account := l.validated.account(address)
+	if account == nil {
+		return 0, false
+	}
+	return account.Balance, true
+}
+
+// LedgerIndex returns the sequence of the latest validated ledger
+func (l *Ledger) LedgerIndex() uint32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.openIndex - 1
+}
+
+// Now returns the simulator clock
+func (l *Ledger) Now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.now
+}
+
+// AdvanceTime moves the simulator clock forward; the next closed ledger takes the new time
+func (l *Ledger) AdvanceTime(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+// SetLoadFactor scales the fee the open ledger requires, as a busy server does
+func (l *Ledger) SetLoadFactor(factor float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if factor < 1 {
+		factor = 1
+	}
+	l.loadFactor = factor
+}
+
+// FailNextRequest makes the next call of an RPC method return the given rippled error code
+func (l *Ledger) FailNextRequest(method, code string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failRequests[method] = append(l.failRequests[method], code)
+}
+
+// FailNextSubmit makes the next submitted transaction fail with engineResult. A tec result is
+// applied to the ledger, claiming the fee; any other result rejects the transaction.
+func (l *Ledger) FailNextSubmit(engineResult string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failSubmits = append(l.failSubmits, engineResult)
+}
+
+// DropNextSubmit makes the next submitted transaction report tesSUCCESS without ever being applied,
+// as if it was lost before reaching a validator
+func (l *Ledger) DropNextSubmit() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dropSubmits++
+}
+
+// CloseLedger validates the open ledger, notifies subscribers and returns the closed ledger's sequence
+func (l *Ledger) CloseLedger() uint32 {
+	l.mu.Lock()
+
+	closed := l.openIndex
+	l.closeTime = rippleTime(l.now)
+	hashes := make([]string, 0, len(l.pending))
+	for _, record := range l.pending {
+		record.closeTime = l.closeTime
+		hashes = append(hashes, record.hash)
+	}
+	l.ledgerHash = ledgerHash(closed, l.closeTime, l.ledgerHash, hashes)
+	l.validated = l.open.clone()
+	l.openIndex++
+
+	records := l.pending
+	l.pending = nil
+	l.history = append(l.history, records...)
+	deliveries := l.notifications(closed, records)
+	l.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		if err := delivery.subscriber.send(delivery.message); err != nil {
+			l.removeSubscriber(delivery.subscriber)
+		}
+	}
+	return closed
+}
+
+// AutoClose advances the clock by interval and closes a ledger on every tick until stop is called
+func (l *Ledger) AutoClose(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				l.AdvanceTime(interval)
+				l.CloseLedger()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// faucet creates an unknown account funded by the faucet, returning its open ledger AccountRoot,
+// or nil when the faucet is disabled or the account already exists; the caller holds the mutex
+func (l *Ledger) faucet(address string) *accountRoot {
+	if l.config.Faucet <= 0 || l.open.account(address) != nil {
+		return nil
+	}
+	for _, s := range []*state{l.open, l.validated} {
+		account, err := s.createAccount(address, l.openIndex)
+		if err != nil {
+			return nil
+		}
+		account.Balance = l.config.Faucet
+	}
+	return l.open.account(address)
+}
+
+// takeRequestFailure returns and consumes an injected error code for method
+func (l *Ledger) takeRequestFailure(method string) string {
+	codes := l.failRequests[method]
+	if len(codes) == 0 {
+		return ""
+	}
+	l.failRequests[method] = codes[1:]
+	return codes[0]
+}
+
+// requiredFee returns the open ledger cost in drops of a transaction with the given base cost
+func (l *Ledger) requiredFee(baseCost int64) int64 {
+	return int64(math.Ceil(float64(baseCost) * l.loadFactor))
+}
+
+// reserve returns the XRP an account owning ownerCount objects must keep
+func (l *Ledger) reserve(ownerCount uint32) int64 {
+	return l.config.ReserveBase + int64(ownerCount)*l.config.ReserveIncrement
+}
+
+// rippleTime converts a time into seconds since the Ripple epoch
+func rippleTime(t time.Time) uint32 {
+	return uint32(t.Unix() - rippleEpoch)
+}
+
+// ledgerHash derives a stable identifier for a closed ledger from its contents
+func ledgerHash(index, closeTime uint32, parent string, txHashes []string) string {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data, index)
+	binary.BigEndian.PutUint32(data[4:], closeTime)
+	data = append(data, parent...)
+	for _, hash := range txHashes {
+		data = append(data, hash...)
+	}
+	sum := sha512.Sum512(data)
+	return strings.ToUpper(hex.EncodeToString(sum[:32]))
+}
+
+// formatDrops renders an XRP amount in drops
+func formatDrops(drops int64) string {
+	return strconv.FormatInt(drops, 10)
+}
+
+// formatXRP renders drops as a decimal XRP value for server_info
+func formatXRP(drops int64) float64 {
+	return float64(drops) / 1000000
+}
+
+// completeLedgers reports the range of validated ledgers in rippled's format
+func (l *Ledger) completeLedgers() string {
+	return fmt.Sprintf("%d-%d", genesisLedger, l.openIndex-1)
+}
@@ -0,0 +1,363 @@
+package simulator
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// The simulator's exchange keeps resting offers and lets cross-currency payments consume them from
+// the direct order book between the currency spent and the currency delivered. Offers do not cross
+// each other when they are placed, and payments do not follow the Paths they carry: every
+// conversion goes through that one book.
+
+// tfPartialPayment lets a payment deliver less than its Amount
+const tfPartialPayment = 0x00020000
+
+// issue identifies what an amount is denominated in: XRP when Currency is empty
+type issue struct {
+	Currency string
+	Issuer   string
+}
+
+// issueOf returns the issue of an amount
+func issueOf(amount xrpl.Amount) issue {
+	if amount.IsNative() {
+		return issue{}
+	}
+	return issue{Currency: amount.Currency, Issuer: amount.Issuer}
+}
+
+func (i issue) native() bool {
+	return i.Currency == ""
+}
+
+// amount renders a value of the issue, rounding XRP down to whole drops
+func (i issue) amount(value *big.Rat) xrpl.Amount {
+	if i.native() {
+		whole := new(big.Int).Quo(value.Num(), value.Denom())
+		return xrpl.Amount{Value: whole.String()}
+	}
+	return xrpl.Amount{Currency: i.Currency, Issuer: i.Issuer, Value: formatValue(value)}
+}
+
+// step is the path step that converts into the issue through its order book
+func (i issue) step() map[string]interface{} {
+	if i.native() {
+		return map[string]interface{}{"currency": "XRP"}
+	}
+	return map[string]interface{}{"currency": i.Currency, "issuer": i.Issuer}
+}
+
+// units returns an amount in drops for XRP and in units of the currency otherwise
+func units(amount xrpl.Amount) *big.Rat {
+	if amount.IsNative() {
+		return new(big.Rat).SetInt64(drops(amount))
+	}
+	return issuedValue(amount)
+}
+
+// conversion is what crossing an order book spent and delivered
+type conversion struct {
+	spent     *big.Rat
+	delivered *big.Rat
+}
+
+// funds returns how much of an issue an account can spend: XRP above its reserve or its trust line
+// balance. It returns nil for an issuer spending its own currency, which is unlimited.
+func (l *Ledger) funds(s *state, address string, of issue) *big.Rat {
+	if of.native() {
+		account := s.account(address)
+		if account == nil {
+			return new(big.Rat)
+		}
+		spendable := account.Balance - l.reserve(account.OwnerCount)
+		if spendable < 0 {
+			spendable = 0
+		}
+		return new(big.Rat).SetInt64(spendable)
+	}
+	if address == of.Issuer {
+		return nil
+	}
+	line := s.line(address, of.Issuer, of.Currency)
+	if line == nil || frozen(line, of.Issuer) {
+		return new(big.Rat)
+	}
+	holding := line.holding(address)
+	if holding.Sign() < 0 {
+		return new(big.Rat)
+	}
+	return holding
+}
+
+// book returns the offers selling deliver for spend, best quality first
+func (s *state) book(spend, deliver issue) []string {
+	var indexes []string
+	for index, offer := range s.offers {
+		if issueOf(offer.TakerPays) == spend && issueOf(offer.TakerGets) == deliver {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		first, second := s.offers[indexes[i]], s.offers[indexes[j]]
+		if order := first.quality().Cmp(second.quality()); order != 0 {
+			return order < 0
+		}
+		return indexes[i] < indexes[j]
+	})
+	return indexes
+}
+
+// convert consumes offers selling deliver for spend, best quality first, paying recipient until
+// deliverLimit is delivered or spendLimit is spent; a nil limit is unlimited. The exchange is
+// applied to s, so quotes run it on a copy of the ledger.
+func (l *Ledger) convert(s *state, taker, recipient string, spend, deliver issue, spendLimit, deliverLimit *big.Rat) (*conversion, string) {
+	exchange := &conversion{spent: new(big.Rat), delivered: new(big.Rat)}
+	for _, index := range s.book(spend, deliver) {
+		offer := s.offers[index]
+		if offer.Account == taker {
+			continue
+		}
+
+		take := units(offer.TakerGets)
+		if funds := l.funds(s, offer.Account, deliver); funds != nil && funds.Cmp(take) < 0 {
+			take = funds
+		}
+		if deliverLimit != nil {
+			if remaining := new(big.Rat).Sub(deliverLimit, exchange.delivered); remaining.Cmp(take) < 0 {
+				take = remaining
+			}
+		}
+		quality := offer.quality()
+		if deliver.native() {
+			take = units(deliver.amount(take))
+		}
+		cost := new(big.Rat).Mul(take, quality)
+		if spend.native() {
+			cost = ceil(cost)
+		}
+		if spendLimit != nil {
+			if remaining := new(big.Rat).Sub(spendLimit, exchange.spent); remaining.Cmp(cost) < 0 {
+				cost = remaining
+				take = new(big.Rat).Quo(cost, quality)
+				if deliver.native() {
+					take = units(deliver.amount(take))
+				}
+			}
+		}
+		if take.Sign() <= 0 || cost.Sign() <= 0 {
+			continue
+		}
+
+		if result := l.move(s, taker, offer.Account, spend, cost); result != "tesSUCCESS" {
+			return nil, result
+		}
+		if result := l.move(s, offer.Account, recipient, deliver, take); result != "tesSUCCESS" {
+			return nil, result
+		}
+		offer.TakerGets = deliver.amount(new(big.Rat).Sub(units(offer.TakerGets), take))
+		offer.TakerPays = spend.amount(new(big.Rat).Sub(units(offer.TakerPays), cost))
+		if units(offer.TakerGets).Sign() <= 0 || units(offer.TakerPays).Sign() <= 0 {
+			delete(s.offers, index)
+			s.account(offer.Account).OwnerCount--
+		}
+
+		exchange.spent.Add(exchange.spent, cost)
+		exchange.delivered.Add(exchange.delivered, take)
+		if (deliverLimit != nil && exchange.delivered.Cmp(deliverLimit) >= 0) || (spendLimit != nil && exchange.spent.Cmp(spendLimit) >= 0) {
+			break
+		}
+	}
+	return exchange, "tesSUCCESS"
+}
+
+// move pays value of an issue from one account to another
+func (l *Ledger) move(s *state, from, to string, of issue, value *big.Rat) string {
+	if !of.native() {
+		return l.transferIssued(s, from, to, of.amount(value))
+	}
+	amount := drops(of.amount(value))
+	s.account(from).Balance -= amount
+	s.account(to).Balance += amount
+	return "tesSUCCESS"
+}
+
+// ceil rounds a value up to a whole number
+func ceil(value *big.Rat) *big.Rat {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return new(big.Rat).SetInt(quotient)
+}
+
+// applyConversion delivers a payment in a different currency than the sender spends by consuming
+// offers from the order book between the two
+func (l *Ledger) applyConversion(s *state, ctx *applyContext, destination string, amount, sendMax xrpl.Amount) string {
+	flags, _ := ctx.tx["Flags"].(uint32)
+	spend := issueOf(sendMax)
+	spendLimit := units(sendMax)
+	if funds := l.funds(s, ctx.account, spend); funds != nil && funds.Cmp(spendLimit) < 0 {
+		spendLimit = funds
+	}
+
+	exchange, result := l.convert(s, ctx.account, destination, spend, issueOf(amount), spendLimit, units(amount))
+	if result != "tesSUCCESS" {
+		return result
+	}
+	switch {
+	case exchange.delivered.Sign() == 0:
+		return "tecPATH_DRY"
+	case exchange.delivered.Cmp(units(amount)) < 0:
+		if flags&tfPartialPayment == 0 {
+			return "tecPATH_PARTIAL"
+		}
+		if deliverMin, ok := amountValue(ctx.tx, "DeliverMin"); ok && exchange.delivered.Cmp(units(deliverMin)) < 0 {
+			return "tecPATH_PARTIAL"
+		}
+	}
+
+	delivered := issueOf(amount).amount(exchange.delivered)
+	ctx.delivered = &delivered
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyOfferCreate(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	takerPays, _ := amountValue(tx, "TakerPays")
+	takerGets, _ := amountValue(tx, "TakerGets")
+	account := s.account(ctx.account)
+
+	// OfferSequence replaces an earlier offer of the account
+	if replaced, ok := tx["OfferSequence"].(uint32); ok {
+		removeOffer(s, ctx.account, replaced)
+	}
+	for _, amount := range []xrpl.Amount{takerPays, takerGets} {
+		if !amount.IsNative() && s.account(amount.Issuer) == nil {
+			return "tecNO_ISSUER"
+		}
+	}
+	if funds := l.funds(s, ctx.account, issueOf(takerGets)); funds != nil && funds.Sign() <= 0 {
+		return "tecUNFUNDED_OFFER"
+	}
+	if account.Balance < l.reserve(account.OwnerCount+1) {
+		return "tecINSUF_RESERVE_OFFER"
+	}
+
+	index, err := xrpl.OfferIndex(ctx.account, sequenceValue(tx))
+	if err != nil {
+		return "temMALFORMED"
+	}
+	s.offers[index] = &offerEntry{
+		Account:   ctx.account,
+		Sequence:  sequenceValue(tx),
+		TakerPays: takerPays,
+		TakerGets: takerGets,
+	}
+	account.OwnerCount++
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyOfferCancel(s *state, ctx *applyContext) string {
+	// Cancelling an offer that is already gone succeeds
+	removeOffer(s, ctx.account, ctx.tx["OfferSequence"].(uint32))
+	return "tesSUCCESS"
+}
+
+// removeOffer deletes an account's offer, returning the reserve it held
+func removeOffer(s *state, owner string, sequence uint32) {
+	index, err := xrpl.OfferIndex(owner, sequence)
+	if err != nil || s.offers[index] == nil {
+		return
+	}
+	delete(s.offers, index)
+	s.account(owner).OwnerCount--
+}
+
+// pathAlternatives quotes how source can deliver amount to destination from each issue it could
+// spend; an amount of -1 quotes how much sendMax can deliver. Quotes run on a copy of the open ledger.
+func (l *Ledger) pathAlternatives(source, destination string, amount xrpl.Amount, sendMax *xrpl.Amount, candidates []issue) []interface{} {
+	deliverAll := units(amount).Sign() < 0
+	target := issueOf(amount)
+	if sendMax != nil {
+		candidates = []issue{issueOf(*sendMax)}
+	}
+	if candidates == nil {
+		candidates = []issue{{}}
+		for _, line := range l.open.linesOf(source) {
+			counterparty := line.High
+			if !line.isLow(source) {
+				counterparty = line.Low
+			}
+			if line.holding(source).Sign() > 0 {
+				candidates = append(candidates, issue{Currency: line.Currency, Issuer: counterparty})
+			}
+		}
+	}
+
+	alternatives := []interface{}{}
+	for _, spend := range candidates {
+		spendLimit := l.funds(l.open, source, spend)
+		if sendMax != nil && (spendLimit == nil || units(*sendMax).Cmp(spendLimit) < 0) {
+			spendLimit = units(*sendMax)
+		}
+		var deliverLimit *big.Rat
+		if !deliverAll {
+			deliverLimit = units(amount)
+		}
+
+		alternative := map[string]interface{}{}
+		if spend == target {
+			// The same currency pays directly without a path
+			switch {
+			case deliverAll && spendLimit != nil:
+				alternative["source_amount"] = spend.amount(spendLimit)
+				alternative["destination_amount"] = spend.amount(spendLimit)
+			case deliverAll || (spendLimit != nil && spendLimit.Cmp(deliverLimit) < 0):
+				continue
+			default:
+				alternative["source_amount"] = amount
+			}
+			alternative["paths_computed"] = []interface{}{}
+			alternatives = append(alternatives, alternative)
+			continue
+		}
+
+		exchange, result := l.convert(l.open.clone(), source, destination, spend, target, spendLimit, deliverLimit)
+		if result != "tesSUCCESS" || exchange.delivered.Sign() == 0 || (!deliverAll && exchange.delivered.Cmp(deliverLimit) < 0) {
+			continue
+		}
+		if !spend.native() {
+			exchange.spent = roundValue(exchange.spent, true)
+		}
+		alternative["source_amount"] = spend.amount(exchange.spent)
+		if deliverAll {
+			if !target.native() {
+				exchange.delivered = roundValue(exchange.delivered, false)
+			}
+			alternative["destination_amount"] = target.amount(exchange.delivered)
+		}
+		alternative["paths_computed"] = []interface{}{[]interface{}{target.step()}}
+		alternatives = append(alternatives, alternative)
+	}
+	return alternatives
+}
+
+// roundValue rounds an issued value to the fifteen significant digits the ledger can represent,
+// up for amounts a quote spends and down for amounts it delivers
+func roundValue(value *big.Rat, up bool) *big.Rat {
+	whole := new(big.Int).Quo(value.Num(), value.Denom())
+	places := 15 - len(whole.String())
+	if places < 0 {
+		places = 0
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil))
+	scaled := new(big.Rat).Mul(value, scale)
+	rounded := new(big.Rat).SetInt(new(big.Int).Quo(scaled.Num(), scaled.Denom()))
+	if up {
+		rounded = ceil(scaled)
+	}
+	return rounded.Quo(rounded, scale)
+}
@@ -0,0 +1,636 @@
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// amendmentsIndex is the ledger entry ID of the singleton Amendments entry
+const amendmentsIndex = "7DB0788C020F02780A673DC74757F23823FA3014C1866E72CC4CD8B226CD6EF4"
+
+// rpcError is an error result in rippled's format
+type rpcError struct {
+	Code    string
+	Message string
+}
+
+// result renders the error as a rippled result object
+func (e *rpcError) result() map[string]interface{} {
+	return map[string]interface{}{
+		"status":        "error",
+		"error":         e.Code,
+		"error_message": e.Message,
+	}
+}
+
+// Handler serves JSON-RPC requests, and WebSocket connections for requests asking to upgrade
+func (l *Ledger) Handler() http.Handler {
+	webSocket := l.webSocketHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			webSocket.ServeHTTP(w, r)
+			return
+		}
+		l.serveJSONRPC(w, r)
+	})
+}
+
+// serveJSONRPC answers one JSON-RPC request
+func (l *Ledger) serveJSONRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Method string                   `json:"method"`
+		Params []map[string]interface{} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Unable to parse request", http.StatusBadRequest)
+		return
+	}
+	params := map[string]interface{}{}
+	if len(request.Params) > 0 && request.Params[0] != nil {
+		params = request.Params[0]
+	}
+
+	result, rpcErr := l.dispatch(request.Method, params)
+	if rpcErr != nil {
+		result = rpcErr.result()
+	} else {
+		result["status"] = "success"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+}
+
+// dispatch runs one command and returns its result
+func (l *Ledger) dispatch(method string, params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	injected := l.takeRequestFailure(method)
+	l.mu.Unlock()
+	if injected != "" {
+		return nil, &rpcError{Code: injected, Message: "Injected failure."}
+	}
+
+	switch method {
+	case "server_info":
+		return l.serverInfo(), nil
+	case "fee":
+		return l.fee(), nil
+	case "account_info":
+		return l.accountInfo(params)
+	case "account_lines":
+		return l.accountLines(params)
+	case "account_objects":
+		return l.accountObjects(params)
+	case "account_channels":
+		return l.accountChannels(params)
+	case "ledger_entry":
+		return l.ledgerEntry(params)
+	case "submit":
+		blob, _ := params["tx_blob"].(string)
+		if blob == "" {
+			return nil, &rpcError{Code: "invalidParams", Message: "Missing field 'tx_blob'."}
+		}
+		return l.submit(blob)
+	case "tx":
+		return l.transaction(params)
+	case "account_tx":
+		return l.accountTx(params)
+	case "ripple_path_find":
+		return l.ripplePathFind(params)
+	case "ledger_accept":
+		l.CloseLedger()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return map[string]interface{}{"ledger_current_index": l.openIndex}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	default:
+		return nil, &rpcError{Code: "unknownCmd", Message: "Unknown method."}
+	}
+}
+
+func (l *Ledger) serverInfo() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return map[string]interface{}{
+		"info": map[string]interface{}{
+			"build_version":    "simulator",
+			"complete_ledgers": l.completeLedgers(),
+			"server_state":     "full",
+			"load_factor":      l.loadFactor,
+			"validated_ledger": map[string]interface{}{
+				"age":              rippleTime(l.now) - l.closeTime,
+				"base_fee_xrp":     formatXRP(l.config.BaseFee),
+				"hash":             l.ledgerHash,
+				"reserve_base_xrp": formatXRP(l.config.ReserveBase),
+				"reserve_inc_xrp":  formatXRP(l.config.ReserveIncrement),
+				"seq":              l.openIndex - 1,
+			},
+		},
+	}
+}
+
+// fee reports the open ledger cost in the fee command's format. The simulator has no transaction
+// queue, so the minimum fee is the open ledger fee and the queue is always empty.
+func (l *Ledger) fee() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	openLedgerFee := l.requiredFee(l.config.BaseFee)
+	return map[string]interface{}{
+		"current_ledger_size":  strconv.Itoa(len(l.pending)),
+		"current_queue_size":   "0",
+		"expected_ledger_size": "1000",
+		"ledger_current_index": l.openIndex,
+		"drops": map[string]interface{}{
+			"base_fee":        formatDrops(l.config.BaseFee),
+			"median_fee":      formatDrops(l.config.BaseFee * 500),
+			"minimum_fee":     formatDrops(openLedgerFee),
+			"open_ledger_fee": formatDrops(openLedgerFee),
+		},
+	}
+}
+
+// ledgerFor selects the open ledger for "current" and the validated ledger otherwise
+func (l *Ledger) ledgerFor(params map[string]interface{}) (*state, map[string]interface{}, *rpcError) {
+	switch selector := params["ledger_index"].(type) {
+	case string:
+		if selector == "current" {
+			return l.open, map[string]interface{}{"ledger_current_index": l.openIndex, "validated": false}, nil
+		}
+		if selector != "" && selector != "validated" && selector != "closed" {
+			return nil, nil, &rpcError{Code: "lgrNotFound", Message: "ledgerNotFound"}
+		}
+	case float64:
+		if uint32(selector) != l.openIndex-1 {
+			return nil, nil, &rpcError{Code: "lgrNotFound", Message: "ledgerNotFound"}
+		}
+	}
+	return l.validated, map[string]interface{}{"ledger_index": l.openIndex - 1, "ledger_hash": l.ledgerHash, "validated": true}, nil
+}
+
+func (l *Ledger) accountInfo(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	address, _ := params["account"].(string)
+	index, err := xrpl.AccountRootIndex(address)
+	if err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+	s, result, rpcErr := l.ledgerFor(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	account := s.accounts[index]
+	if account == nil && l.faucet(address) != nil {
+		account = s.accounts[index]
+	}
+	if account == nil {
+		return nil, &rpcError{Code: "actNotFound", Message: "Account not found."}
+	}
+
+	result["account_data"] = entryJSON(index, account)
+	return result, nil
+}
+
+func (l *Ledger) accountLines(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	address, _ := params["account"].(string)
+	if _, err := xrpl.DecodeAccountID(address); err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+	s, result, rpcErr := l.ledgerFor(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.account(address) == nil {
+		return nil, &rpcError{Code: "actNotFound", Message: "Account not found."}
+	}
+	peerFilter, _ := params["peer"].(string)
+
+	lines := []interface{}{}
+	for _, line := range s.linesOf(address) {
+		peer := line.High
+		if !line.isLow(address) {
+			peer = line.Low
+		}
+		if peerFilter != "" && peer != peerFilter {
+			continue
+		}
+		lines = append(lines, map[string]interface{}{
+			"account":         peer,
+			"balance":         formatValue(line.holding(address)),
+			"currency":        line.Currency,
+			"limit":           formatValue(line.limit(address)),
+			"limit_peer":      formatValue(line.limit(peer)),
+			"quality_in":      0,
+			"quality_out":     0,
+			"no_ripple":       line.Flags&line.flag(address, lsfLowNoRipple, lsfHighNoRipple) != 0,
+			"no_ripple_peer":  line.Flags&line.flag(peer, lsfLowNoRipple, lsfHighNoRipple) != 0,
+			"authorized":      line.Flags&line.flag(address, lsfLowAuth, lsfHighAuth) != 0,
+			"peer_authorized": line.Flags&line.flag(peer, lsfLowAuth, lsfHighAuth) != 0,
+			"freeze":          line.Flags&line.flag(address, lsfLowFreeze, lsfHighFreeze) != 0,
+			"freeze_peer":     line.Flags&line.flag(peer, lsfLowFreeze, lsfHighFreeze) != 0,
+		})
+	}
+
+	result["account"] = address
+	result["lines"] = lines
+	return result, nil
+}
+
+// accountObjectTypes maps account_objects type filters to ledger entry types
+var accountObjectTypes = map[string]string{
+	"check":           "Check",
+	"escrow":          "Escrow",
+	"offer":           "Offer",
+	"payment_channel": "PayChannel",
+	"signer_list":     "SignerList",
+	"state":           "RippleState",
+	"ticket":          "Ticket",
+}
+
+func (l *Ledger) accountObjects(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	address, _ := params["account"].(string)
+	if _, err := xrpl.DecodeAccountID(address); err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+	var entryType string
+	if filter, ok := params["type"].(string); ok && filter != "" {
+		if entryType, ok = accountObjectTypes[filter]; !ok {
+			return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'type'."}
+		}
+	}
+	s, result, rpcErr := l.ledgerFor(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.account(address) == nil {
+		return nil, &rpcError{Code: "actNotFound", Message: "Account not found."}
+	}
+
+	blockersOnly, _ := params["deletion_blockers_only"].(bool)
+	owned := s.ownedBy(address)
+	indexes := make([]string, 0, len(owned))
+	for index, entry := range owned {
+		if blockersOnly && !blocksDeletion(entry) {
+			continue
+		}
+		if entryType == "" || entry.entryType() == entryType {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Strings(indexes)
+
+	// The marker is the index of the first entry of the next page
+	if marker, ok := params["marker"].(string); ok {
+		start := sort.SearchStrings(indexes, marker)
+		if start == len(indexes) || indexes[start] != marker {
+			return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'marker'."}
+		}
+		indexes = indexes[start:]
+	}
+	if limit, ok := uintParam(params["limit"]); ok && limit > 0 && int(limit) < len(indexes) {
+		result["limit"] = limit
+		result["marker"] = indexes[limit]
+		indexes = indexes[:limit]
+	}
+
+	objects := make([]interface{}, 0, len(indexes))
+	for _, index := range indexes {
+		objects = append(objects, entryJSON(index, owned[index]))
+	}
+	result["account"] = address
+	result["account_objects"] = objects
+	return result, nil
+}
+
+func (l *Ledger) accountChannels(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	address, _ := params["account"].(string)
+	if _, err := xrpl.DecodeAccountID(address); err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+	destination, _ := params["destination_account"].(string)
+	if _, err := xrpl.DecodeAccountID(destination); destination != "" && err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+	s, result, rpcErr := l.ledgerFor(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.account(address) == nil {
+		return nil, &rpcError{Code: "actNotFound", Message: "Account not found."}
+	}
+
+	// Only channels the account opened are listed, not those it receives
+	indexes := []string{}
+	for index, channel := range s.channels {
+		if channel.Account == address && (destination == "" || channel.Destination == destination) {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Strings(indexes)
+
+	if marker, ok := params["marker"].(string); ok {
+		start := sort.SearchStrings(indexes, marker)
+		if start == len(indexes) || indexes[start] != marker {
+			return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'marker'."}
+		}
+		indexes = indexes[start:]
+	}
+	if limit, ok := uintParam(params["limit"]); ok && limit > 0 && int(limit) < len(indexes) {
+		result["limit"] = limit
+		result["marker"] = indexes[limit]
+		indexes = indexes[:limit]
+	}
+
+	channels := make([]interface{}, 0, len(indexes))
+	for _, index := range indexes {
+		channels = append(channels, channelJSON(index, s.channels[index]))
+	}
+	result["account"] = address
+	result["channels"] = channels
+	return result, nil
+}
+
+func (l *Ledger) accountTx(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	address, _ := params["account"].(string)
+	if _, err := xrpl.DecodeAccountID(address); err != nil {
+		return nil, &rpcError{Code: "actMalformed", Message: "Account malformed."}
+	}
+
+	// -1 or an omitted bound selects the earliest or latest validated ledger
+	minLedger, maxLedger := uint32(genesisLedger), l.openIndex-1
+	if bound, ok := uintParam(params["ledger_index_min"]); ok && bound > minLedger {
+		minLedger = bound
+	}
+	if bound, ok := uintParam(params["ledger_index_max"]); ok && bound < maxLedger {
+		maxLedger = bound
+	}
+	if minLedger > maxLedger {
+		return nil, &rpcError{Code: "lgrIdxsInvalid", Message: "Ledger indexes invalid."}
+	}
+	forward, _ := params["forward"].(bool)
+
+	// The marker is the position in the history of the next transaction to return
+	position := -1
+	if marker, ok := params["marker"].(map[string]interface{}); ok {
+		seq, ok := uintParam(marker["seq"])
+		if !ok || int(seq) >= len(l.history) {
+			return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'marker'."}
+		}
+		position = int(seq)
+	}
+	step := 1
+	if !forward {
+		step = -1
+	}
+	if position < 0 {
+		position = 0
+		if !forward {
+			position = len(l.history) - 1
+		}
+	}
+	limit, _ := uintParam(params["limit"])
+	if limit == 0 {
+		limit = 200
+	}
+
+	accounts := map[string]bool{address: true}
+	transactions := []interface{}{}
+	result := map[string]interface{}{
+		"account":          address,
+		"ledger_index_min": minLedger,
+		"ledger_index_max": maxLedger,
+		"validated":        true,
+	}
+	for ; position >= 0 && position < len(l.history); position += step {
+		record := l.history[position]
+		if record.ledgerIndex < minLedger || record.ledgerIndex > maxLedger || !touchesAny(record, accounts) {
+			continue
+		}
+		if uint32(len(transactions)) == limit {
+			result["limit"] = limit
+			result["marker"] = map[string]interface{}{"ledger": record.ledgerIndex, "seq": position}
+			break
+		}
+		tx := txJSON(record)
+		tx["ledger_index"] = record.ledgerIndex
+		transactions = append(transactions, map[string]interface{}{
+			"tx":        tx,
+			"meta":      record.meta,
+			"validated": true,
+		})
+	}
+	result["transactions"] = transactions
+	return result, nil
+}
+
+// ripplePathFind quotes a payment against the open ledger's order books
+func (l *Ledger) ripplePathFind(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	source, _ := params["source_account"].(string)
+	if _, err := xrpl.DecodeAccountID(source); err != nil {
+		return nil, &rpcError{Code: "srcActMalformed", Message: "Source account is malformed."}
+	}
+	destination, _ := params["destination_account"].(string)
+	if _, err := xrpl.DecodeAccountID(destination); err != nil {
+		return nil, &rpcError{Code: "dstActMalformed", Message: "Destination account is malformed."}
+	}
+	amount, ok := amountValue(params, "destination_amount")
+	if !ok {
+		return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'destination_amount'."}
+	}
+	var sendMax *xrpl.Amount
+	if _, present := params["send_max"]; present {
+		value, ok := amountValue(params, "send_max")
+		if !ok {
+			return nil, &rpcError{Code: "invalidParams", Message: "Invalid field 'send_max'."}
+		}
+		sendMax = &value
+	}
+	if units(amount).Sign() < 0 && sendMax == nil {
+		return nil, &rpcError{Code: "invalidParams", Message: "Delivering as much as possible requires 'send_max'."}
+	}
+
+	var candidates []issue
+	if currencies, ok := params["source_currencies"].([]interface{}); ok {
+		candidates = []issue{}
+		for _, item := range currencies {
+			fields, _ := item.(map[string]interface{})
+			candidate := issue{Currency: stringValue(fields, "currency"), Issuer: stringValue(fields, "issuer")}
+			if candidate.Currency == "XRP" {
+				candidate = issue{}
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if l.open.account(source) == nil {
+		return nil, &rpcError{Code: "srcActNotFound", Message: "Source account not found."}
+	}
+	if l.open.account(destination) == nil {
+		return nil, &rpcError{Code: "actNotFound", Message: "Destination account not found."}
+	}
+
+	return map[string]interface{}{
+		"alternatives":         l.pathAlternatives(source, destination, amount, sendMax, candidates),
+		"source_account":       source,
+		"destination_account":  destination,
+		"destination_amount":   amount,
+		"ledger_current_index": l.openIndex,
+		"full_reply":           true,
+	}, nil
+}
+
+func (l *Ledger) ledgerEntry(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, result, rpcErr := l.ledgerFor(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var index string
+	switch {
+	case params["escrow"] != nil:
+		switch escrow := params["escrow"].(type) {
+		case string:
+			index = escrow
+		case map[string]interface{}:
+			owner, _ := escrow["owner"].(string)
+			sequence, ok := uintParam(escrow["seq"])
+			if !ok {
+				return nil, &rpcError{Code: "malformedRequest", Message: "Malformed request."}
+			}
+			var err error
+			if index, err = xrpl.EscrowIndex(owner, sequence); err != nil {
+				return nil, &rpcError{Code: "malformedOwner", Message: "Malformed owner."}
+			}
+		}
+	case params["ticket"] != nil:
+		ticket, _ := params["ticket"].(map[string]interface{})
+		account, _ := ticket["account"].(string)
+		ticketSequence, ok := uintParam(ticket["ticket_seq"])
+		if !ok {
+			return nil, &rpcError{Code: "malformedRequest", Message: "Malformed request."}
+		}
+		var err error
+		if index, err = xrpl.TicketIndex(account, ticketSequence); err != nil {
+			return nil, &rpcError{Code: "malformedAddress", Message: "Malformed address."}
+		}
+	case params["payment_channel"] != nil:
+		index, _ = params["payment_channel"].(string)
+	case params["check"] != nil:
+		index, _ = params["check"].(string)
+	case params["account_root"] != nil:
+		address, _ := params["account_root"].(string)
+		var err error
+		if index, err = xrpl.AccountRootIndex(address); err != nil {
+			return nil, &rpcError{Code: "malformedAddress", Message: "Malformed address."}
+		}
+	default:
+		index, _ = params["index"].(string)
+	}
+	index = strings.ToUpper(index)
+
+	if index == amendmentsIndex && len(l.amendments) > 0 {
+		ids := make([]string, 0, len(l.amendments))
+		for name := range l.amendments {
+			ids = append(ids, xrpl.AmendmentID(name))
+		}
+		sort.Strings(ids)
+		result["index"] = index
+		result["node"] = map[string]interface{}{
+			"LedgerEntryType": "Amendments",
+			"Amendments":      ids,
+			"Flags":           0,
+			"index":           index,
+		}
+		return result, nil
+	}
+
+	entry, ok := s.entries()[index]
+	if !ok {
+		return nil, &rpcError{Code: "entryNotFound", Message: "Entry not found."}
+	}
+	result["index"] = index
+	result["node"] = entryJSON(index, entry)
+	return result, nil
+}
+
+func (l *Ledger) transaction(params map[string]interface{}) (map[string]interface{}, *rpcError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hash, _ := params["transaction"].(string)
+	record, ok := l.records[strings.ToUpper(hash)]
+	if !ok {
+		return nil, &rpcError{Code: "txnNotFound", Message: "Transaction not found."}
+	}
+
+	result := txJSON(record)
+	validated := record.ledgerIndex < l.openIndex
+	result["validated"] = validated
+	if validated {
+		result["ledger_index"] = record.ledgerIndex
+		result["meta"] = record.meta
+	}
+	return result, nil
+}
+
+// txJSON returns the JSON form of a recorded transaction including its hash and, once validated,
+// the close time of its ledger
+func txJSON(record *txRecord) map[string]interface{} {
+	fields := make(map[string]interface{}, len(record.tx)+2)
+	for name, value := range record.tx {
+		fields[name] = value
+	}
+	fields["hash"] = record.hash
+	if record.closeTime != 0 {
+		fields["date"] = record.closeTime
+	}
+	return fields
+}
+
+// uintParam reads a non-negative integer request parameter given as a number or a string
+func uintParam(value interface{}) (uint32, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 || v != float64(uint32(v)) {
+			return 0, false
+		}
+		return uint32(v), true
+	case string:
+		number, err := strconv.ParseUint(v, 10, 32)
+		return uint32(number), err == nil
+	default:
+		return 0, false
+	}
+}
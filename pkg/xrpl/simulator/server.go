@@ -0,0 +1,79 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// Sandbox defaults: generous faucet funding and ledgers closing at roughly mainnet pace
+const (
+	SandboxFaucet        = 1000 * 1000000
+	SandboxCloseInterval = 4 * time.Second
+)
+
+// Server serves a simulated ledger over HTTP JSON-RPC and WebSocket
+type Server struct {
+	Ledger       *Ledger
+	URL          string
+	WebSocketURL string
+
+	httpServer    *http.Server
+	stopAutoClose func()
+}
+
+// Serve starts serving the ledger on addr, such as "127.0.0.1:0" for a free loopback port
+func Serve(addr string, ledger *Ledger) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := &Server{
+		Ledger:       ledger,
+		URL:          "http://" + listener.Addr().String(),
+		WebSocketURL: "ws://" + listener.Addr().String(),
+		httpServer:   &http.Server{Handler: ledger.Handler(), ReadHeaderTimeout: 10 * time.Second},
+	}
+	go func() {
+		if err := server.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("XRPL simulator stopped serving: %v", err)
+		}
+	}()
+	return server, nil
+}
+
+// StartSandbox serves a ledger on a loopback port that funds new accounts from a faucet, has
+// TokenEscrow enabled and closes ledgers on its own
+func StartSandbox() (*Server, error) {
+	ledger := New(Config{
+		Amendments: []string{xrpl.AmendmentTokenEscrow},
+		Faucet:     SandboxFaucet,
+	})
+	server, err := Serve("127.0.0.1:0", ledger)
+	if err != nil {
+		return nil, err
+	}
+	server.stopAutoClose = ledger.AutoClose(SandboxCloseInterval)
+
+	log.Printf("XRPL sandbox ledger listening on %s", server.URL)
+	return server, nil
+}
+
+// Close stops closing ledgers, disconnects subscribers and shuts the server down
+func (s *Server) Close() error {
+	if s.stopAutoClose != nil {
+		s.stopAutoClose()
+	}
+	s.Ledger.closeSubscribers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,1038 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+const xrp = 1000000
+
+// keyRing is a KeyProvider over the test accounts' keys
+type keyRing map[string]*xrpl.KeyPair
+
+func (k keyRing) SigningKey(address string) (*xrpl.KeyPair, error) {
+	keyPair, ok := k[address]
+	if !ok {
+		return nil, fmt.Errorf("no key for %s", address)
+	}
+	return keyPair, nil
+}
+
+// newAccount generates a key pair and funds its account when drops is positive
+func (k keyRing) newAccount(t *testing.T, ledger *Ledger, drops int64) string {
+	seed, err := xrpl.GenerateSeed(xrpl.KeyTypeSecp256k1)
+	require.NoError(t, err)
+	keyPair, err := xrpl.DeriveKeyPair(seed)
+	require.NoError(t, err)
+	k[keyPair.Address()] = keyPair
+	if drops > 0 {
+		require.NoError(t, ledger.Fund(keyPair.Address(), drops))
+	}
+	return keyPair.Address()
+}
+
+// newTestLedger serves a ledger over httptest and returns a JSON-RPC client signing with the key ring
+func newTestLedger(t *testing.T, config Config) (*Ledger, *xrpl.Client, keyRing) {
+	ledger := New(config)
+	server := httptest.NewServer(ledger.Handler())
+	t.Cleanup(server.Close)
+
+	client := xrpl.NewClientWithMode(server.URL, true, xrpl.ModeJSONRPC)
+	client.PollInterval = 10 * time.Millisecond
+	keys := keyRing{}
+	client.SetKeyProvider(keys)
+	return ledger, client, keys
+}
+
+// rippleTimeAfter returns a ledger time d after the simulator clock
+func rippleTimeAfter(ledger *Ledger, d time.Duration) uint32 {
+	return rippleTime(ledger.Now().Add(d))
+}
+
+func requireEngineResult(t *testing.T, code string, err error) {
+	t.Helper()
+	var txErr *xrpl.TransactionError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, code, txErr.Code)
+}
+
+// requireProvisionalResult checks a preliminary result that was not applied but is not final: the
+// transaction may still change before it is validated or expires
+func requireProvisionalResult(t *testing.T, code string, result *xrpl.TransactionResult, err error) {
+	t.Helper()
+	require.ErrorIs(t, err, xrpl.ErrProvisionalResult)
+	requireEngineResult(t, code, err)
+	require.NotNil(t, result)
+	assert.Equal(t, code, result.ResultCode)
+}
+
+func TestPayment_ValidatedWhenLedgerCloses(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 0)
+
+	result, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(20 * xrp)})
+	require.NoError(t, err)
+	assert.Equal(t, "tesSUCCESS", result.ResultCode)
+
+	pending, err := client.GetTransaction(result.TransactionID)
+	require.NoError(t, err)
+	assert.False(t, pending.Validated)
+	_, funded := ledger.Balance(bob)
+	assert.False(t, funded, "the open ledger is not validated yet")
+
+	closed := ledger.CloseLedger()
+	validated, err := client.WaitForValidation(context.Background(), result.TransactionID, result.LastLedgerSequence)
+	require.NoError(t, err)
+	assert.Equal(t, closed, validated.LedgerIndex)
+
+	balance, _ := ledger.Balance(bob)
+	assert.Equal(t, int64(20*xrp), balance)
+	balance, _ = ledger.Balance(alice)
+	assert.Equal(t, int64(80*xrp-10), balance)
+
+	info, err := client.GetAccountInfo(bob)
+	require.NoError(t, err)
+	assert.Equal(t, closed, info.Sequence, "new accounts start at the sequence of the ledger that created them")
+}
+
+func TestPayment_FailuresClaimFee(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 30*xrp)
+	bob := keys.newAccount(t, ledger, 0)
+
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(5 * xrp)})
+	requireProvisionalResult(t, "tecNO_DST_INSUF_XRP", provisional, err)
+
+	provisional, err = client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(25 * xrp)})
+	requireProvisionalResult(t, "tecUNFUNDED_PAYMENT", provisional, err)
+
+	ledger.CloseLedger()
+	balance, _ := ledger.Balance(alice)
+	assert.Equal(t, int64(30*xrp-20), balance)
+
+	info, err := client.GetAccountInfo(alice)
+	require.NoError(t, err)
+	initial := uint32(genesisLedger + 1)
+	assert.Equal(t, initial+2, info.Sequence, "tec results consume the sequence")
+}
+
+func TestSubmit_PreclaimChecks(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 100*xrp)
+	sequence := uint32(genesisLedger + 1)
+
+	sign := func(account string, fields xrpl.Transaction) *xrpl.SignedTransaction {
+		tx := xrpl.Transaction{
+			"TransactionType": "Payment",
+			"Account":         account,
+			"Destination":     bob,
+			"Amount":          "1000000",
+			"Fee":             "10",
+			"Flags":           uint32(0),
+			"Sequence":        sequence,
+		}
+		for name, value := range fields {
+			tx[name] = value
+		}
+		signed, err := xrpl.SignTransaction(tx, keys[account])
+		require.NoError(t, err)
+		return signed
+	}
+
+	provisional, err := client.SubmitSignedTransaction(sign(alice, xrpl.Transaction{"Sequence": sequence + 1}).TxBlob)
+	requireProvisionalResult(t, "terPRE_SEQ", provisional, err)
+	provisional, err = client.SubmitSignedTransaction(sign(alice, xrpl.Transaction{"Fee": "9"}).TxBlob)
+	requireProvisionalResult(t, "telINSUF_FEE_P", provisional, err)
+	_, err = client.SubmitSignedTransaction(sign(alice, xrpl.Transaction{"LastLedgerSequence": sequence - 1}).TxBlob)
+	requireEngineResult(t, "tefMAX_LEDGER", err)
+
+	first := sign(alice, nil)
+	_, err = client.SubmitSignedTransaction(first.TxBlob)
+	require.NoError(t, err)
+	provisional, err = client.SubmitSignedTransaction(first.TxBlob)
+	requireProvisionalResult(t, "tefALREADY", provisional, err)
+	_, err = client.SubmitSignedTransaction(sign(alice, xrpl.Transaction{"Amount": "2000000"}).TxBlob)
+	requireEngineResult(t, "tefPAST_SEQ", err)
+
+	carol := keys.newAccount(t, ledger, 0)
+	provisional, err = client.SubmitSignedTransaction(sign(carol, nil).TxBlob)
+	requireProvisionalResult(t, "terNO_ACCOUNT", provisional, err)
+
+	_, err = client.SubmitSignedTransaction("ABCD")
+	var rpcErr *xrpl.RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "invalidTransaction", rpcErr.Code)
+}
+
+func TestEscrow_ConditionalLifecycle(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	owner := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 20*xrp)
+
+	preimage, err := xrpl.GeneratePreimage()
+	require.NoError(t, err)
+	condition, fulfillment, err := xrpl.NewPreimageCondition(preimage)
+	require.NoError(t, err)
+
+	created, err := client.CreateEscrow(&xrpl.EscrowCreate{
+		Account:     owner,
+		Destination: payee,
+		Amount:      xrpl.XRPAmount(30 * xrp),
+		Condition:   condition,
+		FinishAfter: rippleTimeAfter(ledger, time.Hour),
+		CancelAfter: rippleTimeAfter(ledger, 48*time.Hour),
+	})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	escrow, err := client.GetEscrowInfo(owner, fmt.Sprint(created.Sequence))
+	require.NoError(t, err)
+	assert.Equal(t, "30000000", escrow.Amount.Value)
+	assert.Equal(t, condition, escrow.Condition)
+	assert.Equal(t, created.TransactionID, escrow.PreviousTxnID)
+	info, err := client.GetAccountInfo(owner)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), info.OwnerCount)
+	assert.Equal(t, "12000000", info.Reserve)
+
+	finish := &xrpl.EscrowFinish{Account: payee, Owner: owner, OfferSequence: created.Sequence, Condition: condition, Fulfillment: fulfillment}
+	provisional, err := client.FinishEscrow(finish)
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+
+	otherPreimage, err := xrpl.GeneratePreimage()
+	require.NoError(t, err)
+	otherCondition, otherFulfillment, err := xrpl.NewPreimageCondition(otherPreimage)
+	require.NoError(t, err)
+
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	provisional, err = client.FinishEscrow(&xrpl.EscrowFinish{Account: payee, Owner: owner, OfferSequence: created.Sequence, Condition: otherCondition, Fulfillment: otherFulfillment})
+	requireProvisionalResult(t, "tecCRYPTOCONDITION_ERROR", provisional, err)
+
+	finished, err := client.FinishEscrow(finish)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	_, err = client.WaitForValidation(context.Background(), finished.TransactionID, finished.LastLedgerSequence)
+	require.NoError(t, err)
+
+	_, err = client.GetEscrowInfo(owner, fmt.Sprint(created.Sequence))
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+	balance, _ := ledger.Balance(payee)
+	assert.Greater(t, balance, int64(49*xrp))
+	info, err = client.GetAccountInfo(owner)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), info.OwnerCount)
+}
+
+func TestEscrow_CancelAfterExpiry(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	owner := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 20*xrp)
+
+	_, err := client.CreateEscrow(&xrpl.EscrowCreate{Account: owner, Destination: payee, Amount: xrpl.XRPAmount(xrp)})
+	requireEngineResult(t, "temBAD_EXPIRATION", err)
+	provisional, err := client.CreateEscrow(&xrpl.EscrowCreate{Account: owner, Destination: payee, Amount: xrpl.XRPAmount(95 * xrp), FinishAfter: rippleTimeAfter(ledger, time.Hour)})
+	requireProvisionalResult(t, "tecUNFUNDED", provisional, err)
+
+	created, err := client.CreateEscrow(&xrpl.EscrowCreate{
+		Account:     owner,
+		Destination: payee,
+		Amount:      xrpl.XRPAmount(50 * xrp),
+		FinishAfter: rippleTimeAfter(ledger, time.Hour),
+		CancelAfter: rippleTimeAfter(ledger, 2*time.Hour),
+	})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	cancel := &xrpl.EscrowCancel{Account: owner, Owner: owner, OfferSequence: created.Sequence}
+	provisional, err = client.CancelEscrow(cancel)
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+
+	ledger.AdvanceTime(3 * time.Hour)
+	ledger.CloseLedger()
+	provisional, err = client.FinishEscrow(&xrpl.EscrowFinish{Account: payee, Owner: owner, OfferSequence: created.Sequence})
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+	_, err = client.CancelEscrow(cancel)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	balance, _ := ledger.Balance(owner)
+	assert.Equal(t, int64(100*xrp-40), balance, "the escrowed amount is refunded; only fees are spent")
+}
+
+func TestEscrow_DiscoveryAndResolution(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	owner := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 20*xrp)
+
+	var sequences []uint32
+	for i := int64(1); i <= 3; i++ {
+		created, err := client.CreateEscrow(&xrpl.EscrowCreate{
+			Account:     owner,
+			Destination: payee,
+			Amount:      xrpl.XRPAmount(i * xrp),
+			FinishAfter: rippleTimeAfter(ledger, time.Hour),
+			CancelAfter: rippleTimeAfter(ledger, 2*time.Hour),
+		})
+		require.NoError(t, err)
+		sequences = append(sequences, created.Sequence)
+	}
+	ledger.CloseLedger()
+
+	escrows, err := client.ListEscrows(owner)
+	require.NoError(t, err)
+	require.Len(t, escrows, 3)
+	for i, escrow := range escrows {
+		index, err := xrpl.EscrowIndex(owner, sequences[i])
+		require.NoError(t, err)
+		assert.Equal(t, sequences[i], escrow.Sequence)
+		assert.Equal(t, index, escrow.Index)
+		assert.Equal(t, xrpl.XRPAmount(int64(i+1)*xrp), escrow.Amount)
+	}
+
+	page, rpcErr := ledger.dispatch("account_objects", map[string]interface{}{"account": owner, "type": "escrow", "limit": float64(2)})
+	require.Nil(t, rpcErr)
+	assert.Len(t, page["account_objects"], 2)
+	require.NotNil(t, page["marker"])
+	page, rpcErr = ledger.dispatch("account_objects", map[string]interface{}{"account": owner, "type": "escrow", "marker": page["marker"]})
+	require.Nil(t, rpcErr)
+	assert.Len(t, page["account_objects"], 1)
+	assert.Nil(t, page["marker"])
+
+	ledger.AdvanceTime(90 * time.Minute)
+	ledger.CloseLedger()
+	finished, err := client.FinishEscrow(&xrpl.EscrowFinish{Account: payee, Owner: owner, OfferSequence: sequences[0]})
+	require.NoError(t, err)
+	ledger.AdvanceTime(time.Hour)
+	ledger.CloseLedger()
+	cancelled, err := client.CancelEscrow(&xrpl.EscrowCancel{Account: owner, Owner: owner, OfferSequence: sequences[1]})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	escrows, err = client.ListEscrows(owner)
+	require.NoError(t, err)
+	require.Len(t, escrows, 1)
+	assert.Equal(t, sequences[2], escrows[0].Sequence)
+
+	resolution, err := client.FindEscrowResolution(owner, sequences[0], 0)
+	require.NoError(t, err)
+	assert.True(t, resolution.Finished())
+	assert.Equal(t, finished.TransactionID, resolution.TransactionID)
+	assert.Equal(t, payee, resolution.Account)
+	assert.Equal(t, "tesSUCCESS", resolution.ResultCode)
+
+	resolution, err = client.FindEscrowResolution(owner, sequences[1], 0)
+	require.NoError(t, err)
+	assert.False(t, resolution.Finished())
+	assert.Equal(t, cancelled.TransactionID, resolution.TransactionID)
+	assert.Equal(t, "EscrowCancel", resolution.TransactionType)
+
+	_, err = client.FindEscrowResolution(owner, sequences[0], ledger.LedgerIndex())
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound, "the finish is older than the search window")
+	_, err = client.FindEscrowResolution(owner, sequences[2], 0)
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+}
+
+func TestEscrow_IssuedCurrencyNeedsTokenEscrow(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		config := Config{}
+		if enabled {
+			config.Amendments = []string{xrpl.AmendmentTokenEscrow}
+		}
+		ledger, client, keys := newTestLedger(t, config)
+		issuer := keys.newAccount(t, ledger, 100*xrp)
+		holder := keys.newAccount(t, ledger, 100*xrp)
+		payee := keys.newAccount(t, ledger, 100*xrp)
+
+		enabledOnLedger, err := client.AmendmentEnabled(xrpl.AmendmentTokenEscrow)
+		require.NoError(t, err)
+		assert.Equal(t, enabled, enabledOnLedger)
+
+		usd := xrpl.IssuedCurrency{Currency: "USD", Issuer: issuer}
+		limit, err := usd.Amount("1000")
+		require.NoError(t, err)
+		for _, account := range []string{holder, payee} {
+			_, err = client.SetTrustLine(&xrpl.TrustSet{Account: account, LimitAmount: limit})
+			require.NoError(t, err)
+		}
+		issued, err := usd.Amount("100")
+		require.NoError(t, err)
+		_, err = client.SendPayment(&xrpl.Payment{Account: issuer, Destination: holder, Amount: issued})
+		require.NoError(t, err)
+
+		escrowed, err := usd.Amount("40")
+		require.NoError(t, err)
+		created, err := client.CreateEscrow(&xrpl.EscrowCreate{
+			Account:     holder,
+			Destination: payee,
+			Amount:      escrowed,
+			FinishAfter: rippleTimeAfter(ledger, time.Minute),
+		})
+		if !enabled {
+			requireEngineResult(t, "temBAD_AMOUNT", err)
+			continue
+		}
+		require.NoError(t, err)
+
+		ledger.AdvanceTime(time.Hour)
+		ledger.CloseLedger()
+		lines, err := client.GetTrustLines(holder, issuer)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "60", lines[0].Balance)
+
+		_, err = client.FinishEscrow(&xrpl.EscrowFinish{Account: payee, Owner: holder, OfferSequence: created.Sequence})
+		require.NoError(t, err)
+		ledger.CloseLedger()
+		lines, err = client.GetTrustLines(payee, issuer)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "40", lines[0].Balance)
+		assert.Equal(t, "1000", lines[0].Limit)
+	}
+}
+
+func TestPayment_CrossCurrencyThroughOrderBook(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	issuer := keys.newAccount(t, ledger, 100*xrp)
+	maker := keys.newAccount(t, ledger, 1000*xrp)
+	payer := keys.newAccount(t, ledger, 1000*xrp)
+	payee := keys.newAccount(t, ledger, 100*xrp)
+
+	usd := xrpl.IssuedCurrency{Currency: "USD", Issuer: issuer}
+	usdAmount := func(value string) xrpl.Amount {
+		amount, err := usd.Amount(value)
+		require.NoError(t, err)
+		return amount
+	}
+	for _, account := range []string{maker, payer, payee} {
+		_, err := client.SetTrustLine(&xrpl.TrustSet{Account: account, LimitAmount: usdAmount("1000")})
+		require.NoError(t, err)
+	}
+	_, err := client.SendPayment(&xrpl.Payment{Account: issuer, Destination: maker, Amount: usdAmount("500")})
+	require.NoError(t, err)
+
+	// The maker sells 100 USD at 2 XRP and another 100 USD at 2.5 XRP
+	var offers []uint32
+	for _, price := range []int64{200, 250} {
+		placed, err := client.CreateOffer(&xrpl.OfferCreate{Account: maker, TakerGets: usdAmount("100"), TakerPays: xrpl.XRPAmount(price * xrp)})
+		require.NoError(t, err)
+		offers = append(offers, placed.Sequence)
+	}
+	ledger.CloseLedger()
+
+	quote, err := client.FindPaths(&xrpl.PathFindRequest{
+		SourceAccount:      payer,
+		DestinationAccount: payee,
+		DestinationAmount:  usdAmount("150"),
+		SourceCurrencies:   []xrpl.IssuedCurrency{{Currency: "XRP"}},
+	})
+	require.NoError(t, err)
+	best, err := quote.Best()
+	require.NoError(t, err)
+	assert.Equal(t, xrpl.XRPAmount(325*xrp), best.SourceAmount, "100 USD at 2 XRP and 50 USD at 2.5 XRP")
+
+	sendMax := xrpl.XRPAmount(330 * xrp)
+	paid, err := client.SendPayment(&xrpl.Payment{Account: payer, Destination: payee, Amount: usdAmount("150"), SendMax: &sendMax, Paths: best.Paths})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	validated, err := client.GetTransaction(paid.TransactionID)
+	require.NoError(t, err)
+	require.NotNil(t, validated.DeliveredAmount)
+	assert.Equal(t, usdAmount("150"), *validated.DeliveredAmount)
+	fee, _ := new(big.Rat).SetString(validated.Fee)
+	spent := new(big.Rat).Add(big.NewRat(325*xrp, 1), fee)
+	assert.Equal(t, 0, validated.Meta.BalanceChange(payer, "", "").Cmp(spent.Neg(spent)))
+	assert.Equal(t, 0, validated.Meta.BalanceChange(payee, "USD", issuer).Cmp(big.NewRat(150, 1)))
+	assert.Equal(t, 0, validated.Meta.BalanceChange(maker, "USD", issuer).Cmp(big.NewRat(-150, 1)))
+
+	// The first offer was consumed; 50 USD remain of the second
+	remaining, rpcErr := ledger.dispatch("account_objects", map[string]interface{}{"account": maker, "type": "offer"})
+	require.Nil(t, rpcErr)
+	require.Len(t, remaining["account_objects"], 1)
+	offer := remaining["account_objects"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, offers[1], offer["Sequence"])
+	assert.Equal(t, usdAmount("50"), offer["TakerGets"])
+
+	// Spending 100 XRP only buys 40 USD of what is left
+	limit := xrpl.XRPAmount(100 * xrp)
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: payer, Destination: payer, Amount: usdAmount("200"), SendMax: &limit})
+	requireProvisionalResult(t, "tecPATH_PARTIAL", provisional, err)
+
+	deliverMin := usdAmount("45")
+	provisional, err = client.SendPayment(&xrpl.Payment{Account: payer, Destination: payer, Amount: usdAmount("200"), SendMax: &limit, DeliverMin: &deliverMin, Flags: xrpl.PaymentFlagPartialPayment})
+	requireProvisionalResult(t, "tecPATH_PARTIAL", provisional, err)
+
+	deliverAll, err := client.FindPaths(&xrpl.PathFindRequest{SourceAccount: payer, DestinationAccount: payer, DestinationAmount: usdAmount("-1"), SendMax: &limit})
+	require.NoError(t, err)
+	best, err = deliverAll.Best()
+	require.NoError(t, err)
+	require.NotNil(t, best.DestinationAmount)
+	assert.Equal(t, usdAmount("40"), *best.DestinationAmount)
+
+	deliverMin = usdAmount("40")
+	converted, err := client.SendPayment(&xrpl.Payment{Account: payer, Destination: payer, Amount: usdAmount("200"), SendMax: &limit, DeliverMin: &deliverMin, Flags: xrpl.PaymentFlagPartialPayment})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	validated, err = client.GetTransaction(converted.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, usdAmount("40"), *validated.DeliveredAmount)
+	lines, err := client.GetTrustLines(payer, issuer)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "40", lines[0].Balance)
+
+	_, err = client.FindPaths(&xrpl.PathFindRequest{
+		SourceAccount:      payer,
+		DestinationAccount: payee,
+		DestinationAmount:  usdAmount("100"),
+		SourceCurrencies:   []xrpl.IssuedCurrency{{Currency: "XRP"}},
+	})
+	assert.ErrorIs(t, err, xrpl.ErrNoPath, "the book has only 10 USD left")
+
+	_, err = client.CancelOffer(&xrpl.OfferCancel{Account: maker, OfferSequence: offers[1]})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	remaining, rpcErr = ledger.dispatch("account_objects", map[string]interface{}{"account": maker, "type": "offer"})
+	require.Nil(t, rpcErr)
+	assert.Empty(t, remaining["account_objects"])
+}
+
+func TestPayment_PartialPaymentDeliversWhatTheSenderHolds(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	issuer := keys.newAccount(t, ledger, 100*xrp)
+	sender := keys.newAccount(t, ledger, 100*xrp)
+	receiver := keys.newAccount(t, ledger, 100*xrp)
+
+	usd := xrpl.IssuedCurrency{Currency: "USD", Issuer: issuer}
+	usdAmount := func(value string) xrpl.Amount {
+		amount, err := usd.Amount(value)
+		require.NoError(t, err)
+		return amount
+	}
+	for _, account := range []string{sender, receiver} {
+		_, err := client.SetTrustLine(&xrpl.TrustSet{Account: account, LimitAmount: usdAmount("1000000")})
+		require.NoError(t, err)
+	}
+	_, err := client.SendPayment(&xrpl.Payment{Account: issuer, Destination: sender, Amount: usdAmount("1")})
+	require.NoError(t, err)
+
+	// Without the flag the payment fails rather than delivering less
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: sender, Destination: receiver, Amount: usdAmount("1000")})
+	requireProvisionalResult(t, "tecPATH_PARTIAL", provisional, err)
+
+	paid, err := client.SendPayment(&xrpl.Payment{Account: sender, Destination: receiver, Amount: usdAmount("1000"), Flags: xrpl.PaymentFlagPartialPayment})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	delivery, err := client.GetPaymentDelivery(paid.TransactionID)
+	require.NoError(t, err)
+	assert.True(t, delivery.PartialPayment)
+	assert.True(t, delivery.Short())
+	assert.Equal(t, usdAmount("1000"), delivery.Amount)
+	assert.Equal(t, usdAmount("1"), delivery.Delivered)
+	assert.Equal(t, receiver, delivery.Destination)
+
+	// XRP sent as XRP cannot be partial
+	_, err = client.SendPayment(&xrpl.Payment{Account: sender, Destination: receiver, Amount: xrpl.XRPAmount(xrp), Flags: xrpl.PaymentFlagPartialPayment})
+	requireEngineResult(t, "temBAD_SEND_XRP_PARTIAL", err)
+}
+
+func TestMultiSigned_QuorumEnforced(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	treasury := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 100*xrp)
+	first := keys.newAccount(t, ledger, 0)
+	second := keys.newAccount(t, ledger, 0)
+	outsider := keys.newAccount(t, ledger, 0)
+
+	_, err := client.SetSignerList(treasury, 2, []xrpl.SignerEntry{{Account: first, SignerWeight: 1}, {Account: second, SignerWeight: 1}})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	submit := func(signers ...string) error {
+		payment, err := xrpl.TransactionFromStruct("Payment", &xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(xrp)})
+		require.NoError(t, err)
+		prepared, err := client.PrepareMultiSigned(payment, len(signers))
+		require.NoError(t, err)
+		signatures := make([]xrpl.Signer, 0, len(signers))
+		for _, signer := range signers {
+			signature, err := xrpl.MultiSignTransaction(prepared, keys[signer])
+			require.NoError(t, err)
+			signatures = append(signatures, *signature)
+		}
+		_, err = client.SubmitMultiSigned(prepared, signatures)
+		return err
+	}
+
+	// Disabling the master key leaves the signer list as the only way to sign
+	_, err = client.SignAndSubmit(xrpl.Transaction{"TransactionType": "AccountSet", "Account": treasury, "SetFlag": uint32(4)})
+	require.NoError(t, err)
+	_, err = client.SendPayment(&xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(xrp)})
+	requireEngineResult(t, "tefMASTER_DISABLED", err)
+
+	requireEngineResult(t, "tefBAD_QUORUM", submit(first))
+	requireEngineResult(t, "tefBAD_SIGNATURE", submit(first, outsider))
+	require.NoError(t, submit(first, second))
+}
+
+func TestInjectedFailures(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 100*xrp)
+	payment := &xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(xrp)}
+
+	ledger.FailNextRequest("server_info", "tooBusy")
+	_, err := client.GetServerInfo()
+	assert.ErrorIs(t, err, xrpl.ErrServerBusy)
+	_, err = client.GetServerInfo()
+	assert.NoError(t, err, "injected failures are consumed")
+
+	ledger.FailNextSubmit("telCAN_NOT_QUEUE")
+	provisional, err := client.SendPayment(payment)
+	requireProvisionalResult(t, "telCAN_NOT_QUEUE", provisional, err)
+
+	ledger.FailNextSubmit("tecPATH_DRY")
+	provisional, err = client.SendPayment(payment)
+	requireProvisionalResult(t, "tecPATH_DRY", provisional, err)
+
+	ledger.SetLoadFactor(3)
+	info, err := client.GetServerInfo()
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), info.LoadFactor)
+	_, err = client.SendPayment(payment)
+	require.NoError(t, err, "autofill pays the load-scaled fee")
+	ledger.SetLoadFactor(1)
+
+	// A dropped submission never validates, so it expires once its last ledger passes
+	ledger.DropNextSubmit()
+	dropped, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	for ledger.LedgerIndex() <= dropped.LastLedgerSequence {
+		ledger.CloseLedger()
+	}
+	_, err = client.WaitForValidation(context.Background(), dropped.TransactionID, dropped.LastLedgerSequence)
+	assert.ErrorIs(t, err, xrpl.ErrTransactionExpired)
+}
+
+func TestFee_ReportsOpenLedgerCost(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 100*xrp)
+
+	fees, err := client.GetFee()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), fees.BaseFee)
+	assert.Equal(t, int64(10), fees.OpenLedgerFee)
+	assert.Equal(t, float64(1), fees.LoadFactor())
+
+	ledger.SetLoadFactor(2.5)
+	fees, err = client.GetFee()
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), fees.OpenLedgerFee)
+	assert.Equal(t, 2.5, fees.LoadFactor())
+
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(xrp), Fee: "10"})
+	requireProvisionalResult(t, "telINSUF_FEE_P", provisional, err)
+
+	result, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(xrp), Fee: "25"})
+	require.NoError(t, err)
+	assert.Equal(t, "25", result.Fee)
+	ledger.CloseLedger()
+
+	validated, err := client.GetTransaction(result.TransactionID)
+	require.NoError(t, err)
+	assert.True(t, validated.Validated)
+	assert.Equal(t, "25", validated.Fee, "the validated transaction reports the fee it paid")
+}
+
+func TestFaucetFundsNewAccounts(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{Faucet: 500 * xrp})
+	alice := keys.newAccount(t, ledger, 0)
+	bob := keys.newAccount(t, ledger, 0)
+
+	_, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(100 * xrp)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	balance, _ := ledger.Balance(alice)
+	assert.Equal(t, int64(400*xrp-10), balance)
+	balance, _ = ledger.Balance(bob)
+	assert.Equal(t, int64(100*xrp), balance, "a payment creates the account rather than the faucet")
+}
+
+func TestStream_PublishesClosedLedgers(t *testing.T) {
+	ledger := New(Config{})
+	server, err := Serve("127.0.0.1:0", ledger)
+	require.NoError(t, err)
+	defer server.Close()
+
+	client := xrpl.NewClientWithMode(server.URL, true, xrpl.ModeJSONRPC)
+	keys := keyRing{}
+	client.SetKeyProvider(keys)
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 100*xrp)
+
+	stream := xrpl.NewSubscriptionClient(xrpl.StreamConfig{URL: server.WebSocketURL, Streams: []string{xrpl.StreamLedger}})
+	require.NoError(t, stream.SubscribeAccounts(bob))
+	ledgers := make(chan *xrpl.LedgerEvent, 10)
+	transactions := make(chan *xrpl.TransactionEvent, 10)
+	stream.OnLedger(func(event *xrpl.LedgerEvent) { ledgers <- event })
+	stream.OnTransaction(func(event *xrpl.TransactionEvent) { transactions <- event })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = stream.Run(ctx) }()
+	require.Eventually(t, stream.Connected, 5*time.Second, 10*time.Millisecond)
+
+	result, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(xrp)})
+	require.NoError(t, err)
+
+	// The subscription may not be registered yet when the first ledger closes
+	require.Eventually(t, func() bool {
+		ledger.CloseLedger()
+		return len(ledgers) > 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	select {
+	case event := <-transactions:
+		assert.Equal(t, result.TransactionID, event.Hash)
+		assert.True(t, event.Succeeded())
+		assert.Equal(t, bob, event.Destination)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no transaction event for the subscribed account")
+	}
+}
+
+func TestTickets_SubmitOutOfOrder(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	treasury := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 20*xrp)
+
+	created, err := client.CreateTickets(&xrpl.TicketCreate{Account: treasury, TicketCount: 3})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	tickets, err := client.GetTickets(treasury)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{created.Sequence + 1, created.Sequence + 2, created.Sequence + 3}, tickets)
+	info, err := client.GetAccountInfo(treasury)
+	require.NoError(t, err)
+	assert.Equal(t, created.Sequence+4, info.Sequence)
+	assert.Equal(t, uint32(3), info.OwnerCount)
+
+	// Tickets apply in any order and alongside the regular sequence
+	payment, err := client.SendPayment(&xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(xrp), TicketSequence: tickets[2]})
+	require.NoError(t, err)
+	assert.Equal(t, tickets[2], payment.TicketSequence)
+	_, err = client.SendPayment(&xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(xrp)})
+	require.NoError(t, err)
+	escrow, err := client.CreateEscrow(&xrpl.EscrowCreate{
+		Account:        treasury,
+		Destination:    payee,
+		Amount:         xrpl.XRPAmount(5 * xrp),
+		FinishAfter:    rippleTimeAfter(ledger, time.Hour),
+		TicketSequence: tickets[0],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tickets[0], escrow.Sequence, "the escrow is identified by its ticket")
+
+	_, err = client.SendPayment(&xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(2 * xrp), TicketSequence: tickets[2]})
+	requireEngineResult(t, "tefNO_TICKET", err)
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: treasury, Destination: payee, Amount: xrpl.XRPAmount(xrp), TicketSequence: info.Sequence + 10})
+	requireProvisionalResult(t, "terPRE_TICKET", provisional, err)
+	ledger.CloseLedger()
+
+	_, err = client.GetEscrowInfo(treasury, fmt.Sprint(tickets[0]))
+	require.NoError(t, err)
+	tickets, err = client.GetTickets(treasury)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{created.Sequence + 2}, tickets)
+	info, err = client.GetAccountInfo(treasury)
+	require.NoError(t, err)
+	assert.Equal(t, created.Sequence+5, info.Sequence, "only the unticketed payment used a sequence")
+	assert.Equal(t, uint32(2), info.OwnerCount, "one ticket and one escrow")
+}
+
+func TestPaymentChannel_StreamedClaimsAndSettleDelay(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	payer := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 100*xrp)
+	mallory := keys.newAccount(t, ledger, 0)
+	payerKey := keys[payer]
+
+	provisional, err := client.CreatePaymentChannel(&xrpl.PaymentChannelCreate{Account: payer, Destination: payee, Amount: xrpl.XRPAmount(95 * xrp), SettleDelay: 3600, PublicKey: payerKey.PublicKeyHex()})
+	requireProvisionalResult(t, "tecUNFUNDED", provisional, err)
+	created, err := client.CreatePaymentChannel(&xrpl.PaymentChannelCreate{Account: payer, Destination: payee, Amount: xrpl.XRPAmount(50 * xrp), SettleDelay: 3600, PublicKey: payerKey.PublicKeyHex()})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	channelID, err := xrpl.PaymentChannelIndex(payer, payee, created.Sequence)
+	require.NoError(t, err)
+	channels, err := client.ListPaymentChannels(payer, payee)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, channelID, channels[0].ChannelID)
+	assert.Equal(t, "50000000", channels[0].Amount)
+
+	redeem := func(drops uint64, signer *xrpl.KeyPair) (*xrpl.TransactionResult, error) {
+		signature, err := xrpl.SignPaymentChannelClaim(channelID, drops, signer)
+		require.NoError(t, err)
+		balance := xrpl.XRPAmount(int64(drops))
+		return client.ClaimPaymentChannel(&xrpl.PaymentChannelClaim{Account: payee, Channel: channelID, Balance: &balance, Signature: signature, PublicKey: signer.PublicKeyHex()})
+	}
+	_, err = redeem(10*xrp, payerKey)
+	require.NoError(t, err)
+	provisional, err = redeem(10*xrp, payerKey)
+	requireProvisionalResult(t, "tecUNFUNDED_PAYMENT", provisional, err)
+	provisional, err = redeem(60*xrp, payerKey)
+	requireProvisionalResult(t, "tecUNFUNDED_PAYMENT", provisional, err)
+	_, err = redeem(20*xrp, keys[mallory])
+	requireEngineResult(t, "temBAD_SIGNER", err)
+
+	provisional, err = client.FundPaymentChannel(&xrpl.PaymentChannelFund{Account: payee, Channel: channelID, Amount: xrpl.XRPAmount(xrp)})
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+	_, err = client.FundPaymentChannel(&xrpl.PaymentChannelFund{Account: payer, Channel: channelID, Amount: xrpl.XRPAmount(10 * xrp)})
+	require.NoError(t, err)
+
+	// Closing with claimable XRP left gives the payee the settle delay to redeem its last claim
+	_, err = client.ClaimPaymentChannel(&xrpl.PaymentChannelClaim{Account: payer, Channel: channelID, Flags: xrpl.PaymentChannelClaimFlagClose})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	channel, err := client.GetPaymentChannel(channelID)
+	require.NoError(t, err)
+	assert.Equal(t, "60000000", channel.Amount)
+	assert.Equal(t, "10000000", channel.Balance)
+	assert.NotZero(t, channel.Expiration)
+	_, err = redeem(25*xrp, payerKey)
+	require.NoError(t, err)
+
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	_, err = client.ClaimPaymentChannel(&xrpl.PaymentChannelClaim{Account: payer, Channel: channelID})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	_, err = client.GetPaymentChannel(channelID)
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+	balance, _ := ledger.Balance(payer)
+	assert.Equal(t, int64(75*xrp-50), balance, "the unclaimed XRP returns to the payer once the channel expires")
+	balance, _ = ledger.Balance(payee)
+	assert.Equal(t, int64(125*xrp-50), balance, "tec results still cost the fee, the rejected claim does not")
+	info, err := client.GetAccountInfo(payer)
+	require.NoError(t, err)
+	assert.Zero(t, info.OwnerCount)
+}
+
+func TestCheck_CashForMinimumAndCancelAfterExpiry(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	payer := keys.newAccount(t, ledger, 100*xrp)
+	payee := keys.newAccount(t, ledger, 50*xrp)
+	mallory := keys.newAccount(t, ledger, 20*xrp)
+
+	provisional, err := client.CreateCheck(&xrpl.CheckCreate{Account: payer, Destination: payee, SendMax: xrpl.XRPAmount(30 * xrp), Expiration: rippleTimeAfter(ledger, -time.Hour)})
+	requireProvisionalResult(t, "tecEXPIRED", provisional, err)
+	created, err := client.CreateCheck(&xrpl.CheckCreate{Account: payer, Destination: payee, SendMax: xrpl.XRPAmount(30 * xrp), Expiration: rippleTimeAfter(ledger, time.Hour)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	checkID, err := xrpl.CheckIndex(payer, created.Sequence)
+	require.NoError(t, err)
+	checks, err := client.ListChecks(payee)
+	require.NoError(t, err)
+	require.Len(t, checks, 1, "the destination lists checks it can cash")
+	assert.Equal(t, checkID, checks[0].Index)
+	balance, _ := ledger.Balance(payer)
+	assert.Equal(t, int64(100*xrp-20), balance, "writing a check moves no funds")
+
+	provisional, err = client.CashCheck(&xrpl.CheckCash{Account: mallory, CheckID: checkID, DeliverMin: &xrpl.Amount{Value: "1"}})
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+	tooMuch := xrpl.XRPAmount(40 * xrp)
+	provisional, err = client.CashCheck(&xrpl.CheckCash{Account: payee, CheckID: checkID, Amount: &tooMuch})
+	requireProvisionalResult(t, "tecPATH_PARTIAL", provisional, err)
+	minimum := xrpl.XRPAmount(20 * xrp)
+	cashed, err := client.CashCheck(&xrpl.CheckCash{Account: payee, CheckID: checkID, DeliverMin: &minimum})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	resolution, err := client.FindCheckResolution(payer, checkID, 0)
+	require.NoError(t, err)
+	assert.True(t, resolution.Cashed())
+	assert.Equal(t, cashed.TransactionID, resolution.TransactionID)
+	require.NotNil(t, resolution.DeliveredAmount)
+	assert.Equal(t, xrpl.XRPAmount(30*xrp), *resolution.DeliveredAmount, "cashing for a minimum takes all of SendMax")
+	_, err = client.GetCheck(checkID)
+	assert.ErrorIs(t, err, xrpl.ErrEntryNotFound)
+
+	created, err = client.CreateCheck(&xrpl.CheckCreate{Account: payer, Destination: payee, SendMax: xrpl.XRPAmount(10 * xrp), Expiration: rippleTimeAfter(ledger, time.Hour)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	checkID, err = xrpl.CheckIndex(payer, created.Sequence)
+	require.NoError(t, err)
+
+	cancel := &xrpl.CheckCancel{Account: mallory, CheckID: checkID}
+	provisional, err = client.CancelCheck(cancel)
+	requireProvisionalResult(t, "tecNO_PERMISSION", provisional, err)
+	ledger.AdvanceTime(2 * time.Hour)
+	ledger.CloseLedger()
+	exact := xrpl.XRPAmount(10 * xrp)
+	provisional, err = client.CashCheck(&xrpl.CheckCash{Account: payee, CheckID: checkID, Amount: &exact})
+	requireProvisionalResult(t, "tecEXPIRED", provisional, err)
+	cancelled, err := client.CancelCheck(cancel)
+	require.NoError(t, err, "anyone may remove an expired check")
+	ledger.CloseLedger()
+
+	resolution, err = client.FindCheckResolution(payer, checkID, 0)
+	require.NoError(t, err)
+	assert.False(t, resolution.Cashed())
+	assert.Equal(t, cancelled.TransactionID, resolution.TransactionID)
+	assert.Equal(t, mallory, resolution.Account)
+	assert.Nil(t, resolution.DeliveredAmount)
+
+	balance, _ = ledger.Balance(payee)
+	assert.Equal(t, int64(80*xrp-30), balance)
+	info, err := client.GetAccountInfo(payer)
+	require.NoError(t, err)
+	assert.Zero(t, info.OwnerCount)
+}
+
+func TestAccountDelete_SweepsBalanceOnceUnblocked(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	retiring := keys.newAccount(t, ledger, 50*xrp)
+	treasury := keys.newAccount(t, ledger, 100*xrp)
+
+	created, err := client.CreateCheck(&xrpl.CheckCreate{Account: retiring, Destination: treasury, SendMax: xrpl.XRPAmount(5 * xrp)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	reserve, err := client.GetAccountReserve(retiring)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12*xrp), reserve.ReserveDrops, "the check adds an owner reserve increment")
+	assert.Equal(t, int64(38*xrp-10), reserve.Spendable())
+	assert.NoError(t, reserve.Covers(1, 30*xrp))
+	assert.ErrorIs(t, reserve.Covers(1, 37*xrp), xrpl.ErrInsufficientReserve)
+
+	deletion := &xrpl.AccountDelete{Account: retiring, Destination: treasury}
+	_, err = client.DeleteAccount(deletion)
+	assert.ErrorIs(t, err, xrpl.ErrAccountNotDeletable, "the check blocks deletion")
+
+	checkID, err := xrpl.CheckIndex(retiring, created.Sequence)
+	require.NoError(t, err)
+	_, err = client.CancelCheck(&xrpl.CheckCancel{Account: retiring, CheckID: checkID})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	_, err = client.DeleteAccount(deletion)
+	assert.ErrorIs(t, err, xrpl.ErrAccountNotDeletable, "the account is too new")
+
+	for i := 0; i <= xrpl.AccountDeleteLedgerGap; i++ {
+		ledger.CloseLedger()
+	}
+	balance, _ := ledger.Balance(retiring)
+	result, err := client.DeleteAccount(deletion)
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	validated, err := client.GetTransaction(result.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, "2000000", validated.Fee, "deletion costs the owner reserve increment")
+	swept, _ := ledger.Balance(treasury)
+	assert.Equal(t, int64(100*xrp)+balance-2*xrp, swept)
+	_, err = client.GetAccountInfo(retiring)
+	assert.ErrorIs(t, err, xrpl.ErrAccountNotFound)
+}
+
+func TestAccountTransactions_PagesNormalizedHistory(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	alice := keys.newAccount(t, ledger, 100*xrp)
+	bob := keys.newAccount(t, ledger, 100*xrp)
+
+	var hashes []string
+	var ledgers []uint32
+	for i := int64(1); i <= 3; i++ {
+		result, err := client.SendPayment(&xrpl.Payment{Account: alice, Destination: bob, Amount: xrpl.XRPAmount(i * xrp)})
+		require.NoError(t, err)
+		hashes = append(hashes, result.TransactionID)
+		ledgers = append(ledgers, ledger.CloseLedger())
+	}
+
+	var history []xrpl.LedgerTransaction
+	request := &xrpl.AccountTransactionsRequest{Account: bob, MinLedger: ledgers[0], Limit: 2}
+	for pages := 1; ; pages++ {
+		page, err := client.GetAccountTransactions(request)
+		require.NoError(t, err)
+		history = append(history, page.Transactions...)
+		if page.Marker == nil {
+			assert.Equal(t, 2, pages)
+			break
+		}
+		request.Marker = page.Marker
+	}
+
+	require.Len(t, history, 3)
+	for i, tx := range history {
+		assert.Equal(t, hashes[i], tx.Hash)
+		assert.Equal(t, ledgers[i], tx.LedgerIndex)
+		assert.Equal(t, "Payment", tx.TransactionType)
+		assert.Equal(t, alice, tx.Account)
+		assert.Equal(t, bob, tx.Destination)
+		assert.True(t, tx.Succeeded())
+		assert.Equal(t, int64(10), tx.FeeDrops)
+		require.NotNil(t, tx.Delivered)
+		assert.Equal(t, xrpl.XRPAmount(int64(i+1)*xrp).Value, tx.Delivered.Value)
+		assert.False(t, tx.CloseTime.IsZero())
+	}
+
+	// The lower bound leaves out earlier ledgers
+	page, err := client.GetAccountTransactions(&xrpl.AccountTransactionsRequest{Account: bob, MinLedger: ledgers[2]})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, hashes[2], page.Transactions[0].Hash)
+
+	// Nothing is validated past the last closed ledger yet
+	_, err = client.GetAccountTransactions(&xrpl.AccountTransactionsRequest{Account: bob, MinLedger: ledgers[2] + 1})
+	assert.ErrorIs(t, err, xrpl.ErrLedgerNotFound)
+}
+
+func TestRequireDest_RoutesTaggedPaymentsToSharedWallet(t *testing.T) {
+	ledger, client, keys := newTestLedger(t, Config{})
+	omnibus := keys.newAccount(t, ledger, 100*xrp)
+	customer := keys.newAccount(t, ledger, 100*xrp)
+
+	_, err := client.SetAccountFlags(&xrpl.AccountSet{Account: omnibus, SetFlag: xrpl.AccountSetFlagRequireDest})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+	info, err := client.GetAccountInfo(omnibus)
+	require.NoError(t, err)
+	assert.True(t, info.RequiresDestinationTag())
+
+	provisional, err := client.SendPayment(&xrpl.Payment{Account: customer, Destination: omnibus, Amount: xrpl.XRPAmount(5 * xrp)})
+	requireProvisionalResult(t, "tecDST_TAG_NEEDED", provisional, err)
+
+	// An X-address destination is submitted as the classic address with its tag
+	xAddress, err := xrpl.EncodeXAddress(&xrpl.XAddress{ClassicAddress: omnibus, Tag: 1042, HasTag: true})
+	require.NoError(t, err)
+	result, err := client.SendPayment(&xrpl.Payment{Account: customer, Destination: xAddress, Amount: xrpl.XRPAmount(5 * xrp)})
+	require.NoError(t, err)
+	ledger.CloseLedger()
+
+	delivery, err := client.GetPaymentDelivery(result.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, omnibus, delivery.Destination)
+	require.NotNil(t, delivery.DestinationTag)
+	assert.Equal(t, uint32(1042), *delivery.DestinationTag)
+
+	_, err = client.SendPayment(&xrpl.Payment{Account: customer, Destination: xAddress, DestinationTag: 7, Amount: xrpl.XRPAmount(xrp)})
+	assert.Error(t, err, "a tag conflicting with the X-address is refused before submission")
+}
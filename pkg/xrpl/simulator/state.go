@@ -0,0 +1,676 @@
+package simulator
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// AccountRoot flags
+const (
+	lsfRequireDestTag = 0x00020000
+	lsfRequireAuth    = 0x00040000
+	lsfDisallowXRP    = 0x00080000
+	lsfDisableMaster  = 0x00100000
+	lsfDefaultRipple  = 0x00800000
+	lsfDepositAuth    = 0x01000000
+)
+
+// RippleState flags
+const (
+	lsfLowReserve   = 0x00010000
+	lsfHighReserve  = 0x00020000
+	lsfLowAuth      = 0x00040000
+	lsfHighAuth     = 0x00080000
+	lsfLowNoRipple  = 0x00100000
+	lsfHighNoRipple = 0x00200000
+	lsfLowFreeze    = 0x00400000
+	lsfHighFreeze   = 0x00800000
+)
+
+// noAccount is the placeholder issuer of a trust line balance
+const noAccount = "rrrrrrrrrrrrrrrrrrrrBZbvji"
+
+// ledgerEntry is a ledger object the simulator tracks
+type ledgerEntry interface {
+	entryType() string
+	// content returns the entry's fields without its type, index and threading fields
+	content() map[string]interface{}
+	thread() *threading
+}
+
+// threading records the last transaction that modified an entry
+type threading struct {
+	PreviousTxnID     string
+	PreviousTxnLgrSeq uint32
+}
+
+func (t *threading) thread() *threading {
+	return t
+}
+
+// accountRoot is an account's AccountRoot entry
+type accountRoot struct {
+	threading
+	Account     string
+	Balance     int64
+	Sequence    uint32
+	OwnerCount  uint32
+	Flags       uint32
+	Domain      string
+	TicketCount uint32
+}
+
+func (a *accountRoot) entryType() string {
+	return "AccountRoot"
+}
+
+func (a *accountRoot) content() map[string]interface{} {
+	fields := map[string]interface{}{
+		"Account":    a.Account,
+		"Balance":    formatDrops(a.Balance),
+		"Sequence":   a.Sequence,
+		"OwnerCount": a.OwnerCount,
+		"Flags":      a.Flags,
+	}
+	if a.Domain != "" {
+		fields["Domain"] = a.Domain
+	}
+	if a.TicketCount != 0 {
+		fields["TicketCount"] = a.TicketCount
+	}
+	return fields
+}
+
+// escrowEntry is an Escrow entry holding funds until it is finished or canceled
+type escrowEntry struct {
+	threading
+	Account        string
+	Destination    string
+	Amount         xrpl.Amount
+	Condition      string
+	FinishAfter    uint32
+	CancelAfter    uint32
+	DestinationTag *uint32
+	SourceTag      *uint32
+}
+
+func (e *escrowEntry) entryType() string {
+	return "Escrow"
+}
+
+func (e *escrowEntry) content() map[string]interface{} {
+	fields := map[string]interface{}{
+		"Account":         e.Account,
+		"Destination":     e.Destination,
+		"Amount":          e.Amount,
+		"Flags":           uint32(0),
+		"OwnerNode":       "0",
+		"DestinationNode": "0",
+	}
+	if e.Condition != "" {
+		fields["Condition"] = e.Condition
+	}
+	if e.FinishAfter != 0 {
+		fields["FinishAfter"] = e.FinishAfter
+	}
+	if e.CancelAfter != 0 {
+		fields["CancelAfter"] = e.CancelAfter
+	}
+	if e.DestinationTag != nil {
+		fields["DestinationTag"] = *e.DestinationTag
+	}
+	if e.SourceTag != nil {
+		fields["SourceTag"] = *e.SourceTag
+	}
+	return fields
+}
+
+// payChannelEntry is a PayChannel entry holding XRP its destination can claim with signed claims
+type payChannelEntry struct {
+	threading
+	Account        string
+	Destination    string
+	Amount         int64 // drops set aside in the channel
+	Balance        int64 // drops already paid out to the destination
+	PublicKey      string
+	SettleDelay    uint32
+	Expiration     uint32
+	CancelAfter    uint32
+	DestinationTag *uint32
+	SourceTag      *uint32
+}
+
+func (p *payChannelEntry) entryType() string {
+	return "PayChannel"
+}
+
+func (p *payChannelEntry) content() map[string]interface{} {
+	fields := map[string]interface{}{
+		"Account":     p.Account,
+		"Destination": p.Destination,
+		"Amount":      xrpl.XRPAmount(p.Amount),
+		"Balance":     xrpl.XRPAmount(p.Balance),
+		"PublicKey":   p.PublicKey,
+		"SettleDelay": p.SettleDelay,
+		"Flags":       uint32(0),
+		"OwnerNode":   "0",
+	}
+	if p.Expiration != 0 {
+		fields["Expiration"] = p.Expiration
+	}
+	if p.CancelAfter != 0 {
+		fields["CancelAfter"] = p.CancelAfter
+	}
+	if p.DestinationTag != nil {
+		fields["DestinationTag"] = *p.DestinationTag
+	}
+	if p.SourceTag != nil {
+		fields["SourceTag"] = *p.SourceTag
+	}
+	return fields
+}
+
+// expired reports whether the channel passed its expiration or cancel time as of closeTime
+func (p *payChannelEntry) expired(closeTime uint32) bool {
+	return (p.Expiration != 0 && closeTime >= p.Expiration) || (p.CancelAfter != 0 && closeTime >= p.CancelAfter)
+}
+
+// checkEntry is a Check entry its destination can cash for up to SendMax until it expires
+type checkEntry struct {
+	threading
+	Account        string
+	Destination    string
+	SendMax        xrpl.Amount
+	Sequence       uint32
+	Expiration     uint32
+	InvoiceID      string
+	DestinationTag *uint32
+	SourceTag      *uint32
+}
+
+func (c *checkEntry) entryType() string {
+	return "Check"
+}
+
+func (c *checkEntry) content() map[string]interface{} {
+	fields := map[string]interface{}{
+		"Account":         c.Account,
+		"Destination":     c.Destination,
+		"SendMax":         c.SendMax,
+		"Sequence":        c.Sequence,
+		"Flags":           uint32(0),
+		"OwnerNode":       "0",
+		"DestinationNode": "0",
+	}
+	if c.Expiration != 0 {
+		fields["Expiration"] = c.Expiration
+	}
+	if c.InvoiceID != "" {
+		fields["InvoiceID"] = c.InvoiceID
+	}
+	if c.DestinationTag != nil {
+		fields["DestinationTag"] = *c.DestinationTag
+	}
+	if c.SourceTag != nil {
+		fields["SourceTag"] = *c.SourceTag
+	}
+	return fields
+}
+
+// expired reports whether the check passed its expiration as of closeTime
+func (c *checkEntry) expired(closeTime uint32) bool {
+	return c.Expiration != 0 && closeTime >= c.Expiration
+}
+
+// trustLine is a RippleState entry between the numerically lower and higher account
+type trustLine struct {
+	threading
+	Low       string
+	High      string
+	Currency  string
+	Balance   *big.Rat // positive when the low account holds the currency
+	LowLimit  *big.Rat
+	HighLimit *big.Rat
+	Flags     uint32
+}
+
+func (t *trustLine) entryType() string {
+	return "RippleState"
+}
+
+func (t *trustLine) content() map[string]interface{} {
+	return map[string]interface{}{
+		"Balance":   xrpl.Amount{Currency: t.Currency, Issuer: noAccount, Value: formatValue(t.Balance)},
+		"LowLimit":  xrpl.Amount{Currency: t.Currency, Issuer: t.Low, Value: formatValue(t.LowLimit)},
+		"HighLimit": xrpl.Amount{Currency: t.Currency, Issuer: t.High, Value: formatValue(t.HighLimit)},
+		"Flags":     t.Flags,
+		"LowNode":   "0",
+		"HighNode":  "0",
+	}
+}
+
+// isLow reports whether account is the low side of the line
+func (t *trustLine) isLow(account string) bool {
+	return account == t.Low
+}
+
+// holding returns the balance held by account, negative when it owes its peer
+func (t *trustLine) holding(account string) *big.Rat {
+	if t.isLow(account) {
+		return new(big.Rat).Set(t.Balance)
+	}
+	return new(big.Rat).Neg(t.Balance)
+}
+
+// limit returns the most account is willing to hold
+func (t *trustLine) limit(account string) *big.Rat {
+	if t.isLow(account) {
+		return t.LowLimit
+	}
+	return t.HighLimit
+}
+
+// credit moves value to account from its peer
+func (t *trustLine) credit(account string, value *big.Rat) {
+	if t.isLow(account) {
+		t.Balance = new(big.Rat).Add(t.Balance, value)
+	} else {
+		t.Balance = new(big.Rat).Sub(t.Balance, value)
+	}
+}
+
+// flag returns the low or high variant of a side-specific flag for account
+func (t *trustLine) flag(account string, low, high uint32) uint32 {
+	if t.isLow(account) {
+		return low
+	}
+	return high
+}
+
+// signerList is a SignerList entry authorizing multi-signed transactions
+type signerList struct {
+	threading
+	Account string
+	Quorum  uint32
+	Entries []xrpl.SignerEntry
+}
+
+func (s *signerList) entryType() string {
+	return "SignerList"
+}
+
+func (s *signerList) content() map[string]interface{} {
+	entries := make([]interface{}, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		entries = append(entries, map[string]interface{}{"SignerEntry": map[string]interface{}{
+			"Account":      entry.Account,
+			"SignerWeight": entry.SignerWeight,
+		}})
+	}
+	return map[string]interface{}{
+		"SignerQuorum":  s.Quorum,
+		"SignerEntries": entries,
+		"SignerListID":  uint32(0),
+		"OwnerNode":     "0",
+		"Flags":         uint32(0),
+	}
+}
+
+// weight returns the weight of a signer, zero when it is not on the list
+func (s *signerList) weight(account string) uint32 {
+	for _, entry := range s.Entries {
+		if entry.Account == account {
+			return uint32(entry.SignerWeight)
+		}
+	}
+	return 0
+}
+
+// ticketEntry is a Ticket entry reserving a sequence number for later use
+type ticketEntry struct {
+	threading
+	Account        string
+	TicketSequence uint32
+}
+
+func (t *ticketEntry) entryType() string {
+	return "Ticket"
+}
+
+func (t *ticketEntry) content() map[string]interface{} {
+	return map[string]interface{}{
+		"Account":        t.Account,
+		"TicketSequence": t.TicketSequence,
+		"OwnerNode":      "0",
+		"Flags":          uint32(0),
+	}
+}
+
+// offerEntry is an Offer entry: Account sells TakerGets to whoever pays TakerPays for it
+type offerEntry struct {
+	threading
+	Account   string
+	Sequence  uint32
+	TakerPays xrpl.Amount
+	TakerGets xrpl.Amount
+}
+
+func (o *offerEntry) entryType() string {
+	return "Offer"
+}
+
+func (o *offerEntry) content() map[string]interface{} {
+	return map[string]interface{}{
+		"Account":   o.Account,
+		"Sequence":  o.Sequence,
+		"TakerPays": o.TakerPays,
+		"TakerGets": o.TakerGets,
+		"Flags":     uint32(0),
+		"OwnerNode": "0",
+		"BookNode":  "0",
+	}
+}
+
+// quality is the price of the offer: what a taker pays per unit it gets
+func (o *offerEntry) quality() *big.Rat {
+	return new(big.Rat).Quo(units(o.TakerPays), units(o.TakerGets))
+}
+
+// state is one version of the ledger's objects; entry maps are keyed by ledger entry ID
+type state struct {
+	accounts    map[string]*accountRoot
+	escrows     map[string]*escrowEntry
+	lines       map[string]*trustLine
+	offers      map[string]*offerEntry
+	channels    map[string]*payChannelEntry
+	checks      map[string]*checkEntry
+	signerLists map[string]*signerList
+	tickets     map[string]*ticketEntry
+}
+
+func newState() *state {
+	return &state{
+		accounts:    make(map[string]*accountRoot),
+		escrows:     make(map[string]*escrowEntry),
+		lines:       make(map[string]*trustLine),
+		offers:      make(map[string]*offerEntry),
+		channels:    make(map[string]*payChannelEntry),
+		checks:      make(map[string]*checkEntry),
+		signerLists: make(map[string]*signerList),
+		tickets:     make(map[string]*ticketEntry),
+	}
+}
+
+// clone deep-copies the state so a transaction can be applied without touching the original
+func (s *state) clone() *state {
+	copied := newState()
+	for index, account := range s.accounts {
+		entry := *account
+		copied.accounts[index] = &entry
+	}
+	for index, escrow := range s.escrows {
+		entry := *escrow
+		copied.escrows[index] = &entry
+	}
+	for index, line := range s.lines {
+		entry := *line
+		entry.Balance = new(big.Rat).Set(line.Balance)
+		entry.LowLimit = new(big.Rat).Set(line.LowLimit)
+		entry.HighLimit = new(big.Rat).Set(line.HighLimit)
+		copied.lines[index] = &entry
+	}
+	for index, offer := range s.offers {
+		entry := *offer
+		copied.offers[index] = &entry
+	}
+	for index, channel := range s.channels {
+		entry := *channel
+		copied.channels[index] = &entry
+	}
+	for index, check := range s.checks {
+		entry := *check
+		copied.checks[index] = &entry
+	}
+	for index, list := range s.signerLists {
+		entry := *list
+		entry.Entries = append([]xrpl.SignerEntry(nil), list.Entries...)
+		copied.signerLists[index] = &entry
+	}
+	for index, ticket := range s.tickets {
+		entry := *ticket
+		copied.tickets[index] = &entry
+	}
+	return copied
+}
+
+// account returns the AccountRoot of an address, or nil when the account does not exist
+func (s *state) account(address string) *accountRoot {
+	index, err := xrpl.AccountRootIndex(address)
+	if err != nil {
+		return nil
+	}
+	return s.accounts[index]
+}
+
+// createAccount adds an empty AccountRoot whose first sequence is the ledger it was created in
+func (s *state) createAccount(address string, ledgerIndex uint32) (*accountRoot, error) {
+	index, err := xrpl.AccountRootIndex(address)
+	if err != nil {
+		return nil, err
+	}
+	account := &accountRoot{Account: address, Sequence: ledgerIndex}
+	s.accounts[index] = account
+	return account, nil
+}
+
+// line returns the trust line between two accounts for a currency, or nil
+func (s *state) line(account, peer, currency string) *trustLine {
+	index, err := xrpl.RippleStateIndex(account, peer, currency)
+	if err != nil {
+		return nil
+	}
+	return s.lines[index]
+}
+
+// signerList returns the signer list of an account, or nil
+func (s *state) signerList(account string) *signerList {
+	index, err := xrpl.SignerListIndex(account)
+	if err != nil {
+		return nil
+	}
+	return s.signerLists[index]
+}
+
+// ticket returns the ticket an account holds for a sequence, or nil
+func (s *state) ticket(account string, ticketSequence uint32) *ticketEntry {
+	index, err := xrpl.TicketIndex(account, ticketSequence)
+	if err != nil {
+		return nil
+	}
+	return s.tickets[index]
+}
+
+// linesOf returns the trust lines an account is a party to
+func (s *state) linesOf(account string) []*trustLine {
+	var lines []*trustLine
+	for _, line := range s.lines {
+		if line.Low == account || line.High == account {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Currency+lines[i].Low+lines[i].High < lines[j].Currency+lines[j].Low+lines[j].High
+	})
+	return lines
+}
+
+// entries returns every ledger entry keyed by its ID
+func (s *state) entries() map[string]ledgerEntry {
+	entries := make(map[string]ledgerEntry, len(s.accounts)+len(s.escrows)+len(s.lines)+len(s.offers)+len(s.channels)+len(s.checks)+len(s.signerLists)+len(s.tickets))
+	for index, entry := range s.accounts {
+		entries[index] = entry
+	}
+	for index, entry := range s.escrows {
+		entries[index] = entry
+	}
+	for index, entry := range s.lines {
+		entries[index] = entry
+	}
+	for index, entry := range s.offers {
+		entries[index] = entry
+	}
+	for index, entry := range s.channels {
+		entries[index] = entry
+	}
+	for index, entry := range s.checks {
+		entries[index] = entry
+	}
+	for index, entry := range s.signerLists {
+		entries[index] = entry
+	}
+	for index, entry := range s.tickets {
+		entries[index] = entry
+	}
+	return entries
+}
+
+// ownedBy returns the entries in an account's owner directory keyed by their ID
+func (s *state) ownedBy(account string) map[string]ledgerEntry {
+	owned := make(map[string]ledgerEntry)
+	for index, entry := range s.escrows {
+		if entry.Account == account || entry.Destination == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.lines {
+		if entry.Low == account || entry.High == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.offers {
+		if entry.Account == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.channels {
+		if entry.Account == account || entry.Destination == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.checks {
+		if entry.Account == account || entry.Destination == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.signerLists {
+		if entry.Account == account {
+			owned[index] = entry
+		}
+	}
+	for index, entry := range s.tickets {
+		if entry.Account == account {
+			owned[index] = entry
+		}
+	}
+	return owned
+}
+
+// entryJSON renders an entry the way ledger_entry returns it
+func entryJSON(index string, entry ledgerEntry) map[string]interface{} {
+	fields := entry.content()
+	fields["LedgerEntryType"] = entry.entryType()
+	fields["index"] = index
+	if thread := entry.thread(); thread.PreviousTxnID != "" {
+		fields["PreviousTxnID"] = thread.PreviousTxnID
+		fields["PreviousTxnLgrSeq"] = thread.PreviousTxnLgrSeq
+	}
+	return fields
+}
+
+// diffStates describes how a transaction changed the ledger and threads the changed entries to it
+func diffStates(before, after *state, hash string, ledgerIndex uint32) []xrpl.AffectedNode {
+	beforeEntries, afterEntries := before.entries(), after.entries()
+
+	indexes := make([]string, 0, len(afterEntries))
+	for index := range beforeEntries {
+		indexes = append(indexes, index)
+	}
+	for index := range afterEntries {
+		if _, ok := beforeEntries[index]; !ok {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Strings(indexes)
+
+	nodes := []xrpl.AffectedNode{}
+	for _, index := range indexes {
+		old, existed := beforeEntries[index]
+		current, exists := afterEntries[index]
+
+		switch {
+		case !existed:
+			*current.thread() = threading{PreviousTxnID: hash, PreviousTxnLgrSeq: ledgerIndex}
+			nodes = append(nodes, xrpl.AffectedNode{CreatedNode: &xrpl.NodeChange{
+				LedgerEntryType: current.entryType(),
+				LedgerIndex:     index,
+				NewFields:       current.content(),
+			}})
+		case !exists:
+			final := old.content()
+			final["PreviousTxnID"] = old.thread().PreviousTxnID
+			final["PreviousTxnLgrSeq"] = old.thread().PreviousTxnLgrSeq
+			nodes = append(nodes, xrpl.AffectedNode{DeletedNode: &xrpl.NodeChange{
+				LedgerEntryType: old.entryType(),
+				LedgerIndex:     index,
+				PreviousTxnID:   old.thread().PreviousTxnID,
+				FinalFields:     final,
+			}})
+		default:
+			oldFields, newFields := old.content(), current.content()
+			previous := make(map[string]interface{})
+			for name, value := range oldFields {
+				if !reflect.DeepEqual(value, newFields[name]) {
+					previous[name] = value
+				}
+			}
+			if len(previous) == 0 && len(oldFields) == len(newFields) {
+				continue
+			}
+			*current.thread() = threading{PreviousTxnID: hash, PreviousTxnLgrSeq: ledgerIndex}
+			nodes = append(nodes, xrpl.AffectedNode{ModifiedNode: &xrpl.NodeChange{
+				LedgerEntryType: current.entryType(),
+				LedgerIndex:     index,
+				PreviousTxnID:   old.thread().PreviousTxnID,
+				FinalFields:     newFields,
+				PreviousFields:  previous,
+			}})
+		}
+	}
+	return nodes
+}
+
+// lowAndHigh orders two accounts by their numeric account IDs
+func lowAndHigh(account, peer string) (string, string) {
+	first, _ := xrpl.DecodeAccountID(account)
+	second, _ := xrpl.DecodeAccountID(peer)
+	if bytes.Compare(first, second) > 0 {
+		return peer, account
+	}
+	return account, peer
+}
+
+// formatValue renders an issued-currency value as the shortest decimal string
+func formatValue(value *big.Rat) string {
+	formatted := value.FloatString(20)
+	formatted = strings.TrimRight(formatted, "0")
+	formatted = strings.TrimSuffix(formatted, ".")
+	if formatted == "" || formatted == "-0" {
+		return "0"
+	}
+	return formatted
+}
@@ -0,0 +1,208 @@
+package simulator
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// writeTimeout bounds how long a slow subscriber can hold up ledger close notifications
+const writeTimeout = 5 * time.Second
+
+// subscriber is a WebSocket connection with its stream and account subscriptions
+type subscriber struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	// streams and accounts are guarded by the ledger's mutex
+	streams  map[string]bool
+	accounts map[string]bool
+}
+
+// send writes one message to the connection
+func (s *subscriber) send(message interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return websocket.JSON.Send(s.conn, message)
+}
+
+// delivery is a message queued for one subscriber
+type delivery struct {
+	subscriber *subscriber
+	message    interface{}
+}
+
+// webSocketHandler accepts WebSocket connections from any origin
+func (l *Ledger) webSocketHandler() websocket.Server {
+	return websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   l.serveWebSocket,
+	}
+}
+
+// serveWebSocket answers commands on a connection until it closes
+func (l *Ledger) serveWebSocket(conn *websocket.Conn) {
+	sub := &subscriber{conn: conn, streams: make(map[string]bool), accounts: make(map[string]bool)}
+	l.mu.Lock()
+	l.subscribers[sub] = struct{}{}
+	l.mu.Unlock()
+	defer l.removeSubscriber(sub)
+
+	for {
+		var request map[string]interface{}
+		if err := websocket.JSON.Receive(conn, &request); err != nil {
+			return
+		}
+		command, _ := request["command"].(string)
+
+		var result map[string]interface{}
+		var rpcErr *rpcError
+		switch command {
+		case "subscribe":
+			result = l.subscribe(sub, request, true)
+		case "unsubscribe":
+			result = l.subscribe(sub, request, false)
+		default:
+			result, rpcErr = l.dispatch(command, request)
+		}
+
+		response := map[string]interface{}{"type": "response", "id": request["id"]}
+		if rpcErr != nil {
+			for name, value := range rpcErr.result() {
+				response[name] = value
+			}
+		} else {
+			response["status"] = "success"
+			response["result"] = result
+		}
+		if err := sub.send(response); err != nil {
+			return
+		}
+	}
+}
+
+// subscribe adds or removes a connection's streams and accounts. Subscribing to the ledger
+// stream returns the latest validated ledger.
+func (l *Ledger) subscribe(sub *subscriber, request map[string]interface{}, add bool) map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	update := func(set map[string]bool, names interface{}) {
+		items, _ := names.([]interface{})
+		for _, item := range items {
+			if name, ok := item.(string); ok {
+				if add {
+					set[name] = true
+				} else {
+					delete(set, name)
+				}
+			}
+		}
+	}
+	update(sub.streams, request["streams"])
+	update(sub.accounts, request["accounts"])
+
+	result := map[string]interface{}{}
+	if add && sub.streams["ledger"] {
+		result = map[string]interface{}{
+			"fee_base":          l.config.BaseFee,
+			"ledger_hash":       l.ledgerHash,
+			"ledger_index":      l.openIndex - 1,
+			"ledger_time":       l.closeTime,
+			"reserve_base":      l.config.ReserveBase,
+			"reserve_inc":       l.config.ReserveIncrement,
+			"validated_ledgers": l.completeLedgers(),
+		}
+	}
+	return result
+}
+
+// notifications builds the stream messages for a closed ledger; the caller holds the mutex
+func (l *Ledger) notifications(closed uint32, records []*txRecord) []delivery {
+	ledgerClosed := map[string]interface{}{
+		"type":              "ledgerClosed",
+		"ledger_index":      closed,
+		"ledger_hash":       l.ledgerHash,
+		"ledger_time":       l.closeTime,
+		"fee_base":          l.config.BaseFee,
+		"reserve_base":      l.config.ReserveBase,
+		"reserve_inc":       l.config.ReserveIncrement,
+		"txn_count":         len(records),
+		"validated_ledgers": l.completeLedgers(),
+	}
+
+	var deliveries []delivery
+	for sub := range l.subscribers {
+		if sub.streams["ledger"] {
+			deliveries = append(deliveries, delivery{subscriber: sub, message: ledgerClosed})
+		}
+		for _, record := range records {
+			if !sub.streams["transactions"] && !touchesAny(record, sub.accounts) {
+				continue
+			}
+			deliveries = append(deliveries, delivery{subscriber: sub, message: map[string]interface{}{
+				"type":                  "transaction",
+				"engine_result":         record.result,
+				"engine_result_message": engineMessage(record.result),
+				"ledger_index":          closed,
+				"ledger_hash":           l.ledgerHash,
+				"validated":             true,
+				"status":                "closed",
+				"transaction":           txJSON(record),
+				"meta":                  record.meta,
+			}})
+		}
+	}
+	return deliveries
+}
+
+// touchesAny reports whether a transaction involves any of the accounts
+func touchesAny(record *txRecord, accounts map[string]bool) bool {
+	if len(accounts) == 0 {
+		return false
+	}
+	for _, field := range []string{"Account", "Destination", "Owner"} {
+		if accounts[stringValue(record.tx, field)] {
+			return true
+		}
+	}
+	nodes, _ := record.meta["AffectedNodes"].([]xrpl.AffectedNode)
+	for _, node := range nodes {
+		for _, change := range []*xrpl.NodeChange{node.CreatedNode, node.ModifiedNode, node.DeletedNode} {
+			if change == nil || change.LedgerEntryType != "AccountRoot" {
+				continue
+			}
+			if accounts[stringValue(change.NewFields, "Account")] || accounts[stringValue(change.FinalFields, "Account")] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeSubscriber forgets a connection and closes it
+func (l *Ledger) removeSubscriber(sub *subscriber) {
+	l.mu.Lock()
+	delete(l.subscribers, sub)
+	l.mu.Unlock()
+	sub.conn.Close()
+}
+
+// closeSubscribers disconnects every WebSocket connection
+func (l *Ledger) closeSubscribers() {
+	l.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(l.subscribers))
+	for sub := range l.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	l.mu.Unlock()
+
+	for _, sub := range subscribers {
+		l.removeSubscriber(sub)
+	}
+}
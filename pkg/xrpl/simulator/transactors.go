@@ -0,0 +1,1110 @@
+package simulator
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
+)
+
+// AccountSet flags and the AccountRoot flags they toggle
+var accountSetFlags = map[uint32]uint32{
+	1: lsfRequireDestTag,
+	2: lsfRequireAuth,
+	3: lsfDisallowXRP,
+	4: lsfDisableMaster,
+	8: lsfDefaultRipple,
+	9: lsfDepositAuth,
+}
+
+// asfDisableMaster is the AccountSet flag that disables an account's master key
+const asfDisableMaster = 4
+
+// errMalformedSignerEntry is returned for a SignerEntries item that is not a SignerEntry object
+var errMalformedSignerEntry = errors.New("signer entries must be SignerEntry objects")
+
+// TrustSet flags
+const (
+	tfSetfAuth      = 0x00010000
+	tfSetNoRipple   = 0x00020000
+	tfClearNoRipple = 0x00040000
+	tfSetFreeze     = 0x00100000
+	tfClearFreeze   = 0x00200000
+)
+
+// engineMessages holds rippled's descriptions of the engine results the simulator returns
+var engineMessages = map[string]string{
+	"tesSUCCESS":               "The transaction was applied. Only final in a validated ledger.",
+	"tecCRYPTOCONDITION_ERROR": "Malformed, invalid, or mismatched conditional or fulfillment.",
+	"tecDIR_FULL":              "Can not add entry to full directory.",
+	"tecDST_TAG_NEEDED":        "A destination tag is required.",
+	"tecEXPIRED":               "Expiration time is passed.",
+	"tecHAS_OBLIGATIONS":       "The account cannot be deleted since it has obligations.",
+	"tecINSUFFICIENT_FUNDS":    "Not enough funds available to complete requested transaction.",
+	"tecINSUFFICIENT_RESERVE":  "Insufficient reserve to complete requested operation.",
+	"tecINSUF_RESERVE_OFFER":   "Insufficient reserve to create offer.",
+	"tecNEED_MASTER_KEY":       "The operation requires the use of the Master Key.",
+	"tecNO_ALTERNATIVE_KEY":    "The operation would remove the ability to sign transactions with the account.",
+	"tecNO_DST":                "Destination does not exist. Send XRP to create it.",
+	"tecNO_ENTRY":              "No matching entry found.",
+	"tecNO_DST_INSUF_XRP":      "Destination does not exist. Too little XRP sent to create it.",
+	"tecNO_ISSUER":             "Issuer account does not exist.",
+	"tecNO_LINE":               "No such line.",
+	"tecNO_LINE_INSUF_RESERVE": "No such line. Too little reserve to create it.",
+	"tecNO_LINE_REDUNDANT":     "Can't set non-existent line to default.",
+	"tecNO_PERMISSION":         "No permission to perform requested operation.",
+	"tecNO_TARGET":             "Target account does not exist.",
+	"tecPATH_DRY":              "Path could not send partial amount.",
+	"tecPATH_PARTIAL":          "Path could not send full amount.",
+	"tecTOO_SOON":              "It is too early to attempt the requested operation. Please wait.",
+	"tecUNFUNDED":              "Not enough XRP to satisfy the reserve requirement.",
+	"tecUNFUNDED_OFFER":        "Insufficient balance to fund created offer.",
+	"tecUNFUNDED_PAYMENT":      "Insufficient XRP balance to send.",
+	"tefALREADY":               "The exact transaction was already in this ledger.",
+	"tefBAD_AUTH":              "Transaction's public key is not authorized.",
+	"tefBAD_QUORUM":            "Signatures provided do not meet the quorum.",
+	"tefBAD_SIGNATURE":         "A signature is provided for a non-signer.",
+	"tefMASTER_DISABLED":       "Master key is disabled.",
+	"tefMAX_LEDGER":            "Ledger sequence too high.",
+	"tefNOT_MULTI_SIGNING":     "Account has no appropriate list of multi-signers.",
+	"tefNO_TICKET":             "Ticket is not in ledger.",
+	"tefPAST_SEQ":              "This sequence number has already passed.",
+	"telINSUF_FEE_P":           "Fee insufficient.",
+	"temBAD_AMOUNT":            "Can only send positive amounts.",
+	"temBAD_EXPIRATION":        "Malformed: Bad expiration.",
+	"temBAD_FEE":               "Invalid fee, negative or not XRP.",
+	"temBAD_LIMIT":             "Limits must be non-negative.",
+	"temBAD_OFFER":             "Malformed: Bad offer.",
+	"temBAD_QUORUM":            "Malformed: Quorum is unreachable.",
+	"temBAD_SEND_XRP_PARTIAL":  "Partial payment is not allowed for XRP to XRP.",
+	"temBAD_SEQUENCE":          "Malformed: Sequence is not in the past.",
+	"temBAD_SIGNATURE":         "Malformed: Bad signature.",
+	"temBAD_SIGNER":            "Malformed: No signer may duplicate account or other signers.",
+	"temBAD_WEIGHT":            "Malformed: Weight must be a positive value.",
+	"temDST_IS_SRC":            "Destination may not be source.",
+	"temINVALID_COUNT":         "Malformed: Count field outside valid range.",
+	"temINVALID_FLAG":          "The transaction has an invalid flag.",
+	"temMALFORMED":             "Malformed transaction.",
+	"temREDUNDANT":             "The transaction is redundant.",
+	"temSEQ_AND_TICKET":        "Transaction contains a TicketSequence and a non-zero Sequence.",
+	"temUNKNOWN":               "The transaction requires logic that is not implemented yet.",
+	"terINSUF_FEE_B":           "Account balance can't pay fee.",
+	"terNO_ACCOUNT":            "The source account does not exist.",
+	"terPRE_SEQ":               "Missing/inapplicable prior transaction.",
+	"terPRE_TICKET":            "Ticket is not yet in ledger.",
+}
+
+// engineMessage returns the description of an engine result
+func engineMessage(result string) string {
+	if message, ok := engineMessages[result]; ok {
+		return message
+	}
+	return result
+}
+
+// applyContext carries what a transactor needs beyond the transaction itself
+type applyContext struct {
+	tx        xrpl.Transaction
+	account   string
+	master    bool // signed with the account's master key rather than multi-signed
+	delivered *xrpl.Amount
+}
+
+// submit runs a signed transaction blob through the ledger's checks and applies it to the open
+// ledger. It returns the submit result, or an RPC error when the blob cannot be processed.
+func (l *Ledger) submit(blob string) (map[string]interface{}, *rpcError) {
+	data, err := hex.DecodeString(blob)
+	if err != nil {
+		return nil, &rpcError{Code: "invalidTransaction", Message: "fails local checks: Invalid blob."}
+	}
+	tx, err := xrpl.DecodeTransaction(data)
+	if err != nil {
+		return nil, &rpcError{Code: "invalidTransaction", Message: "fails local checks: " + err.Error()}
+	}
+	hash := xrpl.TransactionHash(data)
+
+	signers, err := verifySignatures(tx)
+	if err != nil {
+		return nil, &rpcError{Code: "invalidTransaction", Message: "fails local checks: Invalid signature."}
+	}
+
+	result := l.process(tx, hash, signers)
+
+	applied := strings.HasPrefix(result, "tes") || strings.HasPrefix(result, "tec")
+	return map[string]interface{}{
+		"engine_result":         result,
+		"engine_result_message": engineMessage(result),
+		"tx_blob":               strings.ToUpper(blob),
+		"tx_json":               txJSON(&txRecord{hash: hash, tx: tx}),
+		"accepted":              applied,
+		"applied":               applied,
+		"status":                "success",
+	}, nil
+}
+
+// verifySignatures checks every signature of a transaction, returning its signers when multi-signed
+func verifySignatures(tx xrpl.Transaction) ([]xrpl.Signer, error) {
+	wrapped, multiSigned := tx["Signers"].([]interface{})
+	if !multiSigned {
+		return nil, xrpl.VerifyTransaction(tx)
+	}
+
+	signers := make([]xrpl.Signer, 0, len(wrapped))
+	for _, item := range wrapped {
+		fields, _ := item.(map[string]interface{})["Signer"].(map[string]interface{})
+		signer := xrpl.Signer{
+			Account:       stringValue(fields, "Account"),
+			SigningPubKey: stringValue(fields, "SigningPubKey"),
+			TxnSignature:  stringValue(fields, "TxnSignature"),
+		}
+		if err := xrpl.VerifyMultiSignature(tx, signer); err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// process applies a verified transaction and returns its engine result
+func (l *Ledger) process(tx xrpl.Transaction, hash string, signers []xrpl.Signer) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.dropSubmits > 0 {
+		l.dropSubmits--
+		return "tesSUCCESS"
+	}
+	var injected string
+	if len(l.failSubmits) > 0 {
+		injected = l.failSubmits[0]
+		l.failSubmits = l.failSubmits[1:]
+		if !strings.HasPrefix(injected, "tec") {
+			return injected
+		}
+	}
+
+	if _, ok := l.records[hash]; ok {
+		return "tefALREADY"
+	}
+
+	if result := preflight(tx, l.amendments); result != "tesSUCCESS" {
+		return result
+	}
+
+	ctx := &applyContext{tx: tx, account: stringValue(tx, "Account"), master: len(signers) == 0}
+	fee, _ := strconv.ParseInt(stringValue(tx, "Fee"), 10, 64)
+	if result := l.preclaim(ctx, fee, signers); result != "tesSUCCESS" {
+		return result
+	}
+
+	before := l.open
+	working := before.clone()
+	result := injected
+	if result == "" {
+		result = l.transact(working, ctx, fee)
+	}
+	if result != "tesSUCCESS" {
+		// Only a tec result claims a fee; anything else leaves the ledger as it was
+		if !strings.HasPrefix(result, "tec") {
+			return result
+		}
+		// A failed transaction only consumes its fee and its sequence or ticket
+		working = before.clone()
+		chargeFee(working, ctx, fee)
+		ctx.delivered = nil
+	}
+
+	meta := map[string]interface{}{
+		"TransactionIndex":  len(l.pending),
+		"TransactionResult": result,
+		"AffectedNodes":     diffStates(before, working, hash, l.openIndex),
+	}
+	if ctx.delivered != nil {
+		meta["delivered_amount"] = *ctx.delivered
+	}
+	l.open = working
+
+	record := &txRecord{hash: hash, tx: tx, result: result, ledgerIndex: l.openIndex, meta: meta}
+	l.pending = append(l.pending, record)
+	l.records[hash] = record
+	return result
+}
+
+// preflight checks a transaction for errors that do not depend on ledger state
+func preflight(tx xrpl.Transaction, amendments map[string]bool) string {
+	account := stringValue(tx, "Account")
+	if _, err := xrpl.DecodeAccountID(account); err != nil {
+		return "temMALFORMED"
+	}
+	if fee, err := strconv.ParseInt(stringValue(tx, "Fee"), 10, 64); err != nil || fee < 0 {
+		return "temBAD_FEE"
+	}
+	sequence, ok := tx["Sequence"].(uint32)
+	if !ok {
+		return "temMALFORMED"
+	}
+	if _, ok := tx["TicketSequence"].(uint32); ok && sequence != 0 {
+		return "temSEQ_AND_TICKET"
+	}
+
+	switch tx["TransactionType"] {
+	case "Payment":
+		amount, ok := amountValue(tx, "Amount")
+		if !ok || !positive(amount) {
+			return "temBAD_AMOUNT"
+		}
+		destination := stringValue(tx, "Destination")
+		if destination == "" {
+			return "temMALFORMED"
+		}
+		sendMax, converts := amountValue(tx, "SendMax")
+		if _, present := tx["SendMax"]; present && (!converts || !positive(sendMax)) {
+			return "temBAD_AMOUNT"
+		}
+		converts = converts && issueOf(sendMax) != issueOf(amount)
+		// An account can only pay itself to convert between currencies
+		if destination == account && !converts {
+			return "temREDUNDANT"
+		}
+		flags, _ := tx["Flags"].(uint32)
+		// XRP paid directly as XRP always arrives in full
+		if flags&tfPartialPayment != 0 && amount.IsNative() && !converts {
+			return "temBAD_SEND_XRP_PARTIAL"
+		}
+		if deliverMin, ok := amountValue(tx, "DeliverMin"); ok {
+			if flags&tfPartialPayment == 0 || !positive(deliverMin) || issueOf(deliverMin) != issueOf(amount) || units(deliverMin).Cmp(units(amount)) > 0 {
+				return "temBAD_AMOUNT"
+			}
+		}
+	case "OfferCreate":
+		takerPays, paysOK := amountValue(tx, "TakerPays")
+		takerGets, getsOK := amountValue(tx, "TakerGets")
+		if !paysOK || !getsOK || !positive(takerPays) || !positive(takerGets) || (takerPays.IsNative() && takerGets.IsNative()) {
+			return "temBAD_OFFER"
+		}
+		if issueOf(takerPays) == issueOf(takerGets) {
+			return "temREDUNDANT"
+		}
+	case "OfferCancel":
+		if sequence, _ := tx["OfferSequence"].(uint32); sequence == 0 {
+			return "temBAD_SEQUENCE"
+		}
+	case "TrustSet":
+		limit, ok := amountValue(tx, "LimitAmount")
+		if !ok || limit.IsNative() {
+			return "temBAD_LIMIT"
+		}
+		if value, ok := new(big.Rat).SetString(limit.Value); !ok || value.Sign() < 0 {
+			return "temBAD_LIMIT"
+		}
+		if limit.Issuer == account {
+			return "temDST_IS_SRC"
+		}
+	case "EscrowCreate":
+		amount, ok := amountValue(tx, "Amount")
+		if !ok || !positive(amount) {
+			return "temBAD_AMOUNT"
+		}
+		if !amount.IsNative() && !amendments[xrpl.AmendmentTokenEscrow] {
+			return "temBAD_AMOUNT"
+		}
+		if stringValue(tx, "Destination") == "" {
+			return "temMALFORMED"
+		}
+		finishAfter, _ := tx["FinishAfter"].(uint32)
+		cancelAfter, _ := tx["CancelAfter"].(uint32)
+		if finishAfter == 0 && cancelAfter == 0 {
+			return "temBAD_EXPIRATION"
+		}
+		if finishAfter != 0 && cancelAfter != 0 && cancelAfter <= finishAfter {
+			return "temBAD_EXPIRATION"
+		}
+		condition := stringValue(tx, "Condition")
+		if finishAfter == 0 && condition == "" {
+			return "temMALFORMED"
+		}
+		if condition != "" {
+			if _, _, err := xrpl.ParseCondition(condition); err != nil {
+				return "temMALFORMED"
+			}
+		}
+	case "EscrowFinish", "EscrowCancel":
+		if stringValue(tx, "Owner") == "" {
+			return "temMALFORMED"
+		}
+		if _, ok := tx["OfferSequence"].(uint32); !ok {
+			return "temMALFORMED"
+		}
+		if (stringValue(tx, "Condition") == "") != (stringValue(tx, "Fulfillment") == "") {
+			return "temMALFORMED"
+		}
+	case "PaymentChannelCreate", "PaymentChannelFund", "PaymentChannelClaim":
+		return preflightPaymentChannel(tx, account)
+	case "CheckCreate", "CheckCash", "CheckCancel":
+		return preflightCheck(tx, account)
+	case "TicketCreate":
+		count, _ := tx["TicketCount"].(uint32)
+		if count == 0 || count > xrpl.MaxTicketsPerAccount {
+			return "temINVALID_COUNT"
+		}
+	case "SignerListSet":
+		return preflightSignerList(tx, account)
+	case "AccountDelete":
+		destination := stringValue(tx, "Destination")
+		if destination == "" {
+			return "temMALFORMED"
+		}
+		if destination == account {
+			return "temDST_IS_SRC"
+		}
+	case "AccountSet":
+		setFlag, _ := tx["SetFlag"].(uint32)
+		clearFlag, _ := tx["ClearFlag"].(uint32)
+		if setFlag != 0 && setFlag == clearFlag {
+			return "temINVALID_FLAG"
+		}
+	default:
+		return "temUNKNOWN"
+	}
+	return "tesSUCCESS"
+}
+
+// preflightSignerList validates the shape of a SignerListSet
+func preflightSignerList(tx xrpl.Transaction, account string) string {
+	quorum, ok := tx["SignerQuorum"].(uint32)
+	if !ok {
+		return "temMALFORMED"
+	}
+	entries, err := signerEntries(tx)
+	if err != nil {
+		return "temMALFORMED"
+	}
+	if quorum == 0 {
+		if len(entries) != 0 {
+			return "temMALFORMED"
+		}
+		return "tesSUCCESS"
+	}
+	if len(entries) == 0 || len(entries) > xrpl.MaxSignerEntries {
+		return "temMALFORMED"
+	}
+
+	var total uint32
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if _, err := xrpl.DecodeAccountID(entry.Account); err != nil || entry.Account == account || seen[entry.Account] {
+			return "temBAD_SIGNER"
+		}
+		if entry.SignerWeight == 0 {
+			return "temBAD_WEIGHT"
+		}
+		seen[entry.Account] = true
+		total += uint32(entry.SignerWeight)
+	}
+	if total < quorum {
+		return "temBAD_QUORUM"
+	}
+	return "tesSUCCESS"
+}
+
+// preclaim checks a transaction against the open ledger before it can claim a fee
+func (l *Ledger) preclaim(ctx *applyContext, fee int64, signers []xrpl.Signer) string {
+	tx := ctx.tx
+	account := l.open.account(ctx.account)
+	if account == nil {
+		if account = l.faucet(ctx.account); account == nil {
+			return "terNO_ACCOUNT"
+		}
+	}
+
+	if ticketSequence, ok := tx["TicketSequence"].(uint32); ok {
+		switch {
+		case l.open.ticket(ctx.account, ticketSequence) != nil:
+		case ticketSequence >= account.Sequence:
+			return "terPRE_TICKET"
+		default:
+			return "tefNO_TICKET"
+		}
+	} else {
+		sequence := tx["Sequence"].(uint32)
+		switch {
+		case sequence < account.Sequence:
+			return "tefPAST_SEQ"
+		case sequence > account.Sequence:
+			return "terPRE_SEQ"
+		}
+	}
+	if lastLedger, ok := tx["LastLedgerSequence"].(uint32); ok && lastLedger < l.openIndex {
+		return "tefMAX_LEDGER"
+	}
+
+	baseCost := l.config.BaseFee
+	if fulfillment := stringValue(tx, "Fulfillment"); fulfillment != "" && tx["TransactionType"] == "EscrowFinish" {
+		cost, err := xrpl.EscrowFinishFee(l.config.BaseFee, fulfillment)
+		if err != nil {
+			return "temMALFORMED"
+		}
+		baseCost = cost
+	}
+	// Deleting an account costs the owner reserve increment rather than the reference fee
+	if tx["TransactionType"] == "AccountDelete" {
+		baseCost = l.config.ReserveIncrement
+	}
+	baseCost += l.config.BaseFee * int64(len(signers))
+	if fee < l.requiredFee(baseCost) {
+		return "telINSUF_FEE_P"
+	}
+	if fee > account.Balance {
+		return "terINSUF_FEE_B"
+	}
+
+	if len(signers) > 0 {
+		list := l.open.signerList(ctx.account)
+		if list == nil {
+			return "tefNOT_MULTI_SIGNING"
+		}
+		var weight uint32
+		for _, signer := range signers {
+			signerWeight := list.weight(signer.Account)
+			if signerWeight == 0 {
+				return "tefBAD_SIGNATURE"
+			}
+			weight += signerWeight
+		}
+		if weight < list.Quorum {
+			return "tefBAD_QUORUM"
+		}
+		return "tesSUCCESS"
+	}
+
+	publicKey, _ := hex.DecodeString(stringValue(tx, "SigningPubKey"))
+	if xrpl.EncodeAccountID(xrpl.AccountIDFromPublicKey(publicKey)) != ctx.account {
+		return "tefBAD_AUTH"
+	}
+	if account.Flags&lsfDisableMaster != 0 {
+		return "tefMASTER_DISABLED"
+	}
+	return "tesSUCCESS"
+}
+
+// chargeFee destroys the fee and consumes the sender's sequence or the ticket it used
+func chargeFee(s *state, ctx *applyContext, fee int64) {
+	account := s.account(ctx.account)
+	account.Balance -= fee
+
+	ticketSequence, ok := ctx.tx["TicketSequence"].(uint32)
+	if !ok {
+		account.Sequence++
+		return
+	}
+	index, _ := xrpl.TicketIndex(ctx.account, ticketSequence)
+	delete(s.tickets, index)
+	account.OwnerCount--
+	account.TicketCount--
+}
+
+// sequenceValue returns the sequence a transaction consumes: its ticket when it uses one
+func sequenceValue(tx xrpl.Transaction) uint32 {
+	if ticketSequence, ok := tx["TicketSequence"].(uint32); ok {
+		return ticketSequence
+	}
+	return tx["Sequence"].(uint32)
+}
+
+// transact charges the fee and runs the transactor for the transaction type on working state
+func (l *Ledger) transact(s *state, ctx *applyContext, fee int64) string {
+	chargeFee(s, ctx, fee)
+
+	switch ctx.tx["TransactionType"] {
+	case "Payment":
+		return l.applyPayment(s, ctx)
+	case "TrustSet":
+		return l.applyTrustSet(s, ctx)
+	case "OfferCreate":
+		return l.applyOfferCreate(s, ctx)
+	case "OfferCancel":
+		return l.applyOfferCancel(s, ctx)
+	case "EscrowCreate":
+		return l.applyEscrowCreate(s, ctx)
+	case "EscrowFinish":
+		return l.applyEscrowFinish(s, ctx)
+	case "EscrowCancel":
+		return l.applyEscrowCancel(s, ctx)
+	case "PaymentChannelCreate":
+		return l.applyPaymentChannelCreate(s, ctx)
+	case "PaymentChannelFund":
+		return l.applyPaymentChannelFund(s, ctx)
+	case "PaymentChannelClaim":
+		return l.applyPaymentChannelClaim(s, ctx)
+	case "CheckCreate":
+		return l.applyCheckCreate(s, ctx)
+	case "CheckCash":
+		return l.applyCheckCash(s, ctx)
+	case "CheckCancel":
+		return l.applyCheckCancel(s, ctx)
+	case "SignerListSet":
+		return l.applySignerListSet(s, ctx)
+	case "AccountSet":
+		return l.applyAccountSet(s, ctx)
+	case "AccountDelete":
+		return l.applyAccountDelete(s, ctx)
+	case "TicketCreate":
+		return l.applyTicketCreate(s, ctx)
+	default:
+		return "temUNKNOWN"
+	}
+}
+
+func (l *Ledger) applyPayment(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	amount, _ := amountValue(tx, "Amount")
+	source := s.account(ctx.account)
+	destinationAddress := stringValue(tx, "Destination")
+	destination := s.account(destinationAddress)
+
+	if destination == nil {
+		if !amount.IsNative() {
+			return "tecNO_DST"
+		}
+		if drops(amount) < l.config.ReserveBase {
+			return "tecNO_DST_INSUF_XRP"
+		}
+		var err error
+		if destination, err = s.createAccount(destinationAddress, l.openIndex); err != nil {
+			return "tecNO_DST"
+		}
+	}
+	if _, tagged := tx["DestinationTag"]; destination.Flags&lsfRequireDestTag != 0 && !tagged {
+		return "tecDST_TAG_NEEDED"
+	}
+
+	if sendMax, ok := amountValue(tx, "SendMax"); ok && issueOf(sendMax) != issueOf(amount) {
+		return l.applyConversion(s, ctx, destinationAddress, amount, sendMax)
+	}
+	if amount.IsNative() {
+		if source.Balance-drops(amount) < l.reserve(source.OwnerCount) {
+			return "tecUNFUNDED_PAYMENT"
+		}
+		source.Balance -= drops(amount)
+		destination.Balance += drops(amount)
+	} else {
+		var result string
+		if amount, result = partialDelivery(s, ctx, amount); result != "tesSUCCESS" {
+			return result
+		}
+		if result = l.transferIssued(s, ctx.account, destinationAddress, amount); result != "tesSUCCESS" {
+			return result
+		}
+	}
+
+	ctx.delivered = &amount
+	return "tesSUCCESS"
+}
+
+// partialDelivery reduces a partial payment of an issued currency to what the sender holds,
+// failing when that falls short of DeliverMin. Other payments are returned unchanged.
+func partialDelivery(s *state, ctx *applyContext, amount xrpl.Amount) (xrpl.Amount, string) {
+	flags, _ := ctx.tx["Flags"].(uint32)
+	if flags&tfPartialPayment == 0 || ctx.account == amount.Issuer {
+		return amount, "tesSUCCESS"
+	}
+	line := s.line(ctx.account, amount.Issuer, amount.Currency)
+	if line == nil {
+		return amount, "tecPATH_DRY"
+	}
+	held := line.holding(ctx.account)
+	if held.Cmp(issuedValue(amount)) >= 0 {
+		return amount, "tesSUCCESS"
+	}
+	if held.Sign() <= 0 {
+		return amount, "tecPATH_DRY"
+	}
+	if deliverMin, ok := amountValue(ctx.tx, "DeliverMin"); ok && held.Cmp(units(deliverMin)) < 0 {
+		return amount, "tecPATH_PARTIAL"
+	}
+	return issueOf(amount).amount(held), "tesSUCCESS"
+}
+
+// transferIssued moves an issued amount between two accounts through the issuer's trust lines
+func (l *Ledger) transferIssued(s *state, from, to string, amount xrpl.Amount) string {
+	value := issuedValue(amount)
+	issuer := amount.Issuer
+
+	var fromLine, toLine *trustLine
+	if from != issuer {
+		if fromLine = s.line(from, issuer, amount.Currency); fromLine == nil || frozen(fromLine, issuer) {
+			return "tecPATH_DRY"
+		}
+		if fromLine.holding(from).Cmp(value) < 0 {
+			return "tecPATH_PARTIAL"
+		}
+	}
+	if to != issuer {
+		if toLine = s.line(to, issuer, amount.Currency); toLine == nil || frozen(toLine, issuer) || !authorized(s, toLine, issuer) {
+			return "tecPATH_DRY"
+		}
+		if new(big.Rat).Add(toLine.holding(to), value).Cmp(toLine.limit(to)) > 0 {
+			return "tecPATH_PARTIAL"
+		}
+	}
+
+	if fromLine != nil {
+		fromLine.credit(issuer, value)
+	}
+	if toLine != nil {
+		toLine.credit(to, value)
+	}
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyTrustSet(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	limitAmount, _ := amountValue(tx, "LimitAmount")
+	limit := issuedValue(limitAmount)
+	peer := limitAmount.Issuer
+	flags, _ := tx["Flags"].(uint32)
+	account := s.account(ctx.account)
+
+	if s.account(peer) == nil {
+		return "tecNO_DST"
+	}
+
+	line := s.line(ctx.account, peer, limitAmount.Currency)
+	if line == nil {
+		if limit.Sign() == 0 && flags&(tfSetfAuth|tfSetNoRipple|tfSetFreeze) == 0 {
+			return "tecNO_LINE_REDUNDANT"
+		}
+		if account.Balance < l.reserve(account.OwnerCount+1) {
+			return "tecNO_LINE_INSUF_RESERVE"
+		}
+
+		low, high := lowAndHigh(ctx.account, peer)
+		line = &trustLine{
+			Low:       low,
+			High:      high,
+			Currency:  limitAmount.Currency,
+			Balance:   new(big.Rat),
+			LowLimit:  new(big.Rat),
+			HighLimit: new(big.Rat),
+		}
+		index, err := xrpl.RippleStateIndex(low, high, limitAmount.Currency)
+		if err != nil {
+			return "temMALFORMED"
+		}
+		s.lines[index] = line
+		line.Flags |= line.flag(ctx.account, lsfLowReserve, lsfHighReserve)
+		account.OwnerCount++
+	}
+
+	if line.isLow(ctx.account) {
+		line.LowLimit = limit
+	} else {
+		line.HighLimit = limit
+	}
+	toggle := func(set, clear uint32, low, high uint32) {
+		switch {
+		case flags&set != 0:
+			line.Flags |= line.flag(ctx.account, low, high)
+		case flags&clear != 0:
+			line.Flags &^= line.flag(ctx.account, low, high)
+		}
+	}
+	toggle(tfSetfAuth, 0, lsfLowAuth, lsfHighAuth)
+	toggle(tfSetNoRipple, tfClearNoRipple, lsfLowNoRipple, lsfHighNoRipple)
+	toggle(tfSetFreeze, tfClearFreeze, lsfLowFreeze, lsfHighFreeze)
+
+	// A line back at its default state is removed, returning the reserve it held
+	if line.LowLimit.Sign() == 0 && line.HighLimit.Sign() == 0 && line.Balance.Sign() == 0 &&
+		line.Flags&^(lsfLowReserve|lsfHighReserve) == 0 {
+		for side, flag := range map[string]uint32{line.Low: lsfLowReserve, line.High: lsfHighReserve} {
+			if line.Flags&flag != 0 {
+				s.account(side).OwnerCount--
+			}
+		}
+		index, _ := xrpl.RippleStateIndex(line.Low, line.High, line.Currency)
+		delete(s.lines, index)
+	}
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyEscrowCreate(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	amount, _ := amountValue(tx, "Amount")
+	finishAfter, _ := tx["FinishAfter"].(uint32)
+	cancelAfter, _ := tx["CancelAfter"].(uint32)
+	account := s.account(ctx.account)
+
+	// Escrows cannot start out already finishable or expired
+	if (cancelAfter != 0 && l.closeTime >= cancelAfter) || (finishAfter != 0 && l.closeTime >= finishAfter) {
+		return "tecNO_PERMISSION"
+	}
+
+	destinationAddress := stringValue(tx, "Destination")
+	destination := s.account(destinationAddress)
+	if destination == nil {
+		return "tecNO_DST"
+	}
+	if _, tagged := tx["DestinationTag"]; destination.Flags&lsfRequireDestTag != 0 && !tagged {
+		return "tecDST_TAG_NEEDED"
+	}
+
+	if account.Balance < l.reserve(account.OwnerCount+1) {
+		return "tecINSUFFICIENT_RESERVE"
+	}
+	if amount.IsNative() {
+		if account.Balance < l.reserve(account.OwnerCount+1)+drops(amount) {
+			return "tecUNFUNDED"
+		}
+		account.Balance -= drops(amount)
+	} else {
+		if ctx.account == amount.Issuer {
+			return "tecNO_PERMISSION"
+		}
+		line := s.line(ctx.account, amount.Issuer, amount.Currency)
+		if line == nil {
+			return "tecNO_LINE"
+		}
+		if line.holding(ctx.account).Cmp(issuedValue(amount)) < 0 {
+			return "tecINSUFFICIENT_FUNDS"
+		}
+		// Locked tokens are held by the issuer until the escrow resolves
+		line.credit(amount.Issuer, issuedValue(amount))
+	}
+
+	index, err := xrpl.EscrowIndex(ctx.account, sequenceValue(tx))
+	if err != nil {
+		return "temMALFORMED"
+	}
+	escrow := &escrowEntry{
+		Account:     ctx.account,
+		Destination: destinationAddress,
+		Amount:      amount,
+		Condition:   stringValue(tx, "Condition"),
+		FinishAfter: finishAfter,
+		CancelAfter: cancelAfter,
+	}
+	if tag, ok := tx["DestinationTag"].(uint32); ok {
+		escrow.DestinationTag = &tag
+	}
+	if tag, ok := tx["SourceTag"].(uint32); ok {
+		escrow.SourceTag = &tag
+	}
+	s.escrows[index] = escrow
+	account.OwnerCount++
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyEscrowFinish(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	index, escrow := findEscrow(s, tx)
+	if escrow == nil {
+		return "tecNO_TARGET"
+	}
+
+	if escrow.FinishAfter != 0 && l.closeTime <= escrow.FinishAfter {
+		return "tecNO_PERMISSION"
+	}
+	if escrow.CancelAfter != 0 && l.closeTime > escrow.CancelAfter {
+		return "tecNO_PERMISSION"
+	}
+
+	fulfillment := stringValue(tx, "Fulfillment")
+	switch {
+	case escrow.Condition == "" && fulfillment != "":
+		return "tecCRYPTOCONDITION_ERROR"
+	case escrow.Condition != "":
+		if fulfillment == "" || !strings.EqualFold(stringValue(tx, "Condition"), escrow.Condition) {
+			return "tecCRYPTOCONDITION_ERROR"
+		}
+		if err := xrpl.ValidateFulfillment(escrow.Condition, fulfillment); err != nil {
+			return "tecCRYPTOCONDITION_ERROR"
+		}
+	}
+
+	destination := s.account(escrow.Destination)
+	if destination == nil {
+		return "tecNO_DST"
+	}
+	if destination.Flags&lsfDepositAuth != 0 && ctx.account != escrow.Destination {
+		return "tecNO_PERMISSION"
+	}
+	if result := releaseEscrow(s, escrow, escrow.Destination); result != "tesSUCCESS" {
+		return result
+	}
+
+	delete(s.escrows, index)
+	s.account(escrow.Account).OwnerCount--
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyEscrowCancel(s *state, ctx *applyContext) string {
+	index, escrow := findEscrow(s, ctx.tx)
+	if escrow == nil {
+		return "tecNO_TARGET"
+	}
+	if escrow.CancelAfter == 0 || l.closeTime <= escrow.CancelAfter {
+		return "tecNO_PERMISSION"
+	}
+	if result := releaseEscrow(s, escrow, escrow.Account); result != "tesSUCCESS" {
+		return result
+	}
+
+	delete(s.escrows, index)
+	s.account(escrow.Account).OwnerCount--
+	return "tesSUCCESS"
+}
+
+// findEscrow looks up the escrow an EscrowFinish or EscrowCancel refers to
+func findEscrow(s *state, tx xrpl.Transaction) (string, *escrowEntry) {
+	index, err := xrpl.EscrowIndex(stringValue(tx, "Owner"), tx["OfferSequence"].(uint32))
+	if err != nil {
+		return "", nil
+	}
+	return index, s.escrows[index]
+}
+
+// releaseEscrow pays the escrowed amount to recipient
+func releaseEscrow(s *state, escrow *escrowEntry, recipient string) string {
+	if escrow.Amount.IsNative() {
+		s.account(recipient).Balance += drops(escrow.Amount)
+		return "tesSUCCESS"
+	}
+	if recipient == escrow.Amount.Issuer {
+		return "tesSUCCESS"
+	}
+	line := s.line(recipient, escrow.Amount.Issuer, escrow.Amount.Currency)
+	if line == nil {
+		return "tecNO_LINE"
+	}
+	line.credit(recipient, issuedValue(escrow.Amount))
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applySignerListSet(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	account := s.account(ctx.account)
+	quorum := tx["SignerQuorum"].(uint32)
+	entries, _ := signerEntries(tx)
+	index, err := xrpl.SignerListIndex(ctx.account)
+	if err != nil {
+		return "temMALFORMED"
+	}
+	existing := s.signerLists[index]
+
+	if quorum == 0 {
+		if existing == nil {
+			return "tesSUCCESS"
+		}
+		if account.Flags&lsfDisableMaster != 0 {
+			return "tecNO_ALTERNATIVE_KEY"
+		}
+		delete(s.signerLists, index)
+		account.OwnerCount--
+		return "tesSUCCESS"
+	}
+
+	if existing == nil {
+		if account.Balance < l.reserve(account.OwnerCount+1) {
+			return "tecINSUFFICIENT_RESERVE"
+		}
+		account.OwnerCount++
+	}
+	s.signerLists[index] = &signerList{Account: ctx.account, Quorum: quorum, Entries: entries}
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyAccountSet(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	account := s.account(ctx.account)
+
+	if setFlag, ok := tx["SetFlag"].(uint32); ok {
+		if setFlag == asfDisableMaster {
+			if s.signerList(ctx.account) == nil {
+				return "tecNO_ALTERNATIVE_KEY"
+			}
+			if !ctx.master {
+				return "tecNEED_MASTER_KEY"
+			}
+		}
+		account.Flags |= accountSetFlags[setFlag]
+	}
+	if clearFlag, ok := tx["ClearFlag"].(uint32); ok {
+		if clearFlag == asfDisableMaster && !ctx.master {
+			return "tecNEED_MASTER_KEY"
+		}
+		account.Flags &^= accountSetFlags[clearFlag]
+	}
+	if domain, ok := tx["Domain"].(string); ok {
+		account.Domain = domain
+	}
+	return "tesSUCCESS"
+}
+
+func (l *Ledger) applyAccountDelete(s *state, ctx *applyContext) string {
+	tx := ctx.tx
+	account := s.account(ctx.account)
+
+	destinationAddress := stringValue(tx, "Destination")
+	destination := s.account(destinationAddress)
+	if destination == nil {
+		return "tecNO_DST"
+	}
+	if _, tagged := tx["DestinationTag"]; destination.Flags&lsfRequireDestTag != 0 && !tagged {
+		return "tecDST_TAG_NEEDED"
+	}
+	if destination.Flags&lsfDepositAuth != 0 {
+		return "tecNO_PERMISSION"
+	}
+	// A deleted account could otherwise be recreated in time to replay its old transactions
+	if account.Sequence+xrpl.AccountDeleteLedgerGap-1 > l.openIndex {
+		return "tecTOO_SOON"
+	}
+	for _, entry := range s.ownedBy(ctx.account) {
+		if blocksDeletion(entry) {
+			return "tecHAS_OBLIGATIONS"
+		}
+	}
+
+	// Offers, tickets and the signer list go with the account
+	for index, entry := range s.ownedBy(ctx.account) {
+		switch entry.(type) {
+		case *offerEntry:
+			delete(s.offers, index)
+		case *ticketEntry:
+			delete(s.tickets, index)
+		case *signerList:
+			delete(s.signerLists, index)
+		}
+	}
+	destination.Balance += account.Balance
+	delivered := xrpl.XRPAmount(account.Balance)
+	ctx.delivered = &delivered
+
+	index, _ := xrpl.AccountRootIndex(ctx.account)
+	delete(s.accounts, index)
+	return "tesSUCCESS"
+}
+
+// blocksDeletion reports whether an owned entry keeps its account from being deleted
+func blocksDeletion(entry ledgerEntry) bool {
+	switch entry.(type) {
+	case *offerEntry, *ticketEntry, *signerList:
+		return false
+	default:
+		return true
+	}
+}
+
+func (l *Ledger) applyTicketCreate(s *state, ctx *applyContext) string {
+	count := ctx.tx["TicketCount"].(uint32)
+	account := s.account(ctx.account)
+
+	if account.TicketCount+count > xrpl.MaxTicketsPerAccount {
+		return "tecDIR_FULL"
+	}
+	if account.Balance < l.reserve(account.OwnerCount+count) {
+		return "tecINSUFFICIENT_RESERVE"
+	}
+
+	// The tickets take the sequences that follow the one the TicketCreate consumed
+	for i := uint32(0); i < count; i++ {
+		ticketSequence := account.Sequence + i
+		index, err := xrpl.TicketIndex(ctx.account, ticketSequence)
+		if err != nil {
+			return "temMALFORMED"
+		}
+		s.tickets[index] = &ticketEntry{Account: ctx.account, TicketSequence: ticketSequence}
+	}
+	account.Sequence += count
+	account.OwnerCount += count
+	account.TicketCount += count
+	return "tesSUCCESS"
+}
+
+// signerEntries reads the SignerEntries array of a SignerListSet
+func signerEntries(tx xrpl.Transaction) ([]xrpl.SignerEntry, error) {
+	wrapped, _ := tx["SignerEntries"].([]interface{})
+	entries := make([]xrpl.SignerEntry, 0, len(wrapped))
+	for _, item := range wrapped {
+		object, _ := item.(map[string]interface{})
+		fields, ok := object["SignerEntry"].(map[string]interface{})
+		if !ok {
+			return nil, errMalformedSignerEntry
+		}
+		weight, _ := fields["SignerWeight"].(uint32)
+		entries = append(entries, xrpl.SignerEntry{
+			Account:      stringValue(fields, "Account"),
+			SignerWeight: uint16(weight),
+		})
+	}
+	return entries, nil
+}
+
+// frozen reports whether the issuer has frozen its side of a trust line
+func frozen(line *trustLine, issuer string) bool {
+	return line.Flags&line.flag(issuer, lsfLowFreeze, lsfHighFreeze) != 0
+}
+
+// authorized reports whether a holder may receive tokens of an issuer that requires authorization
+func authorized(s *state, line *trustLine, issuer string) bool {
+	account := s.account(issuer)
+	if account == nil || account.Flags&lsfRequireAuth == 0 {
+		return true
+	}
+	return line.Flags&line.flag(issuer, lsfLowAuth, lsfHighAuth) != 0
+}
+
+// stringValue returns a string field of a decoded object, or "" when it is absent
+func stringValue(object map[string]interface{}, name string) string {
+	value, _ := object[name].(string)
+	return value
+}
+
+// amountValue reads a decoded amount field
+func amountValue(tx xrpl.Transaction, name string) (xrpl.Amount, bool) {
+	switch value := tx[name].(type) {
+	case string:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return xrpl.Amount{}, false
+		}
+		return xrpl.Amount{Value: value}, true
+	case map[string]interface{}:
+		amount := xrpl.Amount{
+			Currency: stringValue(value, "currency"),
+			Issuer:   stringValue(value, "issuer"),
+			Value:    stringValue(value, "value"),
+		}
+		if _, ok := new(big.Rat).SetString(amount.Value); !ok || amount.Currency == "" {
+			return xrpl.Amount{}, false
+		}
+		return amount, true
+	default:
+		return xrpl.Amount{}, false
+	}
+}
+
+// positive reports whether an amount is greater than zero
+func positive(amount xrpl.Amount) bool {
+	if amount.IsNative() {
+		return drops(amount) > 0
+	}
+	return issuedValue(amount).Sign() > 0
+}
+
+// drops returns the drops of a validated XRP amount
+func drops(amount xrpl.Amount) int64 {
+	value, _ := strconv.ParseInt(amount.Value, 10, 64)
+	return value
+}
+
+// issuedValue returns the value of a validated issued amount
+func issuedValue(amount xrpl.Amount) *big.Rat {
+	value, ok := new(big.Rat).SetString(amount.Value)
+	if !ok {
+		return new(big.Rat)
+	}
+	return value
+}
@@ -0,0 +1,574 @@
+package xrpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Streams published by rippled's subscribe method
+const (
+	StreamLedger       = "ledger"
+	StreamTransactions = "transactions"
+)
+
+// ErrStreamClosed is returned when a subscription client is used after Close
+var ErrStreamClosed = errors.New("subscription stream is closed")
+
+// StreamConfig configures a WebSocket subscription client
+type StreamConfig struct {
+	URL string
+	// Streams to subscribe to; defaults to the ledger and transactions streams
+	Streams []string
+	// Reconnect backoff starts at InitialBackoff and doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	DialTimeout    time.Duration
+	// RecentTransactions bounds the hashes remembered to drop duplicate deliveries
+	RecentTransactions int
+}
+
+// LedgerEvent is a ledgerClosed message from the ledger stream
+type LedgerEvent struct {
+	LedgerIndex      uint32 `json:"ledger_index"`
+	LedgerHash       string `json:"ledger_hash"`
+	LedgerTime       uint32 `json:"ledger_time"`
+	FeeBase          uint32 `json:"fee_base"`
+	ReserveBase      uint32 `json:"reserve_base"`
+	ReserveIncrement uint32 `json:"reserve_inc"`
+	TxnCount         uint32 `json:"txn_count"`
+	ValidatedLedgers string `json:"validated_ledgers"`
+}
+
+// TransactionEvent is a transaction message from the transactions or accounts streams
+type TransactionEvent struct {
+	Hash            string                 `json:"hash"`
+	TransactionType string                 `json:"-"`
+	Account         string                 `json:"-"`
+	Destination     string                 `json:"-"`
+	EngineResult    string                 `json:"engine_result"`
+	LedgerIndex     uint32                 `json:"ledger_index"`
+	Validated       bool                   `json:"validated"`
+	Transaction     map[string]interface{} `json:"transaction"`
+	Meta            *TransactionMeta       `json:"meta"`
+}
+
+// apiV2Transaction carries the tx_json field that API version 2 sends instead of transaction
+type apiV2Transaction struct {
+	TxJSON map[string]interface{} `json:"tx_json"`
+}
+
+// TransactionMeta is the metadata describing a transaction's effect on the ledger
+type TransactionMeta struct {
+	TransactionResult string         `json:"TransactionResult"`
+	AffectedNodes     []AffectedNode `json:"AffectedNodes"`
+	// DeliveredAmount is what a payment actually delivered to its destination
+	DeliveredAmount *Amount `json:"delivered_amount,omitempty"`
+}
+
+// AffectedNode wraps one created, modified or deleted ledger entry; exactly one field is set
+type AffectedNode struct {
+	CreatedNode  *NodeChange `json:"CreatedNode,omitempty"`
+	ModifiedNode *NodeChange `json:"ModifiedNode,omitempty"`
+	DeletedNode  *NodeChange `json:"DeletedNode,omitempty"`
+}
+
+// NodeChange describes the change to a single ledger entry
+type NodeChange struct {
+	LedgerEntryType string                 `json:"LedgerEntryType"`
+	LedgerIndex     string                 `json:"LedgerIndex"`
+	PreviousTxnID   string                 `json:"PreviousTxnID,omitempty"`
+	NewFields       map[string]interface{} `json:"NewFields,omitempty"`
+	FinalFields     map[string]interface{} `json:"FinalFields,omitempty"`
+	PreviousFields  map[string]interface{} `json:"PreviousFields,omitempty"`
+}
+
+// Succeeded reports whether the transaction was validated with tesSUCCESS
+func (e *TransactionEvent) Succeeded() bool {
+	return e.Validated && e.EngineResult == "tesSUCCESS"
+}
+
+// AccountBalances returns the XRP balance in drops of every account root the transaction changed
+func (e *TransactionEvent) AccountBalances() map[string]string {
+	balances := make(map[string]string)
+	if e.Meta == nil {
+		return balances
+	}
+
+	for _, node := range e.Meta.AffectedNodes {
+		change, fields := node.entry()
+		if change == nil || change.LedgerEntryType != "AccountRoot" {
+			continue
+		}
+		account, _ := fields["Account"].(string)
+		balance, _ := fields["Balance"].(string)
+		if account != "" && balance != "" {
+			balances[account] = balance
+		}
+	}
+	return balances
+}
+
+// EscrowCreateHashes returns the hashes of the EscrowCreate transactions whose escrows this
+// transaction created or removed. Escrow entries are never modified, so a deleted escrow's
+// PreviousTxnID is the hash of the transaction that created it.
+func (e *TransactionEvent) EscrowCreateHashes() []string {
+	if e.Meta == nil {
+		return nil
+	}
+
+	var hashes []string
+	for _, node := range e.Meta.AffectedNodes {
+		switch {
+		case node.CreatedNode != nil && node.CreatedNode.LedgerEntryType == "Escrow":
+			hashes = append(hashes, e.Hash)
+		case node.DeletedNode != nil && node.DeletedNode.LedgerEntryType == "Escrow":
+			previous := node.DeletedNode.PreviousTxnID
+			if id, ok := node.DeletedNode.FinalFields["PreviousTxnID"].(string); ok {
+				previous = id
+			}
+			if previous != "" {
+				hashes = append(hashes, previous)
+			}
+		}
+	}
+	return hashes
+}
+
+// BalanceChange returns how much a transaction changed an account's holding of a currency: drops
+// of XRP when currency is empty, otherwise the issued currency in its ledger form held on the
+// trust line with issuer. Balances the transaction did not touch report zero.
+func (m *TransactionMeta) BalanceChange(account, currency, issuer string) *big.Rat {
+	change := new(big.Rat)
+	for _, node := range m.AffectedNodes {
+		entry, fields := node.entry()
+		if entry == nil {
+			continue
+		}
+
+		var sign int64 = 1
+		switch entry.LedgerEntryType {
+		case "AccountRoot":
+			if currency != "" || fields["Account"] != account {
+				continue
+			}
+		case "RippleState":
+			low, _ := fields["LowLimit"].(map[string]interface{})
+			high, _ := fields["HighLimit"].(map[string]interface{})
+			balance, _ := fields["Balance"].(map[string]interface{})
+			if low == nil || high == nil || balance == nil || balance["currency"] != currency {
+				continue
+			}
+			// A positive balance is held by the low account
+			switch {
+			case low["issuer"] == account && high["issuer"] == issuer:
+			case high["issuer"] == account && low["issuer"] == issuer:
+				sign = -1
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+
+		final, ok := metaBalance(fields["Balance"])
+		if !ok {
+			continue
+		}
+		previous := new(big.Rat)
+		if node.ModifiedNode != nil || node.DeletedNode != nil {
+			if value, found := metaBalance(entry.PreviousFields["Balance"]); found {
+				previous = value
+			} else {
+				previous = final
+			}
+		}
+		delta := new(big.Rat).Sub(final, previous)
+		change.Add(change, delta.Mul(delta, big.NewRat(sign, 1)))
+	}
+	return change
+}
+
+// metaBalance parses a Balance field: a drops string on an AccountRoot, an amount object on a trust line
+func metaBalance(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case map[string]interface{}:
+		text, _ = v["value"].(string)
+	}
+	balance, ok := new(big.Rat).SetString(text)
+	return balance, ok && text != ""
+}
+
+// entry returns the change and the fields that describe the entry after the transaction
+func (n AffectedNode) entry() (*NodeChange, map[string]interface{}) {
+	switch {
+	case n.CreatedNode != nil:
+		return n.CreatedNode, n.CreatedNode.NewFields
+	case n.ModifiedNode != nil:
+		return n.ModifiedNode, n.ModifiedNode.FinalFields
+	case n.DeletedNode != nil:
+		return n.DeletedNode, n.DeletedNode.FinalFields
+	}
+	return nil, nil
+}
+
+// streamMessage is the envelope of every message received on the WebSocket
+type streamMessage struct {
+	Type   string      `json:"type"`
+	ID     interface{} `json:"id,omitempty"`
+	Status string      `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// SubscriptionClient keeps a WebSocket subscription to rippled open, reconnecting with
+// backoff and resubscribing to every stream and account after each reconnect
+type SubscriptionClient struct {
+	config StreamConfig
+
+	mu                  sync.RWMutex
+	conn                *websocket.Conn
+	accounts            map[string]bool
+	ledgerHandlers      []func(*LedgerEvent)
+	transactionHandlers []func(*TransactionEvent)
+	closed              bool
+	requestID           int
+
+	writeMu     sync.Mutex
+	recentMu    sync.Mutex
+	recent      map[string]bool
+	recentOrder []string
+}
+
+// NewSubscriptionClient creates a subscription client for a rippled WebSocket endpoint
+func NewSubscriptionClient(config StreamConfig) *SubscriptionClient {
+	if len(config.Streams) == 0 {
+		config.Streams = []string{StreamLedger, StreamTransactions}
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Minute
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 10 * time.Second
+	}
+	if config.RecentTransactions <= 0 {
+		config.RecentTransactions = 1000
+	}
+
+	return &SubscriptionClient{
+		config:   config,
+		accounts: make(map[string]bool),
+		recent:   make(map[string]bool),
+	}
+}
+
+// OnLedger registers a handler for closed ledgers
+func (s *SubscriptionClient) OnLedger(handler func(*LedgerEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ledgerHandlers = append(s.ledgerHandlers, handler)
+}
+
+// OnTransaction registers a handler for transactions; each transaction is delivered once
+// even when it arrives on both the transactions and accounts streams
+func (s *SubscriptionClient) OnTransaction(handler func(*TransactionEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactionHandlers = append(s.transactionHandlers, handler)
+}
+
+// SubscribeAccounts adds accounts to the subscription, sending the request immediately when connected
+func (s *SubscriptionClient) SubscribeAccounts(accounts ...string) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrStreamClosed
+	}
+	var added []string
+	for _, account := range accounts {
+		if !s.accounts[account] {
+			s.accounts[account] = true
+			added = append(added, account)
+		}
+	}
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil || len(added) == 0 {
+		return nil
+	}
+	return s.send(conn, map[string]interface{}{"command": "subscribe", "accounts": added})
+}
+
+// UnsubscribeAccounts removes accounts from the subscription
+func (s *SubscriptionClient) UnsubscribeAccounts(accounts ...string) error {
+	s.mu.Lock()
+	var removed []string
+	for _, account := range accounts {
+		if s.accounts[account] {
+			delete(s.accounts, account)
+			removed = append(removed, account)
+		}
+	}
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil || len(removed) == 0 {
+		return nil
+	}
+	return s.send(conn, map[string]interface{}{"command": "unsubscribe", "accounts": removed})
+}
+
+// Accounts returns the accounts currently subscribed to
+func (s *SubscriptionClient) Accounts() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]string, 0, len(s.accounts))
+	for account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// Connected reports whether the client currently holds an open connection
+func (s *SubscriptionClient) Connected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conn != nil
+}
+
+// Run connects and dispatches events until the context is canceled or Close is called,
+// reconnecting with exponential backoff whenever the connection drops
+func (s *SubscriptionClient) Run(ctx context.Context) error {
+	backoff := s.config.InitialBackoff
+
+	for {
+		if s.isClosed() {
+			return ErrStreamClosed
+		}
+
+		connected, err := s.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if s.isClosed() {
+			return ErrStreamClosed
+		}
+		if connected {
+			backoff = s.config.InitialBackoff
+		}
+
+		log.Printf("XRPL stream %s disconnected: %v; reconnecting in %v", s.config.URL, err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+}
+
+// Close stops the client and closes the open connection
+func (s *SubscriptionClient) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// session runs one connection until it fails; connected reports whether the subscription was established
+func (s *SubscriptionClient) session(ctx context.Context) (connected bool, err error) {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// Unblock the read loop when the context is canceled
+	sessionDone := make(chan struct{})
+	defer close(sessionDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-sessionDone:
+		}
+	}()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false, ErrStreamClosed
+	}
+	s.conn = conn
+	accounts := make([]string, 0, len(s.accounts))
+	for account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	request := map[string]interface{}{"command": "subscribe", "streams": s.config.Streams}
+	if len(accounts) > 0 {
+		request["accounts"] = accounts
+	}
+	if err := s.send(conn, request); err != nil {
+		return false, err
+	}
+
+	log.Printf("XRPL stream connected to %s (streams: %v, accounts: %d)", s.config.URL, s.config.Streams, len(accounts))
+
+	for {
+		var raw json.RawMessage
+		if err := websocket.JSON.Receive(conn, &raw); err != nil {
+			return true, err
+		}
+		s.dispatch(raw)
+	}
+}
+
+// dial opens the WebSocket connection within the configured timeout
+func (s *SubscriptionClient) dial(ctx context.Context) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(s.config.URL, "http://localhost/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream URL %s: %w", s.config.URL, err)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, s.config.DialTimeout)
+	defer cancel()
+
+	conn, err := config.DialContext(dialCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", s.config.URL, err)
+	}
+	return conn, nil
+}
+
+// send writes a request on the connection, numbering it for correlation with the response
+func (s *SubscriptionClient) send(conn *websocket.Conn, request map[string]interface{}) error {
+	s.mu.Lock()
+	s.requestID++
+	request["id"] = s.requestID
+	s.mu.Unlock()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := websocket.JSON.Send(conn, request); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", request["command"], err)
+	}
+	return nil
+}
+
+// dispatch decodes one message and hands it to the registered handlers
+func (s *SubscriptionClient) dispatch(raw json.RawMessage) {
+	var message streamMessage
+	if err := json.Unmarshal(raw, &message); err != nil {
+		log.Printf("XRPL stream: dropping undecodable message: %v", err)
+		return
+	}
+
+	switch message.Type {
+	case "ledgerClosed":
+		var event LedgerEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			log.Printf("XRPL stream: dropping malformed ledger message: %v", err)
+			return
+		}
+		s.mu.RLock()
+		handlers := s.ledgerHandlers
+		s.mu.RUnlock()
+		for _, handler := range handlers {
+			handler(&event)
+		}
+
+	case "transaction":
+		var event TransactionEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			log.Printf("XRPL stream: dropping malformed transaction message: %v", err)
+			return
+		}
+		if event.Transaction == nil {
+			var v2 apiV2Transaction
+			if err := json.Unmarshal(raw, &v2); err == nil {
+				event.Transaction = v2.TxJSON
+			}
+		}
+		if event.Hash == "" {
+			event.Hash, _ = event.Transaction["hash"].(string)
+		}
+		event.TransactionType, _ = event.Transaction["TransactionType"].(string)
+		event.Account, _ = event.Transaction["Account"].(string)
+		event.Destination, _ = event.Transaction["Destination"].(string)
+		if s.seen(event.Hash, event.Validated) {
+			return
+		}
+		s.mu.RLock()
+		handlers := s.transactionHandlers
+		s.mu.RUnlock()
+		for _, handler := range handlers {
+			handler(&event)
+		}
+
+	case "response":
+		if message.Status == "error" {
+			log.Printf("XRPL stream request %v failed: %s", message.ID, message.Error)
+		}
+	}
+}
+
+// seen records a validated transaction hash and reports whether it was already delivered
+func (s *SubscriptionClient) seen(hash string, validated bool) bool {
+	if hash == "" || !validated {
+		return false
+	}
+
+	s.recentMu.Lock()
+	defer s.recentMu.Unlock()
+
+	if s.recent[hash] {
+		return true
+	}
+	s.recent[hash] = true
+	s.recentOrder = append(s.recentOrder, hash)
+	if len(s.recentOrder) > s.config.RecentTransactions {
+		delete(s.recent, s.recentOrder[0])
+		s.recentOrder = s.recentOrder[1:]
+	}
+	return false
+}
+
+func (s *SubscriptionClient) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.closed
+}
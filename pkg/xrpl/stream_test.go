@@ -0,0 +1,207 @@
+package xrpl
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const streamTransactionMessage = `{
+	"type": "transaction",
+	"engine_result": "tesSUCCESS",
+	"ledger_index": 101,
+	"validated": true,
+	"transaction": {
+		"hash": "FINISHHASH",
+		"TransactionType": "EscrowFinish",
+		"Account": "rDestination",
+		"Owner": "rOwner",
+		"OfferSequence": 7
+	},
+	"meta": {
+		"TransactionResult": "tesSUCCESS",
+		"AffectedNodes": [
+			{"DeletedNode": {"LedgerEntryType": "Escrow", "LedgerIndex": "ESCROWINDEX",
+				"FinalFields": {"Account": "rOwner", "Destination": "rDestination", "Amount": "1000000", "PreviousTxnID": "CREATEHASH"}}},
+			{"ModifiedNode": {"LedgerEntryType": "AccountRoot", "LedgerIndex": "ROOT1",
+				"FinalFields": {"Account": "rDestination", "Balance": "26000000"},
+				"PreviousFields": {"Balance": "25000000"}}}
+		]
+	}
+}`
+
+// streamServer is a WebSocket endpoint that records subscribe requests and lets tests push messages
+type streamServer struct {
+	server   *httptest.Server
+	requests chan map[string]interface{}
+	conns    chan *websocket.Conn
+}
+
+func newStreamServer(t *testing.T) *streamServer {
+	s := &streamServer{
+		requests: make(chan map[string]interface{}, 10),
+		conns:    make(chan *websocket.Conn, 10),
+	}
+	s.server = httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		s.conns <- conn
+		for {
+			var request map[string]interface{}
+			if err := websocket.JSON.Receive(conn, &request); err != nil {
+				return
+			}
+			s.requests <- request
+			_ = websocket.Message.Send(conn, `{"type":"response","status":"success","id":1,"result":{}}`)
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *streamServer) url() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+func (s *streamServer) nextRequest(t *testing.T) map[string]interface{} {
+	select {
+	case request := <-s.requests:
+		return request
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a stream request")
+		return nil
+	}
+}
+
+func (s *streamServer) nextConn(t *testing.T) *websocket.Conn {
+	select {
+	case conn := <-s.conns:
+		return conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a stream connection")
+		return nil
+	}
+}
+
+func TestSubscriptionClient_DispatchesEvents(t *testing.T) {
+	server := newStreamServer(t)
+	client := NewSubscriptionClient(StreamConfig{URL: server.url(), InitialBackoff: 10 * time.Millisecond})
+	require.NoError(t, client.SubscribeAccounts("rOwner"))
+
+	var mu sync.Mutex
+	var ledgers []*LedgerEvent
+	var transactions []*TransactionEvent
+	delivered := make(chan struct{}, 10)
+	client.OnLedger(func(event *LedgerEvent) {
+		mu.Lock()
+		ledgers = append(ledgers, event)
+		mu.Unlock()
+		delivered <- struct{}{}
+	})
+	client.OnTransaction(func(event *TransactionEvent) {
+		mu.Lock()
+		transactions = append(transactions, event)
+		mu.Unlock()
+		delivered <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = client.Run(ctx) }()
+
+	conn := server.nextConn(t)
+	request := server.nextRequest(t)
+	assert.Equal(t, "subscribe", request["command"])
+	assert.ElementsMatch(t, []interface{}{"ledger", "transactions"}, request["streams"])
+	assert.ElementsMatch(t, []interface{}{"rOwner"}, request["accounts"])
+
+	require.NoError(t, websocket.Message.Send(conn, `{"type":"ledgerClosed","ledger_index":101,"ledger_hash":"LEDGERHASH","fee_base":10,"txn_count":2}`))
+	// The same transaction arrives on the transactions and accounts streams
+	require.NoError(t, websocket.Message.Send(conn, streamTransactionMessage))
+	require.NoError(t, websocket.Message.Send(conn, streamTransactionMessage))
+	require.NoError(t, websocket.Message.Send(conn, `{"type":"ledgerClosed","ledger_index":102}`))
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-delivered:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for stream events")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, ledgers, 2)
+	assert.Equal(t, uint32(101), ledgers[0].LedgerIndex)
+	assert.Equal(t, uint32(10), ledgers[0].FeeBase)
+
+	require.Len(t, transactions, 1)
+	event := transactions[0]
+	assert.Equal(t, "FINISHHASH", event.Hash)
+	assert.Equal(t, "EscrowFinish", event.TransactionType)
+	assert.Equal(t, "rDestination", event.Account)
+	assert.True(t, event.Succeeded())
+	assert.Equal(t, []string{"CREATEHASH"}, event.EscrowCreateHashes())
+	assert.Equal(t, map[string]string{"rDestination": "26000000"}, event.AccountBalances())
+}
+
+func TestSubscriptionClient_ReconnectsAndResubscribes(t *testing.T) {
+	server := newStreamServer(t)
+	client := NewSubscriptionClient(StreamConfig{URL: server.url(), InitialBackoff: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+
+	conn := server.nextConn(t)
+	request := server.nextRequest(t)
+	assert.Nil(t, request["accounts"])
+
+	// Accounts added while connected are subscribed immediately
+	require.NoError(t, client.SubscribeAccounts("rWallet1", "rWallet2"))
+	request = server.nextRequest(t)
+	assert.ElementsMatch(t, []interface{}{"rWallet1", "rWallet2"}, request["accounts"])
+	assert.Nil(t, request["streams"])
+
+	// Drop the connection; the client reconnects and restores every subscription
+	require.NoError(t, conn.Close())
+	server.nextConn(t)
+	request = server.nextRequest(t)
+	assert.Equal(t, "subscribe", request["command"])
+	assert.ElementsMatch(t, []interface{}{"ledger", "transactions"}, request["streams"])
+	assert.ElementsMatch(t, []interface{}{"rWallet1", "rWallet2"}, request["accounts"])
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	assert.False(t, client.Connected())
+}
+
+func TestSubscriptionClient_BacksOffWhileUnreachable(t *testing.T) {
+	client := NewSubscriptionClient(StreamConfig{
+		URL:            "ws://127.0.0.1:1",
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		DialTimeout:    50 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	err := client.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, client.Connected())
+
+	require.NoError(t, client.Close())
+	assert.ErrorIs(t, client.Run(context.Background()), ErrStreamClosed)
+	assert.ErrorIs(t, client.SubscribeAccounts("rWallet"), ErrStreamClosed)
+}
@@ -0,0 +1,256 @@
+package xrpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LastLedgerSequenceOffset is how many ledgers past the latest validated ledger a transaction
+// may be included in before it expires
+const LastLedgerSequenceOffset = 20
+
+// DefaultPollInterval is how often WaitForValidation checks on a submitted transaction
+const DefaultPollInterval = time.Second
+
+// ResultClass groups engine results by how a submitter should react to them
+type ResultClass string
+
+const (
+	// ResultClassSuccess means the transaction applied successfully
+	ResultClassSuccess ResultClass = "success"
+	// ResultClassPending means the transaction is provisionally accepted and may still be validated
+	ResultClassPending ResultClass = "pending"
+	// ResultClassRetry means the transaction was not applied but may succeed if submitted again later
+	ResultClassRetry ResultClass = "retry"
+	// ResultClassResubmit means the transaction can never apply as signed and must be rebuilt with a new sequence or ticket
+	ResultClassResubmit ResultClass = "resubmit"
+	// ResultClassTerminal means retrying cannot help: the transaction is malformed or failed on-ledger
+	ResultClassTerminal ResultClass = "terminal"
+)
+
+// ClassifyResult maps an engine result code to the action a submitter should take
+func ClassifyResult(code string) ResultClass {
+	switch code {
+	case "tesSUCCESS":
+		return ResultClassSuccess
+	case "terQUEUED", "tefALREADY":
+		return ResultClassPending
+	case "tefPAST_SEQ", "tefMAX_LEDGER", "tefNO_TICKET":
+		return ResultClassResubmit
+	}
+
+	switch {
+	case strings.HasPrefix(code, "tec"), strings.HasPrefix(code, "tem"), strings.HasPrefix(code, "tef"):
+		return ResultClassTerminal
+	case strings.HasPrefix(code, "ter"), strings.HasPrefix(code, "tel"):
+		return ResultClassRetry
+	default:
+		return ResultClassTerminal
+	}
+}
+
+// IsFinalRejection reports whether a preliminary result means the transaction can never be
+// included in a ledger. Every other preliminary result may still change before validation.
+func IsFinalRejection(code string) bool {
+	return strings.HasPrefix(code, "tem") || (strings.HasPrefix(code, "tef") && code != "tefALREADY")
+}
+
+// IsFeeRelated reports whether a result means the transaction cost was too low
+func IsFeeRelated(code string) bool {
+	switch code {
+	case "telINSUF_FEE_P", "telCAN_NOT_QUEUE_FEE":
+		return true
+	default:
+		return false
+	}
+}
+
+// Class returns how a submitter should react to the rejected transaction
+func (e *TransactionError) Class() ResultClass {
+	return ClassifyResult(e.Code)
+}
+
+// sequenceUnused reports whether a preliminary result means the transaction will never use its
+// sequence: it was rejected for good, or failed on the local server and was not relayed. Any other
+// result may still be validated, so the transaction holds its sequence until it is or it expires.
+func sequenceUnused(code string) bool {
+	return IsFinalRejection(code) || strings.HasPrefix(code, "tel")
+}
+
+// SequenceAllocator hands out account sequence numbers so concurrent submitters from the same
+// account never sign two transactions with the same sequence
+type SequenceAllocator struct {
+	mu    sync.Mutex
+	next  map[string]uint32
+	held  map[string]heldSequence // by transaction hash
+	fetch func(account string) (uint32, error)
+}
+
+// heldSequence is a reserved sequence a submitted transaction may still use
+type heldSequence struct {
+	account  string
+	sequence uint32
+}
+
+// NewSequenceAllocator creates an allocator that loads an account's next sequence with fetch
+func NewSequenceAllocator(fetch func(account string) (uint32, error)) *SequenceAllocator {
+	return &SequenceAllocator{
+		next:  make(map[string]uint32),
+		held:  make(map[string]heldSequence),
+		fetch: fetch,
+	}
+}
+
+// Next reserves the next unused sequence number of an account
+func (a *SequenceAllocator) Next(account string) (uint32, error) {
+	return a.Reserve(account, 1)
+}
+
+// Reserve reserves count consecutive sequence numbers of an account and returns the first
+func (a *SequenceAllocator) Reserve(account string, count uint32) (uint32, error) {
+	if count == 0 {
+		return 0, fmt.Errorf("cannot reserve zero sequence numbers")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sequence, ok := a.next[account]
+	if !ok {
+		fetched, err := a.fetch(account)
+		if err != nil {
+			return 0, err
+		}
+		sequence = fetched
+	}
+	a.next[account] = sequence + count
+	return sequence, nil
+}
+
+// Reset forgets the cached sequence of an account so the next allocation reloads it from the
+// ledger. Call it when a transaction holding a reserved sequence was not applied. While other
+// transactions of the account hold sequences the ledger has not used yet, allocation resumes right
+// after the highest of them instead, since a reload would hand their sequences out again.
+func (a *SequenceAllocator) Reset(account string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reset(account)
+}
+
+func (a *SequenceAllocator) reset(account string) {
+	var highest uint32
+	holding := false
+	for _, held := range a.held {
+		if held.account == account {
+			holding = true
+			if held.sequence > highest {
+				highest = held.sequence
+			}
+		}
+	}
+	if !holding {
+		delete(a.next, account)
+		return
+	}
+	a.next[account] = highest + 1
+}
+
+// Hold records that a submitted transaction holds a reserved sequence of an account until it is
+// validated or expires
+func (a *SequenceAllocator) Hold(account, hash string, sequence uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.held[hash] = heldSequence{account: account, sequence: sequence}
+}
+
+// Settle forgets a held transaction the ledger validated, which used its sequence
+func (a *SequenceAllocator) Settle(hash string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.held, hash)
+}
+
+// Expire resets the account of a held transaction that expired past its LastLedgerSequence, since
+// it never used its sequence
+func (a *SequenceAllocator) Expire(hash string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if held, ok := a.held[hash]; ok {
+		delete(a.held, hash)
+		a.reset(held.account)
+	}
+}
+
+// WaitForValidation polls a submitted transaction until it appears in a validated ledger or the
+// network validates a ledger past lastLedgerSequence without it, which returns ErrTransactionExpired.
+// A validated transaction that did not succeed returns its result with a TransactionError.
+func (c *Client) WaitForValidation(ctx context.Context, hash string, lastLedgerSequence uint32) (*TransactionResult, error) {
+	if hash == "" {
+		return nil, fmt.Errorf("transaction hash cannot be empty")
+	}
+
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := c.CheckValidation(hash, lastLedgerSequence)
+		if result != nil || err != nil {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for transaction %s: %w", hash, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckValidation looks up a transaction once, returning nil and no error while it is still pending.
+// A transaction missing from the ledgers validated past lastLedgerSequence is ErrTransactionExpired.
+func (c *Client) CheckValidation(hash string, lastLedgerSequence uint32) (*TransactionResult, error) {
+	// Read the validated ledger first: a transaction missing from it cannot appear in an earlier one
+	var validatedLedger uint32
+	if lastLedgerSequence > 0 {
+		info, err := c.GetServerInfo()
+		if err != nil && !errors.Is(err, ErrServerBusy) {
+			return nil, err
+		}
+		if info != nil && info.ValidatedLedger != nil {
+			validatedLedger = info.ValidatedLedger.Seq
+		}
+	}
+
+	result, err := c.GetTransaction(hash)
+	switch {
+	case err == nil && result.Validated:
+		c.sequences.Settle(hash)
+		result.LastLedgerSequence = lastLedgerSequence
+		if result.ResultCode != "tesSUCCESS" {
+			return result, &TransactionError{
+				TransactionID: hash,
+				Code:          result.ResultCode,
+				Message:       "validated with a failure result",
+			}
+		}
+		return result, nil
+	case err != nil && !errors.Is(err, ErrTransactionNotFound) && !errors.Is(err, ErrServerBusy):
+		return nil, err
+	}
+
+	if lastLedgerSequence > 0 && validatedLedger > lastLedgerSequence {
+		log.Printf("Transaction %s expired: validated ledger %d is past LastLedgerSequence %d", hash, validatedLedger, lastLedgerSequence)
+		c.sequences.Expire(hash)
+		return nil, fmt.Errorf("transaction %s: %w", hash, ErrTransactionExpired)
+	}
+	return nil, nil
+}
@@ -0,0 +1,322 @@
+package xrpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyResult(t *testing.T) {
+	cases := map[string]ResultClass{
+		"tesSUCCESS":           ResultClassSuccess,
+		"terQUEUED":            ResultClassPending,
+		"tefALREADY":           ResultClassPending,
+		"tefPAST_SEQ":          ResultClassResubmit,
+		"tefNO_TICKET":         ResultClassResubmit,
+		"tefMAX_LEDGER":        ResultClassResubmit,
+		"terPRE_SEQ":           ResultClassRetry,
+		"telINSUF_FEE_P":       ResultClassRetry,
+		"telCAN_NOT_QUEUE_FEE": ResultClassRetry,
+		"tecNO_TARGET":         ResultClassTerminal,
+		"tecUNFUNDED_PAYMENT":  ResultClassTerminal,
+		"temBAD_AMOUNT":        ResultClassTerminal,
+		"tefBAD_AUTH":          ResultClassTerminal,
+	}
+	for code, class := range cases {
+		assert.Equal(t, class, ClassifyResult(code), code)
+	}
+
+	assert.True(t, IsFinalRejection("temMALFORMED"))
+	assert.True(t, IsFinalRejection("tefPAST_SEQ"))
+	assert.False(t, IsFinalRejection("tefALREADY"))
+	assert.False(t, IsFinalRejection("tecNO_TARGET"), "a provisional tec can still be validated")
+	assert.False(t, IsFinalRejection("telINSUF_FEE_P"))
+	assert.True(t, IsFeeRelated("telINSUF_FEE_P"))
+	assert.False(t, IsFeeRelated("tecUNFUNDED_PAYMENT"))
+}
+
+func TestSequenceAllocator(t *testing.T) {
+	fetches := 0
+	allocator := NewSequenceAllocator(func(account string) (uint32, error) {
+		fetches++
+		return 5, nil
+	})
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := make(map[uint32]bool)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequence, err := allocator.Next(testAccount)
+			require.NoError(t, err)
+			mu.Lock()
+			seen[sequence] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, 50, "concurrent submitters never share a sequence")
+	assert.True(t, seen[5] && seen[54])
+	assert.Equal(t, 1, fetches)
+
+	allocator.Reset(testAccount)
+	sequence, err := allocator.Next(testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), sequence)
+	assert.Equal(t, 2, fetches)
+
+	// A TicketCreate reserves its own sequence and one per ticket
+	sequence, err = allocator.Reserve(testAccount, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), sequence)
+	sequence, err = allocator.Next(testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), sequence)
+	_, err = allocator.Reserve(testAccount, 0)
+	assert.Error(t, err)
+}
+
+func TestJSONRPC_SignAndSubmitReservesSequences(t *testing.T) {
+	keyPair, err := DeriveKeyPair("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	require.NoError(t, err)
+
+	results := map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":       "success",
+			"account_data": map[string]interface{}{"Account": keyPair.Address(), "Sequence": 17},
+		},
+		"server_info": serverInfoResult(),
+		"submit": map[string]interface{}{
+			"status":        "success",
+			"engine_result": "tesSUCCESS",
+			"tx_json":       map[string]interface{}{"hash": "ABC123"},
+		},
+	}
+	server, requests := newTestRippled(t, results)
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	client.SetKeyProvider(staticKeyProvider{keyPair.Address(): keyPair})
+
+	payment := &Payment{Account: keyPair.Address(), Destination: testAccount, Amount: XRPAmount(1000000)}
+	first, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	second, err := client.SendPayment(payment)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(17), first.Sequence)
+	assert.Equal(t, uint32(18), second.Sequence, "the second payment does not reuse the first one's sequence")
+	assert.Equal(t, uint32(90000+LastLedgerSequenceOffset), first.LastLedgerSequence)
+	blob := (*requests)[len(*requests)-1].Params[0].(map[string]interface{})["tx_blob"].(string)
+	assert.Contains(t, blob, "201B00015FA4", "LastLedgerSequence is signed into the transaction")
+
+	// A transaction the local server did not relay releases its sequence so the next one fills the gap
+	results["submit"] = map[string]interface{}{
+		"status":        "success",
+		"engine_result": "telINSUF_FEE_P",
+		"tx_json":       map[string]interface{}{"hash": "DEF456"},
+	}
+	rejected, err := client.SendPayment(payment)
+	require.ErrorIs(t, err, ErrProvisionalResult, "a tel result is not applied but not final")
+	assert.Equal(t, "telINSUF_FEE_P", rejected.ResultCode)
+
+	results["submit"] = map[string]interface{}{
+		"status":        "success",
+		"engine_result": "tesSUCCESS",
+		"tx_json":       map[string]interface{}{"hash": "GHI789"},
+	}
+	third, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(19), third.Sequence, "the unused sequence is handed out again")
+
+	// A final rejection releases its sequence too
+	results["submit"] = map[string]interface{}{
+		"status":        "success",
+		"engine_result": "temBAD_AMOUNT",
+		"tx_json":       map[string]interface{}{"hash": "JKL012"},
+	}
+	_, err = client.SendPayment(payment)
+	var txErr *TransactionError
+	require.True(t, errors.As(err, &txErr))
+	assert.Equal(t, ResultClassTerminal, txErr.Class())
+	assert.NotErrorIs(t, err, ErrProvisionalResult)
+}
+
+func TestJSONRPC_RetriedTransactionHoldsSequenceUntilExpiry(t *testing.T) {
+	keyPair, err := DeriveKeyPair("snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	require.NoError(t, err)
+
+	results := map[string]interface{}{
+		"account_info": map[string]interface{}{
+			"status":       "success",
+			"account_data": map[string]interface{}{"Account": keyPair.Address(), "Sequence": 17},
+		},
+		"server_info": serverInfoResult(),
+		"submit": map[string]interface{}{
+			"status":        "success",
+			"engine_result": "terPRE_SEQ",
+			"tx_json":       map[string]interface{}{"hash": "ABC123"},
+		},
+		"tx": map[string]interface{}{"status": "error", "error": "txnNotFound"},
+	}
+	server, _ := newTestRippled(t, results)
+	client := NewClientWithMode(server.URL, true, ModeJSONRPC)
+	client.SetKeyProvider(staticKeyProvider{keyPair.Address(): keyPair})
+
+	payment := &Payment{Account: keyPair.Address(), Destination: testAccount, Amount: XRPAmount(1000000)}
+	retried, err := client.SendPayment(payment)
+	require.ErrorIs(t, err, ErrProvisionalResult, "a ter result is not applied but not final")
+	assert.Equal(t, "terPRE_SEQ", retried.ResultCode)
+	assert.Equal(t, uint32(17), retried.Sequence)
+
+	// The ter transaction may still be applied, so its sequence is not handed out again
+	results["submit"] = map[string]interface{}{
+		"status":        "success",
+		"engine_result": "tesSUCCESS",
+		"tx_json":       map[string]interface{}{"hash": "DEF456"},
+	}
+	next, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(18), next.Sequence)
+
+	pending, err := client.CheckValidation(retried.TransactionID, retried.LastLedgerSequence)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+	afterPending, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(19), afterPending.Sequence, "a pending transaction keeps holding its sequence")
+
+	// Once the later payments validated and the ledger validates past the retried transaction's
+	// LastLedgerSequence, the sequence is reloaded
+	client.sequences.Settle(next.TransactionID)
+	info := serverInfoResult()
+	info["info"].(map[string]interface{})["validated_ledger"].(map[string]interface{})["seq"] = retried.LastLedgerSequence + 1
+	results["server_info"] = info
+	_, err = client.CheckValidation(retried.TransactionID, retried.LastLedgerSequence)
+	require.ErrorIs(t, err, ErrTransactionExpired)
+
+	reloaded, err := client.SendPayment(payment)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(17), reloaded.Sequence, "the sequence is reloaded from the ledger")
+}
+
+func TestSequenceAllocator_ExpiryKeepsOtherHeldSequences(t *testing.T) {
+	fetches := 0
+	allocator := NewSequenceAllocator(func(account string) (uint32, error) {
+		fetches++
+		return 17, nil
+	})
+
+	first, err := allocator.Next(testAccount)
+	require.NoError(t, err)
+	second, err := allocator.Next(testAccount)
+	require.NoError(t, err)
+	allocator.Hold(testAccount, "FIRST", first)
+	allocator.Hold(testAccount, "SECOND", second)
+
+	// The ledger has used neither sequence yet, so a reload would hand the second one out again
+	allocator.Expire("FIRST")
+	sequence, err := allocator.Next(testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(19), sequence, "the sequence the second transaction holds is not reused")
+
+	allocator.Reset(testAccount)
+	sequence, err = allocator.Next(testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(19), sequence, "an unused sequence after the held one is handed out again")
+	assert.Equal(t, 1, fetches)
+
+	// Once no transaction holds a sequence the account is reloaded from the ledger
+	allocator.Expire("SECOND")
+	sequence, err = allocator.Next(testAccount)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(17), sequence)
+	assert.Equal(t, 2, fetches)
+}
+
+// newLedgerStepper starts a rippled stand-in whose validated ledger advances on every
+// server_info call and which reports the transaction once the ledger reaches includedIn
+func newLedgerStepper(t *testing.T, startLedger, includedIn uint32, resultCode string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	ledger := startLedger
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		defer mu.Unlock()
+
+		var result map[string]interface{}
+		switch req.Method {
+		case "server_info":
+			ledger++
+			info := serverInfoResult()
+			info["info"].(map[string]interface{})["validated_ledger"].(map[string]interface{})["seq"] = ledger
+			result = info
+		case "tx":
+			if includedIn == 0 || ledger < includedIn {
+				result = map[string]interface{}{"status": "error", "error": "txnNotFound"}
+			} else {
+				result = map[string]interface{}{
+					"status":       "success",
+					"hash":         "ABC123",
+					"ledger_index": includedIn,
+					"validated":    true,
+					"meta":         map[string]interface{}{"TransactionResult": resultCode},
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJSONRPC_WaitForValidation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("validated", func(t *testing.T) {
+		client := NewClientWithMode(newLedgerStepper(t, 100, 103, "tesSUCCESS").URL, true, ModeJSONRPC)
+		client.PollInterval = time.Millisecond
+		result, err := client.WaitForValidation(ctx, "ABC123", 120)
+		require.NoError(t, err)
+		assert.True(t, result.Validated)
+		assert.Equal(t, uint32(103), result.LedgerIndex)
+	})
+
+	t.Run("validated with failure", func(t *testing.T) {
+		client := NewClientWithMode(newLedgerStepper(t, 100, 101, "tecUNFUNDED_PAYMENT").URL, true, ModeJSONRPC)
+		client.PollInterval = time.Millisecond
+		result, err := client.WaitForValidation(ctx, "ABC123", 120)
+		var txErr *TransactionError
+		require.True(t, errors.As(err, &txErr))
+		assert.Equal(t, ResultClassTerminal, txErr.Class())
+		assert.Equal(t, "tecUNFUNDED_PAYMENT", result.ResultCode)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		client := NewClientWithMode(newLedgerStepper(t, 100, 0, "").URL, true, ModeJSONRPC)
+		client.PollInterval = time.Millisecond
+		_, err := client.WaitForValidation(ctx, "ABC123", 105)
+		assert.True(t, errors.Is(err, ErrTransactionExpired))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		client := NewClientWithMode(newLedgerStepper(t, 100, 0, "").URL, true, ModeJSONRPC)
+		client.PollInterval = time.Millisecond
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := client.WaitForValidation(canceled, "ABC123", 0)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
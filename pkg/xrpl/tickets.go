@@ -0,0 +1,95 @@
+package xrpl
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// MaxTicketsPerAccount is the most tickets an account can hold at once
+const MaxTicketsPerAccount = 250
+
+// TicketCreate represents parameters for setting aside sequence numbers as tickets
+type TicketCreate struct {
+	Account     string `json:"Account"`
+	TicketCount uint32 `json:"TicketCount"`
+}
+
+// ticketObject is a Ticket entry as listed by account_objects
+type ticketObject struct {
+	TicketSequence uint32 `json:"TicketSequence"`
+}
+
+// CreateTickets sets aside count sequence numbers of an account as tickets. Once validated the
+// tickets are the count sequences following the TicketCreate's own sequence.
+func (c *Client) CreateTickets(ticket *TicketCreate) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if !c.ValidateAddress(ticket.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", ticket.Account)
+	}
+	if ticket.TicketCount == 0 || ticket.TicketCount > MaxTicketsPerAccount {
+		return nil, fmt.Errorf("ticket count must be between 1 and %d, got %d", MaxTicketsPerAccount, ticket.TicketCount)
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("TicketCreate", ticket)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Created %d tickets for %s, TxID: %s", ticket.TicketCount, ticket.Account, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// GetTickets lists the ticket sequences an account holds in the validated ledger, lowest first
+func (c *Client) GetTickets(account string) ([]uint32, error) {
+	if !c.ValidateAddress(account) {
+		return nil, fmt.Errorf("invalid account address: %s", account)
+	}
+
+	if c.simulated() {
+		return []uint32{}, nil
+	}
+
+	tickets := []uint32{}
+	var marker interface{}
+	for {
+		var result struct {
+			AccountObjects []ticketObject `json:"account_objects"`
+			Marker         interface{}    `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":      account,
+			"type":         "ticket",
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_objects", params, &result); err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.AccountObjects {
+			tickets = append(tickets, object.TicketSequence)
+		}
+		if result.Marker == nil {
+			sort.Slice(tickets, func(i, j int) bool { return tickets[i] < tickets[j] })
+			return tickets, nil
+		}
+		marker = result.Marker
+	}
+}
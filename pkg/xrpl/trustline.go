@@ -0,0 +1,122 @@
+package xrpl
+
+import (
+	"fmt"
+	"log"
+)
+
+// TrustSet flags
+const (
+	TrustSetFlagSetAuth       uint32 = 0x00010000
+	TrustSetFlagSetNoRipple   uint32 = 0x00020000
+	TrustSetFlagClearNoRipple uint32 = 0x00040000
+	TrustSetFlagSetFreeze     uint32 = 0x00100000
+	TrustSetFlagClearFreeze   uint32 = 0x00200000
+)
+
+// TrustSet represents parameters for creating or modifying a trust line
+type TrustSet struct {
+	Account     string `json:"Account"`
+	LimitAmount Amount `json:"LimitAmount"`
+	Flags       uint32 `json:"Flags,omitempty"`
+	QualityIn   uint32 `json:"QualityIn,omitempty"`
+	QualityOut  uint32 `json:"QualityOut,omitempty"`
+}
+
+// TrustLine is a trust line as reported by account_lines, seen from the queried account
+type TrustLine struct {
+	Peer         string `json:"account"`
+	Balance      string `json:"balance"`
+	Currency     string `json:"currency"`
+	Limit        string `json:"limit"`
+	LimitPeer    string `json:"limit_peer"`
+	QualityIn    uint32 `json:"quality_in"`
+	QualityOut   uint32 `json:"quality_out"`
+	NoRipple     bool   `json:"no_ripple,omitempty"`
+	NoRipplePeer bool   `json:"no_ripple_peer,omitempty"`
+	Authorized   bool   `json:"authorized,omitempty"`
+	PeerAuth     bool   `json:"peer_authorized,omitempty"`
+	Freeze       bool   `json:"freeze,omitempty"`
+	FreezePeer   bool   `json:"freeze_peer,omitempty"`
+}
+
+// SetTrustLine submits a TrustSet transaction creating or updating a trust line
+func (c *Client) SetTrustLine(trust *TrustSet) (*TransactionResult, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("XRPL client not connected")
+	}
+
+	if trust.LimitAmount.IsNative() {
+		return nil, fmt.Errorf("trust line limit must be an issued-currency amount")
+	}
+	if !c.ValidateAddress(trust.Account) {
+		return nil, fmt.Errorf("invalid account address: %s", trust.Account)
+	}
+	if !c.ValidateAddress(trust.LimitAmount.Issuer) {
+		return nil, fmt.Errorf("invalid issuer address: %s", trust.LimitAmount.Issuer)
+	}
+	if trust.Account == trust.LimitAmount.Issuer {
+		return nil, fmt.Errorf("an account cannot extend a trust line to itself")
+	}
+	if trust.Flags&TrustSetFlagSetFreeze != 0 && trust.Flags&TrustSetFlagClearFreeze != 0 {
+		return nil, fmt.Errorf("cannot set and clear freeze in the same TrustSet")
+	}
+
+	if !c.simulated() {
+		tx, err := TransactionFromStruct("TrustSet", trust)
+		if err != nil {
+			return nil, err
+		}
+		return c.SignAndSubmit(tx)
+	}
+
+	txID := c.generateTransactionID()
+	log.Printf("Set trust line: %s -> %s, Flags: %#x, TxID: %s", trust.Account, trust.LimitAmount, trust.Flags, txID)
+
+	return &TransactionResult{
+		TransactionID: txID,
+		LedgerIndex:   12345, // Mock ledger index
+		Validated:     true,
+		ResultCode:    "tesSUCCESS",
+		ResultMessage: "The transaction was applied. Only final in a validated ledger.",
+	}, nil
+}
+
+// GetTrustLines lists an account's trust lines, optionally only those with one peer
+func (c *Client) GetTrustLines(account, peer string) ([]TrustLine, error) {
+	if !c.ValidateAddress(account) {
+		return nil, fmt.Errorf("invalid account address: %s", account)
+	}
+
+	if c.simulated() {
+		return []TrustLine{}, nil
+	}
+
+	lines := []TrustLine{}
+	var marker interface{}
+	for {
+		var result struct {
+			Lines  []TrustLine `json:"lines"`
+			Marker interface{} `json:"marker,omitempty"`
+		}
+		params := map[string]interface{}{
+			"account":      account,
+			"ledger_index": "validated",
+		}
+		if peer != "" {
+			params["peer"] = peer
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		if err := c.call("account_lines", params, &result); err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, result.Lines...)
+		if result.Marker == nil {
+			return lines, nil
+		}
+		marker = result.Marker
+	}
+}
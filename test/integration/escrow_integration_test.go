@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/smart-payment-infrastructure/internal/models"
 	"github.com/smart-payment-infrastructure/internal/services"
+	"github.com/smart-payment-infrastructure/pkg/xrpl"
 )
 
 func TestSmartChequeEscrowIntegration(t *testing.T) {
@@ -48,17 +50,16 @@ func TestSmartChequeEscrowIntegration(t *testing.T) {
 	t.Log("Step 2: Creating Smart Check escrow...")
 
 	milestoneSecret := "project_milestone_completion_secret_2024"
-	escrowAmount := 100.0 // 100 XRP
-	currency := "XRP"
+	escrowAmount := models.MustParseMoney("100", models.CurrencyXRP) // 100 XRP
 
 	escrowResult, fulfillment, err := xrplService.CreateSmartChequeEscrow(
-		payerWallet.Address, payeeWallet.Address, escrowAmount, currency, milestoneSecret)
+		payerWallet.Address, payeeWallet.Address, escrowAmount, milestoneSecret)
 	require.NoError(t, err, "Should create escrow successfully")
 	require.NotNil(t, escrowResult, "Escrow result should not be nil")
 	require.NotEmpty(t, fulfillment, "Fulfillment should not be empty")
 
 	t.Logf("  Escrow created with Transaction ID: %s", escrowResult.TransactionID)
-	t.Logf("  Escrow amount: %.0f %s", escrowAmount, currency)
+	t.Logf("  Escrow amount: %s %s", escrowAmount, escrowAmount.Currency())
 
 	// Validate escrow creation result
 	assert.Equal(t, "tesSUCCESS", escrowResult.ResultCode, "Escrow should be created successfully")
@@ -137,10 +138,10 @@ func TestSmartChequeEscrowCancellation(t *testing.T) {
 
 	// Create escrow that will be canceled
 	milestoneSecret := "canceled_project_secret_2024"
-	escrowAmount := 50.0 // 50 XRP
+	escrowAmount := models.MustParseMoney("50", models.CurrencyXRP) // 50 XRP
 
 	escrowResult, _, err := xrplService.CreateSmartChequeEscrow(
-		payerWallet.Address, payeeWallet.Address, escrowAmount, "XRP", milestoneSecret)
+		payerWallet.Address, payeeWallet.Address, escrowAmount, milestoneSecret)
 	require.NoError(t, err)
 
 	t.Logf("Created escrow for cancellation: %s", escrowResult.TransactionID)
@@ -183,15 +184,23 @@ func TestMultipleCurrencyEscrows(t *testing.T) {
 	payeeWallet, err := xrplService.CreateWallet()
 	require.NoError(t, err)
 
+	// Stablecoins are issued currencies and need a configured issuer
+	issuerWallet, err := xrplService.CreateWallet()
+	require.NoError(t, err)
+	require.NoError(t, xrplService.ConfigureIssuedAssets([]*models.SupportedAsset{
+		{CurrencyCode: "USDT", AssetType: models.AssetTypeStablecoin, IssuerAddress: &issuerWallet.Address},
+		{CurrencyCode: "USDC", AssetType: models.AssetTypeStablecoin, IssuerAddress: &issuerWallet.Address},
+	}))
+
 	// Test different currency escrows
 	currencies := []struct {
 		name     string
-		amount   float64
+		amount   models.Money
 		currency string
 	}{
-		{"XRP Escrow", 25.5, "XRP"},
-		{"USDT Escrow", 1000.75, "USDT"},
-		{"USDC Escrow", 500.00, "USDC"},
+		{"XRP Escrow", models.MustParseMoney("25.5", models.CurrencyXRP), "XRP"},
+		{"USDT Escrow", models.MustParseMoney("1000.75", models.CurrencyUSDT), "USDT"},
+		{"USDC Escrow", models.MustParseMoney("500.00", models.CurrencyUSDC), "USDC"},
 	}
 
 	for i, test := range currencies {
@@ -200,13 +209,13 @@ func TestMultipleCurrencyEscrows(t *testing.T) {
 
 			escrowResult, fulfillment, err := xrplService.CreateSmartChequeEscrow(
 				payerWallet.Address, payeeWallet.Address,
-				test.amount, test.currency, milestoneSecret)
+				test.amount, milestoneSecret)
 
 			require.NoError(t, err, "Should create %s escrow successfully", test.currency)
 			require.NotNil(t, escrowResult)
 			require.NotEmpty(t, fulfillment)
 
-			t.Logf("  %s escrow created: %.2f %s, TX: %s",
+			t.Logf("  %s escrow created: %s %s, TX: %s",
 				test.currency, test.amount, test.currency, escrowResult.TransactionID)
 
 			// Validate escrow creation
@@ -248,8 +257,8 @@ func TestEscrowConditionGeneration(t *testing.T) {
 		require.NoError(t, err, "Should generate condition for secret %d", i+1)
 
 		// Validate condition format
-		assert.Equal(t, 64, len(condition), "Condition should be 64 character SHA-256 hash")
-		assert.Equal(t, secret, fulfillment, "Fulfillment should match original secret")
+		assert.Equal(t, 78, len(condition), "Condition should be a DER-encoded PREIMAGE-SHA-256 condition")
+		assert.NoError(t, xrpl.ValidateFulfillment(condition, fulfillment), "Fulfillment should satisfy the condition")
 
 		// Ensure conditions are unique
 		for prevSecret, prevCondition := range conditions {
@@ -273,8 +282,8 @@ func TestEscrowConditionGeneration(t *testing.T) {
 
 		assert.Equal(t, expectedCondition, condition,
 			"Same secret should generate same condition")
-		assert.Equal(t, secret, fulfillment,
-			"Fulfillment should match original secret")
+		assert.NoError(t, xrpl.ValidateFulfillment(condition, fulfillment),
+			"Fulfillment should satisfy the condition")
 	}
 
 	t.Log("Condition generation test completed successfully!")